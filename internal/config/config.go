@@ -3,6 +3,8 @@ package config
 import (
 	"log"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -12,10 +14,35 @@ type Config struct {
 	JWTSecretKey      string
 	HashingSalt       string
 	HashingIterations int
+	// DataDir enables persistence of the in-memory database when set
+	DataDir          string
+	SnapshotInterval time.Duration
+	// DatabaseDriver selects the storage backend, either "inmemory" or "postgres"
+	DatabaseDriver string
+	DatabaseURL    string
+	// SeedFile is a JSON or YAML fixtures file loaded on startup, the dev environment falls back to the built-in one
+	SeedFile string
+	// IdempotencyTTL is how long a response is replayed for a repeated Idempotency-Key
+	IdempotencyTTL time.Duration
+	// IdempotencyMaxBodyBytes caps the body of a request with an Idempotency-Key, which is kept along with its response
+	IdempotencyMaxBodyBytes int64
+	// CursorSecretKey signs the pagination cursors, it falls back to JWTSecretKey
+	CursorSecretKey string
+	// TrashRetention is how long a deleted favourite can be restored, and TrashPurgeInterval
+	// how often the favourites kept for longer are purged
+	TrashRetention     time.Duration
+	TrashPurgeInterval time.Duration
+	// LegacyInsightJSON keeps the Id and Text keys of the insights next to the snake_case ones for older clients
+	LegacyInsightJSON bool
 }
 
 const notDefined = ""
 
+const (
+	DatabaseDriverInMemory = "inmemory"
+	DatabaseDriverPostgres = "postgres"
+)
+
 func GetRequiredEnvVariable(key string) string {
 	value := os.Getenv(key)
 	if value == notDefined {
@@ -38,11 +65,71 @@ func GetOptionalEnvVariableWithDefaultValue(key string, defaultValue string) str
 	return value
 }
 
+// GetOptionalDurationEnvVariableWithDefaultValue panics when the duration is not positive,
+// since every duration is an interval of a ticker or a time to keep something for.
+func GetOptionalDurationEnvVariableWithDefaultValue(key string, defaultValue string) time.Duration {
+	value := GetOptionalEnvVariableWithDefaultValue(key, defaultValue)
+
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		log.Panicf("%s env variable is not a valid duration: %s", key, err)
+	}
+
+	if duration <= 0 {
+		log.Panicf("%s env variable must be a positive duration, got %s", key, value)
+	}
+
+	return duration
+}
+
+// GetOptionalIntEnvVariableWithDefaultValue panics when the integer is not positive,
+// since every integer is a limit that would reject everything otherwise.
+func GetOptionalIntEnvVariableWithDefaultValue(key string, defaultValue string) int64 {
+	value := GetOptionalEnvVariableWithDefaultValue(key, defaultValue)
+
+	number, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		log.Panicf("%s env variable is not a valid integer: %s", key, err)
+	}
+
+	if number <= 0 {
+		log.Panicf("%s env variable must be a positive integer, got %s", key, value)
+	}
+
+	return number
+}
+
+func GetOptionalBoolEnvVariableWithDefaultValue(key string, defaultValue string) bool {
+	value := GetOptionalEnvVariableWithDefaultValue(key, defaultValue)
+
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Panicf("%s env variable is not a valid boolean: %s", key, err)
+	}
+
+	return enabled
+}
+
 func buildConfig() *Config {
 	cfg := Config{
-		Environment:  GetOptionalEnvVariableWithDefaultValue("APP_ENV", "dev"),
-		JWTSecretKey: GetRequiredEnvVariable("JWT_SECRET_KEY"),
-		HashingSalt:  GetRequiredEnvVariable("HASHING_SALT"),
+		Environment:             GetOptionalEnvVariableWithDefaultValue("APP_ENV", "dev"),
+		JWTSecretKey:            GetRequiredEnvVariable("JWT_SECRET_KEY"),
+		HashingSalt:             GetRequiredEnvVariable("HASHING_SALT"),
+		DataDir:                 GetOptionalEnvVariableWithDefaultValue("DATA_DIR", ""),
+		SnapshotInterval:        GetOptionalDurationEnvVariableWithDefaultValue("SNAPSHOT_INTERVAL", "5m"),
+		DatabaseDriver:          GetOptionalEnvVariableWithDefaultValue("DB_DRIVER", DatabaseDriverInMemory),
+		DatabaseURL:             GetOptionalEnvVariableWithDefaultValue("DATABASE_URL", ""),
+		SeedFile:                GetOptionalEnvVariableWithDefaultValue("SEED_FILE", ""),
+		IdempotencyTTL:          GetOptionalDurationEnvVariableWithDefaultValue("IDEMPOTENCY_TTL", "24h"),
+		IdempotencyMaxBodyBytes: GetOptionalIntEnvVariableWithDefaultValue("IDEMPOTENCY_MAX_BODY_BYTES", "1048576"),
+		CursorSecretKey:         GetOptionalEnvVariableWithDefaultValue("CURSOR_SECRET_KEY", ""),
+		TrashRetention:          GetOptionalDurationEnvVariableWithDefaultValue("TRASH_RETENTION", "720h"),
+		TrashPurgeInterval:      GetOptionalDurationEnvVariableWithDefaultValue("TRASH_PURGE_INTERVAL", "1h"),
+		LegacyInsightJSON:       GetOptionalBoolEnvVariableWithDefaultValue("LEGACY_INSIGHT_JSON", "false"),
+	}
+
+	if cfg.CursorSecretKey == notDefined {
+		cfg.CursorSecretKey = cfg.JWTSecretKey
 	}
 
 	return &cfg
@@ -4,6 +4,7 @@ import (
 	"os"
 	"platform-go-challenge/internal/config"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -50,3 +51,125 @@ func TestGetOptionalEnvVariableWithDefaultValue(t *testing.T) {
 		assert.Equal(t, actual_result, expected_result)
 	})
 }
+
+func TestGetOptionalDurationEnvVariableWithDefaultValue(t *testing.T) {
+	t.Run("should parse default value when env var is not set", func(t *testing.T) {
+		// Arrange
+		os.Unsetenv("SNAPSHOT_INTERVAL")
+
+		// Act
+		actual_result := config.GetOptionalDurationEnvVariableWithDefaultValue("SNAPSHOT_INTERVAL", "5m")
+
+		// Assert
+		assert.Equal(t, 5*time.Minute, actual_result)
+	})
+
+	t.Run("should parse environment variable when it is set", func(t *testing.T) {
+		// Arrange
+		os.Setenv("SNAPSHOT_INTERVAL", "30s")
+		defer os.Unsetenv("SNAPSHOT_INTERVAL")
+
+		// Act
+		actual_result := config.GetOptionalDurationEnvVariableWithDefaultValue("SNAPSHOT_INTERVAL", "5m")
+
+		// Assert
+		assert.Equal(t, 30*time.Second, actual_result)
+	})
+
+	t.Run("should panic when environment variable is not a duration", func(t *testing.T) {
+		// Arrange
+		os.Setenv("SNAPSHOT_INTERVAL", "often")
+		defer os.Unsetenv("SNAPSHOT_INTERVAL")
+
+		// Act/Assert
+		assert.Panics(t, func() {
+			config.GetOptionalDurationEnvVariableWithDefaultValue("SNAPSHOT_INTERVAL", "5m")
+		})
+	})
+
+	t.Run("should panic when the duration is not positive", func(t *testing.T) {
+		for _, value := range []string{"0s", "-1m"} {
+			// Arrange
+			os.Setenv("SNAPSHOT_INTERVAL", value)
+
+			// Act/Assert
+			assert.Panics(t, func() {
+				config.GetOptionalDurationEnvVariableWithDefaultValue("SNAPSHOT_INTERVAL", "5m")
+			}, value)
+		}
+		os.Unsetenv("SNAPSHOT_INTERVAL")
+	})
+}
+
+func TestGetOptionalBoolEnvVariableWithDefaultValue(t *testing.T) {
+	t.Run("should parse default value when env var is not set", func(t *testing.T) {
+		// Arrange
+		os.Unsetenv("LEGACY_INSIGHT_JSON")
+
+		// Act
+		actual_result := config.GetOptionalBoolEnvVariableWithDefaultValue("LEGACY_INSIGHT_JSON", "false")
+
+		// Assert
+		assert.False(t, actual_result)
+	})
+
+	t.Run("should parse environment variable when it is set", func(t *testing.T) {
+		// Arrange
+		os.Setenv("LEGACY_INSIGHT_JSON", "true")
+		defer os.Unsetenv("LEGACY_INSIGHT_JSON")
+
+		// Act
+		actual_result := config.GetOptionalBoolEnvVariableWithDefaultValue("LEGACY_INSIGHT_JSON", "false")
+
+		// Assert
+		assert.True(t, actual_result)
+	})
+
+	t.Run("should panic when environment variable is not a boolean", func(t *testing.T) {
+		// Arrange
+		os.Setenv("LEGACY_INSIGHT_JSON", "sometimes")
+		defer os.Unsetenv("LEGACY_INSIGHT_JSON")
+
+		// Act/Assert
+		assert.Panics(t, func() {
+			config.GetOptionalBoolEnvVariableWithDefaultValue("LEGACY_INSIGHT_JSON", "false")
+		})
+	})
+}
+
+func TestGetOptionalIntEnvVariableWithDefaultValue(t *testing.T) {
+	t.Run("should parse default value when env var is not set", func(t *testing.T) {
+		// Arrange
+		os.Unsetenv("IDEMPOTENCY_MAX_BODY_BYTES")
+
+		// Act
+		actual_result := config.GetOptionalIntEnvVariableWithDefaultValue("IDEMPOTENCY_MAX_BODY_BYTES", "1048576")
+
+		// Assert
+		assert.Equal(t, int64(1048576), actual_result)
+	})
+
+	t.Run("should panic when environment variable is not an integer", func(t *testing.T) {
+		// Arrange
+		os.Setenv("IDEMPOTENCY_MAX_BODY_BYTES", "1MB")
+		defer os.Unsetenv("IDEMPOTENCY_MAX_BODY_BYTES")
+
+		// Act/Assert
+		assert.Panics(t, func() {
+			config.GetOptionalIntEnvVariableWithDefaultValue("IDEMPOTENCY_MAX_BODY_BYTES", "1048576")
+		})
+	})
+
+	t.Run("should panic when the integer is not positive", func(t *testing.T) {
+		for _, value := range []string{"0", "-1"} {
+			// Arrange
+			os.Setenv("IDEMPOTENCY_MAX_BODY_BYTES", value)
+
+			// Act/Assert
+			assert.Panics(t, func() {
+				config.GetOptionalIntEnvVariableWithDefaultValue("IDEMPOTENCY_MAX_BODY_BYTES", "1048576")
+			}, value)
+		}
+		os.Unsetenv("IDEMPOTENCY_MAX_BODY_BYTES")
+	})
+}
@@ -0,0 +1,123 @@
+package database
+
+import (
+	"slices"
+
+	"github.com/google/uuid"
+)
+
+// IMBatch stages the writes of an IMStorage.Batch. They are applied right away so the next
+// writes of the batch see them, and are undone when the batch does not commit.
+// It must not be used after the build function of the batch has returned.
+type IMBatch[T any] struct {
+	storage *IMStorage[T]
+	// previous holds the item each written id had before the batch, nil when it did not exist
+	previous map[uuid.UUID]*T
+	records  []imBatchRecord
+}
+
+func (b *IMBatch[T]) Get(id uuid.UUID) (T, bool) {
+	v, found := b.storage.items[id]
+
+	return v, found
+}
+
+// Insert behaves like IMStorage.Insert, including the items staged before it in the batch.
+func (b *IMBatch[T]) Insert(id uuid.UUID, v T) (T, error) {
+	if existing, err := b.storage.checkUnique(id, v); err != nil {
+		return existing, err
+	}
+
+	b.set(id, v)
+
+	return v, nil
+}
+
+// Update behaves like IMStorage.Update, including the items staged before it in the batch.
+func (b *IMBatch[T]) Update(id uuid.UUID, update func(current T) (T, error)) (T, error) {
+	var empty T
+
+	current, found := b.storage.items[id]
+	if !found {
+		return empty, IMErrItemNotFound
+	}
+
+	v, err := update(current)
+	if err != nil {
+		return empty, err
+	}
+
+	if err := b.storage.checkUniqueChanges(id, current, v); err != nil {
+		return empty, err
+	}
+
+	b.set(id, v)
+
+	return v, nil
+}
+
+// DeleteIf behaves like IMStorage.DeleteIf, including the items staged before it in the batch.
+func (b *IMBatch[T]) DeleteIf(id uuid.UUID, check func(current T) error) error {
+	current, found := b.storage.items[id]
+	if !found {
+		return IMErrItemNotFound
+	}
+
+	if err := check(current); err != nil {
+		return err
+	}
+
+	b.remember(id)
+	b.storage.delete(id)
+	b.records = append(b.records, imBatchRecord{Op: imOperationDelete, Id: id})
+
+	return nil
+}
+
+// DeletePartition behaves like IMStorage.DeletePartition, including the items staged before it in the batch.
+func (b *IMBatch[T]) DeletePartition(indexName string, partition uuid.UUID) (int, error) {
+	index, found := b.storage.indexes[indexName].(*IMSortedIndex[T])
+	if !found {
+		return 0, IMErrIndexNotFound
+	}
+
+	ids := slices.Clone(index.partitions[partition])
+	for _, id := range ids {
+		b.remember(id)
+		b.storage.delete(id)
+		b.records = append(b.records, imBatchRecord{Op: imOperationDelete, Id: id})
+	}
+
+	return len(ids), nil
+}
+
+func (b *IMBatch[T]) set(id uuid.UUID, v T) {
+	b.remember(id)
+	b.storage.set(id, v)
+	b.records = append(b.records, imBatchRecord{Op: imOperationSet, Id: id, Value: v})
+}
+
+// remember keeps the item an id had before its first write in the batch.
+func (b *IMBatch[T]) remember(id uuid.UUID) {
+	if _, found := b.previous[id]; found {
+		return
+	}
+
+	var previous *T
+	if v, found := b.storage.items[id]; found {
+		previous = &v
+	}
+
+	b.previous[id] = previous
+}
+
+func (b *IMBatch[T]) rollback() {
+	for id, previous := range b.previous {
+		if previous == nil {
+			b.storage.delete(id)
+			continue
+		}
+
+		b.storage.set(id, *previous)
+	}
+}
@@ -0,0 +1,325 @@
+package database
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"platform-go-challenge/internal/utils"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed fixtures/dev.json
+var devFixtures []byte
+
+var (
+	ErrInvalidFixtures           = errors.New("Invalid fixtures")
+	ErrUnsupportedFixturesFormat = errors.New("Unsupported fixtures format, expected .json, .yaml or .yml")
+)
+
+// Fixtures is a dataset that can be loaded into any storage backend.
+// Passwords are in plain text and get hashed while loading.
+type Fixtures struct {
+	Users      []FixtureUser      `json:"users" yaml:"users"`
+	Charts     []FixtureChart     `json:"charts" yaml:"charts"`
+	Insights   []FixtureInsight   `json:"insights" yaml:"insights"`
+	Audiences  []FixtureAudience  `json:"audiences" yaml:"audiences"`
+	Favourites []FixtureFavourite `json:"favourites" yaml:"favourites"`
+}
+
+type FixtureUser struct {
+	Id       uuid.UUID `json:"id" yaml:"id"`
+	Email    string    `json:"email" yaml:"email"`
+	Password string    `json:"password" yaml:"password"`
+	IsAdmin  bool      `json:"is_admin" yaml:"is_admin"`
+}
+
+// FixtureChart is a chart of schema version 1 with its x and y maps, it is converted to the current shape when it is read.
+type FixtureChart struct {
+	Id         uuid.UUID            `json:"id" yaml:"id"`
+	Title      string               `json:"title" yaml:"title"`
+	XAxisTitle string               `json:"x_axis_title" yaml:"x_axis_title"`
+	YAxisTitle string               `json:"y_axis_title" yaml:"y_axis_title"`
+	Data       []map[string]float64 `json:"data" yaml:"data"`
+}
+
+type FixtureInsight struct {
+	Id   uuid.UUID `json:"id" yaml:"id"`
+	Text string    `json:"text" yaml:"text"`
+}
+
+type FixtureAudience struct {
+	Id                 uuid.UUID `json:"id" yaml:"id"`
+	Gender             string    `json:"gender" yaml:"gender"`
+	BirthCountry       string    `json:"birth_country" yaml:"birth_country"`
+	AgeGroup           string    `json:"age_group" yaml:"age_group"`
+	SocialMediaHours   float64   `json:"social_media_hours" yaml:"social_media_hours"`
+	PurchasesLastMonth int       `json:"purchases_last_month" yaml:"purchases_last_month"`
+}
+
+type FixtureFavourite struct {
+	Id          uuid.UUID `json:"id" yaml:"id"`
+	UserId      uuid.UUID `json:"user_id" yaml:"user_id"`
+	AssetId     uuid.UUID `json:"asset_id" yaml:"asset_id"`
+	AssetType   string    `json:"asset_type" yaml:"asset_type"`
+	Description string    `json:"description" yaml:"description"`
+	CreatedAt   time.Time `json:"created_at" yaml:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" yaml:"updated_at"`
+	// Rank can be left out, the favourites of a user are then ranked in the order of the file
+	Rank   string `json:"rank" yaml:"rank"`
+	Pinned bool   `json:"pinned" yaml:"pinned"`
+}
+
+// DefaultFixtures returns the dataset used by the dev environment.
+func DefaultFixtures() (*Fixtures, error) {
+	return parseFixtures(devFixtures, ".json")
+}
+
+// LoadFixtures reads a JSON or YAML fixtures file, picked by its extension, and validates it.
+func LoadFixtures(path string) (*Fixtures, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	fixtures, err := parseFixtures(data, filepath.Ext(path))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return fixtures, nil
+}
+
+func parseFixtures(data []byte, ext string) (*Fixtures, error) {
+	fixtures := Fixtures{}
+
+	switch ext {
+	case ".json":
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&fixtures); err != nil {
+			return nil, err
+		}
+	case ".yaml", ".yml":
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(&fixtures); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, ErrUnsupportedFixturesFormat
+	}
+
+	if err := fixtures.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &fixtures, nil
+}
+
+// Validate reports every missing id, duplicate and dangling reference at once.
+// The assets of the favourites are checked by ValidateAssets, once the asset types are known.
+func (f *Fixtures) Validate() error {
+	errs := []error{}
+	invalid := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%w: "+format, append([]any{ErrInvalidFixtures}, args...)...))
+	}
+
+	checkId := func(kind string, id uuid.UUID, seen map[uuid.UUID]bool) {
+		switch {
+		case id == uuid.Nil:
+			invalid("%s without id", kind)
+		case seen[id]:
+			invalid("duplicate %s %s", kind, id)
+		}
+		seen[id] = true
+	}
+
+	users := map[uuid.UUID]bool{}
+	emails := map[string]bool{}
+	for _, user := range f.Users {
+		checkId("user", user.Id, users)
+
+		if user.Email == "" || user.Password == "" {
+			invalid("user %s needs an email and a password", user.Id)
+		}
+		if emails[user.Email] {
+			invalid("duplicate user email %s", user.Email)
+		}
+		emails[user.Email] = true
+	}
+
+	charts := map[uuid.UUID]bool{}
+	for _, chart := range f.Charts {
+		checkId("chart", chart.Id, charts)
+	}
+	insights := map[uuid.UUID]bool{}
+	texts := map[string]bool{}
+	for _, insight := range f.Insights {
+		checkId("insight", insight.Id, insights)
+
+		// Insight texts are unique regardless of case, like in the storage
+		text := strings.ToLower(insight.Text)
+		if texts[text] {
+			invalid("duplicate insight text %q", insight.Text)
+		}
+		texts[text] = true
+	}
+	audiences := map[uuid.UUID]bool{}
+	for _, audience := range f.Audiences {
+		checkId("audience", audience.Id, audiences)
+
+		// Checked like the admin creates them, so the fixtures hold no audience the API would reject
+		characteristics := utils.AudienceCharacteristics{
+			Gender:             audience.Gender,
+			BirthCountry:       audience.BirthCountry,
+			AgeGroup:           audience.AgeGroup,
+			SocialMediaHours:   audience.SocialMediaHours,
+			PurchasesLastMonth: audience.PurchasesLastMonth,
+		}
+		fieldErrs := characteristics.FieldErrors("gender", "birth_country", "age_group", "social_media_hours", "purchases_last_month")
+		for _, field := range slices.Sorted(maps.Keys(fieldErrs)) {
+			invalid("audience %s %s %s", audience.Id, field, fieldErrs[field])
+		}
+	}
+
+	favourites := map[uuid.UUID]bool{}
+	favouritedAssets := map[[2]uuid.UUID]uuid.UUID{}
+	for _, favourite := range f.Favourites {
+		checkId("favourite", favourite.Id, favourites)
+
+		pair := [2]uuid.UUID{favourite.UserId, favourite.AssetId}
+		if other, found := favouritedAssets[pair]; found {
+			invalid("favourites %s and %s are both of user %s for %s %s",
+				other, favourite.Id, favourite.UserId, favourite.AssetType, favourite.AssetId)
+		} else {
+			favouritedAssets[pair] = favourite.Id
+		}
+
+		if !users[favourite.UserId] {
+			invalid("favourite %s points to missing user %s", favourite.Id, favourite.UserId)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// ValidateAssets reports every favourite whose asset type is not one of the given ones, the types
+// of the registered asset providers, or whose asset is not in the fixtures.
+func (f *Fixtures) ValidateAssets(assetTypes []string) error {
+	errs := []error{}
+
+	assets := f.assetIds()
+	for _, favourite := range f.Favourites {
+		switch {
+		case !slices.Contains(assetTypes, favourite.AssetType):
+			errs = append(errs, fmt.Errorf("%w: favourite %s has unknown asset type %q",
+				ErrInvalidFixtures, favourite.Id, favourite.AssetType))
+		case !assets[favourite.AssetType][favourite.AssetId]:
+			errs = append(errs, fmt.Errorf("%w: favourite %s points to missing %s %s",
+				ErrInvalidFixtures, favourite.Id, favourite.AssetType, favourite.AssetId))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// assetIds returns the ids of the assets the fixtures hold, by the asset type they are stored with.
+func (f *Fixtures) assetIds() map[string]map[uuid.UUID]bool {
+	result := map[string]map[uuid.UUID]bool{
+		"chart":    {},
+		"insight":  {},
+		"audience": {},
+	}
+	for _, chart := range f.Charts {
+		result["chart"][chart.Id] = true
+	}
+	for _, insight := range f.Insights {
+		result["insight"][insight.Id] = true
+	}
+	for _, audience := range f.Audiences {
+		result["audience"][audience.Id] = true
+	}
+
+	return result
+}
+
+// favouriteRanks returns the rank of every favourite, in order. A favourite without one is ranked
+// right after the favourite of the same user before it in the file.
+func (f *Fixtures) favouriteRanks() []string {
+	result := []string{}
+	previous := map[uuid.UUID]string{}
+
+	for _, favourite := range f.Favourites {
+		rank := favourite.Rank
+		if rank == "" {
+			rank = utils.RankBetween(previous[favourite.UserId], "")
+		}
+
+		previous[favourite.UserId] = rank
+		result = append(result, rank)
+	}
+
+	return result
+}
+
+// IMLoadFixtures writes the fixtures into the in-memory database, replacing items with the same id.
+func IMLoadFixtures(db *IMDatabase, fixtures *Fixtures, passwordHasher func(string) string) error {
+	errs := []error{}
+
+	for _, user := range fixtures.Users {
+		errs = append(errs, db.UserStorage.Set(user.Id, IMUserModel{
+			Id:       user.Id,
+			Email:    user.Email,
+			Password: passwordHasher(user.Password),
+			IsAdmin:  user.IsAdmin,
+		}))
+	}
+
+	for _, chart := range fixtures.Charts {
+		errs = append(errs, db.ChartStorage.Set(chart.Id, IMChartModel{
+			Id:         chart.Id,
+			Title:      chart.Title,
+			XAxisTitle: chart.XAxisTitle,
+			YAxisTitle: chart.YAxisTitle,
+			Data:       chart.Data,
+		}))
+	}
+
+	for _, insight := range fixtures.Insights {
+		errs = append(errs, db.InsightStorage.Set(insight.Id, IMInsightModel(insight)))
+	}
+
+	for _, audience := range fixtures.Audiences {
+		errs = append(errs, db.AudienceStorage.Set(audience.Id, IMAudienceModel(audience)))
+	}
+
+	ranks := fixtures.favouriteRanks()
+	for i, favourite := range fixtures.Favourites {
+		// Inserted so a second favourite of the same user and asset is rejected, like the unique index of PostgreSQL
+		_, err := db.FavouriteStorage.Insert(favourite.Id, IMFavouriteModel{
+			Id:          favourite.Id,
+			UserId:      favourite.UserId,
+			AssetId:     favourite.AssetId,
+			AssetType:   favourite.AssetType,
+			Description: favourite.Description,
+			Version:     1,
+			CreatedAt:   favourite.CreatedAt,
+			UpdatedAt:   favourite.UpdatedAt,
+			Rank:        ranks[i],
+			Pinned:      favourite.Pinned,
+		})
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,308 @@
+package database_test
+
+import (
+	"os"
+	"path/filepath"
+	"platform-go-challenge/internal/database"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFixturesFile(t *testing.T, name string, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+
+	return path
+}
+
+const yamlFixtures = `
+users:
+  - id: 7b4f9c2e-1d2a-4c55-9f0e-3a1b2c3d4e5f
+    email: qa@test.com
+    password: secret
+insights:
+  - id: 8c5a0d3f-2e3b-4d66-8a1f-4b2c3d4e5f60
+    text: an insight
+favourites:
+  - id: 9d6b1e4a-3f4c-4e77-9b2a-5c3d4e5f6071
+    user_id: 7b4f9c2e-1d2a-4c55-9f0e-3a1b2c3d4e5f
+    asset_id: 8c5a0d3f-2e3b-4d66-8a1f-4b2c3d4e5f60
+    asset_type: insight
+    description: qa favourite
+`
+
+func TestLoadFixtures(t *testing.T) {
+	t.Run("should load the default dev fixtures", func(t *testing.T) {
+		// Act
+		fixtures, err := database.DefaultFixtures()
+
+		// Assert
+		assert.NoError(t, err)
+		if assert.Len(t, fixtures.Users, 2) {
+			assert.False(t, fixtures.Users[0].IsAdmin)
+			assert.True(t, fixtures.Users[1].IsAdmin)
+		}
+		assert.Len(t, fixtures.Charts, 1)
+		assert.Len(t, fixtures.Insights, 2)
+		assert.Len(t, fixtures.Audiences, 1)
+		assert.Len(t, fixtures.Favourites, 3)
+	})
+
+	t.Run("should load a yaml file", func(t *testing.T) {
+		// Arrange
+		path := writeFixturesFile(t, "qa.yaml", yamlFixtures)
+
+		// Act
+		fixtures, err := database.LoadFixtures(path)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, "qa@test.com", fixtures.Users[0].Email)
+		assert.Equal(t, "an insight", fixtures.Insights[0].Text)
+		assert.Equal(t, "qa favourite", fixtures.Favourites[0].Description)
+	})
+
+	t.Run("should load a json file", func(t *testing.T) {
+		// Arrange
+		path := writeFixturesFile(t, "qa.json", `{"insights": [{"id": "8c5a0d3f-2e3b-4d66-8a1f-4b2c3d4e5f60", "text": "an insight"}]}`)
+
+		// Act
+		fixtures, err := database.LoadFixtures(path)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, "an insight", fixtures.Insights[0].Text)
+	})
+
+	t.Run("should reject unknown fields", func(t *testing.T) {
+		// Arrange
+		path := writeFixturesFile(t, "qa.json", `{"insights": [{"id": "8c5a0d3f-2e3b-4d66-8a1f-4b2c3d4e5f60", "txt": "typo"}]}`)
+
+		// Act
+		_, err := database.LoadFixtures(path)
+
+		// Assert
+		assert.ErrorContains(t, err, "txt")
+	})
+
+	t.Run("should reject unsupported file extensions", func(t *testing.T) {
+		// Arrange
+		path := writeFixturesFile(t, "qa.toml", "")
+
+		// Act
+		_, err := database.LoadFixtures(path)
+
+		// Assert
+		assert.ErrorIs(t, err, database.ErrUnsupportedFixturesFormat)
+	})
+}
+
+func TestValidateFixtures(t *testing.T) {
+	userId := uuid.New()
+	chartId := uuid.New()
+
+	t.Run("should report favourites pointing to missing users", func(t *testing.T) {
+		// Arrange
+		missingUserId := uuid.New()
+		fixtures := database.Fixtures{
+			Users:  []database.FixtureUser{{Id: userId, Email: "a@test.com", Password: "pass"}},
+			Charts: []database.FixtureChart{{Id: chartId}},
+			Favourites: []database.FixtureFavourite{
+				{Id: uuid.New(), UserId: missingUserId, AssetId: chartId, AssetType: "chart"},
+				{Id: uuid.New(), UserId: userId, AssetId: chartId, AssetType: "video"},
+			},
+		}
+
+		// Act
+		err := fixtures.Validate()
+
+		// Assert
+		assert.ErrorIs(t, err, database.ErrInvalidFixtures)
+		assert.ErrorContains(t, err, "missing user "+missingUserId.String())
+		assert.NotContains(t, err.Error(), "video")
+	})
+
+	t.Run("should report duplicate ids and emails", func(t *testing.T) {
+		// Arrange
+		fixtures := database.Fixtures{
+			Users: []database.FixtureUser{
+				{Id: userId, Email: "a@test.com", Password: "pass"},
+				{Id: userId, Email: "a@test.com", Password: "pass"},
+			},
+			Charts: []database.FixtureChart{{Id: chartId}, {Id: chartId}, {}},
+			Insights: []database.FixtureInsight{
+				{Id: uuid.New(), Text: "Most users log in on mobile"},
+				{Id: uuid.New(), Text: "most users log in on MOBILE"},
+			},
+		}
+
+		// Act
+		err := fixtures.Validate()
+
+		// Assert
+		assert.ErrorIs(t, err, database.ErrInvalidFixtures)
+		assert.ErrorContains(t, err, "duplicate user "+userId.String())
+		assert.ErrorContains(t, err, "duplicate user email a@test.com")
+		assert.ErrorContains(t, err, "duplicate chart "+chartId.String())
+		assert.ErrorContains(t, err, "chart without id")
+		assert.ErrorContains(t, err, `duplicate insight text "most users log in on MOBILE"`)
+	})
+
+	t.Run("should report two favourites of a user for the same asset", func(t *testing.T) {
+		// Arrange
+		first := uuid.New()
+		second := uuid.New()
+		fixtures := database.Fixtures{
+			Users:  []database.FixtureUser{{Id: userId, Email: "a@test.com", Password: "pass"}},
+			Charts: []database.FixtureChart{{Id: chartId}},
+			Favourites: []database.FixtureFavourite{
+				{Id: first, UserId: userId, AssetId: chartId, AssetType: "chart"},
+				{Id: second, UserId: userId, AssetId: chartId, AssetType: "chart"},
+			},
+		}
+
+		// Act
+		err := fixtures.Validate()
+
+		// Assert
+		assert.ErrorIs(t, err, database.ErrInvalidFixtures)
+		assert.ErrorContains(t, err, "favourites "+first.String()+" and "+second.String()+" are both of user "+userId.String())
+	})
+
+	t.Run("should report audiences the admin could not create", func(t *testing.T) {
+		// Arrange
+		audienceId := uuid.New()
+		fixtures := database.Fixtures{
+			Audiences: []database.FixtureAudience{{
+				Id:                 audienceId,
+				Gender:             "Male",
+				BirthCountry:       "United Kingdom",
+				AgeGroup:           "34-25",
+				SocialMediaHours:   1,
+				PurchasesLastMonth: -1,
+			}},
+		}
+
+		// Act
+		err := fixtures.Validate()
+
+		// Assert
+		assert.ErrorIs(t, err, database.ErrInvalidFixtures)
+		assert.ErrorContains(t, err, "audience "+audienceId.String()+" birth_country must be an ISO 3166-1 alpha-2 country code like GB")
+		assert.ErrorContains(t, err, "audience "+audienceId.String()+" age_group must be an age range")
+		assert.ErrorContains(t, err, "audience "+audienceId.String()+" purchases_last_month must be at least 0")
+		assert.NotContains(t, err.Error(), "gender")
+	})
+}
+
+func TestValidateFixtureAssets(t *testing.T) {
+	userId := uuid.New()
+	chartId := uuid.New()
+
+	t.Run("should report favourites pointing to missing assets", func(t *testing.T) {
+		// Arrange
+		missingInsightId := uuid.New()
+		fixtures := database.Fixtures{
+			Users:  []database.FixtureUser{{Id: userId, Email: "a@test.com", Password: "pass"}},
+			Charts: []database.FixtureChart{{Id: chartId}},
+			Favourites: []database.FixtureFavourite{
+				{Id: uuid.New(), UserId: userId, AssetId: missingInsightId, AssetType: "insight"},
+				{Id: uuid.New(), UserId: userId, AssetId: chartId, AssetType: "insight"},
+			},
+		}
+
+		// Act
+		err := fixtures.ValidateAssets([]string{"chart", "insight"})
+
+		// Assert
+		assert.ErrorIs(t, err, database.ErrInvalidFixtures)
+		assert.ErrorContains(t, err, "missing insight "+missingInsightId.String())
+		assert.ErrorContains(t, err, "missing insight "+chartId.String())
+	})
+
+	t.Run("should report asset types that are not given", func(t *testing.T) {
+		// Arrange
+		audienceId := uuid.New()
+		fixtures := database.Fixtures{
+			Users:     []database.FixtureUser{{Id: userId, Email: "a@test.com", Password: "pass"}},
+			Charts:    []database.FixtureChart{{Id: chartId}},
+			Audiences: []database.FixtureAudience{{Id: audienceId}},
+			Favourites: []database.FixtureFavourite{
+				{Id: uuid.New(), UserId: userId, AssetId: chartId, AssetType: "chart"},
+				{Id: uuid.New(), UserId: userId, AssetId: audienceId, AssetType: "audience"},
+				{Id: uuid.New(), UserId: userId, AssetId: chartId, AssetType: "video"},
+			},
+		}
+
+		// Act
+		err := fixtures.ValidateAssets([]string{"chart", "video"})
+
+		// Assert
+		assert.ErrorIs(t, err, database.ErrInvalidFixtures)
+		assert.ErrorContains(t, err, `unknown asset type "audience"`)
+		assert.ErrorContains(t, err, "missing video "+chartId.String())
+		assert.NotContains(t, err.Error(), `"chart"`)
+	})
+}
+
+func TestIMLoadFixtures(t *testing.T) {
+	t.Run("should store every fixture and hash the passwords", func(t *testing.T) {
+		// Arrange
+		db := database.NewIMDatabase()
+		fixtures, _ := database.DefaultFixtures()
+		hasher := func(password string) string { return "hashed:" + password }
+
+		// Act
+		err := database.IMLoadFixtures(db, fixtures, hasher)
+
+		// Assert
+		assert.NoError(t, err)
+		user, found := db.UserStorage.Get(fixtures.Users[0].Id)
+		assert.True(t, found)
+		assert.Equal(t, "hashed:pass", user.Password)
+		assert.Equal(t, 1, db.ChartStorage.Len())
+		assert.Equal(t, 2, db.InsightStorage.Len())
+		assert.Equal(t, 1, db.AudienceStorage.Len())
+		assert.Equal(t, 3, db.FavouriteStorage.Len())
+	})
+
+	t.Run("should return error when two favourites are of the same user and asset", func(t *testing.T) {
+		// Arrange
+		db := database.NewIMDatabase()
+		fixtures, _ := database.DefaultFixtures()
+		duplicate := fixtures.Favourites[0]
+		duplicate.Id = uuid.New()
+		fixtures.Favourites = append(fixtures.Favourites, duplicate)
+
+		// Act
+		err := database.IMLoadFixtures(db, fixtures, func(password string) string { return password })
+
+		// Assert
+		assert.ErrorIs(t, err, database.ErrItemAlreadyExists)
+		assert.Equal(t, 3, db.FavouriteStorage.Len())
+	})
+
+	t.Run("should rank the favourites without a rank after the previous favourite of their user", func(t *testing.T) {
+		// Arrange
+		db := database.NewIMDatabase()
+		fixtures, _ := database.DefaultFixtures()
+		fixtures.Favourites[1].Rank = "a"
+		fixtures.Favourites[1].Pinned = true
+
+		// Act
+		err := database.IMLoadFixtures(db, fixtures, func(password string) string { return password })
+
+		// Assert
+		assert.NoError(t, err)
+		ranked, _, _ := db.FavouriteStorage.Page(database.IMFavouritesByUserRankIndex, fixtures.Users[0].Id, 0, 10)
+		if assert.Len(t, ranked, 3) {
+			assert.Equal(t, fixtures.Favourites[1].Id, ranked[0].Id)
+			assert.Equal(t, fixtures.Favourites[0].Id, ranked[1].Id)
+			assert.Equal(t, fixtures.Favourites[2].Id, ranked[2].Id)
+			assert.Greater(t, ranked[2].Rank, "a")
+		}
+	})
+}
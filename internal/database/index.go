@@ -0,0 +1,335 @@
+package database
+
+import (
+	"bytes"
+	"maps"
+	"slices"
+
+	"github.com/google/uuid"
+)
+
+// IMIndex is kept up to date by the storage it is attached to on every write.
+type IMIndex[T any] interface {
+	indexName() string
+	build(items map[uuid.UUID]T)
+	insert(items map[uuid.UUID]T, id uuid.UUID, v T)
+	remove(items map[uuid.UUID]T, id uuid.UUID, v T)
+}
+
+// IMSortedIndex groups the ids of a storage by a partition key (e.g. the owner id)
+// and keeps every partition sorted, so a page can be read without scanning the storage.
+// Items that compare as equal are ordered by their id.
+type IMSortedIndex[T any] struct {
+	Name       string
+	partition  func(T) uuid.UUID
+	compare    func(a, b T) int
+	partitions map[uuid.UUID]uuid.UUIDs
+}
+
+// NewIMSortedIndex creates an index, a nil compare orders every partition by id.
+func NewIMSortedIndex[T any](name string, partition func(T) uuid.UUID, compare func(a, b T) int) *IMSortedIndex[T] {
+	if compare == nil {
+		compare = func(a, b T) int { return 0 }
+	}
+
+	return &IMSortedIndex[T]{
+		Name:       name,
+		partition:  partition,
+		compare:    compare,
+		partitions: map[uuid.UUID]uuid.UUIDs{},
+	}
+}
+
+func (idx *IMSortedIndex[T]) indexName() string {
+	return idx.Name
+}
+
+func (idx *IMSortedIndex[T]) compareEntries(a T, aId uuid.UUID, b T, bId uuid.UUID) int {
+	if result := idx.compare(a, b); result != 0 {
+		return result
+	}
+
+	return compareIds(aId, bId)
+}
+
+// search returns the position where the given entry is, or should be inserted.
+func (idx *IMSortedIndex[T]) search(items map[uuid.UUID]T, ids uuid.UUIDs, id uuid.UUID, v T) int {
+	position, _ := slices.BinarySearchFunc(ids, id, func(entryId uuid.UUID, target uuid.UUID) int {
+		return idx.compareEntries(items[entryId], entryId, v, target)
+	})
+
+	return position
+}
+
+func (idx *IMSortedIndex[T]) build(items map[uuid.UUID]T) {
+	idx.partitions = map[uuid.UUID]uuid.UUIDs{}
+
+	for id, v := range items {
+		key := idx.partition(v)
+		idx.partitions[key] = append(idx.partitions[key], id)
+	}
+
+	for _, ids := range idx.partitions {
+		slices.SortFunc(ids, func(a, b uuid.UUID) int {
+			return idx.compareEntries(items[a], a, items[b], b)
+		})
+	}
+}
+
+// insert expects the storage to not contain an entry for id in this index.
+func (idx *IMSortedIndex[T]) insert(items map[uuid.UUID]T, id uuid.UUID, v T) {
+	key := idx.partition(v)
+	ids := idx.partitions[key]
+	position := idx.search(items, ids, id, v)
+
+	idx.partitions[key] = slices.Insert(ids, position, id)
+}
+
+// remove expects items[id] to still hold the indexed value v.
+func (idx *IMSortedIndex[T]) remove(items map[uuid.UUID]T, id uuid.UUID, v T) {
+	key := idx.partition(v)
+	ids := idx.partitions[key]
+	position := idx.search(items, ids, id, v)
+
+	if position >= len(ids) || ids[position] != id {
+		return
+	}
+
+	ids = slices.Delete(ids, position, position+1)
+	if len(ids) == 0 {
+		delete(idx.partitions, key)
+		return
+	}
+
+	idx.partitions[key] = ids
+}
+
+// page returns a copy of the ids of a window of the matching partition entries, together with the number of matching entries.
+// A nil match keeps every entry, any other has to visit the whole partition to count them.
+func (idx *IMSortedIndex[T]) page(items map[uuid.UUID]T, key uuid.UUID, offset int, limit int, match func(T) bool) (uuid.UUIDs, int) {
+	ids := idx.partitions[key]
+	if match != nil {
+		ids = slices.DeleteFunc(slices.Clone(ids), func(id uuid.UUID) bool { return !match(items[id]) })
+	}
+
+	total := len(ids)
+
+	if offset >= total {
+		return uuid.UUIDs{}, total
+	}
+
+	end := min(offset+limit, total)
+
+	return slices.Clone(ids[offset:end]), total
+}
+
+// last returns the id of the last entry of a partition.
+func (idx *IMSortedIndex[T]) last(key uuid.UUID) (uuid.UUID, bool) {
+	ids := idx.partitions[key]
+	if len(ids) == 0 {
+		return uuid.Nil, false
+	}
+
+	return ids[len(ids)-1], true
+}
+
+// seek returns up to limit matching ids of a partition that come right after the entry (id, v) in the index order,
+// or right before it when backwards is set, together with whether the partition has more matching ids further in that direction.
+// The entry itself does not have to be stored anymore, and a nil match keeps every entry.
+func (idx *IMSortedIndex[T]) seek(items map[uuid.UUID]T, key uuid.UUID, id uuid.UUID, v T, limit int, backwards bool, match func(T) bool) (uuid.UUIDs, bool) {
+	ids := idx.partitions[key]
+	position := idx.search(items, ids, id, v)
+
+	candidates := ids[:position]
+	if !backwards {
+		if position < len(ids) && ids[position] == id {
+			position++
+		}
+		candidates = ids[position:]
+	}
+
+	// Walk away from the entry, the first match past the limit tells there are more
+	result := uuid.UUIDs{}
+	for i := range candidates {
+		candidate := candidates[i]
+		if backwards {
+			candidate = candidates[len(candidates)-1-i]
+		}
+
+		if match != nil && !match(items[candidate]) {
+			continue
+		}
+
+		if len(result) == limit {
+			if backwards {
+				slices.Reverse(result)
+			}
+			return result, true
+		}
+
+		result = append(result, candidate)
+	}
+
+	if backwards {
+		slices.Reverse(result)
+	}
+
+	return result, false
+}
+
+// IMUniqueIndex maps a key of every item to its id, so IMStorage.Insert can reject a second item with the same key.
+// Items written with Set are not checked, so the key of duplicates resolves to the smallest id. A persisted database
+// holding duplicates fails to open, like the unique indexes of PostgreSQL fail to be created.
+type IMUniqueIndex[T any] struct {
+	Name string
+	key  func(T) string
+	keys map[string]uuid.UUIDs
+}
+
+func NewIMUniqueIndex[T any](name string, key func(T) string) *IMUniqueIndex[T] {
+	return &IMUniqueIndex[T]{
+		Name: name,
+		key:  key,
+		keys: map[string]uuid.UUIDs{},
+	}
+}
+
+func (idx *IMUniqueIndex[T]) indexName() string {
+	return idx.Name
+}
+
+func compareIds(a, b uuid.UUID) int {
+	return bytes.Compare(a[:], b[:])
+}
+
+func (idx *IMUniqueIndex[T]) build(items map[uuid.UUID]T) {
+	idx.keys = map[string]uuid.UUIDs{}
+
+	for id, v := range items {
+		key := idx.key(v)
+		idx.keys[key] = append(idx.keys[key], id)
+	}
+
+	for _, ids := range idx.keys {
+		slices.SortFunc(ids, compareIds)
+	}
+}
+
+func (idx *IMUniqueIndex[T]) insert(items map[uuid.UUID]T, id uuid.UUID, v T) {
+	key := idx.key(v)
+	ids := idx.keys[key]
+	position, _ := slices.BinarySearchFunc(ids, id, compareIds)
+
+	idx.keys[key] = slices.Insert(ids, position, id)
+}
+
+func (idx *IMUniqueIndex[T]) remove(items map[uuid.UUID]T, id uuid.UUID, v T) {
+	key := idx.key(v)
+	ids := idx.keys[key]
+	position, found := slices.BinarySearchFunc(ids, id, compareIds)
+
+	if !found {
+		return
+	}
+
+	ids = slices.Delete(ids, position, position+1)
+	if len(ids) == 0 {
+		delete(idx.keys, key)
+		return
+	}
+
+	idx.keys[key] = ids
+}
+
+// lookup returns the id of the item that holds the key of v.
+func (idx *IMUniqueIndex[T]) lookup(v T) (uuid.UUID, bool) {
+	ids := idx.keys[idx.key(v)]
+	if len(ids) == 0 {
+		return uuid.Nil, false
+	}
+
+	return ids[0], true
+}
+
+// duplicate returns a key held by more than one item with the ids holding it.
+func (idx *IMUniqueIndex[T]) duplicate() (string, uuid.UUIDs, bool) {
+	for key, ids := range idx.keys {
+		if len(ids) > 1 {
+			return key, ids, true
+		}
+	}
+
+	return "", nil, false
+}
+
+// IMKeyCountIndex counts how many items of every partition hold each key, where an item holds any number of keys
+// (e.g. the tags of a favourite), so the keys of a partition can be listed without scanning the storage.
+// A key held more than once by the same item is counted once.
+type IMKeyCountIndex[T any] struct {
+	Name       string
+	partition  func(T) uuid.UUID
+	keys       func(T) []string
+	partitions map[uuid.UUID]map[string]int
+}
+
+func NewIMKeyCountIndex[T any](name string, partition func(T) uuid.UUID, keys func(T) []string) *IMKeyCountIndex[T] {
+	return &IMKeyCountIndex[T]{
+		Name:       name,
+		partition:  partition,
+		keys:       keys,
+		partitions: map[uuid.UUID]map[string]int{},
+	}
+}
+
+func (idx *IMKeyCountIndex[T]) indexName() string {
+	return idx.Name
+}
+
+func (idx *IMKeyCountIndex[T]) build(items map[uuid.UUID]T) {
+	idx.partitions = map[uuid.UUID]map[string]int{}
+
+	for id, v := range items {
+		idx.insert(items, id, v)
+	}
+}
+
+func (idx *IMKeyCountIndex[T]) insert(items map[uuid.UUID]T, id uuid.UUID, v T) {
+	key := idx.partition(v)
+	counts := idx.partitions[key]
+
+	for _, k := range idx.distinctKeys(v) {
+		if counts == nil {
+			counts = map[string]int{}
+			idx.partitions[key] = counts
+		}
+
+		counts[k]++
+	}
+}
+
+func (idx *IMKeyCountIndex[T]) remove(items map[uuid.UUID]T, id uuid.UUID, v T) {
+	key := idx.partition(v)
+	counts := idx.partitions[key]
+
+	for _, k := range idx.distinctKeys(v) {
+		if counts[k] <= 1 {
+			delete(counts, k)
+			continue
+		}
+
+		counts[k]--
+	}
+
+	if len(counts) == 0 {
+		delete(idx.partitions, key)
+	}
+}
+
+func (idx *IMKeyCountIndex[T]) distinctKeys(v T) []string {
+	return slices.Compact(slices.Sorted(slices.Values(idx.keys(v))))
+}
+
+// counts returns a copy of the counts of a partition.
+func (idx *IMKeyCountIndex[T]) counts(key uuid.UUID) map[string]int {
+	return maps.Clone(idx.partitions[key])
+}
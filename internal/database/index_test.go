@@ -0,0 +1,292 @@
+package database_test
+
+import (
+	"cmp"
+	"platform-go-challenge/internal/database"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func newDescriptionIndexedStorage(items map[uuid.UUID]database.IMFavouriteModel) *database.FavouriteStorage {
+	index := database.NewIMSortedIndex(
+		"by_description",
+		func(model database.IMFavouriteModel) uuid.UUID { return model.UserId },
+		func(a, b database.IMFavouriteModel) int { return cmp.Compare(a.Description, b.Description) },
+	)
+
+	return database.NewIMStorage(items, index)
+}
+
+func descriptions(models []database.IMFavouriteModel) []string {
+	result := []string{}
+	for _, model := range models {
+		result = append(result, model.Description)
+	}
+
+	return result
+}
+
+func TestIMSortedIndex(t *testing.T) {
+	userId := uuid.New()
+	otherUserId := uuid.New()
+
+	t.Run("should page a partition in index order", func(t *testing.T) {
+		// Arrange
+		storage := newDescriptionIndexedStorage(nil)
+		for _, description := range []string{"c", "a", "d", "b"} {
+			id := uuid.New()
+			storage.Set(id, database.IMFavouriteModel{Id: id, UserId: userId, Description: description})
+		}
+		otherId := uuid.New()
+		storage.Set(otherId, database.IMFavouriteModel{Id: otherId, UserId: otherUserId, Description: "0"})
+
+		// Act
+		firstPage, total, err := storage.Page("by_description", userId, 0, 3)
+		secondPage, _, _ := storage.Page("by_description", userId, 3, 3)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, 4, total)
+		assert.Equal(t, []string{"a", "b", "c"}, descriptions(firstPage))
+		assert.Equal(t, []string{"d"}, descriptions(secondPage))
+	})
+
+	t.Run("should build the index from the initial items", func(t *testing.T) {
+		// Arrange
+		id1 := uuid.New()
+		id2 := uuid.New()
+		storage := newDescriptionIndexedStorage(map[uuid.UUID]database.IMFavouriteModel{
+			id1: {Id: id1, UserId: userId, Description: "z"},
+			id2: {Id: id2, UserId: userId, Description: "y"},
+		})
+
+		// Act
+		result, total, err := storage.Page("by_description", userId, 0, 10)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, 2, total)
+		assert.Equal(t, []string{"y", "z"}, descriptions(result))
+	})
+
+	t.Run("should move an updated item and drop a deleted one", func(t *testing.T) {
+		// Arrange
+		id1 := uuid.New()
+		id2 := uuid.New()
+		id3 := uuid.New()
+		storage := newDescriptionIndexedStorage(map[uuid.UUID]database.IMFavouriteModel{
+			id1: {Id: id1, UserId: userId, Description: "a"},
+			id2: {Id: id2, UserId: userId, Description: "b"},
+			id3: {Id: id3, UserId: userId, Description: "c"},
+		})
+
+		// Act
+		storage.Set(id1, database.IMFavouriteModel{Id: id1, UserId: userId, Description: "d"})
+		storage.Delete(id2)
+		result, total, err := storage.Page("by_description", userId, 0, 10)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, 2, total)
+		assert.Equal(t, []string{"c", "d"}, descriptions(result))
+	})
+
+	t.Run("should order equal items by id", func(t *testing.T) {
+		// Arrange
+		storage := newDescriptionIndexedStorage(nil)
+		ids := uuid.UUIDs{}
+		for range 5 {
+			id := uuid.New()
+			ids = append(ids, id)
+			storage.Set(id, database.IMFavouriteModel{Id: id, UserId: userId, Description: "same"})
+		}
+
+		// Act
+		result, _, err := storage.Page("by_description", userId, 0, 10)
+
+		// Assert
+		assert.NoError(t, err)
+		for i := 1; i < len(result); i++ {
+			assert.Less(t, result[i-1].Id.String(), result[i].Id.String())
+		}
+	})
+
+	t.Run("should return empty page when offset is out of range", func(t *testing.T) {
+		// Arrange
+		storage := newDescriptionIndexedStorage(nil)
+
+		// Act
+		result, total, err := storage.Page("by_description", userId, 20, 10)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Empty(t, result)
+		assert.Equal(t, 0, total)
+	})
+
+	t.Run("should seek the items after and before a position", func(t *testing.T) {
+		// Arrange
+		storage := newDescriptionIndexedStorage(nil)
+		models := map[string]database.IMFavouriteModel{}
+		for _, description := range []string{"a", "b", "c", "d", "e"} {
+			id := uuid.New()
+			models[description] = database.IMFavouriteModel{Id: id, UserId: userId, Description: description}
+			storage.Set(id, models[description])
+		}
+
+		// Act
+		after, hasMoreAfter, err := storage.Seek("by_description", userId, models["b"].Id, models["b"], 2, false)
+		last, hasMoreLast, _ := storage.Seek("by_description", userId, models["c"].Id, models["c"], 2, false)
+		before, hasMoreBefore, _ := storage.Seek("by_description", userId, models["d"].Id, models["d"], 2, true)
+		first, hasMoreFirst, _ := storage.Seek("by_description", userId, models["c"].Id, models["c"], 2, true)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"c", "d"}, descriptions(after))
+		assert.True(t, hasMoreAfter)
+		assert.Equal(t, []string{"d", "e"}, descriptions(last))
+		assert.False(t, hasMoreLast)
+		assert.Equal(t, []string{"b", "c"}, descriptions(before))
+		assert.True(t, hasMoreBefore)
+		assert.Equal(t, []string{"a", "b"}, descriptions(first))
+		assert.False(t, hasMoreFirst)
+	})
+
+	t.Run("should seek from the position of a deleted item", func(t *testing.T) {
+		// Arrange
+		storage := newDescriptionIndexedStorage(nil)
+		var deleted database.IMFavouriteModel
+		for _, description := range []string{"a", "b", "c"} {
+			id := uuid.New()
+			storage.Set(id, database.IMFavouriteModel{Id: id, UserId: userId, Description: description})
+			if description == "b" {
+				deleted = database.IMFavouriteModel{Id: id, UserId: userId, Description: description}
+			}
+		}
+		storage.Delete(deleted.Id)
+
+		// Act
+		after, _, err := storage.Seek("by_description", userId, deleted.Id, deleted, 10, false)
+		before, _, _ := storage.Seek("by_description", userId, deleted.Id, deleted, 10, true)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"c"}, descriptions(after))
+		assert.Equal(t, []string{"a"}, descriptions(before))
+	})
+
+	t.Run("should page and seek only the matching items", func(t *testing.T) {
+		// Arrange
+		storage := newDescriptionIndexedStorage(nil)
+		models := map[string]database.IMFavouriteModel{}
+		for _, description := range []string{"a1", "b2", "c1", "d2", "e1", "f1"} {
+			id := uuid.New()
+			models[description] = database.IMFavouriteModel{Id: id, UserId: userId, Description: description}
+			storage.Set(id, models[description])
+		}
+		odd := func(model database.IMFavouriteModel) bool { return strings.HasSuffix(model.Description, "1") }
+
+		// Act
+		page, total, err := storage.PageWhere("by_description", userId, 1, 2, odd)
+		after, hasMoreAfter, _ := storage.SeekWhere("by_description", userId, models["b2"].Id, models["b2"], 2, false, odd)
+		before, hasMoreBefore, _ := storage.SeekWhere("by_description", userId, models["f1"].Id, models["f1"], 2, true, odd)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, 4, total)
+		assert.Equal(t, []string{"c1", "e1"}, descriptions(page))
+		assert.Equal(t, []string{"c1", "e1"}, descriptions(after))
+		assert.True(t, hasMoreAfter)
+		assert.Equal(t, []string{"c1", "e1"}, descriptions(before))
+		assert.True(t, hasMoreBefore)
+	})
+
+	t.Run("should return the last item of a partition", func(t *testing.T) {
+		// Arrange
+		storage := newDescriptionIndexedStorage(nil)
+		for _, description := range []string{"b", "c", "a"} {
+			id := uuid.New()
+			storage.Set(id, database.IMFavouriteModel{Id: id, UserId: userId, Description: description})
+		}
+
+		// Act
+		last, found, err := storage.Last("by_description", userId)
+		_, foundOther, otherErr := storage.Last("by_description", otherUserId)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.NoError(t, otherErr)
+		assert.True(t, found)
+		assert.Equal(t, "c", last.Description)
+		assert.False(t, foundOther)
+	})
+
+	t.Run("should return error when index does not exist", func(t *testing.T) {
+		// Arrange
+		storage := newDescriptionIndexedStorage(nil)
+
+		// Act
+		_, _, err := storage.Page("missing", userId, 0, 10)
+
+		// Assert
+		assert.ErrorIs(t, err, database.IMErrIndexNotFound)
+	})
+}
+
+func TestIMKeyCountIndex(t *testing.T) {
+	userId := uuid.New()
+	otherUserId := uuid.New()
+
+	t.Run("should count the keys of every partition as items are written", func(t *testing.T) {
+		// Arrange
+		storage := database.NewFavouriteStorage(nil)
+		id1 := uuid.New()
+		id2 := uuid.New()
+		id3 := uuid.New()
+		storage.Set(id1, database.IMFavouriteModel{Id: id1, UserId: userId, AssetId: uuid.New(), Tags: []string{"q2", "sales", "q2"}})
+		storage.Set(id2, database.IMFavouriteModel{Id: id2, UserId: userId, AssetId: uuid.New(), Tags: []string{"q2"}})
+		storage.Set(id3, database.IMFavouriteModel{Id: id3, UserId: otherUserId, AssetId: uuid.New(), Tags: []string{"q2"}})
+
+		// Act
+		storage.Set(id2, database.IMFavouriteModel{Id: id2, UserId: userId, AssetId: uuid.New(), Tags: []string{"q3"}})
+		storage.Delete(id1)
+		counts, err := storage.KeyCounts(database.IMFavouritesByUserTagIndex, userId)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]int{"q3": 1}, counts)
+	})
+
+	t.Run("should build the counts from the initial items", func(t *testing.T) {
+		// Arrange
+		id1 := uuid.New()
+		id2 := uuid.New()
+		storage := database.NewFavouriteStorage(map[uuid.UUID]database.IMFavouriteModel{
+			id1: {Id: id1, UserId: userId, Tags: []string{"q2", "sales"}},
+			id2: {Id: id2, UserId: userId, Tags: []string{"q2"}},
+		})
+
+		// Act
+		counts, err := storage.KeyCounts(database.IMFavouritesByUserTagIndex, userId)
+		otherCounts, _ := storage.KeyCounts(database.IMFavouritesByUserTagIndex, otherUserId)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]int{"q2": 2, "sales": 1}, counts)
+		assert.Empty(t, otherCounts)
+	})
+
+	t.Run("should return error for an index that does not count keys", func(t *testing.T) {
+		// Arrange
+		storage := database.NewFavouriteStorage(nil)
+
+		// Act
+		_, err := storage.KeyCounts(database.IMFavouritesByUserIndex, userId)
+
+		// Assert
+		assert.ErrorIs(t, err, database.IMErrIndexNotFound)
+	})
+}
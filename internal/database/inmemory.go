@@ -2,16 +2,61 @@ package database
 
 import (
 	"errors"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 )
 
-var IMErrItemNotFound = errors.New("Not Found")
+var (
+	// ErrItemNotFound is returned by every storage backend for a missing row
+	ErrItemNotFound = errors.New("Not Found")
+	// ErrItemAlreadyExists is returned by every storage backend when a write breaks a uniqueness constraint
+	ErrItemAlreadyExists = errors.New("Already Exists")
+	// ErrItemFavourited is returned when deleting an asset a favourite still points to
+	ErrItemFavourited  = errors.New("Favourited")
+	IMErrItemNotFound  = ErrItemNotFound
+	IMErrIndexNotFound = errors.New("Index Not Found")
+)
+
+const (
+	// IMFavouritesByUserIndex orders the favourites of every user by creation time
+	IMFavouritesByUserIndex              = "favourites_by_user"
+	IMFavouritesByUserCreatedAtDescIndex = "favourites_by_user_created_at_desc"
+	IMFavouritesByUserDescriptionIndex   = "favourites_by_user_description"
+	IMFavouritesByUserAssetTypeIndex     = "favourites_by_user_asset_type"
+	// IMFavouritesByUserRankIndex orders the favourites of every user as the user arranged them, pinned ones first
+	IMFavouritesByUserRankIndex  = "favourites_by_user_rank"
+	IMFavouritesByUserAssetIndex = "favourites_by_user_asset"
+	// IMFavouritesByAssetIndex holds the favourites of every asset, of any user
+	IMFavouritesByAssetIndex = "favourites_by_asset"
+	// IMFavouritesByUserTagIndex counts how many favourites of every user have each tag
+	IMFavouritesByUserTagIndex = "favourites_by_user_tag"
+	// IMTrashedFavouritesByUserIndex orders the trashed favourites of every user by when they were deleted, latest first
+	IMTrashedFavouritesByUserIndex = "trashed_favourites_by_user"
+	// IMTrashedFavouritesByDeletedAtIndex orders every trashed favourite by when it was deleted, in a single partition
+	IMTrashedFavouritesByDeletedAtIndex = "trashed_favourites_by_deleted_at"
+	// IMCollectionsByUserIndex orders the collections of every user by name
+	IMCollectionsByUserIndex     = "collections_by_user"
+	IMCollectionsByUserNameIndex = "collections_by_user_name"
+	// IMCollectionFavouritesByCollectionIndex orders the favourites of every collection by when they were added, then by favourite id
+	IMCollectionFavouritesByCollectionIndex          = "collection_favourites_by_collection"
+	IMCollectionFavouritesByFavouriteIndex           = "collection_favourites_by_favourite"
+	IMCollectionFavouritesByCollectionFavouriteIndex = "collection_favourites_by_collection_favourite"
+	// IMChartsByTitleIndex orders every chart by title, in a single partition
+	IMChartsByTitleIndex = "charts_by_title"
+	// IMInsightsByTextIndex orders every insight by text, in a single partition
+	IMInsightsByTextIndex      = "insights_by_text"
+	IMInsightsByLowerTextIndex = "insights_by_lower_text"
+	// IMAudiencesByCharacteristicsIndex orders every audience by birth country, age group and gender, in a single partition
+	IMAudiencesByCharacteristicsIndex = "audiences_by_characteristics"
+)
 
 type IMUserModel struct {
 	Id       uuid.UUID
 	Email    string
 	Password string
+	IsAdmin  bool
 }
 
 type IMInsightModel struct {
@@ -19,12 +64,36 @@ type IMInsightModel struct {
 	Text string
 }
 
+// IMChartModel holds a chart of schema version 2 in Kind, the axes and Series. The charts stored before,
+// with a SchemaVersion of 0 or 1, only have the titles of their axes and their x and y maps in Data.
 type IMChartModel struct {
-	Id         uuid.UUID
-	Title      string
-	XAxisTitle string
-	YAxisTitle string
-	Data       []map[string]float64
+	Id            uuid.UUID
+	SchemaVersion int
+	Title         string
+	Kind          string
+	XAxis         IMChartAxisModel
+	YAxis         IMChartAxisModel
+	Series        []IMChartSeriesModel
+	XAxisTitle    string
+	YAxisTitle    string
+	Data          []map[string]float64
+}
+
+type IMChartAxisModel struct {
+	Title string
+	Type  string
+	Unit  string
+}
+
+type IMChartSeriesModel struct {
+	Name   string
+	Points []IMChartPointModel
+}
+
+// IMChartPointModel has an X of float64 on a numeric axis and of string on a time or category one.
+type IMChartPointModel struct {
+	X any
+	Y float64
 }
 
 type IMAudienceModel struct {
@@ -42,42 +111,351 @@ type IMFavouriteModel struct {
 	AssetId     uuid.UUID
 	AssetType   string
 	Description string
+	// Version is increased on every update, favourites stored before versioning have version 0
+	Version int
+	// CreatedAt and UpdatedAt are zero for favourites stored before the timestamps existed
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	// Rank is the position of the favourite among the user's favourites, see utils.RankBetween.
+	// It is empty for favourites stored before ranks existed, which are listed first, ordered by id
+	Rank   string
+	Pinned bool
+	// Tags are normalised and sorted, they are nil for favourites stored before tags existed
+	Tags []string
+}
+
+// IMTrashedFavouriteModel is a deleted favourite, kept until it is restored or purged.
+type IMTrashedFavouriteModel struct {
+	Favourite IMFavouriteModel
+	DeletedAt time.Time
+}
+
+type IMCollectionModel struct {
+	Id          uuid.UUID
+	UserId      uuid.UUID
+	Name        string
+	Description string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// IMCollectionFavouriteModel puts a favourite in a collection, a favourite can be in any number of collections.
+type IMCollectionFavouriteModel struct {
+	Id           uuid.UUID
+	CollectionId uuid.UUID
+	FavouriteId  uuid.UUID
+	AddedAt      time.Time
 }
 
 type (
-	UserStorage      map[uuid.UUID]IMUserModel
-	ChartStorage     map[uuid.UUID]IMChartModel
-	InsightStorage   map[uuid.UUID]IMInsightModel
-	AudienceStorage  map[uuid.UUID]IMAudienceModel
-	FavouriteStorage map[uuid.UUID]IMFavouriteModel
+	UserStorage                = IMStorage[IMUserModel]
+	ChartStorage               = IMStorage[IMChartModel]
+	InsightStorage             = IMStorage[IMInsightModel]
+	AudienceStorage            = IMStorage[IMAudienceModel]
+	FavouriteStorage           = IMStorage[IMFavouriteModel]
+	TrashedFavouriteStorage    = IMStorage[IMTrashedFavouriteModel]
+	CollectionStorage          = IMStorage[IMCollectionModel]
+	CollectionFavouriteStorage = IMStorage[IMCollectionFavouriteModel]
 )
 
 type IMDatabase struct {
-	UserStorage      UserStorage
-	ChartStorage     ChartStorage
-	InsightStorage   InsightStorage
-	AudienceStorage  AudienceStorage
-	FavouriteStorage FavouriteStorage
+	UserStorage                *UserStorage
+	ChartStorage               *ChartStorage
+	InsightStorage             *InsightStorage
+	AudienceStorage            *AudienceStorage
+	FavouriteStorage           *FavouriteStorage
+	TrashedFavouriteStorage    *TrashedFavouriteStorage
+	CollectionStorage          *CollectionStorage
+	CollectionFavouriteStorage *CollectionFavouriteStorage
+	persistence                *imPersistence
 }
 
 func NewIMDatabase() *IMDatabase {
-	userStorage := UserStorage{}
-	chartStorage := ChartStorage{}
-	insighStorage := InsightStorage{}
-	audienceStorage := AudienceStorage{}
-	favouriteStorage := FavouriteStorage{}
+	userStorage := NewIMStorage[IMUserModel](nil)
+	chartStorage := NewChartStorage(nil)
+	insighStorage := NewInsightStorage(nil)
+	audienceStorage := NewAudienceStorage(nil)
+	favouriteStorage := NewFavouriteStorage(nil)
+	trashedFavouriteStorage := NewTrashedFavouriteStorage(nil)
+	collectionStorage := NewCollectionStorage(nil)
+	collectionFavouriteStorage := NewCollectionFavouriteStorage(nil)
 
 	return &IMDatabase{
-		UserStorage:      userStorage,
-		ChartStorage:     chartStorage,
-		InsightStorage:   insighStorage,
-		AudienceStorage:  audienceStorage,
-		FavouriteStorage: favouriteStorage,
+		UserStorage:                userStorage,
+		ChartStorage:               chartStorage,
+		InsightStorage:             insighStorage,
+		AudienceStorage:            audienceStorage,
+		FavouriteStorage:           favouriteStorage,
+		TrashedFavouriteStorage:    trashedFavouriteStorage,
+		CollectionStorage:          collectionStorage,
+		CollectionFavouriteStorage: collectionFavouriteStorage,
 	}
 }
 
-func IMStorageGetById[T any](id uuid.UUID, storage map[uuid.UUID]T) (*T, error) {
-	v, found := storage[id]
+// IsEmpty reports whether nothing has been stored yet, e.g. to seed a new database only once.
+func (db *IMDatabase) IsEmpty() bool {
+	return db.UserStorage.Len() == 0 &&
+		db.ChartStorage.Len() == 0 &&
+		db.InsightStorage.Len() == 0 &&
+		db.AudienceStorage.Len() == 0 &&
+		db.FavouriteStorage.Len() == 0 &&
+		db.TrashedFavouriteStorage.Len() == 0 &&
+		db.CollectionStorage.Len() == 0 &&
+		db.CollectionFavouriteStorage.Len() == 0
+}
+
+// RemoveFavouritesFromCollections takes the favourites out of every collection they are in,
+// which the foreign key of collection_favourites does in PostgreSQL.
+// It does nothing for a database built without a collection storage.
+func (db *IMDatabase) RemoveFavouritesFromCollections(favouriteIds ...uuid.UUID) error {
+	if db.CollectionFavouriteStorage == nil {
+		return nil
+	}
+
+	for _, id := range favouriteIds {
+		if _, err := db.CollectionFavouriteStorage.DeletePartition(IMCollectionFavouritesByFavouriteIndex, id); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// IMDeleteUnlessFavourited deletes an asset and fails with ErrItemFavourited while a favourite points to it.
+// The favourites stay locked until the asset is gone, so none is added in between, and the two storages
+// are locked in the order Snapshot locks them.
+func IMDeleteUnlessFavourited[T any](db *IMDatabase, assets *IMStorage[T], id uuid.UUID) (bool, error) {
+	favourites := db.FavouriteStorage
+	if favourites == nil {
+		return assets.Delete(id)
+	}
+
+	deleteUnlessFavourited := func(deleteAsset func() (bool, error)) (bool, error) {
+		favourites.mu.RLock()
+		defer favourites.mu.RUnlock()
+
+		if favourites.partitionLen(IMFavouritesByAssetIndex, id) > 0 {
+			return false, ErrItemFavourited
+		}
+
+		return deleteAsset()
+	}
+
+	name := ""
+	for storageName, storage := range db.persistentStorages() {
+		if storage == imPersistentStorage(assets) {
+			name = storageName
+		}
+	}
+
+	if name > "favourites" {
+		return deleteUnlessFavourited(func() (bool, error) { return assets.Delete(id) })
+	}
+
+	deleted := false
+	err := assets.Batch(func(batch *IMBatch[T]) error {
+		var err error
+		deleted, err = deleteUnlessFavourited(func() (bool, error) {
+			err := batch.DeleteIf(id, func(T) error { return nil })
+			if errors.Is(err, ErrItemNotFound) {
+				return false, nil
+			}
+
+			return err == nil, err
+		})
+
+		return err
+	})
+
+	return deleted, err
+}
+
+// dropStrayCollectionFavourites takes out of the collections the entries whose collection or favourite no longer exists,
+// which a crash between deleting either and taking it out of its collections leaves behind.
+func (db *IMDatabase) dropStrayCollectionFavourites() error {
+	for _, model := range db.CollectionFavouriteStorage.Values() {
+		_, collectionFound := db.CollectionStorage.Get(model.CollectionId)
+		_, favouriteFound := db.FavouriteStorage.Get(model.FavouriteId)
+		if collectionFound && favouriteFound {
+			continue
+		}
+
+		if _, err := db.CollectionFavouriteStorage.Delete(model.Id); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// NewChartStorage creates the chart storage with an index of every chart by title for the admin listing.
+func NewChartStorage(items map[uuid.UUID]IMChartModel) *ChartStorage {
+	byTitle := NewIMSortedIndex(
+		IMChartsByTitleIndex,
+		func(model IMChartModel) uuid.UUID { return uuid.Nil },
+		func(a, b IMChartModel) int { return strings.Compare(a.Title, b.Title) },
+	)
+
+	return NewIMStorage(items, byTitle)
+}
+
+// NewInsightStorage creates the insight storage with an index of every insight by text for the admin listing,
+// and a unique index on the text that ignores case.
+func NewInsightStorage(items map[uuid.UUID]IMInsightModel) *InsightStorage {
+	byText := NewIMSortedIndex(
+		IMInsightsByTextIndex,
+		func(model IMInsightModel) uuid.UUID { return uuid.Nil },
+		func(a, b IMInsightModel) int { return strings.Compare(a.Text, b.Text) },
+	)
+	byLowerText := NewIMUniqueIndex(
+		IMInsightsByLowerTextIndex,
+		func(model IMInsightModel) string { return strings.ToLower(model.Text) },
+	)
+
+	return NewIMStorage(items, byText, byLowerText)
+}
+
+// NewAudienceStorage creates the audience storage with an index of every audience by its characteristics for the admin listing.
+func NewAudienceStorage(items map[uuid.UUID]IMAudienceModel) *AudienceStorage {
+	byCharacteristics := NewIMSortedIndex(
+		IMAudiencesByCharacteristicsIndex,
+		func(model IMAudienceModel) uuid.UUID { return uuid.Nil },
+		func(a, b IMAudienceModel) int {
+			if result := strings.Compare(a.BirthCountry, b.BirthCountry); result != 0 {
+				return result
+			}
+			if result := strings.Compare(a.AgeGroup, b.AgeGroup); result != 0 {
+				return result
+			}
+
+			return strings.Compare(a.Gender, b.Gender)
+		},
+	)
+
+	return NewIMStorage(items, byCharacteristics)
+}
+
+// NewFavouriteStorage creates the favourite storage with an index of every user's favourites for each listing order,
+// a unique index on the (user, asset) pair, an index of every asset's favourites and a count of every user's tags.
+func NewFavouriteStorage(items map[uuid.UUID]IMFavouriteModel) *FavouriteStorage {
+	byUser := func(model IMFavouriteModel) uuid.UUID { return model.UserId }
+
+	byUserCreatedAt := NewIMSortedIndex(
+		IMFavouritesByUserIndex,
+		byUser,
+		func(a, b IMFavouriteModel) int { return a.CreatedAt.Compare(b.CreatedAt) },
+	)
+	byUserCreatedAtDesc := NewIMSortedIndex(
+		IMFavouritesByUserCreatedAtDescIndex,
+		byUser,
+		func(a, b IMFavouriteModel) int { return b.CreatedAt.Compare(a.CreatedAt) },
+	)
+	byUserDescription := NewIMSortedIndex(
+		IMFavouritesByUserDescriptionIndex,
+		byUser,
+		func(a, b IMFavouriteModel) int { return strings.Compare(a.Description, b.Description) },
+	)
+	byUserAssetType := NewIMSortedIndex(
+		IMFavouritesByUserAssetTypeIndex,
+		byUser,
+		func(a, b IMFavouriteModel) int { return strings.Compare(a.AssetType, b.AssetType) },
+	)
+	byUserRank := NewIMSortedIndex(
+		IMFavouritesByUserRankIndex,
+		byUser,
+		func(a, b IMFavouriteModel) int {
+			if a.Pinned != b.Pinned {
+				if a.Pinned {
+					return -1
+				}
+				return 1
+			}
+
+			return strings.Compare(a.Rank, b.Rank)
+		},
+	)
+	byUserAsset := NewIMUniqueIndex(
+		IMFavouritesByUserAssetIndex,
+		func(model IMFavouriteModel) string { return model.UserId.String() + "/" + model.AssetId.String() },
+	)
+	byAsset := NewIMSortedIndex(
+		IMFavouritesByAssetIndex,
+		func(model IMFavouriteModel) uuid.UUID { return model.AssetId },
+		func(a, b IMFavouriteModel) int { return 0 },
+	)
+	byUserTag := NewIMKeyCountIndex(
+		IMFavouritesByUserTagIndex,
+		byUser,
+		func(model IMFavouriteModel) []string { return model.Tags },
+	)
+
+	return NewIMStorage(items, byUserCreatedAt, byUserCreatedAtDesc, byUserDescription, byUserAssetType, byUserRank, byUserAsset, byAsset, byUserTag)
+}
+
+// NewTrashedFavouriteStorage creates the storage of the deleted favourites with an index of every user's trash,
+// latest deleted first, and an index of the whole trash by deletion time for purging it.
+func NewTrashedFavouriteStorage(items map[uuid.UUID]IMTrashedFavouriteModel) *TrashedFavouriteStorage {
+	byUser := NewIMSortedIndex(
+		IMTrashedFavouritesByUserIndex,
+		func(model IMTrashedFavouriteModel) uuid.UUID { return model.Favourite.UserId },
+		func(a, b IMTrashedFavouriteModel) int { return b.DeletedAt.Compare(a.DeletedAt) },
+	)
+	byDeletedAt := NewIMSortedIndex(
+		IMTrashedFavouritesByDeletedAtIndex,
+		func(model IMTrashedFavouriteModel) uuid.UUID { return uuid.Nil },
+		func(a, b IMTrashedFavouriteModel) int { return a.DeletedAt.Compare(b.DeletedAt) },
+	)
+
+	return NewIMStorage(items, byUser, byDeletedAt)
+}
+
+// NewCollectionStorage creates the collection storage with an index of every user's collections by name,
+// and a unique index on the (user, name) pair that ignores case.
+func NewCollectionStorage(items map[uuid.UUID]IMCollectionModel) *CollectionStorage {
+	byUser := NewIMSortedIndex(
+		IMCollectionsByUserIndex,
+		func(model IMCollectionModel) uuid.UUID { return model.UserId },
+		func(a, b IMCollectionModel) int { return strings.Compare(a.Name, b.Name) },
+	)
+	byUserName := NewIMUniqueIndex(
+		IMCollectionsByUserNameIndex,
+		func(model IMCollectionModel) string { return model.UserId.String() + "/" + strings.ToLower(model.Name) },
+	)
+
+	return NewIMStorage(items, byUser, byUserName)
+}
+
+// NewCollectionFavouriteStorage creates the storage of the favourites put in collections, with an index of every collection's
+// favourites by when they were added, an index of the collections of every favourite and a unique index on the (collection, favourite) pair.
+func NewCollectionFavouriteStorage(items map[uuid.UUID]IMCollectionFavouriteModel) *CollectionFavouriteStorage {
+	byCollection := NewIMSortedIndex(
+		IMCollectionFavouritesByCollectionIndex,
+		func(model IMCollectionFavouriteModel) uuid.UUID { return model.CollectionId },
+		func(a, b IMCollectionFavouriteModel) int {
+			if result := a.AddedAt.Compare(b.AddedAt); result != 0 {
+				return result
+			}
+
+			return compareIds(a.FavouriteId, b.FavouriteId)
+		},
+	)
+	byFavourite := NewIMSortedIndex(
+		IMCollectionFavouritesByFavouriteIndex,
+		func(model IMCollectionFavouriteModel) uuid.UUID { return model.FavouriteId },
+		nil,
+	)
+	byCollectionFavourite := NewIMUniqueIndex(
+		IMCollectionFavouritesByCollectionFavouriteIndex,
+		func(model IMCollectionFavouriteModel) string {
+			return model.CollectionId.String() + "/" + model.FavouriteId.String()
+		},
+	)
+
+	return NewIMStorage(items, byCollection, byFavourite, byCollectionFavourite)
+}
+
+func IMStorageGetById[T any](id uuid.UUID, storage *IMStorage[T]) (*T, error) {
+	v, found := storage.Get(id)
 
 	if !found {
 		return nil, IMErrItemNotFound
@@ -0,0 +1,40 @@
+CREATE TABLE users (
+	id UUID PRIMARY KEY,
+	email TEXT NOT NULL UNIQUE,
+	password TEXT NOT NULL
+);
+
+CREATE TABLE charts (
+	id UUID PRIMARY KEY,
+	title TEXT NOT NULL,
+	x_axis_title TEXT NOT NULL,
+	y_axis_title TEXT NOT NULL,
+	data JSONB NOT NULL DEFAULT '[]'
+);
+
+CREATE TABLE insights (
+	id UUID PRIMARY KEY,
+	text TEXT NOT NULL
+);
+
+CREATE TABLE audiences (
+	id UUID PRIMARY KEY,
+	gender TEXT NOT NULL,
+	birth_country TEXT NOT NULL,
+	age_group TEXT NOT NULL,
+	social_media_hours DOUBLE PRECISION NOT NULL,
+	purchases_last_month INTEGER NOT NULL
+);
+
+-- asset_id points to charts, insights or audiences depending on asset_type,
+-- so it cannot have a foreign key.
+CREATE TABLE favourites (
+	id UUID PRIMARY KEY,
+	user_id UUID NOT NULL,
+	asset_id UUID NOT NULL,
+	asset_type TEXT NOT NULL,
+	description TEXT NOT NULL
+);
+
+-- Serves the paginated listing of a user's favourites ordered by id.
+CREATE INDEX favourites_user_id_id_idx ON favourites (user_id, id);
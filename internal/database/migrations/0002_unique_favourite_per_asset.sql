@@ -0,0 +1,23 @@
+-- A user can only favourite an asset once. Duplicates created before this constraint are not
+-- removed, since their descriptions would be lost: the migration fails listing them instead,
+-- so they can be merged by hand before it is run again.
+DO $$
+DECLARE
+	duplicates TEXT;
+BEGIN
+	SELECT string_agg(format('user %s and asset %s (favourites %s)', user_id, asset_id, ids), '; ')
+	INTO duplicates
+	FROM (
+		SELECT user_id, asset_id, string_agg(id::text, ', ' ORDER BY id) AS ids
+		FROM favourites
+		GROUP BY user_id, asset_id
+		HAVING count(*) > 1
+	) grouped;
+
+	IF duplicates IS NOT NULL THEN
+		RAISE EXCEPTION 'A user can only favourite an asset once, merge the duplicate favourites of %', duplicates;
+	END IF;
+END
+$$;
+
+CREATE UNIQUE INDEX favourites_user_id_asset_id_idx ON favourites (user_id, asset_id);
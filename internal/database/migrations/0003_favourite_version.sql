@@ -0,0 +1,2 @@
+-- Increased on every update, so concurrent writers can detect each other.
+ALTER TABLE favourites ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,14 @@
+-- Favourites stored before the timestamps existed get the time of the migration.
+ALTER TABLE favourites
+	ADD COLUMN created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	ADD COLUMN updated_at TIMESTAMPTZ NOT NULL DEFAULT now();
+
+-- Serve the sorted listings of a user's favourites, ties are ordered by id like in the in-memory indexes.
+-- Text is compared byte-wise so the order does not depend on the collation of the database.
+CREATE INDEX favourites_user_id_created_at_idx ON favourites (user_id, created_at, id);
+CREATE INDEX favourites_user_id_created_at_desc_idx ON favourites (user_id, created_at DESC, id);
+CREATE INDEX favourites_user_id_description_idx ON favourites (user_id, description COLLATE "C", id);
+CREATE INDEX favourites_user_id_asset_type_idx ON favourites (user_id, asset_type COLLATE "C", id);
+
+-- The listing is no longer ordered by id.
+DROP INDEX favourites_user_id_id_idx;
@@ -0,0 +1,22 @@
+-- The position of a favourite in the order its user arranged, see utils.RankBetween.
+ALTER TABLE favourites
+	ADD COLUMN rank TEXT NOT NULL DEFAULT '',
+	ADD COLUMN pinned BOOLEAN NOT NULL DEFAULT false;
+
+-- Favourites stored before ranks existed keep their creation order. Lowercase hex digits are rank digits
+-- in the same byte order, and the last digit is never zero.
+UPDATE favourites ranked
+SET rank = numbered.rank
+FROM (
+	SELECT id, lpad(to_hex(row_number() OVER (PARTITION BY user_id ORDER BY created_at, id)), 12, '0') || 'V' AS rank
+	FROM favourites
+) numbered
+WHERE ranked.id = numbered.id;
+
+ALTER TABLE favourites ALTER COLUMN rank DROP DEFAULT;
+
+-- Pinned favourites come first, folding both into one key lets the rank listing seek like the other orders.
+ALTER TABLE favourites
+	ADD COLUMN position TEXT GENERATED ALWAYS AS ((CASE WHEN pinned THEN '0' ELSE '1' END) || rank) STORED;
+
+CREATE INDEX favourites_user_id_position_idx ON favourites (user_id, position COLLATE "C", id);
@@ -0,0 +1,25 @@
+-- Collections group a user's favourites under a name, unique per user regardless of case.
+CREATE TABLE collections (
+	id UUID PRIMARY KEY,
+	user_id UUID NOT NULL,
+	name TEXT NOT NULL,
+	description TEXT NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL,
+	updated_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE UNIQUE INDEX collections_user_id_lower_name_idx ON collections (user_id, lower(name));
+-- Serves the listing of a user's collections, ordered by name like the in-memory index.
+CREATE INDEX collections_user_id_name_idx ON collections (user_id, name COLLATE "C", id);
+
+-- Deleting a collection or a favourite takes the favourite out of the collection, the favourite itself is kept.
+CREATE TABLE collection_favourites (
+	collection_id UUID NOT NULL REFERENCES collections (id) ON DELETE CASCADE,
+	favourite_id UUID NOT NULL REFERENCES favourites (id) ON DELETE CASCADE,
+	added_at TIMESTAMPTZ NOT NULL,
+	PRIMARY KEY (collection_id, favourite_id)
+);
+
+-- Serves the listing of a collection's favourites and the cascade from favourites.
+CREATE INDEX collection_favourites_collection_id_added_at_idx ON collection_favourites (collection_id, added_at, favourite_id);
+CREATE INDEX collection_favourites_favourite_id_idx ON collection_favourites (favourite_id);
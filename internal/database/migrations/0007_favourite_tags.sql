@@ -0,0 +1,31 @@
+-- The normalised tags of a favourite, read together with the favourite.
+ALTER TABLE favourites ADD COLUMN tags TEXT[] NOT NULL DEFAULT '{}';
+
+-- Every tag of every favourite, keyed by user so the tag filters and the most used tags of a user
+-- are read from the index instead of from all of the user's favourites.
+CREATE TABLE favourite_tags (
+	user_id UUID NOT NULL,
+	tag TEXT COLLATE "C" NOT NULL,
+	favourite_id UUID NOT NULL REFERENCES favourites (id) ON DELETE CASCADE,
+	PRIMARY KEY (user_id, tag, favourite_id)
+);
+
+CREATE INDEX favourite_tags_favourite_id_idx ON favourite_tags (favourite_id);
+
+-- favourite_tags follows favourites.tags on every write, deleting a favourite removes its tags through the foreign key.
+CREATE FUNCTION favourite_tags_sync() RETURNS trigger AS $$
+BEGIN
+	DELETE FROM favourite_tags WHERE favourite_id = NEW.id;
+	INSERT INTO favourite_tags (user_id, tag, favourite_id)
+	SELECT DISTINCT NEW.user_id, tag, NEW.id FROM unnest(NEW.tags) AS tag;
+
+	RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER favourites_tags_insert AFTER INSERT ON favourites
+	FOR EACH ROW EXECUTE FUNCTION favourite_tags_sync();
+
+CREATE TRIGGER favourites_tags_update AFTER UPDATE OF user_id, tags ON favourites
+	FOR EACH ROW WHEN (OLD.tags IS DISTINCT FROM NEW.tags OR OLD.user_id <> NEW.user_id)
+	EXECUTE FUNCTION favourite_tags_sync();
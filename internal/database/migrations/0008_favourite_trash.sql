@@ -0,0 +1,21 @@
+-- Deleted favourites are moved here with the time they were deleted, until they are restored or purged.
+-- A user may trash several favourites of the same asset over time, so the (user, asset) pair is not unique here.
+CREATE TABLE trashed_favourites (
+	id UUID PRIMARY KEY,
+	user_id UUID NOT NULL,
+	asset_id UUID NOT NULL,
+	asset_type TEXT NOT NULL,
+	description TEXT NOT NULL,
+	version INTEGER NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL,
+	updated_at TIMESTAMPTZ NOT NULL,
+	rank TEXT NOT NULL,
+	pinned BOOLEAN NOT NULL,
+	tags TEXT[] NOT NULL,
+	deleted_at TIMESTAMPTZ NOT NULL
+);
+
+-- Serves the listing of a user's trash, latest deleted first like the in-memory index.
+CREATE INDEX trashed_favourites_user_id_deleted_at_idx ON trashed_favourites (user_id, deleted_at DESC, id);
+-- Serves the purge of the expired favourites.
+CREATE INDEX trashed_favourites_deleted_at_idx ON trashed_favourites (deleted_at);
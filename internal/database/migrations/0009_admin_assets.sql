@@ -0,0 +1,5 @@
+-- Admins manage the assets through the admin endpoints.
+ALTER TABLE users ADD COLUMN is_admin BOOLEAN NOT NULL DEFAULT false;
+
+-- Serves the admin listing of the charts by title.
+CREATE INDEX charts_title_idx ON charts (title COLLATE "C", id);
@@ -0,0 +1,3 @@
+-- Insight texts are unique regardless of case, and the admin listing orders them by text.
+CREATE UNIQUE INDEX insights_lower_text_idx ON insights (lower(text));
+CREATE INDEX insights_text_idx ON insights (text COLLATE "C", id);
@@ -0,0 +1,2 @@
+-- Serves the admin listing of the audiences, ordered by their characteristics like the in-memory index.
+CREATE INDEX audiences_characteristics_idx ON audiences (birth_country COLLATE "C", age_group COLLATE "C", gender COLLATE "C", id);
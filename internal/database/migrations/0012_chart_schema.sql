@@ -0,0 +1,9 @@
+-- Charts of schema version 2 have a kind, typed axes and named series. The rows of schema version 1 keep
+-- their x and y maps in data and are converted when they are read; x_axis_title and y_axis_title stay
+-- filled for every row.
+ALTER TABLE charts
+	ADD COLUMN schema_version INTEGER NOT NULL DEFAULT 1,
+	ADD COLUMN kind TEXT NOT NULL DEFAULT 'line',
+	ADD COLUMN x_axis JSONB,
+	ADD COLUMN y_axis JSONB,
+	ADD COLUMN series JSONB NOT NULL DEFAULT '[]';
@@ -0,0 +1,2 @@
+-- Serves the check for favourites of an asset before the asset is deleted.
+CREATE INDEX favourites_asset_id_idx ON favourites (asset_id);
@@ -0,0 +1,532 @@
+package database
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"log"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Persistence for the in-memory database.
+//
+// Every write is appended to a write-ahead log segment (wal-<first seq>.log) and synced to disk
+// before it is applied in memory. Each record is framed as:
+//
+//	[4 bytes payload length][4 bytes CRC-32C of payload][JSON payload]
+//
+// so a record torn by a crash is detected on startup and cut off the end of the log.
+// A snapshot (snapshot.json) stores every storage together with the last sequence it includes,
+// after it is written the older log segments are removed.
+// Startup loads the snapshot and replays the log records that came after it.
+
+var (
+	IMErrCorruptLog     = errors.New("Corrupt write-ahead log")
+	IMErrNotPersistent  = errors.New("Database is not persistent")
+	imCRCTable          = crc32.MakeTable(crc32.Castagnoli)
+	imSegmentNamePrefix = "wal-"
+	imSegmentNameSuffix = ".log"
+	imSnapshotFileName  = "snapshot.json"
+)
+
+const imFrameHeaderSize = 8
+
+type imOperation string
+
+const (
+	imOperationSet    imOperation = "set"
+	imOperationDelete imOperation = "delete"
+	// imOperationBatch holds the writes of an IMStorage.Batch in one record, so they are replayed all or not at all
+	imOperationBatch imOperation = "batch"
+)
+
+type imJournal interface {
+	append(storage string, op imOperation, id uuid.UUID, value any) error
+}
+
+type imPersistentStorage interface {
+	lock()
+	unlock()
+	attach(name string, journal imJournal)
+	dump() any
+	restore(raw json.RawMessage) error
+	replay(op imOperation, id uuid.UUID, raw json.RawMessage) error
+	checkDuplicates() error
+}
+
+type imLogRecord struct {
+	Seq     uint64          `json:"seq"`
+	Storage string          `json:"storage"`
+	Op      imOperation     `json:"op"`
+	Id      uuid.UUID       `json:"id"`
+	Value   json.RawMessage `json:"value,omitempty"`
+}
+
+type imBatchRecord struct {
+	Op    imOperation `json:"op"`
+	Id    uuid.UUID   `json:"id"`
+	Value any         `json:"value,omitempty"`
+}
+
+type imSnapshot struct {
+	Seq      uint64                     `json:"seq"`
+	Storages map[string]json.RawMessage `json:"storages"`
+}
+
+type imPersistence struct {
+	dir        string
+	mu         sync.Mutex
+	snapshotMu sync.Mutex
+	seq        uint64
+	segment    *os.File
+	offset     int64
+}
+
+// NewPersistentIMDatabase restores the database kept in dir and records every following write there.
+func NewPersistentIMDatabase(dir string) (*IMDatabase, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	db := NewIMDatabase()
+	storages := db.persistentStorages()
+
+	seq, err := imRestoreSnapshot(dir, storages)
+	if err != nil {
+		return nil, err
+	}
+
+	seq, err = imReplaySegments(dir, storages, seq)
+	if err != nil {
+		return nil, err
+	}
+
+	// Items written before the unique indexes existed may share a key, they have to be merged by hand
+	for _, name := range slices.Sorted(maps.Keys(storages)) {
+		if err := storages[name].checkDuplicates(); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+	}
+
+	if err := db.dropStrayCollectionFavourites(); err != nil {
+		return nil, err
+	}
+
+	persistence := &imPersistence{dir: dir, seq: seq}
+	if err := persistence.openSegment(); err != nil {
+		return nil, err
+	}
+
+	for name, storage := range storages {
+		storage.attach(name, persistence)
+	}
+	db.persistence = persistence
+
+	return db, nil
+}
+
+// persistentStorages returns the storages by the name used for them on disk.
+func (db *IMDatabase) persistentStorages() map[string]imPersistentStorage {
+	return map[string]imPersistentStorage{
+		"users":                 db.UserStorage,
+		"charts":                db.ChartStorage,
+		"insights":              db.InsightStorage,
+		"audiences":             db.AudienceStorage,
+		"favourites":            db.FavouriteStorage,
+		"trashed_favourites":    db.TrashedFavouriteStorage,
+		"collections":           db.CollectionStorage,
+		"collection_favourites": db.CollectionFavouriteStorage,
+	}
+}
+
+// Snapshot writes every storage to disk and drops the log segments it makes redundant.
+func (db *IMDatabase) Snapshot() error {
+	persistence := db.persistence
+	if persistence == nil {
+		return IMErrNotPersistent
+	}
+
+	persistence.snapshotMu.Lock()
+	defer persistence.snapshotMu.Unlock()
+
+	storages := db.persistentStorages()
+	names := slices.Sorted(maps.Keys(storages))
+
+	// Writers hold their storage lock while appending to the log,
+	// so with every storage locked the copies match the log position exactly.
+	for _, name := range names {
+		storages[name].lock()
+	}
+
+	dumps := map[string]any{}
+	for _, name := range names {
+		dumps[name] = storages[name].dump()
+	}
+
+	seq, err := persistence.rotate()
+
+	for _, name := range names {
+		storages[name].unlock()
+	}
+
+	if err != nil {
+		return err
+	}
+
+	snapshot := imSnapshot{Seq: seq, Storages: map[string]json.RawMessage{}}
+	for name, dump := range dumps {
+		raw, err := json.Marshal(dump)
+		if err != nil {
+			return err
+		}
+		snapshot.Storages[name] = raw
+	}
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+
+	if err := imWriteFileAtomic(filepath.Join(persistence.dir, imSnapshotFileName), data); err != nil {
+		return err
+	}
+
+	return imRemoveSegmentsBefore(persistence.dir, seq+1)
+}
+
+// StartSnapshots takes a snapshot on every interval until the returned stop function is called,
+// which returns once a snapshot in progress is written.
+func (db *IMDatabase) StartSnapshots(interval time.Duration) func() {
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+
+	go func() {
+		defer close(stopped)
+		for {
+			select {
+			case <-ticker.C:
+				if err := db.Snapshot(); err != nil {
+					log.Printf("Could not snapshot database: %s", err)
+				}
+			case <-done:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
+// Close releases the open log segment, the database must not be written afterwards.
+func (db *IMDatabase) Close() error {
+	if db.persistence == nil {
+		return nil
+	}
+
+	db.persistence.mu.Lock()
+	defer db.persistence.mu.Unlock()
+
+	return db.persistence.segment.Close()
+}
+
+func (p *imPersistence) append(storage string, op imOperation, id uuid.UUID, value any) error {
+	record := imLogRecord{Storage: storage, Op: op, Id: id}
+
+	if value != nil {
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		record.Value = raw
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	record.Seq = p.seq + 1
+
+	payload, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	frame := make([]byte, imFrameHeaderSize, imFrameHeaderSize+len(payload))
+	binary.BigEndian.PutUint32(frame[0:4], uint32(len(payload)))
+	binary.BigEndian.PutUint32(frame[4:8], crc32.Checksum(payload, imCRCTable))
+	frame = append(frame, payload...)
+
+	if _, err := p.segment.WriteAt(frame, p.offset); err != nil {
+		// Do not leave a partial frame in front of the next record
+		p.segment.Truncate(p.offset)
+		return err
+	}
+
+	if err := p.segment.Sync(); err != nil {
+		p.segment.Truncate(p.offset)
+		return err
+	}
+
+	p.offset += int64(len(frame))
+	p.seq = record.Seq
+
+	return nil
+}
+
+// openSegment opens the segment that starts after the current sequence, must be called with mu held.
+func (p *imPersistence) openSegment() error {
+	path := filepath.Join(p.dir, imSegmentName(p.seq+1))
+
+	segment, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+
+	info, err := segment.Stat()
+	if err != nil {
+		segment.Close()
+		return err
+	}
+
+	p.segment = segment
+	p.offset = info.Size()
+
+	return imSyncDir(p.dir)
+}
+
+// rotate starts a new segment and returns the last sequence written to the previous ones.
+func (p *imPersistence) rotate() (uint64, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := p.segment.Close(); err != nil {
+		return 0, err
+	}
+
+	if err := p.openSegment(); err != nil {
+		return 0, err
+	}
+
+	return p.seq, nil
+}
+
+func imSegmentName(firstSeq uint64) string {
+	return fmt.Sprintf("%s%020d%s", imSegmentNamePrefix, firstSeq, imSegmentNameSuffix)
+}
+
+// imListSegments returns the segment paths ordered by their first sequence.
+func imListSegments(dir string) ([]string, []uint64, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		paths     []string
+		firstSeqs []uint64
+	)
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasPrefix(name, imSegmentNamePrefix) || !strings.HasSuffix(name, imSegmentNameSuffix) {
+			continue
+		}
+
+		firstSeq, err := strconv.ParseUint(strings.TrimSuffix(strings.TrimPrefix(name, imSegmentNamePrefix), imSegmentNameSuffix), 10, 64)
+		if err != nil {
+			continue
+		}
+
+		// Names are zero padded so the directory order is the sequence order
+		paths = append(paths, filepath.Join(dir, name))
+		firstSeqs = append(firstSeqs, firstSeq)
+	}
+
+	return paths, firstSeqs, nil
+}
+
+func imRemoveSegmentsBefore(dir string, firstSeq uint64) error {
+	paths, firstSeqs, err := imListSegments(dir)
+	if err != nil {
+		return err
+	}
+
+	for i, path := range paths {
+		if firstSeqs[i] >= firstSeq {
+			continue
+		}
+
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+	}
+
+	return imSyncDir(dir)
+}
+
+func imRestoreSnapshot(dir string, storages map[string]imPersistentStorage) (uint64, error) {
+	data, err := os.ReadFile(filepath.Join(dir, imSnapshotFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	var snapshot imSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return 0, fmt.Errorf("could not read snapshot: %w", err)
+	}
+
+	for name, raw := range snapshot.Storages {
+		storage, found := storages[name]
+		if !found {
+			return 0, fmt.Errorf("snapshot has unknown storage %q", name)
+		}
+
+		if err := storage.restore(raw); err != nil {
+			return 0, fmt.Errorf("could not restore %s: %w", name, err)
+		}
+	}
+
+	return snapshot.Seq, nil
+}
+
+// imReplaySegments applies the records after seq and returns the last applied sequence.
+// A torn record at the end of the last segment is what a crash mid-write leaves behind, it is truncated.
+func imReplaySegments(dir string, storages map[string]imPersistentStorage, seq uint64) (uint64, error) {
+	paths, _, err := imListSegments(dir)
+	if err != nil {
+		return 0, err
+	}
+
+	for i, path := range paths {
+		records, validSize, torn, err := imReadSegment(path)
+		if err != nil {
+			return 0, err
+		}
+
+		if torn {
+			if i != len(paths)-1 {
+				return 0, fmt.Errorf("%w: %s", IMErrCorruptLog, path)
+			}
+
+			log.Printf("Truncating torn write at the end of %s", path)
+			if err := os.Truncate(path, validSize); err != nil {
+				return 0, err
+			}
+		}
+
+		for _, record := range records {
+			if record.Seq <= seq {
+				continue
+			}
+
+			storage, found := storages[record.Storage]
+			if !found {
+				return 0, fmt.Errorf("%w: unknown storage %q", IMErrCorruptLog, record.Storage)
+			}
+
+			if err := storage.replay(record.Op, record.Id, record.Value); err != nil {
+				return 0, fmt.Errorf("%w: %s", IMErrCorruptLog, err)
+			}
+
+			seq = record.Seq
+		}
+	}
+
+	return seq, nil
+}
+
+// imReadSegment returns the complete records of a segment and the size they span.
+func imReadSegment(path string) ([]imLogRecord, int64, bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, 0, false, err
+	}
+
+	records := []imLogRecord{}
+	offset := 0
+
+	for offset < len(data) {
+		if len(data)-offset < imFrameHeaderSize {
+			return records, int64(offset), true, nil
+		}
+
+		size := int(binary.BigEndian.Uint32(data[offset : offset+4]))
+		checksum := binary.BigEndian.Uint32(data[offset+4 : offset+8])
+		start := offset + imFrameHeaderSize
+
+		if len(data)-start < size {
+			return records, int64(offset), true, nil
+		}
+
+		payload := data[start : start+size]
+		if crc32.Checksum(payload, imCRCTable) != checksum {
+			return records, int64(offset), true, nil
+		}
+
+		var record imLogRecord
+		if err := json.Unmarshal(payload, &record); err != nil {
+			return nil, 0, false, fmt.Errorf("%w: %s", IMErrCorruptLog, err)
+		}
+
+		records = append(records, record)
+		offset = start + size
+	}
+
+	return records, int64(offset), false, nil
+}
+
+func imWriteFileAtomic(path string, data []byte) error {
+	tmpPath := path + ".tmp"
+
+	file, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		return err
+	}
+
+	return imSyncDir(filepath.Dir(path))
+}
+
+func imSyncDir(dir string) error {
+	file, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return file.Sync()
+}
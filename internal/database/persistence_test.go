@@ -0,0 +1,277 @@
+package database_test
+
+import (
+	"os"
+	"path/filepath"
+	"platform-go-challenge/internal/database"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func openPersistentDB(t *testing.T, dir string) *database.IMDatabase {
+	db, err := database.NewPersistentIMDatabase(dir)
+	require.NoError(t, err)
+
+	return db
+}
+
+func lastSegment(t *testing.T, dir string) string {
+	segments, err := filepath.Glob(filepath.Join(dir, "wal-*.log"))
+	require.NoError(t, err)
+	require.NotEmpty(t, segments)
+
+	return segments[len(segments)-1]
+}
+
+func TestPersistentIMDatabase(t *testing.T) {
+	t.Run("should restore writes and deletes after reopening", func(t *testing.T) {
+		// Arrange
+		dir := t.TempDir()
+		kept := database.IMInsightModel{Id: uuid.New(), Text: "kept"}
+		deleted := database.IMInsightModel{Id: uuid.New(), Text: "deleted"}
+		userId := uuid.New()
+		fav := database.IMFavouriteModel{Id: uuid.New(), UserId: userId, AssetId: kept.Id, AssetType: "insight"}
+
+		db := openPersistentDB(t, dir)
+		require.NoError(t, db.InsightStorage.Set(kept.Id, kept))
+		require.NoError(t, db.InsightStorage.Set(deleted.Id, deleted))
+		require.NoError(t, db.FavouriteStorage.Set(fav.Id, fav))
+		_, err := db.InsightStorage.Delete(deleted.Id)
+		require.NoError(t, err)
+		require.NoError(t, db.Close())
+
+		// Act
+		reopened := openPersistentDB(t, dir)
+		defer reopened.Close()
+
+		// Assert
+		result, found := reopened.InsightStorage.Get(kept.Id)
+		assert.True(t, found)
+		assert.Equal(t, kept, result)
+
+		_, found = reopened.InsightStorage.Get(deleted.Id)
+		assert.False(t, found)
+
+		favourites, total, err := reopened.FavouriteStorage.Page(database.IMFavouritesByUserIndex, userId, 0, 10)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, total)
+		assert.Equal(t, []database.IMFavouriteModel{fav}, favourites)
+	})
+
+	t.Run("should restore the writes of a batch and nothing of a rolled back one", func(t *testing.T) {
+		// Arrange
+		dir := t.TempDir()
+		removed := database.IMInsightModel{Id: uuid.New(), Text: "removed"}
+		added := database.IMInsightModel{Id: uuid.New(), Text: "added"}
+		rolledBack := database.IMInsightModel{Id: uuid.New(), Text: "rolled back"}
+
+		db := openPersistentDB(t, dir)
+		require.NoError(t, db.InsightStorage.Set(removed.Id, removed))
+		require.NoError(t, db.InsightStorage.Batch(func(batch *database.IMBatch[database.IMInsightModel]) error {
+			if _, err := batch.Insert(added.Id, added); err != nil {
+				return err
+			}
+			return batch.DeleteIf(removed.Id, func(database.IMInsightModel) error { return nil })
+		}))
+		require.Error(t, db.InsightStorage.Batch(func(batch *database.IMBatch[database.IMInsightModel]) error {
+			batch.Insert(rolledBack.Id, rolledBack)
+			return database.IMErrItemNotFound
+		}))
+		require.NoError(t, db.Close())
+
+		// Act
+		reopened := openPersistentDB(t, dir)
+		defer reopened.Close()
+
+		// Assert
+		assert.Equal(t, []database.IMInsightModel{added}, reopened.InsightStorage.Values())
+	})
+
+	t.Run("should restore a favourite removed from every collection", func(t *testing.T) {
+		// Arrange
+		dir := t.TempDir()
+		favouriteId := uuid.New()
+		kept := database.IMCollectionFavouriteModel{Id: uuid.New(), CollectionId: uuid.New(), FavouriteId: uuid.New()}
+
+		db := openPersistentDB(t, dir)
+		for range 2 {
+			id := uuid.New()
+			require.NoError(t, db.CollectionFavouriteStorage.Set(id, database.IMCollectionFavouriteModel{
+				Id: id, CollectionId: uuid.New(), FavouriteId: favouriteId,
+			}))
+		}
+		require.NoError(t, db.CollectionStorage.Set(kept.CollectionId, database.IMCollectionModel{Id: kept.CollectionId}))
+		require.NoError(t, db.FavouriteStorage.Set(kept.FavouriteId, database.IMFavouriteModel{Id: kept.FavouriteId}))
+		require.NoError(t, db.CollectionFavouriteStorage.Set(kept.Id, kept))
+		require.NoError(t, db.RemoveFavouritesFromCollections(favouriteId))
+		require.NoError(t, db.Close())
+
+		// Act
+		reopened := openPersistentDB(t, dir)
+		defer reopened.Close()
+
+		// Assert
+		assert.Equal(t, []database.IMCollectionFavouriteModel{kept}, reopened.CollectionFavouriteStorage.Values())
+	})
+
+	t.Run("should drop the collection entries of a collection or favourite deleted before a crash", func(t *testing.T) {
+		// Arrange
+		dir := t.TempDir()
+		collection := database.IMCollectionModel{Id: uuid.New(), UserId: uuid.New(), Name: "kept"}
+		fav := database.IMFavouriteModel{Id: uuid.New(), UserId: collection.UserId}
+		kept := database.IMCollectionFavouriteModel{Id: uuid.New(), CollectionId: collection.Id, FavouriteId: fav.Id}
+		ofDeletedCollection := database.IMCollectionFavouriteModel{Id: uuid.New(), CollectionId: uuid.New(), FavouriteId: fav.Id}
+		ofDeletedFavourite := database.IMCollectionFavouriteModel{Id: uuid.New(), CollectionId: collection.Id, FavouriteId: uuid.New()}
+
+		db := openPersistentDB(t, dir)
+		require.NoError(t, db.CollectionStorage.Set(collection.Id, collection))
+		require.NoError(t, db.FavouriteStorage.Set(fav.Id, fav))
+		for _, model := range []database.IMCollectionFavouriteModel{kept, ofDeletedCollection, ofDeletedFavourite} {
+			require.NoError(t, db.CollectionFavouriteStorage.Set(model.Id, model))
+		}
+		require.NoError(t, db.Close())
+
+		// Act
+		reopened := openPersistentDB(t, dir)
+		defer reopened.Close()
+
+		// Assert
+		assert.Equal(t, []database.IMCollectionFavouriteModel{kept}, reopened.CollectionFavouriteStorage.Values())
+	})
+
+	t.Run("should restore from snapshot and the writes after it", func(t *testing.T) {
+		// Arrange
+		dir := t.TempDir()
+		beforeSnapshot := database.IMInsightModel{Id: uuid.New(), Text: "before"}
+		afterSnapshot := database.IMInsightModel{Id: uuid.New(), Text: "after"}
+
+		db := openPersistentDB(t, dir)
+		require.NoError(t, db.InsightStorage.Set(beforeSnapshot.Id, beforeSnapshot))
+		require.NoError(t, db.Snapshot())
+		require.NoError(t, db.InsightStorage.Set(afterSnapshot.Id, afterSnapshot))
+		require.NoError(t, db.Close())
+		segments, _ := filepath.Glob(filepath.Join(dir, "wal-*.log"))
+
+		// Act
+		reopened := openPersistentDB(t, dir)
+		defer reopened.Close()
+
+		// Assert
+		assert.Len(t, segments, 1)
+		assert.FileExists(t, filepath.Join(dir, "snapshot.json"))
+		assert.Equal(t, 2, reopened.InsightStorage.Len())
+		_, found := reopened.InsightStorage.Get(afterSnapshot.Id)
+		assert.True(t, found)
+	})
+
+	t.Run("should drop a torn record at the end of the log and keep writing", func(t *testing.T) {
+		// Arrange
+		dir := t.TempDir()
+		complete := database.IMInsightModel{Id: uuid.New(), Text: "complete"}
+		afterCrash := database.IMInsightModel{Id: uuid.New(), Text: "after crash"}
+
+		db := openPersistentDB(t, dir)
+		require.NoError(t, db.InsightStorage.Set(complete.Id, complete))
+		require.NoError(t, db.Close())
+
+		// Simulate a crash in the middle of writing the next frame
+		segment, err := os.OpenFile(lastSegment(t, dir), os.O_APPEND|os.O_WRONLY, 0o644)
+		require.NoError(t, err)
+		_, err = segment.Write([]byte{0, 0, 0, 200, 1, 2, 3, 4, '{', '"'})
+		require.NoError(t, err)
+		require.NoError(t, segment.Close())
+
+		// Act
+		reopened := openPersistentDB(t, dir)
+		require.NoError(t, reopened.InsightStorage.Set(afterCrash.Id, afterCrash))
+		require.NoError(t, reopened.Close())
+		final := openPersistentDB(t, dir)
+		defer final.Close()
+
+		// Assert
+		assert.Equal(t, 2, final.InsightStorage.Len())
+		_, found := final.InsightStorage.Get(afterCrash.Id)
+		assert.True(t, found)
+	})
+
+	t.Run("should drop a record with a bad checksum at the end of the log", func(t *testing.T) {
+		// Arrange
+		dir := t.TempDir()
+		first := database.IMInsightModel{Id: uuid.New(), Text: "first"}
+		second := database.IMInsightModel{Id: uuid.New(), Text: "second"}
+
+		db := openPersistentDB(t, dir)
+		require.NoError(t, db.InsightStorage.Set(first.Id, first))
+		require.NoError(t, db.InsightStorage.Set(second.Id, second))
+		require.NoError(t, db.Close())
+
+		path := lastSegment(t, dir)
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		data[len(data)-2] ^= 0xff
+		require.NoError(t, os.WriteFile(path, data, 0o644))
+
+		// Act
+		reopened := openPersistentDB(t, dir)
+		defer reopened.Close()
+
+		// Assert
+		assert.Equal(t, 1, reopened.InsightStorage.Len())
+		_, found := reopened.InsightStorage.Get(first.Id)
+		assert.True(t, found)
+	})
+
+	t.Run("should return error when snapshotting a database that is not persistent", func(t *testing.T) {
+		// Arrange
+		db := database.NewIMDatabase()
+
+		// Act
+		err := db.Snapshot()
+
+		// Assert
+		assert.ErrorIs(t, err, database.IMErrNotPersistent)
+	})
+
+	t.Run("should take no snapshot once the snapshots are stopped", func(t *testing.T) {
+		// Arrange
+		dir := t.TempDir()
+		db := openPersistentDB(t, dir)
+		defer db.Close()
+		stop := db.StartSnapshots(time.Millisecond)
+		time.Sleep(20 * time.Millisecond)
+
+		// Act
+		stop()
+		require.NoError(t, os.Remove(filepath.Join(dir, "snapshot.json")))
+		time.Sleep(20 * time.Millisecond)
+
+		// Assert
+		_, err := os.Stat(filepath.Join(dir, "snapshot.json"))
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+	t.Run("should return error when reopening a database holding two favourites of a user for the same asset", func(t *testing.T) {
+		// Arrange
+		dir := t.TempDir()
+		userId := uuid.New()
+		assetId := uuid.New()
+		first := database.IMFavouriteModel{Id: uuid.New(), UserId: userId, AssetId: assetId, AssetType: "chart"}
+		second := database.IMFavouriteModel{Id: uuid.New(), UserId: userId, AssetId: assetId, AssetType: "chart"}
+
+		db := openPersistentDB(t, dir)
+		require.NoError(t, db.FavouriteStorage.Set(first.Id, first))
+		require.NoError(t, db.FavouriteStorage.Set(second.Id, second))
+		require.NoError(t, db.Close())
+
+		// Act
+		_, err := database.NewPersistentIMDatabase(dir)
+
+		// Assert
+		assert.ErrorIs(t, err, database.ErrItemAlreadyExists)
+		assert.ErrorContains(t, err, first.Id.String())
+		assert.ErrorContains(t, err, second.Id.String())
+	})
+}
@@ -0,0 +1,182 @@
+package database
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+//go:embed migrations/*.sql
+var pgMigrations embed.FS
+
+// Arbitrary key so only one instance applies migrations at a time.
+const pgMigrationsLockKey = 7_351_020_250
+
+func NewPostgresDB(url string) (*pgxpool.Pool, error) {
+	config, err := pgxpool.ParseConfig(url)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewPostgresDBWithConfig(config)
+}
+
+func NewPostgresDBWithConfig(config *pgxpool.Config) (*pgxpool.Pool, error) {
+	pool, err := pgxpool.NewWithConfig(context.Background(), config)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := pool.Ping(context.Background()); err != nil {
+		pool.Close()
+		return nil, err
+	}
+
+	return pool, nil
+}
+
+// PGMigrate applies the embedded migrations that have not been applied yet, in file name order.
+func PGMigrate(pool *pgxpool.Pool) error {
+	ctx := context.Background()
+
+	_, err := pool.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version TEXT PRIMARY KEY,
+			applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+		)
+	`)
+	if err != nil {
+		return err
+	}
+
+	names, err := fs.Glob(pgMigrations, "migrations/*.sql")
+	if err != nil {
+		return err
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		if err := pgApplyMigration(ctx, pool, name); err != nil {
+			return fmt.Errorf("migration %s failed: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+func pgApplyMigration(ctx context.Context, pool *pgxpool.Pool, name string) error {
+	version := strings.TrimSuffix(strings.TrimPrefix(name, "migrations/"), ".sql")
+
+	script, err := pgMigrations.ReadFile(name)
+	if err != nil {
+		return err
+	}
+
+	return pgx.BeginFunc(ctx, pool, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, "SELECT pg_advisory_xact_lock($1)", pgMigrationsLockKey); err != nil {
+			return err
+		}
+
+		var applied bool
+		err := tx.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM schema_migrations WHERE version = $1)", version).Scan(&applied)
+		if err != nil || applied {
+			return err
+		}
+
+		if _, err := tx.Exec(ctx, string(script)); err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(ctx, "INSERT INTO schema_migrations (version) VALUES ($1)", version)
+		return err
+	})
+}
+
+// PGItemNotFound turns pgx's no rows error into ErrItemNotFound.
+func PGItemNotFound(err error) error {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrItemNotFound
+	}
+
+	return err
+}
+
+// PGDeleteUnlessFavourited deletes an asset from its table and fails with ErrItemFavourited while a favourite points to it.
+// The check and the delete are a single statement, like IMDeleteUnlessFavourited holds the favourites locked.
+func PGDeleteUnlessFavourited(pool *pgxpool.Pool, table string, id uuid.UUID) (bool, error) {
+	query := fmt.Sprintf(`
+		WITH favourited AS (
+			SELECT EXISTS (SELECT 1 FROM favourites WHERE asset_id = $1) AS favourited
+		), deleted AS (
+			DELETE FROM %s WHERE id = $1 AND NOT (SELECT favourited FROM favourited) RETURNING id
+		)
+		SELECT (SELECT favourited FROM favourited), EXISTS (SELECT 1 FROM deleted)`, table)
+
+	var favourited, deleted bool
+	if err := pool.QueryRow(context.Background(), query, id).Scan(&favourited, &deleted); err != nil {
+		return false, err
+	}
+
+	if favourited {
+		return false, ErrItemFavourited
+	}
+
+	return deleted, nil
+}
+
+// PGLoadFixtures inserts the fixtures in a single transaction, rows that already exist are left untouched.
+func PGLoadFixtures(pool *pgxpool.Pool, fixtures *Fixtures, passwordHasher func(string) string) error {
+	batch := &pgx.Batch{}
+
+	for _, user := range fixtures.Users {
+		batch.Queue(
+			"INSERT INTO users (id, email, password, is_admin) VALUES ($1, $2, $3, $4) ON CONFLICT DO NOTHING",
+			user.Id, user.Email, passwordHasher(user.Password), user.IsAdmin,
+		)
+	}
+
+	for _, chart := range fixtures.Charts {
+		batch.Queue(
+			"INSERT INTO charts (id, title, x_axis_title, y_axis_title, data) VALUES ($1, $2, $3, $4, $5) ON CONFLICT DO NOTHING",
+			chart.Id, chart.Title, chart.XAxisTitle, chart.YAxisTitle, chart.Data,
+		)
+	}
+
+	for _, insight := range fixtures.Insights {
+		batch.Queue(
+			"INSERT INTO insights (id, text) VALUES ($1, $2) ON CONFLICT DO NOTHING",
+			insight.Id, insight.Text,
+		)
+	}
+
+	for _, audience := range fixtures.Audiences {
+		batch.Queue(
+			`INSERT INTO audiences (id, gender, birth_country, age_group, social_media_hours, purchases_last_month)
+			VALUES ($1, $2, $3, $4, $5, $6) ON CONFLICT DO NOTHING`,
+			audience.Id, audience.Gender, audience.BirthCountry, audience.AgeGroup,
+			audience.SocialMediaHours, audience.PurchasesLastMonth,
+		)
+	}
+
+	ranks := fixtures.favouriteRanks()
+	for i, favourite := range fixtures.Favourites {
+		batch.Queue(
+			`INSERT INTO favourites (id, user_id, asset_id, asset_type, description, created_at, updated_at, rank, pinned)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) ON CONFLICT DO NOTHING`,
+			favourite.Id, favourite.UserId, favourite.AssetId, favourite.AssetType, favourite.Description,
+			favourite.CreatedAt, favourite.UpdatedAt, ranks[i], favourite.Pinned,
+		)
+	}
+
+	return pgx.BeginFunc(context.Background(), pool, func(tx pgx.Tx) error {
+		return tx.SendBatch(context.Background(), batch).Close()
+	})
+}
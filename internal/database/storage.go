@@ -0,0 +1,551 @@
+package database
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// IMStorage is an in-memory table keyed by id.
+// Every access goes through a RWMutex so it can be shared between request goroutines.
+// When a journal is attached every write is recorded in it before being applied.
+type IMStorage[T any] struct {
+	mu      sync.RWMutex
+	items   map[uuid.UUID]T
+	indexes map[string]IMIndex[T]
+	name    string
+	journal imJournal
+}
+
+func NewIMStorage[T any](items map[uuid.UUID]T, indexes ...IMIndex[T]) *IMStorage[T] {
+	if items == nil {
+		items = map[uuid.UUID]T{}
+	}
+
+	storage := &IMStorage[T]{
+		items:   items,
+		indexes: map[string]IMIndex[T]{},
+	}
+
+	for _, index := range indexes {
+		index.build(items)
+		storage.indexes[index.indexName()] = index
+	}
+
+	return storage
+}
+
+func (s *IMStorage[T]) Get(id uuid.UUID) (T, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	v, found := s.items[id]
+
+	return v, found
+}
+
+// GetMany returns the items for the given ids in the same order, skipping the missing ones.
+func (s *IMStorage[T]) GetMany(ids uuid.UUIDs) []T {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := []T{}
+	for _, id := range ids {
+		v, found := s.items[id]
+		if !found {
+			continue
+		}
+
+		result = append(result, v)
+	}
+
+	return result
+}
+
+// GetByKey returns the item holding the key of v in a unique index, v only has to hold the fields of the key.
+func (s *IMStorage[T]) GetByKey(indexName string, v T) (T, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var empty T
+
+	index, found := s.indexes[indexName].(*IMUniqueIndex[T])
+	if !found {
+		return empty, false, IMErrIndexNotFound
+	}
+
+	id, found := index.lookup(v)
+	if !found {
+		return empty, false, nil
+	}
+
+	return s.items[id], true, nil
+}
+
+// Find returns the first item that matches, the iteration order is not defined.
+func (s *IMStorage[T]) Find(match func(T) bool) (T, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, v := range s.items {
+		if match(v) {
+			return v, true
+		}
+	}
+
+	var empty T
+	return empty, false
+}
+
+func (s *IMStorage[T]) Values() []T {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return slices.Collect(maps.Values(s.items))
+}
+
+func (s *IMStorage[T]) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return len(s.items)
+}
+
+// Page returns a window of a partition in index order, together with the size of the partition.
+func (s *IMStorage[T]) Page(indexName string, partition uuid.UUID, offset int, limit int) ([]T, int, error) {
+	return s.PageWhere(indexName, partition, offset, limit, nil)
+}
+
+// PageWhere is Page over the items of the partition that match, a nil match keeps every item.
+func (s *IMStorage[T]) PageWhere(indexName string, partition uuid.UUID, offset int, limit int, match func(T) bool) ([]T, int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	index, found := s.indexes[indexName].(*IMSortedIndex[T])
+	if !found {
+		return nil, 0, IMErrIndexNotFound
+	}
+
+	ids, total := index.page(s.items, partition, offset, limit, match)
+
+	return s.collect(ids), total, nil
+}
+
+// Seek returns up to limit items of a partition that come right after the given item in index order,
+// or right before it when backwards is set, so a listing can be walked from a position instead of an offset.
+// The given item only has to hold the fields the index compares, and the bool reports whether there are more items further on.
+func (s *IMStorage[T]) Seek(indexName string, partition uuid.UUID, id uuid.UUID, v T, limit int, backwards bool) ([]T, bool, error) {
+	return s.SeekWhere(indexName, partition, id, v, limit, backwards, nil)
+}
+
+// SeekWhere is Seek over the items of the partition that match, a nil match keeps every item.
+func (s *IMStorage[T]) SeekWhere(indexName string, partition uuid.UUID, id uuid.UUID, v T, limit int, backwards bool, match func(T) bool) ([]T, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	index, found := s.indexes[indexName].(*IMSortedIndex[T])
+	if !found {
+		return nil, false, IMErrIndexNotFound
+	}
+
+	ids, hasMore := index.seek(s.items, partition, id, v, limit, backwards, match)
+
+	return s.collect(ids), hasMore, nil
+}
+
+// Last returns the item of a partition that comes last in index order.
+func (s *IMStorage[T]) Last(indexName string, partition uuid.UUID) (T, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var empty T
+
+	index, found := s.indexes[indexName].(*IMSortedIndex[T])
+	if !found {
+		return empty, false, IMErrIndexNotFound
+	}
+
+	id, found := index.last(partition)
+	if !found {
+		return empty, false, nil
+	}
+
+	return s.items[id], true, nil
+}
+
+// KeyCounts returns how many items of a partition hold each key of a key count index.
+func (s *IMStorage[T]) KeyCounts(indexName string, partition uuid.UUID) (map[string]int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	index, found := s.indexes[indexName].(*IMKeyCountIndex[T])
+	if !found {
+		return nil, IMErrIndexNotFound
+	}
+
+	return index.counts(partition), nil
+}
+
+// collect expects the caller to hold the lock and every id to be stored.
+func (s *IMStorage[T]) collect(ids uuid.UUIDs) []T {
+	result := make([]T, 0, len(ids))
+	for _, id := range ids {
+		result = append(result, s.items[id])
+	}
+
+	return result
+}
+
+func (s *IMStorage[T]) Set(id uuid.UUID, v T) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.journal != nil {
+		if err := s.journal.append(s.name, imOperationSet, id, v); err != nil {
+			return err
+		}
+	}
+
+	s.set(id, v)
+
+	return nil
+}
+
+// Insert stores a new item unless one of the unique indexes already holds its key,
+// in which case it returns the item holding the key and ErrItemAlreadyExists.
+func (s *IMStorage[T]) Insert(id uuid.UUID, v T) (T, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if existing, err := s.checkUnique(id, v); err != nil {
+		return existing, err
+	}
+
+	if s.journal != nil {
+		if err := s.journal.append(s.name, imOperationSet, id, v); err != nil {
+			var empty T
+			return empty, err
+		}
+	}
+
+	s.set(id, v)
+
+	return v, nil
+}
+
+// Update replaces an existing item with the result of update, under the write lock.
+// It returns IMErrItemNotFound when the item does not exist, the error of update when it fails
+// and ErrItemAlreadyExists when the result takes a unique key another item holds.
+func (s *IMStorage[T]) Update(id uuid.UUID, update func(current T) (T, error)) (T, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var empty T
+
+	current, found := s.items[id]
+	if !found {
+		return empty, IMErrItemNotFound
+	}
+
+	v, err := update(current)
+	if err != nil {
+		return empty, err
+	}
+
+	if err := s.checkUniqueChanges(id, current, v); err != nil {
+		return empty, err
+	}
+
+	if s.journal != nil {
+		if err := s.journal.append(s.name, imOperationSet, id, v); err != nil {
+			return empty, err
+		}
+	}
+
+	s.set(id, v)
+
+	return v, nil
+}
+
+// DeleteIf removes an existing item when check accepts it, under the write lock.
+// It returns IMErrItemNotFound when the item does not exist and the error of check when it refuses.
+func (s *IMStorage[T]) DeleteIf(id uuid.UUID, check func(current T) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, found := s.items[id]
+	if !found {
+		return IMErrItemNotFound
+	}
+
+	if err := check(current); err != nil {
+		return err
+	}
+
+	if s.journal != nil {
+		if err := s.journal.append(s.name, imOperationDelete, id, nil); err != nil {
+			return err
+		}
+	}
+
+	s.delete(id)
+
+	return nil
+}
+
+// Delete removes the item and reports whether it existed.
+func (s *IMStorage[T]) Delete(id uuid.UUID) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, found := s.items[id]; !found {
+		return false, nil
+	}
+
+	if s.journal != nil {
+		if err := s.journal.append(s.name, imOperationDelete, id, nil); err != nil {
+			return false, err
+		}
+	}
+
+	s.delete(id)
+
+	return true, nil
+}
+
+// DeletePartition removes every item of a partition of a sorted index, journaled as a single record,
+// and returns how many it removed.
+func (s *IMStorage[T]) DeletePartition(indexName string, partition uuid.UUID) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	index, found := s.indexes[indexName].(*IMSortedIndex[T])
+	if !found {
+		return 0, IMErrIndexNotFound
+	}
+
+	ids := slices.Clone(index.partitions[partition])
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	if s.journal != nil {
+		records := make([]imBatchRecord, 0, len(ids))
+		for _, id := range ids {
+			records = append(records, imBatchRecord{Op: imOperationDelete, Id: id})
+		}
+
+		if err := s.journal.append(s.name, imOperationBatch, uuid.Nil, records); err != nil {
+			return 0, err
+		}
+	}
+
+	for _, id := range ids {
+		s.delete(id)
+	}
+
+	return len(ids), nil
+}
+
+// Batch runs build with the write lock held and keeps the writes it stages only when it returns nil,
+// so either all of them are stored or none is. The writes are journaled as a single record,
+// and nobody else sees them before build has returned.
+func (s *IMStorage[T]) Batch(build func(batch *IMBatch[T]) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	batch := &IMBatch[T]{storage: s, previous: map[uuid.UUID]*T{}}
+
+	if err := build(batch); err != nil {
+		batch.rollback()
+		return err
+	}
+
+	if s.journal != nil && len(batch.records) > 0 {
+		if err := s.journal.append(s.name, imOperationBatch, uuid.Nil, batch.records); err != nil {
+			batch.rollback()
+			return err
+		}
+	}
+
+	return nil
+}
+
+// partitionLen counts the items of a partition of a sorted index, it expects the caller to hold the lock.
+func (s *IMStorage[T]) partitionLen(indexName string, partition uuid.UUID) int {
+	index, found := s.indexes[indexName].(*IMSortedIndex[T])
+	if !found {
+		return 0
+	}
+
+	return len(index.partitions[partition])
+}
+
+// checkDuplicates fails with ErrItemAlreadyExists when items share the key of a unique index.
+func (s *IMStorage[T]) checkDuplicates() error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, index := range s.indexes {
+		unique, ok := index.(*IMUniqueIndex[T])
+		if !ok {
+			continue
+		}
+
+		if key, ids, found := unique.duplicate(); found {
+			return fmt.Errorf("%w: items %s share the key %q of %s", ErrItemAlreadyExists, ids.Strings(), key, unique.Name)
+		}
+	}
+
+	return nil
+}
+
+// checkUnique returns the item already holding one of the unique keys of v and ErrItemAlreadyExists,
+// it expects the caller to hold the lock.
+func (s *IMStorage[T]) checkUnique(id uuid.UUID, v T) (T, error) {
+	for _, index := range s.indexes {
+		unique, ok := index.(*IMUniqueIndex[T])
+		if !ok {
+			continue
+		}
+
+		if existingId, found := unique.lookup(v); found && existingId != id {
+			return s.items[existingId], ErrItemAlreadyExists
+		}
+	}
+
+	var empty T
+	return empty, nil
+}
+
+// checkUniqueChanges is checkUnique for the unique keys that differ between current and v only,
+// so an item can still be updated while it shares a key with legacy duplicates. It expects the caller to hold the lock.
+func (s *IMStorage[T]) checkUniqueChanges(id uuid.UUID, current T, v T) error {
+	for _, index := range s.indexes {
+		unique, ok := index.(*IMUniqueIndex[T])
+		if !ok || unique.key(current) == unique.key(v) {
+			continue
+		}
+
+		if existingId, found := unique.lookup(v); found && existingId != id {
+			return ErrItemAlreadyExists
+		}
+	}
+
+	return nil
+}
+
+func (s *IMStorage[T]) set(id uuid.UUID, v T) {
+	if old, found := s.items[id]; found {
+		for _, index := range s.indexes {
+			index.remove(s.items, id, old)
+		}
+	}
+
+	s.items[id] = v
+
+	for _, index := range s.indexes {
+		index.insert(s.items, id, v)
+	}
+}
+
+func (s *IMStorage[T]) delete(id uuid.UUID) {
+	old, found := s.items[id]
+	if !found {
+		return
+	}
+
+	for _, index := range s.indexes {
+		index.remove(s.items, id, old)
+	}
+
+	delete(s.items, id)
+}
+
+// The methods below let the persistence layer snapshot and restore a storage without knowing its type.
+
+func (s *IMStorage[T]) lock() {
+	s.mu.Lock()
+}
+
+func (s *IMStorage[T]) unlock() {
+	s.mu.Unlock()
+}
+
+func (s *IMStorage[T]) attach(name string, journal imJournal) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.name = name
+	s.journal = journal
+}
+
+// dump copies the items, it must be called while the storage is locked.
+func (s *IMStorage[T]) dump() any {
+	return maps.Clone(s.items)
+}
+
+func (s *IMStorage[T]) restore(raw json.RawMessage) error {
+	items := map[uuid.UUID]T{}
+	if err := json.Unmarshal(raw, &items); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.items = items
+	for _, index := range s.indexes {
+		index.build(items)
+	}
+
+	return nil
+}
+
+func (s *IMStorage[T]) replay(op imOperation, id uuid.UUID, raw json.RawMessage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if op != imOperationBatch {
+		return s.replayOne(op, id, raw)
+	}
+
+	records := []struct {
+		Op    imOperation     `json:"op"`
+		Id    uuid.UUID       `json:"id"`
+		Value json.RawMessage `json:"value"`
+	}{}
+	if err := json.Unmarshal(raw, &records); err != nil {
+		return err
+	}
+
+	for _, record := range records {
+		if err := s.replayOne(record.Op, record.Id, record.Value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// replayOne expects the caller to hold the lock.
+func (s *IMStorage[T]) replayOne(op imOperation, id uuid.UUID, raw json.RawMessage) error {
+	switch op {
+	case imOperationSet:
+		var v T
+		if err := json.Unmarshal(raw, &v); err != nil {
+			return err
+		}
+		s.set(id, v)
+	case imOperationDelete:
+		s.delete(id)
+	default:
+		return IMErrCorruptLog
+	}
+
+	return nil
+}
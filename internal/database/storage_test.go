@@ -0,0 +1,414 @@
+package database_test
+
+import (
+	"errors"
+	"platform-go-challenge/internal/database"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIMStorage(t *testing.T) {
+	t.Run("should return stored item by id", func(t *testing.T) {
+		// Arrange
+		id := uuid.New()
+		storage := database.NewIMStorage[database.IMInsightModel](nil)
+		storage.Set(id, database.IMInsightModel{Id: id, Text: "insight"})
+
+		// Act
+		result, found := storage.Get(id)
+
+		// Assert
+		assert.True(t, found)
+		assert.Equal(t, "insight", result.Text)
+	})
+
+	t.Run("should return items in the requested order skipping missing ones", func(t *testing.T) {
+		// Arrange
+		id1 := uuid.New()
+		id2 := uuid.New()
+		storage := database.NewIMStorage(map[uuid.UUID]database.IMInsightModel{
+			id1: {Id: id1, Text: "first"},
+			id2: {Id: id2, Text: "second"},
+		})
+
+		// Act
+		result := storage.GetMany(uuid.UUIDs{id2, uuid.New(), id1})
+
+		// Assert
+		assert.Equal(t, []database.IMInsightModel{{Id: id2, Text: "second"}, {Id: id1, Text: "first"}}, result)
+	})
+
+	t.Run("should report whether delete removed an item", func(t *testing.T) {
+		// Arrange
+		id := uuid.New()
+		storage := database.NewIMStorage(map[uuid.UUID]database.IMInsightModel{id: {Id: id}})
+
+		// Act
+		deleted, err := storage.Delete(id)
+		deletedAgain, _ := storage.Delete(id)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.True(t, deleted)
+		assert.False(t, deletedAgain)
+		assert.Equal(t, 0, storage.Len())
+	})
+
+	t.Run("should update an existing item", func(t *testing.T) {
+		// Arrange
+		id := uuid.New()
+		storage := database.NewIMStorage(map[uuid.UUID]database.IMInsightModel{id: {Id: id, Text: "old"}})
+
+		// Act
+		result, err := storage.Update(id, func(current database.IMInsightModel) (database.IMInsightModel, error) {
+			current.Text = current.Text + " and new"
+			return current, nil
+		})
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, "old and new", result.Text)
+		stored, _ := storage.Get(id)
+		assert.Equal(t, result, stored)
+	})
+
+	t.Run("should not update a missing item or when the update fails", func(t *testing.T) {
+		// Arrange
+		id := uuid.New()
+		storage := database.NewIMStorage(map[uuid.UUID]database.IMInsightModel{id: {Id: id, Text: "old"}})
+		errRejected := errors.New("rejected")
+
+		// Act
+		_, missingErr := storage.Update(uuid.New(), func(current database.IMInsightModel) (database.IMInsightModel, error) {
+			return current, nil
+		})
+		_, rejectedErr := storage.Update(id, func(current database.IMInsightModel) (database.IMInsightModel, error) {
+			current.Text = "new"
+			return current, errRejected
+		})
+
+		// Assert
+		assert.ErrorIs(t, missingErr, database.IMErrItemNotFound)
+		assert.ErrorIs(t, rejectedErr, errRejected)
+		stored, _ := storage.Get(id)
+		assert.Equal(t, "old", stored.Text)
+		assert.Equal(t, 1, storage.Len())
+	})
+
+	t.Run("should delete only an existing item the check accepts", func(t *testing.T) {
+		// Arrange
+		id := uuid.New()
+		storage := database.NewIMStorage(map[uuid.UUID]database.IMInsightModel{id: {Id: id, Text: "old"}})
+		errRejected := errors.New("rejected")
+
+		// Act
+		missingErr := storage.DeleteIf(uuid.New(), func(database.IMInsightModel) error { return nil })
+		rejectedErr := storage.DeleteIf(id, func(database.IMInsightModel) error { return errRejected })
+		lenAfterRejected := storage.Len()
+		err := storage.DeleteIf(id, func(current database.IMInsightModel) error {
+			assert.Equal(t, "old", current.Text)
+			return nil
+		})
+
+		// Assert
+		assert.ErrorIs(t, missingErr, database.IMErrItemNotFound)
+		assert.ErrorIs(t, rejectedErr, errRejected)
+		assert.Equal(t, 1, lenAfterRejected)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, storage.Len())
+	})
+
+	t.Run("should reject inserting a second item with the same unique key", func(t *testing.T) {
+		// Arrange
+		storage := database.NewFavouriteStorage(nil)
+		userId := uuid.New()
+		assetId := uuid.New()
+		first := database.IMFavouriteModel{Id: uuid.New(), UserId: userId, AssetId: assetId, Description: "first"}
+		second := database.IMFavouriteModel{Id: uuid.New(), UserId: userId, AssetId: assetId, Description: "second"}
+		otherUser := database.IMFavouriteModel{Id: uuid.New(), UserId: uuid.New(), AssetId: assetId}
+
+		// Act
+		_, firstErr := storage.Insert(first.Id, first)
+		existing, secondErr := storage.Insert(second.Id, second)
+		_, otherUserErr := storage.Insert(otherUser.Id, otherUser)
+
+		// Assert
+		assert.NoError(t, firstErr)
+		assert.ErrorIs(t, secondErr, database.ErrItemAlreadyExists)
+		assert.Equal(t, first, existing)
+		assert.NoError(t, otherUserErr)
+		assert.Equal(t, 2, storage.Len())
+	})
+
+	t.Run("should allow inserting the key again after deleting its item", func(t *testing.T) {
+		// Arrange
+		storage := database.NewFavouriteStorage(nil)
+		first := database.IMFavouriteModel{Id: uuid.New(), UserId: uuid.New(), AssetId: uuid.New()}
+		second := first
+		second.Id = uuid.New()
+		storage.Insert(first.Id, first)
+
+		// Act
+		storage.Delete(first.Id)
+		_, err := storage.Insert(second.Id, second)
+
+		// Assert
+		assert.NoError(t, err)
+	})
+
+	t.Run("should reject updating an item to a unique key another item holds", func(t *testing.T) {
+		// Arrange
+		userId := uuid.New()
+		storage := database.NewCollectionStorage(nil)
+		first := database.IMCollectionModel{Id: uuid.New(), UserId: userId, Name: "First"}
+		second := database.IMCollectionModel{Id: uuid.New(), UserId: userId, Name: "Second"}
+		storage.Insert(first.Id, first)
+		storage.Insert(second.Id, second)
+
+		// Act
+		_, err := storage.Update(second.Id, func(current database.IMCollectionModel) (database.IMCollectionModel, error) {
+			current.Name = "first"
+			return current, nil
+		})
+		_, renameErr := storage.Update(second.Id, func(current database.IMCollectionModel) (database.IMCollectionModel, error) {
+			current.Name = "second"
+			return current, nil
+		})
+
+		// Assert
+		assert.ErrorIs(t, err, database.ErrItemAlreadyExists)
+		assert.NoError(t, renameErr)
+		result, _ := storage.Get(second.Id)
+		assert.Equal(t, "second", result.Name)
+	})
+
+	t.Run("should update an item that shares its unique key with legacy duplicates", func(t *testing.T) {
+		// Arrange
+		userId := uuid.New()
+		assetId := uuid.New()
+		smallest, _ := uuid.Parse("00000000-0000-0000-0000-000000000001")
+		largest, _ := uuid.Parse("ffffffff-ffff-ffff-ffff-ffffffffffff")
+		storage := database.NewFavouriteStorage(map[uuid.UUID]database.IMFavouriteModel{
+			largest:  {Id: largest, UserId: userId, AssetId: assetId},
+			smallest: {Id: smallest, UserId: userId, AssetId: assetId},
+		})
+
+		// Act
+		_, err := storage.Update(largest, func(current database.IMFavouriteModel) (database.IMFavouriteModel, error) {
+			current.Description = "updated"
+			return current, nil
+		})
+
+		// Assert
+		assert.NoError(t, err)
+	})
+
+	t.Run("should return the item holding a unique key", func(t *testing.T) {
+		// Arrange
+		storage := database.NewFavouriteStorage(nil)
+		fav := database.IMFavouriteModel{Id: uuid.New(), UserId: uuid.New(), AssetId: uuid.New()}
+		storage.Insert(fav.Id, fav)
+
+		// Act
+		result, found, err := storage.GetByKey(
+			database.IMFavouritesByUserAssetIndex,
+			database.IMFavouriteModel{UserId: fav.UserId, AssetId: fav.AssetId},
+		)
+		_, missingFound, _ := storage.GetByKey(
+			database.IMFavouritesByUserAssetIndex,
+			database.IMFavouriteModel{UserId: fav.UserId, AssetId: uuid.New()},
+		)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, fav, result)
+		assert.False(t, missingFound)
+	})
+
+	t.Run("should resolve legacy duplicates to the smallest id", func(t *testing.T) {
+		// Arrange
+		userId := uuid.New()
+		assetId := uuid.New()
+		smallest, _ := uuid.Parse("00000000-0000-0000-0000-000000000001")
+		largest, _ := uuid.Parse("ffffffff-ffff-ffff-ffff-ffffffffffff")
+		storage := database.NewFavouriteStorage(map[uuid.UUID]database.IMFavouriteModel{
+			largest:  {Id: largest, UserId: userId, AssetId: assetId},
+			smallest: {Id: smallest, UserId: userId, AssetId: assetId},
+		})
+
+		// Act
+		existing, err := storage.Insert(uuid.New(), database.IMFavouriteModel{UserId: userId, AssetId: assetId})
+
+		// Assert
+		assert.ErrorIs(t, err, database.ErrItemAlreadyExists)
+		assert.Equal(t, smallest, existing.Id)
+	})
+}
+
+func TestIMStorageDeletePartition(t *testing.T) {
+	t.Run("should delete every item of the partition and nothing else", func(t *testing.T) {
+		// Arrange
+		userId := uuid.New()
+		otherUserId := uuid.New()
+		storage := database.NewFavouriteStorage(nil)
+		for range 3 {
+			id := uuid.New()
+			storage.Set(id, database.IMFavouriteModel{Id: id, UserId: userId, AssetId: uuid.New()})
+		}
+		otherId := uuid.New()
+		storage.Set(otherId, database.IMFavouriteModel{Id: otherId, UserId: otherUserId, AssetId: uuid.New()})
+
+		// Act
+		deleted, err := storage.DeletePartition(database.IMFavouritesByUserIndex, userId)
+		deletedAgain, _ := storage.DeletePartition(database.IMFavouritesByUserIndex, userId)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, 3, deleted)
+		assert.Equal(t, 0, deletedAgain)
+		assert.Equal(t, 1, storage.Len())
+		_, found := storage.Get(otherId)
+		assert.True(t, found)
+	})
+
+	t.Run("should return error for an unknown index", func(t *testing.T) {
+		// Arrange
+		storage := database.NewFavouriteStorage(nil)
+
+		// Act
+		_, err := storage.DeletePartition("unknown", uuid.New())
+
+		// Assert
+		assert.ErrorIs(t, err, database.IMErrIndexNotFound)
+	})
+}
+
+func TestIMStorageBatch(t *testing.T) {
+	t.Run("should keep every write of a batch that succeeds", func(t *testing.T) {
+		// Arrange
+		storage := database.NewFavouriteStorage(nil)
+		userId := uuid.New()
+		assetId := uuid.New()
+		old := database.IMFavouriteModel{Id: uuid.New(), UserId: userId, AssetId: assetId, Description: "old"}
+		storage.Insert(old.Id, old)
+		replacement := database.IMFavouriteModel{Id: uuid.New(), UserId: userId, AssetId: assetId, Description: "new"}
+
+		// Act
+		err := storage.Batch(func(batch *database.IMBatch[database.IMFavouriteModel]) error {
+			if err := batch.DeleteIf(old.Id, func(database.IMFavouriteModel) error { return nil }); err != nil {
+				return err
+			}
+			_, err := batch.Insert(replacement.Id, replacement)
+			return err
+		})
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, []database.IMFavouriteModel{replacement}, storage.Values())
+	})
+
+	t.Run("should undo every write of a batch that fails", func(t *testing.T) {
+		// Arrange
+		storage := database.NewFavouriteStorage(nil)
+		userId := uuid.New()
+		updated := database.IMFavouriteModel{Id: uuid.New(), UserId: userId, AssetId: uuid.New(), Description: "old"}
+		deleted := database.IMFavouriteModel{Id: uuid.New(), UserId: userId, AssetId: uuid.New(), Description: "deleted"}
+		storage.Insert(updated.Id, updated)
+		storage.Insert(deleted.Id, deleted)
+		inserted := database.IMFavouriteModel{Id: uuid.New(), UserId: userId, AssetId: uuid.New()}
+		duplicate := database.IMFavouriteModel{Id: uuid.New(), UserId: userId, AssetId: inserted.AssetId}
+
+		// Act
+		var duplicateErr error
+		err := storage.Batch(func(batch *database.IMBatch[database.IMFavouriteModel]) error {
+			batch.Update(updated.Id, func(current database.IMFavouriteModel) (database.IMFavouriteModel, error) {
+				current.Description = "new"
+				return current, nil
+			})
+			batch.DeleteIf(deleted.Id, func(database.IMFavouriteModel) error { return nil })
+			batch.Insert(inserted.Id, inserted)
+			_, duplicateErr = batch.Insert(duplicate.Id, duplicate)
+			return duplicateErr
+		})
+
+		// Assert
+		assert.ErrorIs(t, err, database.ErrItemAlreadyExists)
+		assert.ErrorIs(t, duplicateErr, database.ErrItemAlreadyExists)
+		assert.ElementsMatch(t, []database.IMFavouriteModel{updated, deleted}, storage.Values())
+		_, err = storage.Insert(duplicate.Id, duplicate)
+		assert.NoError(t, err, "the key of the rolled back insert should be free again")
+	})
+
+	t.Run("should delete a partition in a batch and restore it when the batch fails", func(t *testing.T) {
+		// Arrange
+		storage := database.NewFavouriteStorage(nil)
+		userId := uuid.New()
+		first := database.IMFavouriteModel{Id: uuid.New(), UserId: userId, AssetId: uuid.New()}
+		second := database.IMFavouriteModel{Id: uuid.New(), UserId: userId, AssetId: uuid.New()}
+		other := database.IMFavouriteModel{Id: uuid.New(), UserId: uuid.New(), AssetId: uuid.New()}
+		storage.Insert(first.Id, first)
+		storage.Insert(second.Id, second)
+		storage.Insert(other.Id, other)
+		rollback := errors.New("rollback")
+
+		// Act
+		var rolledBackCount int
+		rolledBackErr := storage.Batch(func(batch *database.IMBatch[database.IMFavouriteModel]) error {
+			rolledBackCount, _ = batch.DeletePartition(database.IMFavouritesByUserIndex, userId)
+			return rollback
+		})
+		rolledBackValues := storage.Values()
+		var count int
+		err := storage.Batch(func(batch *database.IMBatch[database.IMFavouriteModel]) error {
+			var err error
+			count, err = batch.DeletePartition(database.IMFavouritesByUserIndex, userId)
+			return err
+		})
+
+		// Assert
+		assert.ErrorIs(t, rolledBackErr, rollback)
+		assert.Equal(t, 2, rolledBackCount)
+		assert.ElementsMatch(t, []database.IMFavouriteModel{first, second, other}, rolledBackValues)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, count)
+		assert.Equal(t, []database.IMFavouriteModel{other}, storage.Values())
+	})
+}
+
+func TestIMStorageConcurrentAccess(t *testing.T) {
+	// Arrange
+	storage := database.NewIMStorage[database.IMFavouriteModel](nil)
+	workers := 8
+	iterations := 100
+
+	var wg sync.WaitGroup
+
+	// Act
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for i := 0; i < iterations; i++ {
+				id := uuid.New()
+				storage.Set(id, database.IMFavouriteModel{Id: id})
+				storage.Set(id, database.IMFavouriteModel{Id: id, Description: "updated"})
+				storage.Get(id)
+				storage.Values()
+				storage.Find(func(m database.IMFavouriteModel) bool { return m.Id == id })
+				if i%2 == 0 {
+					storage.Delete(id)
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	// Assert
+	assert.Equal(t, workers*iterations/2, storage.Len())
+}
@@ -1,6 +1,10 @@
 package audience
 
-import "github.com/google/uuid"
+import (
+	"platform-go-challenge/internal/utils"
+
+	"github.com/google/uuid"
+)
 
 type Audience struct {
 	Id                 uuid.UUID `json:"id"`
@@ -10,3 +14,58 @@ type Audience struct {
 	SocialMediaHours   float64   `json:"social_media_hours"`
 	PurchasesLastMonth int       `json:"purchases_last_month"`
 }
+
+// AssetId identifies the audience as the asset of a favourite.
+func (audience Audience) AssetId() uuid.UUID {
+	return audience.Id
+}
+
+// AudienceChanges are the fields an update sets, nil fields are left as they are.
+type AudienceChanges struct {
+	Gender             *string
+	BirthCountry       *string
+	AgeGroup           *string
+	SocialMediaHours   *float64
+	PurchasesLastMonth *int
+}
+
+// CreateAudienceRequestBody takes the numbers as pointers, so a missing one is told apart from 0.
+type CreateAudienceRequestBody struct {
+	Gender             string   `json:"gender" validate:"required,audience_gender"`
+	BirthCountry       string   `json:"birth_country" validate:"required,country"`
+	AgeGroup           string   `json:"age_group" validate:"required,age_group"`
+	SocialMediaHours   *float64 `json:"social_media_hours" validate:"required,gte=0"`
+	PurchasesLastMonth *int     `json:"purchases_last_month" validate:"required,gte=0"`
+}
+
+// UpdateAudienceRequestBody is a merge patch, it leaves out the fields that are not changed, see UpdateAudienceRequestBody.Changes.
+type UpdateAudienceRequestBody struct {
+	utils.MergePatch
+	Gender             *string  `json:"gender" validate:"omitnil,audience_gender"`
+	BirthCountry       *string  `json:"birth_country" validate:"omitnil,country"`
+	AgeGroup           *string  `json:"age_group" validate:"omitnil,age_group"`
+	SocialMediaHours   *float64 `json:"social_media_hours" validate:"omitnil,gte=0"`
+	PurchasesLastMonth *int     `json:"purchases_last_month" validate:"omitnil,gte=0"`
+}
+
+// Changes returns the changes of the patch. Every characteristic of an audience is required,
+// so the fields set to null are returned as FieldErrors.
+func (body UpdateAudienceRequestBody) Changes() (AudienceChanges, error) {
+	errs := utils.FieldErrors{}
+	for _, field := range []string{"gender", "birth_country", "age_group", "social_media_hours", "purchases_last_month"} {
+		if body.IsNull(field) {
+			errs[field] = "is required"
+		}
+	}
+	if len(errs) > 0 {
+		return AudienceChanges{}, errs
+	}
+
+	return AudienceChanges{
+		Gender:             body.Gender,
+		BirthCountry:       body.BirthCountry,
+		AgeGroup:           body.AgeGroup,
+		SocialMediaHours:   body.SocialMediaHours,
+		PurchasesLastMonth: body.PurchasesLastMonth,
+	}, nil
+}
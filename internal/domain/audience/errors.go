@@ -0,0 +1,9 @@
+package audience
+
+import "errors"
+
+var (
+	ErrAudienceNotFound     = errors.New("Audience not found")
+	ErrCouldNotSaveAudience = errors.New("Could not save audience")
+	ErrAudienceFavourited   = errors.New("Audience is a favourite of some users")
+)
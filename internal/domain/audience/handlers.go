@@ -0,0 +1,166 @@
+package audience
+
+import (
+	"errors"
+	"net/http"
+	"platform-go-challenge/internal/utils"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// respondWithAudienceError answers the errors every write of an audience can fail with,
+// the rejected characteristics are listed under data like the body validation does.
+func respondWithAudienceError(w http.ResponseWriter, err error) {
+	if errors.Is(err, ErrAudienceNotFound) {
+		utils.RespondWithError(w, http.StatusNotFound, "Could not find Audience with this Id")
+		return
+	}
+	if errors.Is(err, ErrAudienceFavourited) {
+		utils.RespondWithError(w, http.StatusConflict, "Audience is a favourite of some users, it cannot be deleted")
+		return
+	}
+	var fieldErrs utils.FieldErrors
+	if errors.As(err, &fieldErrs) {
+		utils.RespondWithErrorAndData(w, http.StatusBadRequest, fieldErrs.Error(), fieldErrs)
+		return
+	}
+
+	utils.RespondWithError(w, http.StatusInternalServerError, "Internal Server Error")
+}
+
+type GetAudiencesHandlerDependencies struct {
+	AudienceService AudienceService
+}
+
+func GetAudiencesHandler(dependencies GetAudiencesHandlerDependencies) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		pageSize, pageNumber, err := utils.GetPaginationQuery(r, 10, 0)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		audiences, pagination, err := dependencies.AudienceService.GetPaginated(pageSize, pageNumber)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusInternalServerError, "Internal Server Error")
+			return
+		}
+
+		utils.RespondWithPaginatedData(w, http.StatusOK, audiences, *pagination)
+	}
+}
+
+type GetAudienceHandlerDependencies struct {
+	AudienceService AudienceService
+}
+
+func GetAudienceHandler(dependencies GetAudienceHandlerDependencies) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		audienceId, err := uuid.Parse(chi.URLParam(r, "id"))
+		if err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "Audience Id param is not a UUID")
+			return
+		}
+
+		audience, err := dependencies.AudienceService.Get(audienceId)
+		if err != nil {
+			respondWithAudienceError(w, err)
+			return
+		}
+
+		utils.RespondWithData(w, http.StatusOK, audience)
+	}
+}
+
+type CreateAudienceHandlerDependencies struct {
+	AudienceService AudienceService
+}
+
+func CreateAudienceHandler(dependencies CreateAudienceHandlerDependencies) http.HandlerFunc {
+	validation := utils.BodyValidator[CreateAudienceRequestBody]
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		body, ok := utils.GetParsedBody[CreateAudienceRequestBody](r)
+		if !ok {
+			// Should not happen since we validate body before getting in to handler
+			utils.RespondWithError(w, http.StatusInternalServerError, "Internal Server Error")
+			return
+		}
+
+		audience, err := dependencies.AudienceService.Create(Audience{
+			Gender:             body.Gender,
+			BirthCountry:       body.BirthCountry,
+			AgeGroup:           body.AgeGroup,
+			SocialMediaHours:   *body.SocialMediaHours,
+			PurchasesLastMonth: *body.PurchasesLastMonth,
+		})
+		if err != nil {
+			respondWithAudienceError(w, err)
+			return
+		}
+
+		utils.RespondWithData(w, http.StatusCreated, audience)
+	}
+
+	return validation(handler)
+}
+
+type UpdateAudienceHandlerDependencies struct {
+	AudienceService AudienceService
+}
+
+func UpdateAudienceHandler(dependencies UpdateAudienceHandlerDependencies) http.HandlerFunc {
+	validation := utils.BodyValidator[UpdateAudienceRequestBody]
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		audienceId, err := uuid.Parse(chi.URLParam(r, "id"))
+		if err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "Audience Id param is not a UUID")
+			return
+		}
+
+		body, ok := utils.GetParsedBody[UpdateAudienceRequestBody](r)
+		if !ok {
+			// Should not happen since we validate body before getting in to handler
+			utils.RespondWithError(w, http.StatusInternalServerError, "Internal Server Error")
+			return
+		}
+
+		changes, err := body.Changes()
+		if err != nil {
+			respondWithAudienceError(w, err)
+			return
+		}
+
+		audience, err := dependencies.AudienceService.Update(audienceId, changes)
+		if err != nil {
+			respondWithAudienceError(w, err)
+			return
+		}
+
+		utils.RespondWithData(w, http.StatusOK, audience)
+	}
+
+	return validation(handler)
+}
+
+type DeleteAudienceHandlerDependencies struct {
+	AudienceService AudienceService
+}
+
+func DeleteAudienceHandler(dependencies DeleteAudienceHandlerDependencies) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		audienceId, err := uuid.Parse(chi.URLParam(r, "id"))
+		if err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "Audience Id param is not a UUID")
+			return
+		}
+
+		err = dependencies.AudienceService.Delete(audienceId)
+		if err != nil {
+			respondWithAudienceError(w, err)
+			return
+		}
+
+		utils.RespondWithMessage(w, http.StatusOK, "Audience deleted")
+	}
+}
@@ -0,0 +1,264 @@
+package audience_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"platform-go-challenge/internal/domain/audience"
+	"platform-go-challenge/internal/utils"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+type StubAudienceService struct {
+	GetPaginatedFunc func(pageSize, pageNumber int) ([]audience.Audience, *utils.Pagination, error)
+	GetFunc          func(audienceId uuid.UUID) (*audience.Audience, error)
+	CreateFunc       func(a audience.Audience) (*audience.Audience, error)
+	UpdateFunc       func(audienceId uuid.UUID, changes audience.AudienceChanges) (*audience.Audience, error)
+	DeleteFunc       func(audienceId uuid.UUID) error
+}
+
+func (s *StubAudienceService) GetPaginated(pageSize, pageNumber int) ([]audience.Audience, *utils.Pagination, error) {
+	if s.GetPaginatedFunc != nil {
+		return s.GetPaginatedFunc(pageSize, pageNumber)
+	}
+	return nil, nil, errors.New("not implemented")
+}
+
+func (s *StubAudienceService) Get(audienceId uuid.UUID) (*audience.Audience, error) {
+	if s.GetFunc != nil {
+		return s.GetFunc(audienceId)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (s *StubAudienceService) Create(a audience.Audience) (*audience.Audience, error) {
+	if s.CreateFunc != nil {
+		return s.CreateFunc(a)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (s *StubAudienceService) Update(audienceId uuid.UUID, changes audience.AudienceChanges) (*audience.Audience, error) {
+	if s.UpdateFunc != nil {
+		return s.UpdateFunc(audienceId, changes)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (s *StubAudienceService) Delete(audienceId uuid.UUID) error {
+	if s.DeleteFunc != nil {
+		return s.DeleteFunc(audienceId)
+	}
+	return errors.New("not implemented")
+}
+
+// withAudienceId sets the id route param chi would have matched.
+func withAudienceId(req *http.Request, audienceId string) *http.Request {
+	ctx := chi.NewRouteContext()
+	ctx.URLParams.Add("id", audienceId)
+
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, ctx))
+}
+
+type fieldErrorsResponse struct {
+	Error string            `json:"error"`
+	Data  map[string]string `json:"data"`
+}
+
+func TestCreateAudienceHandler(t *testing.T) {
+	t.Run("Should return 201 when audience is created successfully", func(t *testing.T) {
+		// Arrange
+		expected := &audience.Audience{
+			Id:                 uuid.New(),
+			Gender:             "Female",
+			BirthCountry:       "GR",
+			AgeGroup:           "25-34",
+			SocialMediaHours:   0,
+			PurchasesLastMonth: 4,
+		}
+		stubService := &StubAudienceService{
+			CreateFunc: func(a audience.Audience) (*audience.Audience, error) {
+				assert.Equal(t, "Female", a.Gender)
+				assert.Equal(t, "gr", a.BirthCountry)
+				assert.Equal(t, "25-34", a.AgeGroup)
+				assert.Equal(t, 0.0, a.SocialMediaHours)
+				assert.Equal(t, 4, a.PurchasesLastMonth)
+				return expected, nil
+			},
+		}
+		handler := audience.CreateAudienceHandler(audience.CreateAudienceHandlerDependencies{AudienceService: stubService})
+
+		req := httptest.NewRequest(
+			http.MethodPost,
+			"/admin/audiences",
+			bytes.NewReader([]byte(`{"gender": "Female", "birth_country": "gr", "age_group": "25-34", "social_media_hours": 0, "purchases_last_month": 4}`)),
+		)
+		w := httptest.NewRecorder()
+
+		// Act
+		handler(w, req)
+
+		// Assert
+		assert.Equal(t, http.StatusCreated, w.Result().StatusCode)
+		var response utils.DataResponse[audience.Audience]
+		assert.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+		assert.Equal(t, *expected, response.Data)
+	})
+
+	t.Run("Should return 400 with the error of every rejected field", func(t *testing.T) {
+		// Arrange
+		handler := audience.CreateAudienceHandler(audience.CreateAudienceHandlerDependencies{AudienceService: &StubAudienceService{}})
+
+		req := httptest.NewRequest(
+			http.MethodPost,
+			"/admin/audiences",
+			bytes.NewReader([]byte(`{"gender": "Unknown", "birth_country": "Greece", "age_group": "25 to 34", "social_media_hours": -2}`)),
+		)
+		w := httptest.NewRecorder()
+
+		// Act
+		handler(w, req)
+
+		// Assert
+		assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
+		var response fieldErrorsResponse
+		assert.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+		assert.Equal(t, map[string]string{
+			"gender":               "must be one of Female, Male, Non-binary or Other",
+			"birth_country":        "must be an ISO 3166-1 alpha-2 country code like GB",
+			"age_group":            "must be an age range like 25-34, or like 65+ for no upper bound",
+			"social_media_hours":   "must be at least 0",
+			"purchases_last_month": "is required",
+		}, response.Data)
+	})
+}
+
+func TestUpdateAudienceHandler(t *testing.T) {
+	t.Run("Should pass the fields of the merge patch as changes", func(t *testing.T) {
+		// Arrange
+		audienceId := uuid.New()
+		stubService := &StubAudienceService{
+			UpdateFunc: func(aId uuid.UUID, changes audience.AudienceChanges) (*audience.Audience, error) {
+				ageGroup := "65+"
+				assert.Equal(t, audienceId, aId)
+				assert.Equal(t, audience.AudienceChanges{AgeGroup: &ageGroup}, changes)
+				return &audience.Audience{Id: aId, AgeGroup: ageGroup}, nil
+			},
+		}
+		handler := audience.UpdateAudienceHandler(audience.UpdateAudienceHandlerDependencies{AudienceService: stubService})
+
+		req := httptest.NewRequest(http.MethodPatch, "/admin/audiences", bytes.NewReader([]byte(`{"age_group": "65+"}`)))
+		req = withAudienceId(req, audienceId.String())
+		w := httptest.NewRecorder()
+
+		// Act
+		handler(w, req)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+	})
+
+	t.Run("Should return 400 when a characteristic is set to null", func(t *testing.T) {
+		// Arrange
+		handler := audience.UpdateAudienceHandler(audience.UpdateAudienceHandlerDependencies{AudienceService: &StubAudienceService{}})
+
+		req := httptest.NewRequest(http.MethodPatch, "/admin/audiences", bytes.NewReader([]byte(`{"gender": null}`)))
+		req = withAudienceId(req, uuid.NewString())
+		w := httptest.NewRecorder()
+
+		// Act
+		handler(w, req)
+
+		// Assert
+		assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
+		var response fieldErrorsResponse
+		assert.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+		assert.Equal(t, map[string]string{"gender": "is required"}, response.Data)
+	})
+
+	t.Run("Should return 400 with the field errors of the service", func(t *testing.T) {
+		// Arrange
+		stubService := &StubAudienceService{
+			UpdateFunc: func(uuid.UUID, audience.AudienceChanges) (*audience.Audience, error) {
+				return nil, utils.FieldErrors{"birth_country": "must be an ISO 3166-1 alpha-2 country code like GB"}
+			},
+		}
+		handler := audience.UpdateAudienceHandler(audience.UpdateAudienceHandlerDependencies{AudienceService: stubService})
+
+		req := httptest.NewRequest(http.MethodPatch, "/admin/audiences", bytes.NewReader([]byte(`{"birth_country": "GR"}`)))
+		req = withAudienceId(req, uuid.NewString())
+		w := httptest.NewRecorder()
+
+		// Act
+		handler(w, req)
+
+		// Assert
+		assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
+		var response fieldErrorsResponse
+		assert.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+		assert.Equal(t, map[string]string{"birth_country": "must be an ISO 3166-1 alpha-2 country code like GB"}, response.Data)
+	})
+
+	t.Run("Should return 404 when audience not found", func(t *testing.T) {
+		// Arrange
+		stubService := &StubAudienceService{
+			UpdateFunc: func(uuid.UUID, audience.AudienceChanges) (*audience.Audience, error) {
+				return nil, audience.ErrAudienceNotFound
+			},
+		}
+		handler := audience.UpdateAudienceHandler(audience.UpdateAudienceHandlerDependencies{AudienceService: stubService})
+
+		req := httptest.NewRequest(http.MethodPatch, "/admin/audiences", bytes.NewReader([]byte(`{"gender": "Male"}`)))
+		req = withAudienceId(req, uuid.NewString())
+		w := httptest.NewRecorder()
+
+		// Act
+		handler(w, req)
+
+		// Assert
+		assert.Equal(t, http.StatusNotFound, w.Result().StatusCode)
+	})
+}
+
+func TestDeleteAudienceHandler(t *testing.T) {
+	cases := []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{"Should return 200 when delete is successful", nil, http.StatusOK},
+		{"Should return 404 when audience not found", audience.ErrAudienceNotFound, http.StatusNotFound},
+		{"Should return 409 when audience is favourited", audience.ErrAudienceFavourited, http.StatusConflict},
+		{"Should return 500 when service fails unexpectedly", errors.New("db down"), http.StatusInternalServerError},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			audienceId := uuid.New()
+			stubService := &StubAudienceService{
+				DeleteFunc: func(aId uuid.UUID) error {
+					assert.Equal(t, audienceId, aId)
+					return tc.err
+				},
+			}
+			handler := audience.DeleteAudienceHandler(audience.DeleteAudienceHandlerDependencies{AudienceService: stubService})
+
+			req := withAudienceId(httptest.NewRequest(http.MethodDelete, "/admin/audiences", nil), audienceId.String())
+			w := httptest.NewRecorder()
+
+			// Act
+			handler(w, req)
+
+			// Assert
+			assert.Equal(t, tc.status, w.Result().StatusCode)
+		})
+	}
+}
@@ -1,7 +1,9 @@
 package audience
 
 import (
+	"errors"
 	"platform-go-challenge/internal/database"
+	"platform-go-challenge/internal/utils"
 
 	"github.com/google/uuid"
 )
@@ -9,6 +11,13 @@ import (
 type AudienceRepository interface {
 	GetByIds(ids uuid.UUIDs) ([]Audience, error)
 	GetById(id uuid.UUID) (*Audience, error)
+	// GetPaginated lists every audience by birth country, age group and gender
+	GetPaginated(pageSize int, pageNumber int) ([]Audience, utils.Pagination, error)
+	Create(audience Audience) (*Audience, error)
+	// Update fails with ErrAudienceNotFound when the audience is gone
+	Update(audience Audience) (*Audience, error)
+	// Delete fails with ErrAudienceNotFound when the audience is gone, and with ErrAudienceFavourited while a user has a favourite of it
+	Delete(id uuid.UUID) error
 }
 
 type inMemoryDBAudienceRepository struct {
@@ -32,13 +41,20 @@ func InMemoryDBAudienceModelToDTO(model database.IMAudienceModel) Audience {
 	}
 }
 
+func DTOToInMemoryDBAudienceModel(dto Audience) database.IMAudienceModel {
+	return database.IMAudienceModel{
+		Id:                 dto.Id,
+		Gender:             dto.Gender,
+		BirthCountry:       dto.BirthCountry,
+		AgeGroup:           dto.AgeGroup,
+		SocialMediaHours:   dto.SocialMediaHours,
+		PurchasesLastMonth: dto.PurchasesLastMonth,
+	}
+}
+
 func (repo *inMemoryDBAudienceRepository) GetByIds(ids uuid.UUIDs) ([]Audience, error) {
 	result := []Audience{}
-	for _, id := range ids {
-		model, found := repo.DB.AudienceStorage[id]
-		if !found {
-			continue
-		}
+	for _, model := range repo.DB.AudienceStorage.GetMany(ids) {
 		dto := InMemoryDBAudienceModelToDTO(model)
 		result = append(result, dto)
 	}
@@ -58,3 +74,63 @@ func (repo *inMemoryDBAudienceRepository) GetById(id uuid.UUID) (*Audience, erro
 
 	return &dto, nil
 }
+
+func (repo *inMemoryDBAudienceRepository) GetPaginated(pageSize int, pageNumber int) ([]Audience, utils.Pagination, error) {
+	models, total, err := repo.DB.AudienceStorage.Page(database.IMAudiencesByCharacteristicsIndex, uuid.Nil, pageSize*pageNumber, pageSize)
+	if err != nil {
+		return nil, utils.Pagination{}, err
+	}
+
+	result := []Audience{}
+	for _, model := range models {
+		result = append(result, InMemoryDBAudienceModelToDTO(model))
+	}
+
+	maxPage := utils.CalculateMaxPages(total, pageSize)
+
+	return result, utils.Pagination{Page: pageNumber, PageSize: pageSize, MaxPage: maxPage}, nil
+}
+
+func (repo *inMemoryDBAudienceRepository) Create(audience Audience) (*Audience, error) {
+	if _, err := repo.DB.AudienceStorage.Insert(audience.Id, DTOToInMemoryDBAudienceModel(audience)); err != nil {
+		return nil, err
+	}
+
+	return &audience, nil
+}
+
+func (repo *inMemoryDBAudienceRepository) Update(audience Audience) (*Audience, error) {
+	model, err := repo.DB.AudienceStorage.Update(
+		audience.Id,
+		func(current database.IMAudienceModel) (database.IMAudienceModel, error) {
+			return DTOToInMemoryDBAudienceModel(audience), nil
+		},
+	)
+	if err != nil {
+		if errors.Is(err, database.ErrItemNotFound) {
+			return nil, ErrAudienceNotFound
+		}
+
+		return nil, err
+	}
+
+	updated := InMemoryDBAudienceModelToDTO(model)
+
+	return &updated, nil
+}
+
+func (repo *inMemoryDBAudienceRepository) Delete(id uuid.UUID) error {
+	deleted, err := database.IMDeleteUnlessFavourited(repo.DB, repo.DB.AudienceStorage, id)
+	if errors.Is(err, database.ErrItemFavourited) {
+		return ErrAudienceFavourited
+	}
+	if err != nil {
+		return err
+	}
+
+	if !deleted {
+		return ErrAudienceNotFound
+	}
+
+	return nil
+}
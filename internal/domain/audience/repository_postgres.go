@@ -0,0 +1,151 @@
+package audience
+
+import (
+	"context"
+	"errors"
+	"platform-go-challenge/internal/database"
+	"platform-go-challenge/internal/utils"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type postgresDBAudienceRepository struct {
+	DB *pgxpool.Pool
+}
+
+func NewPostgresDBAudienceRepository(db *pgxpool.Pool) *postgresDBAudienceRepository {
+	return &postgresDBAudienceRepository{
+		DB: db,
+	}
+}
+
+const pgAudienceColumns = "id, gender, birth_country, age_group, social_media_hours, purchases_last_month"
+
+func pgScanAudience(row pgx.CollectableRow) (Audience, error) {
+	var audience Audience
+	err := row.Scan(
+		&audience.Id,
+		&audience.Gender,
+		&audience.BirthCountry,
+		&audience.AgeGroup,
+		&audience.SocialMediaHours,
+		&audience.PurchasesLastMonth,
+	)
+
+	return audience, err
+}
+
+// GetByIds returns the audiences in the order of ids, skipping the missing ones.
+func (repo *postgresDBAudienceRepository) GetByIds(ids uuid.UUIDs) ([]Audience, error) {
+	rows, err := repo.DB.Query(
+		context.Background(),
+		"SELECT "+pgAudienceColumns+" FROM audiences WHERE id = ANY($1::uuid[]) ORDER BY array_position($1::uuid[], id)",
+		ids.Strings(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, pgScanAudience)
+}
+
+func (repo *postgresDBAudienceRepository) GetById(id uuid.UUID) (*Audience, error) {
+	rows, err := repo.DB.Query(
+		context.Background(),
+		"SELECT "+pgAudienceColumns+" FROM audiences WHERE id = $1",
+		id,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	audience, err := pgx.CollectExactlyOneRow(rows, pgScanAudience)
+	if err != nil {
+		return nil, database.PGItemNotFound(err)
+	}
+
+	return &audience, nil
+}
+
+func (repo *postgresDBAudienceRepository) GetPaginated(pageSize int, pageNumber int) ([]Audience, utils.Pagination, error) {
+	ctx := context.Background()
+
+	var totalCount int
+	err := repo.DB.QueryRow(ctx, "SELECT count(*) FROM audiences").Scan(&totalCount)
+	if err != nil {
+		return nil, utils.Pagination{}, err
+	}
+
+	rows, err := repo.DB.Query(
+		ctx,
+		"SELECT "+pgAudienceColumns+` FROM audiences
+		ORDER BY birth_country COLLATE "C", age_group COLLATE "C", gender COLLATE "C", id
+		LIMIT $1 OFFSET $2`,
+		pageSize, pageSize*pageNumber,
+	)
+	if err != nil {
+		return nil, utils.Pagination{}, err
+	}
+
+	result, err := pgx.CollectRows(rows, pgScanAudience)
+	if err != nil {
+		return nil, utils.Pagination{}, err
+	}
+
+	if result == nil {
+		result = []Audience{}
+	}
+
+	maxPage := utils.CalculateMaxPages(totalCount, pageSize)
+
+	return result, utils.Pagination{Page: pageNumber, PageSize: pageSize, MaxPage: maxPage}, nil
+}
+
+func (repo *postgresDBAudienceRepository) Create(audience Audience) (*Audience, error) {
+	_, err := repo.DB.Exec(
+		context.Background(),
+		"INSERT INTO audiences ("+pgAudienceColumns+") VALUES ($1, $2, $3, $4, $5, $6)",
+		audience.Id, audience.Gender, audience.BirthCountry, audience.AgeGroup, audience.SocialMediaHours, audience.PurchasesLastMonth,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &audience, nil
+}
+
+func (repo *postgresDBAudienceRepository) Update(audience Audience) (*Audience, error) {
+	tag, err := repo.DB.Exec(
+		context.Background(),
+		`UPDATE audiences SET gender = $2, birth_country = $3, age_group = $4, social_media_hours = $5, purchases_last_month = $6
+		WHERE id = $1`,
+		audience.Id, audience.Gender, audience.BirthCountry, audience.AgeGroup, audience.SocialMediaHours, audience.PurchasesLastMonth,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return nil, ErrAudienceNotFound
+	}
+
+	return &audience, nil
+}
+
+func (repo *postgresDBAudienceRepository) Delete(id uuid.UUID) error {
+	deleted, err := database.PGDeleteUnlessFavourited(repo.DB, "audiences", id)
+	if errors.Is(err, database.ErrItemFavourited) {
+		return ErrAudienceFavourited
+	}
+	if err != nil {
+		return err
+	}
+
+	if !deleted {
+		return ErrAudienceNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,53 @@
+package audience_test
+
+import (
+	"context"
+	"platform-go-challenge/internal/domain/audience"
+	"platform-go-challenge/internal/domain/favourite"
+	"platform-go-challenge/test"
+	"platform-go-challenge/test/conformance"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresDBAudienceRepositoryConformance(t *testing.T) {
+	conformance.AudienceRepository(t, func(t *testing.T, audiences []audience.Audience) audience.AudienceRepository {
+		pool := test.PostgresPool(t)
+		for _, a := range audiences {
+			_, err := pool.Exec(
+				context.Background(),
+				`INSERT INTO audiences (id, gender, birth_country, age_group, social_media_hours, purchases_last_month)
+				VALUES ($1, $2, $3, $4, $5, $6)`,
+				a.Id, a.Gender, a.BirthCountry, a.AgeGroup, a.SocialMediaHours, a.PurchasesLastMonth,
+			)
+			require.NoError(t, err)
+		}
+
+		return audience.NewPostgresDBAudienceRepository(pool)
+	})
+}
+
+func TestPostgresDBAudienceRepositoryDelete(t *testing.T) {
+	t.Run("should return error and keep the audience while a user has a favourite of it", func(t *testing.T) {
+		// Arrange
+		pool := test.PostgresPool(t)
+		repo := audience.NewPostgresDBAudienceRepository(pool)
+		stored, err := repo.Create(audience.Audience{Id: uuid.New(), Gender: "Female", BirthCountry: "GB", AgeGroup: "25-34"})
+		require.NoError(t, err)
+		_, err = favourite.NewPostgresDBFavouriteRepository(pool).Create(favourite.Favourite{
+			Id: uuid.New(), UserId: uuid.New(), AssetId: stored.Id, AssetType: favourite.AssetTypeAudience, Rank: "V", Tags: []string{},
+		})
+		require.NoError(t, err)
+
+		// Act
+		err = repo.Delete(stored.Id)
+
+		// Assert
+		assert.ErrorIs(t, err, audience.ErrAudienceFavourited)
+		_, err = repo.GetById(stored.Id)
+		assert.NoError(t, err)
+	})
+}
@@ -3,6 +3,7 @@ package audience_test
 import (
 	"platform-go-challenge/internal/database"
 	"platform-go-challenge/internal/domain/audience"
+	"platform-go-challenge/test/conformance"
 	"testing"
 
 	"github.com/google/uuid"
@@ -16,7 +17,7 @@ func TestGetByIds(t *testing.T) {
 		aud2ID := uuid.New()
 
 		mockDB := &database.IMDatabase{
-			AudienceStorage: map[uuid.UUID]database.IMAudienceModel{
+			AudienceStorage: database.NewIMStorage(map[uuid.UUID]database.IMAudienceModel{
 				aud1ID: {
 					Id:                 aud1ID,
 					Gender:             "Female",
@@ -33,7 +34,7 @@ func TestGetByIds(t *testing.T) {
 					SocialMediaHours:   3.0,
 					PurchasesLastMonth: 4,
 				},
-			},
+			}),
 		}
 
 		repo := audience.NewInMemoryDBAudienceRepository(mockDB)
@@ -70,7 +71,7 @@ func TestGetByIds(t *testing.T) {
 		missingID := uuid.New()
 
 		mockDB := &database.IMDatabase{
-			AudienceStorage: map[uuid.UUID]database.IMAudienceModel{
+			AudienceStorage: database.NewIMStorage(map[uuid.UUID]database.IMAudienceModel{
 				aud1ID: {
 					Id:                 aud1ID,
 					Gender:             "Female",
@@ -79,7 +80,7 @@ func TestGetByIds(t *testing.T) {
 					SocialMediaHours:   2.0,
 					PurchasesLastMonth: 1,
 				},
-			},
+			}),
 		}
 
 		repo := audience.NewInMemoryDBAudienceRepository(mockDB)
@@ -105,7 +106,7 @@ func TestGetByIds(t *testing.T) {
 	t.Run("should return empty slice when input is empty", func(t *testing.T) {
 		// Arrange
 		mockDB := &database.IMDatabase{
-			AudienceStorage: map[uuid.UUID]database.IMAudienceModel{},
+			AudienceStorage: database.NewIMStorage(map[uuid.UUID]database.IMAudienceModel{}),
 		}
 		repo := audience.NewInMemoryDBAudienceRepository(mockDB)
 
@@ -122,7 +123,7 @@ func TestGetById(t *testing.T) {
 		// Arrange
 		audID := uuid.New()
 		mockDB := &database.IMDatabase{
-			AudienceStorage: map[uuid.UUID]database.IMAudienceModel{
+			AudienceStorage: database.NewIMStorage(map[uuid.UUID]database.IMAudienceModel{
 				audID: {
 					Id:                 audID,
 					Gender:             "Other",
@@ -131,7 +132,7 @@ func TestGetById(t *testing.T) {
 					SocialMediaHours:   1.2,
 					PurchasesLastMonth: 0,
 				},
-			},
+			}),
 		}
 
 		repo := audience.NewInMemoryDBAudienceRepository(mockDB)
@@ -156,7 +157,7 @@ func TestGetById(t *testing.T) {
 	t.Run("should return error when ID does not exist", func(t *testing.T) {
 		// Arrange
 		mockDB := &database.IMDatabase{
-			AudienceStorage: map[uuid.UUID]database.IMAudienceModel{},
+			AudienceStorage: database.NewIMStorage(map[uuid.UUID]database.IMAudienceModel{}),
 		}
 		repo := audience.NewInMemoryDBAudienceRepository(mockDB)
 
@@ -170,3 +171,73 @@ func TestGetById(t *testing.T) {
 		assert.Nil(t, result)
 	})
 }
+
+func TestDelete(t *testing.T) {
+	newDB := func(audienceId uuid.UUID, favourites ...database.IMFavouriteModel) *database.IMDatabase {
+		db := database.NewIMDatabase()
+		db.AudienceStorage.Set(audienceId, database.IMAudienceModel{Id: audienceId, Gender: "Female"})
+		for _, fav := range favourites {
+			db.FavouriteStorage.Set(fav.Id, fav)
+		}
+		return db
+	}
+
+	t.Run("should delete an audience no user has a favourite of", func(t *testing.T) {
+		// Arrange
+		audienceId := uuid.New()
+		db := newDB(audienceId, database.IMFavouriteModel{Id: uuid.New(), UserId: uuid.New(), AssetId: uuid.New(), AssetType: "audience"})
+		repo := audience.NewInMemoryDBAudienceRepository(db)
+
+		// Act
+		err := repo.Delete(audienceId)
+
+		// Assert
+		assert.NoError(t, err)
+		_, found := db.AudienceStorage.Get(audienceId)
+		assert.False(t, found)
+	})
+
+	t.Run("should return error and keep the audience while a user has a favourite of it", func(t *testing.T) {
+		// Arrange
+		audienceId := uuid.New()
+		db := newDB(audienceId, database.IMFavouriteModel{Id: uuid.New(), UserId: uuid.New(), AssetId: audienceId, AssetType: "audience"})
+		repo := audience.NewInMemoryDBAudienceRepository(db)
+
+		// Act
+		err := repo.Delete(audienceId)
+
+		// Assert
+		assert.ErrorIs(t, err, audience.ErrAudienceFavourited)
+		_, found := db.AudienceStorage.Get(audienceId)
+		assert.True(t, found)
+	})
+
+	t.Run("should return not found error when the audience does not exist", func(t *testing.T) {
+		// Arrange
+		repo := audience.NewInMemoryDBAudienceRepository(newDB(uuid.New()))
+
+		// Act
+		err := repo.Delete(uuid.New())
+
+		// Assert
+		assert.ErrorIs(t, err, audience.ErrAudienceNotFound)
+	})
+}
+
+func TestInMemoryDBAudienceRepositoryConformance(t *testing.T) {
+	conformance.AudienceRepository(t, func(t *testing.T, audiences []audience.Audience) audience.AudienceRepository {
+		db := database.NewIMDatabase()
+		for _, a := range audiences {
+			db.AudienceStorage.Set(a.Id, database.IMAudienceModel{
+				Id:                 a.Id,
+				Gender:             a.Gender,
+				BirthCountry:       a.BirthCountry,
+				AgeGroup:           a.AgeGroup,
+				SocialMediaHours:   a.SocialMediaHours,
+				PurchasesLastMonth: a.PurchasesLastMonth,
+			})
+		}
+
+		return audience.NewInMemoryDBAudienceRepository(db)
+	})
+}
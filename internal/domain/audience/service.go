@@ -0,0 +1,114 @@
+package audience
+
+import (
+	"errors"
+	"platform-go-challenge/internal/database"
+	"platform-go-challenge/internal/utils"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// AudienceService manages the audiences for the admin endpoints, the favourites read them through AudienceRepository.
+type AudienceService interface {
+	GetPaginated(pageSize int, pageNumber int) ([]Audience, *utils.Pagination, error)
+	Get(audienceId uuid.UUID) (*Audience, error)
+	// Create stores the audience under a new id, it fails with FieldErrors when a characteristic is not valid
+	Create(audience Audience) (*Audience, error)
+	// Update checks only the characteristics it changes, it fails with FieldErrors when one of them is not valid
+	Update(audienceId uuid.UUID, changes AudienceChanges) (*Audience, error)
+	// Delete fails with ErrAudienceFavourited while a user has a favourite of the audience, the trash left out
+	Delete(audienceId uuid.UUID) error
+}
+
+type AudienceServiceDependencies struct {
+	AudienceRepository AudienceRepository
+}
+
+type audienceService struct {
+	Dependencies AudienceServiceDependencies
+}
+
+func NewAudienceService(dependencies AudienceServiceDependencies) audienceService {
+	return audienceService{
+		Dependencies: dependencies,
+	}
+}
+
+func (service *audienceService) GetPaginated(pageSize int, pageNumber int) ([]Audience, *utils.Pagination, error) {
+	audiences, pagination, err := service.Dependencies.AudienceRepository.GetPaginated(pageSize, pageNumber)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return audiences, &pagination, nil
+}
+
+func (service *audienceService) Get(audienceId uuid.UUID) (*Audience, error) {
+	audience, err := service.Dependencies.AudienceRepository.GetById(audienceId)
+	if err != nil {
+		if errors.Is(err, database.ErrItemNotFound) {
+			return nil, ErrAudienceNotFound
+		}
+
+		return nil, utils.ErrUnexpected
+	}
+
+	return audience, nil
+}
+
+func (service *audienceService) Create(audience Audience) (*Audience, error) {
+	audience.Id = uuid.New()
+
+	errs := fieldErrors(audience, "gender", "birth_country", "age_group", "social_media_hours", "purchases_last_month")
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	audience.BirthCountry = strings.ToUpper(audience.BirthCountry)
+
+	created, err := service.Dependencies.AudienceRepository.Create(audience)
+	if err != nil {
+		return nil, ErrCouldNotSaveAudience
+	}
+
+	return created, nil
+}
+
+func (service *audienceService) Update(audienceId uuid.UUID, changes AudienceChanges) (*Audience, error) {
+	audience, err := service.Get(audienceId)
+	if err != nil {
+		return nil, err
+	}
+
+	changed := []string{}
+	if changes.Gender != nil {
+		audience.Gender = *changes.Gender
+		changed = append(changed, "gender")
+	}
+	if changes.BirthCountry != nil {
+		audience.BirthCountry = strings.ToUpper(*changes.BirthCountry)
+		changed = append(changed, "birth_country")
+	}
+	if changes.AgeGroup != nil {
+		audience.AgeGroup = *changes.AgeGroup
+		changed = append(changed, "age_group")
+	}
+	if changes.SocialMediaHours != nil {
+		audience.SocialMediaHours = *changes.SocialMediaHours
+		changed = append(changed, "social_media_hours")
+	}
+	if changes.PurchasesLastMonth != nil {
+		audience.PurchasesLastMonth = *changes.PurchasesLastMonth
+		changed = append(changed, "purchases_last_month")
+	}
+
+	if errs := fieldErrors(*audience, changed...); len(errs) > 0 {
+		return nil, errs
+	}
+
+	return service.Dependencies.AudienceRepository.Update(*audience)
+}
+
+func (service *audienceService) Delete(audienceId uuid.UUID) error {
+	return service.Dependencies.AudienceRepository.Delete(audienceId)
+}
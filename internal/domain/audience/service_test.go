@@ -0,0 +1,196 @@
+package audience_test
+
+import (
+	"errors"
+	"platform-go-challenge/internal/database"
+	"platform-go-challenge/internal/domain/audience"
+	"platform-go-challenge/internal/utils"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockAudienceRepo struct {
+	audience.AudienceRepository
+	getByIdFn func(id uuid.UUID) (*audience.Audience, error)
+	createFn  func(a audience.Audience) (*audience.Audience, error)
+	updateFn  func(a audience.Audience) (*audience.Audience, error)
+	deleteFn  func(id uuid.UUID) error
+}
+
+func (m *mockAudienceRepo) GetById(id uuid.UUID) (*audience.Audience, error) {
+	return m.getByIdFn(id)
+}
+
+func (m *mockAudienceRepo) Create(a audience.Audience) (*audience.Audience, error) {
+	return m.createFn(a)
+}
+
+func (m *mockAudienceRepo) Update(a audience.Audience) (*audience.Audience, error) {
+	return m.updateFn(a)
+}
+
+func (m *mockAudienceRepo) Delete(id uuid.UUID) error {
+	return m.deleteFn(id)
+}
+
+func storedAudienceRepo(stored *audience.Audience) *mockAudienceRepo {
+	return &mockAudienceRepo{
+		getByIdFn: func(id uuid.UUID) (*audience.Audience, error) {
+			if stored == nil || stored.Id != id {
+				return nil, database.ErrItemNotFound
+			}
+			copied := *stored
+			return &copied, nil
+		},
+		updateFn: func(a audience.Audience) (*audience.Audience, error) { return &a, nil },
+	}
+}
+
+func TestCreateAudienceService(t *testing.T) {
+	valid := audience.Audience{
+		Gender:             "Female",
+		BirthCountry:       "gr",
+		AgeGroup:           "25-34",
+		SocialMediaHours:   2.5,
+		PurchasesLastMonth: 4,
+	}
+
+	t.Run("should create the audience under a new id with an upper case country", func(t *testing.T) {
+		// Arrange
+		service := audience.NewAudienceService(audience.AudienceServiceDependencies{
+			AudienceRepository: &mockAudienceRepo{
+				createFn: func(a audience.Audience) (*audience.Audience, error) { return &a, nil },
+			},
+		})
+
+		// Act
+		result, err := service.Create(valid)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.NotEqual(t, uuid.Nil, result.Id)
+		assert.Equal(t, "GR", result.BirthCountry)
+		assert.Equal(t, "25-34", result.AgeGroup)
+	})
+
+	t.Run("should return the field errors of every invalid characteristic", func(t *testing.T) {
+		// Arrange
+		service := audience.NewAudienceService(audience.AudienceServiceDependencies{})
+
+		// Act
+		result, err := service.Create(audience.Audience{
+			Gender:             "female",
+			BirthCountry:       "Greece",
+			AgeGroup:           "34-25",
+			SocialMediaHours:   -1,
+			PurchasesLastMonth: -1,
+		})
+
+		// Assert
+		assert.Nil(t, result)
+		var fieldErrs utils.FieldErrors
+		assert.ErrorAs(t, err, &fieldErrs)
+		assert.Equal(t, utils.FieldErrors{
+			"gender":               "must be one of Female, Male, Non-binary or Other",
+			"birth_country":        "must be an ISO 3166-1 alpha-2 country code like GB",
+			"age_group":            "must be an age range like 25-34, or like 65+ for no upper bound",
+			"social_media_hours":   "must be at least 0",
+			"purchases_last_month": "must be at least 0",
+		}, fieldErrs)
+	})
+
+	t.Run("should accept an age group without upper bound", func(t *testing.T) {
+		// Arrange
+		service := audience.NewAudienceService(audience.AudienceServiceDependencies{
+			AudienceRepository: &mockAudienceRepo{
+				createFn: func(a audience.Audience) (*audience.Audience, error) { return &a, nil },
+			},
+		})
+		open := valid
+		open.AgeGroup = "65+"
+
+		// Act
+		result, err := service.Create(open)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, "65+", result.AgeGroup)
+	})
+
+	t.Run("should return error when the repository fails", func(t *testing.T) {
+		// Arrange
+		service := audience.NewAudienceService(audience.AudienceServiceDependencies{
+			AudienceRepository: &mockAudienceRepo{
+				createFn: func(audience.Audience) (*audience.Audience, error) { return nil, errors.New("db down") },
+			},
+		})
+
+		// Act
+		result, err := service.Create(valid)
+
+		// Assert
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, audience.ErrCouldNotSaveAudience)
+	})
+}
+
+func TestUpdateAudienceService(t *testing.T) {
+	// Stored before the characteristics were checked, so its country is not a code
+	stored := audience.Audience{
+		Id:                 uuid.New(),
+		Gender:             "Male",
+		BirthCountry:       "United Kingdom",
+		AgeGroup:           "25-34",
+		SocialMediaHours:   4,
+		PurchasesLastMonth: 10,
+	}
+
+	t.Run("should change only the given fields without checking the others", func(t *testing.T) {
+		// Arrange
+		service := audience.NewAudienceService(audience.AudienceServiceDependencies{AudienceRepository: storedAudienceRepo(&stored)})
+		ageGroup := "35-44"
+
+		// Act
+		result, err := service.Update(stored.Id, audience.AudienceChanges{AgeGroup: &ageGroup})
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, "35-44", result.AgeGroup)
+		assert.Equal(t, "United Kingdom", result.BirthCountry)
+		assert.Equal(t, "Male", result.Gender)
+	})
+
+	t.Run("should return the field errors of the changed fields", func(t *testing.T) {
+		// Arrange
+		service := audience.NewAudienceService(audience.AudienceServiceDependencies{AudienceRepository: storedAudienceRepo(&stored)})
+		country := "XX"
+		purchases := -3
+
+		// Act
+		result, err := service.Update(stored.Id, audience.AudienceChanges{BirthCountry: &country, PurchasesLastMonth: &purchases})
+
+		// Assert
+		assert.Nil(t, result)
+		var fieldErrs utils.FieldErrors
+		assert.ErrorAs(t, err, &fieldErrs)
+		assert.Equal(t, utils.FieldErrors{
+			"birth_country":        "must be an ISO 3166-1 alpha-2 country code like GB",
+			"purchases_last_month": "must be at least 0",
+		}, fieldErrs)
+	})
+
+	t.Run("should return error when audience not found", func(t *testing.T) {
+		// Arrange
+		service := audience.NewAudienceService(audience.AudienceServiceDependencies{AudienceRepository: storedAudienceRepo(nil)})
+		gender := "Female"
+
+		// Act
+		result, err := service.Update(stored.Id, audience.AudienceChanges{Gender: &gender})
+
+		// Assert
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, audience.ErrAudienceNotFound)
+	})
+}
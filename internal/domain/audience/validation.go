@@ -0,0 +1,31 @@
+package audience
+
+import (
+	"platform-go-challenge/internal/utils"
+
+	"github.com/go-playground/validator/v10"
+)
+
+func init() {
+	utils.RegisterValidation("audience_gender", stringRule(utils.IsAudienceGender), utils.AudienceGenderMessage)
+	utils.RegisterValidation("age_group", stringRule(utils.IsAgeGroup), utils.AgeGroupMessage)
+	utils.RegisterValidation("country", stringRule(utils.IsCountry), utils.CountryMessage)
+}
+
+func stringRule(valid func(value string) bool) validator.Func {
+	return func(fl validator.FieldLevel) bool {
+		return valid(fl.Field().String())
+	}
+}
+
+// fieldErrors checks the characteristics of the audience named in fields, by their JSON names.
+// The ones left out are not checked, so an update does not reject what was stored before the checks existed.
+func fieldErrors(audience Audience, fields ...string) utils.FieldErrors {
+	return utils.AudienceCharacteristics{
+		Gender:             audience.Gender,
+		BirthCountry:       audience.BirthCountry,
+		AgeGroup:           audience.AgeGroup,
+		SocialMediaHours:   audience.SocialMediaHours,
+		PurchasesLastMonth: audience.PurchasesLastMonth,
+	}.FieldErrors(fields...)
+}
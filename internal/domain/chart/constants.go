@@ -0,0 +1,18 @@
+package chart
+
+// ChartSchemaVersion is the version of the chart shape with named series and typed axes. The charts stored
+// before it, with a list of x and y maps, are version 1 and are converted when they are read.
+const ChartSchemaVersion = 2
+
+const (
+	ChartKindLine    ChartKind = "line"
+	ChartKindBar     ChartKind = "bar"
+	ChartKindArea    ChartKind = "area"
+	ChartKindScatter ChartKind = "scatter"
+)
+
+const (
+	AxisTypeNumeric  AxisType = "numeric"
+	AxisTypeTime     AxisType = "time"
+	AxisTypeCategory AxisType = "category"
+)
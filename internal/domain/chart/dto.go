@@ -1,14 +1,194 @@
 package chart
 
-import "github.com/google/uuid"
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"platform-go-challenge/internal/utils"
 
-type (
-	ChartData []map[string]float64
-	Chart     struct {
-		Id         uuid.UUID            `json:"id"`
-		Title      string               `json:"title"`
-		XAxisTitle string               `json:"x_axis_title"`
-		YAxisTitle string               `json:"y_axis_title"`
-		Data       []map[string]float64 `json:"data"`
-	}
+	"github.com/google/uuid"
 )
+
+// ChartKind is how a chart is drawn.
+type ChartKind string
+
+// AxisType tells what the values along an axis are, the y axis is always numeric.
+type AxisType string
+
+type Chart struct {
+	Id            uuid.UUID     `json:"id"`
+	SchemaVersion int           `json:"schema_version"`
+	Title         string        `json:"title"`
+	Kind          ChartKind     `json:"kind"`
+	XAxis         ChartAxis     `json:"x_axis"`
+	YAxis         ChartAxis     `json:"y_axis"`
+	Series        []ChartSeries `json:"series"`
+}
+
+// AssetId identifies the chart as the asset of a favourite.
+func (chart Chart) AssetId() uuid.UUID {
+	return chart.Id
+}
+
+type ChartAxis struct {
+	Title string   `json:"title" validate:"required,max=100"`
+	Type  AxisType `json:"type" validate:"required,oneof=numeric time category"`
+	// Unit is what the values along the axis are measured in, like EUR or ms, it can be left empty
+	Unit string `json:"unit" validate:"max=20"`
+}
+
+// ChartSeries is a named line, set of bars or area of a chart.
+type ChartSeries struct {
+	Name   string       `json:"name"`
+	Points []ChartPoint `json:"points"`
+}
+
+type ChartPoint struct {
+	X ChartX  `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// ChartX is the x of a point: a number on a numeric axis, an RFC 3339 time on a time axis
+// and a label on a category axis. The last two are sent as JSON strings.
+type ChartX struct {
+	number float64
+	text   string
+	isText bool
+}
+
+func NumberX(value float64) ChartX {
+	return ChartX{number: value}
+}
+
+func TextX(value string) ChartX {
+	return ChartX{text: value, isText: true}
+}
+
+// Number returns the x of a point on a numeric axis, ok is false when the x is a text.
+func (x ChartX) Number() (value float64, ok bool) {
+	return x.number, !x.isText
+}
+
+// Text returns the x of a point on a time or category axis, ok is false when the x is a number.
+func (x ChartX) Text() (value string, ok bool) {
+	return x.text, x.isText
+}
+
+func (x ChartX) MarshalJSON() ([]byte, error) {
+	if x.isText {
+		return json.Marshal(x.text)
+	}
+
+	return json.Marshal(x.number)
+}
+
+func (x *ChartX) UnmarshalJSON(data []byte) error {
+	if bytes.HasPrefix(data, []byte(`"`)) {
+		var text string
+		if err := json.Unmarshal(data, &text); err != nil {
+			return err
+		}
+		*x = TextX(text)
+		return nil
+	}
+
+	var number float64
+	if err := json.Unmarshal(data, &number); err != nil {
+		return errors.New("x must be a number or a string")
+	}
+	*x = NumberX(number)
+
+	return nil
+}
+
+// ChartChanges are the fields an update sets, nil fields are left as they are.
+type ChartChanges struct {
+	Title *string
+	Kind  *ChartKind
+	XAxis *ChartAxis
+	YAxis *ChartAxis
+	// Series replaces every series of the chart, an empty slice removes them all
+	Series []ChartSeries
+}
+
+// ChartSeriesBody is a series of a request body.
+type ChartSeriesBody struct {
+	Name   string           `json:"name" validate:"required,max=100"`
+	Points []ChartPointBody `json:"points" validate:"max=1000,dive"`
+}
+
+// ChartPointBody takes x and y as pointers, so a missing one is told apart from 0.
+type ChartPointBody struct {
+	X *ChartX  `json:"x" validate:"required"`
+	Y *float64 `json:"y" validate:"required"`
+}
+
+type CreateChartRequestBody struct {
+	Title  string            `json:"title" validate:"required,max=200"`
+	Kind   ChartKind         `json:"kind" validate:"required,oneof=line bar area scatter"`
+	XAxis  ChartAxis         `json:"x_axis"`
+	YAxis  ChartAxis         `json:"y_axis"`
+	Series []ChartSeriesBody `json:"series" validate:"max=20,dive"`
+}
+
+// UpdateChartRequestBody is a merge patch, it leaves out the fields that are not changed, see UpdateChartRequestBody.Changes.
+// An axis is replaced as a whole.
+type UpdateChartRequestBody struct {
+	utils.MergePatch
+	Title  *string           `json:"title" validate:"omitnil,max=200"`
+	Kind   *ChartKind        `json:"kind" validate:"omitnil,oneof=line bar area scatter"`
+	XAxis  *ChartAxis        `json:"x_axis"`
+	YAxis  *ChartAxis        `json:"y_axis"`
+	Series []ChartSeriesBody `json:"series" validate:"max=20,dive"`
+}
+
+// Changes returns the changes of the patch. The title, the kind and the axes cannot be cleared, so when they are
+// set to null they are blank and rejected, while null series remove every series.
+func (body UpdateChartRequestBody) Changes() ChartChanges {
+	changes := ChartChanges{}
+
+	if body.Has("title") {
+		changes.Title = body.Title
+		if changes.Title == nil {
+			changes.Title = new(string)
+		}
+	}
+	if body.Has("kind") {
+		changes.Kind = body.Kind
+		if changes.Kind == nil {
+			changes.Kind = new(ChartKind)
+		}
+	}
+	if body.Has("x_axis") {
+		changes.XAxis = body.XAxis
+		if changes.XAxis == nil {
+			changes.XAxis = &ChartAxis{}
+		}
+	}
+	if body.Has("y_axis") {
+		changes.YAxis = body.YAxis
+		if changes.YAxis == nil {
+			changes.YAxis = &ChartAxis{}
+		}
+	}
+	if body.Has("series") {
+		changes.Series = SeriesFromBody(body.Series)
+	}
+
+	return changes
+}
+
+// SeriesFromBody returns the series of a request body, which is validated to have every x and y.
+func SeriesFromBody(body []ChartSeriesBody) []ChartSeries {
+	result := []ChartSeries{}
+	for _, series := range body {
+		points := []ChartPoint{}
+		for _, point := range series.Points {
+			points = append(points, ChartPoint{X: *point.X, Y: *point.Y})
+		}
+
+		result = append(result, ChartSeries{Name: series.Name, Points: points})
+	}
+
+	return result
+}
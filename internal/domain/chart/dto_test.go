@@ -0,0 +1,81 @@
+package chart_test
+
+import (
+	"encoding/json"
+	"platform-go-challenge/internal/domain/chart"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChartJSON(t *testing.T) {
+	id := uuid.MustParse("11111111-1111-1111-1111-111111111111")
+
+	t.Run("should serialise the schema version, the axes and the series with snake_case keys", func(t *testing.T) {
+		// Arrange
+		value := chart.Chart{
+			Id:            id,
+			SchemaVersion: chart.ChartSchemaVersion,
+			Title:         "Revenue",
+			Kind:          chart.ChartKindBar,
+			XAxis:         chart.ChartAxis{Title: "Month", Type: chart.AxisTypeCategory},
+			YAxis:         chart.ChartAxis{Title: "Revenue", Type: chart.AxisTypeNumeric, Unit: "EUR"},
+			Series:        []chart.ChartSeries{{Name: "2024", Points: []chart.ChartPoint{{X: chart.TextX("Jan"), Y: 10.5}}}},
+		}
+
+		// Act
+		result, err := json.Marshal(value)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{
+			"id":"11111111-1111-1111-1111-111111111111",
+			"schema_version":2,
+			"title":"Revenue",
+			"kind":"bar",
+			"x_axis":{"title":"Month","type":"category","unit":""},
+			"y_axis":{"title":"Revenue","type":"numeric","unit":"EUR"},
+			"series":[{"name":"2024","points":[{"x":"Jan","y":10.5}]}]
+		}`, string(result))
+	})
+}
+
+func TestChartXJSON(t *testing.T) {
+	t.Run("should read a number as a numeric x and a string as a text x", func(t *testing.T) {
+		// Arrange
+		var points []chart.ChartPoint
+
+		// Act
+		err := json.Unmarshal([]byte(`[{"x": 3, "y": 1}, {"x": "2024-01-01T00:00:00Z", "y": 2}]`), &points)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, []chart.ChartPoint{
+			{X: chart.NumberX(3), Y: 1},
+			{X: chart.TextX("2024-01-01T00:00:00Z"), Y: 2},
+		}, points)
+	})
+
+	t.Run("should write a numeric x as a number and a text x as a string", func(t *testing.T) {
+		// Act
+		result, err := json.Marshal([]chart.ChartX{chart.NumberX(1.5), chart.TextX("Jan")})
+
+		// Assert
+		assert.NoError(t, err)
+		assert.JSONEq(t, `[1.5, "Jan"]`, string(result))
+	})
+
+	t.Run("should return error when x is neither a number nor a string", func(t *testing.T) {
+		for _, value := range []string{`true`, `{}`, `[1]`} {
+			// Arrange
+			var x chart.ChartX
+
+			// Act
+			err := json.Unmarshal([]byte(value), &x)
+
+			// Assert
+			assert.EqualError(t, err, "x must be a number or a string", value)
+		}
+	})
+}
@@ -0,0 +1,14 @@
+package chart
+
+import "errors"
+
+var (
+	ErrChartNotFound      = errors.New("Chart not found")
+	ErrInvalidChartTitle  = errors.New("Chart title and axis titles must not be blank")
+	ErrInvalidChartKind   = errors.New("Chart kind must be one of line, bar, area, scatter")
+	ErrInvalidChartAxis   = errors.New("Chart x axis type must be one of numeric, time, category and the y axis must be numeric")
+	ErrInvalidChartSeries = errors.New("Every chart series must have a name of its own")
+	ErrInvalidChartData   = errors.New("Every chart point must have an x of the type of the x axis and a numeric y")
+	ErrCouldNotSaveChart  = errors.New("Could not save chart")
+	ErrChartFavourited    = errors.New("Chart is a favourite of some users")
+)
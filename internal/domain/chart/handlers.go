@@ -0,0 +1,159 @@
+package chart
+
+import (
+	"errors"
+	"net/http"
+	"platform-go-challenge/internal/utils"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// respondWithChartError answers the errors every write of a chart can fail with.
+func respondWithChartError(w http.ResponseWriter, err error) {
+	if errors.Is(err, ErrChartNotFound) {
+		utils.RespondWithError(w, http.StatusNotFound, "Could not find Chart with this Id")
+		return
+	}
+	if errors.Is(err, ErrChartFavourited) {
+		utils.RespondWithError(w, http.StatusConflict, "Chart is a favourite of some users, it cannot be deleted")
+		return
+	}
+	if errors.Is(err, ErrInvalidChartTitle) || errors.Is(err, ErrInvalidChartKind) || errors.Is(err, ErrInvalidChartAxis) ||
+		errors.Is(err, ErrInvalidChartSeries) || errors.Is(err, ErrInvalidChartData) {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.RespondWithError(w, http.StatusInternalServerError, "Internal Server Error")
+}
+
+type GetChartsHandlerDependencies struct {
+	ChartService ChartService
+}
+
+func GetChartsHandler(dependencies GetChartsHandlerDependencies) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		pageSize, pageNumber, err := utils.GetPaginationQuery(r, 10, 0)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		charts, pagination, err := dependencies.ChartService.GetPaginated(pageSize, pageNumber)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusInternalServerError, "Internal Server Error")
+			return
+		}
+
+		utils.RespondWithPaginatedData(w, http.StatusOK, charts, *pagination)
+	}
+}
+
+type GetChartHandlerDependencies struct {
+	ChartService ChartService
+}
+
+func GetChartHandler(dependencies GetChartHandlerDependencies) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		chartId, err := uuid.Parse(chi.URLParam(r, "id"))
+		if err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "Chart Id param is not a UUID")
+			return
+		}
+
+		chart, err := dependencies.ChartService.Get(chartId)
+		if err != nil {
+			respondWithChartError(w, err)
+			return
+		}
+
+		utils.RespondWithData(w, http.StatusOK, chart)
+	}
+}
+
+type CreateChartHandlerDependencies struct {
+	ChartService ChartService
+}
+
+func CreateChartHandler(dependencies CreateChartHandlerDependencies) http.HandlerFunc {
+	validation := utils.BodyValidator[CreateChartRequestBody]
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		body, ok := utils.GetParsedBody[CreateChartRequestBody](r)
+		if !ok {
+			// Should not happen since we validate body before getting in to handler
+			utils.RespondWithError(w, http.StatusInternalServerError, "Internal Server Error")
+			return
+		}
+
+		chart, err := dependencies.ChartService.Create(Chart{
+			Title:  body.Title,
+			Kind:   body.Kind,
+			XAxis:  body.XAxis,
+			YAxis:  body.YAxis,
+			Series: SeriesFromBody(body.Series),
+		})
+		if err != nil {
+			respondWithChartError(w, err)
+			return
+		}
+
+		utils.RespondWithData(w, http.StatusCreated, chart)
+	}
+
+	return validation(handler)
+}
+
+type UpdateChartHandlerDependencies struct {
+	ChartService ChartService
+}
+
+func UpdateChartHandler(dependencies UpdateChartHandlerDependencies) http.HandlerFunc {
+	validation := utils.BodyValidator[UpdateChartRequestBody]
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		chartId, err := uuid.Parse(chi.URLParam(r, "id"))
+		if err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "Chart Id param is not a UUID")
+			return
+		}
+
+		body, ok := utils.GetParsedBody[UpdateChartRequestBody](r)
+		if !ok {
+			// Should not happen since we validate body before getting in to handler
+			utils.RespondWithError(w, http.StatusInternalServerError, "Internal Server Error")
+			return
+		}
+
+		chart, err := dependencies.ChartService.Update(chartId, body.Changes())
+		if err != nil {
+			respondWithChartError(w, err)
+			return
+		}
+
+		utils.RespondWithData(w, http.StatusOK, chart)
+	}
+
+	return validation(handler)
+}
+
+type DeleteChartHandlerDependencies struct {
+	ChartService ChartService
+}
+
+func DeleteChartHandler(dependencies DeleteChartHandlerDependencies) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		chartId, err := uuid.Parse(chi.URLParam(r, "id"))
+		if err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "Chart Id param is not a UUID")
+			return
+		}
+
+		err = dependencies.ChartService.Delete(chartId)
+		if err != nil {
+			respondWithChartError(w, err)
+			return
+		}
+
+		utils.RespondWithMessage(w, http.StatusOK, "Chart deleted")
+	}
+}
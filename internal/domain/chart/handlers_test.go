@@ -0,0 +1,325 @@
+package chart_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"platform-go-challenge/internal/domain/chart"
+	"platform-go-challenge/internal/utils"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+type StubChartService struct {
+	GetPaginatedFunc func(pageSize, pageNumber int) ([]chart.Chart, *utils.Pagination, error)
+	GetFunc          func(chartId uuid.UUID) (*chart.Chart, error)
+	CreateFunc       func(c chart.Chart) (*chart.Chart, error)
+	UpdateFunc       func(chartId uuid.UUID, changes chart.ChartChanges) (*chart.Chart, error)
+	DeleteFunc       func(chartId uuid.UUID) error
+}
+
+func (s *StubChartService) GetPaginated(pageSize, pageNumber int) ([]chart.Chart, *utils.Pagination, error) {
+	if s.GetPaginatedFunc != nil {
+		return s.GetPaginatedFunc(pageSize, pageNumber)
+	}
+	return nil, nil, errors.New("not implemented")
+}
+
+func (s *StubChartService) Get(chartId uuid.UUID) (*chart.Chart, error) {
+	if s.GetFunc != nil {
+		return s.GetFunc(chartId)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (s *StubChartService) Create(c chart.Chart) (*chart.Chart, error) {
+	if s.CreateFunc != nil {
+		return s.CreateFunc(c)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (s *StubChartService) Update(chartId uuid.UUID, changes chart.ChartChanges) (*chart.Chart, error) {
+	if s.UpdateFunc != nil {
+		return s.UpdateFunc(chartId, changes)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (s *StubChartService) Delete(chartId uuid.UUID) error {
+	if s.DeleteFunc != nil {
+		return s.DeleteFunc(chartId)
+	}
+	return errors.New("not implemented")
+}
+
+// withChartId sets the id route param chi would have matched.
+func withChartId(req *http.Request, chartId string) *http.Request {
+	ctx := chi.NewRouteContext()
+	ctx.URLParams.Add("id", chartId)
+
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, ctx))
+}
+
+func TestGetChartsHandler(t *testing.T) {
+	t.Run("Should return 200 with the page of charts", func(t *testing.T) {
+		// Arrange
+		charts := []chart.Chart{salesChart()}
+		stubService := &StubChartService{
+			GetPaginatedFunc: func(pageSize, pageNumber int) ([]chart.Chart, *utils.Pagination, error) {
+				assert.Equal(t, 5, pageSize)
+				assert.Equal(t, 1, pageNumber)
+				return charts, &utils.Pagination{Page: 1, PageSize: 5, MaxPage: 1}, nil
+			},
+		}
+		handler := chart.GetChartsHandler(chart.GetChartsHandlerDependencies{ChartService: stubService})
+
+		req := httptest.NewRequest(http.MethodGet, "/admin/charts?pageSize=5&pageNumber=1", nil)
+		w := httptest.NewRecorder()
+
+		// Act
+		handler(w, req)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+		var response utils.PaginatedDataResponse[[]chart.Chart]
+		assert.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+		assert.Equal(t, charts, response.Data)
+		assert.Equal(t, utils.Pagination{Page: 1, PageSize: 5, MaxPage: 1}, response.Pagination)
+	})
+}
+
+func TestGetChartHandler(t *testing.T) {
+	t.Run("Should return 404 when chart not found", func(t *testing.T) {
+		// Arrange
+		stubService := &StubChartService{
+			GetFunc: func(uuid.UUID) (*chart.Chart, error) { return nil, chart.ErrChartNotFound },
+		}
+		handler := chart.GetChartHandler(chart.GetChartHandlerDependencies{ChartService: stubService})
+
+		req := withChartId(httptest.NewRequest(http.MethodGet, "/admin/charts", nil), uuid.NewString())
+		w := httptest.NewRecorder()
+
+		// Act
+		handler(w, req)
+
+		// Assert
+		assert.Equal(t, http.StatusNotFound, w.Result().StatusCode)
+	})
+
+	t.Run("Should return 400 when id is not a UUID", func(t *testing.T) {
+		// Arrange
+		handler := chart.GetChartHandler(chart.GetChartHandlerDependencies{ChartService: &StubChartService{}})
+
+		req := withChartId(httptest.NewRequest(http.MethodGet, "/admin/charts", nil), "not-a-uuid")
+		w := httptest.NewRecorder()
+
+		// Act
+		handler(w, req)
+
+		// Assert
+		assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
+	})
+}
+
+func TestCreateChartHandler(t *testing.T) {
+	t.Run("Should return 201 when chart is created successfully", func(t *testing.T) {
+		// Arrange
+		expected := &chart.Chart{
+			Id:            uuid.New(),
+			SchemaVersion: chart.ChartSchemaVersion,
+			Title:         "Revenue",
+			Kind:          chart.ChartKindBar,
+			XAxis:         chart.ChartAxis{Title: "Month", Type: chart.AxisTypeCategory},
+			YAxis:         chart.ChartAxis{Title: "Revenue", Type: chart.AxisTypeNumeric, Unit: "EUR"},
+			Series:        []chart.ChartSeries{{Name: "2024", Points: []chart.ChartPoint{{X: chart.TextX("Jan"), Y: 0}}}},
+		}
+		stubService := &StubChartService{
+			CreateFunc: func(c chart.Chart) (*chart.Chart, error) {
+				assert.Equal(t, "Revenue", c.Title)
+				assert.Equal(t, expected.Kind, c.Kind)
+				assert.Equal(t, expected.XAxis, c.XAxis)
+				assert.Equal(t, expected.YAxis, c.YAxis)
+				assert.Equal(t, expected.Series, c.Series)
+				return expected, nil
+			},
+		}
+		handler := chart.CreateChartHandler(chart.CreateChartHandlerDependencies{ChartService: stubService})
+
+		req := httptest.NewRequest(http.MethodPost, "/admin/charts", bytes.NewReader([]byte(`{
+			"title": "Revenue",
+			"kind": "bar",
+			"x_axis": {"title": "Month", "type": "category"},
+			"y_axis": {"title": "Revenue", "type": "numeric", "unit": "EUR"},
+			"series": [{"name": "2024", "points": [{"x": "Jan", "y": 0}]}]
+		}`)))
+		w := httptest.NewRecorder()
+
+		// Act
+		handler(w, req)
+
+		// Assert
+		assert.Equal(t, http.StatusCreated, w.Result().StatusCode)
+		var response utils.DataResponse[chart.Chart]
+		assert.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+		assert.Equal(t, *expected, response.Data)
+	})
+
+	t.Run("Should return 400 when title is missing", func(t *testing.T) {
+		// Arrange
+		handler := chart.CreateChartHandler(chart.CreateChartHandlerDependencies{ChartService: &StubChartService{}})
+
+		req := httptest.NewRequest(http.MethodPost, "/admin/charts", bytes.NewReader([]byte(`{
+			"kind": "line",
+			"x_axis": {"title": "Month", "type": "numeric"},
+			"y_axis": {"title": "Units", "type": "numeric"}
+		}`)))
+		w := httptest.NewRecorder()
+
+		// Act
+		handler(w, req)
+
+		// Assert
+		assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
+	})
+
+	t.Run("Should return 400 when a point has no y", func(t *testing.T) {
+		// Arrange
+		handler := chart.CreateChartHandler(chart.CreateChartHandlerDependencies{ChartService: &StubChartService{}})
+
+		req := httptest.NewRequest(http.MethodPost, "/admin/charts", bytes.NewReader([]byte(`{
+			"title": "Sales",
+			"kind": "line",
+			"x_axis": {"title": "Month", "type": "numeric"},
+			"y_axis": {"title": "Units", "type": "numeric"},
+			"series": [{"name": "Units", "points": [{"x": 1}]}]
+		}`)))
+		w := httptest.NewRecorder()
+
+		// Act
+		handler(w, req)
+
+		// Assert
+		assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
+	})
+
+	t.Run("Should return 400 when the service rejects the chart", func(t *testing.T) {
+		for _, serviceErr := range []error{chart.ErrInvalidChartKind, chart.ErrInvalidChartAxis, chart.ErrInvalidChartSeries, chart.ErrInvalidChartData} {
+			// Arrange
+			stubService := &StubChartService{
+				CreateFunc: func(chart.Chart) (*chart.Chart, error) { return nil, serviceErr },
+			}
+			handler := chart.CreateChartHandler(chart.CreateChartHandlerDependencies{ChartService: stubService})
+
+			req := httptest.NewRequest(http.MethodPost, "/admin/charts", bytes.NewReader([]byte(`{
+				"title": "Sales",
+				"kind": "line",
+				"x_axis": {"title": "Month", "type": "time"},
+				"y_axis": {"title": "Units", "type": "numeric"},
+				"series": [{"name": "Units", "points": [{"x": 1, "y": 10}]}]
+			}`)))
+			w := httptest.NewRecorder()
+
+			// Act
+			handler(w, req)
+
+			// Assert
+			assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
+			assert.Contains(t, w.Body.String(), serviceErr.Error())
+		}
+	})
+}
+
+func TestUpdateChartHandler(t *testing.T) {
+	t.Run("Should pass the fields of the merge patch as changes", func(t *testing.T) {
+		// Arrange
+		chartId := uuid.New()
+		stubService := &StubChartService{
+			UpdateFunc: func(cId uuid.UUID, changes chart.ChartChanges) (*chart.Chart, error) {
+				title := "Revenue"
+				assert.Equal(t, chartId, cId)
+				assert.Equal(t, chart.ChartChanges{
+					Title:  &title,
+					YAxis:  &chart.ChartAxis{Title: "Revenue", Type: chart.AxisTypeNumeric, Unit: "EUR"},
+					Series: []chart.ChartSeries{},
+				}, changes)
+				return &chart.Chart{Id: cId, Title: title}, nil
+			},
+		}
+		handler := chart.UpdateChartHandler(chart.UpdateChartHandlerDependencies{ChartService: stubService})
+
+		req := httptest.NewRequest(http.MethodPatch, "/admin/charts", bytes.NewReader([]byte(`{
+			"title": "Revenue",
+			"y_axis": {"title": "Revenue", "type": "numeric", "unit": "EUR"},
+			"series": null
+		}`)))
+		req = withChartId(req, chartId.String())
+		w := httptest.NewRecorder()
+
+		// Act
+		handler(w, req)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+	})
+
+	t.Run("Should return 404 when chart not found", func(t *testing.T) {
+		// Arrange
+		stubService := &StubChartService{
+			UpdateFunc: func(uuid.UUID, chart.ChartChanges) (*chart.Chart, error) { return nil, chart.ErrChartNotFound },
+		}
+		handler := chart.UpdateChartHandler(chart.UpdateChartHandlerDependencies{ChartService: stubService})
+
+		req := httptest.NewRequest(http.MethodPatch, "/admin/charts", bytes.NewReader([]byte(`{"title": "Revenue"}`)))
+		req = withChartId(req, uuid.NewString())
+		w := httptest.NewRecorder()
+
+		// Act
+		handler(w, req)
+
+		// Assert
+		assert.Equal(t, http.StatusNotFound, w.Result().StatusCode)
+	})
+}
+
+func TestDeleteChartHandler(t *testing.T) {
+	cases := []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{"Should return 200 when delete is successful", nil, http.StatusOK},
+		{"Should return 404 when chart not found", chart.ErrChartNotFound, http.StatusNotFound},
+		{"Should return 409 when chart is favourited", chart.ErrChartFavourited, http.StatusConflict},
+		{"Should return 500 when service fails unexpectedly", errors.New("db down"), http.StatusInternalServerError},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			chartId := uuid.New()
+			stubService := &StubChartService{
+				DeleteFunc: func(cId uuid.UUID) error {
+					assert.Equal(t, chartId, cId)
+					return tc.err
+				},
+			}
+			handler := chart.DeleteChartHandler(chart.DeleteChartHandlerDependencies{ChartService: stubService})
+
+			req := withChartId(httptest.NewRequest(http.MethodDelete, "/admin/charts", nil), chartId.String())
+			w := httptest.NewRecorder()
+
+			// Act
+			handler(w, req)
+
+			// Assert
+			assert.Equal(t, tc.status, w.Result().StatusCode)
+		})
+	}
+}
@@ -1,7 +1,9 @@
 package chart
 
 import (
+	"errors"
 	"platform-go-challenge/internal/database"
+	"platform-go-challenge/internal/utils"
 
 	"github.com/google/uuid"
 )
@@ -9,6 +11,13 @@ import (
 type ChartRepository interface {
 	GetByIds(ids uuid.UUIDs) ([]Chart, error)
 	GetById(id uuid.UUID) (*Chart, error)
+	// GetPaginated lists every chart by title
+	GetPaginated(pageSize int, pageNumber int) ([]Chart, utils.Pagination, error)
+	Create(chart Chart) (*Chart, error)
+	// Update fails with ErrChartNotFound when the chart is gone
+	Update(chart Chart) (*Chart, error)
+	// Delete fails with ErrChartNotFound when the chart is gone, and with ErrChartFavourited while a user has a favourite of it
+	Delete(id uuid.UUID) error
 }
 
 type inMemoryDBChartRepository struct {
@@ -21,25 +30,98 @@ func NewInMemoryDBChartRepository(db *database.IMDatabase) *inMemoryDBChartRepos
 	}
 }
 
+// InMemoryDBChartModelToDTO converts the charts stored before schema version 2, see LegacyChartToDTO.
 func InMemoryDBChartModelToDTO(chartModel database.IMChartModel) Chart {
+	if chartModel.SchemaVersion < ChartSchemaVersion {
+		return LegacyChartToDTO(chartModel.Id, chartModel.Title, chartModel.XAxisTitle, chartModel.YAxisTitle, chartModel.Data)
+	}
+
+	series := []ChartSeries{}
+	for _, seriesModel := range chartModel.Series {
+		points := []ChartPoint{}
+		for _, pointModel := range seriesModel.Points {
+			x := NumberX(0)
+			switch value := pointModel.X.(type) {
+			case float64:
+				x = NumberX(value)
+			case string:
+				x = TextX(value)
+			}
+
+			points = append(points, ChartPoint{X: x, Y: pointModel.Y})
+		}
+
+		series = append(series, ChartSeries{Name: seriesModel.Name, Points: points})
+	}
+
 	return Chart{
-		Id:         chartModel.Id,
-		Title:      chartModel.Title,
-		XAxisTitle: chartModel.XAxisTitle,
-		YAxisTitle: chartModel.YAxisTitle,
-		Data:       chartModel.Data,
+		Id:            chartModel.Id,
+		SchemaVersion: chartModel.SchemaVersion,
+		Title:         chartModel.Title,
+		Kind:          ChartKind(chartModel.Kind),
+		XAxis:         ChartAxis{Title: chartModel.XAxis.Title, Type: AxisType(chartModel.XAxis.Type), Unit: chartModel.XAxis.Unit},
+		YAxis:         ChartAxis{Title: chartModel.YAxis.Title, Type: AxisType(chartModel.YAxis.Type), Unit: chartModel.YAxis.Unit},
+		Series:        series,
 	}
 }
 
-func (repo *inMemoryDBChartRepository) GetByIds(ids uuid.UUIDs) ([]Chart, error) {
-	result := []Chart{}
+func DTOToInMemoryDBChartModel(dto Chart) database.IMChartModel {
+	series := []database.IMChartSeriesModel{}
+	for _, s := range dto.Series {
+		points := []database.IMChartPointModel{}
+		for _, point := range s.Points {
+			var x any
+			if text, ok := point.X.Text(); ok {
+				x = text
+			} else {
+				x, _ = point.X.Number()
+			}
 
-	for _, id := range ids {
-		v, found := repo.DB.ChartStorage[id]
-		if !found {
-			continue
+			points = append(points, database.IMChartPointModel{X: x, Y: point.Y})
 		}
 
+		series = append(series, database.IMChartSeriesModel{Name: s.Name, Points: points})
+	}
+
+	return database.IMChartModel{
+		Id:            dto.Id,
+		SchemaVersion: dto.SchemaVersion,
+		Title:         dto.Title,
+		Kind:          string(dto.Kind),
+		XAxis:         database.IMChartAxisModel{Title: dto.XAxis.Title, Type: string(dto.XAxis.Type), Unit: dto.XAxis.Unit},
+		YAxis:         database.IMChartAxisModel{Title: dto.YAxis.Title, Type: string(dto.YAxis.Type), Unit: dto.YAxis.Unit},
+		Series:        series,
+	}
+}
+
+// LegacyChartToDTO converts a chart of schema version 1, stored with a list of x and y maps, into a line chart
+// with numeric axes and a single series named after the y axis. A chart without data points has no series.
+func LegacyChartToDTO(id uuid.UUID, title string, xAxisTitle string, yAxisTitle string, data []map[string]float64) Chart {
+	series := []ChartSeries{}
+	if len(data) > 0 {
+		points := []ChartPoint{}
+		for _, point := range data {
+			points = append(points, ChartPoint{X: NumberX(point["x"]), Y: point["y"]})
+		}
+
+		series = append(series, ChartSeries{Name: yAxisTitle, Points: points})
+	}
+
+	return Chart{
+		Id:            id,
+		SchemaVersion: ChartSchemaVersion,
+		Title:         title,
+		Kind:          ChartKindLine,
+		XAxis:         ChartAxis{Title: xAxisTitle, Type: AxisTypeNumeric},
+		YAxis:         ChartAxis{Title: yAxisTitle, Type: AxisTypeNumeric},
+		Series:        series,
+	}
+}
+
+func (repo *inMemoryDBChartRepository) GetByIds(ids uuid.UUIDs) ([]Chart, error) {
+	result := []Chart{}
+
+	for _, v := range repo.DB.ChartStorage.GetMany(ids) {
 		dto := InMemoryDBChartModelToDTO(v)
 
 		result = append(result, dto)
@@ -61,3 +143,63 @@ func (repo *inMemoryDBChartRepository) GetById(id uuid.UUID) (*Chart, error) {
 
 	return &dto, nil
 }
+
+func (repo *inMemoryDBChartRepository) GetPaginated(pageSize int, pageNumber int) ([]Chart, utils.Pagination, error) {
+	models, total, err := repo.DB.ChartStorage.Page(database.IMChartsByTitleIndex, uuid.Nil, pageSize*pageNumber, pageSize)
+	if err != nil {
+		return nil, utils.Pagination{}, err
+	}
+
+	result := []Chart{}
+	for _, model := range models {
+		result = append(result, InMemoryDBChartModelToDTO(model))
+	}
+
+	maxPage := utils.CalculateMaxPages(total, pageSize)
+
+	return result, utils.Pagination{Page: pageNumber, PageSize: pageSize, MaxPage: maxPage}, nil
+}
+
+func (repo *inMemoryDBChartRepository) Create(chart Chart) (*Chart, error) {
+	if _, err := repo.DB.ChartStorage.Insert(chart.Id, DTOToInMemoryDBChartModel(chart)); err != nil {
+		return nil, err
+	}
+
+	return &chart, nil
+}
+
+func (repo *inMemoryDBChartRepository) Update(chart Chart) (*Chart, error) {
+	model, err := repo.DB.ChartStorage.Update(
+		chart.Id,
+		func(current database.IMChartModel) (database.IMChartModel, error) {
+			return DTOToInMemoryDBChartModel(chart), nil
+		},
+	)
+	if err != nil {
+		if errors.Is(err, database.ErrItemNotFound) {
+			return nil, ErrChartNotFound
+		}
+
+		return nil, err
+	}
+
+	updated := InMemoryDBChartModelToDTO(model)
+
+	return &updated, nil
+}
+
+func (repo *inMemoryDBChartRepository) Delete(id uuid.UUID) error {
+	deleted, err := database.IMDeleteUnlessFavourited(repo.DB, repo.DB.ChartStorage, id)
+	if errors.Is(err, database.ErrItemFavourited) {
+		return ErrChartFavourited
+	}
+	if err != nil {
+		return err
+	}
+
+	if !deleted {
+		return ErrChartNotFound
+	}
+
+	return nil
+}
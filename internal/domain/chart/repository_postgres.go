@@ -0,0 +1,161 @@
+package chart
+
+import (
+	"context"
+	"errors"
+	"platform-go-challenge/internal/database"
+	"platform-go-challenge/internal/utils"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type postgresDBChartRepository struct {
+	DB *pgxpool.Pool
+}
+
+func NewPostgresDBChartRepository(db *pgxpool.Pool) *postgresDBChartRepository {
+	return &postgresDBChartRepository{
+		DB: db,
+	}
+}
+
+const pgChartColumns = "id, schema_version, title, kind, x_axis, y_axis, series, x_axis_title, y_axis_title, data"
+
+// pgScanChart converts the rows stored before schema version 2, see LegacyChartToDTO.
+func pgScanChart(row pgx.CollectableRow) (Chart, error) {
+	var chart Chart
+	var kind string
+	var xAxis, yAxis *ChartAxis
+	var xAxisTitle, yAxisTitle string
+	var data []map[string]float64
+	err := row.Scan(&chart.Id, &chart.SchemaVersion, &chart.Title, &kind, &xAxis, &yAxis, &chart.Series, &xAxisTitle, &yAxisTitle, &data)
+	if err != nil {
+		return chart, err
+	}
+
+	if chart.SchemaVersion < ChartSchemaVersion || xAxis == nil || yAxis == nil {
+		return LegacyChartToDTO(chart.Id, chart.Title, xAxisTitle, yAxisTitle, data), nil
+	}
+
+	chart.Kind = ChartKind(kind)
+	chart.XAxis = *xAxis
+	chart.YAxis = *yAxis
+	if chart.Series == nil {
+		chart.Series = []ChartSeries{}
+	}
+
+	return chart, nil
+}
+
+// GetByIds returns the charts in the order of ids, skipping the missing ones.
+func (repo *postgresDBChartRepository) GetByIds(ids uuid.UUIDs) ([]Chart, error) {
+	rows, err := repo.DB.Query(
+		context.Background(),
+		"SELECT "+pgChartColumns+" FROM charts WHERE id = ANY($1::uuid[]) ORDER BY array_position($1::uuid[], id)",
+		ids.Strings(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, pgScanChart)
+}
+
+func (repo *postgresDBChartRepository) GetById(id uuid.UUID) (*Chart, error) {
+	rows, err := repo.DB.Query(
+		context.Background(),
+		"SELECT "+pgChartColumns+" FROM charts WHERE id = $1",
+		id,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	chart, err := pgx.CollectExactlyOneRow(rows, pgScanChart)
+	if err != nil {
+		return nil, database.PGItemNotFound(err)
+	}
+
+	return &chart, nil
+}
+
+func (repo *postgresDBChartRepository) GetPaginated(pageSize int, pageNumber int) ([]Chart, utils.Pagination, error) {
+	ctx := context.Background()
+
+	var totalCount int
+	err := repo.DB.QueryRow(ctx, "SELECT count(*) FROM charts").Scan(&totalCount)
+	if err != nil {
+		return nil, utils.Pagination{}, err
+	}
+
+	rows, err := repo.DB.Query(
+		ctx,
+		"SELECT "+pgChartColumns+` FROM charts ORDER BY title COLLATE "C", id LIMIT $1 OFFSET $2`,
+		pageSize, pageSize*pageNumber,
+	)
+	if err != nil {
+		return nil, utils.Pagination{}, err
+	}
+
+	result, err := pgx.CollectRows(rows, pgScanChart)
+	if err != nil {
+		return nil, utils.Pagination{}, err
+	}
+
+	if result == nil {
+		result = []Chart{}
+	}
+
+	maxPage := utils.CalculateMaxPages(totalCount, pageSize)
+
+	return result, utils.Pagination{Page: pageNumber, PageSize: pageSize, MaxPage: maxPage}, nil
+}
+
+func (repo *postgresDBChartRepository) Create(chart Chart) (*Chart, error) {
+	_, err := repo.DB.Exec(
+		context.Background(),
+		"INSERT INTO charts ("+pgChartColumns+") VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, '[]')",
+		chart.Id, chart.SchemaVersion, chart.Title, chart.Kind, chart.XAxis, chart.YAxis, chart.Series, chart.XAxis.Title, chart.YAxis.Title,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &chart, nil
+}
+
+func (repo *postgresDBChartRepository) Update(chart Chart) (*Chart, error) {
+	tag, err := repo.DB.Exec(
+		context.Background(),
+		`UPDATE charts SET schema_version = $2, title = $3, kind = $4, x_axis = $5, y_axis = $6, series = $7,
+			x_axis_title = $8, y_axis_title = $9, data = '[]' WHERE id = $1`,
+		chart.Id, chart.SchemaVersion, chart.Title, chart.Kind, chart.XAxis, chart.YAxis, chart.Series, chart.XAxis.Title, chart.YAxis.Title,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return nil, ErrChartNotFound
+	}
+
+	return &chart, nil
+}
+
+func (repo *postgresDBChartRepository) Delete(id uuid.UUID) error {
+	deleted, err := database.PGDeleteUnlessFavourited(repo.DB, "charts", id)
+	if errors.Is(err, database.ErrItemFavourited) {
+		return ErrChartFavourited
+	}
+	if err != nil {
+		return err
+	}
+
+	if !deleted {
+		return ErrChartNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,78 @@
+package chart_test
+
+import (
+	"context"
+	"platform-go-challenge/internal/domain/chart"
+	"platform-go-challenge/internal/domain/favourite"
+	"platform-go-challenge/test"
+	"platform-go-challenge/test/conformance"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresDBChartRepositoryConformance(t *testing.T) {
+	conformance.ChartRepository(t, func(t *testing.T, charts []chart.Chart) chart.ChartRepository {
+		repo := chart.NewPostgresDBChartRepository(test.PostgresPool(t))
+		for _, c := range charts {
+			_, err := repo.Create(c)
+			require.NoError(t, err)
+		}
+
+		return repo
+	})
+}
+
+func TestPostgresDBChartRepositoryLegacyCharts(t *testing.T) {
+	t.Run("should convert a chart stored before the schema version 2 when it is read", func(t *testing.T) {
+		// Arrange
+		pool := test.PostgresPool(t)
+		chartID := uuid.New()
+		_, err := pool.Exec(
+			context.Background(),
+			"INSERT INTO charts (id, title, x_axis_title, y_axis_title, data) VALUES ($1, $2, $3, $4, $5)",
+			chartID, "Sales", "Month", "Units", []map[string]float64{{"x": 1, "y": 10}},
+		)
+		require.NoError(t, err)
+		repo := chart.NewPostgresDBChartRepository(pool)
+
+		// Act
+		result, err := repo.GetById(chartID)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, &chart.Chart{
+			Id:            chartID,
+			SchemaVersion: chart.ChartSchemaVersion,
+			Title:         "Sales",
+			Kind:          chart.ChartKindLine,
+			XAxis:         chart.ChartAxis{Title: "Month", Type: chart.AxisTypeNumeric},
+			YAxis:         chart.ChartAxis{Title: "Units", Type: chart.AxisTypeNumeric},
+			Series:        []chart.ChartSeries{{Name: "Units", Points: []chart.ChartPoint{{X: chart.NumberX(1), Y: 10}}}},
+		}, result)
+	})
+}
+
+func TestPostgresDBChartRepositoryDelete(t *testing.T) {
+	t.Run("should return error and keep the chart while a user has a favourite of it", func(t *testing.T) {
+		// Arrange
+		pool := test.PostgresPool(t)
+		repo := chart.NewPostgresDBChartRepository(pool)
+		stored, err := repo.Create(chart.Chart{Id: uuid.New(), Title: "Revenue", Kind: chart.ChartKindLine})
+		require.NoError(t, err)
+		_, err = favourite.NewPostgresDBFavouriteRepository(pool).Create(favourite.Favourite{
+			Id: uuid.New(), UserId: uuid.New(), AssetId: stored.Id, AssetType: favourite.AssetTypeChart, Rank: "V", Tags: []string{},
+		})
+		require.NoError(t, err)
+
+		// Act
+		err = repo.Delete(stored.Id)
+
+		// Assert
+		assert.ErrorIs(t, err, chart.ErrChartFavourited)
+		_, err = repo.GetById(stored.Id)
+		assert.NoError(t, err)
+	})
+}
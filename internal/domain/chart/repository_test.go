@@ -3,6 +3,7 @@ package chart_test
 import (
 	"platform-go-challenge/internal/database"
 	"platform-go-challenge/internal/domain/chart"
+	"platform-go-challenge/test/conformance"
 	"testing"
 
 	"github.com/google/uuid"
@@ -16,7 +17,7 @@ func TestGetByIds(t *testing.T) {
 		chart2ID := uuid.New()
 
 		mockDB := &database.IMDatabase{
-			ChartStorage: map[uuid.UUID]database.IMChartModel{
+			ChartStorage: database.NewIMStorage(map[uuid.UUID]database.IMChartModel{
 				chart1ID: {
 					Id:         chart1ID,
 					Title:      "Chart 1",
@@ -37,29 +38,31 @@ func TestGetByIds(t *testing.T) {
 						{"x": 3, "y": 2},
 					},
 				},
-			},
+			}),
 		}
 		repo := chart.NewInMemoryDBChartRepository(mockDB)
 
 		expectedResult := []chart.Chart{
 			{
-				Id:         chart1ID,
-				Title:      "Chart 1",
-				XAxisTitle: "X Axis 1",
-				YAxisTitle: "Y Axis 1",
-				Data: chart.ChartData{
-					{"x": 1, "y": 1},
-					{"x": 2, "y": 3},
+				Id:            chart1ID,
+				SchemaVersion: chart.ChartSchemaVersion,
+				Title:         "Chart 1",
+				Kind:          chart.ChartKindLine,
+				XAxis:         chart.ChartAxis{Title: "X Axis 1", Type: chart.AxisTypeNumeric},
+				YAxis:         chart.ChartAxis{Title: "Y Axis 1", Type: chart.AxisTypeNumeric},
+				Series: []chart.ChartSeries{
+					{Name: "Y Axis 1", Points: []chart.ChartPoint{{X: chart.NumberX(1), Y: 1}, {X: chart.NumberX(2), Y: 3}}},
 				},
 			},
 			{
-				Id:         chart2ID,
-				Title:      "Chart 2",
-				XAxisTitle: "X Axis 2",
-				YAxisTitle: "Y Axis 2",
-				Data: chart.ChartData{
-					{"x": 1, "y": 1},
-					{"x": 3, "y": 2},
+				Id:            chart2ID,
+				SchemaVersion: chart.ChartSchemaVersion,
+				Title:         "Chart 2",
+				Kind:          chart.ChartKindLine,
+				XAxis:         chart.ChartAxis{Title: "X Axis 2", Type: chart.AxisTypeNumeric},
+				YAxis:         chart.ChartAxis{Title: "Y Axis 2", Type: chart.AxisTypeNumeric},
+				Series: []chart.ChartSeries{
+					{Name: "Y Axis 2", Points: []chart.ChartPoint{{X: chart.NumberX(1), Y: 1}, {X: chart.NumberX(3), Y: 2}}},
 				},
 			},
 		}
@@ -77,7 +80,7 @@ func TestGetByIds(t *testing.T) {
 		nonexistentID := uuid.New()
 
 		mockDB := &database.IMDatabase{
-			ChartStorage: map[uuid.UUID]database.IMChartModel{
+			ChartStorage: database.NewIMStorage(map[uuid.UUID]database.IMChartModel{
 				chart1ID: {
 					Id:         chart1ID,
 					Title:      "Chart 1",
@@ -88,18 +91,19 @@ func TestGetByIds(t *testing.T) {
 						{"x": 2, "y": 3},
 					},
 				},
-			},
+			}),
 		}
 		repo := chart.NewInMemoryDBChartRepository(mockDB)
 		expectedResult := []chart.Chart{
 			{
-				Id:         chart1ID,
-				Title:      "Chart 1",
-				XAxisTitle: "X Axis 1",
-				YAxisTitle: "Y Axis 1",
-				Data: chart.ChartData{
-					{"x": 1, "y": 1},
-					{"x": 2, "y": 3},
+				Id:            chart1ID,
+				SchemaVersion: chart.ChartSchemaVersion,
+				Title:         "Chart 1",
+				Kind:          chart.ChartKindLine,
+				XAxis:         chart.ChartAxis{Title: "X Axis 1", Type: chart.AxisTypeNumeric},
+				YAxis:         chart.ChartAxis{Title: "Y Axis 1", Type: chart.AxisTypeNumeric},
+				Series: []chart.ChartSeries{
+					{Name: "Y Axis 1", Points: []chart.ChartPoint{{X: chart.NumberX(1), Y: 1}, {X: chart.NumberX(2), Y: 3}}},
 				},
 			},
 		}
@@ -114,7 +118,7 @@ func TestGetByIds(t *testing.T) {
 	t.Run("should return empty slice when input is empty", func(t *testing.T) {
 		// Arrange
 		mockDB := &database.IMDatabase{
-			ChartStorage: map[uuid.UUID]database.IMChartModel{},
+			ChartStorage: database.NewIMStorage(map[uuid.UUID]database.IMChartModel{}),
 		}
 		repo := chart.NewInMemoryDBChartRepository(mockDB)
 
@@ -131,7 +135,7 @@ func TestGetById(t *testing.T) {
 		// Arrange
 		chartID := uuid.New()
 		mockDB := &database.IMDatabase{
-			ChartStorage: map[uuid.UUID]database.IMChartModel{
+			ChartStorage: database.NewIMStorage(map[uuid.UUID]database.IMChartModel{
 				chartID: {
 					Id:         chartID,
 					Title:      "Chart 1",
@@ -142,18 +146,19 @@ func TestGetById(t *testing.T) {
 						{"x": 2, "y": 2},
 					},
 				},
-			},
+			}),
 		}
 		repo := chart.NewInMemoryDBChartRepository(mockDB)
 
 		expected := &chart.Chart{
-			Id:         chartID,
-			Title:      "Chart 1",
-			XAxisTitle: "X Axis",
-			YAxisTitle: "Y Axis",
-			Data: chart.ChartData{
-				{"x": 1, "y": 1},
-				{"x": 2, "y": 2},
+			Id:            chartID,
+			SchemaVersion: chart.ChartSchemaVersion,
+			Title:         "Chart 1",
+			Kind:          chart.ChartKindLine,
+			XAxis:         chart.ChartAxis{Title: "X Axis", Type: chart.AxisTypeNumeric},
+			YAxis:         chart.ChartAxis{Title: "Y Axis", Type: chart.AxisTypeNumeric},
+			Series: []chart.ChartSeries{
+				{Name: "Y Axis", Points: []chart.ChartPoint{{X: chart.NumberX(1), Y: 1}, {X: chart.NumberX(2), Y: 2}}},
 			},
 		}
 
@@ -168,7 +173,7 @@ func TestGetById(t *testing.T) {
 	t.Run("should return error when ID does not exist", func(t *testing.T) {
 		// Arrange
 		mockDB := &database.IMDatabase{
-			ChartStorage: map[uuid.UUID]database.IMChartModel{},
+			ChartStorage: database.NewIMStorage(map[uuid.UUID]database.IMChartModel{}),
 		}
 		repo := chart.NewInMemoryDBChartRepository(mockDB)
 		missingID := uuid.New()
@@ -181,3 +186,110 @@ func TestGetById(t *testing.T) {
 		assert.Nil(t, result)
 	})
 }
+
+func TestDelete(t *testing.T) {
+	newDB := func(chartId uuid.UUID, favourites ...database.IMFavouriteModel) *database.IMDatabase {
+		db := database.NewIMDatabase()
+		db.ChartStorage.Set(chartId, database.IMChartModel{Id: chartId, Title: "Revenue"})
+		for _, fav := range favourites {
+			db.FavouriteStorage.Set(fav.Id, fav)
+		}
+		return db
+	}
+
+	t.Run("should delete a chart no user has a favourite of", func(t *testing.T) {
+		// Arrange
+		chartId := uuid.New()
+		db := newDB(chartId, database.IMFavouriteModel{Id: uuid.New(), UserId: uuid.New(), AssetId: uuid.New(), AssetType: "chart"})
+		repo := chart.NewInMemoryDBChartRepository(db)
+
+		// Act
+		err := repo.Delete(chartId)
+
+		// Assert
+		assert.NoError(t, err)
+		_, found := db.ChartStorage.Get(chartId)
+		assert.False(t, found)
+	})
+
+	t.Run("should return error and keep the chart while a user has a favourite of it", func(t *testing.T) {
+		// Arrange
+		chartId := uuid.New()
+		db := newDB(chartId, database.IMFavouriteModel{Id: uuid.New(), UserId: uuid.New(), AssetId: chartId, AssetType: "chart"})
+		repo := chart.NewInMemoryDBChartRepository(db)
+
+		// Act
+		err := repo.Delete(chartId)
+
+		// Assert
+		assert.ErrorIs(t, err, chart.ErrChartFavourited)
+		_, found := db.ChartStorage.Get(chartId)
+		assert.True(t, found)
+	})
+
+	t.Run("should return not found error when the chart does not exist", func(t *testing.T) {
+		// Arrange
+		repo := chart.NewInMemoryDBChartRepository(newDB(uuid.New()))
+
+		// Act
+		err := repo.Delete(uuid.New())
+
+		// Assert
+		assert.ErrorIs(t, err, chart.ErrChartNotFound)
+	})
+}
+
+func TestInMemoryDBChartModelToDTO(t *testing.T) {
+	t.Run("should convert a legacy chart without data to a line chart without series", func(t *testing.T) {
+		// Arrange
+		chartID := uuid.New()
+		model := database.IMChartModel{Id: chartID, Title: "Empty", XAxisTitle: "Month", YAxisTitle: "Units"}
+
+		// Act
+		result := chart.InMemoryDBChartModelToDTO(model)
+
+		// Assert
+		assert.Equal(t, chart.Chart{
+			Id:            chartID,
+			SchemaVersion: chart.ChartSchemaVersion,
+			Title:         "Empty",
+			Kind:          chart.ChartKindLine,
+			XAxis:         chart.ChartAxis{Title: "Month", Type: chart.AxisTypeNumeric},
+			YAxis:         chart.ChartAxis{Title: "Units", Type: chart.AxisTypeNumeric},
+			Series:        []chart.ChartSeries{},
+		}, result)
+	})
+
+	t.Run("should keep the numeric and text x of a chart of the current schema", func(t *testing.T) {
+		// Arrange
+		expected := chart.Chart{
+			Id:            uuid.New(),
+			SchemaVersion: chart.ChartSchemaVersion,
+			Title:         "Visits",
+			Kind:          chart.ChartKindArea,
+			XAxis:         chart.ChartAxis{Title: "Day", Type: chart.AxisTypeTime},
+			YAxis:         chart.ChartAxis{Title: "Latency", Type: chart.AxisTypeNumeric, Unit: "ms"},
+			Series: []chart.ChartSeries{
+				{Name: "p50", Points: []chart.ChartPoint{{X: chart.TextX("2024-01-01T00:00:00Z"), Y: 12}}},
+				{Name: "p99", Points: []chart.ChartPoint{{X: chart.NumberX(3), Y: 80}}},
+			},
+		}
+
+		// Act
+		result := chart.InMemoryDBChartModelToDTO(chart.DTOToInMemoryDBChartModel(expected))
+
+		// Assert
+		assert.Equal(t, expected, result)
+	})
+}
+
+func TestInMemoryDBChartRepositoryConformance(t *testing.T) {
+	conformance.ChartRepository(t, func(t *testing.T, charts []chart.Chart) chart.ChartRepository {
+		db := database.NewIMDatabase()
+		for _, c := range charts {
+			db.ChartStorage.Set(c.Id, chart.DTOToInMemoryDBChartModel(c))
+		}
+
+		return chart.NewInMemoryDBChartRepository(db)
+	})
+}
@@ -0,0 +1,180 @@
+package chart
+
+import (
+	"errors"
+	"platform-go-challenge/internal/database"
+	"platform-go-challenge/internal/utils"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ChartService manages the charts for the admin endpoints, the favourites read them through ChartRepository.
+type ChartService interface {
+	GetPaginated(pageSize int, pageNumber int) ([]Chart, *utils.Pagination, error)
+	Get(chartId uuid.UUID) (*Chart, error)
+	// Create stores the chart under a new id
+	Create(chart Chart) (*Chart, error)
+	Update(chartId uuid.UUID, changes ChartChanges) (*Chart, error)
+	// Delete fails with ErrChartFavourited while a user has a favourite of the chart, the trash left out
+	Delete(chartId uuid.UUID) error
+}
+
+type ChartServiceDependencies struct {
+	ChartRepository ChartRepository
+}
+
+type chartService struct {
+	Dependencies ChartServiceDependencies
+}
+
+func NewChartService(dependencies ChartServiceDependencies) chartService {
+	return chartService{
+		Dependencies: dependencies,
+	}
+}
+
+func (service *chartService) GetPaginated(pageSize int, pageNumber int) ([]Chart, *utils.Pagination, error) {
+	charts, pagination, err := service.Dependencies.ChartRepository.GetPaginated(pageSize, pageNumber)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return charts, &pagination, nil
+}
+
+func (service *chartService) Get(chartId uuid.UUID) (*Chart, error) {
+	chart, err := service.Dependencies.ChartRepository.GetById(chartId)
+	if err != nil {
+		if errors.Is(err, database.ErrItemNotFound) {
+			return nil, ErrChartNotFound
+		}
+
+		return nil, utils.ErrUnexpected
+	}
+
+	return chart, nil
+}
+
+func (service *chartService) Create(chart Chart) (*Chart, error) {
+	chart.Id = uuid.New()
+
+	chart, err := normalizedChart(chart)
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := service.Dependencies.ChartRepository.Create(chart)
+	if err != nil {
+		return nil, ErrCouldNotSaveChart
+	}
+
+	return created, nil
+}
+
+func (service *chartService) Update(chartId uuid.UUID, changes ChartChanges) (*Chart, error) {
+	chart, err := service.Get(chartId)
+	if err != nil {
+		return nil, err
+	}
+
+	if changes.Title != nil {
+		chart.Title = *changes.Title
+	}
+	if changes.Kind != nil {
+		chart.Kind = *changes.Kind
+	}
+	if changes.XAxis != nil {
+		chart.XAxis = *changes.XAxis
+	}
+	if changes.YAxis != nil {
+		chart.YAxis = *changes.YAxis
+	}
+	if changes.Series != nil {
+		chart.Series = changes.Series
+	}
+
+	updated, err := normalizedChart(*chart)
+	if err != nil {
+		return nil, err
+	}
+
+	return service.Dependencies.ChartRepository.Update(updated)
+}
+
+func (service *chartService) Delete(chartId uuid.UUID) error {
+	return service.Dependencies.ChartRepository.Delete(chartId)
+}
+
+// normalizedChart trims the titles, units and series names of the chart and checks them, its kind, its axes
+// and its points, which must have an x of the type of the x axis. A chart or series without points gets an
+// empty list so every backend stores the same, and the chart is stored with the current schema version.
+func normalizedChart(chart Chart) (Chart, error) {
+	chart.SchemaVersion = ChartSchemaVersion
+	chart.Title = strings.TrimSpace(chart.Title)
+	chart.XAxis = normalizedAxis(chart.XAxis)
+	chart.YAxis = normalizedAxis(chart.YAxis)
+
+	if chart.Title == "" || chart.XAxis.Title == "" || chart.YAxis.Title == "" {
+		return Chart{}, ErrInvalidChartTitle
+	}
+
+	if !slices.Contains([]ChartKind{ChartKindLine, ChartKindBar, ChartKindArea, ChartKindScatter}, chart.Kind) {
+		return Chart{}, ErrInvalidChartKind
+	}
+
+	if !slices.Contains([]AxisType{AxisTypeNumeric, AxisTypeTime, AxisTypeCategory}, chart.XAxis.Type) || chart.YAxis.Type != AxisTypeNumeric {
+		return Chart{}, ErrInvalidChartAxis
+	}
+
+	series := []ChartSeries{}
+	names := map[string]bool{}
+	for _, s := range chart.Series {
+		s.Name = strings.TrimSpace(s.Name)
+		if s.Name == "" || names[s.Name] {
+			return Chart{}, ErrInvalidChartSeries
+		}
+		names[s.Name] = true
+
+		for _, point := range s.Points {
+			if !validX(point.X, chart.XAxis.Type) {
+				return Chart{}, ErrInvalidChartData
+			}
+		}
+
+		if s.Points == nil {
+			s.Points = []ChartPoint{}
+		}
+
+		series = append(series, s)
+	}
+	chart.Series = series
+
+	return chart, nil
+}
+
+func normalizedAxis(axis ChartAxis) ChartAxis {
+	axis.Title = strings.TrimSpace(axis.Title)
+	axis.Unit = strings.TrimSpace(axis.Unit)
+
+	return axis
+}
+
+// validX tells whether x is a number on a numeric axis, an RFC 3339 time on a time axis or a non blank label on a category axis.
+func validX(x ChartX, axisType AxisType) bool {
+	text, isText := x.Text()
+
+	switch axisType {
+	case AxisTypeNumeric:
+		return !isText
+	case AxisTypeTime:
+		_, err := time.Parse(time.RFC3339, text)
+		return isText && err == nil
+	case AxisTypeCategory:
+		return isText && strings.TrimSpace(text) != ""
+	}
+
+	return false
+}
@@ -0,0 +1,351 @@
+package chart_test
+
+import (
+	"errors"
+	"platform-go-challenge/internal/database"
+	"platform-go-challenge/internal/domain/chart"
+	"platform-go-challenge/internal/utils"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockChartRepo struct {
+	getByIdsFn     func(ids uuid.UUIDs) ([]chart.Chart, error)
+	getByIdFn      func(id uuid.UUID) (*chart.Chart, error)
+	getPaginatedFn func(pageSize, pageNumber int) ([]chart.Chart, utils.Pagination, error)
+	createFn       func(c chart.Chart) (*chart.Chart, error)
+	updateFn       func(c chart.Chart) (*chart.Chart, error)
+	deleteFn       func(id uuid.UUID) error
+}
+
+func (m *mockChartRepo) GetByIds(ids uuid.UUIDs) ([]chart.Chart, error) {
+	return m.getByIdsFn(ids)
+}
+
+func (m *mockChartRepo) GetById(id uuid.UUID) (*chart.Chart, error) {
+	return m.getByIdFn(id)
+}
+
+func (m *mockChartRepo) GetPaginated(pageSize, pageNumber int) ([]chart.Chart, utils.Pagination, error) {
+	return m.getPaginatedFn(pageSize, pageNumber)
+}
+
+func (m *mockChartRepo) Create(c chart.Chart) (*chart.Chart, error) {
+	return m.createFn(c)
+}
+
+func (m *mockChartRepo) Update(c chart.Chart) (*chart.Chart, error) {
+	return m.updateFn(c)
+}
+
+func (m *mockChartRepo) Delete(id uuid.UUID) error {
+	return m.deleteFn(id)
+}
+
+func storedChartRepo(stored *chart.Chart) *mockChartRepo {
+	return &mockChartRepo{
+		getByIdFn: func(id uuid.UUID) (*chart.Chart, error) {
+			if stored == nil || stored.Id != id {
+				return nil, database.ErrItemNotFound
+			}
+			copied := *stored
+			return &copied, nil
+		},
+		updateFn: func(c chart.Chart) (*chart.Chart, error) { return &c, nil },
+	}
+}
+
+// salesChart is a valid chart of the current schema with a single series.
+func salesChart() chart.Chart {
+	return chart.Chart{
+		Id:            uuid.New(),
+		SchemaVersion: chart.ChartSchemaVersion,
+		Title:         "Sales",
+		Kind:          chart.ChartKindLine,
+		XAxis:         chart.ChartAxis{Title: "Month", Type: chart.AxisTypeNumeric},
+		YAxis:         chart.ChartAxis{Title: "Units", Type: chart.AxisTypeNumeric},
+		Series:        []chart.ChartSeries{{Name: "Units", Points: []chart.ChartPoint{{X: chart.NumberX(1), Y: 10}}}},
+	}
+}
+
+func TestGetChartService(t *testing.T) {
+	stored := salesChart()
+
+	t.Run("should return the chart", func(t *testing.T) {
+		// Arrange
+		service := chart.NewChartService(chart.ChartServiceDependencies{ChartRepository: storedChartRepo(&stored)})
+
+		// Act
+		result, err := service.Get(stored.Id)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, &stored, result)
+	})
+
+	t.Run("should return error when chart not found", func(t *testing.T) {
+		// Arrange
+		service := chart.NewChartService(chart.ChartServiceDependencies{ChartRepository: storedChartRepo(nil)})
+
+		// Act
+		result, err := service.Get(stored.Id)
+
+		// Assert
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, chart.ErrChartNotFound)
+	})
+}
+
+func TestCreateChartService(t *testing.T) {
+	creatingRepo := func() *mockChartRepo {
+		return &mockChartRepo{
+			createFn: func(c chart.Chart) (*chart.Chart, error) { return &c, nil },
+		}
+	}
+
+	t.Run("should create the chart under a new id and the current schema version with trimmed titles", func(t *testing.T) {
+		// Arrange
+		service := chart.NewChartService(chart.ChartServiceDependencies{ChartRepository: creatingRepo()})
+
+		// Act
+		result, err := service.Create(chart.Chart{
+			Title:  " Revenue ",
+			Kind:   chart.ChartKindBar,
+			XAxis:  chart.ChartAxis{Title: "Month ", Type: chart.AxisTypeCategory},
+			YAxis:  chart.ChartAxis{Title: " Revenue", Type: chart.AxisTypeNumeric, Unit: " EUR "},
+			Series: []chart.ChartSeries{{Name: " 2024 ", Points: []chart.ChartPoint{{X: chart.TextX("Jan"), Y: 10}}}},
+		})
+
+		// Assert
+		assert.NoError(t, err)
+		assert.NotEqual(t, uuid.Nil, result.Id)
+		assert.Equal(t, chart.ChartSchemaVersion, result.SchemaVersion)
+		assert.Equal(t, "Revenue", result.Title)
+		assert.Equal(t, chart.ChartAxis{Title: "Month", Type: chart.AxisTypeCategory}, result.XAxis)
+		assert.Equal(t, chart.ChartAxis{Title: "Revenue", Type: chart.AxisTypeNumeric, Unit: "EUR"}, result.YAxis)
+		assert.Equal(t, []chart.ChartSeries{{Name: "2024", Points: []chart.ChartPoint{{X: chart.TextX("Jan"), Y: 10}}}}, result.Series)
+	})
+
+	t.Run("should store a chart without series and a series without points with empty lists", func(t *testing.T) {
+		// Arrange
+		service := chart.NewChartService(chart.ChartServiceDependencies{ChartRepository: creatingRepo()})
+		withoutSeries := salesChart()
+		withoutSeries.Series = nil
+		withoutPoints := salesChart()
+		withoutPoints.Series = []chart.ChartSeries{{Name: "Units"}}
+
+		// Act
+		first, firstErr := service.Create(withoutSeries)
+		second, secondErr := service.Create(withoutPoints)
+
+		// Assert
+		assert.NoError(t, firstErr)
+		assert.Equal(t, []chart.ChartSeries{}, first.Series)
+		assert.NoError(t, secondErr)
+		assert.Equal(t, []chart.ChartSeries{{Name: "Units", Points: []chart.ChartPoint{}}}, second.Series)
+	})
+
+	t.Run("should return error when a title is blank", func(t *testing.T) {
+		// Arrange
+		service := chart.NewChartService(chart.ChartServiceDependencies{})
+		invalid := salesChart()
+		invalid.XAxis.Title = "  "
+
+		// Act
+		result, err := service.Create(invalid)
+
+		// Assert
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, chart.ErrInvalidChartTitle)
+	})
+
+	t.Run("should return error when the kind is unknown", func(t *testing.T) {
+		// Arrange
+		service := chart.NewChartService(chart.ChartServiceDependencies{})
+		invalid := salesChart()
+		invalid.Kind = "pie"
+
+		// Act
+		result, err := service.Create(invalid)
+
+		// Assert
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, chart.ErrInvalidChartKind)
+	})
+
+	t.Run("should return error when an axis type is not allowed", func(t *testing.T) {
+		// Arrange
+		service := chart.NewChartService(chart.ChartServiceDependencies{})
+		unknownX := salesChart()
+		unknownX.XAxis.Type = "log"
+		categoryY := salesChart()
+		categoryY.YAxis.Type = chart.AxisTypeCategory
+
+		for _, invalid := range []chart.Chart{unknownX, categoryY} {
+			// Act
+			result, err := service.Create(invalid)
+
+			// Assert
+			assert.Nil(t, result)
+			assert.ErrorIs(t, err, chart.ErrInvalidChartAxis)
+		}
+	})
+
+	t.Run("should return error when a series name is blank or taken", func(t *testing.T) {
+		// Arrange
+		service := chart.NewChartService(chart.ChartServiceDependencies{})
+		blank := salesChart()
+		blank.Series = []chart.ChartSeries{{Name: " "}}
+		taken := salesChart()
+		taken.Series = []chart.ChartSeries{{Name: "Units"}, {Name: " Units "}}
+
+		for _, invalid := range []chart.Chart{blank, taken} {
+			// Act
+			result, err := service.Create(invalid)
+
+			// Assert
+			assert.Nil(t, result)
+			assert.ErrorIs(t, err, chart.ErrInvalidChartSeries)
+		}
+	})
+
+	t.Run("should accept an x of the type of the x axis", func(t *testing.T) {
+		// Arrange
+		service := chart.NewChartService(chart.ChartServiceDependencies{ChartRepository: creatingRepo()})
+		cases := map[chart.AxisType]chart.ChartX{
+			chart.AxisTypeNumeric:  chart.NumberX(-2.5),
+			chart.AxisTypeTime:     chart.TextX("2024-01-01T10:00:00+02:00"),
+			chart.AxisTypeCategory: chart.TextX("Jan"),
+		}
+
+		for axisType, x := range cases {
+			valid := salesChart()
+			valid.XAxis.Type = axisType
+			valid.Series[0].Points = []chart.ChartPoint{{X: x, Y: 1}}
+
+			// Act
+			_, err := service.Create(valid)
+
+			// Assert
+			assert.NoError(t, err, axisType)
+		}
+	})
+
+	t.Run("should return error when an x is not of the type of the x axis", func(t *testing.T) {
+		// Arrange
+		service := chart.NewChartService(chart.ChartServiceDependencies{})
+		cases := []struct {
+			axisType chart.AxisType
+			x        chart.ChartX
+		}{
+			{chart.AxisTypeNumeric, chart.TextX("1")},
+			{chart.AxisTypeTime, chart.NumberX(1704067200)},
+			{chart.AxisTypeTime, chart.TextX("2024-01-01")},
+			{chart.AxisTypeCategory, chart.NumberX(1)},
+			{chart.AxisTypeCategory, chart.TextX(" ")},
+		}
+
+		for _, tc := range cases {
+			invalid := salesChart()
+			invalid.XAxis.Type = tc.axisType
+			invalid.Series[0].Points = []chart.ChartPoint{{X: tc.x, Y: 1}}
+
+			// Act
+			result, err := service.Create(invalid)
+
+			// Assert
+			assert.Nil(t, result)
+			assert.ErrorIs(t, err, chart.ErrInvalidChartData, tc.axisType)
+		}
+	})
+
+	t.Run("should return error when the repository fails", func(t *testing.T) {
+		// Arrange
+		service := chart.NewChartService(chart.ChartServiceDependencies{
+			ChartRepository: &mockChartRepo{
+				createFn: func(c chart.Chart) (*chart.Chart, error) { return nil, errors.New("db down") },
+			},
+		})
+
+		// Act
+		result, err := service.Create(salesChart())
+
+		// Assert
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, chart.ErrCouldNotSaveChart)
+	})
+}
+
+func TestUpdateChartService(t *testing.T) {
+	stored := salesChart()
+
+	t.Run("should change only the given fields", func(t *testing.T) {
+		// Arrange
+		service := chart.NewChartService(chart.ChartServiceDependencies{ChartRepository: storedChartRepo(&stored)})
+		title := " Revenue "
+		kind := chart.ChartKindArea
+
+		// Act
+		result, err := service.Update(stored.Id, chart.ChartChanges{Title: &title, Kind: &kind})
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, "Revenue", result.Title)
+		assert.Equal(t, chart.ChartKindArea, result.Kind)
+		assert.Equal(t, stored.XAxis, result.XAxis)
+		assert.Equal(t, stored.YAxis, result.YAxis)
+		assert.Equal(t, stored.Series, result.Series)
+	})
+
+	t.Run("should check the series against a changed x axis", func(t *testing.T) {
+		// Arrange
+		service := chart.NewChartService(chart.ChartServiceDependencies{ChartRepository: storedChartRepo(&stored)})
+
+		// Act
+		result, err := service.Update(stored.Id, chart.ChartChanges{XAxis: &chart.ChartAxis{Title: "Month", Type: chart.AxisTypeCategory}})
+
+		// Assert
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, chart.ErrInvalidChartData)
+	})
+
+	t.Run("should remove every series when the series are empty", func(t *testing.T) {
+		// Arrange
+		service := chart.NewChartService(chart.ChartServiceDependencies{ChartRepository: storedChartRepo(&stored)})
+
+		// Act
+		result, err := service.Update(stored.Id, chart.ChartChanges{Series: []chart.ChartSeries{}})
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Empty(t, result.Series)
+	})
+
+	t.Run("should return error when the title is cleared", func(t *testing.T) {
+		// Arrange
+		service := chart.NewChartService(chart.ChartServiceDependencies{ChartRepository: storedChartRepo(&stored)})
+		empty := ""
+
+		// Act
+		result, err := service.Update(stored.Id, chart.ChartChanges{Title: &empty})
+
+		// Assert
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, chart.ErrInvalidChartTitle)
+	})
+
+	t.Run("should return error when chart not found", func(t *testing.T) {
+		// Arrange
+		service := chart.NewChartService(chart.ChartServiceDependencies{ChartRepository: storedChartRepo(nil)})
+		title := "Revenue"
+
+		// Act
+		result, err := service.Update(stored.Id, chart.ChartChanges{Title: &title})
+
+		// Assert
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, chart.ErrChartNotFound)
+	})
+}
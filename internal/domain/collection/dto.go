@@ -0,0 +1,37 @@
+package collection
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Collection is a named group of a user's favourites, a favourite can be in any number of collections.
+type Collection struct {
+	Id          uuid.UUID `json:"id"`
+	UserId      uuid.UUID `json:"user_id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// CollectionChanges are the fields an update sets, an empty Name and a nil Description are left as they are.
+type CollectionChanges struct {
+	Name        string
+	Description *string
+}
+
+type CreateCollectionRequestBody struct {
+	Name        string `json:"name" validate:"required,max=100"`
+	Description string `json:"description" validate:"max=500"`
+}
+
+type UpdateCollectionRequestBody struct {
+	Name        string  `json:"name" validate:"max=100"`
+	Description *string `json:"description" validate:"omitnil,max=500"`
+}
+
+type AddCollectionFavouriteRequestBody struct {
+	FavouriteId uuid.UUID `json:"favouriteId" validate:"required"`
+}
@@ -0,0 +1,13 @@
+package collection
+
+import "errors"
+
+var (
+	ErrCollectionNotFound           = errors.New("Collection not found")
+	ErrCollectionNotUnderGivenUser  = errors.New("Collection is not under given user")
+	ErrCollectionNameTaken          = errors.New("The user already has a collection with this name")
+	ErrInvalidCollectionName        = errors.New("Collection name must not be blank")
+	ErrCouldNotSaveCollection       = errors.New("Could not save collection")
+	ErrFavouriteAlreadyInCollection = errors.New("Favourite is already in the collection")
+	ErrFavouriteNotInCollection     = errors.New("Favourite is not in the collection")
+)
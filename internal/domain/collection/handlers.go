@@ -0,0 +1,327 @@
+package collection
+
+import (
+	"errors"
+	"net/http"
+	"platform-go-challenge/internal/domain/favourite"
+	"platform-go-challenge/internal/utils"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// respondWithCollectionError answers the errors every request about a single collection can fail with.
+func respondWithCollectionError(w http.ResponseWriter, err error) {
+	if errors.Is(err, ErrCollectionNotFound) {
+		utils.RespondWithError(w, http.StatusNotFound, "Could not find Collection with this Id")
+		return
+	}
+	if errors.Is(err, ErrCollectionNotUnderGivenUser) {
+		utils.RespondWithError(w, http.StatusUnauthorized, "Collection is not under given user")
+		return
+	}
+
+	utils.RespondWithError(w, http.StatusInternalServerError, "Internal Server Error")
+}
+
+type GetCollectionsHandlerDependencies struct {
+	CollectionService CollectionService
+}
+
+func GetCollectionsHandler(dependencies GetCollectionsHandlerDependencies) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userId, err := utils.GetUserIdFromAuthToken(r)
+		if err != nil {
+			// Should not happen since we have auth middlewares before this route
+			utils.RespondWithError(w, http.StatusInternalServerError, "Internal Server Error")
+			return
+		}
+
+		pageSize, pageNumber, err := utils.GetPaginationQuery(r, 10, 0)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		collections, pagination, err := dependencies.CollectionService.GetPaginatedForUser(userId, pageSize, pageNumber)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusInternalServerError, "Internal Server Error")
+			return
+		}
+
+		utils.RespondWithPaginatedData(w, http.StatusOK, collections, *pagination)
+	}
+}
+
+type GetCollectionHandlerDependencies struct {
+	CollectionService CollectionService
+}
+
+func GetCollectionHandler(dependencies GetCollectionHandlerDependencies) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		collectionId, err := uuid.Parse(chi.URLParam(r, "id"))
+		if err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "Collection Id param is not a UUID")
+			return
+		}
+
+		userId, err := utils.GetUserIdFromAuthToken(r)
+		if err != nil {
+			// Should not happen since we have auth middlewares before this route
+			utils.RespondWithError(w, http.StatusInternalServerError, "Internal Server Error")
+			return
+		}
+
+		collection, err := dependencies.CollectionService.GetForUser(userId, collectionId)
+		if err != nil {
+			respondWithCollectionError(w, err)
+			return
+		}
+
+		utils.RespondWithData(w, http.StatusOK, collection)
+	}
+}
+
+type CreateCollectionHandlerDependencies struct {
+	CollectionService CollectionService
+}
+
+func CreateCollectionHandler(dependencies CreateCollectionHandlerDependencies) http.HandlerFunc {
+	validation := utils.BodyValidator[CreateCollectionRequestBody]
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		userId, err := utils.GetUserIdFromAuthToken(r)
+		if err != nil {
+			// Should not happen since we have auth middlewares before this route
+			utils.RespondWithError(w, http.StatusInternalServerError, "Internal Server Error")
+			return
+		}
+
+		body, ok := utils.GetParsedBody[CreateCollectionRequestBody](r)
+		if !ok {
+			// Should not happen since we validate body before getting in to handler
+			utils.RespondWithError(w, http.StatusInternalServerError, "Internal Server Error")
+			return
+		}
+
+		collection, err := dependencies.CollectionService.CreateForUser(userId, body.Name, body.Description)
+		if err != nil {
+			if errors.Is(err, ErrInvalidCollectionName) {
+				utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			if errors.Is(err, ErrCollectionNameTaken) {
+				utils.RespondWithErrorAndData(w, http.StatusConflict, "A Collection with this name already exists", collection)
+				return
+			}
+
+			utils.RespondWithError(w, http.StatusInternalServerError, "Internal Server Error")
+			return
+		}
+
+		utils.RespondWithData(w, http.StatusCreated, collection)
+	}
+
+	return validation(handler)
+}
+
+type UpdateCollectionHandlerDependencies struct {
+	CollectionService CollectionService
+}
+
+func UpdateCollectionHandler(dependencies UpdateCollectionHandlerDependencies) http.HandlerFunc {
+	validation := utils.BodyValidator[UpdateCollectionRequestBody]
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		collectionId, err := uuid.Parse(chi.URLParam(r, "id"))
+		if err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "Collection Id param is not a UUID")
+			return
+		}
+
+		userId, err := utils.GetUserIdFromAuthToken(r)
+		if err != nil {
+			// Should not happen since we have auth middlewares before this route
+			utils.RespondWithError(w, http.StatusInternalServerError, "Internal Server Error")
+			return
+		}
+
+		body, ok := utils.GetParsedBody[UpdateCollectionRequestBody](r)
+		if !ok {
+			// Should not happen since we validate body before getting in to handler
+			utils.RespondWithError(w, http.StatusInternalServerError, "Internal Server Error")
+			return
+		}
+
+		changes := CollectionChanges{Name: body.Name, Description: body.Description}
+		collection, err := dependencies.CollectionService.Update(userId, collectionId, changes)
+		if err != nil {
+			if errors.Is(err, ErrCollectionNameTaken) {
+				utils.RespondWithError(w, http.StatusConflict, "A Collection with this name already exists")
+				return
+			}
+
+			respondWithCollectionError(w, err)
+			return
+		}
+
+		utils.RespondWithData(w, http.StatusOK, collection)
+	}
+
+	return validation(handler)
+}
+
+type DeleteCollectionHandlerDependencies struct {
+	CollectionService CollectionService
+}
+
+func DeleteCollectionHandler(dependencies DeleteCollectionHandlerDependencies) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		collectionId, err := uuid.Parse(chi.URLParam(r, "id"))
+		if err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "Collection Id param is not a UUID")
+			return
+		}
+
+		userId, err := utils.GetUserIdFromAuthToken(r)
+		if err != nil {
+			// Should not happen since we have auth middlewares before this route
+			utils.RespondWithError(w, http.StatusInternalServerError, "Internal Server Error")
+			return
+		}
+
+		err = dependencies.CollectionService.Delete(userId, collectionId)
+		if err != nil {
+			respondWithCollectionError(w, err)
+			return
+		}
+
+		utils.RespondWithMessage(w, http.StatusOK, "Collection deleted")
+	}
+}
+
+type GetCollectionFavouritesHandlerDependencies struct {
+	CollectionService CollectionService
+}
+
+// GetCollectionFavouritesHandler lists the favourites like the flat layout of the favourites listing.
+func GetCollectionFavouritesHandler(dependencies GetCollectionFavouritesHandlerDependencies) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		collectionId, err := uuid.Parse(chi.URLParam(r, "id"))
+		if err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "Collection Id param is not a UUID")
+			return
+		}
+
+		userId, err := utils.GetUserIdFromAuthToken(r)
+		if err != nil {
+			// Should not happen since we have auth middlewares before this route
+			utils.RespondWithError(w, http.StatusInternalServerError, "Internal Server Error")
+			return
+		}
+
+		pageSize, pageNumber, err := utils.GetPaginationQuery(r, 10, 0)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		favourites, pagination, err := dependencies.CollectionService.GetFavouritesPaginated(userId, collectionId, pageSize, pageNumber)
+		if err != nil {
+			respondWithCollectionError(w, err)
+			return
+		}
+
+		utils.RespondWithPaginatedData(w, http.StatusOK, favourite.BuildFavouriteItems(favourites), *pagination)
+	}
+}
+
+type AddCollectionFavouriteHandlerDependencies struct {
+	CollectionService CollectionService
+}
+
+func AddCollectionFavouriteHandler(dependencies AddCollectionFavouriteHandlerDependencies) http.HandlerFunc {
+	validation := utils.BodyValidator[AddCollectionFavouriteRequestBody]
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		collectionId, err := uuid.Parse(chi.URLParam(r, "id"))
+		if err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "Collection Id param is not a UUID")
+			return
+		}
+
+		userId, err := utils.GetUserIdFromAuthToken(r)
+		if err != nil {
+			// Should not happen since we have auth middlewares before this route
+			utils.RespondWithError(w, http.StatusInternalServerError, "Internal Server Error")
+			return
+		}
+
+		body, ok := utils.GetParsedBody[AddCollectionFavouriteRequestBody](r)
+		if !ok {
+			// Should not happen since we validate body before getting in to handler
+			utils.RespondWithError(w, http.StatusInternalServerError, "Internal Server Error")
+			return
+		}
+
+		err = dependencies.CollectionService.AddFavourite(userId, collectionId, body.FavouriteId)
+		if err != nil {
+			if errors.Is(err, favourite.ErrFavouriteNotFound) {
+				utils.RespondWithError(w, http.StatusNotFound, "Could not find Favourite with this Id")
+				return
+			}
+			if errors.Is(err, favourite.ErrFavouriteNotUnderGivenUser) {
+				utils.RespondWithError(w, http.StatusUnauthorized, "Favourite is not under given user")
+				return
+			}
+			if errors.Is(err, ErrFavouriteAlreadyInCollection) {
+				utils.RespondWithError(w, http.StatusConflict, "Favourite is already in the Collection")
+				return
+			}
+
+			respondWithCollectionError(w, err)
+			return
+		}
+
+		utils.RespondWithMessage(w, http.StatusCreated, "Favourite added to the Collection")
+	}
+
+	return validation(handler)
+}
+
+type RemoveCollectionFavouriteHandlerDependencies struct {
+	CollectionService CollectionService
+}
+
+func RemoveCollectionFavouriteHandler(dependencies RemoveCollectionFavouriteHandlerDependencies) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		collectionId, err := uuid.Parse(chi.URLParam(r, "id"))
+		if err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "Collection Id param is not a UUID")
+			return
+		}
+
+		favouriteId, err := uuid.Parse(chi.URLParam(r, "favouriteId"))
+		if err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "Favourite Id param is not a UUID")
+			return
+		}
+
+		userId, err := utils.GetUserIdFromAuthToken(r)
+		if err != nil {
+			// Should not happen since we have auth middlewares before this route
+			utils.RespondWithError(w, http.StatusInternalServerError, "Internal Server Error")
+			return
+		}
+
+		err = dependencies.CollectionService.RemoveFavourite(userId, collectionId, favouriteId)
+		if err != nil {
+			if errors.Is(err, ErrFavouriteNotInCollection) {
+				utils.RespondWithError(w, http.StatusNotFound, "Favourite is not in the Collection")
+				return
+			}
+
+			respondWithCollectionError(w, err)
+			return
+		}
+
+		utils.RespondWithMessage(w, http.StatusOK, "Favourite removed from the Collection")
+	}
+}
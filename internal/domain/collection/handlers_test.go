@@ -0,0 +1,392 @@
+package collection_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"platform-go-challenge/internal/domain/collection"
+	"platform-go-challenge/internal/domain/favourite"
+	"platform-go-challenge/internal/domain/insight"
+	"platform-go-challenge/internal/utils"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+type StubCollectionService struct {
+	GetPaginatedForUserFunc    func(userId uuid.UUID, pageSize, pageNumber int) ([]collection.Collection, *utils.Pagination, error)
+	GetForUserFunc             func(userId, collectionId uuid.UUID) (*collection.Collection, error)
+	CreateForUserFunc          func(userId uuid.UUID, name, description string) (*collection.Collection, error)
+	UpdateFunc                 func(userId, collectionId uuid.UUID, changes collection.CollectionChanges) (*collection.Collection, error)
+	DeleteFunc                 func(userId, collectionId uuid.UUID) error
+	AddFavouriteFunc           func(userId, collectionId, favouriteId uuid.UUID) error
+	RemoveFavouriteFunc        func(userId, collectionId, favouriteId uuid.UUID) error
+	GetFavouritesPaginatedFunc func(userId, collectionId uuid.UUID, pageSize, pageNumber int) ([]favourite.FavouriteWithAsset, *utils.Pagination, error)
+}
+
+func (s *StubCollectionService) GetPaginatedForUser(userId uuid.UUID, pageSize, pageNumber int) ([]collection.Collection, *utils.Pagination, error) {
+	if s.GetPaginatedForUserFunc != nil {
+		return s.GetPaginatedForUserFunc(userId, pageSize, pageNumber)
+	}
+	return nil, nil, errors.New("not implemented")
+}
+
+func (s *StubCollectionService) GetForUser(userId, collectionId uuid.UUID) (*collection.Collection, error) {
+	if s.GetForUserFunc != nil {
+		return s.GetForUserFunc(userId, collectionId)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (s *StubCollectionService) CreateForUser(userId uuid.UUID, name, description string) (*collection.Collection, error) {
+	if s.CreateForUserFunc != nil {
+		return s.CreateForUserFunc(userId, name, description)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (s *StubCollectionService) Update(userId, collectionId uuid.UUID, changes collection.CollectionChanges) (*collection.Collection, error) {
+	if s.UpdateFunc != nil {
+		return s.UpdateFunc(userId, collectionId, changes)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (s *StubCollectionService) Delete(userId, collectionId uuid.UUID) error {
+	if s.DeleteFunc != nil {
+		return s.DeleteFunc(userId, collectionId)
+	}
+	return errors.New("not implemented")
+}
+
+func (s *StubCollectionService) AddFavourite(userId, collectionId, favouriteId uuid.UUID) error {
+	if s.AddFavouriteFunc != nil {
+		return s.AddFavouriteFunc(userId, collectionId, favouriteId)
+	}
+	return errors.New("not implemented")
+}
+
+func (s *StubCollectionService) RemoveFavourite(userId, collectionId, favouriteId uuid.UUID) error {
+	if s.RemoveFavouriteFunc != nil {
+		return s.RemoveFavouriteFunc(userId, collectionId, favouriteId)
+	}
+	return errors.New("not implemented")
+}
+
+func (s *StubCollectionService) GetFavouritesPaginated(userId, collectionId uuid.UUID, pageSize, pageNumber int) ([]favourite.FavouriteWithAsset, *utils.Pagination, error) {
+	if s.GetFavouritesPaginatedFunc != nil {
+		return s.GetFavouritesPaginatedFunc(userId, collectionId, pageSize, pageNumber)
+	}
+	return nil, nil, errors.New("not implemented")
+}
+
+func injectJWT(ctx context.Context, userID string) context.Context {
+	tokenAuth := jwtauth.New("HS256", []byte("secret"), nil)
+	token, _, _ := tokenAuth.Encode(map[string]interface{}{"sub": userID})
+	return jwtauth.NewContext(ctx, token, nil)
+}
+
+// withURLParams authenticates the request as the user and sets the route params chi would have matched.
+func withURLParams(req *http.Request, userId uuid.UUID, params map[string]string) *http.Request {
+	ctx := chi.NewRouteContext()
+	for key, value := range params {
+		ctx.URLParams.Add(key, value)
+	}
+
+	return req.WithContext(context.WithValue(injectJWT(req.Context(), userId.String()), chi.RouteCtxKey, ctx))
+}
+
+func TestCreateCollectionHandler(t *testing.T) {
+	t.Run("Should return 201 when collection is created successfully", func(t *testing.T) {
+		// Arrange
+		userId := uuid.New()
+		expected := &collection.Collection{Id: uuid.New(), UserId: userId, Name: "Q2 campaign"}
+		stubService := &StubCollectionService{
+			CreateForUserFunc: func(uId uuid.UUID, name, description string) (*collection.Collection, error) {
+				assert.Equal(t, userId, uId)
+				assert.Equal(t, "Q2 campaign", name)
+				assert.Equal(t, "slides", description)
+				return expected, nil
+			},
+		}
+		handler := collection.CreateCollectionHandler(collection.CreateCollectionHandlerDependencies{
+			CollectionService: stubService,
+		})
+
+		bodyBytes, _ := json.Marshal(map[string]any{"name": "Q2 campaign", "description": "slides"})
+		req := httptest.NewRequest(http.MethodPost, "/collections", bytes.NewReader(bodyBytes))
+		req = req.WithContext(injectJWT(req.Context(), userId.String()))
+		w := httptest.NewRecorder()
+
+		// Act
+		handler(w, req)
+
+		// Assert
+		assert.Equal(t, http.StatusCreated, w.Result().StatusCode)
+		var response utils.DataResponse[collection.Collection]
+		assert.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+		assert.Equal(t, *expected, response.Data)
+	})
+
+	t.Run("Should return 400 when name is missing", func(t *testing.T) {
+		// Arrange
+		handler := collection.CreateCollectionHandler(collection.CreateCollectionHandlerDependencies{
+			CollectionService: &StubCollectionService{},
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/collections", bytes.NewReader([]byte(`{"description": "slides"}`)))
+		req = req.WithContext(injectJWT(req.Context(), uuid.NewString()))
+		w := httptest.NewRecorder()
+
+		// Act
+		handler(w, req)
+
+		// Assert
+		assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
+	})
+
+	t.Run("Should return 409 with the existing collection when the name is taken", func(t *testing.T) {
+		// Arrange
+		existing := &collection.Collection{Id: uuid.New(), Name: "Q2 campaign"}
+		stubService := &StubCollectionService{
+			CreateForUserFunc: func(uuid.UUID, string, string) (*collection.Collection, error) {
+				return existing, collection.ErrCollectionNameTaken
+			},
+		}
+		handler := collection.CreateCollectionHandler(collection.CreateCollectionHandlerDependencies{
+			CollectionService: stubService,
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/collections", bytes.NewReader([]byte(`{"name": "q2 campaign"}`)))
+		req = req.WithContext(injectJWT(req.Context(), uuid.NewString()))
+		w := httptest.NewRecorder()
+
+		// Act
+		handler(w, req)
+
+		// Assert
+		assert.Equal(t, http.StatusConflict, w.Result().StatusCode)
+		assert.Contains(t, w.Body.String(), existing.Id.String())
+	})
+}
+
+func TestUpdateCollectionHandler(t *testing.T) {
+	t.Run("Should pass a missing description as unchanged", func(t *testing.T) {
+		// Arrange
+		userId := uuid.New()
+		collectionId := uuid.New()
+		stubService := &StubCollectionService{
+			UpdateFunc: func(uId, cId uuid.UUID, changes collection.CollectionChanges) (*collection.Collection, error) {
+				assert.Equal(t, userId, uId)
+				assert.Equal(t, collectionId, cId)
+				assert.Equal(t, collection.CollectionChanges{Name: "New"}, changes)
+				return &collection.Collection{Id: cId, Name: "New"}, nil
+			},
+		}
+		handler := collection.UpdateCollectionHandler(collection.UpdateCollectionHandlerDependencies{
+			CollectionService: stubService,
+		})
+
+		req := httptest.NewRequest(http.MethodPatch, "/collections", bytes.NewReader([]byte(`{"name": "New"}`)))
+		req = withURLParams(req, userId, map[string]string{"id": collectionId.String()})
+		w := httptest.NewRecorder()
+
+		// Act
+		handler(w, req)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+	})
+
+	t.Run("Should return 409 when the name is taken", func(t *testing.T) {
+		// Arrange
+		stubService := &StubCollectionService{
+			UpdateFunc: func(uuid.UUID, uuid.UUID, collection.CollectionChanges) (*collection.Collection, error) {
+				return nil, collection.ErrCollectionNameTaken
+			},
+		}
+		handler := collection.UpdateCollectionHandler(collection.UpdateCollectionHandlerDependencies{
+			CollectionService: stubService,
+		})
+
+		req := httptest.NewRequest(http.MethodPatch, "/collections", bytes.NewReader([]byte(`{"name": "Taken"}`)))
+		req = withURLParams(req, uuid.New(), map[string]string{"id": uuid.NewString()})
+		w := httptest.NewRecorder()
+
+		// Act
+		handler(w, req)
+
+		// Assert
+		assert.Equal(t, http.StatusConflict, w.Result().StatusCode)
+	})
+}
+
+func TestDeleteCollectionHandler(t *testing.T) {
+	cases := []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{"Should return 200 when delete is successful", nil, http.StatusOK},
+		{"Should return 404 when collection not found", collection.ErrCollectionNotFound, http.StatusNotFound},
+		{"Should return 401 when collection does not belong to user", collection.ErrCollectionNotUnderGivenUser, http.StatusUnauthorized},
+		{"Should return 500 when service fails unexpectedly", errors.New("db down"), http.StatusInternalServerError},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			// Arrange
+			stubService := &StubCollectionService{
+				DeleteFunc: func(uuid.UUID, uuid.UUID) error { return c.err },
+			}
+			handler := collection.DeleteCollectionHandler(collection.DeleteCollectionHandlerDependencies{
+				CollectionService: stubService,
+			})
+
+			req := httptest.NewRequest(http.MethodDelete, "/collections", nil)
+			req = withURLParams(req, uuid.New(), map[string]string{"id": uuid.NewString()})
+			w := httptest.NewRecorder()
+
+			// Act
+			handler(w, req)
+
+			// Assert
+			assert.Equal(t, c.status, w.Result().StatusCode)
+		})
+	}
+
+	t.Run("Should return 400 when id is not a UUID", func(t *testing.T) {
+		// Arrange
+		handler := collection.DeleteCollectionHandler(collection.DeleteCollectionHandlerDependencies{
+			CollectionService: &StubCollectionService{},
+		})
+
+		req := httptest.NewRequest(http.MethodDelete, "/collections", nil)
+		req = withURLParams(req, uuid.New(), map[string]string{"id": "not-a-uuid"})
+		w := httptest.NewRecorder()
+
+		// Act
+		handler(w, req)
+
+		// Assert
+		assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
+	})
+}
+
+func TestGetCollectionFavouritesHandler(t *testing.T) {
+	t.Run("Should return 200 with the favourites in the flat layout", func(t *testing.T) {
+		// Arrange
+		userId := uuid.New()
+		collectionId := uuid.New()
+		asset := insight.Insight{Id: uuid.New(), Text: "insight"}
+		fav := favourite.Favourite{Id: uuid.New(), UserId: userId, AssetId: asset.Id, AssetType: favourite.AssetTypeInsight}
+		stubService := &StubCollectionService{
+			GetFavouritesPaginatedFunc: func(uId, cId uuid.UUID, pageSize, pageNumber int) ([]favourite.FavouriteWithAsset, *utils.Pagination, error) {
+				assert.Equal(t, userId, uId)
+				assert.Equal(t, collectionId, cId)
+				assert.Equal(t, 5, pageSize)
+				assert.Equal(t, 1, pageNumber)
+				return []favourite.FavouriteWithAsset{{Favourite: fav, Asset: asset}}, &utils.Pagination{Page: 1, PageSize: 5, MaxPage: 1}, nil
+			},
+		}
+		handler := collection.GetCollectionFavouritesHandler(collection.GetCollectionFavouritesHandlerDependencies{
+			CollectionService: stubService,
+		})
+
+		req := httptest.NewRequest(http.MethodGet, "/collections/favourites?pageSize=5&pageNumber=1", nil)
+		req = withURLParams(req, userId, map[string]string{"id": collectionId.String()})
+		w := httptest.NewRecorder()
+
+		// Act
+		handler(w, req)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+		var response struct {
+			Data []struct {
+				Id   uuid.UUID           `json:"id"`
+				Type favourite.AssetType `json:"type"`
+			} `json:"data"`
+		}
+		assert.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+		if assert.Len(t, response.Data, 1) {
+			assert.Equal(t, fav.Id, response.Data[0].Id)
+			assert.Equal(t, favourite.AssetTypeInsight, response.Data[0].Type)
+		}
+	})
+}
+
+func TestAddCollectionFavouriteHandler(t *testing.T) {
+	cases := []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{"Should return 201 when the favourite is added", nil, http.StatusCreated},
+		{"Should return 404 when favourite not found", favourite.ErrFavouriteNotFound, http.StatusNotFound},
+		{"Should return 401 when favourite does not belong to user", favourite.ErrFavouriteNotUnderGivenUser, http.StatusUnauthorized},
+		{"Should return 409 when favourite is already in the collection", collection.ErrFavouriteAlreadyInCollection, http.StatusConflict},
+		{"Should return 404 when collection not found", collection.ErrCollectionNotFound, http.StatusNotFound},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			// Arrange
+			favouriteId := uuid.New()
+			stubService := &StubCollectionService{
+				AddFavouriteFunc: func(_, _ uuid.UUID, fId uuid.UUID) error {
+					assert.Equal(t, favouriteId, fId)
+					return c.err
+				},
+			}
+			handler := collection.AddCollectionFavouriteHandler(collection.AddCollectionFavouriteHandlerDependencies{
+				CollectionService: stubService,
+			})
+
+			bodyBytes, _ := json.Marshal(map[string]any{"favouriteId": favouriteId})
+			req := httptest.NewRequest(http.MethodPost, "/collections/favourites", bytes.NewReader(bodyBytes))
+			req = withURLParams(req, uuid.New(), map[string]string{"id": uuid.NewString()})
+			w := httptest.NewRecorder()
+
+			// Act
+			handler(w, req)
+
+			// Assert
+			assert.Equal(t, c.status, w.Result().StatusCode)
+		})
+	}
+}
+
+func TestRemoveCollectionFavouriteHandler(t *testing.T) {
+	t.Run("Should return 404 when favourite is not in the collection", func(t *testing.T) {
+		// Arrange
+		collectionId := uuid.New()
+		favouriteId := uuid.New()
+		stubService := &StubCollectionService{
+			RemoveFavouriteFunc: func(_, cId, fId uuid.UUID) error {
+				assert.Equal(t, collectionId, cId)
+				assert.Equal(t, favouriteId, fId)
+				return collection.ErrFavouriteNotInCollection
+			},
+		}
+		handler := collection.RemoveCollectionFavouriteHandler(collection.RemoveCollectionFavouriteHandlerDependencies{
+			CollectionService: stubService,
+		})
+
+		req := httptest.NewRequest(http.MethodDelete, "/collections/favourites", nil)
+		req = withURLParams(req, uuid.New(), map[string]string{"id": collectionId.String(), "favouriteId": favouriteId.String()})
+		w := httptest.NewRecorder()
+
+		// Act
+		handler(w, req)
+
+		// Assert
+		assert.Equal(t, http.StatusNotFound, w.Result().StatusCode)
+	})
+}
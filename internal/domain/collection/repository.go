@@ -0,0 +1,210 @@
+package collection
+
+import (
+	"errors"
+	"platform-go-challenge/internal/database"
+	"platform-go-challenge/internal/domain/favourite"
+	"platform-go-challenge/internal/utils"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type CollectionRepository interface {
+	GetById(id uuid.UUID) (*Collection, error)
+	GetByUserIdPaginated(userId uuid.UUID, pageSize int, pageNumber int) ([]Collection, utils.Pagination, error)
+	// Create returns the collection the user already has with the name, ignoring case, together with ErrCollectionNameTaken
+	Create(collection Collection) (*Collection, error)
+	Update(collection Collection) (*Collection, error)
+	Delete(id uuid.UUID) error
+	AddFavourite(collectionId uuid.UUID, favouriteId uuid.UUID, addedAt time.Time) error
+	RemoveFavourite(collectionId uuid.UUID, favouriteId uuid.UUID) error
+	// GetFavouriteIdsPaginated pages the favourites in the order they were added
+	GetFavouriteIdsPaginated(collectionId uuid.UUID, pageSize int, pageNumber int) (uuid.UUIDs, utils.Pagination, error)
+}
+
+type inMemoryDBCollectionRepository struct {
+	DB *database.IMDatabase
+}
+
+func NewInMemoryDBCollectionRepository(db *database.IMDatabase) *inMemoryDBCollectionRepository {
+	return &inMemoryDBCollectionRepository{
+		DB: db,
+	}
+}
+
+func InMemoryDBCollectionModelToDTO(model database.IMCollectionModel) Collection {
+	return Collection{
+		Id:          model.Id,
+		UserId:      model.UserId,
+		Name:        model.Name,
+		Description: model.Description,
+		CreatedAt:   model.CreatedAt,
+		UpdatedAt:   model.UpdatedAt,
+	}
+}
+
+func DTOToInMemoryDBCollectionModel(dto Collection) database.IMCollectionModel {
+	return database.IMCollectionModel{
+		Id:          dto.Id,
+		UserId:      dto.UserId,
+		Name:        dto.Name,
+		Description: dto.Description,
+		CreatedAt:   dto.CreatedAt,
+		UpdatedAt:   dto.UpdatedAt,
+	}
+}
+
+func (repo *inMemoryDBCollectionRepository) GetById(id uuid.UUID) (*Collection, error) {
+	model, found := repo.DB.CollectionStorage.Get(id)
+	if !found {
+		return nil, database.ErrItemNotFound
+	}
+
+	collection := InMemoryDBCollectionModelToDTO(model)
+
+	return &collection, nil
+}
+
+func (repo *inMemoryDBCollectionRepository) GetByUserIdPaginated(userId uuid.UUID, pageSize int, pageNumber int) ([]Collection, utils.Pagination, error) {
+	models, total, err := repo.DB.CollectionStorage.Page(database.IMCollectionsByUserIndex, userId, pageSize*pageNumber, pageSize)
+	if err != nil {
+		return nil, utils.Pagination{}, err
+	}
+
+	result := []Collection{}
+	for _, model := range models {
+		result = append(result, InMemoryDBCollectionModelToDTO(model))
+	}
+
+	maxPage := utils.CalculateMaxPages(total, pageSize)
+
+	return result, utils.Pagination{Page: pageNumber, PageSize: pageSize, MaxPage: maxPage}, nil
+}
+
+func (repo *inMemoryDBCollectionRepository) Create(collection Collection) (*Collection, error) {
+	model, err := repo.DB.CollectionStorage.Insert(collection.Id, DTOToInMemoryDBCollectionModel(collection))
+	if err != nil {
+		if errors.Is(err, database.ErrItemAlreadyExists) {
+			existing := InMemoryDBCollectionModelToDTO(model)
+			return &existing, ErrCollectionNameTaken
+		}
+
+		return nil, err
+	}
+
+	return &collection, nil
+}
+
+func (repo *inMemoryDBCollectionRepository) Update(collection Collection) (*Collection, error) {
+	model, err := repo.DB.CollectionStorage.Update(
+		collection.Id,
+		func(current database.IMCollectionModel) (database.IMCollectionModel, error) {
+			return DTOToInMemoryDBCollectionModel(collection), nil
+		},
+	)
+	if err != nil {
+		if errors.Is(err, database.ErrItemNotFound) {
+			return nil, ErrCollectionNotFound
+		}
+		if errors.Is(err, database.ErrItemAlreadyExists) {
+			return nil, ErrCollectionNameTaken
+		}
+
+		return nil, err
+	}
+
+	updated := InMemoryDBCollectionModelToDTO(model)
+
+	return &updated, nil
+}
+
+// Delete and AddFavourite run under the lock of the collection favourites, which deleting a favourite also takes
+// to take it out of its collections, so no entry is left behind for a deleted collection or favourite.
+// The collection is deleted first, the entries a crash leaves behind are dropped when the database is reopened.
+func (repo *inMemoryDBCollectionRepository) Delete(id uuid.UUID) error {
+	return repo.DB.CollectionFavouriteStorage.Batch(func(batch *database.IMBatch[database.IMCollectionFavouriteModel]) error {
+		deleted, err := repo.DB.CollectionStorage.Delete(id)
+		if err != nil {
+			return err
+		}
+
+		if !deleted {
+			return ErrCollectionNotFound
+		}
+
+		_, err = batch.DeletePartition(database.IMCollectionFavouritesByCollectionIndex, id)
+
+		return err
+	})
+}
+
+func (repo *inMemoryDBCollectionRepository) AddFavourite(collectionId uuid.UUID, favouriteId uuid.UUID, addedAt time.Time) error {
+	return repo.DB.CollectionFavouriteStorage.Batch(func(batch *database.IMBatch[database.IMCollectionFavouriteModel]) error {
+		if _, found := repo.DB.CollectionStorage.Get(collectionId); !found {
+			return ErrCollectionNotFound
+		}
+
+		if _, found := repo.DB.FavouriteStorage.Get(favouriteId); !found {
+			return favourite.ErrFavouriteNotFound
+		}
+
+		id := uuid.New()
+		_, err := batch.Insert(id, database.IMCollectionFavouriteModel{
+			Id:           id,
+			CollectionId: collectionId,
+			FavouriteId:  favouriteId,
+			AddedAt:      addedAt,
+		})
+		if errors.Is(err, database.ErrItemAlreadyExists) {
+			return ErrFavouriteAlreadyInCollection
+		}
+
+		return err
+	})
+}
+
+func (repo *inMemoryDBCollectionRepository) RemoveFavourite(collectionId uuid.UUID, favouriteId uuid.UUID) error {
+	storage := repo.DB.CollectionFavouriteStorage
+
+	model, found, err := storage.GetByKey(
+		database.IMCollectionFavouritesByCollectionFavouriteIndex,
+		database.IMCollectionFavouriteModel{CollectionId: collectionId, FavouriteId: favouriteId},
+	)
+	if err != nil {
+		return err
+	}
+
+	if !found {
+		return ErrFavouriteNotInCollection
+	}
+
+	deleted, err := storage.Delete(model.Id)
+	if err != nil {
+		return err
+	}
+
+	if !deleted {
+		return ErrFavouriteNotInCollection
+	}
+
+	return nil
+}
+
+func (repo *inMemoryDBCollectionRepository) GetFavouriteIdsPaginated(collectionId uuid.UUID, pageSize int, pageNumber int) (uuid.UUIDs, utils.Pagination, error) {
+	models, total, err := repo.DB.CollectionFavouriteStorage.Page(
+		database.IMCollectionFavouritesByCollectionIndex, collectionId, pageSize*pageNumber, pageSize,
+	)
+	if err != nil {
+		return nil, utils.Pagination{}, err
+	}
+
+	result := uuid.UUIDs{}
+	for _, model := range models {
+		result = append(result, model.FavouriteId)
+	}
+
+	maxPage := utils.CalculateMaxPages(total, pageSize)
+
+	return result, utils.Pagination{Page: pageNumber, PageSize: pageSize, MaxPage: maxPage}, nil
+}
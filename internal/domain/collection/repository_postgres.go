@@ -0,0 +1,264 @@
+package collection
+
+import (
+	"context"
+	"errors"
+	"platform-go-challenge/internal/database"
+	"platform-go-challenge/internal/domain/favourite"
+	"platform-go-challenge/internal/utils"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type postgresDBCollectionRepository struct {
+	DB *pgxpool.Pool
+}
+
+func NewPostgresDBCollectionRepository(db *pgxpool.Pool) *postgresDBCollectionRepository {
+	return &postgresDBCollectionRepository{
+		DB: db,
+	}
+}
+
+const pgCollectionColumns = "id, user_id, name, description, created_at, updated_at"
+
+const (
+	pgUniqueViolation     = "23505"
+	pgForeignKeyViolation = "23503"
+)
+
+func pgScanCollection(row pgx.CollectableRow) (Collection, error) {
+	var collection Collection
+	err := row.Scan(
+		&collection.Id,
+		&collection.UserId,
+		&collection.Name,
+		&collection.Description,
+		&collection.CreatedAt,
+		&collection.UpdatedAt,
+	)
+
+	// Timestamps are scanned in the local time zone, the domain keeps them in UTC
+	collection.CreatedAt = collection.CreatedAt.UTC()
+	collection.UpdatedAt = collection.UpdatedAt.UTC()
+
+	return collection, err
+}
+
+// pgErrorCode returns the SQLSTATE and the violated constraint of a PostgreSQL error, or empty strings for any other error.
+func pgErrorCode(err error) (string, string) {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return "", ""
+	}
+
+	return pgErr.Code, pgErr.ConstraintName
+}
+
+func (repo *postgresDBCollectionRepository) GetById(id uuid.UUID) (*Collection, error) {
+	rows, err := repo.DB.Query(
+		context.Background(),
+		"SELECT "+pgCollectionColumns+" FROM collections WHERE id = $1",
+		id,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	collection, err := pgx.CollectExactlyOneRow(rows, pgScanCollection)
+	if err != nil {
+		return nil, database.PGItemNotFound(err)
+	}
+
+	return &collection, nil
+}
+
+func (repo *postgresDBCollectionRepository) GetByUserIdPaginated(userId uuid.UUID, pageSize int, pageNumber int) ([]Collection, utils.Pagination, error) {
+	ctx := context.Background()
+
+	var totalCount int
+	err := repo.DB.QueryRow(ctx, "SELECT count(*) FROM collections WHERE user_id = $1", userId).Scan(&totalCount)
+	if err != nil {
+		return nil, utils.Pagination{}, err
+	}
+
+	rows, err := repo.DB.Query(
+		ctx,
+		"SELECT "+pgCollectionColumns+` FROM collections WHERE user_id = $1 ORDER BY name COLLATE "C", id LIMIT $2 OFFSET $3`,
+		userId, pageSize, pageSize*pageNumber,
+	)
+	if err != nil {
+		return nil, utils.Pagination{}, err
+	}
+
+	result, err := pgx.CollectRows(rows, pgScanCollection)
+	if err != nil {
+		return nil, utils.Pagination{}, err
+	}
+
+	if result == nil {
+		result = []Collection{}
+	}
+
+	maxPage := utils.CalculateMaxPages(totalCount, pageSize)
+
+	return result, utils.Pagination{Page: pageNumber, PageSize: pageSize, MaxPage: maxPage}, nil
+}
+
+// pgCreateAttempts bounds the retries when the conflicting collection is deleted between the insert and the lookup.
+const pgCreateAttempts = 3
+
+func (repo *postgresDBCollectionRepository) Create(collection Collection) (*Collection, error) {
+	ctx := context.Background()
+
+	for range pgCreateAttempts {
+		tag, err := repo.DB.Exec(
+			ctx,
+			"INSERT INTO collections ("+pgCollectionColumns+") VALUES ($1, $2, $3, $4, $5, $6) ON CONFLICT (user_id, lower(name)) DO NOTHING",
+			collection.Id, collection.UserId, collection.Name, collection.Description, collection.CreatedAt, collection.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if tag.RowsAffected() == 1 {
+			return &collection, nil
+		}
+
+		rows, err := repo.DB.Query(
+			ctx,
+			"SELECT "+pgCollectionColumns+" FROM collections WHERE user_id = $1 AND lower(name) = lower($2)",
+			collection.UserId, collection.Name,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		existing, err := pgx.CollectExactlyOneRow(rows, pgScanCollection)
+		if errors.Is(err, pgx.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		return &existing, ErrCollectionNameTaken
+	}
+
+	return nil, ErrCouldNotSaveCollection
+}
+
+func (repo *postgresDBCollectionRepository) Update(collection Collection) (*Collection, error) {
+	rows, err := repo.DB.Query(
+		context.Background(),
+		"UPDATE collections SET user_id = $2, name = $3, description = $4, created_at = $5, updated_at = $6 WHERE id = $1 RETURNING "+pgCollectionColumns,
+		collection.Id, collection.UserId, collection.Name, collection.Description, collection.CreatedAt, collection.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := pgx.CollectExactlyOneRow(rows, pgScanCollection)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrCollectionNotFound
+	}
+	if code, _ := pgErrorCode(err); code == pgUniqueViolation {
+		return nil, ErrCollectionNameTaken
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &updated, nil
+}
+
+// Delete leaves taking the favourites out of the collection to the foreign key of collection_favourites.
+func (repo *postgresDBCollectionRepository) Delete(id uuid.UUID) error {
+	tag, err := repo.DB.Exec(context.Background(), "DELETE FROM collections WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return ErrCollectionNotFound
+	}
+
+	return nil
+}
+
+func (repo *postgresDBCollectionRepository) AddFavourite(collectionId uuid.UUID, favouriteId uuid.UUID, addedAt time.Time) error {
+	tag, err := repo.DB.Exec(
+		context.Background(),
+		"INSERT INTO collection_favourites (collection_id, favourite_id, added_at) VALUES ($1, $2, $3) ON CONFLICT DO NOTHING",
+		collectionId, favouriteId, addedAt,
+	)
+	if code, constraint := pgErrorCode(err); code == pgForeignKeyViolation {
+		if constraint == "collection_favourites_collection_id_fkey" {
+			return ErrCollectionNotFound
+		}
+
+		return favourite.ErrFavouriteNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return ErrFavouriteAlreadyInCollection
+	}
+
+	return nil
+}
+
+func (repo *postgresDBCollectionRepository) RemoveFavourite(collectionId uuid.UUID, favouriteId uuid.UUID) error {
+	tag, err := repo.DB.Exec(
+		context.Background(),
+		"DELETE FROM collection_favourites WHERE collection_id = $1 AND favourite_id = $2",
+		collectionId, favouriteId,
+	)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return ErrFavouriteNotInCollection
+	}
+
+	return nil
+}
+
+func (repo *postgresDBCollectionRepository) GetFavouriteIdsPaginated(collectionId uuid.UUID, pageSize int, pageNumber int) (uuid.UUIDs, utils.Pagination, error) {
+	ctx := context.Background()
+
+	var totalCount int
+	err := repo.DB.QueryRow(ctx, "SELECT count(*) FROM collection_favourites WHERE collection_id = $1", collectionId).Scan(&totalCount)
+	if err != nil {
+		return nil, utils.Pagination{}, err
+	}
+
+	rows, err := repo.DB.Query(
+		ctx,
+		"SELECT favourite_id FROM collection_favourites WHERE collection_id = $1 ORDER BY added_at, favourite_id LIMIT $2 OFFSET $3",
+		collectionId, pageSize, pageSize*pageNumber,
+	)
+	if err != nil {
+		return nil, utils.Pagination{}, err
+	}
+
+	result, err := pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+	if err != nil {
+		return nil, utils.Pagination{}, err
+	}
+
+	if result == nil {
+		result = uuid.UUIDs{}
+	}
+
+	maxPage := utils.CalculateMaxPages(totalCount, pageSize)
+
+	return result, utils.Pagination{Page: pageNumber, PageSize: pageSize, MaxPage: maxPage}, nil
+}
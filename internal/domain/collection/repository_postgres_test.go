@@ -0,0 +1,16 @@
+package collection_test
+
+import (
+	"platform-go-challenge/internal/domain/collection"
+	"platform-go-challenge/internal/domain/favourite"
+	"platform-go-challenge/test"
+	"platform-go-challenge/test/conformance"
+	"testing"
+)
+
+func TestPostgresDBCollectionRepositoryConformance(t *testing.T) {
+	conformance.CollectionRepository(t, func(t *testing.T) (collection.CollectionRepository, favourite.FavouriteRepository) {
+		pool := test.PostgresPool(t)
+		return collection.NewPostgresDBCollectionRepository(pool), favourite.NewPostgresDBFavouriteRepository(pool)
+	})
+}
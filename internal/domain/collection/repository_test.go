@@ -0,0 +1,16 @@
+package collection_test
+
+import (
+	"platform-go-challenge/internal/database"
+	"platform-go-challenge/internal/domain/collection"
+	"platform-go-challenge/internal/domain/favourite"
+	"platform-go-challenge/test/conformance"
+	"testing"
+)
+
+func TestInMemoryDBCollectionRepositoryConformance(t *testing.T) {
+	conformance.CollectionRepository(t, func(t *testing.T) (collection.CollectionRepository, favourite.FavouriteRepository) {
+		db := database.NewIMDatabase()
+		return collection.NewInMemoryDBCollectionRepository(db), favourite.NewInMemoryDBFavouriteRepository(db)
+	})
+}
@@ -0,0 +1,189 @@
+package collection
+
+import (
+	"errors"
+	"platform-go-challenge/internal/database"
+	"platform-go-challenge/internal/domain/favourite"
+	"platform-go-challenge/internal/utils"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type CollectionService interface {
+	GetPaginatedForUser(userId uuid.UUID, pageSize int, pageNumber int) ([]Collection, *utils.Pagination, error)
+	GetForUser(userId, collectionId uuid.UUID) (*Collection, error)
+	// CreateForUser returns the collection the user already has with the name together with ErrCollectionNameTaken
+	CreateForUser(userId uuid.UUID, name string, description string) (*Collection, error)
+	Update(userId, collectionId uuid.UUID, changes CollectionChanges) (*Collection, error)
+	// Delete keeps the favourites that were in the collection
+	Delete(userId, collectionId uuid.UUID) error
+	// AddFavourite fails with favourite.ErrFavouriteNotFound or favourite.ErrFavouriteNotUnderGivenUser
+	// when the favourite is not one of the user's
+	AddFavourite(userId, collectionId, favouriteId uuid.UUID) error
+	RemoveFavourite(userId, collectionId, favouriteId uuid.UUID) error
+	// GetFavouritesPaginated returns a page of the collection's favourites in the order they were added, each with its asset
+	GetFavouritesPaginated(userId, collectionId uuid.UUID, pageSize int, pageNumber int) ([]favourite.FavouriteWithAsset, *utils.Pagination, error)
+}
+
+type CollectionServiceDependencies struct {
+	CollectionRepository CollectionRepository
+	FavouriteRepository  favourite.FavouriteRepository
+	// FavouriteService looks up the assets of the listed favourites
+	FavouriteService favourite.FavouriteService
+	// Now stamps the created, updated and added times, it defaults to time.Now
+	Now func() time.Time
+}
+
+type collectionService struct {
+	Dependencies CollectionServiceDependencies
+}
+
+func NewCollectionService(dependencies CollectionServiceDependencies) collectionService {
+	if dependencies.Now == nil {
+		dependencies.Now = time.Now
+	}
+
+	return collectionService{
+		Dependencies: dependencies,
+	}
+}
+
+// now returns the current time in UTC at the microsecond precision every backend stores.
+func (service *collectionService) now() time.Time {
+	return service.Dependencies.Now().UTC().Truncate(time.Microsecond)
+}
+
+func (service *collectionService) GetPaginatedForUser(userId uuid.UUID, pageSize int, pageNumber int) ([]Collection, *utils.Pagination, error) {
+	collections, pagination, err := service.Dependencies.CollectionRepository.GetByUserIdPaginated(userId, pageSize, pageNumber)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return collections, &pagination, nil
+}
+
+func (service *collectionService) GetForUser(userId uuid.UUID, collectionId uuid.UUID) (*Collection, error) {
+	return service.ownedCollection(userId, collectionId)
+}
+
+// ownedCollection reads the collection a request is about and checks that it is one of the user's.
+func (service *collectionService) ownedCollection(userId uuid.UUID, collectionId uuid.UUID) (*Collection, error) {
+	collection, err := service.Dependencies.CollectionRepository.GetById(collectionId)
+	if err != nil {
+		if errors.Is(err, database.ErrItemNotFound) {
+			return nil, ErrCollectionNotFound
+		}
+
+		return nil, utils.ErrUnexpected
+	}
+
+	if userId != collection.UserId {
+		return nil, ErrCollectionNotUnderGivenUser
+	}
+
+	return collection, nil
+}
+
+func (service *collectionService) CreateForUser(userId uuid.UUID, name string, description string) (*Collection, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, ErrInvalidCollectionName
+	}
+
+	now := service.now()
+	collection := Collection{
+		Id:          uuid.New(),
+		UserId:      userId,
+		Name:        name,
+		Description: description,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	created, err := service.Dependencies.CollectionRepository.Create(collection)
+	if err != nil {
+		if errors.Is(err, ErrCollectionNameTaken) {
+			return created, ErrCollectionNameTaken
+		}
+
+		return nil, ErrCouldNotSaveCollection
+	}
+
+	return created, nil
+}
+
+func (service *collectionService) Update(userId uuid.UUID, collectionId uuid.UUID, changes CollectionChanges) (*Collection, error) {
+	collection, err := service.ownedCollection(userId, collectionId)
+	if err != nil {
+		return nil, err
+	}
+
+	if name := strings.TrimSpace(changes.Name); name != "" {
+		collection.Name = name
+	}
+	if changes.Description != nil {
+		collection.Description = *changes.Description
+	}
+	collection.UpdatedAt = service.now()
+
+	return service.Dependencies.CollectionRepository.Update(*collection)
+}
+
+func (service *collectionService) Delete(userId uuid.UUID, collectionId uuid.UUID) error {
+	if _, err := service.ownedCollection(userId, collectionId); err != nil {
+		return err
+	}
+
+	return service.Dependencies.CollectionRepository.Delete(collectionId)
+}
+
+func (service *collectionService) AddFavourite(userId uuid.UUID, collectionId uuid.UUID, favouriteId uuid.UUID) error {
+	if _, err := service.ownedCollection(userId, collectionId); err != nil {
+		return err
+	}
+
+	fav, err := service.Dependencies.FavouriteRepository.GetById(favouriteId)
+	if err != nil {
+		if errors.Is(err, database.ErrItemNotFound) {
+			return favourite.ErrFavouriteNotFound
+		}
+
+		return utils.ErrUnexpected
+	}
+
+	if userId != fav.UserId {
+		return favourite.ErrFavouriteNotUnderGivenUser
+	}
+
+	return service.Dependencies.CollectionRepository.AddFavourite(collectionId, favouriteId, service.now())
+}
+
+func (service *collectionService) RemoveFavourite(userId uuid.UUID, collectionId uuid.UUID, favouriteId uuid.UUID) error {
+	if _, err := service.ownedCollection(userId, collectionId); err != nil {
+		return err
+	}
+
+	return service.Dependencies.CollectionRepository.RemoveFavourite(collectionId, favouriteId)
+}
+
+// GetFavouritesPaginated pages through the ids in the collection and looks the favourites up afterwards,
+// so a page can hold fewer favourites than its size when some of them were deleted in between.
+func (service *collectionService) GetFavouritesPaginated(userId uuid.UUID, collectionId uuid.UUID, pageSize int, pageNumber int) ([]favourite.FavouriteWithAsset, *utils.Pagination, error) {
+	if _, err := service.ownedCollection(userId, collectionId); err != nil {
+		return nil, nil, err
+	}
+
+	ids, pagination, err := service.Dependencies.CollectionRepository.GetFavouriteIdsPaginated(collectionId, pageSize, pageNumber)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	favourites, err := service.Dependencies.FavouriteService.GetManyForUser(userId, ids)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return favourites, &pagination, nil
+}
@@ -0,0 +1,387 @@
+package collection_test
+
+import (
+	"errors"
+	"platform-go-challenge/internal/database"
+	"platform-go-challenge/internal/domain/collection"
+	"platform-go-challenge/internal/domain/favourite"
+	"platform-go-challenge/internal/domain/insight"
+	"platform-go-challenge/internal/utils"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockCollectionRepo struct {
+	getByIdFn                  func(id uuid.UUID) (*collection.Collection, error)
+	getByUserIdPaginatedFn     func(userId uuid.UUID, pageSize, pageNumber int) ([]collection.Collection, utils.Pagination, error)
+	createFn                   func(c collection.Collection) (*collection.Collection, error)
+	updateFn                   func(c collection.Collection) (*collection.Collection, error)
+	deleteFn                   func(id uuid.UUID) error
+	addFavouriteFn             func(collectionId, favouriteId uuid.UUID, addedAt time.Time) error
+	removeFavouriteFn          func(collectionId, favouriteId uuid.UUID) error
+	getFavouriteIdsPaginatedFn func(collectionId uuid.UUID, pageSize, pageNumber int) (uuid.UUIDs, utils.Pagination, error)
+}
+
+func (m *mockCollectionRepo) GetById(id uuid.UUID) (*collection.Collection, error) {
+	return m.getByIdFn(id)
+}
+
+func (m *mockCollectionRepo) GetByUserIdPaginated(userId uuid.UUID, pageSize, pageNumber int) ([]collection.Collection, utils.Pagination, error) {
+	return m.getByUserIdPaginatedFn(userId, pageSize, pageNumber)
+}
+
+func (m *mockCollectionRepo) Create(c collection.Collection) (*collection.Collection, error) {
+	return m.createFn(c)
+}
+
+func (m *mockCollectionRepo) Update(c collection.Collection) (*collection.Collection, error) {
+	return m.updateFn(c)
+}
+
+func (m *mockCollectionRepo) Delete(id uuid.UUID) error {
+	return m.deleteFn(id)
+}
+
+func (m *mockCollectionRepo) AddFavourite(collectionId, favouriteId uuid.UUID, addedAt time.Time) error {
+	return m.addFavouriteFn(collectionId, favouriteId, addedAt)
+}
+
+func (m *mockCollectionRepo) RemoveFavourite(collectionId, favouriteId uuid.UUID) error {
+	return m.removeFavouriteFn(collectionId, favouriteId)
+}
+
+func (m *mockCollectionRepo) GetFavouriteIdsPaginated(collectionId uuid.UUID, pageSize, pageNumber int) (uuid.UUIDs, utils.Pagination, error) {
+	return m.getFavouriteIdsPaginatedFn(collectionId, pageSize, pageNumber)
+}
+
+// mockFavouriteRepo only reads favourites by id, which is all the collection service needs from it.
+type mockFavouriteRepo struct {
+	favourite.FavouriteRepository
+	getByIdFn func(id uuid.UUID) (*favourite.Favourite, error)
+}
+
+func (m *mockFavouriteRepo) GetById(id uuid.UUID) (*favourite.Favourite, error) {
+	return m.getByIdFn(id)
+}
+
+type mockFavouriteService struct {
+	favourite.FavouriteService
+	getManyForUserFn func(userId uuid.UUID, favouriteIds uuid.UUIDs) ([]favourite.FavouriteWithAsset, error)
+}
+
+func (m *mockFavouriteService) GetManyForUser(userId uuid.UUID, favouriteIds uuid.UUIDs) ([]favourite.FavouriteWithAsset, error) {
+	return m.getManyForUserFn(userId, favouriteIds)
+}
+
+func storedCollectionRepo(stored *collection.Collection) *mockCollectionRepo {
+	return &mockCollectionRepo{
+		getByIdFn: func(id uuid.UUID) (*collection.Collection, error) {
+			if stored == nil || stored.Id != id {
+				return nil, database.ErrItemNotFound
+			}
+			copied := *stored
+			return &copied, nil
+		},
+	}
+}
+
+func TestCreateForUserService(t *testing.T) {
+	now := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	t.Run("should create the collection with a trimmed name", func(t *testing.T) {
+		// Arrange
+		userId := uuid.New()
+		service := collection.NewCollectionService(collection.CollectionServiceDependencies{
+			CollectionRepository: &mockCollectionRepo{
+				createFn: func(c collection.Collection) (*collection.Collection, error) { return &c, nil },
+			},
+			Now: func() time.Time { return now },
+		})
+
+		// Act
+		result, err := service.CreateForUser(userId, "  Q2 campaign ", "Slides for Q2")
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, userId, result.UserId)
+		assert.Equal(t, "Q2 campaign", result.Name)
+		assert.Equal(t, "Slides for Q2", result.Description)
+		assert.Equal(t, now, result.CreatedAt)
+		assert.Equal(t, now, result.UpdatedAt)
+	})
+
+	t.Run("should return error when the name is blank", func(t *testing.T) {
+		// Arrange
+		service := collection.NewCollectionService(collection.CollectionServiceDependencies{})
+
+		// Act
+		result, err := service.CreateForUser(uuid.New(), "   ", "")
+
+		// Assert
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, collection.ErrInvalidCollectionName)
+	})
+
+	t.Run("should return the existing collection when the name is taken", func(t *testing.T) {
+		// Arrange
+		existing := collection.Collection{Id: uuid.New(), Name: "Q2 campaign"}
+		service := collection.NewCollectionService(collection.CollectionServiceDependencies{
+			CollectionRepository: &mockCollectionRepo{
+				createFn: func(c collection.Collection) (*collection.Collection, error) {
+					return &existing, collection.ErrCollectionNameTaken
+				},
+			},
+		})
+
+		// Act
+		result, err := service.CreateForUser(uuid.New(), "q2 campaign", "")
+
+		// Assert
+		assert.ErrorIs(t, err, collection.ErrCollectionNameTaken)
+		assert.Equal(t, &existing, result)
+	})
+
+	t.Run("should return error when the repository fails", func(t *testing.T) {
+		// Arrange
+		service := collection.NewCollectionService(collection.CollectionServiceDependencies{
+			CollectionRepository: &mockCollectionRepo{
+				createFn: func(c collection.Collection) (*collection.Collection, error) { return nil, errors.New("db down") },
+			},
+		})
+
+		// Act
+		result, err := service.CreateForUser(uuid.New(), "name", "")
+
+		// Assert
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, collection.ErrCouldNotSaveCollection)
+	})
+}
+
+func TestUpdateCollectionService(t *testing.T) {
+	userId := uuid.New()
+	stored := collection.Collection{Id: uuid.New(), UserId: userId, Name: "Old", Description: "old"}
+
+	t.Run("should rename and keep the description when it is not given", func(t *testing.T) {
+		// Arrange
+		repo := storedCollectionRepo(&stored)
+		repo.updateFn = func(c collection.Collection) (*collection.Collection, error) { return &c, nil }
+		service := collection.NewCollectionService(collection.CollectionServiceDependencies{CollectionRepository: repo})
+
+		// Act
+		result, err := service.Update(userId, stored.Id, collection.CollectionChanges{Name: "New"})
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, "New", result.Name)
+		assert.Equal(t, "old", result.Description)
+	})
+
+	t.Run("should clear the description when it is given empty", func(t *testing.T) {
+		// Arrange
+		repo := storedCollectionRepo(&stored)
+		repo.updateFn = func(c collection.Collection) (*collection.Collection, error) { return &c, nil }
+		service := collection.NewCollectionService(collection.CollectionServiceDependencies{CollectionRepository: repo})
+		empty := ""
+
+		// Act
+		result, err := service.Update(userId, stored.Id, collection.CollectionChanges{Description: &empty})
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, "Old", result.Name)
+		assert.Equal(t, "", result.Description)
+	})
+
+	t.Run("should return error when collection not found", func(t *testing.T) {
+		// Arrange
+		service := collection.NewCollectionService(collection.CollectionServiceDependencies{CollectionRepository: storedCollectionRepo(nil)})
+
+		// Act
+		result, err := service.Update(userId, stored.Id, collection.CollectionChanges{Name: "New"})
+
+		// Assert
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, collection.ErrCollectionNotFound)
+	})
+
+	t.Run("should return error when collection does not belong to user", func(t *testing.T) {
+		// Arrange
+		service := collection.NewCollectionService(collection.CollectionServiceDependencies{CollectionRepository: storedCollectionRepo(&stored)})
+
+		// Act
+		result, err := service.Update(uuid.New(), stored.Id, collection.CollectionChanges{Name: "New"})
+
+		// Assert
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, collection.ErrCollectionNotUnderGivenUser)
+	})
+}
+
+func TestDeleteCollectionService(t *testing.T) {
+	userId := uuid.New()
+	stored := collection.Collection{Id: uuid.New(), UserId: userId}
+
+	t.Run("should delete a collection of the user", func(t *testing.T) {
+		// Arrange
+		deleted := uuid.Nil
+		repo := storedCollectionRepo(&stored)
+		repo.deleteFn = func(id uuid.UUID) error {
+			deleted = id
+			return nil
+		}
+		service := collection.NewCollectionService(collection.CollectionServiceDependencies{CollectionRepository: repo})
+
+		// Act
+		err := service.Delete(userId, stored.Id)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, stored.Id, deleted)
+	})
+
+	t.Run("should return error when collection does not belong to user", func(t *testing.T) {
+		// Arrange
+		service := collection.NewCollectionService(collection.CollectionServiceDependencies{CollectionRepository: storedCollectionRepo(&stored)})
+
+		// Act
+		err := service.Delete(uuid.New(), stored.Id)
+
+		// Assert
+		assert.ErrorIs(t, err, collection.ErrCollectionNotUnderGivenUser)
+	})
+}
+
+func TestAddFavouriteService(t *testing.T) {
+	userId := uuid.New()
+	stored := collection.Collection{Id: uuid.New(), UserId: userId}
+	now := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	newService := func(fav *favourite.Favourite, added *uuid.UUID) collection.CollectionService {
+		repo := storedCollectionRepo(&stored)
+		repo.addFavouriteFn = func(collectionId, favouriteId uuid.UUID, addedAt time.Time) error {
+			assert.Equal(t, stored.Id, collectionId)
+			assert.Equal(t, now, addedAt)
+			*added = favouriteId
+			return nil
+		}
+		service := collection.NewCollectionService(collection.CollectionServiceDependencies{
+			CollectionRepository: repo,
+			FavouriteRepository: &mockFavouriteRepo{
+				getByIdFn: func(id uuid.UUID) (*favourite.Favourite, error) {
+					if fav == nil {
+						return nil, database.ErrItemNotFound
+					}
+					return fav, nil
+				},
+			},
+			Now: func() time.Time { return now },
+		})
+		return &service
+	}
+
+	t.Run("should add a favourite of the user", func(t *testing.T) {
+		// Arrange
+		fav := favourite.Favourite{Id: uuid.New(), UserId: userId}
+		added := uuid.Nil
+		service := newService(&fav, &added)
+
+		// Act
+		err := service.AddFavourite(userId, stored.Id, fav.Id)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, fav.Id, added)
+	})
+
+	t.Run("should return error when favourite not found", func(t *testing.T) {
+		// Arrange
+		added := uuid.Nil
+		service := newService(nil, &added)
+
+		// Act
+		err := service.AddFavourite(userId, stored.Id, uuid.New())
+
+		// Assert
+		assert.ErrorIs(t, err, favourite.ErrFavouriteNotFound)
+		assert.Equal(t, uuid.Nil, added)
+	})
+
+	t.Run("should return error when favourite does not belong to user", func(t *testing.T) {
+		// Arrange
+		fav := favourite.Favourite{Id: uuid.New(), UserId: uuid.New()}
+		added := uuid.Nil
+		service := newService(&fav, &added)
+
+		// Act
+		err := service.AddFavourite(userId, stored.Id, fav.Id)
+
+		// Assert
+		assert.ErrorIs(t, err, favourite.ErrFavouriteNotUnderGivenUser)
+		assert.Equal(t, uuid.Nil, added)
+	})
+
+	t.Run("should return error when collection does not belong to user", func(t *testing.T) {
+		// Arrange
+		fav := favourite.Favourite{Id: uuid.New(), UserId: userId}
+		added := uuid.Nil
+		service := newService(&fav, &added)
+
+		// Act
+		err := service.AddFavourite(uuid.New(), stored.Id, fav.Id)
+
+		// Assert
+		assert.ErrorIs(t, err, collection.ErrCollectionNotUnderGivenUser)
+	})
+}
+
+func TestGetFavouritesPaginatedService(t *testing.T) {
+	t.Run("should resolve the favourites of the page with their assets", func(t *testing.T) {
+		// Arrange
+		userId := uuid.New()
+		stored := collection.Collection{Id: uuid.New(), UserId: userId}
+		ids := uuid.UUIDs{uuid.New(), uuid.New()}
+		expected := []favourite.FavouriteWithAsset{
+			{Favourite: favourite.Favourite{Id: ids[0], UserId: userId}, Asset: insight.Insight{Id: uuid.New()}},
+		}
+		repo := storedCollectionRepo(&stored)
+		repo.getFavouriteIdsPaginatedFn = func(collectionId uuid.UUID, pageSize, pageNumber int) (uuid.UUIDs, utils.Pagination, error) {
+			assert.Equal(t, stored.Id, collectionId)
+			return ids, utils.Pagination{Page: pageNumber, PageSize: pageSize, MaxPage: 3}, nil
+		}
+		service := collection.NewCollectionService(collection.CollectionServiceDependencies{
+			CollectionRepository: repo,
+			FavouriteService: &mockFavouriteService{
+				getManyForUserFn: func(requestedUserId uuid.UUID, favouriteIds uuid.UUIDs) ([]favourite.FavouriteWithAsset, error) {
+					assert.Equal(t, userId, requestedUserId)
+					assert.Equal(t, ids, favouriteIds)
+					return expected, nil
+				},
+			},
+		})
+
+		// Act
+		result, pagination, err := service.GetFavouritesPaginated(userId, stored.Id, 2, 1)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, expected, result)
+		assert.Equal(t, &utils.Pagination{Page: 1, PageSize: 2, MaxPage: 3}, pagination)
+	})
+
+	t.Run("should return error when collection does not belong to user", func(t *testing.T) {
+		// Arrange
+		stored := collection.Collection{Id: uuid.New(), UserId: uuid.New()}
+		service := collection.NewCollectionService(collection.CollectionServiceDependencies{CollectionRepository: storedCollectionRepo(&stored)})
+
+		// Act
+		result, pagination, err := service.GetFavouritesPaginated(uuid.New(), stored.Id, 10, 0)
+
+		// Assert
+		assert.Nil(t, result)
+		assert.Nil(t, pagination)
+		assert.ErrorIs(t, err, collection.ErrCollectionNotUnderGivenUser)
+	})
+}
@@ -1,7 +1,46 @@
 package favourite
 
+import "time"
+
+// The built-in asset types, each registered with an AssetProvider in server.wireDependencies
 const (
 	AssetTypeChart    AssetType = "chart"
 	AssetTypeInsight  AssetType = "insight"
 	AssetTypeAudience AssetType = "audience"
 )
+
+const (
+	FavouriteSortRank          FavouriteSort = "rank"
+	FavouriteSortCreatedAt     FavouriteSort = "created_at"
+	FavouriteSortCreatedAtDesc FavouriteSort = "-created_at"
+	FavouriteSortDescription   FavouriteSort = "description"
+	FavouriteSortAssetType     FavouriteSort = "asset_type"
+)
+
+const (
+	TagMatchAll TagMatch = "all"
+	TagMatchAny TagMatch = "any"
+)
+
+const (
+	// MaxFavouriteTags bounds the tags of a favourite and MaxTagLength the characters of a tag
+	MaxFavouriteTags = 20
+	MaxTagLength     = 50
+	// DefaultTagCountLimit and MaxTagCountLimit bound how many of the most used tags are listed at once
+	DefaultTagCountLimit = 10
+	MaxTagCountLimit     = 100
+)
+
+// DefaultTrashRetention is how long a deleted favourite can be restored before it is purged
+const DefaultTrashRetention = 30 * 24 * time.Hour
+
+const (
+	FavouritesLayoutGrouped FavouritesLayout = "grouped"
+	FavouritesLayoutFlat    FavouritesLayout = "flat"
+)
+
+const (
+	FavouriteWriteCreate FavouriteWriteOp = "create"
+	FavouriteWriteUpdate FavouriteWriteOp = "update"
+	FavouriteWriteDelete FavouriteWriteOp = "delete"
+)
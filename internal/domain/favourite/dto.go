@@ -1,56 +1,210 @@
 package favourite
 
 import (
-	"platform-go-challenge/internal/domain/audience"
-	"platform-go-challenge/internal/domain/chart"
-	"platform-go-challenge/internal/domain/insight"
+	"platform-go-challenge/internal/utils"
+	"time"
 
 	"github.com/google/uuid"
 )
 
 type AssetType string
 
+// Asset is the payload a favourite points to, every asset type implements it.
+type Asset interface {
+	AssetId() uuid.UUID
+}
+
+// FavouritesLayout is the shape of a favourites listing response.
+type FavouritesLayout string
+
+// FavouriteSort is the order of a favourites listing, ties are always ordered by id.
+type FavouriteSort string
+
+// TagMatch tells whether a listing filtered by tags keeps the favourites with every tag or with any of them.
+type TagMatch string
+
+// FavouriteListOptions narrows down and orders a favourites listing, the zero value lists every favourite by rank.
+type FavouriteListOptions struct {
+	Sort FavouriteSort
+	// AssetTypes keeps the favourites of these types only, when not empty
+	AssetTypes []AssetType
+	// Query keeps the favourites whose description contains it, ignoring case, when not empty
+	Query string
+	// Tags keeps the favourites with every one of these normalised tags, or with any of them
+	// when TagMatch is TagMatchAny, when not empty
+	Tags     []string
+	TagMatch TagMatch
+}
+
+// FavouriteCursor is the position of a favourite in a listing order, it holds the sort key of the order and the id.
+type FavouriteCursor struct {
+	Sort        FavouriteSort `json:"sort"`
+	Id          uuid.UUID     `json:"id"`
+	CreatedAt   time.Time     `json:"created_at"`
+	Description string        `json:"description,omitempty"`
+	AssetType   AssetType     `json:"asset_type,omitempty"`
+	Rank        string        `json:"rank,omitempty"`
+	Pinned      bool          `json:"pinned,omitempty"`
+}
+
 type Favourite struct {
 	Id          uuid.UUID `json:"id"`
 	UserId      uuid.UUID `json:"user_id"`
 	AssetId     uuid.UUID `json:"asset_id"`
 	AssetType   AssetType `json:"asset_type"`
 	Description string    `json:"description"`
+	Version     int       `json:"version"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	// Rank orders the favourites of a user as the user arranged them, with the pinned ones first
+	Rank   string `json:"rank"`
+	Pinned bool   `json:"pinned"`
+	// Tags are normalised and sorted, see NormalizeTags
+	Tags []string `json:"tags"`
+}
+
+// TrashedFavourite is a deleted favourite, which can be restored until it expires and is purged.
+type TrashedFavourite struct {
+	Favourite
+	DeletedAt time.Time `json:"deleted_at"`
+	// ExpiresAt is set by the service from the retention of the trash, the repositories leave it zero
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// FavouriteChanges are the fields an update sets, a nil Description, a nil Pinned and nil Tags are left as they are.
+type FavouriteChanges struct {
+	// Description set to an empty string clears it
+	Description *string
+	Pinned      *bool
+	// Tags replace the tags of the favourite, an empty slice removes them all
+	Tags []string
+}
+
+// FavouriteWithAsset is a favourite together with the asset it points to.
+type FavouriteWithAsset struct {
+	Favourite Favourite
+	Asset     Asset
 }
 
-type AssetFavourites struct {
-	Charts    []ChartFavourite    `json:"charts"`
-	Insights  []InsightFavourite  `json:"insights"`
-	Audiences []AudienceFavourite `json:"audiences"`
+// FavouriteDetails is a favourite with its asset embedded.
+type FavouriteDetails struct {
+	Favourite
+	Asset Asset `json:"asset"`
 }
 
-type ChartFavourite struct {
-	Id          uuid.UUID   `json:"id"`
-	Description string      `json:"description"`
-	Info        chart.Chart `json:"info"`
+// FavouriteWriteOp tells which write a FavouriteWrite is.
+type FavouriteWriteOp string
+
+// FavouriteWrite is one write of FavouriteRepository.WriteMany, a delete only needs the id and version of the favourite.
+type FavouriteWrite struct {
+	Op        FavouriteWriteOp
+	Favourite Favourite
+	// DeletedAt is when a delete moves the favourite to the trash
+	DeletedAt time.Time
+}
+
+// FavouriteWriteResult is the outcome of one write of a batch.
+// Favourite is what the single write would have returned, it is nil for deletes.
+type FavouriteWriteResult struct {
+	Favourite *Favourite
+	Err       error
 }
 
-type InsightFavourite struct {
-	Id          uuid.UUID       `json:"id"`
-	Description string          `json:"description"`
-	Info        insight.Insight `json:"info"`
+// FavouriteItem is an entry of the flat favourites listing, Type tells which asset is embedded.
+type FavouriteItem struct {
+	Id          uuid.UUID `json:"id"`
+	Type        AssetType `json:"type"`
+	Description string    `json:"description"`
+	Asset       Asset     `json:"asset"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	Rank        string    `json:"rank"`
+	Pinned      bool      `json:"pinned"`
+	Tags        []string  `json:"tags"`
 }
 
-type AudienceFavourite struct {
-	Id          uuid.UUID         `json:"id"`
-	Description string            `json:"description"`
-	Info        audience.Audience `json:"info"`
+// TagCount is a tag together with the number of favourites of the user that have it.
+type TagCount struct {
+	Tag   string `json:"tag"`
+	Count int    `json:"count"`
+}
+
+// AssetFavourites is the grouped favourites listing, it maps the group of every registered asset type to its favourites.
+type AssetFavourites map[string][]AssetFavourite
+
+// AssetFavourite is an entry of the grouped favourites listing, Info is an asset of the type of its group.
+type AssetFavourite struct {
+	Id          uuid.UUID `json:"id"`
+	Description string    `json:"description"`
+	Info        Asset     `json:"info"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	Rank        string    `json:"rank"`
+	Pinned      bool      `json:"pinned"`
+	Tags        []string  `json:"tags"`
 }
 
 type CreateFavouriteRequestBody struct {
 	AssetId     uuid.UUID `json:"assetId" validate:"required,uuid"`
 	Description string    `json:"description" validate:"required"`
+	Tags        []string  `json:"tags" validate:"max=20,dive,max=50"`
 }
 
+// UpdateFavouriteRequestBody is a merge patch, it leaves out the fields that are not changed
+// and sets the ones to clear to null, see UpdateFavouriteRequestBody.Changes.
 type UpdateFavouriteRequestBody struct {
-	Description string `json:"description"`
+	utils.MergePatch
+	Description *string  `json:"description"`
+	Pinned      *bool    `json:"pinned"`
+	Tags        []string `json:"tags" validate:"max=20,dive,max=50"`
+}
+
+// MoveFavouriteRequestBody places a favourite right before or right after another favourite of the user.
+type MoveFavouriteRequestBody struct {
+	Before *uuid.UUID `json:"before" validate:"required_without=After,excluded_with=After"`
+	After  *uuid.UUID `json:"after" validate:"required_without=Before,excluded_with=Before"`
 }
 
 type DeleteFavouriteRequestBody struct {
 	Id uuid.UUID `json:"id" validate:"required,uuid"`
 }
+
+// CreateFavouritesBatchRequestBody creates several favourites at once, with Atomic either all of them or none.
+type CreateFavouritesBatchRequestBody struct {
+	Items  []CreateFavouriteRequestBody `json:"items" validate:"required,min=1,max=100,dive"`
+	Atomic bool                         `json:"atomic"`
+}
+
+// UpdateFavouritesBatchItem is an item of a batch update, Version works like If-Match on a single update.
+type UpdateFavouritesBatchItem struct {
+	Id uuid.UUID `json:"id" validate:"required"`
+	// Description is left as it is when it is left out, an empty string clears it
+	Description *string  `json:"description"`
+	Pinned      *bool    `json:"pinned"`
+	Tags        []string `json:"tags" validate:"max=20,dive,max=50"`
+	Version     *int     `json:"version"`
+}
+
+type UpdateFavouritesBatchRequestBody struct {
+	Items  []UpdateFavouritesBatchItem `json:"items" validate:"required,min=1,max=100,dive"`
+	Atomic bool                        `json:"atomic"`
+}
+
+// DeleteFavouritesBatchItem is an item of a batch delete, Version works like If-Match on a single delete.
+type DeleteFavouritesBatchItem struct {
+	Id      uuid.UUID `json:"id" validate:"required"`
+	Version *int      `json:"version"`
+}
+
+type DeleteFavouritesBatchRequestBody struct {
+	Items  []DeleteFavouritesBatchItem `json:"items" validate:"required,min=1,max=100,dive"`
+	Atomic bool                        `json:"atomic"`
+}
+
+// FavouriteBatchItemResult is the response for one item of a batch, in the order of the request.
+// Status is the status the item would have had as a single request.
+type FavouriteBatchItemResult struct {
+	Status int        `json:"status"`
+	Data   *Favourite `json:"data,omitempty"`
+	Error  string     `json:"error,omitempty"`
+}
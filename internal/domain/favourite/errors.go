@@ -8,4 +8,15 @@ var (
 	ErrCouldNotSaveFavourite         = errors.New("Could not save favourite")
 	ErrFavouriteNotUnderGivenUser    = errors.New("Favourite is not under given user")
 	ErrFavouriteNotFound             = errors.New("Favourite not found.")
+	ErrFavouriteAlreadyExists        = errors.New("Asset is already a favourite of the user")
+	ErrFavouriteVersionMismatch      = errors.New("Favourite was changed since the given version")
+	ErrMoveTargetNotFound            = errors.New("Could not find the favourite to move next to")
+	ErrFavouriteBatchRolledBack      = errors.New("Not applied since another item of the batch failed")
+	ErrInvalidFavouriteSort          = errors.New("sort must be one of rank, created_at, -created_at, description, asset_type")
+	ErrInvalidAssetTypeFilter        = errors.New("type must be a comma separated list of asset types")
+	ErrInvalidFavouritesLayout       = errors.New("layout must be one of grouped, flat")
+	ErrInvalidTag                    = errors.New("tags must not be blank, longer than 50 characters or contain commas")
+	ErrTooManyTags                   = errors.New("a favourite can have at most 20 tags")
+	ErrInvalidTagMatch               = errors.New("tagMatch must be one of all, any")
+	ErrInvalidTagCountLimit          = errors.New("limit must be a number between 1 and 100")
 )
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"net/http"
 	"platform-go-challenge/internal/utils"
+	"strings"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
@@ -11,6 +12,8 @@ import (
 
 type GetFavouritesHandlerDependencies struct {
 	FavouriteService FavouriteService
+	// Assets tells the asset types the listing can be filtered by and grouped into
+	Assets *AssetRegistry
 }
 
 func GetFavouritesHandler(dependencies GetFavouritesHandlerDependencies) http.HandlerFunc {
@@ -22,20 +25,145 @@ func GetFavouritesHandler(dependencies GetFavouritesHandlerDependencies) http.Ha
 			return
 		}
 
-		pageSize, pageNumber, err := utils.GetPaginationQuery(r, 10, 0)
+		page, err := utils.GetPageQuery(r, 10, 0)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		sort, err := ParseFavouriteSort(r.URL.Query().Get("sort"))
+		if err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		assetTypes, err := dependencies.Assets.ParseAssetTypes(r.URL.Query().Get("type"))
+		if err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		tags, err := ParseTagFilter(r.URL.Query()["tag"])
+		if err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		tagMatch, err := ParseTagMatch(r.URL.Query().Get("tagMatch"))
+		if err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		options := FavouriteListOptions{
+			Sort:       sort,
+			AssetTypes: assetTypes,
+			Query:      strings.TrimSpace(r.URL.Query().Get("q")),
+			Tags:       tags,
+			TagMatch:   tagMatch,
+		}
+
+		layout, err := ParseFavouritesLayout(r.URL.Query().Get("layout"), r.Header.Get("Accept"))
+		if err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		favourites, pagination, err := dependencies.FavouriteService.GetPaginatedForUser(userId, page, options)
+		if err != nil {
+			if errors.Is(err, utils.ErrInvalidCursor) {
+				utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+
+			utils.RespondWithError(w, http.StatusInternalServerError, "Internal Server Error")
+			return
+		}
+
+		w.Header().Add("Vary", "Accept")
+
+		if layout == FavouritesLayoutFlat {
+			utils.RespondWithPaginatedData(w, http.StatusOK, BuildFavouriteItems(favourites), *pagination)
+			return
+		}
+
+		utils.RespondWithPaginatedData(w, http.StatusOK, dependencies.Assets.BuildAssetFavourites(favourites), *pagination)
+	}
+}
+
+type GetFavouriteTagsHandlerDependencies struct {
+	FavouriteService FavouriteService
+}
+
+// GetFavouriteTagsHandler lists the user's most used tags, narrowed down to the ones starting with the prefix query param.
+func GetFavouriteTagsHandler(dependencies GetFavouriteTagsHandlerDependencies) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userId, err := utils.GetUserIdFromAuthToken(r)
+		if err != nil {
+			// Should not happen since we have auth middlewares before this route
+			utils.RespondWithError(w, http.StatusInternalServerError, "Internal Server Error")
+			return
+		}
+
+		limit, err := ParseTagCountLimit(r.URL.Query().Get("limit"))
 		if err != nil {
 			utils.RespondWithError(w, http.StatusBadRequest, err.Error())
 			return
 		}
 
-		assetFavourites, pagination, err := dependencies.FavouriteService.GetPaginatedForUser(userId, pageSize, pageNumber)
+		tags, err := dependencies.FavouriteService.GetTagsForUser(userId, r.URL.Query().Get("prefix"), limit)
 		if err != nil {
 			utils.RespondWithError(w, http.StatusInternalServerError, "Internal Server Error")
 			return
 		}
 
-		utils.RespondWithPaginatedData(w, http.StatusOK, *assetFavourites, *pagination)
+		utils.RespondWithData(w, http.StatusOK, tags)
+	}
+}
+
+type GetFavouriteHandlerDependencies struct {
+	FavouriteService FavouriteService
+}
+
+func GetFavouriteHandler(dependencies GetFavouriteHandlerDependencies) http.HandlerFunc {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		favouriteId, err := uuid.Parse(chi.URLParam(r, "id"))
+		if err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "Favourite Id param is not a UUID")
+			return
+		}
+
+		userId, err := utils.GetUserIdFromAuthToken(r)
+		if err != nil {
+			// Should not happen since we have auth middlewares before this route
+			utils.RespondWithError(w, http.StatusInternalServerError, "Internal Server Error")
+			return
+		}
+
+		favourite, err := dependencies.FavouriteService.GetForUser(userId, favouriteId)
+		if err != nil {
+			if errors.Is(err, ErrFavouriteNotFound) {
+				utils.RespondWithError(w, http.StatusNotFound, "Could not find Favourite with this Id")
+				return
+			}
+			if errors.Is(err, ErrAssetNotFound) {
+				utils.RespondWithError(w, http.StatusNotFound, "The asset of the Favourite no longer exists")
+				return
+			}
+			if errors.Is(err, ErrFavouriteNotUnderGivenUser) {
+				utils.RespondWithError(w, http.StatusUnauthorized, "Favourite is not under given user")
+				return
+			}
+
+			utils.RespondWithError(w, http.StatusInternalServerError, "Internal Server Error")
+			return
+		}
+
+		utils.SetETag(w, favourite.Version)
+		utils.RespondWithData(w, http.StatusOK, favourite)
 	}
+
+	return handler
 }
 
 type CreateFavouriteHandlerDependencies struct {
@@ -59,17 +187,27 @@ func CreateFavouriteHandler(dependencies CreateFavouriteHandlerDependencies) htt
 			return
 		}
 
-		favourite, err := dependencies.FavouriteService.CreateForUser(userId, body.AssetId, body.Description)
+		favourite, err := dependencies.FavouriteService.CreateForUser(userId, body.AssetId, body.Description, body.Tags)
 		if err != nil {
+			if errors.Is(err, ErrInvalidTag) || errors.Is(err, ErrTooManyTags) {
+				utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+				return
+			}
 			if errors.Is(err, ErrAssetNotFound) {
 				utils.RespondWithError(w, http.StatusNotFound, "Could not find Asset with this Id")
 				return
 			}
+			if errors.Is(err, ErrFavouriteAlreadyExists) {
+				utils.SetETag(w, favourite.Version)
+				utils.RespondWithErrorAndData(w, http.StatusConflict, "Asset is already a favourite", favourite)
+				return
+			}
 
 			utils.RespondWithError(w, http.StatusInternalServerError, "Internal Server Error")
 			return
 		}
 
+		utils.SetETag(w, favourite.Version)
 		utils.RespondWithData(w, http.StatusCreated, favourite)
 	}
 
@@ -103,21 +241,106 @@ func UpdateFavouriteHandler(dependencies UpdateFavouriteHandlerDependencies) htt
 			return
 		}
 
-		favourite, err := dependencies.FavouriteService.Update(userId, favouriteId, body.Description)
+		expectedVersion, err := utils.GetIfMatchVersion(r)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		favourite, err := dependencies.FavouriteService.Update(userId, favouriteId, body.Changes(), expectedVersion)
+		if err != nil {
+			if errors.Is(err, ErrInvalidTag) || errors.Is(err, ErrTooManyTags) {
+				utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			if errors.Is(err, ErrFavouriteNotFound) {
+				utils.RespondWithError(w, http.StatusNotFound, "Could not find Favourite with this Id")
+				return
+			}
+			if errors.Is(err, ErrFavouriteNotUnderGivenUser) {
+				utils.RespondWithError(w, http.StatusUnauthorized, "Favourite is not under given user")
+				return
+			}
+			if errors.Is(err, ErrFavouriteVersionMismatch) {
+				utils.RespondWithError(w, http.StatusPreconditionFailed, "Favourite was changed since the given version")
+				return
+			}
+
+			utils.RespondWithError(w, http.StatusInternalServerError, "Internal Server Error")
+			return
+		}
+
+		utils.SetETag(w, favourite.Version)
+		utils.RespondWithData(w, http.StatusOK, favourite)
+	}
+
+	return validation(handler)
+}
+
+type MoveFavouriteHandlerDependencies struct {
+	FavouriteService FavouriteService
+}
+
+func MoveFavouriteHandler(dependencies MoveFavouriteHandlerDependencies) http.HandlerFunc {
+	validation := utils.BodyValidator[MoveFavouriteRequestBody]
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		favouriteId, err := uuid.Parse(chi.URLParam(r, "id"))
+		if err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "Favourite Id param is not a UUID")
+			return
+		}
+
+		userId, err := utils.GetUserIdFromAuthToken(r)
+		if err != nil {
+			// Should not happen since we have auth middlewares before this route
+			utils.RespondWithError(w, http.StatusInternalServerError, "Internal Server Error")
+			return
+		}
+
+		body, ok := utils.GetParsedBody[MoveFavouriteRequestBody](r)
+		if !ok {
+			// Should not happen since we validate body before getting in to handler
+			utils.RespondWithError(w, http.StatusInternalServerError, "Internal Server Error")
+			return
+		}
+
+		expectedVersion, err := utils.GetIfMatchVersion(r)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		// The validation lets exactly one of before and after through
+		after := body.After != nil
+		targetId := body.Before
+		if after {
+			targetId = body.After
+		}
+
+		favourite, err := dependencies.FavouriteService.Move(userId, favouriteId, *targetId, after, expectedVersion)
 		if err != nil {
 			if errors.Is(err, ErrFavouriteNotFound) {
 				utils.RespondWithError(w, http.StatusNotFound, "Could not find Favourite with this Id")
 				return
 			}
+			if errors.Is(err, ErrMoveTargetNotFound) {
+				utils.RespondWithError(w, http.StatusNotFound, "Could not find the Favourite to move next to")
+				return
+			}
 			if errors.Is(err, ErrFavouriteNotUnderGivenUser) {
 				utils.RespondWithError(w, http.StatusUnauthorized, "Favourite is not under given user")
 				return
 			}
+			if errors.Is(err, ErrFavouriteVersionMismatch) {
+				utils.RespondWithError(w, http.StatusPreconditionFailed, "Favourite was changed since the given version")
+				return
+			}
 
 			utils.RespondWithError(w, http.StatusInternalServerError, "Internal Server Error")
 			return
 		}
 
+		utils.SetETag(w, favourite.Version)
 		utils.RespondWithData(w, http.StatusOK, favourite)
 	}
 
@@ -143,7 +366,13 @@ func DeleteFavouriteHandler(dependencies DeleteFavouriteHandlerDependencies) htt
 			return
 		}
 
-		err = dependencies.FavouriteService.Delete(userId, favouriteId)
+		expectedVersion, err := utils.GetIfMatchVersion(r)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		err = dependencies.FavouriteService.Delete(userId, favouriteId, expectedVersion)
 		if err != nil {
 			if errors.Is(err, ErrFavouriteNotFound) {
 				utils.RespondWithError(w, http.StatusNotFound, "Could not find Asset with this Id")
@@ -153,6 +382,10 @@ func DeleteFavouriteHandler(dependencies DeleteFavouriteHandlerDependencies) htt
 				utils.RespondWithError(w, http.StatusUnauthorized, "Favourite is not under given user")
 				return
 			}
+			if errors.Is(err, ErrFavouriteVersionMismatch) {
+				utils.RespondWithError(w, http.StatusPreconditionFailed, "Favourite was changed since the given version")
+				return
+			}
 
 			utils.RespondWithError(w, http.StatusInternalServerError, "Internal Server Error")
 			return
@@ -163,3 +396,256 @@ func DeleteFavouriteHandler(dependencies DeleteFavouriteHandlerDependencies) htt
 
 	return handler
 }
+
+type GetFavouriteTrashHandlerDependencies struct {
+	FavouriteService FavouriteService
+}
+
+func GetFavouriteTrashHandler(dependencies GetFavouriteTrashHandlerDependencies) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userId, err := utils.GetUserIdFromAuthToken(r)
+		if err != nil {
+			// Should not happen since we have auth middlewares before this route
+			utils.RespondWithError(w, http.StatusInternalServerError, "Internal Server Error")
+			return
+		}
+
+		pageSize, pageNumber, err := utils.GetPaginationQuery(r, 10, 0)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		trashed, pagination, err := dependencies.FavouriteService.GetTrashForUser(userId, pageSize, pageNumber)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusInternalServerError, "Internal Server Error")
+			return
+		}
+
+		utils.RespondWithPaginatedData(w, http.StatusOK, trashed, *pagination)
+	}
+}
+
+type RestoreFavouriteHandlerDependencies struct {
+	FavouriteService FavouriteService
+}
+
+func RestoreFavouriteHandler(dependencies RestoreFavouriteHandlerDependencies) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		favouriteId, err := uuid.Parse(chi.URLParam(r, "id"))
+		if err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "Favourite Id param is not a UUID")
+			return
+		}
+
+		userId, err := utils.GetUserIdFromAuthToken(r)
+		if err != nil {
+			// Should not happen since we have auth middlewares before this route
+			utils.RespondWithError(w, http.StatusInternalServerError, "Internal Server Error")
+			return
+		}
+
+		favourite, err := dependencies.FavouriteService.Restore(userId, favouriteId)
+		if err != nil {
+			if errors.Is(err, ErrFavouriteNotFound) {
+				utils.RespondWithError(w, http.StatusNotFound, "Could not find Favourite with this Id in the trash")
+				return
+			}
+			if errors.Is(err, ErrAssetNotFound) {
+				utils.RespondWithError(w, http.StatusNotFound, "The asset of the Favourite no longer exists")
+				return
+			}
+			if errors.Is(err, ErrFavouriteNotUnderGivenUser) {
+				utils.RespondWithError(w, http.StatusUnauthorized, "Favourite is not under given user")
+				return
+			}
+			if errors.Is(err, ErrFavouriteAlreadyExists) {
+				utils.RespondWithErrorAndData(w, http.StatusConflict, "Asset is already a favourite", favourite)
+				return
+			}
+
+			utils.RespondWithError(w, http.StatusInternalServerError, "Internal Server Error")
+			return
+		}
+
+		utils.SetETag(w, favourite.Version)
+		utils.RespondWithData(w, http.StatusOK, favourite)
+	}
+}
+
+type PurgeFavouriteHandlerDependencies struct {
+	FavouriteService FavouriteService
+}
+
+func PurgeFavouriteHandler(dependencies PurgeFavouriteHandlerDependencies) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		favouriteId, err := uuid.Parse(chi.URLParam(r, "id"))
+		if err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "Favourite Id param is not a UUID")
+			return
+		}
+
+		userId, err := utils.GetUserIdFromAuthToken(r)
+		if err != nil {
+			// Should not happen since we have auth middlewares before this route
+			utils.RespondWithError(w, http.StatusInternalServerError, "Internal Server Error")
+			return
+		}
+
+		err = dependencies.FavouriteService.Purge(userId, favouriteId)
+		if err != nil {
+			if errors.Is(err, ErrFavouriteNotFound) {
+				utils.RespondWithError(w, http.StatusNotFound, "Could not find Favourite with this Id in the trash")
+				return
+			}
+			if errors.Is(err, ErrFavouriteNotUnderGivenUser) {
+				utils.RespondWithError(w, http.StatusUnauthorized, "Favourite is not under given user")
+				return
+			}
+
+			utils.RespondWithError(w, http.StatusInternalServerError, "Internal Server Error")
+			return
+		}
+
+		utils.RespondWithMessage(w, http.StatusOK, "Favourite purged")
+	}
+}
+
+type CreateFavouritesBatchHandlerDependencies struct {
+	FavouriteService FavouriteService
+}
+
+func CreateFavouritesBatchHandler(dependencies CreateFavouritesBatchHandlerDependencies) http.HandlerFunc {
+	validation := utils.BodyValidator[CreateFavouritesBatchRequestBody]
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		userId, err := utils.GetUserIdFromAuthToken(r)
+		if err != nil {
+			// Should not happen since we have auth middlewares before this route
+			utils.RespondWithError(w, http.StatusInternalServerError, "Internal Server Error")
+			return
+		}
+
+		body, ok := utils.GetParsedBody[CreateFavouritesBatchRequestBody](r)
+		if !ok {
+			// Should not happen since we validate body before getting in to handler
+			utils.RespondWithError(w, http.StatusInternalServerError, "Internal Server Error")
+			return
+		}
+
+		results, err := dependencies.FavouriteService.CreateManyForUser(userId, body.Items, body.Atomic)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusInternalServerError, "Internal Server Error")
+			return
+		}
+
+		respondWithBatchResults(w, results, http.StatusCreated, body.Atomic)
+	}
+
+	return validation(handler)
+}
+
+type UpdateFavouritesBatchHandlerDependencies struct {
+	FavouriteService FavouriteService
+}
+
+func UpdateFavouritesBatchHandler(dependencies UpdateFavouritesBatchHandlerDependencies) http.HandlerFunc {
+	validation := utils.BodyValidator[UpdateFavouritesBatchRequestBody]
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		userId, err := utils.GetUserIdFromAuthToken(r)
+		if err != nil {
+			// Should not happen since we have auth middlewares before this route
+			utils.RespondWithError(w, http.StatusInternalServerError, "Internal Server Error")
+			return
+		}
+
+		body, ok := utils.GetParsedBody[UpdateFavouritesBatchRequestBody](r)
+		if !ok {
+			// Should not happen since we validate body before getting in to handler
+			utils.RespondWithError(w, http.StatusInternalServerError, "Internal Server Error")
+			return
+		}
+
+		results, err := dependencies.FavouriteService.UpdateMany(userId, body.Items, body.Atomic)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusInternalServerError, "Internal Server Error")
+			return
+		}
+
+		respondWithBatchResults(w, results, http.StatusOK, body.Atomic)
+	}
+
+	return validation(handler)
+}
+
+type DeleteFavouritesBatchHandlerDependencies struct {
+	FavouriteService FavouriteService
+}
+
+func DeleteFavouritesBatchHandler(dependencies DeleteFavouritesBatchHandlerDependencies) http.HandlerFunc {
+	validation := utils.BodyValidator[DeleteFavouritesBatchRequestBody]
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		userId, err := utils.GetUserIdFromAuthToken(r)
+		if err != nil {
+			// Should not happen since we have auth middlewares before this route
+			utils.RespondWithError(w, http.StatusInternalServerError, "Internal Server Error")
+			return
+		}
+
+		body, ok := utils.GetParsedBody[DeleteFavouritesBatchRequestBody](r)
+		if !ok {
+			// Should not happen since we validate body before getting in to handler
+			utils.RespondWithError(w, http.StatusInternalServerError, "Internal Server Error")
+			return
+		}
+
+		results, err := dependencies.FavouriteService.DeleteMany(userId, body.Items, body.Atomic)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusInternalServerError, "Internal Server Error")
+			return
+		}
+
+		respondWithBatchResults(w, results, http.StatusOK, body.Atomic)
+	}
+
+	return validation(handler)
+}
+
+// respondWithBatchResults responds with the result of every item of a batch,
+// and with 422 when an atomic batch was not applied because some of its items failed.
+func respondWithBatchResults(w http.ResponseWriter, results []FavouriteWriteResult, successStatus int, atomic bool) {
+	response := []FavouriteBatchItemResult{}
+	for _, result := range results {
+		response = append(response, favouriteBatchItemResult(result, successStatus))
+	}
+
+	if atomic && HasFailedWrite(results) {
+		utils.RespondWithErrorAndData(w, http.StatusUnprocessableEntity, "No favourite was changed since some items failed", response)
+		return
+	}
+
+	utils.RespondWithData(w, http.StatusOK, response)
+}
+
+// favouriteBatchItemResult returns the status and body an item of a batch would have had as a single request.
+func favouriteBatchItemResult(result FavouriteWriteResult, successStatus int) FavouriteBatchItemResult {
+	switch {
+	case result.Err == nil:
+		return FavouriteBatchItemResult{Status: successStatus, Data: result.Favourite}
+	case errors.Is(result.Err, ErrFavouriteAlreadyExists):
+		return FavouriteBatchItemResult{Status: http.StatusConflict, Data: result.Favourite, Error: "Asset is already a favourite"}
+	case errors.Is(result.Err, ErrInvalidTag), errors.Is(result.Err, ErrTooManyTags):
+		return FavouriteBatchItemResult{Status: http.StatusBadRequest, Error: result.Err.Error()}
+	case errors.Is(result.Err, ErrAssetNotFound):
+		return FavouriteBatchItemResult{Status: http.StatusNotFound, Error: "Could not find Asset with this Id"}
+	case errors.Is(result.Err, ErrFavouriteNotFound):
+		return FavouriteBatchItemResult{Status: http.StatusNotFound, Error: "Could not find Favourite with this Id"}
+	case errors.Is(result.Err, ErrFavouriteNotUnderGivenUser):
+		return FavouriteBatchItemResult{Status: http.StatusUnauthorized, Error: "Favourite is not under given user"}
+	case errors.Is(result.Err, ErrFavouriteVersionMismatch):
+		return FavouriteBatchItemResult{Status: http.StatusPreconditionFailed, Error: "Favourite was changed since the given version"}
+	case errors.Is(result.Err, ErrFavouriteBatchRolledBack):
+		return FavouriteBatchItemResult{Status: http.StatusFailedDependency, Error: "Not applied since another item of the batch failed"}
+	default:
+		return FavouriteBatchItemResult{Status: http.StatusInternalServerError, Error: "Internal Server Error"}
+	}
+}
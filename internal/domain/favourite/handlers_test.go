@@ -7,7 +7,9 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"platform-go-challenge/internal/domain/chart"
 	"platform-go-challenge/internal/domain/favourite"
+	"platform-go-challenge/internal/domain/insight"
 	"platform-go-challenge/internal/utils"
 	"strings"
 	"testing"
@@ -19,36 +21,116 @@ import (
 )
 
 type StubFavouriteService struct {
-	GetPaginatedForUserFunc func(userId uuid.UUID, pageSize, pageNumber int) (*favourite.AssetFavourites, *utils.Pagination, error)
-	CreateForUserFunc       func(userId, assetId uuid.UUID, description string) (*favourite.Favourite, error)
-	UpdateFunc              func(userId, favouriteId uuid.UUID, description string) (*favourite.Favourite, error)
-	DeleteFunc              func(userId, favouriteId uuid.UUID) error
+	GetPaginatedForUserFunc func(userId uuid.UUID, page utils.PageQuery, options favourite.FavouriteListOptions) ([]favourite.FavouriteWithAsset, *utils.Pagination, error)
+	GetForUserFunc          func(userId, favouriteId uuid.UUID) (*favourite.FavouriteDetails, error)
+	GetManyForUserFunc      func(userId uuid.UUID, favouriteIds uuid.UUIDs) ([]favourite.FavouriteWithAsset, error)
+	CreateForUserFunc       func(userId, assetId uuid.UUID, description string, tags []string) (*favourite.Favourite, error)
+	UpdateFunc              func(userId, favouriteId uuid.UUID, changes favourite.FavouriteChanges, expectedVersion *int) (*favourite.Favourite, error)
+	DeleteFunc              func(userId, favouriteId uuid.UUID, expectedVersion *int) error
+	MoveFunc                func(userId, favouriteId, targetId uuid.UUID, after bool, expectedVersion *int) (*favourite.Favourite, error)
+	CreateManyForUserFunc   func(userId uuid.UUID, items []favourite.CreateFavouriteRequestBody, atomic bool) ([]favourite.FavouriteWriteResult, error)
+	UpdateManyFunc          func(userId uuid.UUID, items []favourite.UpdateFavouritesBatchItem, atomic bool) ([]favourite.FavouriteWriteResult, error)
+	DeleteManyFunc          func(userId uuid.UUID, items []favourite.DeleteFavouritesBatchItem, atomic bool) ([]favourite.FavouriteWriteResult, error)
+	GetTagsForUserFunc      func(userId uuid.UUID, prefix string, limit int) ([]favourite.TagCount, error)
+	GetTrashForUserFunc     func(userId uuid.UUID, pageSize int, pageNumber int) ([]favourite.TrashedFavourite, *utils.Pagination, error)
+	RestoreFunc             func(userId, favouriteId uuid.UUID) (*favourite.Favourite, error)
+	PurgeFunc               func(userId, favouriteId uuid.UUID) error
 }
 
-func (s *StubFavouriteService) GetPaginatedForUser(userId uuid.UUID, pageSize, pageNumber int) (*favourite.AssetFavourites, *utils.Pagination, error) {
+func (s *StubFavouriteService) GetPaginatedForUser(userId uuid.UUID, page utils.PageQuery, options favourite.FavouriteListOptions) ([]favourite.FavouriteWithAsset, *utils.Pagination, error) {
 	if s.GetPaginatedForUserFunc != nil {
-		return s.GetPaginatedForUserFunc(userId, pageSize, pageNumber)
+		return s.GetPaginatedForUserFunc(userId, page, options)
 	}
 	return nil, nil, errors.New("not implemented")
 }
 
-func (s *StubFavouriteService) CreateForUser(userId, assetId uuid.UUID, description string) (*favourite.Favourite, error) {
+func (s *StubFavouriteService) GetForUser(userId, favouriteId uuid.UUID) (*favourite.FavouriteDetails, error) {
+	if s.GetForUserFunc != nil {
+		return s.GetForUserFunc(userId, favouriteId)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (s *StubFavouriteService) GetManyForUser(userId uuid.UUID, favouriteIds uuid.UUIDs) ([]favourite.FavouriteWithAsset, error) {
+	if s.GetManyForUserFunc != nil {
+		return s.GetManyForUserFunc(userId, favouriteIds)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (s *StubFavouriteService) CreateForUser(userId, assetId uuid.UUID, description string, tags []string) (*favourite.Favourite, error) {
 	if s.CreateForUserFunc != nil {
-		return s.CreateForUserFunc(userId, assetId, description)
+		return s.CreateForUserFunc(userId, assetId, description, tags)
 	}
 	return nil, errors.New("not implemented")
 }
 
-func (s *StubFavouriteService) Update(userId, favouriteId uuid.UUID, description string) (*favourite.Favourite, error) {
+func (s *StubFavouriteService) Update(userId, favouriteId uuid.UUID, changes favourite.FavouriteChanges, expectedVersion *int) (*favourite.Favourite, error) {
 	if s.UpdateFunc != nil {
-		return s.UpdateFunc(userId, favouriteId, description)
+		return s.UpdateFunc(userId, favouriteId, changes, expectedVersion)
 	}
 	return nil, errors.New("not implemented")
 }
 
-func (s *StubFavouriteService) Delete(userId, favouriteId uuid.UUID) error {
+func (s *StubFavouriteService) Delete(userId, favouriteId uuid.UUID, expectedVersion *int) error {
 	if s.DeleteFunc != nil {
-		return s.DeleteFunc(userId, favouriteId)
+		return s.DeleteFunc(userId, favouriteId, expectedVersion)
+	}
+	return errors.New("not implemented")
+}
+
+func (s *StubFavouriteService) Move(userId, favouriteId, targetId uuid.UUID, after bool, expectedVersion *int) (*favourite.Favourite, error) {
+	if s.MoveFunc != nil {
+		return s.MoveFunc(userId, favouriteId, targetId, after, expectedVersion)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (s *StubFavouriteService) CreateManyForUser(userId uuid.UUID, items []favourite.CreateFavouriteRequestBody, atomic bool) ([]favourite.FavouriteWriteResult, error) {
+	if s.CreateManyForUserFunc != nil {
+		return s.CreateManyForUserFunc(userId, items, atomic)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (s *StubFavouriteService) UpdateMany(userId uuid.UUID, items []favourite.UpdateFavouritesBatchItem, atomic bool) ([]favourite.FavouriteWriteResult, error) {
+	if s.UpdateManyFunc != nil {
+		return s.UpdateManyFunc(userId, items, atomic)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (s *StubFavouriteService) DeleteMany(userId uuid.UUID, items []favourite.DeleteFavouritesBatchItem, atomic bool) ([]favourite.FavouriteWriteResult, error) {
+	if s.DeleteManyFunc != nil {
+		return s.DeleteManyFunc(userId, items, atomic)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (s *StubFavouriteService) GetTagsForUser(userId uuid.UUID, prefix string, limit int) ([]favourite.TagCount, error) {
+	if s.GetTagsForUserFunc != nil {
+		return s.GetTagsForUserFunc(userId, prefix, limit)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (s *StubFavouriteService) GetTrashForUser(userId uuid.UUID, pageSize int, pageNumber int) ([]favourite.TrashedFavourite, *utils.Pagination, error) {
+	if s.GetTrashForUserFunc != nil {
+		return s.GetTrashForUserFunc(userId, pageSize, pageNumber)
+	}
+	return nil, nil, errors.New("not implemented")
+}
+
+func (s *StubFavouriteService) Restore(userId, favouriteId uuid.UUID) (*favourite.Favourite, error) {
+	if s.RestoreFunc != nil {
+		return s.RestoreFunc(userId, favouriteId)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (s *StubFavouriteService) Purge(userId, favouriteId uuid.UUID) error {
+	if s.PurgeFunc != nil {
+		return s.PurgeFunc(userId, favouriteId)
 	}
 	return errors.New("not implemented")
 }
@@ -68,13 +150,14 @@ func TestGetFavouritesHandler(t *testing.T) {
 		// Arrange
 		validUUID := uuid.New()
 		stubService := &StubFavouriteService{
-			GetPaginatedForUserFunc: func(userId uuid.UUID, pageSize, pageNumber int) (*favourite.AssetFavourites, *utils.Pagination, error) {
+			GetPaginatedForUserFunc: func(userId uuid.UUID, page utils.PageQuery, options favourite.FavouriteListOptions) ([]favourite.FavouriteWithAsset, *utils.Pagination, error) {
 				assert.Equal(t, validUUID, userId)
-				return &favourite.AssetFavourites{}, &utils.Pagination{}, nil
+				return []favourite.FavouriteWithAsset{}, &utils.Pagination{}, nil
 			},
 		}
 		handler := favourite.GetFavouritesHandler(favourite.GetFavouritesHandlerDependencies{
 			FavouriteService: stubService,
+			Assets:           builtInAssets(),
 		})
 		req := httptest.NewRequest(http.MethodGet, "/favourites", nil)
 		req = req.WithContext(injectJWT(req.Context(), validUUID.String()))
@@ -87,29 +170,45 @@ func TestGetFavouritesHandler(t *testing.T) {
 		assert.Equal(t, http.StatusOK, w.Result().StatusCode)
 	})
 
-	t.Run("Should return 500 when JWT sub is invalid UUID", func(t *testing.T) {
+	t.Run("Should pass the sort query to the service", func(t *testing.T) {
 		// Arrange
+		validUUID := uuid.New()
+		stubService := &StubFavouriteService{
+			GetPaginatedForUserFunc: func(userId uuid.UUID, page utils.PageQuery, options favourite.FavouriteListOptions) ([]favourite.FavouriteWithAsset, *utils.Pagination, error) {
+				assert.Equal(t, favourite.FavouriteSortCreatedAtDesc, options.Sort)
+				return []favourite.FavouriteWithAsset{}, &utils.Pagination{}, nil
+			},
+		}
 		handler := favourite.GetFavouritesHandler(favourite.GetFavouritesHandlerDependencies{
-			FavouriteService: &StubFavouriteService{},
+			FavouriteService: stubService,
+			Assets:           builtInAssets(),
 		})
-		req := httptest.NewRequest(http.MethodGet, "/favourites", nil)
-		req = req.WithContext(injectJWT(req.Context(), "not-a-uuid"))
+		req := httptest.NewRequest(http.MethodGet, "/favourites?sort=-created_at", nil)
+		req = req.WithContext(injectJWT(req.Context(), validUUID.String()))
 		w := httptest.NewRecorder()
 
 		// Act
 		handler(w, req)
 
 		// Assert
-		assert.Equal(t, http.StatusInternalServerError, w.Result().StatusCode)
+		assert.Equal(t, http.StatusOK, w.Result().StatusCode)
 	})
 
-	t.Run("Should return 400 when pagination query is invalid", func(t *testing.T) {
+	t.Run("Should pass the type and q filters to the service", func(t *testing.T) {
 		// Arrange
 		validUUID := uuid.New()
+		stubService := &StubFavouriteService{
+			GetPaginatedForUserFunc: func(userId uuid.UUID, page utils.PageQuery, options favourite.FavouriteListOptions) ([]favourite.FavouriteWithAsset, *utils.Pagination, error) {
+				assert.Equal(t, []favourite.AssetType{favourite.AssetTypeChart, favourite.AssetTypeAudience}, options.AssetTypes)
+				assert.Equal(t, "q2 review", options.Query)
+				return []favourite.FavouriteWithAsset{}, &utils.Pagination{}, nil
+			},
+		}
 		handler := favourite.GetFavouritesHandler(favourite.GetFavouritesHandlerDependencies{
-			FavouriteService: &StubFavouriteService{},
+			FavouriteService: stubService,
+			Assets:           builtInAssets(),
 		})
-		req := httptest.NewRequest(http.MethodGet, "/favourites?pageSize=invalid", nil)
+		req := httptest.NewRequest(http.MethodGet, "/favourites?type=chart,audience&q=+q2+review+", nil)
 		req = req.WithContext(injectJWT(req.Context(), validUUID.String()))
 		w := httptest.NewRecorder()
 
@@ -117,20 +216,24 @@ func TestGetFavouritesHandler(t *testing.T) {
 		handler(w, req)
 
 		// Assert
-		assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
+		assert.Equal(t, http.StatusOK, w.Result().StatusCode)
 	})
 
-	t.Run("Should return 500 when service returns error", func(t *testing.T) {
+	t.Run("Should pass the tag filter to the service", func(t *testing.T) {
 		// Arrange
 		validUUID := uuid.New()
-		handler := favourite.GetFavouritesHandler(favourite.GetFavouritesHandlerDependencies{
-			FavouriteService: &StubFavouriteService{
-				GetPaginatedForUserFunc: func(userId uuid.UUID, pageSize, pageNumber int) (*favourite.AssetFavourites, *utils.Pagination, error) {
-					return nil, nil, errors.New("fail")
-				},
+		stubService := &StubFavouriteService{
+			GetPaginatedForUserFunc: func(userId uuid.UUID, page utils.PageQuery, options favourite.FavouriteListOptions) ([]favourite.FavouriteWithAsset, *utils.Pagination, error) {
+				assert.Equal(t, []string{"q2", "sales"}, options.Tags)
+				assert.Equal(t, favourite.TagMatchAny, options.TagMatch)
+				return []favourite.FavouriteWithAsset{}, &utils.Pagination{}, nil
 			},
+		}
+		handler := favourite.GetFavouritesHandler(favourite.GetFavouritesHandlerDependencies{
+			FavouriteService: stubService,
+			Assets:           builtInAssets(),
 		})
-		req := httptest.NewRequest(http.MethodGet, "/favourites", nil)
+		req := httptest.NewRequest(http.MethodGet, "/favourites?tag=Sales&tag=q2&tagMatch=any", nil)
 		req = req.WithContext(injectJWT(req.Context(), validUUID.String()))
 		w := httptest.NewRecorder()
 
@@ -138,109 +241,98 @@ func TestGetFavouritesHandler(t *testing.T) {
 		handler(w, req)
 
 		// Assert
-		assert.Equal(t, http.StatusInternalServerError, w.Result().StatusCode)
+		assert.Equal(t, http.StatusOK, w.Result().StatusCode)
 	})
 
-	t.Run("Should return 500 when JWT is missing", func(t *testing.T) {
+	t.Run("Should return 400 when tagMatch query is unknown", func(t *testing.T) {
 		// Arrange
+		validUUID := uuid.New()
 		handler := favourite.GetFavouritesHandler(favourite.GetFavouritesHandlerDependencies{
 			FavouriteService: &StubFavouriteService{},
+			Assets:           builtInAssets(),
 		})
-		req := httptest.NewRequest(http.MethodGet, "/favourites", nil)
+		req := httptest.NewRequest(http.MethodGet, "/favourites?tag=q2&tagMatch=some", nil)
+		req = req.WithContext(injectJWT(req.Context(), validUUID.String()))
 		w := httptest.NewRecorder()
 
 		// Act
 		handler(w, req)
 
 		// Assert
-		assert.Equal(t, http.StatusInternalServerError, w.Result().StatusCode)
+		assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
+		assert.Contains(t, w.Body.String(), favourite.ErrInvalidTagMatch.Error())
 	})
-}
 
-func TestCreateFavouriteHandler(t *testing.T) {
-	t.Run("Should return 201 when favourite is created successfully", func(t *testing.T) {
+	t.Run("Should return 400 when type query is unknown", func(t *testing.T) {
 		// Arrange
-		userId := uuid.New()
-		assetId := uuid.New()
-
-		requestBody := map[string]interface{}{
-			"assetId":     assetId.String(),
-			"description": "test",
-		}
-		expected := &favourite.Favourite{
-			Id:          uuid.New(),
-			UserId:      userId,
-			AssetId:     assetId,
-			AssetType:   "chart",
-			Description: "test",
-		}
-		stubService := &StubFavouriteService{
-			CreateForUserFunc: func(uId, aId uuid.UUID, desc string) (*favourite.Favourite, error) {
-				assert.Equal(t, userId, uId)
-				assert.Equal(t, assetId, aId)
-				assert.Equal(t, "test", desc)
-				return expected, nil
-			},
-		}
-		handler := favourite.CreateFavouriteHandler(favourite.CreateFavouriteHandlerDependencies{
-			FavouriteService: stubService,
+		validUUID := uuid.New()
+		handler := favourite.GetFavouritesHandler(favourite.GetFavouritesHandlerDependencies{
+			FavouriteService: &StubFavouriteService{},
+			Assets:           builtInAssets(),
 		})
-
-		bodyBytes, _ := json.Marshal(requestBody)
-		req := httptest.NewRequest(http.MethodPost, "/favourites", bytes.NewReader(bodyBytes))
-		req = req.WithContext(injectJWT(req.Context(), userId.String()))
-		req.Header.Set("Content-Type", "application/json")
+		req := httptest.NewRequest(http.MethodGet, "/favourites?type=chart,report", nil)
+		req = req.WithContext(injectJWT(req.Context(), validUUID.String()))
 		w := httptest.NewRecorder()
 
 		// Act
 		handler(w, req)
 
 		// Assert
-		assert.Equal(t, http.StatusCreated, w.Result().StatusCode)
+		assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
 	})
 
-	t.Run("Should return 404 when asset is not found", func(t *testing.T) {
+	t.Run("Should return one ordered list when the flat layout is asked in Accept", func(t *testing.T) {
 		// Arrange
-		userId := uuid.New()
-		assetId := uuid.New()
-
-		requestBody := map[string]interface{}{
-			"assetId":     assetId.String(),
-			"description": "test",
-		}
+		validUUID := uuid.New()
+		chartAsset := chart.Chart{Id: uuid.New(), Title: "Sales"}
+		insightAsset := insight.Insight{Id: uuid.New(), Text: "Retention"}
 		stubService := &StubFavouriteService{
-			CreateForUserFunc: func(_, _ uuid.UUID, _ string) (*favourite.Favourite, error) {
-				return nil, favourite.ErrAssetNotFound
+			GetPaginatedForUserFunc: func(userId uuid.UUID, page utils.PageQuery, options favourite.FavouriteListOptions) ([]favourite.FavouriteWithAsset, *utils.Pagination, error) {
+				return []favourite.FavouriteWithAsset{
+					{Favourite: favourite.Favourite{Id: uuid.New(), AssetType: favourite.AssetTypeInsight}, Asset: insightAsset},
+					{Favourite: favourite.Favourite{Id: uuid.New(), AssetType: favourite.AssetTypeChart}, Asset: chartAsset},
+				}, &utils.Pagination{}, nil
 			},
 		}
-		handler := favourite.CreateFavouriteHandler(favourite.CreateFavouriteHandlerDependencies{
+		handler := favourite.GetFavouritesHandler(favourite.GetFavouritesHandlerDependencies{
 			FavouriteService: stubService,
+			Assets:           builtInAssets(),
 		})
-
-		bodyBytes, _ := json.Marshal(requestBody)
-		req := httptest.NewRequest(http.MethodPost, "/favourites", bytes.NewReader(bodyBytes))
-		req = req.WithContext(injectJWT(req.Context(), userId.String()))
-		req.Header.Set("Content-Type", "application/json")
+		req := httptest.NewRequest(http.MethodGet, "/favourites", nil)
+		req.Header.Set("Accept", `application/json; profile="flat"`)
+		req = req.WithContext(injectJWT(req.Context(), validUUID.String()))
 		w := httptest.NewRecorder()
 
 		// Act
 		handler(w, req)
 
 		// Assert
-		assert.Equal(t, http.StatusNotFound, w.Result().StatusCode)
+		assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+		assert.Equal(t, "Accept", w.Header().Get("Vary"))
+		var body struct {
+			Data []struct {
+				Type  string         `json:"type"`
+				Asset map[string]any `json:"asset"`
+			} `json:"data"`
+		}
+		assert.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+		if assert.Len(t, body.Data, 2) {
+			assert.Equal(t, "insight", body.Data[0].Type)
+			assert.Equal(t, "Retention", body.Data[0].Asset["text"])
+			assert.Equal(t, "chart", body.Data[1].Type)
+			assert.Equal(t, "Sales", body.Data[1].Asset["title"])
+		}
 	})
 
-	t.Run("Should return 400 if body is invalid JSON", func(t *testing.T) {
+	t.Run("Should return 400 when layout query is unknown", func(t *testing.T) {
 		// Arrange
-		userId := uuid.New()
-
-		handler := favourite.CreateFavouriteHandler(favourite.CreateFavouriteHandlerDependencies{
+		validUUID := uuid.New()
+		handler := favourite.GetFavouritesHandler(favourite.GetFavouritesHandlerDependencies{
 			FavouriteService: &StubFavouriteService{},
+			Assets:           builtInAssets(),
 		})
-
-		req := httptest.NewRequest(http.MethodPost, "/favourites", strings.NewReader("{invalid json}"))
-		req = req.WithContext(injectJWT(req.Context(), userId.String()))
-		req.Header.Set("Content-Type", "application/json")
+		req := httptest.NewRequest(http.MethodGet, "/favourites?layout=table", nil)
+		req = req.WithContext(injectJWT(req.Context(), validUUID.String()))
 		w := httptest.NewRecorder()
 
 		// Act
@@ -250,20 +342,15 @@ func TestCreateFavouriteHandler(t *testing.T) {
 		assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
 	})
 
-	t.Run("Should return 400 if required fields are missing", func(t *testing.T) {
+	t.Run("Should return 400 when sort query is unknown", func(t *testing.T) {
 		// Arrange
-		userId := uuid.New()
-		requestBody := map[string]interface{}{}
-
-		bodyBytes, _ := json.Marshal(requestBody)
-
-		handler := favourite.CreateFavouriteHandler(favourite.CreateFavouriteHandlerDependencies{
+		validUUID := uuid.New()
+		handler := favourite.GetFavouritesHandler(favourite.GetFavouritesHandlerDependencies{
 			FavouriteService: &StubFavouriteService{},
+			Assets:           builtInAssets(),
 		})
-
-		req := httptest.NewRequest(http.MethodPost, "/favourites", bytes.NewReader(bodyBytes))
-		req = req.WithContext(injectJWT(req.Context(), userId.String()))
-		req.Header.Set("Content-Type", "application/json")
+		req := httptest.NewRequest(http.MethodGet, "/favourites?sort=id", nil)
+		req = req.WithContext(injectJWT(req.Context(), validUUID.String()))
 		w := httptest.NewRecorder()
 
 		// Act
@@ -273,22 +360,14 @@ func TestCreateFavouriteHandler(t *testing.T) {
 		assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
 	})
 
-	t.Run("Should return 500 if JWT is missing", func(t *testing.T) {
+	t.Run("Should return 500 when JWT sub is invalid UUID", func(t *testing.T) {
 		// Arrange
-		assetId := uuid.New()
-		requestBody := map[string]interface{}{
-			"assetId":     assetId.String(),
-			"description": "testaki",
-		}
-
-		bodyBytes, _ := json.Marshal(requestBody)
-
-		handler := favourite.CreateFavouriteHandler(favourite.CreateFavouriteHandlerDependencies{
+		handler := favourite.GetFavouritesHandler(favourite.GetFavouritesHandlerDependencies{
 			FavouriteService: &StubFavouriteService{},
+			Assets:           builtInAssets(),
 		})
-
-		req := httptest.NewRequest(http.MethodPost, "/favourites", bytes.NewReader(bodyBytes))
-		req.Header.Set("Content-Type", "application/json")
+		req := httptest.NewRequest(http.MethodGet, "/favourites", nil)
+		req = req.WithContext(injectJWT(req.Context(), "not-a-uuid"))
 		w := httptest.NewRecorder()
 
 		// Act
@@ -297,141 +376,1149 @@ func TestCreateFavouriteHandler(t *testing.T) {
 		// Assert
 		assert.Equal(t, http.StatusInternalServerError, w.Result().StatusCode)
 	})
-}
 
-func TestUpdateFavouriteHandler(t *testing.T) {
-	t.Run("Should return 200 when update is successful", func(t *testing.T) {
+	t.Run("Should return 400 when pagination query is invalid", func(t *testing.T) {
 		// Arrange
-		userId := uuid.New()
-		favouriteId := uuid.New()
-
-		requestBody := map[string]interface{}{
-			"description": "updated description",
-		}
-
-		expected := &favourite.Favourite{
-			Id:          favouriteId,
-			UserId:      userId,
-			AssetId:     uuid.New(),
-			AssetType:   "chart",
-			Description: "test",
-		}
-		stubService := &StubFavouriteService{
-			UpdateFunc: func(uId, fId uuid.UUID, desc string) (*favourite.Favourite, error) {
-				assert.Equal(t, userId, uId)
-				assert.Equal(t, favouriteId, fId)
-				assert.Equal(t, "updated description", desc)
-				return expected, nil
-			},
-		}
-		handler := favourite.UpdateFavouriteHandler(favourite.UpdateFavouriteHandlerDependencies{
-			FavouriteService: stubService,
+		validUUID := uuid.New()
+		handler := favourite.GetFavouritesHandler(favourite.GetFavouritesHandlerDependencies{
+			FavouriteService: &StubFavouriteService{},
+			Assets:           builtInAssets(),
 		})
-
-		bodyBytes, _ := json.Marshal(requestBody)
-
-		ctx := chi.NewRouteContext()
-		ctx.URLParams.Add("id", favouriteId.String())
-
-		req := httptest.NewRequest(http.MethodPatch, "/favourites", bytes.NewReader(bodyBytes))
-		req = req.WithContext(context.WithValue(injectJWT(req.Context(), userId.String()), chi.RouteCtxKey, ctx))
-		req.Header.Set("Content-Type", "application/json")
+		req := httptest.NewRequest(http.MethodGet, "/favourites?pageSize=invalid", nil)
+		req = req.WithContext(injectJWT(req.Context(), validUUID.String()))
 		w := httptest.NewRecorder()
 
 		// Act
 		handler(w, req)
 
 		// Assert
-		assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+		assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
 	})
 
-	t.Run("Should return 404 when favourite is not found", func(t *testing.T) {
+	t.Run("Should pass the cursor query to the service", func(t *testing.T) {
 		// Arrange
-		userId := uuid.New()
-		favouriteId := uuid.New()
-
-		requestBody := map[string]interface{}{
-			"description": "does not matter",
-		}
-
+		validUUID := uuid.New()
 		stubService := &StubFavouriteService{
-			UpdateFunc: func(_, _ uuid.UUID, _ string) (*favourite.Favourite, error) {
-				return nil, favourite.ErrFavouriteNotFound
+			GetPaginatedForUserFunc: func(userId uuid.UUID, page utils.PageQuery, options favourite.FavouriteListOptions) ([]favourite.FavouriteWithAsset, *utils.Pagination, error) {
+				assert.Equal(t, utils.PageQuery{Size: 5, Before: "cursor"}, page)
+				return []favourite.FavouriteWithAsset{}, &utils.Pagination{PageSize: 5, NextCursor: "next"}, nil
 			},
 		}
-		handler := favourite.UpdateFavouriteHandler(favourite.UpdateFavouriteHandlerDependencies{
+		handler := favourite.GetFavouritesHandler(favourite.GetFavouritesHandlerDependencies{
 			FavouriteService: stubService,
+			Assets:           builtInAssets(),
 		})
+		req := httptest.NewRequest(http.MethodGet, "/favourites?pageSize=5&before=cursor", nil)
+		req = req.WithContext(injectJWT(req.Context(), validUUID.String()))
+		w := httptest.NewRecorder()
 
-		bodyBytes, _ := json.Marshal(requestBody)
+		// Act
+		handler(w, req)
 
-		ctx := chi.NewRouteContext()
-		ctx.URLParams.Add("id", favouriteId.String())
+		// Assert
+		assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+		assert.Contains(t, w.Body.String(), `"nextCursor":"next"`)
+		assert.NotContains(t, w.Body.String(), "prevCursor")
+	})
 
-		req := httptest.NewRequest(http.MethodPatch, "/favourites", bytes.NewReader(bodyBytes))
-		req = req.WithContext(context.WithValue(injectJWT(req.Context(), userId.String()), chi.RouteCtxKey, ctx))
-		req.Header.Set("Content-Type", "application/json")
+	t.Run("Should return 400 when after and before are both given", func(t *testing.T) {
+		// Arrange
+		validUUID := uuid.New()
+		handler := favourite.GetFavouritesHandler(favourite.GetFavouritesHandlerDependencies{
+			FavouriteService: &StubFavouriteService{},
+			Assets:           builtInAssets(),
+		})
+		req := httptest.NewRequest(http.MethodGet, "/favourites?after=a&before=b", nil)
+		req = req.WithContext(injectJWT(req.Context(), validUUID.String()))
 		w := httptest.NewRecorder()
 
 		// Act
 		handler(w, req)
 
 		// Assert
-		assert.Equal(t, http.StatusNotFound, w.Result().StatusCode)
+		assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
+	})
+
+	t.Run("Should return 400 when cursor is invalid", func(t *testing.T) {
+		// Arrange
+		validUUID := uuid.New()
+		handler := favourite.GetFavouritesHandler(favourite.GetFavouritesHandlerDependencies{
+			FavouriteService: &StubFavouriteService{
+				GetPaginatedForUserFunc: func(userId uuid.UUID, page utils.PageQuery, options favourite.FavouriteListOptions) ([]favourite.FavouriteWithAsset, *utils.Pagination, error) {
+					return nil, nil, utils.ErrInvalidCursor
+				},
+			},
+			Assets: builtInAssets(),
+		})
+		req := httptest.NewRequest(http.MethodGet, "/favourites?after=forged", nil)
+		req = req.WithContext(injectJWT(req.Context(), validUUID.String()))
+		w := httptest.NewRecorder()
+
+		// Act
+		handler(w, req)
+
+		// Assert
+		assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
+	})
+
+	t.Run("Should return 500 when service returns error", func(t *testing.T) {
+		// Arrange
+		validUUID := uuid.New()
+		handler := favourite.GetFavouritesHandler(favourite.GetFavouritesHandlerDependencies{
+			FavouriteService: &StubFavouriteService{
+				GetPaginatedForUserFunc: func(userId uuid.UUID, page utils.PageQuery, options favourite.FavouriteListOptions) ([]favourite.FavouriteWithAsset, *utils.Pagination, error) {
+					return nil, nil, errors.New("fail")
+				},
+			},
+			Assets: builtInAssets(),
+		})
+		req := httptest.NewRequest(http.MethodGet, "/favourites", nil)
+		req = req.WithContext(injectJWT(req.Context(), validUUID.String()))
+		w := httptest.NewRecorder()
+
+		// Act
+		handler(w, req)
+
+		// Assert
+		assert.Equal(t, http.StatusInternalServerError, w.Result().StatusCode)
+	})
+
+	t.Run("Should return 500 when JWT is missing", func(t *testing.T) {
+		// Arrange
+		handler := favourite.GetFavouritesHandler(favourite.GetFavouritesHandlerDependencies{
+			FavouriteService: &StubFavouriteService{},
+			Assets:           builtInAssets(),
+		})
+		req := httptest.NewRequest(http.MethodGet, "/favourites", nil)
+		w := httptest.NewRecorder()
+
+		// Act
+		handler(w, req)
+
+		// Assert
+		assert.Equal(t, http.StatusInternalServerError, w.Result().StatusCode)
+	})
+}
+
+func TestGetFavouriteTagsHandler(t *testing.T) {
+	t.Run("Should return 200 with the tags of the user", func(t *testing.T) {
+		// Arrange
+		validUUID := uuid.New()
+		stubService := &StubFavouriteService{
+			GetTagsForUserFunc: func(userId uuid.UUID, prefix string, limit int) ([]favourite.TagCount, error) {
+				assert.Equal(t, validUUID, userId)
+				assert.Equal(t, "q", prefix)
+				assert.Equal(t, 5, limit)
+				return []favourite.TagCount{{Tag: "q2", Count: 2}}, nil
+			},
+		}
+		handler := favourite.GetFavouriteTagsHandler(favourite.GetFavouriteTagsHandlerDependencies{
+			FavouriteService: stubService,
+		})
+		req := httptest.NewRequest(http.MethodGet, "/favourites/tags?prefix=q&limit=5", nil)
+		req = req.WithContext(injectJWT(req.Context(), validUUID.String()))
+		w := httptest.NewRecorder()
+
+		// Act
+		handler(w, req)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+		assert.JSONEq(t, `{"data":[{"tag":"q2","count":2}]}`, w.Body.String())
+	})
+
+	t.Run("Should return 400 when limit query is invalid", func(t *testing.T) {
+		// Arrange
+		validUUID := uuid.New()
+		handler := favourite.GetFavouriteTagsHandler(favourite.GetFavouriteTagsHandlerDependencies{
+			FavouriteService: &StubFavouriteService{},
+		})
+		req := httptest.NewRequest(http.MethodGet, "/favourites/tags?limit=1000", nil)
+		req = req.WithContext(injectJWT(req.Context(), validUUID.String()))
+		w := httptest.NewRecorder()
+
+		// Act
+		handler(w, req)
+
+		// Assert
+		assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
+	})
+
+	t.Run("Should return 500 when service returns error", func(t *testing.T) {
+		// Arrange
+		validUUID := uuid.New()
+		stubService := &StubFavouriteService{
+			GetTagsForUserFunc: func(userId uuid.UUID, prefix string, limit int) ([]favourite.TagCount, error) {
+				return nil, errors.New("db error")
+			},
+		}
+		handler := favourite.GetFavouriteTagsHandler(favourite.GetFavouriteTagsHandlerDependencies{
+			FavouriteService: stubService,
+		})
+		req := httptest.NewRequest(http.MethodGet, "/favourites/tags", nil)
+		req = req.WithContext(injectJWT(req.Context(), validUUID.String()))
+		w := httptest.NewRecorder()
+
+		// Act
+		handler(w, req)
+
+		// Assert
+		assert.Equal(t, http.StatusInternalServerError, w.Result().StatusCode)
+	})
+}
+
+func TestGetFavouriteHandler(t *testing.T) {
+	newRequest := func(favouriteId string, userId uuid.UUID) *http.Request {
+		req := httptest.NewRequest(http.MethodGet, "/favourites/"+favouriteId, nil)
+		routeCtx := chi.NewRouteContext()
+		routeCtx.URLParams.Add("id", favouriteId)
+		ctx := context.WithValue(req.Context(), chi.RouteCtxKey, routeCtx)
+		return req.WithContext(injectJWT(ctx, userId.String()))
+	}
+
+	t.Run("Should return 200 with the favourite, its asset and its ETag", func(t *testing.T) {
+		// Arrange
+		userId := uuid.New()
+		favouriteId := uuid.New()
+		chartAsset := chart.Chart{Id: uuid.New(), Title: "Sales"}
+		handler := favourite.GetFavouriteHandler(favourite.GetFavouriteHandlerDependencies{
+			FavouriteService: &StubFavouriteService{
+				GetForUserFunc: func(uId, fId uuid.UUID) (*favourite.FavouriteDetails, error) {
+					assert.Equal(t, userId, uId)
+					assert.Equal(t, favouriteId, fId)
+					return &favourite.FavouriteDetails{
+						Favourite: favourite.Favourite{Id: favouriteId, AssetId: chartAsset.Id, AssetType: favourite.AssetTypeChart, Version: 3},
+						Asset:     chartAsset,
+					}, nil
+				},
+			},
+		})
+		w := httptest.NewRecorder()
+
+		// Act
+		handler(w, newRequest(favouriteId.String(), userId))
+
+		// Assert
+		assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+		assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+		var body struct {
+			Data struct {
+				Id        uuid.UUID      `json:"id"`
+				AssetType string         `json:"asset_type"`
+				Asset     map[string]any `json:"asset"`
+			} `json:"data"`
+		}
+		assert.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+		assert.Equal(t, favouriteId, body.Data.Id)
+		assert.Equal(t, "chart", body.Data.AssetType)
+		assert.Equal(t, "Sales", body.Data.Asset["title"])
+	})
+
+	t.Run("Should return 400 when favourite Id param is not uuid", func(t *testing.T) {
+		// Arrange
+		handler := favourite.GetFavouriteHandler(favourite.GetFavouriteHandlerDependencies{
+			FavouriteService: &StubFavouriteService{},
+		})
+		w := httptest.NewRecorder()
+
+		// Act
+		handler(w, newRequest("not-a-uuid", uuid.New()))
+
+		// Assert
+		assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
+	})
+
+	for _, tc := range []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{"Should return 404 when favourite is not found", favourite.ErrFavouriteNotFound, http.StatusNotFound},
+		{"Should return 404 when the asset of the favourite is gone", favourite.ErrAssetNotFound, http.StatusNotFound},
+		{"Should return 401 when favourite is not under user", favourite.ErrFavouriteNotUnderGivenUser, http.StatusUnauthorized},
+		{"Should return 500 on unexpected service error", errors.New("fail"), http.StatusInternalServerError},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			handler := favourite.GetFavouriteHandler(favourite.GetFavouriteHandlerDependencies{
+				FavouriteService: &StubFavouriteService{
+					GetForUserFunc: func(uId, fId uuid.UUID) (*favourite.FavouriteDetails, error) {
+						return nil, tc.err
+					},
+				},
+			})
+			w := httptest.NewRecorder()
+
+			// Act
+			handler(w, newRequest(uuid.NewString(), uuid.New()))
+
+			// Assert
+			assert.Equal(t, tc.status, w.Result().StatusCode)
+		})
+	}
+}
+
+func TestCreateFavouriteHandler(t *testing.T) {
+	t.Run("Should return 201 when favourite is created successfully", func(t *testing.T) {
+		// Arrange
+		userId := uuid.New()
+		assetId := uuid.New()
+
+		requestBody := map[string]interface{}{
+			"assetId":     assetId.String(),
+			"description": "test",
+		}
+		expected := &favourite.Favourite{
+			Id:          uuid.New(),
+			UserId:      userId,
+			AssetId:     assetId,
+			AssetType:   "chart",
+			Description: "test",
+			Version:     1,
+		}
+		stubService := &StubFavouriteService{
+			CreateForUserFunc: func(uId, aId uuid.UUID, desc string, tags []string) (*favourite.Favourite, error) {
+				assert.Equal(t, userId, uId)
+				assert.Equal(t, assetId, aId)
+				assert.Equal(t, "test", desc)
+				return expected, nil
+			},
+		}
+		handler := favourite.CreateFavouriteHandler(favourite.CreateFavouriteHandlerDependencies{
+			FavouriteService: stubService,
+		})
+
+		bodyBytes, _ := json.Marshal(requestBody)
+		req := httptest.NewRequest(http.MethodPost, "/favourites", bytes.NewReader(bodyBytes))
+		req = req.WithContext(injectJWT(req.Context(), userId.String()))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		// Act
+		handler(w, req)
+
+		// Assert
+		assert.Equal(t, http.StatusCreated, w.Result().StatusCode)
+		assert.Equal(t, `"1"`, w.Result().Header.Get("ETag"))
+	})
+
+	t.Run("Should return 404 when asset is not found", func(t *testing.T) {
+		// Arrange
+		userId := uuid.New()
+		assetId := uuid.New()
+
+		requestBody := map[string]interface{}{
+			"assetId":     assetId.String(),
+			"description": "test",
+		}
+		stubService := &StubFavouriteService{
+			CreateForUserFunc: func(_, _ uuid.UUID, _ string, _ []string) (*favourite.Favourite, error) {
+				return nil, favourite.ErrAssetNotFound
+			},
+		}
+		handler := favourite.CreateFavouriteHandler(favourite.CreateFavouriteHandlerDependencies{
+			FavouriteService: stubService,
+		})
+
+		bodyBytes, _ := json.Marshal(requestBody)
+		req := httptest.NewRequest(http.MethodPost, "/favourites", bytes.NewReader(bodyBytes))
+		req = req.WithContext(injectJWT(req.Context(), userId.String()))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		// Act
+		handler(w, req)
+
+		// Assert
+		assert.Equal(t, http.StatusNotFound, w.Result().StatusCode)
+	})
+
+	t.Run("Should return 400 when a tag is invalid", func(t *testing.T) {
+		// Arrange
+		userId := uuid.New()
+		requestBody := map[string]interface{}{
+			"assetId":     uuid.New().String(),
+			"description": "test",
+			"tags":        []string{"a,b"},
+		}
+		stubService := &StubFavouriteService{
+			CreateForUserFunc: func(_, _ uuid.UUID, _ string, tags []string) (*favourite.Favourite, error) {
+				assert.Equal(t, []string{"a,b"}, tags)
+				return nil, favourite.ErrInvalidTag
+			},
+		}
+		handler := favourite.CreateFavouriteHandler(favourite.CreateFavouriteHandlerDependencies{
+			FavouriteService: stubService,
+		})
+
+		bodyBytes, _ := json.Marshal(requestBody)
+		req := httptest.NewRequest(http.MethodPost, "/favourites", bytes.NewReader(bodyBytes))
+		req = req.WithContext(injectJWT(req.Context(), userId.String()))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		// Act
+		handler(w, req)
+
+		// Assert
+		assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
+		assert.Contains(t, w.Body.String(), favourite.ErrInvalidTag.Error())
+	})
+
+	t.Run("Should return 409 with the existing favourite when asset is already a favourite", func(t *testing.T) {
+		// Arrange
+		userId := uuid.New()
+		assetId := uuid.New()
+
+		requestBody := map[string]interface{}{
+			"assetId":     assetId.String(),
+			"description": "test",
+		}
+		existing := &favourite.Favourite{
+			Id:          uuid.New(),
+			UserId:      userId,
+			AssetId:     assetId,
+			AssetType:   "chart",
+			Description: "first",
+			Version:     2,
+		}
+		stubService := &StubFavouriteService{
+			CreateForUserFunc: func(_, _ uuid.UUID, _ string, _ []string) (*favourite.Favourite, error) {
+				return existing, favourite.ErrFavouriteAlreadyExists
+			},
+		}
+		handler := favourite.CreateFavouriteHandler(favourite.CreateFavouriteHandlerDependencies{
+			FavouriteService: stubService,
+		})
+
+		bodyBytes, _ := json.Marshal(requestBody)
+		req := httptest.NewRequest(http.MethodPost, "/favourites", bytes.NewReader(bodyBytes))
+		req = req.WithContext(injectJWT(req.Context(), userId.String()))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		// Act
+		handler(w, req)
+
+		// Assert
+		assert.Equal(t, http.StatusConflict, w.Result().StatusCode)
+
+		var body utils.ErrorWithDataResponse[favourite.Favourite]
+		err := json.NewDecoder(w.Body).Decode(&body)
+		assert.NoError(t, err)
+		assert.Equal(t, *existing, body.Data)
+		assert.NotEmpty(t, body.Error)
+		assert.Equal(t, `"2"`, w.Result().Header.Get("ETag"))
+	})
+
+	t.Run("Should return 400 if body is invalid JSON", func(t *testing.T) {
+		// Arrange
+		userId := uuid.New()
+
+		handler := favourite.CreateFavouriteHandler(favourite.CreateFavouriteHandlerDependencies{
+			FavouriteService: &StubFavouriteService{},
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/favourites", strings.NewReader("{invalid json}"))
+		req = req.WithContext(injectJWT(req.Context(), userId.String()))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		// Act
+		handler(w, req)
+
+		// Assert
+		assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
+	})
+
+	t.Run("Should return 400 if required fields are missing", func(t *testing.T) {
+		// Arrange
+		userId := uuid.New()
+		requestBody := map[string]interface{}{}
+
+		bodyBytes, _ := json.Marshal(requestBody)
+
+		handler := favourite.CreateFavouriteHandler(favourite.CreateFavouriteHandlerDependencies{
+			FavouriteService: &StubFavouriteService{},
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/favourites", bytes.NewReader(bodyBytes))
+		req = req.WithContext(injectJWT(req.Context(), userId.String()))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		// Act
+		handler(w, req)
+
+		// Assert
+		assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
+	})
+
+	t.Run("Should return 500 if JWT is missing", func(t *testing.T) {
+		// Arrange
+		assetId := uuid.New()
+		requestBody := map[string]interface{}{
+			"assetId":     assetId.String(),
+			"description": "testaki",
+		}
+
+		bodyBytes, _ := json.Marshal(requestBody)
+
+		handler := favourite.CreateFavouriteHandler(favourite.CreateFavouriteHandlerDependencies{
+			FavouriteService: &StubFavouriteService{},
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/favourites", bytes.NewReader(bodyBytes))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		// Act
+		handler(w, req)
+
+		// Assert
+		assert.Equal(t, http.StatusInternalServerError, w.Result().StatusCode)
+	})
+}
+
+func TestUpdateFavouriteHandler(t *testing.T) {
+	t.Run("Should return 200 when update is successful", func(t *testing.T) {
+		// Arrange
+		userId := uuid.New()
+		favouriteId := uuid.New()
+
+		requestBody := map[string]interface{}{
+			"description": "updated description",
+		}
+
+		expected := &favourite.Favourite{
+			Id:          favouriteId,
+			UserId:      userId,
+			AssetId:     uuid.New(),
+			AssetType:   "chart",
+			Description: "test",
+			Version:     4,
+		}
+		stubService := &StubFavouriteService{
+			UpdateFunc: func(uId, fId uuid.UUID, changes favourite.FavouriteChanges, expectedVersion *int) (*favourite.Favourite, error) {
+				assert.Equal(t, userId, uId)
+				assert.Equal(t, favouriteId, fId)
+				assert.Equal(t, "updated description", *changes.Description)
+				assert.Equal(t, 3, *expectedVersion)
+				return expected, nil
+			},
+		}
+		handler := favourite.UpdateFavouriteHandler(favourite.UpdateFavouriteHandlerDependencies{
+			FavouriteService: stubService,
+		})
+
+		bodyBytes, _ := json.Marshal(requestBody)
+
+		ctx := chi.NewRouteContext()
+		ctx.URLParams.Add("id", favouriteId.String())
+
+		req := httptest.NewRequest(http.MethodPatch, "/favourites", bytes.NewReader(bodyBytes))
+		req = req.WithContext(context.WithValue(injectJWT(req.Context(), userId.String()), chi.RouteCtxKey, ctx))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("If-Match", `"3"`)
+		w := httptest.NewRecorder()
+
+		// Act
+		handler(w, req)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+		assert.Equal(t, `"4"`, w.Result().Header.Get("ETag"))
+	})
+
+	t.Run("Should clear the fields a merge patch sets to null and leave out the others", func(t *testing.T) {
+		// Arrange
+		userId := uuid.New()
+		favouriteId := uuid.New()
+		stubService := &StubFavouriteService{
+			UpdateFunc: func(_, _ uuid.UUID, changes favourite.FavouriteChanges, _ *int) (*favourite.Favourite, error) {
+				assert.Equal(t, favourite.FavouriteChanges{Description: ptr(""), Tags: []string{}}, changes)
+				return &favourite.Favourite{Id: favouriteId}, nil
+			},
+		}
+		handler := favourite.UpdateFavouriteHandler(favourite.UpdateFavouriteHandlerDependencies{
+			FavouriteService: stubService,
+		})
+
+		ctx := chi.NewRouteContext()
+		ctx.URLParams.Add("id", favouriteId.String())
+
+		req := httptest.NewRequest(http.MethodPatch, "/favourites", strings.NewReader(`{"description":null,"tags":null}`))
+		req = req.WithContext(context.WithValue(injectJWT(req.Context(), userId.String()), chi.RouteCtxKey, ctx))
+		req.Header.Set("Content-Type", utils.MergePatchContentType)
+		w := httptest.NewRecorder()
+
+		// Act
+		handler(w, req)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+	})
+
+	t.Run("Should apply a JSON Patch of whole fields", func(t *testing.T) {
+		// Arrange
+		userId := uuid.New()
+		favouriteId := uuid.New()
+		stubService := &StubFavouriteService{
+			UpdateFunc: func(_, _ uuid.UUID, changes favourite.FavouriteChanges, _ *int) (*favourite.Favourite, error) {
+				assert.Equal(t, favourite.FavouriteChanges{Description: ptr("new"), Pinned: ptr(false)}, changes)
+				return &favourite.Favourite{Id: favouriteId}, nil
+			},
+		}
+		handler := favourite.UpdateFavouriteHandler(favourite.UpdateFavouriteHandlerDependencies{
+			FavouriteService: stubService,
+		})
+
+		ctx := chi.NewRouteContext()
+		ctx.URLParams.Add("id", favouriteId.String())
+
+		body := `[{"op":"replace","path":"/description","value":"new"},{"op":"remove","path":"/pinned"}]`
+		req := httptest.NewRequest(http.MethodPatch, "/favourites", strings.NewReader(body))
+		req = req.WithContext(context.WithValue(injectJWT(req.Context(), userId.String()), chi.RouteCtxKey, ctx))
+		req.Header.Set("Content-Type", utils.JSONPatchContentType)
+		w := httptest.NewRecorder()
+
+		// Act
+		handler(w, req)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+	})
+
+	t.Run("Should return 400 for unknown fields", func(t *testing.T) {
+		// Arrange
+		userId := uuid.New()
+		favouriteId := uuid.New()
+		handler := favourite.UpdateFavouriteHandler(favourite.UpdateFavouriteHandlerDependencies{
+			FavouriteService: &StubFavouriteService{},
+		})
+
+		ctx := chi.NewRouteContext()
+		ctx.URLParams.Add("id", favouriteId.String())
+
+		req := httptest.NewRequest(http.MethodPatch, "/favourites", strings.NewReader(`{"asset_id":"x"}`))
+		req = req.WithContext(context.WithValue(injectJWT(req.Context(), userId.String()), chi.RouteCtxKey, ctx))
+		w := httptest.NewRecorder()
+
+		// Act
+		handler(w, req)
+
+		// Assert
+		assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
+		assert.JSONEq(t, `{"error":"Unknown field \"asset_id\""}`, w.Body.String())
+	})
+
+	t.Run("Should return 412 when favourite was changed since the If-Match version", func(t *testing.T) {
+		// Arrange
+		userId := uuid.New()
+		favouriteId := uuid.New()
+		stubService := &StubFavouriteService{
+			UpdateFunc: func(_, _ uuid.UUID, _ favourite.FavouriteChanges, expectedVersion *int) (*favourite.Favourite, error) {
+				return nil, favourite.ErrFavouriteVersionMismatch
+			},
+		}
+		handler := favourite.UpdateFavouriteHandler(favourite.UpdateFavouriteHandlerDependencies{
+			FavouriteService: stubService,
+		})
+
+		ctx := chi.NewRouteContext()
+		ctx.URLParams.Add("id", favouriteId.String())
+
+		req := httptest.NewRequest(http.MethodPatch, "/favourites", strings.NewReader(`{"description":"test"}`))
+		req = req.WithContext(context.WithValue(injectJWT(req.Context(), userId.String()), chi.RouteCtxKey, ctx))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("If-Match", `"1"`)
+		w := httptest.NewRecorder()
+
+		// Act
+		handler(w, req)
+
+		// Assert
+		assert.Equal(t, http.StatusPreconditionFailed, w.Result().StatusCode)
+	})
+
+	t.Run("Should return 400 when If-Match is not a strong ETag", func(t *testing.T) {
+		// Arrange
+		userId := uuid.New()
+		favouriteId := uuid.New()
+		handler := favourite.UpdateFavouriteHandler(favourite.UpdateFavouriteHandlerDependencies{
+			FavouriteService: &StubFavouriteService{},
+		})
+
+		ctx := chi.NewRouteContext()
+		ctx.URLParams.Add("id", favouriteId.String())
+
+		req := httptest.NewRequest(http.MethodPatch, "/favourites", strings.NewReader(`{"description":"test"}`))
+		req = req.WithContext(context.WithValue(injectJWT(req.Context(), userId.String()), chi.RouteCtxKey, ctx))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("If-Match", `W/"1"`)
+		w := httptest.NewRecorder()
+
+		// Act
+		handler(w, req)
+
+		// Assert
+		assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
+	})
+
+	t.Run("Should return 404 when favourite is not found", func(t *testing.T) {
+		// Arrange
+		userId := uuid.New()
+		favouriteId := uuid.New()
+
+		requestBody := map[string]interface{}{
+			"description": "does not matter",
+		}
+
+		stubService := &StubFavouriteService{
+			UpdateFunc: func(_, _ uuid.UUID, _ favourite.FavouriteChanges, expectedVersion *int) (*favourite.Favourite, error) {
+				return nil, favourite.ErrFavouriteNotFound
+			},
+		}
+		handler := favourite.UpdateFavouriteHandler(favourite.UpdateFavouriteHandlerDependencies{
+			FavouriteService: stubService,
+		})
+
+		bodyBytes, _ := json.Marshal(requestBody)
+
+		ctx := chi.NewRouteContext()
+		ctx.URLParams.Add("id", favouriteId.String())
+
+		req := httptest.NewRequest(http.MethodPatch, "/favourites", bytes.NewReader(bodyBytes))
+		req = req.WithContext(context.WithValue(injectJWT(req.Context(), userId.String()), chi.RouteCtxKey, ctx))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		// Act
+		handler(w, req)
+
+		// Assert
+		assert.Equal(t, http.StatusNotFound, w.Result().StatusCode)
+	})
+
+	t.Run("Should return 401 when favourite is not under user", func(t *testing.T) {
+		// Arrange
+		userId := uuid.New()
+		favouriteId := uuid.New()
+		requestBody := map[string]interface{}{
+			"description": "test",
+		}
+		stubService := &StubFavouriteService{
+			UpdateFunc: func(_, _ uuid.UUID, _ favourite.FavouriteChanges, expectedVersion *int) (*favourite.Favourite, error) {
+				return nil, favourite.ErrFavouriteNotUnderGivenUser
+			},
+		}
+		handler := favourite.UpdateFavouriteHandler(favourite.UpdateFavouriteHandlerDependencies{
+			FavouriteService: stubService,
+		})
+
+		bodyBytes, _ := json.Marshal(requestBody)
+
+		ctx := chi.NewRouteContext()
+		ctx.URLParams.Add("id", favouriteId.String())
+
+		req := httptest.NewRequest(http.MethodPatch, "/favourites", bytes.NewReader(bodyBytes))
+		req = req.WithContext(context.WithValue(injectJWT(req.Context(), userId.String()), chi.RouteCtxKey, ctx))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		// Act
+		handler(w, req)
+
+		// Assert
+		assert.Equal(t, http.StatusUnauthorized, w.Result().StatusCode)
+	})
+
+	t.Run("Should return 500 when JWT is missing", func(t *testing.T) {
+		// Arrange
+		favouriteId := uuid.New()
+		requestBody := map[string]interface{}{
+			"description": "test",
+		}
+		handler := favourite.UpdateFavouriteHandler(favourite.UpdateFavouriteHandlerDependencies{
+			FavouriteService: &StubFavouriteService{},
+		})
+
+		bodyBytes, _ := json.Marshal(requestBody)
+
+		ctx := chi.NewRouteContext()
+		ctx.URLParams.Add("id", favouriteId.String())
+
+		req := httptest.NewRequest(http.MethodPatch, "/favourites", bytes.NewReader(bodyBytes))
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, ctx))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		// Act
+		handler(w, req)
+
+		// Assert
+		assert.Equal(t, http.StatusInternalServerError, w.Result().StatusCode)
+	})
+
+	t.Run("Should return 500 on unexpected service error", func(t *testing.T) {
+		// Arrange
+		userId := uuid.New()
+		requestBody := map[string]interface{}{
+			"description": "test",
+		}
+		stubService := &StubFavouriteService{
+			UpdateFunc: func(_, _ uuid.UUID, _ favourite.FavouriteChanges, expectedVersion *int) (*favourite.Favourite, error) {
+				return nil, errors.New("random error")
+			},
+		}
+
+		bodyBytes, _ := json.Marshal(requestBody)
+
+		ctx := chi.NewRouteContext()
+		ctx.URLParams.Add("id", uuid.New().String())
+
+		req := httptest.NewRequest(http.MethodPatch, "/favourites", bytes.NewReader(bodyBytes))
+		req = req.WithContext(context.WithValue(injectJWT(req.Context(), userId.String()), chi.RouteCtxKey, ctx))
+		req.Header.Set("Content-Type", "application/json")
+
+		w := httptest.NewRecorder()
+
+		handler := favourite.UpdateFavouriteHandler(favourite.UpdateFavouriteHandlerDependencies{
+			FavouriteService: stubService,
+		})
+
+		// Act
+		handler(w, req)
+
+		// Assert
+		assert.Equal(t, http.StatusInternalServerError, w.Result().StatusCode)
+	})
+}
+
+func TestDeleteFavouriteHandler(t *testing.T) {
+	t.Run("Should return 200 when delete is successful", func(t *testing.T) {
+		// Arrange
+		userId := uuid.New()
+		favouriteId := uuid.New()
+
+		stubService := &StubFavouriteService{
+			DeleteFunc: func(uId, fId uuid.UUID, expectedVersion *int) error {
+				assert.Equal(t, userId, uId)
+				assert.Equal(t, favouriteId, fId)
+				assert.Nil(t, expectedVersion)
+
+				return nil
+			},
+		}
+		handler := favourite.DeleteFavouriteHandler(
+			favourite.DeleteFavouriteHandlerDependencies{
+				FavouriteService: stubService,
+			},
+		)
+
+		req := httptest.NewRequest(http.MethodDelete, "/favourites", nil)
+		ctx := chi.NewRouteContext()
+		ctx.URLParams.Add("id", favouriteId.String())
+		req = req.WithContext(context.WithValue(injectJWT(req.Context(), userId.String()), chi.RouteCtxKey, ctx))
+		w := httptest.NewRecorder()
+
+		// Act
+		handler(w, req)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+	})
+
+	t.Run("Should return 412 when favourite was changed since the If-Match version", func(t *testing.T) {
+		// Arrange
+		userId := uuid.New()
+		favouriteId := uuid.New()
+
+		stubService := &StubFavouriteService{
+			DeleteFunc: func(_, _ uuid.UUID, expectedVersion *int) error {
+				assert.Equal(t, 2, *expectedVersion)
+				return favourite.ErrFavouriteVersionMismatch
+			},
+		}
+		handler := favourite.DeleteFavouriteHandler(
+			favourite.DeleteFavouriteHandlerDependencies{
+				FavouriteService: stubService,
+			},
+		)
+
+		req := httptest.NewRequest(http.MethodDelete, "/favourites", nil)
+		ctx := chi.NewRouteContext()
+		ctx.URLParams.Add("id", favouriteId.String())
+		req = req.WithContext(context.WithValue(injectJWT(req.Context(), userId.String()), chi.RouteCtxKey, ctx))
+		req.Header.Set("If-Match", `"2"`)
+		w := httptest.NewRecorder()
+
+		// Act
+		handler(w, req)
+
+		// Assert
+		assert.Equal(t, http.StatusPreconditionFailed, w.Result().StatusCode)
+	})
+
+	t.Run("Should return 400 when favourite Id param is not uuid", func(t *testing.T) {
+		// Arrange
+		stubService := &StubFavouriteService{
+			DeleteFunc: nil,
+		}
+		handler := favourite.DeleteFavouriteHandler(
+			favourite.DeleteFavouriteHandlerDependencies{
+				FavouriteService: stubService,
+			},
+		)
+
+		req := httptest.NewRequest(http.MethodDelete, "/favourites", nil)
+		ctx := chi.NewRouteContext()
+		ctx.URLParams.Add("id", "not-uuid")
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, ctx))
+		w := httptest.NewRecorder()
+
+		// Act
+		handler(w, req)
+
+		// Assert
+		assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
+	})
+
+	t.Run("Should return 404 when favourite is not found", func(t *testing.T) {
+		// Arrange
+		userId := uuid.New()
+
+		stubService := &StubFavouriteService{
+			DeleteFunc: func(_, _ uuid.UUID, expectedVersion *int) error {
+				return favourite.ErrFavouriteNotFound
+			},
+		}
+		handler := favourite.DeleteFavouriteHandler(favourite.DeleteFavouriteHandlerDependencies{
+			FavouriteService: stubService,
+		})
+
+		req := httptest.NewRequest(http.MethodDelete, "/favourites", nil)
+		ctx := chi.NewRouteContext()
+		ctx.URLParams.Add("id", uuid.New().String())
+		req = req.WithContext(context.WithValue(injectJWT(req.Context(), userId.String()), chi.RouteCtxKey, ctx))
+		w := httptest.NewRecorder()
+
+		// Act
+		handler(w, req)
+
+		// Assert
+		assert.Equal(t, http.StatusNotFound, w.Result().StatusCode)
 	})
 
 	t.Run("Should return 401 when favourite is not under user", func(t *testing.T) {
 		// Arrange
-		userId := uuid.New()
-		favouriteId := uuid.New()
-		requestBody := map[string]interface{}{
-			"description": "test",
+		userId := uuid.New()
+
+		stubService := &StubFavouriteService{
+			DeleteFunc: func(_, _ uuid.UUID, expectedVersion *int) error {
+				return favourite.ErrFavouriteNotUnderGivenUser
+			},
+		}
+
+		handler := favourite.DeleteFavouriteHandler(favourite.DeleteFavouriteHandlerDependencies{FavouriteService: stubService})
+
+		req := httptest.NewRequest(http.MethodDelete, "/favourites", nil)
+		ctx := chi.NewRouteContext()
+		ctx.URLParams.Add("id", uuid.New().String())
+		req = req.WithContext(context.WithValue(injectJWT(req.Context(), userId.String()), chi.RouteCtxKey, ctx))
+		w := httptest.NewRecorder()
+
+		// Act
+		handler(w, req)
+
+		// Assert
+		assert.Equal(t, http.StatusUnauthorized, w.Result().StatusCode)
+	})
+
+	t.Run("Should return 500 when JWT is missing", func(t *testing.T) {
+		// Arrange
+		handler := favourite.DeleteFavouriteHandler(favourite.DeleteFavouriteHandlerDependencies{
+			FavouriteService: &StubFavouriteService{},
+		})
+
+		req := httptest.NewRequest(http.MethodDelete, "/favourites", nil)
+		ctx := chi.NewRouteContext()
+		ctx.URLParams.Add("id", uuid.New().String())
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, ctx))
+		w := httptest.NewRecorder()
+
+		// Act
+		handler(w, req)
+
+		// Assert
+		assert.Equal(t, http.StatusInternalServerError, w.Result().StatusCode)
+	})
+
+	t.Run("Should return 400 on unexpected service error", func(t *testing.T) {
+		// Arrange
+		userId := uuid.New()
+		stubService := &StubFavouriteService{
+			DeleteFunc: func(_, _ uuid.UUID, expectedVersion *int) error {
+				return errors.New("random error")
+			},
+		}
+
+		handler := favourite.DeleteFavouriteHandler(favourite.DeleteFavouriteHandlerDependencies{FavouriteService: stubService})
+		req := httptest.NewRequest(http.MethodDelete, "/favourites", strings.NewReader(`{}`))
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(injectJWT(req.Context(), userId.String()))
+		w := httptest.NewRecorder()
+
+		// Act
+		handler(w, req)
+
+		// Assert
+		assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
+	})
+}
+
+func TestMoveFavouriteHandler(t *testing.T) {
+	newRequest := func(userId uuid.UUID, favouriteId uuid.UUID, body string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/favourites/move", strings.NewReader(body))
+		ctx := chi.NewRouteContext()
+		ctx.URLParams.Add("id", favouriteId.String())
+		req = req.WithContext(context.WithValue(injectJWT(req.Context(), userId.String()), chi.RouteCtxKey, ctx))
+		req.Header.Set("Content-Type", "application/json")
+		return req
+	}
+
+	t.Run("Should return 200 and the moved favourite", func(t *testing.T) {
+		// Arrange
+		userId := uuid.New()
+		favouriteId := uuid.New()
+		targetId := uuid.New()
+		expected := &favourite.Favourite{Id: favouriteId, UserId: userId, Rank: "W", Version: 3}
+		stubService := &StubFavouriteService{
+			MoveFunc: func(uId, fId, tId uuid.UUID, after bool, expectedVersion *int) (*favourite.Favourite, error) {
+				assert.Equal(t, userId, uId)
+				assert.Equal(t, favouriteId, fId)
+				assert.Equal(t, targetId, tId)
+				assert.True(t, after)
+				assert.Equal(t, 2, *expectedVersion)
+				return expected, nil
+			},
+		}
+		handler := favourite.MoveFavouriteHandler(favourite.MoveFavouriteHandlerDependencies{
+			FavouriteService: stubService,
+		})
+
+		req := newRequest(userId, favouriteId, `{"after":"`+targetId.String()+`"}`)
+		req.Header.Set("If-Match", `"2"`)
+		w := httptest.NewRecorder()
+
+		// Act
+		handler(w, req)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+		assert.Equal(t, `"3"`, w.Result().Header.Get("ETag"))
+	})
+
+	t.Run("Should return 400 unless exactly one of before and after is given", func(t *testing.T) {
+		// Arrange
+		handler := favourite.MoveFavouriteHandler(favourite.MoveFavouriteHandlerDependencies{
+			FavouriteService: &StubFavouriteService{},
+		})
+		targetId := uuid.NewString()
+
+		for _, body := range []string{`{}`, `{"before":"` + targetId + `","after":"` + targetId + `"}`} {
+			w := httptest.NewRecorder()
+
+			// Act
+			handler(w, newRequest(uuid.New(), uuid.New(), body))
+
+			// Assert
+			assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode, body)
 		}
+	})
+
+	t.Run("Should return 404 when the target is not a favourite of the user", func(t *testing.T) {
+		// Arrange
 		stubService := &StubFavouriteService{
-			UpdateFunc: func(_, _ uuid.UUID, _ string) (*favourite.Favourite, error) {
-				return nil, favourite.ErrFavouriteNotUnderGivenUser
+			MoveFunc: func(_, _, _ uuid.UUID, after bool, _ *int) (*favourite.Favourite, error) {
+				assert.False(t, after)
+				return nil, favourite.ErrMoveTargetNotFound
 			},
 		}
-		handler := favourite.UpdateFavouriteHandler(favourite.UpdateFavouriteHandlerDependencies{
+		handler := favourite.MoveFavouriteHandler(favourite.MoveFavouriteHandlerDependencies{
 			FavouriteService: stubService,
 		})
+		w := httptest.NewRecorder()
 
-		bodyBytes, _ := json.Marshal(requestBody)
+		// Act
+		handler(w, newRequest(uuid.New(), uuid.New(), `{"before":"`+uuid.NewString()+`"}`))
 
-		ctx := chi.NewRouteContext()
-		ctx.URLParams.Add("id", favouriteId.String())
+		// Assert
+		assert.Equal(t, http.StatusNotFound, w.Result().StatusCode)
+	})
 
-		req := httptest.NewRequest(http.MethodPatch, "/favourites", bytes.NewReader(bodyBytes))
-		req = req.WithContext(context.WithValue(injectJWT(req.Context(), userId.String()), chi.RouteCtxKey, ctx))
-		req.Header.Set("Content-Type", "application/json")
+	t.Run("Should return 412 when favourite was changed since the If-Match version", func(t *testing.T) {
+		// Arrange
+		stubService := &StubFavouriteService{
+			MoveFunc: func(_, _, _ uuid.UUID, _ bool, _ *int) (*favourite.Favourite, error) {
+				return nil, favourite.ErrFavouriteVersionMismatch
+			},
+		}
+		handler := favourite.MoveFavouriteHandler(favourite.MoveFavouriteHandlerDependencies{
+			FavouriteService: stubService,
+		})
+		req := newRequest(uuid.New(), uuid.New(), `{"before":"`+uuid.NewString()+`"}`)
+		req.Header.Set("If-Match", `"1"`)
 		w := httptest.NewRecorder()
 
 		// Act
 		handler(w, req)
 
 		// Assert
-		assert.Equal(t, http.StatusUnauthorized, w.Result().StatusCode)
+		assert.Equal(t, http.StatusPreconditionFailed, w.Result().StatusCode)
 	})
+}
 
-	t.Run("Should return 500 when JWT is missing", func(t *testing.T) {
+func TestGetFavouriteTrashHandler(t *testing.T) {
+	t.Run("Should return 200 with the trash of the user", func(t *testing.T) {
 		// Arrange
-		favouriteId := uuid.New()
-		requestBody := map[string]interface{}{
-			"id":          favouriteId.String(),
-			"description": "test",
+		userId := uuid.New()
+		stubService := &StubFavouriteService{
+			GetTrashForUserFunc: func(uId uuid.UUID, pageSize int, pageNumber int) ([]favourite.TrashedFavourite, *utils.Pagination, error) {
+				assert.Equal(t, userId, uId)
+				assert.Equal(t, 5, pageSize)
+				assert.Equal(t, 1, pageNumber)
+				return []favourite.TrashedFavourite{{Favourite: favourite.Favourite{Id: uuid.New()}}},
+					&utils.Pagination{Page: pageNumber, PageSize: pageSize, MaxPage: 1}, nil
+			},
 		}
-		handler := favourite.UpdateFavouriteHandler(favourite.UpdateFavouriteHandlerDependencies{
-			FavouriteService: &StubFavouriteService{},
+		handler := favourite.GetFavouriteTrashHandler(favourite.GetFavouriteTrashHandlerDependencies{
+			FavouriteService: stubService,
 		})
+		req := httptest.NewRequest(http.MethodGet, "/favourites/trash?pageSize=5&pageNumber=1", nil)
+		req = req.WithContext(injectJWT(req.Context(), userId.String()))
+		w := httptest.NewRecorder()
 
-		bodyBytes, _ := json.Marshal(requestBody)
+		// Act
+		handler(w, req)
 
-		ctx := chi.NewRouteContext()
-		ctx.URLParams.Add("id", favouriteId.String())
+		// Assert
+		assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+	})
 
-		req := httptest.NewRequest(http.MethodPatch, "/favourites", bytes.NewReader(bodyBytes))
-		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, ctx))
-		req.Header.Set("Content-Type", "application/json")
+	t.Run("Should return 500 when service returns error", func(t *testing.T) {
+		// Arrange
+		userId := uuid.New()
+		stubService := &StubFavouriteService{
+			GetTrashForUserFunc: func(uuid.UUID, int, int) ([]favourite.TrashedFavourite, *utils.Pagination, error) {
+				return nil, nil, errors.New("db error")
+			},
+		}
+		handler := favourite.GetFavouriteTrashHandler(favourite.GetFavouriteTrashHandlerDependencies{
+			FavouriteService: stubService,
+		})
+		req := httptest.NewRequest(http.MethodGet, "/favourites/trash", nil)
+		req = req.WithContext(injectJWT(req.Context(), userId.String()))
 		w := httptest.NewRecorder()
 
 		// Act
@@ -440,181 +1527,362 @@ func TestUpdateFavouriteHandler(t *testing.T) {
 		// Assert
 		assert.Equal(t, http.StatusInternalServerError, w.Result().StatusCode)
 	})
+}
 
-	t.Run("Should return 500 on unexpected service error", func(t *testing.T) {
+func TestRestoreFavouriteHandler(t *testing.T) {
+	newRequest := func(userId, favouriteId uuid.UUID) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/favourites/trash/restore", nil)
+		ctx := chi.NewRouteContext()
+		ctx.URLParams.Add("id", favouriteId.String())
+		return req.WithContext(context.WithValue(injectJWT(req.Context(), userId.String()), chi.RouteCtxKey, ctx))
+	}
+
+	t.Run("Should return 200 with the restored favourite and its ETag", func(t *testing.T) {
 		// Arrange
 		userId := uuid.New()
-		requestBody := map[string]interface{}{
-			"description": "test",
-		}
+		favouriteId := uuid.New()
 		stubService := &StubFavouriteService{
-			UpdateFunc: func(_, _ uuid.UUID, _ string) (*favourite.Favourite, error) {
-				return nil, errors.New("random error")
+			RestoreFunc: func(uId, fId uuid.UUID) (*favourite.Favourite, error) {
+				assert.Equal(t, userId, uId)
+				assert.Equal(t, favouriteId, fId)
+				return &favourite.Favourite{Id: fId, UserId: uId, Version: 3}, nil
 			},
 		}
+		handler := favourite.RestoreFavouriteHandler(favourite.RestoreFavouriteHandlerDependencies{
+			FavouriteService: stubService,
+		})
+		w := httptest.NewRecorder()
 
-		bodyBytes, _ := json.Marshal(requestBody)
-
-		ctx := chi.NewRouteContext()
-		ctx.URLParams.Add("id", uuid.New().String())
+		// Act
+		handler(w, newRequest(userId, favouriteId))
 
-		req := httptest.NewRequest(http.MethodPatch, "/favourites", bytes.NewReader(bodyBytes))
-		req = req.WithContext(context.WithValue(injectJWT(req.Context(), userId.String()), chi.RouteCtxKey, ctx))
-		req.Header.Set("Content-Type", "application/json")
+		// Assert
+		assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+		assert.Equal(t, `"3"`, w.Result().Header.Get("ETag"))
+	})
 
+	t.Run("Should return 404 when favourite is not in the trash", func(t *testing.T) {
+		// Arrange
+		stubService := &StubFavouriteService{
+			RestoreFunc: func(uuid.UUID, uuid.UUID) (*favourite.Favourite, error) {
+				return nil, favourite.ErrFavouriteNotFound
+			},
+		}
+		handler := favourite.RestoreFavouriteHandler(favourite.RestoreFavouriteHandlerDependencies{
+			FavouriteService: stubService,
+		})
 		w := httptest.NewRecorder()
 
-		handler := favourite.UpdateFavouriteHandler(favourite.UpdateFavouriteHandlerDependencies{
+		// Act
+		handler(w, newRequest(uuid.New(), uuid.New()))
+
+		// Assert
+		assert.Equal(t, http.StatusNotFound, w.Result().StatusCode)
+	})
+
+	t.Run("Should return 409 with the existing favourite when the asset is a favourite again", func(t *testing.T) {
+		// Arrange
+		existingId := uuid.New()
+		stubService := &StubFavouriteService{
+			RestoreFunc: func(uuid.UUID, uuid.UUID) (*favourite.Favourite, error) {
+				return &favourite.Favourite{Id: existingId}, favourite.ErrFavouriteAlreadyExists
+			},
+		}
+		handler := favourite.RestoreFavouriteHandler(favourite.RestoreFavouriteHandlerDependencies{
 			FavouriteService: stubService,
 		})
+		w := httptest.NewRecorder()
 
 		// Act
-		handler(w, req)
+		handler(w, newRequest(uuid.New(), uuid.New()))
 
 		// Assert
-		assert.Equal(t, http.StatusInternalServerError, w.Result().StatusCode)
+		assert.Equal(t, http.StatusConflict, w.Result().StatusCode)
+		assert.Contains(t, w.Body.String(), existingId.String())
 	})
 }
 
-func TestDeleteFavouriteHandler(t *testing.T) {
-	t.Run("Should return 200 when delete is successful", func(t *testing.T) {
+func TestPurgeFavouriteHandler(t *testing.T) {
+	newRequest := func(userId, favouriteId uuid.UUID) *http.Request {
+		req := httptest.NewRequest(http.MethodDelete, "/favourites/trash", nil)
+		ctx := chi.NewRouteContext()
+		ctx.URLParams.Add("id", favouriteId.String())
+		return req.WithContext(context.WithValue(injectJWT(req.Context(), userId.String()), chi.RouteCtxKey, ctx))
+	}
+
+	t.Run("Should return 200 when purge is successful", func(t *testing.T) {
 		// Arrange
 		userId := uuid.New()
 		favouriteId := uuid.New()
-
 		stubService := &StubFavouriteService{
-			DeleteFunc: func(uId, fId uuid.UUID) error {
+			PurgeFunc: func(uId, fId uuid.UUID) error {
 				assert.Equal(t, userId, uId)
 				assert.Equal(t, favouriteId, fId)
-
 				return nil
 			},
 		}
-		handler := favourite.DeleteFavouriteHandler(
-			favourite.DeleteFavouriteHandlerDependencies{
-				FavouriteService: stubService,
-			},
-		)
-
-		req := httptest.NewRequest(http.MethodDelete, "/favourites", nil)
-		ctx := chi.NewRouteContext()
-		ctx.URLParams.Add("id", favouriteId.String())
-		req = req.WithContext(context.WithValue(injectJWT(req.Context(), userId.String()), chi.RouteCtxKey, ctx))
+		handler := favourite.PurgeFavouriteHandler(favourite.PurgeFavouriteHandlerDependencies{
+			FavouriteService: stubService,
+		})
 		w := httptest.NewRecorder()
 
 		// Act
-		handler(w, req)
+		handler(w, newRequest(userId, favouriteId))
 
 		// Assert
 		assert.Equal(t, http.StatusOK, w.Result().StatusCode)
 	})
 
-	t.Run("Should return 400 when favourite Id param is not uuid", func(t *testing.T) {
+	t.Run("Should return 401 when favourite is not under the user", func(t *testing.T) {
 		// Arrange
 		stubService := &StubFavouriteService{
-			DeleteFunc: nil,
+			PurgeFunc: func(uuid.UUID, uuid.UUID) error {
+				return favourite.ErrFavouriteNotUnderGivenUser
+			},
 		}
-		handler := favourite.DeleteFavouriteHandler(
-			favourite.DeleteFavouriteHandlerDependencies{
-				FavouriteService: stubService,
+		handler := favourite.PurgeFavouriteHandler(favourite.PurgeFavouriteHandlerDependencies{
+			FavouriteService: stubService,
+		})
+		w := httptest.NewRecorder()
+
+		// Act
+		handler(w, newRequest(uuid.New(), uuid.New()))
+
+		// Assert
+		assert.Equal(t, http.StatusUnauthorized, w.Result().StatusCode)
+	})
+}
+
+func TestCreateFavouritesBatchHandler(t *testing.T) {
+	t.Run("Should return 200 with the status of every item", func(t *testing.T) {
+		// Arrange
+		userId := uuid.New()
+		created := &favourite.Favourite{Id: uuid.New(), UserId: userId, AssetId: uuid.New(), Version: 1}
+		existing := &favourite.Favourite{Id: uuid.New(), UserId: userId, AssetId: uuid.New(), Version: 3}
+		missingAssetId := uuid.New()
+
+		requestBody := map[string]interface{}{
+			"items": []map[string]interface{}{
+				{"assetId": created.AssetId.String(), "description": "new"},
+				{"assetId": existing.AssetId.String(), "description": "again"},
+				{"assetId": missingAssetId.String(), "description": "missing"},
 			},
-		)
+		}
+		stubService := &StubFavouriteService{
+			CreateManyForUserFunc: func(uId uuid.UUID, items []favourite.CreateFavouriteRequestBody, atomic bool) ([]favourite.FavouriteWriteResult, error) {
+				assert.Equal(t, userId, uId)
+				assert.Len(t, items, 3)
+				assert.Equal(t, missingAssetId, items[2].AssetId)
+				assert.False(t, atomic)
+				return []favourite.FavouriteWriteResult{
+					{Favourite: created},
+					{Favourite: existing, Err: favourite.ErrFavouriteAlreadyExists},
+					{Err: favourite.ErrAssetNotFound},
+				}, nil
+			},
+		}
+		handler := favourite.CreateFavouritesBatchHandler(favourite.CreateFavouritesBatchHandlerDependencies{
+			FavouriteService: stubService,
+		})
 
-		req := httptest.NewRequest(http.MethodDelete, "/favourites", nil)
-		ctx := chi.NewRouteContext()
-		ctx.URLParams.Add("id", "not-uuid")
-		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, ctx))
+		bodyBytes, _ := json.Marshal(requestBody)
+		req := httptest.NewRequest(http.MethodPost, "/favourites/batch", bytes.NewReader(bodyBytes))
+		req = req.WithContext(injectJWT(req.Context(), userId.String()))
 		w := httptest.NewRecorder()
 
 		// Act
 		handler(w, req)
 
 		// Assert
-		assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
+		assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+
+		var body utils.DataResponse[[]favourite.FavouriteBatchItemResult]
+		err := json.NewDecoder(w.Body).Decode(&body)
+		assert.NoError(t, err)
+		assert.Equal(t, []favourite.FavouriteBatchItemResult{
+			{Status: http.StatusCreated, Data: created},
+			{Status: http.StatusConflict, Data: existing, Error: "Asset is already a favourite"},
+			{Status: http.StatusNotFound, Error: "Could not find Asset with this Id"},
+		}, body.Data)
 	})
 
-	t.Run("Should return 404 when favourite is not found", func(t *testing.T) {
+	t.Run("Should return 422 with the status of every item when an atomic batch is rolled back", func(t *testing.T) {
 		// Arrange
 		userId := uuid.New()
-
+		requestBody := map[string]interface{}{
+			"atomic": true,
+			"items": []map[string]interface{}{
+				{"assetId": uuid.NewString(), "description": "fine"},
+				{"assetId": uuid.NewString(), "description": "missing"},
+			},
+		}
 		stubService := &StubFavouriteService{
-			DeleteFunc: func(_, _ uuid.UUID) error {
-				return favourite.ErrFavouriteNotFound
+			CreateManyForUserFunc: func(_ uuid.UUID, _ []favourite.CreateFavouriteRequestBody, atomic bool) ([]favourite.FavouriteWriteResult, error) {
+				assert.True(t, atomic)
+				return []favourite.FavouriteWriteResult{
+					{Err: favourite.ErrFavouriteBatchRolledBack},
+					{Err: favourite.ErrAssetNotFound},
+				}, nil
 			},
 		}
-		handler := favourite.DeleteFavouriteHandler(favourite.DeleteFavouriteHandlerDependencies{
+		handler := favourite.CreateFavouritesBatchHandler(favourite.CreateFavouritesBatchHandlerDependencies{
 			FavouriteService: stubService,
 		})
 
-		req := httptest.NewRequest(http.MethodDelete, "/favourites", nil)
-		ctx := chi.NewRouteContext()
-		ctx.URLParams.Add("id", uuid.New().String())
-		req = req.WithContext(context.WithValue(injectJWT(req.Context(), userId.String()), chi.RouteCtxKey, ctx))
+		bodyBytes, _ := json.Marshal(requestBody)
+		req := httptest.NewRequest(http.MethodPost, "/favourites/batch", bytes.NewReader(bodyBytes))
+		req = req.WithContext(injectJWT(req.Context(), userId.String()))
 		w := httptest.NewRecorder()
 
 		// Act
 		handler(w, req)
 
 		// Assert
-		assert.Equal(t, http.StatusNotFound, w.Result().StatusCode)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Result().StatusCode)
+
+		var body utils.ErrorWithDataResponse[[]favourite.FavouriteBatchItemResult]
+		err := json.NewDecoder(w.Body).Decode(&body)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, body.Error)
+		if assert.Len(t, body.Data, 2) {
+			assert.Equal(t, http.StatusFailedDependency, body.Data[0].Status)
+			assert.Equal(t, http.StatusNotFound, body.Data[1].Status)
+		}
 	})
 
-	t.Run("Should return 401 when favourite is not under user", func(t *testing.T) {
+	t.Run("Should return 400 when there are no items or too many", func(t *testing.T) {
 		// Arrange
 		userId := uuid.New()
+		tooMany := []map[string]interface{}{}
+		for range 101 {
+			tooMany = append(tooMany, map[string]interface{}{"assetId": uuid.NewString(), "description": "desc"})
+		}
+
+		handler := favourite.CreateFavouritesBatchHandler(favourite.CreateFavouritesBatchHandlerDependencies{
+			FavouriteService: &StubFavouriteService{},
+		})
+
+		for _, items := range [][]map[string]interface{}{{}, tooMany, {{"assetId": uuid.NewString()}}} {
+			bodyBytes, _ := json.Marshal(map[string]interface{}{"items": items})
+			req := httptest.NewRequest(http.MethodPost, "/favourites/batch", bytes.NewReader(bodyBytes))
+			req = req.WithContext(injectJWT(req.Context(), userId.String()))
+			w := httptest.NewRecorder()
+
+			// Act
+			handler(w, req)
+
+			// Assert
+			assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
+		}
+	})
 
+	t.Run("Should return 500 when service returns error", func(t *testing.T) {
+		// Arrange
+		userId := uuid.New()
 		stubService := &StubFavouriteService{
-			DeleteFunc: func(_, _ uuid.UUID) error {
-				return favourite.ErrFavouriteNotUnderGivenUser
+			CreateManyForUserFunc: func(_ uuid.UUID, _ []favourite.CreateFavouriteRequestBody, _ bool) ([]favourite.FavouriteWriteResult, error) {
+				return nil, errors.New("db error")
 			},
 		}
+		handler := favourite.CreateFavouritesBatchHandler(favourite.CreateFavouritesBatchHandlerDependencies{
+			FavouriteService: stubService,
+		})
 
-		handler := favourite.DeleteFavouriteHandler(favourite.DeleteFavouriteHandlerDependencies{FavouriteService: stubService})
-
-		req := httptest.NewRequest(http.MethodDelete, "/favourites", nil)
-		ctx := chi.NewRouteContext()
-		ctx.URLParams.Add("id", uuid.New().String())
-		req = req.WithContext(context.WithValue(injectJWT(req.Context(), userId.String()), chi.RouteCtxKey, ctx))
+		bodyBytes, _ := json.Marshal(map[string]interface{}{
+			"items": []map[string]interface{}{{"assetId": uuid.NewString(), "description": "desc"}},
+		})
+		req := httptest.NewRequest(http.MethodPost, "/favourites/batch", bytes.NewReader(bodyBytes))
+		req = req.WithContext(injectJWT(req.Context(), userId.String()))
 		w := httptest.NewRecorder()
 
 		// Act
 		handler(w, req)
 
 		// Assert
-		assert.Equal(t, http.StatusUnauthorized, w.Result().StatusCode)
+		assert.Equal(t, http.StatusInternalServerError, w.Result().StatusCode)
 	})
+}
 
-	t.Run("Should return 500 when JWT is missing", func(t *testing.T) {
+func TestUpdateFavouritesBatchHandler(t *testing.T) {
+	t.Run("Should return 200 with the status of every item", func(t *testing.T) {
 		// Arrange
-		handler := favourite.DeleteFavouriteHandler(favourite.DeleteFavouriteHandlerDependencies{
-			FavouriteService: &StubFavouriteService{},
+		userId := uuid.New()
+		updated := &favourite.Favourite{Id: uuid.New(), UserId: userId, Description: "new", Version: 2}
+		changedId := uuid.New()
+
+		requestBody := map[string]interface{}{
+			"items": []map[string]interface{}{
+				{"id": updated.Id.String(), "description": "new"},
+				{"id": changedId.String(), "description": "late", "version": 1},
+			},
+		}
+		stubService := &StubFavouriteService{
+			UpdateManyFunc: func(uId uuid.UUID, items []favourite.UpdateFavouritesBatchItem, atomic bool) ([]favourite.FavouriteWriteResult, error) {
+				assert.Equal(t, userId, uId)
+				if assert.Len(t, items, 2) {
+					assert.Nil(t, items[0].Version)
+					if assert.NotNil(t, items[1].Version) {
+						assert.Equal(t, 1, *items[1].Version)
+					}
+				}
+				return []favourite.FavouriteWriteResult{
+					{Favourite: updated},
+					{Err: favourite.ErrFavouriteVersionMismatch},
+				}, nil
+			},
+		}
+		handler := favourite.UpdateFavouritesBatchHandler(favourite.UpdateFavouritesBatchHandlerDependencies{
+			FavouriteService: stubService,
 		})
 
-		req := httptest.NewRequest(http.MethodDelete, "/favourites", nil)
-		ctx := chi.NewRouteContext()
-		ctx.URLParams.Add("id", uuid.New().String())
-		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, ctx))
+		bodyBytes, _ := json.Marshal(requestBody)
+		req := httptest.NewRequest(http.MethodPatch, "/favourites/batch", bytes.NewReader(bodyBytes))
+		req = req.WithContext(injectJWT(req.Context(), userId.String()))
 		w := httptest.NewRecorder()
 
 		// Act
 		handler(w, req)
 
 		// Assert
-		assert.Equal(t, http.StatusInternalServerError, w.Result().StatusCode)
+		assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+
+		var body utils.DataResponse[[]favourite.FavouriteBatchItemResult]
+		err := json.NewDecoder(w.Body).Decode(&body)
+		assert.NoError(t, err)
+		assert.Equal(t, []favourite.FavouriteBatchItemResult{
+			{Status: http.StatusOK, Data: updated},
+			{Status: http.StatusPreconditionFailed, Error: "Favourite was changed since the given version"},
+		}, body.Data)
 	})
+}
 
-	t.Run("Should return 400 on unexpected service error", func(t *testing.T) {
+func TestDeleteFavouritesBatchHandler(t *testing.T) {
+	t.Run("Should return 200 with the status of every item", func(t *testing.T) {
 		// Arrange
 		userId := uuid.New()
+		requestBody := map[string]interface{}{
+			"items": []map[string]interface{}{
+				{"id": uuid.NewString()},
+				{"id": uuid.NewString()},
+				{"id": uuid.NewString()},
+			},
+		}
 		stubService := &StubFavouriteService{
-			DeleteFunc: func(_, _ uuid.UUID) error {
-				return errors.New("random error")
+			DeleteManyFunc: func(_ uuid.UUID, items []favourite.DeleteFavouritesBatchItem, _ bool) ([]favourite.FavouriteWriteResult, error) {
+				assert.Len(t, items, 3)
+				return []favourite.FavouriteWriteResult{
+					{},
+					{Err: favourite.ErrFavouriteNotFound},
+					{Err: favourite.ErrFavouriteNotUnderGivenUser},
+				}, nil
 			},
 		}
+		handler := favourite.DeleteFavouritesBatchHandler(favourite.DeleteFavouritesBatchHandlerDependencies{
+			FavouriteService: stubService,
+		})
 
-		handler := favourite.DeleteFavouriteHandler(favourite.DeleteFavouriteHandlerDependencies{FavouriteService: stubService})
-		req := httptest.NewRequest(http.MethodDelete, "/favourites", strings.NewReader(`{}`))
-		req.Header.Set("Content-Type", "application/json")
+		bodyBytes, _ := json.Marshal(requestBody)
+		req := httptest.NewRequest(http.MethodDelete, "/favourites/batch", bytes.NewReader(bodyBytes))
 		req = req.WithContext(injectJWT(req.Context(), userId.String()))
 		w := httptest.NewRecorder()
 
@@ -622,6 +1890,15 @@ func TestDeleteFavouriteHandler(t *testing.T) {
 		handler(w, req)
 
 		// Assert
-		assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
+		assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+
+		var body utils.DataResponse[[]favourite.FavouriteBatchItemResult]
+		err := json.NewDecoder(w.Body).Decode(&body)
+		assert.NoError(t, err)
+		assert.Equal(t, []favourite.FavouriteBatchItemResult{
+			{Status: http.StatusOK},
+			{Status: http.StatusNotFound, Error: "Could not find Favourite with this Id"},
+			{Status: http.StatusUnauthorized, Error: "Favourite is not under given user"},
+		}, body.Data)
 	})
 }
@@ -1,128 +1,334 @@
 package favourite
 
 import (
-	"platform-go-challenge/internal/domain/audience"
-	"platform-go-challenge/internal/domain/chart"
-	"platform-go-challenge/internal/domain/insight"
+	"mime"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
 )
 
-func ExtractAssetTypeIds(assetType AssetType, favourites []Favourite) uuid.UUIDs {
-	result := uuid.UUIDs{}
+// ParseFavouriteSort reads the sort query param of a listing, an empty one lists by rank.
+func ParseFavouriteSort(value string) (FavouriteSort, error) {
+	switch sort := FavouriteSort(value); sort {
+	case "":
+		return FavouriteSortRank, nil
+	case FavouriteSortRank, FavouriteSortCreatedAt, FavouriteSortCreatedAtDesc, FavouriteSortDescription, FavouriteSortAssetType:
+		return sort, nil
+	default:
+		return "", ErrInvalidFavouriteSort
+	}
+}
 
-	for _, favourite := range favourites {
-		if assetType == favourite.AssetType {
-			result = append(result, favourite.AssetId)
+// NormalizeTag lowercases a tag and collapses its whitespace, so tags that only differ in case or spacing are the same.
+// It fails with ErrInvalidTag when the tag is blank, too long, or holds a comma, which separates the tags of a filter.
+func NormalizeTag(tag string) (string, error) {
+	result := normalizeTagText(tag)
+	if result == "" || utf8.RuneCountInString(result) > MaxTagLength || strings.Contains(result, ",") {
+		return "", ErrInvalidTag
+	}
+
+	return result, nil
+}
+
+// normalizeTagText is NormalizeTag without the checks, for the text a tag is searched by.
+func normalizeTagText(text string) string {
+	return strings.ToLower(strings.Join(strings.Fields(text), " "))
+}
+
+// NormalizeTags normalises every tag, drops the duplicates and sorts them. The result is never nil.
+func NormalizeTags(tags []string) ([]string, error) {
+	result := []string{}
+	for _, tag := range tags {
+		normalized, err := NormalizeTag(tag)
+		if err != nil {
+			return nil, err
 		}
+
+		result = append(result, normalized)
 	}
 
-	return result
+	slices.Sort(result)
+	result = slices.Compact(result)
+
+	if len(result) > MaxFavouriteTags {
+		return nil, ErrTooManyTags
+	}
+
+	return result, nil
 }
 
-func BuildAssetFavourites(
-	favourites []Favourite,
-	charts []chart.Chart,
-	insights []insight.Insight,
-	audiences []audience.Audience,
-) (*AssetFavourites, error) {
-	chartsFavourites, err := buildChartFavourites(charts, favourites)
-	if err != nil {
-		return nil, err
+// ParseTagFilter reads the tag query params of a listing, each holding one tag or a comma separated list of them.
+func ParseTagFilter(values []string) ([]string, error) {
+	tags := []string{}
+	for _, value := range values {
+		for _, tag := range strings.Split(value, ",") {
+			normalized, err := NormalizeTag(tag)
+			if err != nil {
+				return nil, err
+			}
+
+			tags = append(tags, normalized)
+		}
 	}
 
-	insightFavourites, err := buildInsightFavourites(insights, favourites)
-	if err != nil {
-		return nil, err
+	if len(tags) == 0 {
+		return nil, nil
 	}
 
-	audienceFavourites, err := buildAudienceFavourite(audiences, favourites)
-	if err != nil {
-		return nil, err
+	slices.Sort(tags)
+
+	return slices.Compact(tags), nil
+}
+
+// ParseTagMatch reads the tagMatch query param of a listing, by default a favourite needs every tag of the filter.
+func ParseTagMatch(value string) (TagMatch, error) {
+	switch match := TagMatch(value); match {
+	case "":
+		return TagMatchAll, nil
+	case TagMatchAll, TagMatchAny:
+		return match, nil
+	default:
+		return "", ErrInvalidTagMatch
+	}
+}
+
+// ParseTagCountLimit reads the limit query param of the most used tags.
+func ParseTagCountLimit(value string) (int, error) {
+	if value == "" {
+		return DefaultTagCountLimit, nil
 	}
 
-	return &AssetFavourites{
-		Charts:    *chartsFavourites,
-		Insights:  *insightFavourites,
-		Audiences: *audienceFavourites,
-	}, nil
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit < 1 || limit > MaxTagCountLimit {
+		return 0, ErrInvalidTagCountLimit
+	}
+
+	return limit, nil
 }
 
-func findFavouriteInSliceByAssetId(id uuid.UUID, favourites []Favourite) *Favourite {
-	for _, favourite := range favourites {
-		if favourite.AssetId == id {
-			return &favourite
-		}
+// Includes reports whether the listing keeps the favourites of the asset type.
+func (options FavouriteListOptions) Includes(assetType AssetType) bool {
+	return len(options.AssetTypes) == 0 || slices.Contains(options.AssetTypes, assetType)
+}
+
+// Matches reports whether the listing keeps the favourite with the given asset type, description and tags.
+func (options FavouriteListOptions) Matches(assetType AssetType, description string, tags []string) bool {
+	return options.Includes(assetType) &&
+		strings.Contains(strings.ToLower(description), strings.ToLower(options.Query)) &&
+		options.hasTags(tags)
+}
+
+func (options FavouriteListOptions) hasTags(tags []string) bool {
+	if len(options.Tags) == 0 {
+		return true
+	}
+
+	if options.TagMatch == TagMatchAny {
+		return slices.ContainsFunc(options.Tags, func(tag string) bool { return slices.Contains(tags, tag) })
 	}
 
-	return nil
+	return !slices.ContainsFunc(options.Tags, func(tag string) bool { return !slices.Contains(tags, tag) })
 }
 
-func buildChartFavourites(charts []chart.Chart, favourites []Favourite) (*[]ChartFavourite, error) {
-	result := []ChartFavourite{}
+// IsFiltered reports whether the listing leaves out any favourite.
+func (options FavouriteListOptions) IsFiltered() bool {
+	return len(options.AssetTypes) > 0 || options.Query != "" || len(options.Tags) > 0
+}
 
-	for _, chart := range charts {
-		favourite := findFavouriteInSliceByAssetId(chart.Id, favourites)
+// ParseFavouritesLayout reads the layout query param of a listing, or else the profile of an application/json
+// entry of the Accept header, e.g. `application/json; profile="flat"`. Lists are grouped by asset type by default.
+func ParseFavouritesLayout(query string, accept string) (FavouritesLayout, error) {
+	if query != "" {
+		switch layout := FavouritesLayout(query); layout {
+		case FavouritesLayoutGrouped, FavouritesLayoutFlat:
+			return layout, nil
+		default:
+			return "", ErrInvalidFavouritesLayout
+		}
+	}
 
-		if favourite == nil {
-			return nil, ErrCouldNotFindFavouriteForAsset
+	// An unknown profile is not an error, the client just gets the default representation
+	for _, entry := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(entry)
+		if err != nil || mediaType != "application/json" {
+			continue
 		}
 
-		result = append(
-			result,
-			ChartFavourite{
-				Id:          favourite.Id,
-				Description: favourite.Description,
-				Info:        chart,
-			},
-		)
+		switch layout := FavouritesLayout(params["profile"]); layout {
+		case FavouritesLayoutGrouped, FavouritesLayoutFlat:
+			return layout, nil
+		}
 	}
 
-	return &result, nil
+	return FavouritesLayoutGrouped, nil
 }
 
-func buildInsightFavourites(insights []insight.Insight, favourites []Favourite) (*[]InsightFavourite, error) {
-	result := []InsightFavourite{}
+// NewFavouriteCursor returns the position of the favourite in the listing order of sort.
+func NewFavouriteCursor(sort FavouriteSort, favourite Favourite) FavouriteCursor {
+	cursor := FavouriteCursor{Sort: sort, Id: favourite.Id}
+
+	switch sort {
+	case FavouriteSortDescription:
+		cursor.Description = favourite.Description
+	case FavouriteSortAssetType:
+		cursor.AssetType = favourite.AssetType
+	case FavouriteSortRank:
+		cursor.Rank = favourite.Rank
+		cursor.Pinned = favourite.Pinned
+	default:
+		cursor.CreatedAt = favourite.CreatedAt
+	}
+
+	return cursor
+}
 
-	for _, insight := range insights {
-		favourite := findFavouriteInSliceByAssetId(insight.Id, favourites)
+// Changes returns the changes of the patch, where a field set to null is cleared:
+// the description is emptied, the favourite is unpinned and its tags are removed.
+func (body UpdateFavouriteRequestBody) Changes() FavouriteChanges {
+	changes := FavouriteChanges{}
 
-		if favourite == nil {
-			return nil, ErrCouldNotFindFavouriteForAsset
+	if body.Has("description") {
+		description := ""
+		if body.Description != nil {
+			description = *body.Description
 		}
+		changes.Description = &description
+	}
 
-		result = append(
-			result,
-			InsightFavourite{
-				Id:          favourite.Id,
-				Description: favourite.Description,
-				Info:        insight,
-			},
-		)
+	if body.Has("pinned") {
+		pinned := body.Pinned != nil && *body.Pinned
+		changes.Pinned = &pinned
 	}
 
-	return &result, nil
+	if body.Has("tags") {
+		changes.Tags = body.Tags
+		if changes.Tags == nil {
+			changes.Tags = []string{}
+		}
+	}
+
+	return changes
+}
+
+// normalized returns the changes with their tags normalised, nil tags stay nil so they are left as they are.
+func (changes FavouriteChanges) normalized() (FavouriteChanges, error) {
+	if changes.Tags == nil {
+		return changes, nil
+	}
+
+	tags, err := NormalizeTags(changes.Tags)
+	if err != nil {
+		return FavouriteChanges{}, err
+	}
+
+	changes.Tags = tags
+
+	return changes, nil
+}
+
+// applyTo sets the changed fields on the favourite, pinning keeps the rank so unpinning puts the favourite back in place.
+func (changes FavouriteChanges) applyTo(favourite *Favourite) {
+	if changes.Description != nil {
+		favourite.Description = *changes.Description
+	}
+
+	if changes.Pinned != nil {
+		favourite.Pinned = *changes.Pinned
+	}
+
+	if changes.Tags != nil {
+		favourite.Tags = changes.Tags
+	}
+}
+
+// ExtractAssetTypeIds returns the distinct asset ids of the given type, in the order of the favourites.
+func ExtractAssetTypeIds(assetType AssetType, favourites []Favourite) uuid.UUIDs {
+	result := uuid.UUIDs{}
+	seen := map[uuid.UUID]bool{}
+
+	for _, favourite := range favourites {
+		if assetType == favourite.AssetType && !seen[favourite.AssetId] {
+			seen[favourite.AssetId] = true
+			result = append(result, favourite.AssetId)
+		}
+	}
+
+	return result
 }
 
-func buildAudienceFavourite(audiences []audience.Audience, favourites []Favourite) (*[]AudienceFavourite, error) {
-	result := []AudienceFavourite{}
+// MatchFavouritesToAssets pairs every favourite with its asset, keeping the order of the favourites.
+// Favourites whose asset is missing are skipped, and every asset must belong to at least one favourite.
+// Legacy data can hold several favourites of the same asset, each of them gets its own entry.
+func MatchFavouritesToAssets(favourites []Favourite, assets []Asset) ([]FavouriteWithAsset, error) {
+	assetsById := map[uuid.UUID]Asset{}
+	for _, asset := range assets {
+		assetsById[asset.AssetId()] = asset
+	}
 
-	for _, audience := range audiences {
-		favourite := findFavouriteInSliceByAssetId(audience.Id, favourites)
+	result := []FavouriteWithAsset{}
+	matched := map[uuid.UUID]bool{}
 
-		if favourite == nil {
-			return nil, ErrCouldNotFindFavouriteForAsset
+	for _, favourite := range favourites {
+		asset, found := assetsById[favourite.AssetId]
+		if !found {
+			continue
 		}
 
-		result = append(
-			result,
-			AudienceFavourite{
-				Id:          favourite.Id,
-				Description: favourite.Description,
-				Info:        audience,
-			},
-		)
+		matched[favourite.AssetId] = true
+		result = append(result, FavouriteWithAsset{Favourite: favourite, Asset: asset})
+	}
+
+	if len(matched) != len(assetsById) {
+		return nil, ErrCouldNotFindFavouriteForAsset
+	}
+
+	return result, nil
+}
+
+// BuildFavouriteItems lists the favourites in their order, each with its asset embedded.
+func BuildFavouriteItems(favourites []FavouriteWithAsset) []FavouriteItem {
+	result := []FavouriteItem{}
+
+	for _, entry := range favourites {
+		result = append(result, FavouriteItem{
+			Id:          entry.Favourite.Id,
+			Type:        entry.Favourite.AssetType,
+			Description: entry.Favourite.Description,
+			Asset:       entry.Asset,
+			CreatedAt:   entry.Favourite.CreatedAt,
+			UpdatedAt:   entry.Favourite.UpdatedAt,
+			Rank:        entry.Favourite.Rank,
+			Pinned:      entry.Favourite.Pinned,
+			Tags:        entry.Favourite.Tags,
+		})
 	}
 
-	return &result, nil
+	return result
+}
+
+// toAssets widens the assets of one type to the common interface.
+func toAssets[T Asset](assets []T) []Asset {
+	result := make([]Asset, 0, len(assets))
+	for _, asset := range assets {
+		result = append(result, asset)
+	}
+
+	return result
+}
+
+// HasFailedWrite reports whether any write of a batch failed.
+func HasFailedWrite(results []FavouriteWriteResult) bool {
+	return slices.ContainsFunc(results, func(result FavouriteWriteResult) bool { return result.Err != nil })
+}
+
+// RollBackFavouriteWrites marks the writes of a batch that did not fail as not applied, once the batch is rolled back.
+func RollBackFavouriteWrites(results []FavouriteWriteResult) {
+	for i := range results {
+		if results[i].Err == nil {
+			results[i] = FavouriteWriteResult{Err: ErrFavouriteBatchRolledBack}
+		}
+	}
 }
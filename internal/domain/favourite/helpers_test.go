@@ -2,87 +2,358 @@ package favourite_test
 
 import (
 	"errors"
+	"fmt"
 	"platform-go-challenge/internal/domain/audience"
 	"platform-go-challenge/internal/domain/chart"
 	"platform-go-challenge/internal/domain/favourite"
 	"platform-go-challenge/internal/domain/insight"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 )
 
-func TestBuildAssetFavourites(t *testing.T) {
-	t.Run("should build grouped asset favourites successfully", func(t *testing.T) {
+func TestMatchFavouritesToAssets(t *testing.T) {
+	t.Run("should pair every favourite with its asset in the order of the favourites", func(t *testing.T) {
 		// Arrange
 		chartID := uuid.New()
 		insightID := uuid.New()
 		audienceID := uuid.New()
+
+		assets := []favourite.Asset{chart.Chart{Id: chartID}, insight.Insight{Id: insightID}, audience.Audience{Id: audienceID}}
+		favs := []favourite.Favourite{
+			{Id: uuid.New(), AssetId: audienceID, AssetType: "audience", Description: "Audience C"},
+			{Id: uuid.New(), AssetId: chartID, AssetType: "chart", Description: "Chart A"},
+			{Id: uuid.New(), AssetId: insightID, AssetType: "insight", Description: "Insight B"},
+		}
+
+		// Act
+		result, err := favourite.MatchFavouritesToAssets(favs, assets)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, []favourite.FavouriteWithAsset{
+			{Favourite: favs[0], Asset: assets[2]},
+			{Favourite: favs[1], Asset: assets[0]},
+			{Favourite: favs[2], Asset: assets[1]},
+		}, result)
+	})
+
+	t.Run("should fail if favourite is missing for a provided asset", func(t *testing.T) {
+		// Arrange
+		assets := []favourite.Asset{chart.Chart{Id: uuid.New()}}
+		favs := []favourite.Favourite{} // no favourites provided
+
+		// Act
+		_, err := favourite.MatchFavouritesToAssets(favs, assets)
+
+		// Assert
+		assert.Error(t, err)
+		assert.True(t, errors.Is(err, favourite.ErrCouldNotFindFavouriteForAsset))
+	})
+
+	t.Run("should return no favourites when there are no assets", func(t *testing.T) {
+		// Act
+		result, err := favourite.MatchFavouritesToAssets([]favourite.Favourite{}, []favourite.Asset{})
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Empty(t, result)
+	})
+
+	t.Run("should list every legacy duplicate favourite of the same asset with its own id", func(t *testing.T) {
+		// Arrange
+		chartID := uuid.New()
 		favID1 := uuid.New()
 		favID2 := uuid.New()
-		favID3 := uuid.New()
 
-		charts := []chart.Chart{{Id: chartID}}
-		insights := []insight.Insight{{Id: insightID}}
-		audiences := []audience.Audience{{Id: audienceID}}
+		assets := []favourite.Asset{chart.Chart{Id: chartID}}
+		favs := []favourite.Favourite{
+			{Id: favID1, AssetId: chartID, AssetType: "chart", Description: "first"},
+			{Id: favID2, AssetId: chartID, AssetType: "chart", Description: "second"},
+		}
+
+		// Act
+		result, err := favourite.MatchFavouritesToAssets(favs, assets)
 
+		// Assert
+		assert.NoError(t, err)
+		assert.Len(t, result, 2)
+		assert.Equal(t, favID1, result[0].Favourite.Id)
+		assert.Equal(t, favID2, result[1].Favourite.Id)
+	})
+
+	t.Run("should keep the order of the favourites and skip the ones with missing assets", func(t *testing.T) {
+		// Arrange
+		chartID1 := uuid.New()
+		chartID2 := uuid.New()
+		favID1 := uuid.New()
+		favID2 := uuid.New()
+
+		assets := []favourite.Asset{chart.Chart{Id: chartID1}, chart.Chart{Id: chartID2}}
 		favs := []favourite.Favourite{
-			{Id: favID1, AssetId: chartID, AssetType: "charts", Description: "Chart A"},
-			{Id: favID2, AssetId: insightID, AssetType: "insights", Description: "Insight B"},
-			{Id: favID3, AssetId: audienceID, AssetType: "audiences", Description: "Audience C"},
+			{Id: favID2, AssetId: chartID2, AssetType: "chart"},
+			{Id: uuid.New(), AssetId: uuid.New(), AssetType: "chart"},
+			{Id: favID1, AssetId: chartID1, AssetType: "chart"},
 		}
 
 		// Act
-		result, err := favourite.BuildAssetFavourites(favs, charts, insights, audiences)
+		result, err := favourite.MatchFavouritesToAssets(favs, assets)
 
 		// Assert
 		assert.NoError(t, err)
-		assert.NotNil(t, result)
+		assert.Len(t, result, 2)
+		assert.Equal(t, favID2, result[0].Favourite.Id)
+		assert.Equal(t, favID1, result[1].Favourite.Id)
+	})
+}
 
-		assert.Len(t, result.Charts, 1)
-		assert.Equal(t, favID1, result.Charts[0].Id)
-		assert.Equal(t, chartID, result.Charts[0].Info.Id)
+func TestBuildFavouriteItems(t *testing.T) {
+	t.Run("should list the favourites in order with their type and asset", func(t *testing.T) {
+		// Arrange
+		chartAsset := chart.Chart{Id: uuid.New(), Title: "Sales"}
+		insightAsset := insight.Insight{Id: uuid.New(), Text: "Retention"}
+		createdAt := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+		favs := []favourite.FavouriteWithAsset{
+			{Favourite: favourite.Favourite{Id: uuid.New(), AssetType: favourite.AssetTypeInsight, Description: "first", CreatedAt: createdAt}, Asset: insightAsset},
+			{Favourite: favourite.Favourite{Id: uuid.New(), AssetType: favourite.AssetTypeChart, Description: "second", UpdatedAt: createdAt}, Asset: chartAsset},
+		}
 
-		assert.Len(t, result.Insights, 1)
-		assert.Equal(t, favID2, result.Insights[0].Id)
-		assert.Equal(t, insightID, result.Insights[0].Info.Id)
+		// Act
+		result := favourite.BuildFavouriteItems(favs)
 
-		assert.Len(t, result.Audiences, 1)
-		assert.Equal(t, favID3, result.Audiences[0].Id)
-		assert.Equal(t, audienceID, result.Audiences[0].Info.Id)
+		// Assert
+		assert.Equal(t, []favourite.FavouriteItem{
+			{Id: favs[0].Favourite.Id, Type: favourite.AssetTypeInsight, Description: "first", Asset: insightAsset, CreatedAt: createdAt},
+			{Id: favs[1].Favourite.Id, Type: favourite.AssetTypeChart, Description: "second", Asset: chartAsset, UpdatedAt: createdAt},
+		}, result)
 	})
+}
 
-	t.Run("should fail if favourite is missing for a provided asset", func(t *testing.T) {
+func TestExtractAssetTypeIds(t *testing.T) {
+	t.Run("should return each asset id of the type once", func(t *testing.T) {
 		// Arrange
-		chartID := uuid.New()
-		charts := []chart.Chart{{Id: chartID}}
-		insights := []insight.Insight{}
-		audiences := []audience.Audience{}
-		favs := []favourite.Favourite{} // no favourites provided
+		chartID1 := uuid.New()
+		chartID2 := uuid.New()
+		favs := []favourite.Favourite{
+			{AssetId: chartID1, AssetType: favourite.AssetTypeChart},
+			{AssetId: uuid.New(), AssetType: favourite.AssetTypeInsight},
+			{AssetId: chartID2, AssetType: favourite.AssetTypeChart},
+			{AssetId: chartID1, AssetType: favourite.AssetTypeChart},
+		}
 
 		// Act
-		_, err := favourite.BuildAssetFavourites(favs, charts, insights, audiences)
+		result := favourite.ExtractAssetTypeIds(favourite.AssetTypeChart, favs)
 
 		// Assert
-		assert.Error(t, err)
-		assert.True(t, errors.Is(err, favourite.ErrCouldNotFindFavouriteForAsset))
+		assert.Equal(t, uuid.UUIDs{chartID1, chartID2}, result)
+	})
+}
+
+func TestParseFavouriteSort(t *testing.T) {
+	t.Run("should list by rank when sort is empty", func(t *testing.T) {
+		// Act
+		result, err := favourite.ParseFavouriteSort("")
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, favourite.FavouriteSortRank, result)
+	})
+
+	t.Run("should accept every known sort and reject the rest", func(t *testing.T) {
+		for _, value := range []string{"rank", "created_at", "-created_at", "description", "asset_type"} {
+			// Act
+			result, err := favourite.ParseFavouriteSort(value)
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, favourite.FavouriteSort(value), result)
+		}
+
+		// Act
+		result, err := favourite.ParseFavouriteSort("-description")
+
+		// Assert
+		assert.ErrorIs(t, err, favourite.ErrInvalidFavouriteSort)
+		assert.Empty(t, result)
+	})
+}
+
+func TestFavouriteListOptionsMatches(t *testing.T) {
+	t.Run("should keep favourites of the listed types whose description contains the query", func(t *testing.T) {
+		// Arrange
+		options := favourite.FavouriteListOptions{
+			AssetTypes: []favourite.AssetType{favourite.AssetTypeChart, favourite.AssetTypeInsight},
+			Query:      "q2",
+		}
+
+		// Act & Assert
+		assert.True(t, options.Matches(favourite.AssetTypeChart, "For the Q2 review", nil))
+		assert.False(t, options.Matches(favourite.AssetTypeChart, "For the Q3 review", nil))
+		assert.False(t, options.Matches(favourite.AssetTypeAudience, "For the Q2 review", nil))
+		assert.True(t, favourite.FavouriteListOptions{}.Matches(favourite.AssetTypeAudience, "", nil))
+	})
+
+	t.Run("should keep favourites with every tag, or with any tag when asked to", func(t *testing.T) {
+		// Arrange
+		everyTag := favourite.FavouriteListOptions{Tags: []string{"q2", "sales"}}
+		anyTag := favourite.FavouriteListOptions{Tags: []string{"q2", "sales"}, TagMatch: favourite.TagMatchAny}
+
+		// Act & Assert
+		assert.True(t, everyTag.Matches(favourite.AssetTypeChart, "", []string{"churn", "q2", "sales"}))
+		assert.False(t, everyTag.Matches(favourite.AssetTypeChart, "", []string{"q2"}))
+		assert.True(t, anyTag.Matches(favourite.AssetTypeChart, "", []string{"q2"}))
+		assert.False(t, anyTag.Matches(favourite.AssetTypeChart, "", []string{"churn"}))
+		assert.False(t, anyTag.Matches(favourite.AssetTypeChart, "", nil))
+	})
+}
+
+func TestNormalizeTags(t *testing.T) {
+	t.Run("should lowercase, collapse spaces, drop duplicates and sort", func(t *testing.T) {
+		// Act
+		result, err := favourite.NormalizeTags([]string{" Sales ", "Q2  Review", "q2 review", "churn"})
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"churn", "q2 review", "sales"}, result)
+	})
+
+	t.Run("should return an empty list for no tags", func(t *testing.T) {
+		// Act
+		result, err := favourite.NormalizeTags(nil)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, []string{}, result)
+	})
+
+	t.Run("should reject blank, long and comma separated tags", func(t *testing.T) {
+		for _, tag := range []string{"  ", strings.Repeat("a", favourite.MaxTagLength+1), "q2,sales"} {
+			// Act
+			_, err := favourite.NormalizeTags([]string{tag})
+
+			// Assert
+			assert.ErrorIs(t, err, favourite.ErrInvalidTag, tag)
+		}
 	})
 
-	t.Run("should return empty grouped favourites when asset slices are empty", func(t *testing.T) {
+	t.Run("should reject more distinct tags than allowed", func(t *testing.T) {
 		// Arrange
-		favs := []favourite.Favourite{}
-		charts := []chart.Chart{}
-		insights := []insight.Insight{}
-		audiences := []audience.Audience{}
+		tags := []string{}
+		for i := range favourite.MaxFavouriteTags + 1 {
+			tags = append(tags, fmt.Sprintf("tag %d", i))
+		}
+
+		// Act
+		_, err := favourite.NormalizeTags(tags)
+		_, duplicatesErr := favourite.NormalizeTags(append(tags[:favourite.MaxFavouriteTags], "TAG 0"))
+
+		// Assert
+		assert.ErrorIs(t, err, favourite.ErrTooManyTags)
+		assert.NoError(t, duplicatesErr)
+	})
+}
 
+func TestParseTagFilter(t *testing.T) {
+	t.Run("should read repeated and comma separated tags once each", func(t *testing.T) {
 		// Act
-		result, err := favourite.BuildAssetFavourites(favs, charts, insights, audiences)
+		result, err := favourite.ParseTagFilter([]string{"Sales,q2", "sales"})
 
 		// Assert
 		assert.NoError(t, err)
-		assert.NotNil(t, result)
-		assert.Len(t, result.Charts, 0)
-		assert.Len(t, result.Insights, 0)
-		assert.Len(t, result.Audiences, 0)
+		assert.Equal(t, []string{"q2", "sales"}, result)
+	})
+
+	t.Run("should not filter when no tag is given", func(t *testing.T) {
+		// Act
+		result, err := favourite.ParseTagFilter(nil)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Nil(t, result)
+	})
+
+	t.Run("should reject empty tags", func(t *testing.T) {
+		// Act
+		_, err := favourite.ParseTagFilter([]string{"q2,,sales"})
+
+		// Assert
+		assert.ErrorIs(t, err, favourite.ErrInvalidTag)
+	})
+}
+
+func TestParseTagMatch(t *testing.T) {
+	t.Run("should need every tag by default", func(t *testing.T) {
+		// Act
+		result, err := favourite.ParseTagMatch("")
+		anyResult, anyErr := favourite.ParseTagMatch("any")
+		_, invalidErr := favourite.ParseTagMatch("some")
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, favourite.TagMatchAll, result)
+		assert.NoError(t, anyErr)
+		assert.Equal(t, favourite.TagMatchAny, anyResult)
+		assert.ErrorIs(t, invalidErr, favourite.ErrInvalidTagMatch)
+	})
+}
+
+func TestParseTagCountLimit(t *testing.T) {
+	t.Run("should default and bound the limit", func(t *testing.T) {
+		// Act
+		defaultLimit, defaultErr := favourite.ParseTagCountLimit("")
+		limit, err := favourite.ParseTagCountLimit("25")
+
+		// Assert
+		assert.NoError(t, defaultErr)
+		assert.Equal(t, favourite.DefaultTagCountLimit, defaultLimit)
+		assert.NoError(t, err)
+		assert.Equal(t, 25, limit)
+		for _, value := range []string{"0", "101", "ten"} {
+			_, err := favourite.ParseTagCountLimit(value)
+			assert.ErrorIs(t, err, favourite.ErrInvalidTagCountLimit, value)
+		}
+	})
+}
+
+func TestParseFavouritesLayout(t *testing.T) {
+	t.Run("should group by asset type by default", func(t *testing.T) {
+		// Act
+		result, err := favourite.ParseFavouritesLayout("", "application/json")
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, favourite.FavouritesLayoutGrouped, result)
+	})
+
+	t.Run("should read the layout from the query before the Accept profile", func(t *testing.T) {
+		// Act
+		result, err := favourite.ParseFavouritesLayout("grouped", `application/json; profile="flat"`)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, favourite.FavouritesLayoutGrouped, result)
+	})
+
+	t.Run("should read the layout from the profile of an application/json Accept entry", func(t *testing.T) {
+		// Act
+		result, err := favourite.ParseFavouritesLayout("", `text/html, application/json;profile=flat;q=0.9`)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, favourite.FavouritesLayoutFlat, result)
+	})
+
+	t.Run("should reject an unknown layout query and ignore an unknown profile", func(t *testing.T) {
+		// Act
+		_, queryErr := favourite.ParseFavouritesLayout("list", "")
+		result, profileErr := favourite.ParseFavouritesLayout("", `application/json; profile="list"`)
+
+		// Assert
+		assert.ErrorIs(t, queryErr, favourite.ErrInvalidFavouritesLayout)
+		assert.NoError(t, profileErr)
+		assert.Equal(t, favourite.FavouritesLayoutGrouped, result)
 	})
 }
@@ -0,0 +1,159 @@
+package favourite
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// AssetProvider plugs an asset type into the favourites, for their creation, listing and hydration.
+type AssetProvider interface {
+	// Type is stored with the favourites of the assets and is what the type filter of a listing takes
+	Type() AssetType
+	// Group is the key the favourites of the assets are listed under in the grouped layout
+	Group() string
+	// GetByIds returns the assets with the given ids, leaving out the ids that are not one of its assets
+	GetByIds(ids uuid.UUIDs) ([]Asset, error)
+}
+
+type assetProvider[T Asset] struct {
+	assetType AssetType
+	group     string
+	getByIds  func(ids uuid.UUIDs) ([]T, error)
+}
+
+// NewAssetProvider makes an AssetProvider out of the GetByIds of an asset repository.
+func NewAssetProvider[T Asset](assetType AssetType, group string, getByIds func(ids uuid.UUIDs) ([]T, error)) AssetProvider {
+	return &assetProvider[T]{
+		assetType: assetType,
+		group:     group,
+		getByIds:  getByIds,
+	}
+}
+
+func (provider *assetProvider[T]) Type() AssetType {
+	return provider.assetType
+}
+
+func (provider *assetProvider[T]) Group() string {
+	return provider.group
+}
+
+func (provider *assetProvider[T]) GetByIds(ids uuid.UUIDs) ([]Asset, error) {
+	assets, err := provider.getByIds(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	return toAssets(assets), nil
+}
+
+// AssetRegistry holds the provider of every asset type a favourite can point to, in the order they are registered.
+type AssetRegistry struct {
+	providers []AssetProvider
+}
+
+func NewAssetRegistry() *AssetRegistry {
+	return &AssetRegistry{}
+}
+
+// Register adds the provider of an asset type. An id that is an asset of several types is detected
+// as the type registered first. It panics when the type or the group is already registered,
+// since registering happens once at wiring time.
+func (registry *AssetRegistry) Register(provider AssetProvider) {
+	for _, registered := range registry.providers {
+		if registered.Type() == provider.Type() || registered.Group() == provider.Group() {
+			panic(fmt.Sprintf("asset type %q with group %q is already registered", provider.Type(), provider.Group()))
+		}
+	}
+
+	registry.providers = append(registry.providers, provider)
+}
+
+// Providers returns the providers in the order they are registered.
+func (registry *AssetRegistry) Providers() []AssetProvider {
+	return slices.Clone(registry.providers)
+}
+
+// Provider returns the provider of the asset type, if it is registered.
+func (registry *AssetRegistry) Provider(assetType AssetType) (AssetProvider, bool) {
+	for _, provider := range registry.providers {
+		if provider.Type() == assetType {
+			return provider, true
+		}
+	}
+
+	return nil, false
+}
+
+// Types returns the registered asset types in the order they are registered.
+func (registry *AssetRegistry) Types() []AssetType {
+	result := make([]AssetType, 0, len(registry.providers))
+	for _, provider := range registry.providers {
+		result = append(result, provider.Type())
+	}
+
+	return result
+}
+
+// ParseAssetTypes reads the comma separated type query param of a listing, an empty one keeps every type.
+// It fails with ErrInvalidAssetTypeFilter, listing the registered types, when a type is not registered.
+func (registry *AssetRegistry) ParseAssetTypes(value string) ([]AssetType, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	result := []AssetType{}
+	for _, part := range strings.Split(value, ",") {
+		assetType := AssetType(strings.TrimSpace(part))
+		if _, found := registry.Provider(assetType); !found {
+			types := []string{}
+			for _, registered := range registry.Types() {
+				types = append(types, string(registered))
+			}
+
+			return nil, fmt.Errorf("%w: %s", ErrInvalidAssetTypeFilter, strings.Join(types, ", "))
+		}
+
+		if !slices.Contains(result, assetType) {
+			result = append(result, assetType)
+		}
+	}
+
+	return result, nil
+}
+
+// BuildAssetFavourites groups the favourites by the type of their asset, keeping their order within every group.
+// Every registered group is listed, the ones without favourites as an empty list.
+func (registry *AssetRegistry) BuildAssetFavourites(favourites []FavouriteWithAsset) AssetFavourites {
+	result := AssetFavourites{}
+	groups := map[AssetType]string{}
+	for _, provider := range registry.providers {
+		result[provider.Group()] = []AssetFavourite{}
+		groups[provider.Type()] = provider.Group()
+	}
+
+	for _, entry := range favourites {
+		favourite := entry.Favourite
+
+		group, found := groups[favourite.AssetType]
+		if !found {
+			continue
+		}
+
+		result[group] = append(result[group], AssetFavourite{
+			Id:          favourite.Id,
+			Description: favourite.Description,
+			Info:        entry.Asset,
+			CreatedAt:   favourite.CreatedAt,
+			UpdatedAt:   favourite.UpdatedAt,
+			Rank:        favourite.Rank,
+			Pinned:      favourite.Pinned,
+			Tags:        favourite.Tags,
+		})
+	}
+
+	return result
+}
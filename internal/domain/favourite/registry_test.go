@@ -0,0 +1,175 @@
+package favourite_test
+
+import (
+	"platform-go-challenge/internal/domain/audience"
+	"platform-go-challenge/internal/domain/chart"
+	"platform-go-challenge/internal/domain/favourite"
+	"platform-go-challenge/internal/domain/insight"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// newAssetRegistry registers the built-in asset types like server.wireDependencies, reading them from the given repositories.
+func newAssetRegistry(charts chart.ChartRepository, insights insight.InsightRepository, audiences audience.AudienceRepository) *favourite.AssetRegistry {
+	assets := favourite.NewAssetRegistry()
+	assets.Register(favourite.NewAssetProvider(favourite.AssetTypeChart, "charts", charts.GetByIds))
+	assets.Register(favourite.NewAssetProvider(favourite.AssetTypeInsight, "insights", insights.GetByIds))
+	assets.Register(favourite.NewAssetProvider(favourite.AssetTypeAudience, "audiences", audiences.GetByIds))
+
+	return assets
+}
+
+// builtInAssets registers the built-in asset types for the tests that never read an asset.
+func builtInAssets() *favourite.AssetRegistry {
+	return newAssetRegistry(&mockChartRepo{}, &mockInsightRepo{}, &mockAudienceRepo{})
+}
+
+func TestAssetRegistryRegister(t *testing.T) {
+	t.Run("should keep the providers in the order they are registered", func(t *testing.T) {
+		// Act
+		assets := builtInAssets()
+
+		// Assert
+		assert.Equal(t, []favourite.AssetType{favourite.AssetTypeChart, favourite.AssetTypeInsight, favourite.AssetTypeAudience}, assets.Types())
+		provider, found := assets.Provider(favourite.AssetTypeInsight)
+		assert.True(t, found)
+		assert.Equal(t, "insights", provider.Group())
+	})
+
+	t.Run("should panic when a type or a group is registered twice", func(t *testing.T) {
+		// Arrange
+		assets := builtInAssets()
+		sameType := favourite.NewAssetProvider(favourite.AssetTypeChart, "graphs", (&mockChartRepo{}).GetByIds)
+		sameGroup := favourite.NewAssetProvider(favourite.AssetType("graph"), "charts", (&mockChartRepo{}).GetByIds)
+
+		// Act & Assert
+		assert.Panics(t, func() { assets.Register(sameType) })
+		assert.Panics(t, func() { assets.Register(sameGroup) })
+	})
+}
+
+func TestAssetProviderGetByIds(t *testing.T) {
+	t.Run("should read the assets from the repository", func(t *testing.T) {
+		// Arrange
+		chartId := uuid.New()
+		provider := favourite.NewAssetProvider(favourite.AssetTypeChart, "charts", (&mockChartRepo{
+			getByIdsFn: func(ids uuid.UUIDs) ([]chart.Chart, error) {
+				assert.Equal(t, uuid.UUIDs{chartId}, ids)
+				return []chart.Chart{{Id: chartId}}, nil
+			},
+		}).GetByIds)
+
+		// Act
+		result, err := provider.GetByIds(uuid.UUIDs{chartId})
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, []favourite.Asset{chart.Chart{Id: chartId}}, result)
+	})
+}
+
+func TestParseAssetTypes(t *testing.T) {
+	assets := builtInAssets()
+
+	t.Run("should keep every type when the filter is empty", func(t *testing.T) {
+		// Act
+		result, err := assets.ParseAssetTypes("")
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Empty(t, result)
+	})
+
+	t.Run("should read each listed type once", func(t *testing.T) {
+		// Act
+		result, err := assets.ParseAssetTypes("chart, audience,chart")
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, []favourite.AssetType{favourite.AssetTypeChart, favourite.AssetTypeAudience}, result)
+	})
+
+	t.Run("should reject unknown and empty types listing the registered ones", func(t *testing.T) {
+		for _, value := range []string{"charts", "chart,", "insight,,audience"} {
+			// Act
+			_, err := assets.ParseAssetTypes(value)
+
+			// Assert
+			assert.ErrorIs(t, err, favourite.ErrInvalidAssetTypeFilter, value)
+			assert.EqualError(t, err, "type must be a comma separated list of asset types: chart, insight, audience", value)
+		}
+	})
+
+	t.Run("should accept a type once its provider is registered", func(t *testing.T) {
+		// Arrange
+		withReports := builtInAssets()
+		withReports.Register(favourite.NewAssetProvider(favourite.AssetType("report"), "reports", (&mockChartRepo{}).GetByIds))
+
+		// Act
+		result, err := withReports.ParseAssetTypes("report")
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, []favourite.AssetType{"report"}, result)
+	})
+}
+
+func TestBuildAssetFavourites(t *testing.T) {
+	assets := builtInAssets()
+
+	t.Run("should build grouped asset favourites successfully", func(t *testing.T) {
+		// Arrange
+		chartID := uuid.New()
+		insightID := uuid.New()
+		audienceID := uuid.New()
+		favID1 := uuid.New()
+		favID2 := uuid.New()
+		favID3 := uuid.New()
+
+		favs := []favourite.FavouriteWithAsset{
+			{
+				Favourite: favourite.Favourite{Id: favID1, AssetId: chartID, AssetType: favourite.AssetTypeChart, Description: "Chart A"},
+				Asset:     chart.Chart{Id: chartID},
+			},
+			{
+				Favourite: favourite.Favourite{Id: favID2, AssetId: insightID, AssetType: favourite.AssetTypeInsight, Description: "Insight B"},
+				Asset:     insight.Insight{Id: insightID},
+			},
+			{
+				Favourite: favourite.Favourite{Id: favID3, AssetId: audienceID, AssetType: favourite.AssetTypeAudience, Description: "Audience C"},
+				Asset:     audience.Audience{Id: audienceID},
+			},
+		}
+
+		// Act
+		result := assets.BuildAssetFavourites(favs)
+
+		// Assert
+		assert.Len(t, result["charts"], 1)
+		assert.Equal(t, favID1, result["charts"][0].Id)
+		assert.Equal(t, "Chart A", result["charts"][0].Description)
+		assert.Equal(t, chart.Chart{Id: chartID}, result["charts"][0].Info)
+
+		assert.Len(t, result["insights"], 1)
+		assert.Equal(t, favID2, result["insights"][0].Id)
+		assert.Equal(t, insight.Insight{Id: insightID}, result["insights"][0].Info)
+
+		assert.Len(t, result["audiences"], 1)
+		assert.Equal(t, favID3, result["audiences"][0].Id)
+		assert.Equal(t, audience.Audience{Id: audienceID}, result["audiences"][0].Info)
+	})
+
+	t.Run("should return empty grouped favourites when there are no favourites", func(t *testing.T) {
+		// Act
+		result := assets.BuildAssetFavourites([]favourite.FavouriteWithAsset{})
+
+		// Assert
+		assert.Equal(t, favourite.AssetFavourites{
+			"charts":    {},
+			"insights":  {},
+			"audiences": {},
+		}, result)
+	})
+}
@@ -1,21 +1,40 @@
 package favourite
 
 import (
-	"maps"
+	"cmp"
+	"errors"
 	"platform-go-challenge/internal/database"
 	"platform-go-challenge/internal/utils"
 	"slices"
-	"sort"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 )
 
 type FavouriteRepository interface {
-	GetByUserIdPaginated(userId uuid.UUID, pageSize int, pageNumber int) ([]Favourite, utils.Pagination, error)
+	GetByUserIdPaginated(userId uuid.UUID, pageSize int, pageNumber int, options FavouriteListOptions) ([]Favourite, utils.Pagination, error)
+	// GetByUserIdKeyset returns the favourites in listing order, also when they are the ones before the cursor
+	GetByUserIdKeyset(userId uuid.UUID, pageSize int, cursor FavouriteCursor, before bool, options FavouriteListOptions) ([]Favourite, bool, error)
 	GetById(id uuid.UUID) (*Favourite, error)
+	// Create returns the favourite the user already has for the asset together with ErrFavouriteAlreadyExists
 	Create(favourite Favourite) (*Favourite, error)
+	// Update only stores the favourite while the stored version still equals favourite.Version
 	Update(favourite Favourite) (*Favourite, error)
-	Delete(id uuid.UUID) error
+	// Delete only moves the favourite to the trash while the stored version still equals version
+	Delete(id uuid.UUID, version int, deletedAt time.Time) error
+	// GetLastRank returns the rank of the favourite the user's listing by rank ends with, or "" when the user has none
+	GetLastRank(userId uuid.UUID) (string, error)
+	GetByIds(ids uuid.UUIDs) ([]Favourite, error)
+	GetTagCounts(userId uuid.UUID, prefix string, limit int) ([]TagCount, error)
+	GetTrashedByUserIdPaginated(userId uuid.UUID, deletedAfter time.Time, pageSize int, pageNumber int) ([]TrashedFavourite, utils.Pagination, error)
+	GetTrashedById(id uuid.UUID) (*TrashedFavourite, error)
+	// Restore returns the favourite the user has for the asset by now together with ErrFavouriteAlreadyExists
+	Restore(id uuid.UUID, restoredAt time.Time) (*Favourite, error)
+	Purge(id uuid.UUID) error
+	PurgeDeletedBefore(before time.Time) (int, error)
+	// WriteMany with atomic set keeps none of the writes when any fails, the others fail with ErrFavouriteBatchRolledBack
+	WriteMany(writes []FavouriteWrite, atomic bool) ([]FavouriteWriteResult, error)
 }
 
 type inMemoryDBFavouriteRepository struct {
@@ -29,12 +48,24 @@ func NewInMemoryDBFavouriteRepository(db *database.IMDatabase) *inMemoryDBFavour
 }
 
 func InMemoryDBFavouriteModelToDTO(model database.IMFavouriteModel) Favourite {
+	// Favourites stored before tags existed have none
+	tags := model.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+
 	return Favourite{
 		Id:          model.Id,
 		UserId:      model.UserId,
 		AssetId:     model.AssetId,
 		AssetType:   AssetType(model.AssetType),
 		Description: model.Description,
+		Version:     model.Version,
+		CreatedAt:   model.CreatedAt,
+		UpdatedAt:   model.UpdatedAt,
+		Rank:        model.Rank,
+		Pinned:      model.Pinned,
+		Tags:        tags,
 	}
 }
 
@@ -45,6 +76,39 @@ func DTOToInMemoryDBFavouriteModel(dto Favourite) database.IMFavouriteModel {
 		AssetId:     dto.AssetId,
 		AssetType:   string(dto.AssetType),
 		Description: dto.Description,
+		Version:     dto.Version,
+		CreatedAt:   dto.CreatedAt,
+		UpdatedAt:   dto.UpdatedAt,
+		Rank:        dto.Rank,
+		Pinned:      dto.Pinned,
+		Tags:        dto.Tags,
+	}
+}
+
+func InMemoryDBTrashedFavouriteModelToDTO(model database.IMTrashedFavouriteModel) TrashedFavourite {
+	return TrashedFavourite{
+		Favourite: InMemoryDBFavouriteModelToDTO(model.Favourite),
+		DeletedAt: model.DeletedAt,
+	}
+}
+
+var imFavouriteSortIndexes = map[FavouriteSort]string{
+	"":                         database.IMFavouritesByUserRankIndex,
+	FavouriteSortRank:          database.IMFavouritesByUserRankIndex,
+	FavouriteSortCreatedAt:     database.IMFavouritesByUserIndex,
+	FavouriteSortCreatedAtDesc: database.IMFavouritesByUserCreatedAtDescIndex,
+	FavouriteSortDescription:   database.IMFavouritesByUserDescriptionIndex,
+	FavouriteSortAssetType:     database.IMFavouritesByUserAssetTypeIndex,
+}
+
+// imFavouriteMatch returns the storage filter of the listing, or nil when it keeps every favourite.
+func imFavouriteMatch(options FavouriteListOptions) func(database.IMFavouriteModel) bool {
+	if !options.IsFiltered() {
+		return nil
+	}
+
+	return func(model database.IMFavouriteModel) bool {
+		return options.Matches(AssetType(model.AssetType), model.Description, model.Tags)
 	}
 }
 
@@ -59,33 +123,172 @@ func (repo *inMemoryDBFavouriteRepository) GetById(id uuid.UUID) (*Favourite, er
 	return &dto, nil
 }
 
-func (repo *inMemoryDBFavouriteRepository) GetByUserIdPaginated(userId uuid.UUID, pageSize int, pageNumber int) ([]Favourite, utils.Pagination, error) {
-	var result []Favourite
+func (repo *inMemoryDBFavouriteRepository) GetByUserIdPaginated(userId uuid.UUID, pageSize int, pageNumber int, options FavouriteListOptions) ([]Favourite, utils.Pagination, error) {
+	result := []Favourite{}
+
+	index, found := imFavouriteSortIndexes[options.Sort]
+	if !found {
+		return nil, utils.Pagination{}, ErrInvalidFavouriteSort
+	}
 
-	totalCount := 0
 	offset := pageSize * pageNumber
 
-	sortedFavs := slices.Collect(maps.Values(repo.DB.FavouriteStorage))
+	models, totalCount, err := repo.DB.FavouriteStorage.PageWhere(index, userId, offset, pageSize, imFavouriteMatch(options))
+	if err != nil {
+		return nil, utils.Pagination{}, err
+	}
 
-	sort.Slice(
-		sortedFavs,
-		func(i, j int) bool { return sortedFavs[i].Id.String() < sortedFavs[j].Id.String() },
-	)
+	for _, model := range models {
+		result = append(result, InMemoryDBFavouriteModelToDTO(model))
+	}
 
-	for _, fav := range sortedFavs {
-		if fav.UserId != userId {
-			continue
+	maxPage := utils.CalculateMaxPages(totalCount, pageSize)
+
+	return result, utils.Pagination{Page: pageNumber, PageSize: pageSize, MaxPage: maxPage}, nil
+}
+
+func (repo *inMemoryDBFavouriteRepository) GetByUserIdKeyset(userId uuid.UUID, pageSize int, cursor FavouriteCursor, before bool, options FavouriteListOptions) ([]Favourite, bool, error) {
+	index, found := imFavouriteSortIndexes[options.Sort]
+	if !found {
+		return nil, false, ErrInvalidFavouriteSort
+	}
+
+	position := database.IMFavouriteModel{
+		Id:          cursor.Id,
+		UserId:      userId,
+		AssetType:   string(cursor.AssetType),
+		Description: cursor.Description,
+		CreatedAt:   cursor.CreatedAt,
+		Rank:        cursor.Rank,
+		Pinned:      cursor.Pinned,
+	}
+
+	models, hasMore, err := repo.DB.FavouriteStorage.SeekWhere(index, userId, cursor.Id, position, pageSize, before, imFavouriteMatch(options))
+	if err != nil {
+		return nil, false, err
+	}
+
+	result := []Favourite{}
+	for _, model := range models {
+		result = append(result, InMemoryDBFavouriteModelToDTO(model))
+	}
+
+	return result, hasMore, nil
+}
+
+type imFavouriteWriter interface {
+	Insert(id uuid.UUID, v database.IMFavouriteModel) (database.IMFavouriteModel, error)
+	Update(id uuid.UUID, update func(current database.IMFavouriteModel) (database.IMFavouriteModel, error)) (database.IMFavouriteModel, error)
+	DeleteIf(id uuid.UUID, check func(current database.IMFavouriteModel) error) error
+}
+
+func (repo *inMemoryDBFavouriteRepository) GetLastRank(userId uuid.UUID) (string, error) {
+	model, _, err := repo.DB.FavouriteStorage.Last(database.IMFavouritesByUserRankIndex, userId)
+	if err != nil {
+		return "", err
+	}
+
+	return model.Rank, nil
+}
+
+// GetTagCounts reads the tag counts the storage keeps for every user, so the user's favourites are not visited.
+func (repo *inMemoryDBFavouriteRepository) GetTagCounts(userId uuid.UUID, prefix string, limit int) ([]TagCount, error) {
+	counts, err := repo.DB.FavouriteStorage.KeyCounts(database.IMFavouritesByUserTagIndex, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []TagCount{}
+	for tag, count := range counts {
+		if strings.HasPrefix(tag, prefix) {
+			result = append(result, TagCount{Tag: tag, Count: count})
+		}
+	}
+
+	slices.SortFunc(result, func(a, b TagCount) int {
+		if a.Count != b.Count {
+			return cmp.Compare(b.Count, a.Count)
 		}
 
-		totalCount++
+		return strings.Compare(a.Tag, b.Tag)
+	})
+
+	return result[:min(limit, len(result))], nil
+}
+
+func (repo *inMemoryDBFavouriteRepository) GetByIds(ids uuid.UUIDs) ([]Favourite, error) {
+	result := []Favourite{}
+	for _, model := range repo.DB.FavouriteStorage.GetMany(ids) {
+		result = append(result, InMemoryDBFavouriteModelToDTO(model))
+	}
+
+	return result, nil
+}
+
+func (repo *inMemoryDBFavouriteRepository) Create(favourite Favourite) (*Favourite, error) {
+	return imCreateFavourite(repo.DB.FavouriteStorage, favourite)
+}
+
+func (repo *inMemoryDBFavouriteRepository) Update(favourite Favourite) (*Favourite, error) {
+	return imUpdateFavourite(repo.DB.FavouriteStorage, favourite)
+}
+
+// Delete also takes the favourite out of every collection it is in, like the foreign key does in PostgreSQL.
+func (repo *inMemoryDBFavouriteRepository) Delete(id uuid.UUID, version int, deletedAt time.Time) error {
+	write := FavouriteWrite{Op: FavouriteWriteDelete, Favourite: Favourite{Id: id, Version: version}, DeletedAt: deletedAt}
+	results, err := repo.WriteMany([]FavouriteWrite{write}, true)
+	if err != nil {
+		return err
+	}
+
+	return results[0].Err
+}
 
-		if totalCount <= offset {
-			continue
+// WriteMany runs every batch as a storage batch, so its writes are journaled and made visible together.
+// A batch that is not atomic keeps the writes that succeeded.
+func (repo *inMemoryDBFavouriteRepository) WriteMany(writes []FavouriteWrite, atomic bool) ([]FavouriteWriteResult, error) {
+	var results []FavouriteWriteResult
+	trashedIds := uuid.UUIDs{}
+	err := repo.DB.FavouriteStorage.Batch(func(batch *database.IMBatch[database.IMFavouriteModel]) error {
+		var trashed []database.IMTrashedFavouriteModel
+		results, trashed = imWriteFavourites(batch, writes)
+		if atomic && HasFailedWrite(results) {
+			return ErrFavouriteBatchRolledBack
 		}
 
-		if len(result) != pageSize {
-			result = append(result, InMemoryDBFavouriteModelToDTO(fav))
+		// The trash is journaled before the batch, so a crash in between keeps a deleted favourite twice rather than losing it
+		for _, model := range trashed {
+			if err := repo.DB.TrashedFavouriteStorage.Set(model.Favourite.Id, model); err != nil {
+				return err
+			}
+			trashedIds = append(trashedIds, model.Favourite.Id)
 		}
+
+		return nil
+	})
+	if errors.Is(err, ErrFavouriteBatchRolledBack) {
+		RollBackFavouriteWrites(results)
+		return results, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return results, repo.DB.RemoveFavouritesFromCollections(trashedIds...)
+}
+
+func (repo *inMemoryDBFavouriteRepository) GetTrashedByUserIdPaginated(userId uuid.UUID, deletedAfter time.Time, pageSize int, pageNumber int) ([]TrashedFavourite, utils.Pagination, error) {
+	models, totalCount, err := repo.DB.TrashedFavouriteStorage.PageWhere(
+		database.IMTrashedFavouritesByUserIndex, userId, pageSize*pageNumber, pageSize,
+		func(model database.IMTrashedFavouriteModel) bool { return model.DeletedAt.After(deletedAfter) },
+	)
+	if err != nil {
+		return nil, utils.Pagination{}, err
+	}
+
+	result := []TrashedFavourite{}
+	for _, model := range models {
+		result = append(result, InMemoryDBTrashedFavouriteModelToDTO(model))
 	}
 
 	maxPage := utils.CalculateMaxPages(totalCount, pageSize)
@@ -93,23 +296,168 @@ func (repo *inMemoryDBFavouriteRepository) GetByUserIdPaginated(userId uuid.UUID
 	return result, utils.Pagination{Page: pageNumber, PageSize: pageSize, MaxPage: maxPage}, nil
 }
 
-func (repo *inMemoryDBFavouriteRepository) Create(favourite Favourite) (*Favourite, error) {
-	repo.DB.FavouriteStorage[favourite.Id] = DTOToInMemoryDBFavouriteModel(favourite)
-	return &favourite, nil
+func (repo *inMemoryDBFavouriteRepository) GetTrashedById(id uuid.UUID) (*TrashedFavourite, error) {
+	model, err := database.IMStorageGetById(id, repo.DB.TrashedFavouriteStorage)
+	if err != nil {
+		return nil, err
+	}
+
+	dto := InMemoryDBTrashedFavouriteModelToDTO(*model)
+
+	return &dto, nil
 }
 
-func (repo *inMemoryDBFavouriteRepository) Update(favourite Favourite) (*Favourite, error) {
-	repo.DB.FavouriteStorage[favourite.Id] = DTOToInMemoryDBFavouriteModel(favourite)
-	return &favourite, nil
+// Restore inserts the favourite back before taking it out of the trash, so a crash in between leaves a copy
+// in the trash that is purged when it expires, rather than losing the favourite.
+func (repo *inMemoryDBFavouriteRepository) Restore(id uuid.UUID, restoredAt time.Time) (*Favourite, error) {
+	trashed, found := repo.DB.TrashedFavouriteStorage.Get(id)
+	if !found {
+		return nil, ErrFavouriteNotFound
+	}
+
+	model := trashed.Favourite
+	model.Version++
+	model.UpdatedAt = restoredAt
+
+	existing, err := repo.DB.FavouriteStorage.Insert(id, model)
+	if errors.Is(err, database.ErrItemAlreadyExists) {
+		dto := InMemoryDBFavouriteModelToDTO(existing)
+		return &dto, ErrFavouriteAlreadyExists
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := repo.DB.TrashedFavouriteStorage.Delete(id); err != nil {
+		return nil, err
+	}
+
+	restored := InMemoryDBFavouriteModelToDTO(model)
+
+	return &restored, nil
 }
 
-func (repo *inMemoryDBFavouriteRepository) Delete(id uuid.UUID) error {
-	favourite, err := database.IMStorageGetById(id, repo.DB.FavouriteStorage)
+func (repo *inMemoryDBFavouriteRepository) Purge(id uuid.UUID) error {
+	deleted, err := repo.DB.TrashedFavouriteStorage.Delete(id)
 	if err != nil {
-		return ErrFavouriteNotFound
+		return err
 	}
 
-	delete(repo.DB.FavouriteStorage, favourite.Id)
+	if !deleted {
+		return ErrFavouriteNotFound
+	}
 
 	return nil
 }
+
+const imPurgeBatchSize = 100
+
+func (repo *inMemoryDBFavouriteRepository) PurgeDeletedBefore(before time.Time) (int, error) {
+	storage := repo.DB.TrashedFavouriteStorage
+	purged := 0
+
+	for {
+		models, _, err := storage.Page(database.IMTrashedFavouritesByDeletedAtIndex, uuid.Nil, 0, imPurgeBatchSize)
+		if err != nil {
+			return purged, err
+		}
+
+		for _, model := range models {
+			if !model.DeletedAt.Before(before) {
+				return purged, nil
+			}
+
+			deleted, err := storage.Delete(model.Favourite.Id)
+			if err != nil {
+				return purged, err
+			}
+			if deleted {
+				purged++
+			}
+		}
+
+		if len(models) < imPurgeBatchSize {
+			return purged, nil
+		}
+	}
+}
+
+// imWriteFavourites also returns the favourites the deletes removed, to be moved to the trash.
+func imWriteFavourites(writer imFavouriteWriter, writes []FavouriteWrite) ([]FavouriteWriteResult, []database.IMTrashedFavouriteModel) {
+	results := make([]FavouriteWriteResult, len(writes))
+	trashed := []database.IMTrashedFavouriteModel{}
+	for i, write := range writes {
+		switch write.Op {
+		case FavouriteWriteCreate:
+			results[i].Favourite, results[i].Err = imCreateFavourite(writer, write.Favourite)
+		case FavouriteWriteUpdate:
+			results[i].Favourite, results[i].Err = imUpdateFavourite(writer, write.Favourite)
+		case FavouriteWriteDelete:
+			var model database.IMFavouriteModel
+			model, results[i].Err = imDeleteFavourite(writer, write.Favourite.Id, write.Favourite.Version)
+			if results[i].Err == nil {
+				trashed = append(trashed, database.IMTrashedFavouriteModel{Favourite: model, DeletedAt: write.DeletedAt})
+			}
+		}
+	}
+
+	return results, trashed
+}
+
+func imCreateFavourite(writer imFavouriteWriter, favourite Favourite) (*Favourite, error) {
+	model, err := writer.Insert(favourite.Id, DTOToInMemoryDBFavouriteModel(favourite))
+	if err != nil {
+		if errors.Is(err, database.ErrItemAlreadyExists) {
+			existing := InMemoryDBFavouriteModelToDTO(model)
+			return &existing, ErrFavouriteAlreadyExists
+		}
+
+		return nil, err
+	}
+
+	return &favourite, nil
+}
+
+func imUpdateFavourite(writer imFavouriteWriter, favourite Favourite) (*Favourite, error) {
+	model, err := writer.Update(
+		favourite.Id,
+		func(current database.IMFavouriteModel) (database.IMFavouriteModel, error) {
+			if current.Version != favourite.Version {
+				return current, ErrFavouriteVersionMismatch
+			}
+
+			updated := DTOToInMemoryDBFavouriteModel(favourite)
+			updated.Version++
+
+			return updated, nil
+		},
+	)
+	if err != nil {
+		if errors.Is(err, database.ErrItemNotFound) {
+			return nil, ErrFavouriteNotFound
+		}
+
+		return nil, err
+	}
+
+	updated := InMemoryDBFavouriteModelToDTO(model)
+
+	return &updated, nil
+}
+
+func imDeleteFavourite(writer imFavouriteWriter, id uuid.UUID, version int) (database.IMFavouriteModel, error) {
+	var deleted database.IMFavouriteModel
+	err := writer.DeleteIf(id, func(current database.IMFavouriteModel) error {
+		if current.Version != version {
+			return ErrFavouriteVersionMismatch
+		}
+
+		deleted = current
+		return nil
+	})
+	if errors.Is(err, database.ErrItemNotFound) {
+		return deleted, ErrFavouriteNotFound
+	}
+
+	return deleted, err
+}
@@ -0,0 +1,627 @@
+package favourite
+
+import (
+	"context"
+	"errors"
+	"platform-go-challenge/internal/database"
+	"platform-go-challenge/internal/utils"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type postgresDBFavouriteRepository struct {
+	DB *pgxpool.Pool
+}
+
+func NewPostgresDBFavouriteRepository(db *pgxpool.Pool) *postgresDBFavouriteRepository {
+	return &postgresDBFavouriteRepository{
+		DB: db,
+	}
+}
+
+const pgFavouriteColumns = "id, user_id, asset_id, asset_type, description, version, created_at, updated_at, rank, pinned, tags"
+
+// pgFavouriteOrder describes how a listing order walks its index, ties are always ordered by ascending id.
+type pgFavouriteOrder struct {
+	key        string
+	descending bool
+	keyValue   func(cursor FavouriteCursor) any
+}
+
+// pgFavouriteOrders maps every listing order to the key of its index.
+var pgFavouriteOrders = map[FavouriteSort]pgFavouriteOrder{
+	"":                         {key: `position COLLATE "C"`, keyValue: pgFavouritePosition},
+	FavouriteSortRank:          {key: `position COLLATE "C"`, keyValue: pgFavouritePosition},
+	FavouriteSortCreatedAt:     {key: "created_at", keyValue: func(cursor FavouriteCursor) any { return cursor.CreatedAt }},
+	FavouriteSortCreatedAtDesc: {key: "created_at", descending: true, keyValue: func(cursor FavouriteCursor) any { return cursor.CreatedAt }},
+	FavouriteSortDescription:   {key: `description COLLATE "C"`, keyValue: func(cursor FavouriteCursor) any { return cursor.Description }},
+	FavouriteSortAssetType:     {key: `asset_type COLLATE "C"`, keyValue: func(cursor FavouriteCursor) any { return string(cursor.AssetType) }},
+}
+
+// pgFavouritePosition is the value of the generated position column at the cursor, see migration 0005.
+func pgFavouritePosition(cursor FavouriteCursor) any {
+	if cursor.Pinned {
+		return "0" + cursor.Rank
+	}
+
+	return "1" + cursor.Rank
+}
+
+// orderBy returns the ORDER BY of the listing, or of the listing walked backwards when reversed is set.
+func (order pgFavouriteOrder) orderBy(reversed bool) string {
+	keyDirection, idDirection := "", ""
+	if order.descending != reversed {
+		keyDirection = " DESC"
+	}
+	if reversed {
+		idDirection = " DESC"
+	}
+
+	return order.key + keyDirection + ", id" + idDirection
+}
+
+// seekCondition matches the rows after the cursor (key and id are the placeholders of its values), or before it when reversed is set.
+// The redundant bound on the key alone lets the index range scan start at the cursor.
+func (order pgFavouriteOrder) seekCondition(reversed bool, key string, id string) string {
+	keyComparison, idComparison := ">", ">"
+	if order.descending != reversed {
+		keyComparison = "<"
+	}
+	if reversed {
+		idComparison = "<"
+	}
+
+	return order.key + " " + keyComparison + "= " + key + " AND (" + order.key + " " + keyComparison + " " + key + " OR id " + idComparison + " " + id + ")"
+}
+
+// pgParam adds a query parameter to args and returns its placeholder.
+func pgParam(args *[]any, value any) string {
+	*args = append(*args, value)
+
+	return "$" + strconv.Itoa(len(*args))
+}
+
+// pgFavouriteFilter returns the WHERE condition of a listing of the user's favourites and adds its parameters to args.
+func pgFavouriteFilter(userId uuid.UUID, options FavouriteListOptions, args *[]any) string {
+	user := pgParam(args, userId)
+	condition := "user_id = " + user
+
+	if len(options.AssetTypes) > 0 {
+		assetTypes := []string{}
+		for _, assetType := range options.AssetTypes {
+			assetTypes = append(assetTypes, string(assetType))
+		}
+		condition += " AND asset_type = ANY(" + pgParam(args, assetTypes) + ")"
+	}
+
+	// strpos instead of ILIKE, so the wildcards of LIKE in the query are matched as they are
+	if options.Query != "" {
+		condition += " AND strpos(lower(description), lower(" + pgParam(args, options.Query) + ")) > 0"
+	}
+
+	// The tags are looked up in the user's part of favourite_tags, see migration 0007
+	if len(options.Tags) > 0 {
+		tags := pgParam(args, options.Tags)
+		tagged := "SELECT favourite_id FROM favourite_tags WHERE user_id = " + user + " AND tag = ANY(" + tags + ")"
+		if options.TagMatch != TagMatchAny {
+			tagged += " GROUP BY favourite_id HAVING count(*) = cardinality(" + tags + "::text[])"
+		}
+		condition += " AND id IN (" + tagged + ")"
+	}
+
+	return condition
+}
+
+func pgScanFavourite(row pgx.CollectableRow) (Favourite, error) {
+	var favourite Favourite
+	err := row.Scan(
+		&favourite.Id,
+		&favourite.UserId,
+		&favourite.AssetId,
+		&favourite.AssetType,
+		&favourite.Description,
+		&favourite.Version,
+		&favourite.CreatedAt,
+		&favourite.UpdatedAt,
+		&favourite.Rank,
+		&favourite.Pinned,
+		&favourite.Tags,
+	)
+
+	// Timestamps are scanned in the local time zone, the domain keeps them in UTC
+	favourite.CreatedAt = favourite.CreatedAt.UTC()
+	favourite.UpdatedAt = favourite.UpdatedAt.UTC()
+	favourite.Tags = pgTags(favourite.Tags)
+
+	return favourite, err
+}
+
+func (repo *postgresDBFavouriteRepository) GetById(id uuid.UUID) (*Favourite, error) {
+	rows, err := repo.DB.Query(
+		context.Background(),
+		"SELECT "+pgFavouriteColumns+" FROM favourites WHERE id = $1",
+		id,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	favourite, err := pgx.CollectExactlyOneRow(rows, pgScanFavourite)
+	if err != nil {
+		return nil, database.PGItemNotFound(err)
+	}
+
+	return &favourite, nil
+}
+
+// GetByUserIdPaginated walks the user_id index of the sort for the page and counts the matching rows separately,
+// so an offset past the end still reports the right number of pages.
+func (repo *postgresDBFavouriteRepository) GetByUserIdPaginated(userId uuid.UUID, pageSize int, pageNumber int, options FavouriteListOptions) ([]Favourite, utils.Pagination, error) {
+	order, found := pgFavouriteOrders[options.Sort]
+	if !found {
+		return nil, utils.Pagination{}, ErrInvalidFavouriteSort
+	}
+
+	ctx := context.Background()
+	offset := pageSize * pageNumber
+
+	args := []any{}
+	condition := pgFavouriteFilter(userId, options, &args)
+
+	var totalCount int
+	err := repo.DB.QueryRow(ctx, "SELECT count(*) FROM favourites WHERE "+condition, args...).Scan(&totalCount)
+	if err != nil {
+		return nil, utils.Pagination{}, err
+	}
+
+	rows, err := repo.DB.Query(
+		ctx,
+		"SELECT "+pgFavouriteColumns+" FROM favourites WHERE "+condition+
+			" ORDER BY "+order.orderBy(false)+" LIMIT "+pgParam(&args, pageSize)+" OFFSET "+pgParam(&args, offset),
+		args...,
+	)
+	if err != nil {
+		return nil, utils.Pagination{}, err
+	}
+
+	result, err := pgx.CollectRows(rows, pgScanFavourite)
+	if err != nil {
+		return nil, utils.Pagination{}, err
+	}
+
+	if result == nil {
+		result = []Favourite{}
+	}
+
+	maxPage := utils.CalculateMaxPages(totalCount, pageSize)
+
+	return result, utils.Pagination{Page: pageNumber, PageSize: pageSize, MaxPage: maxPage}, nil
+}
+
+// GetByUserIdKeyset reads one row more than the page to tell whether there are more rows further on,
+// and walks the index backwards for the rows before the cursor.
+func (repo *postgresDBFavouriteRepository) GetByUserIdKeyset(userId uuid.UUID, pageSize int, cursor FavouriteCursor, before bool, options FavouriteListOptions) ([]Favourite, bool, error) {
+	order, found := pgFavouriteOrders[options.Sort]
+	if !found {
+		return nil, false, ErrInvalidFavouriteSort
+	}
+
+	args := []any{}
+	condition := pgFavouriteFilter(userId, options, &args)
+	seek := order.seekCondition(before, pgParam(&args, order.keyValue(cursor)), pgParam(&args, cursor.Id))
+
+	rows, err := repo.DB.Query(
+		context.Background(),
+		"SELECT "+pgFavouriteColumns+" FROM favourites WHERE "+condition+" AND "+seek+
+			" ORDER BY "+order.orderBy(before)+" LIMIT "+pgParam(&args, pageSize+1),
+		args...,
+	)
+	if err != nil {
+		return nil, false, err
+	}
+
+	result, err := pgx.CollectRows(rows, pgScanFavourite)
+	if err != nil {
+		return nil, false, err
+	}
+
+	hasMore := len(result) > pageSize
+	if hasMore {
+		result = result[:pageSize]
+	}
+
+	if before {
+		slices.Reverse(result)
+	}
+
+	if result == nil {
+		result = []Favourite{}
+	}
+
+	return result, hasMore, nil
+}
+
+// pgQuerier is what the writes need from the pool, or from a transaction of it.
+type pgQuerier interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+func (repo *postgresDBFavouriteRepository) GetLastRank(userId uuid.UUID) (string, error) {
+	var rank string
+	err := repo.DB.QueryRow(
+		context.Background(),
+		`SELECT rank FROM favourites WHERE user_id = $1 ORDER BY position COLLATE "C" DESC, id DESC LIMIT 1`,
+		userId,
+	).Scan(&rank)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", nil
+	}
+
+	return rank, err
+}
+
+func (repo *postgresDBFavouriteRepository) GetTagCounts(userId uuid.UUID, prefix string, limit int) ([]TagCount, error) {
+	rows, err := repo.DB.Query(
+		context.Background(),
+		"SELECT tag, count(*) FROM favourite_tags WHERE user_id = $1 AND starts_with(tag, $2)"+
+			" GROUP BY tag ORDER BY count(*) DESC, tag LIMIT $3",
+		userId, prefix, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (TagCount, error) {
+		var tagCount TagCount
+		err := row.Scan(&tagCount.Tag, &tagCount.Count)
+
+		return tagCount, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if result == nil {
+		result = []TagCount{}
+	}
+
+	return result, nil
+}
+
+func (repo *postgresDBFavouriteRepository) GetByIds(ids uuid.UUIDs) ([]Favourite, error) {
+	rows, err := repo.DB.Query(
+		context.Background(),
+		"SELECT "+pgFavouriteColumns+" FROM favourites WHERE id = ANY($1::uuid[]) ORDER BY array_position($1::uuid[], id)",
+		ids.Strings(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, pgScanFavourite)
+}
+
+func (repo *postgresDBFavouriteRepository) Create(favourite Favourite) (*Favourite, error) {
+	return pgCreateFavourite(repo.DB, favourite)
+}
+
+// Update bumps the version in the same statement that checks it, so only one of concurrent writers of a version wins.
+func (repo *postgresDBFavouriteRepository) Update(favourite Favourite) (*Favourite, error) {
+	return pgUpdateFavourite(repo.DB, favourite)
+}
+
+// Delete moves the row to trashed_favourites in one statement, the foreign keys take it out of its collections and tags.
+func (repo *postgresDBFavouriteRepository) Delete(id uuid.UUID, version int, deletedAt time.Time) error {
+	return pgDeleteFavourite(repo.DB, id, version, deletedAt)
+}
+
+func pgScanTrashedFavourite(row pgx.CollectableRow) (TrashedFavourite, error) {
+	var trashed TrashedFavourite
+	err := row.Scan(
+		&trashed.Id,
+		&trashed.UserId,
+		&trashed.AssetId,
+		&trashed.AssetType,
+		&trashed.Description,
+		&trashed.Version,
+		&trashed.CreatedAt,
+		&trashed.UpdatedAt,
+		&trashed.Rank,
+		&trashed.Pinned,
+		&trashed.Tags,
+		&trashed.DeletedAt,
+	)
+
+	trashed.CreatedAt = trashed.CreatedAt.UTC()
+	trashed.UpdatedAt = trashed.UpdatedAt.UTC()
+	trashed.DeletedAt = trashed.DeletedAt.UTC()
+	trashed.Tags = pgTags(trashed.Tags)
+
+	return trashed, err
+}
+
+func (repo *postgresDBFavouriteRepository) GetTrashedByUserIdPaginated(userId uuid.UUID, deletedAfter time.Time, pageSize int, pageNumber int) ([]TrashedFavourite, utils.Pagination, error) {
+	ctx := context.Background()
+
+	var totalCount int
+	err := repo.DB.QueryRow(
+		ctx,
+		"SELECT count(*) FROM trashed_favourites WHERE user_id = $1 AND deleted_at > $2",
+		userId, deletedAfter,
+	).Scan(&totalCount)
+	if err != nil {
+		return nil, utils.Pagination{}, err
+	}
+
+	rows, err := repo.DB.Query(
+		ctx,
+		"SELECT "+pgFavouriteColumns+", deleted_at FROM trashed_favourites WHERE user_id = $1 AND deleted_at > $2"+
+			" ORDER BY deleted_at DESC, id LIMIT $3 OFFSET $4",
+		userId, deletedAfter, pageSize, pageSize*pageNumber,
+	)
+	if err != nil {
+		return nil, utils.Pagination{}, err
+	}
+
+	result, err := pgx.CollectRows(rows, pgScanTrashedFavourite)
+	if err != nil {
+		return nil, utils.Pagination{}, err
+	}
+
+	if result == nil {
+		result = []TrashedFavourite{}
+	}
+
+	maxPage := utils.CalculateMaxPages(totalCount, pageSize)
+
+	return result, utils.Pagination{Page: pageNumber, PageSize: pageSize, MaxPage: maxPage}, nil
+}
+
+func (repo *postgresDBFavouriteRepository) GetTrashedById(id uuid.UUID) (*TrashedFavourite, error) {
+	rows, err := repo.DB.Query(
+		context.Background(),
+		"SELECT "+pgFavouriteColumns+", deleted_at FROM trashed_favourites WHERE id = $1",
+		id,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	trashed, err := pgx.CollectExactlyOneRow(rows, pgScanTrashedFavourite)
+	if err != nil {
+		return nil, database.PGItemNotFound(err)
+	}
+
+	return &trashed, nil
+}
+
+// Restore locks the trashed row first, so of concurrent restores of the same favourite only one finds it.
+// The favourite the user has for the asset by now is read in the same transaction, which is then rolled back.
+func (repo *postgresDBFavouriteRepository) Restore(id uuid.UUID, restoredAt time.Time) (*Favourite, error) {
+	ctx := context.Background()
+
+	tx, err := repo.DB.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, "SELECT "+pgFavouriteColumns+" FROM trashed_favourites WHERE id = $1 FOR UPDATE", id)
+	if err != nil {
+		return nil, err
+	}
+
+	favourite, err := pgx.CollectExactlyOneRow(rows, pgScanFavourite)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrFavouriteNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	favourite.Version++
+	favourite.UpdatedAt = restoredAt
+
+	restored, err := pgCreateFavourite(tx, favourite)
+	if err != nil {
+		return restored, err
+	}
+
+	if _, err := tx.Exec(ctx, "DELETE FROM trashed_favourites WHERE id = $1", id); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return restored, nil
+}
+
+func (repo *postgresDBFavouriteRepository) Purge(id uuid.UUID) error {
+	tag, err := repo.DB.Exec(context.Background(), "DELETE FROM trashed_favourites WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return ErrFavouriteNotFound
+	}
+
+	return nil
+}
+
+func (repo *postgresDBFavouriteRepository) PurgeDeletedBefore(before time.Time) (int, error) {
+	tag, err := repo.DB.Exec(context.Background(), "DELETE FROM trashed_favourites WHERE deleted_at < $1", before)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(tag.RowsAffected()), nil
+}
+
+// WriteMany runs an atomic batch in a transaction that is only committed when every write succeeded.
+// None of the writes raises an error for a failed item, so the transaction stays usable after one.
+func (repo *postgresDBFavouriteRepository) WriteMany(writes []FavouriteWrite, atomic bool) ([]FavouriteWriteResult, error) {
+	if !atomic {
+		return pgWriteFavourites(repo.DB, writes), nil
+	}
+
+	ctx := context.Background()
+
+	tx, err := repo.DB.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	results := pgWriteFavourites(tx, writes)
+	if HasFailedWrite(results) {
+		if err := tx.Rollback(ctx); err != nil {
+			return nil, err
+		}
+
+		RollBackFavouriteWrites(results)
+		return results, nil
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+func pgWriteFavourites(db pgQuerier, writes []FavouriteWrite) []FavouriteWriteResult {
+	results := make([]FavouriteWriteResult, len(writes))
+	for i, write := range writes {
+		switch write.Op {
+		case FavouriteWriteCreate:
+			results[i].Favourite, results[i].Err = pgCreateFavourite(db, write.Favourite)
+		case FavouriteWriteUpdate:
+			results[i].Favourite, results[i].Err = pgUpdateFavourite(db, write.Favourite)
+		case FavouriteWriteDelete:
+			results[i].Err = pgDeleteFavourite(db, write.Favourite.Id, write.Favourite.Version, write.DeletedAt)
+		}
+	}
+
+	return results
+}
+
+// pgCreateAttempts bounds the retries when the conflicting favourite is deleted between the insert and the lookup.
+const pgCreateAttempts = 3
+
+func pgCreateFavourite(db pgQuerier, favourite Favourite) (*Favourite, error) {
+	ctx := context.Background()
+
+	for range pgCreateAttempts {
+		tag, err := db.Exec(
+			ctx,
+			"INSERT INTO favourites ("+pgFavouriteColumns+") VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) ON CONFLICT (user_id, asset_id) DO NOTHING",
+			favourite.Id, favourite.UserId, favourite.AssetId, favourite.AssetType, favourite.Description, favourite.Version,
+			favourite.CreatedAt, favourite.UpdatedAt, favourite.Rank, favourite.Pinned, pgTags(favourite.Tags),
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if tag.RowsAffected() == 1 {
+			return &favourite, nil
+		}
+
+		rows, err := db.Query(
+			ctx,
+			"SELECT "+pgFavouriteColumns+" FROM favourites WHERE user_id = $1 AND asset_id = $2",
+			favourite.UserId, favourite.AssetId,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		existing, err := pgx.CollectExactlyOneRow(rows, pgScanFavourite)
+		if errors.Is(err, pgx.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		return &existing, ErrFavouriteAlreadyExists
+	}
+
+	return nil, ErrCouldNotSaveFavourite
+}
+
+// pgTags turns missing tags into an empty list, which the column requires and the domain returns for no tags.
+func pgTags(tags []string) []string {
+	if tags == nil {
+		return []string{}
+	}
+
+	return tags
+}
+
+func pgUpdateFavourite(db pgQuerier, favourite Favourite) (*Favourite, error) {
+	rows, err := db.Query(
+		context.Background(),
+		"UPDATE favourites SET user_id = $2, asset_id = $3, asset_type = $4, description = $5, updated_at = $7, rank = $8, pinned = $9,"+
+			" tags = $10, version = version + 1 WHERE id = $1 AND version = $6 RETURNING "+pgFavouriteColumns,
+		favourite.Id, favourite.UserId, favourite.AssetId, favourite.AssetType, favourite.Description, favourite.Version,
+		favourite.UpdatedAt, favourite.Rank, favourite.Pinned, pgTags(favourite.Tags),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := pgx.CollectExactlyOneRow(rows, pgScanFavourite)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, pgMissedVersionError(db, favourite.Id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &updated, nil
+}
+
+func pgDeleteFavourite(db pgQuerier, id uuid.UUID, version int, deletedAt time.Time) error {
+	tag, err := db.Exec(
+		context.Background(),
+		"WITH deleted AS (DELETE FROM favourites WHERE id = $1 AND version = $2 RETURNING "+pgFavouriteColumns+")"+
+			" INSERT INTO trashed_favourites ("+pgFavouriteColumns+", deleted_at) SELECT "+pgFavouriteColumns+", $3 FROM deleted",
+		id, version, deletedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return pgMissedVersionError(db, id)
+	}
+
+	return nil
+}
+
+// pgMissedVersionError tells apart a write that matched no row because the favourite is gone
+// from one that matched no row because its version moved on.
+func pgMissedVersionError(db pgQuerier, id uuid.UUID) error {
+	var exists bool
+	err := db.QueryRow(context.Background(), "SELECT EXISTS (SELECT 1 FROM favourites WHERE id = $1)", id).Scan(&exists)
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		return ErrFavouriteNotFound
+	}
+
+	return ErrFavouriteVersionMismatch
+}
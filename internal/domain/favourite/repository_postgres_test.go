@@ -0,0 +1,14 @@
+package favourite_test
+
+import (
+	"platform-go-challenge/internal/domain/favourite"
+	"platform-go-challenge/test"
+	"platform-go-challenge/test/conformance"
+	"testing"
+)
+
+func TestPostgresDBFavouriteRepositoryConformance(t *testing.T) {
+	conformance.FavouriteRepository(t, func(t *testing.T) favourite.FavouriteRepository {
+		return favourite.NewPostgresDBFavouriteRepository(test.PostgresPool(t))
+	})
+}
@@ -1,12 +1,18 @@
 package favourite_test
 
 import (
+	"os"
+	"path/filepath"
 	"platform-go-challenge/internal/database"
 	"platform-go-challenge/internal/domain/favourite"
+	"platform-go-challenge/test/conformance"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestGetByUserIdPaginated(t *testing.T) {
@@ -31,14 +37,14 @@ func TestGetByUserIdPaginated(t *testing.T) {
 		storage := map[uuid.UUID]database.IMFavouriteModel{
 			fav1.Id: fav1, fav2.Id: fav2, fav3.Id: fav3, fav4.Id: fav4,
 		}
-		repo := favourite.NewInMemoryDBFavouriteRepository(&database.IMDatabase{FavouriteStorage: storage})
+		repo := favourite.NewInMemoryDBFavouriteRepository(&database.IMDatabase{FavouriteStorage: database.NewFavouriteStorage(storage)})
 		pageSize := 2
 		pageNumber := 0
 		expectedItemsLen := 2
 		expectedMaxPage := 1
 
 		// Act
-		result, pagination, err := repo.GetByUserIdPaginated(user1, pageSize, pageNumber)
+		result, pagination, err := repo.GetByUserIdPaginated(user1, pageSize, pageNumber, favourite.FavouriteListOptions{})
 
 		// Assert
 		assert.NoError(t, err)
@@ -56,14 +62,14 @@ func TestGetByUserIdPaginated(t *testing.T) {
 		storage := map[uuid.UUID]database.IMFavouriteModel{
 			fav1.Id: fav1, fav2.Id: fav2, fav3.Id: fav3, fav4.Id: fav4,
 		}
-		repo := favourite.NewInMemoryDBFavouriteRepository(&database.IMDatabase{FavouriteStorage: storage})
+		repo := favourite.NewInMemoryDBFavouriteRepository(&database.IMDatabase{FavouriteStorage: database.NewFavouriteStorage(storage)})
 		pageSize := 2
 		pageNumber := 1
 		expectedItemsLen := 1
 		expectedMaxPage := 1
 
 		// Act
-		result, pagination, err := repo.GetByUserIdPaginated(user1, pageSize, pageNumber)
+		result, pagination, err := repo.GetByUserIdPaginated(user1, pageSize, pageNumber, favourite.FavouriteListOptions{})
 
 		// Assert
 		assert.NoError(t, err)
@@ -76,15 +82,33 @@ func TestGetByUserIdPaginated(t *testing.T) {
 		}
 	})
 
+	t.Run("should return favourites of a user ordered by id", func(t *testing.T) {
+		// Arrange
+		storage := map[uuid.UUID]database.IMFavouriteModel{
+			fav1.Id: fav1, fav2.Id: fav2, fav3.Id: fav3, fav4.Id: fav4,
+		}
+		repo := favourite.NewInMemoryDBFavouriteRepository(&database.IMDatabase{FavouriteStorage: database.NewFavouriteStorage(storage)})
+
+		// Act
+		result, _, err := repo.GetByUserIdPaginated(user1, 10, 0, favourite.FavouriteListOptions{})
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Len(t, result, 3)
+		for i := 1; i < len(result); i++ {
+			assert.Less(t, result[i-1].Id.String(), result[i].Id.String())
+		}
+	})
+
 	t.Run("should return empty slice when page number is out of range", func(t *testing.T) {
 		// Arrange
 		storage := map[uuid.UUID]database.IMFavouriteModel{fav1.Id: fav1}
-		repo := favourite.NewInMemoryDBFavouriteRepository(&database.IMDatabase{FavouriteStorage: storage})
+		repo := favourite.NewInMemoryDBFavouriteRepository(&database.IMDatabase{FavouriteStorage: database.NewFavouriteStorage(storage)})
 		pageSize := 1
 		pageNumber := 5
 
 		// Act
-		result, pagination, err := repo.GetByUserIdPaginated(user1, pageSize, pageNumber)
+		result, pagination, err := repo.GetByUserIdPaginated(user1, pageSize, pageNumber, favourite.FavouriteListOptions{})
 
 		// Assert
 		assert.NoError(t, err)
@@ -97,11 +121,11 @@ func TestGetByUserIdPaginated(t *testing.T) {
 	t.Run("should return empty slice when user has no favourites", func(t *testing.T) {
 		// Arrange
 		repo := favourite.NewInMemoryDBFavouriteRepository(&database.IMDatabase{
-			FavouriteStorage: map[uuid.UUID]database.IMFavouriteModel{},
+			FavouriteStorage: database.NewFavouriteStorage(map[uuid.UUID]database.IMFavouriteModel{}),
 		})
 
 		// Act
-		result, pagination, err := repo.GetByUserIdPaginated(user1, 10, 0)
+		result, pagination, err := repo.GetByUserIdPaginated(user1, 10, 0, favourite.FavouriteListOptions{})
 
 		// Assert
 		assert.NoError(t, err)
@@ -113,7 +137,7 @@ func TestGetByUserIdPaginated(t *testing.T) {
 func TestCreate(t *testing.T) {
 	t.Run("should store favourite in memory database", func(t *testing.T) {
 		// Arrange
-		db := &database.IMDatabase{FavouriteStorage: make(map[uuid.UUID]database.IMFavouriteModel)}
+		db := &database.IMDatabase{FavouriteStorage: database.NewFavouriteStorage(nil)}
 		repo := favourite.NewInMemoryDBFavouriteRepository(db)
 
 		newFav := favourite.Favourite{
@@ -128,7 +152,7 @@ func TestCreate(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Equal(t, &newFav, created)
 
-		stored, ok := db.FavouriteStorage[newFav.Id]
+		stored, ok := db.FavouriteStorage.Get(newFav.Id)
 		assert.True(t, ok)
 		assert.Equal(t, newFav.Id, stored.Id)
 		assert.Equal(t, newFav.UserId, stored.UserId)
@@ -157,7 +181,7 @@ func TestUpdate(t *testing.T) {
 			Description: existingFavourite.Description,
 		}
 
-		db := &database.IMDatabase{FavouriteStorage: map[uuid.UUID]database.IMFavouriteModel{existingFavouriteModel.Id: existingFavouriteModel}}
+		db := &database.IMDatabase{FavouriteStorage: database.NewFavouriteStorage(map[uuid.UUID]database.IMFavouriteModel{existingFavouriteModel.Id: existingFavouriteModel})}
 		repo := favourite.NewInMemoryDBFavouriteRepository(db)
 
 		updatedFavourite := existingFavourite
@@ -169,7 +193,7 @@ func TestUpdate(t *testing.T) {
 		// Assert
 		assert.NoError(t, err)
 
-		stored, ok := db.FavouriteStorage[existingFavourite.Id]
+		stored, ok := db.FavouriteStorage.Get(existingFavourite.Id)
 		assert.True(t, ok)
 		assert.Equal(t, updatedFavourite.Id, stored.Id)
 		assert.Equal(t, updatedFavourite.UserId, stored.UserId)
@@ -180,7 +204,7 @@ func TestUpdate(t *testing.T) {
 }
 
 func TestDelete(t *testing.T) {
-	t.Run("should delete favourite in memory database", func(t *testing.T) {
+	t.Run("should move favourite to the trash in memory database", func(t *testing.T) {
 		// Arrange
 		model := database.IMFavouriteModel{
 			Id:          uuid.New(),
@@ -190,16 +214,53 @@ func TestDelete(t *testing.T) {
 			Description: "created fav",
 		}
 
-		db := &database.IMDatabase{FavouriteStorage: map[uuid.UUID]database.IMFavouriteModel{model.Id: model}}
+		db := &database.IMDatabase{
+			FavouriteStorage:        database.NewFavouriteStorage(map[uuid.UUID]database.IMFavouriteModel{model.Id: model}),
+			TrashedFavouriteStorage: database.NewTrashedFavouriteStorage(nil),
+		}
 		repo := favourite.NewInMemoryDBFavouriteRepository(db)
+		deletedAt := time.Date(2025, time.February, 1, 0, 0, 0, 0, time.UTC)
 
 		// Act
-		err := repo.Delete(model.Id)
+		err := repo.Delete(model.Id, model.Version, deletedAt)
 
 		// Assert
-		_, ok := db.FavouriteStorage[model.Id]
+		_, ok := db.FavouriteStorage.Get(model.Id)
 		assert.False(t, ok)
 		assert.NoError(t, err)
+		trashed, found := db.TrashedFavouriteStorage.Get(model.Id)
+		assert.True(t, found)
+		assert.Equal(t, database.IMTrashedFavouriteModel{Favourite: model, DeletedAt: deletedAt}, trashed)
+	})
+	t.Run("should keep a deleted favourite in the trash when the delete is lost in a crash", func(t *testing.T) {
+		// Arrange
+		dir := t.TempDir()
+		model := database.IMFavouriteModel{Id: uuid.New(), UserId: uuid.New(), AssetId: uuid.New(), AssetType: "chart", Version: 1}
+		db, err := database.NewPersistentIMDatabase(dir)
+		require.NoError(t, err)
+		require.NoError(t, db.FavouriteStorage.Set(model.Id, model))
+		require.NoError(t, favourite.NewInMemoryDBFavouriteRepository(db).Delete(model.Id, model.Version, time.Now()))
+		require.NoError(t, db.Close())
+
+		// Simulate a crash in the middle of journaling the delete, the last record of the log
+		segments, err := filepath.Glob(filepath.Join(dir, "wal-*.log"))
+		require.NoError(t, err)
+		path := segments[len(segments)-1]
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		data[len(data)-2] ^= 0xff
+		require.NoError(t, os.WriteFile(path, data, 0o644))
+
+		// Act
+		reopened, err := database.NewPersistentIMDatabase(dir)
+		require.NoError(t, err)
+		defer reopened.Close()
+
+		// Assert
+		_, found := reopened.FavouriteStorage.Get(model.Id)
+		assert.True(t, found)
+		_, found = reopened.TrashedFavouriteStorage.Get(model.Id)
+		assert.True(t, found)
 	})
 	t.Run("should return err when favourite not foudnd in memory database", func(t *testing.T) {
 		// Arrange
@@ -211,13 +272,66 @@ func TestDelete(t *testing.T) {
 			Description: "created fav",
 		}
 
-		db := &database.IMDatabase{FavouriteStorage: map[uuid.UUID]database.IMFavouriteModel{model.Id: model}}
+		db := &database.IMDatabase{FavouriteStorage: database.NewFavouriteStorage(map[uuid.UUID]database.IMFavouriteModel{model.Id: model})}
 		repo := favourite.NewInMemoryDBFavouriteRepository(db)
 
 		// Act
-		err := repo.Delete(uuid.New())
+		err := repo.Delete(uuid.New(), 0, time.Now())
 
 		// Assert
 		assert.Equal(t, favourite.ErrFavouriteNotFound, err)
 	})
 }
+
+func TestConcurrentRepositoryAccess(t *testing.T) {
+	t.Run("should handle parallel create, update, delete and list", func(t *testing.T) {
+		// Arrange
+		db := database.NewIMDatabase()
+		repo := favourite.NewInMemoryDBFavouriteRepository(db)
+		userId := uuid.New()
+		workers := 8
+		iterations := 50
+
+		var wg sync.WaitGroup
+
+		// Act
+		for w := 0; w < workers; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				for i := 0; i < iterations; i++ {
+					fav := favourite.Favourite{
+						Id: uuid.New(), UserId: userId, AssetId: uuid.New(),
+						AssetType: favourite.AssetTypeChart, Description: "created",
+					}
+
+					_, err := repo.Create(fav)
+					assert.NoError(t, err)
+
+					fav.Description = "updated"
+					updated, err := repo.Update(fav)
+					assert.NoError(t, err)
+
+					_, _, err = repo.GetByUserIdPaginated(userId, 10, 0, favourite.FavouriteListOptions{})
+					assert.NoError(t, err)
+
+					if i%2 == 0 {
+						assert.NoError(t, repo.Delete(fav.Id, updated.Version, time.Now()))
+					}
+				}
+			}()
+		}
+
+		wg.Wait()
+
+		// Assert
+		assert.Equal(t, workers*iterations/2, db.FavouriteStorage.Len())
+	})
+}
+
+func TestInMemoryDBFavouriteRepositoryConformance(t *testing.T) {
+	conformance.FavouriteRepository(t, func(t *testing.T) favourite.FavouriteRepository {
+		return favourite.NewInMemoryDBFavouriteRepository(database.NewIMDatabase())
+	})
+}
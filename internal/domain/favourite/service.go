@@ -2,29 +2,43 @@ package favourite
 
 import (
 	"errors"
+	"log"
 	"platform-go-challenge/internal/database"
-	"platform-go-challenge/internal/domain/audience"
-	"platform-go-challenge/internal/domain/chart"
-	"platform-go-challenge/internal/domain/insight"
 	"platform-go-challenge/internal/utils"
-	"sync"
+	"slices"
+	"time"
 
 	"github.com/google/uuid"
 	"golang.org/x/sync/errgroup"
 )
 
 type FavouriteService interface {
-	GetPaginatedForUser(UserId uuid.UUID, pageSize int, pageNumber int) (*AssetFavourites, *utils.Pagination, error)
-	CreateForUser(UserId, assetId uuid.UUID, description string) (*Favourite, error)
-	Update(userId, favouriteId uuid.UUID, newDescription string) (*Favourite, error)
-	Delete(userId, favouriteId uuid.UUID) error
+	GetPaginatedForUser(UserId uuid.UUID, page utils.PageQuery, options FavouriteListOptions) ([]FavouriteWithAsset, *utils.Pagination, error)
+	GetForUser(userId, favouriteId uuid.UUID) (*FavouriteDetails, error)
+	GetManyForUser(userId uuid.UUID, favouriteIds uuid.UUIDs) ([]FavouriteWithAsset, error)
+	CreateForUser(UserId, assetId uuid.UUID, description string, tags []string) (*Favourite, error)
+	// Update and Delete fail with ErrFavouriteVersionMismatch when expectedVersion is no longer the version
+	Update(userId, favouriteId uuid.UUID, changes FavouriteChanges, expectedVersion *int) (*Favourite, error)
+	Delete(userId, favouriteId uuid.UUID, expectedVersion *int) error
+	Move(userId, favouriteId, targetId uuid.UUID, after bool, expectedVersion *int) (*Favourite, error)
+	// With atomic set a batch changes nothing unless every item succeeds, the others fail with ErrFavouriteBatchRolledBack
+	CreateManyForUser(userId uuid.UUID, items []CreateFavouriteRequestBody, atomic bool) ([]FavouriteWriteResult, error)
+	UpdateMany(userId uuid.UUID, items []UpdateFavouritesBatchItem, atomic bool) ([]FavouriteWriteResult, error)
+	DeleteMany(userId uuid.UUID, items []DeleteFavouritesBatchItem, atomic bool) ([]FavouriteWriteResult, error)
+	GetTagsForUser(userId uuid.UUID, prefix string, limit int) ([]TagCount, error)
+	GetTrashForUser(userId uuid.UUID, pageSize int, pageNumber int) ([]TrashedFavourite, *utils.Pagination, error)
+	// Restore returns the favourite the user has for the asset by now together with ErrFavouriteAlreadyExists
+	Restore(userId, favouriteId uuid.UUID) (*Favourite, error)
+	Purge(userId, favouriteId uuid.UUID) error
 }
 
 type FavouriteServiceDependencies struct {
 	FavouriteRepository FavouriteRepository
-	ChartRepository     chart.ChartRepository
-	InsightRepository   insight.InsightRepository
-	AudienceRepository  audience.AudienceRepository
+	Assets              *AssetRegistry
+	Now                 func() time.Time
+	TrashRetention      time.Duration
+	// Cursors defaults to a codec with a random secret, whose cursors stop working when the service restarts
+	Cursors *utils.CursorCodec
 }
 
 type favouriteService struct {
@@ -32,122 +46,280 @@ type favouriteService struct {
 }
 
 func NewFavouriteService(dependencies FavouriteServiceDependencies) favouriteService {
+	if dependencies.Now == nil {
+		dependencies.Now = time.Now
+	}
+	if dependencies.TrashRetention == 0 {
+		dependencies.TrashRetention = DefaultTrashRetention
+	}
+	if dependencies.Cursors == nil {
+		dependencies.Cursors = utils.NewCursorCodec(uuid.NewString())
+	}
+
 	return favouriteService{
 		Dependencies: dependencies,
 	}
 }
 
-func (service *favouriteService) GetPaginatedForUser(UserId uuid.UUID, pageSize int, pageNumber int) (*AssetFavourites, *utils.Pagination, error) {
-	favourites, pagination, err := service.Dependencies.FavouriteRepository.GetByUserIdPaginated(UserId, pageSize, pageNumber)
+// now returns the current time in UTC at the microsecond precision every backend stores.
+func (service *favouriteService) now() time.Time {
+	return service.Dependencies.Now().UTC().Truncate(time.Microsecond)
+}
+
+func (service *favouriteService) GetPaginatedForUser(UserId uuid.UUID, page utils.PageQuery, options FavouriteListOptions) ([]FavouriteWithAsset, *utils.Pagination, error) {
+	if options.Sort == "" {
+		options.Sort = FavouriteSortRank
+	}
+
+	favourites, pagination, err := service.listForUser(UserId, page, options)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	chartIds := ExtractAssetTypeIds(AssetTypeChart, favourites)
-	insightIds := ExtractAssetTypeIds(AssetTypeInsight, favourites)
-	audienceIds := ExtractAssetTypeIds(AssetTypeAudience, favourites)
+	result, err := service.withAssets(favourites, options)
+	if err != nil {
+		return nil, nil, err
+	}
 
-	var (
-		charts    []chart.Chart
-		insights  []insight.Insight
-		audiences []audience.Audience
-	)
+	return result, pagination, nil
+}
 
-	g := new(errgroup.Group)
+func (service *favouriteService) GetManyForUser(userId uuid.UUID, favouriteIds uuid.UUIDs) ([]FavouriteWithAsset, error) {
+	favourites, err := service.Dependencies.FavouriteRepository.GetByIds(favouriteIds)
+	if err != nil {
+		return nil, err
+	}
 
-	g.Go(func() error {
-		var err error
-		charts, err = service.Dependencies.ChartRepository.GetByIds(chartIds)
-		return err
-	})
+	favourites = slices.DeleteFunc(favourites, func(favourite Favourite) bool { return favourite.UserId != userId })
 
-	g.Go(func() error {
-		var err error
-		insights, err = service.Dependencies.InsightRepository.GetByIds(insightIds)
-		return err
-	})
+	return service.withAssets(favourites, FavouriteListOptions{})
+}
 
-	g.Go(func() error {
-		var err error
-		audiences, err = service.Dependencies.AudienceRepository.GetByIds(audienceIds)
-		return err
-	})
+func (service *favouriteService) withAssets(favourites []Favourite, options FavouriteListOptions) ([]FavouriteWithAsset, error) {
+	// The asset types left out by the listing have no favourites to look up
+	providers := service.Dependencies.Assets.Providers()
+	assetsByType := make([][]Asset, len(providers))
+
+	g := new(errgroup.Group)
+
+	for i, provider := range providers {
+		if !options.Includes(provider.Type()) {
+			continue
+		}
+
+		ids := ExtractAssetTypeIds(provider.Type(), favourites)
+		g.Go(func() error {
+			var err error
+			assetsByType[i], err = provider.GetByIds(ids)
+			return err
+		})
+	}
 
 	if err := g.Wait(); err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
-	result, err := BuildAssetFavourites(favourites, charts, insights, audiences)
+	return MatchFavouritesToAssets(favourites, slices.Concat(assetsByType...))
+}
+
+func (service *favouriteService) GetForUser(userId uuid.UUID, favouriteId uuid.UUID) (*FavouriteDetails, error) {
+	favourite, err := service.Dependencies.FavouriteRepository.GetById(favouriteId)
+	if err != nil {
+		if errors.Is(err, database.ErrItemNotFound) {
+			return nil, ErrFavouriteNotFound
+		}
+
+		return nil, utils.ErrUnexpected
+	}
+
+	if userId != favourite.UserId {
+		return nil, ErrFavouriteNotUnderGivenUser
+	}
+
+	asset, err := service.assetOf(*favourite)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FavouriteDetails{Favourite: *favourite, Asset: asset}, nil
+}
+
+func (service *favouriteService) assetOf(favourite Favourite) (Asset, error) {
+	provider, found := service.Dependencies.Assets.Provider(favourite.AssetType)
+	if !found {
+		return nil, ErrAssetNotFound
+	}
+
+	assets, err := provider.GetByIds(uuid.UUIDs{favourite.AssetId})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(assets) == 0 {
+		return nil, ErrAssetNotFound
+	}
+
+	return assets[0], nil
+}
+
+func (service *favouriteService) listForUser(userId uuid.UUID, page utils.PageQuery, options FavouriteListOptions) ([]Favourite, *utils.Pagination, error) {
+	if !page.IsCursor() {
+		favourites, pagination, err := service.Dependencies.FavouriteRepository.GetByUserIdPaginated(userId, page.Size, page.Number, options)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if len(favourites) > 0 {
+			err = service.encodeCursors(
+				&pagination,
+				NewFavouriteCursor(options.Sort, favourites[0]), pagination.Page > 0,
+				NewFavouriteCursor(options.Sort, favourites[len(favourites)-1]), pagination.Page < pagination.MaxPage,
+			)
+		}
+
+		return favourites, &pagination, err
+	}
+
+	before := page.Before != ""
+	encodedCursor := page.After
+	if before {
+		encodedCursor = page.Before
+	}
+
+	var cursor FavouriteCursor
+	err := service.Dependencies.Cursors.Decode(encodedCursor, &cursor)
+	if err != nil || cursor.Sort != options.Sort {
+		return nil, nil, utils.ErrInvalidCursor
+	}
+
+	favourites, hasMore, err := service.Dependencies.FavouriteRepository.GetByUserIdKeyset(userId, page.Size, cursor, before, options)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	return result, &pagination, nil
+	pagination := utils.Pagination{PageSize: page.Size}
+
+	// An empty page still has the cursor it was asked with on its other side
+	first, last := cursor, cursor
+	if len(favourites) > 0 {
+		first = NewFavouriteCursor(options.Sort, favourites[0])
+		last = NewFavouriteCursor(options.Sort, favourites[len(favourites)-1])
+	}
+
+	hasPrev, hasNext := true, hasMore
+	if before {
+		hasPrev, hasNext = hasMore, true
+	}
+	if len(favourites) == 0 {
+		hasPrev, hasNext = !before, before
+	}
+
+	err = service.encodeCursors(&pagination, first, hasPrev, last, hasNext)
+
+	return favourites, &pagination, err
 }
 
-func (service *favouriteService) detectAssetType(assetId uuid.UUID) (AssetType, error) {
-	var (
-		chart    *chart.Chart
-		insight  *insight.Insight
-		audience *audience.Audience
-	)
+func (service *favouriteService) encodeCursors(pagination *utils.Pagination, first FavouriteCursor, hasPrev bool, last FavouriteCursor, hasNext bool) error {
+	var err error
 
-	var wg sync.WaitGroup
-	wg.Add(3)
+	if hasPrev {
+		pagination.PrevCursor, err = service.Dependencies.Cursors.Encode(first)
+		if err != nil {
+			return err
+		}
+	}
 
-	go func() {
-		defer wg.Done()
-		chart, _ = service.Dependencies.ChartRepository.GetById(assetId)
-	}()
-	go func() {
-		defer wg.Done()
-		insight, _ = service.Dependencies.InsightRepository.GetById(assetId)
-	}()
-	go func() {
-		defer wg.Done()
-		audience, _ = service.Dependencies.AudienceRepository.GetById(assetId)
-	}()
+	if hasNext {
+		pagination.NextCursor, err = service.Dependencies.Cursors.Encode(last)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
 
-	wg.Wait()
+func (service *favouriteService) detectAssetTypes(assetIds uuid.UUIDs) (map[uuid.UUID]AssetType, error) {
+	providers := service.Dependencies.Assets.Providers()
+	assetsByType := make([][]Asset, len(providers))
 
-	switch {
-	case chart != nil:
-		return AssetTypeChart, nil
-	case insight != nil:
-		return AssetTypeInsight, nil
-	case audience != nil:
-		return AssetTypeAudience, nil
-	default:
-		return "", ErrAssetNotFound
+	g := new(errgroup.Group)
+
+	for i, provider := range providers {
+		g.Go(func() error {
+			var err error
+			assetsByType[i], err = provider.GetByIds(assetIds)
+			return err
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return nil, err
 	}
+
+	// The type registered first wins, if an id is ever an asset of several types
+	result := map[uuid.UUID]AssetType{}
+	for i := len(providers) - 1; i >= 0; i-- {
+		for _, asset := range assetsByType[i] {
+			result[asset.AssetId()] = providers[i].Type()
+		}
+	}
+
+	return result, nil
 }
 
-func (service *favouriteService) CreateForUser(userId uuid.UUID, assetId uuid.UUID, description string) (*Favourite, error) {
-	assetType, err := service.detectAssetType(assetId)
+func (service *favouriteService) CreateForUser(userId uuid.UUID, assetId uuid.UUID, description string, tags []string) (*Favourite, error) {
+	tags, err := NormalizeTags(tags)
 	if err != nil {
 		return nil, err
 	}
 
+	assetTypes, err := service.detectAssetTypes(uuid.UUIDs{assetId})
+	if err != nil {
+		return nil, err
+	}
+
+	assetType, found := assetTypes[assetId]
+	if !found {
+		return nil, ErrAssetNotFound
+	}
+
+	// A new favourite is ranked last, two concurrent creates can get the same rank and are then ordered by id
+	lastRank, err := service.Dependencies.FavouriteRepository.GetLastRank(userId)
+	if err != nil {
+		return nil, ErrCouldNotSaveFavourite
+	}
+
+	now := service.now()
 	favourite := Favourite{
 		Id:          uuid.New(),
 		UserId:      userId,
 		AssetId:     assetId,
 		AssetType:   assetType,
 		Description: description,
+		Version:     1,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		Rank:        utils.RankBetween(lastRank, ""),
+		Tags:        tags,
 	}
 
 	fav, err := service.Dependencies.FavouriteRepository.Create(favourite)
 	if err != nil {
+		if errors.Is(err, ErrFavouriteAlreadyExists) {
+			return fav, ErrFavouriteAlreadyExists
+		}
+
 		return nil, ErrCouldNotSaveFavourite
 	}
 
 	return fav, nil
 }
 
-func (service *favouriteService) Update(userId uuid.UUID, favouriteId uuid.UUID, newDescription string) (*Favourite, error) {
+func (service *favouriteService) ownedFavourite(userId uuid.UUID, favouriteId uuid.UUID, expectedVersion *int) (*Favourite, error) {
 	favourite, err := service.Dependencies.FavouriteRepository.GetById(favouriteId)
 	if err != nil {
-		if errors.Is(err, database.IMErrItemNotFound) {
+		if errors.Is(err, database.ErrItemNotFound) {
 			return nil, ErrFavouriteNotFound
 		}
 
@@ -158,26 +330,467 @@ func (service *favouriteService) Update(userId uuid.UUID, favouriteId uuid.UUID,
 		return nil, ErrFavouriteNotUnderGivenUser
 	}
 
-	if newDescription != "" {
-		favourite.Description = newDescription
+	if expectedVersion != nil && *expectedVersion != favourite.Version {
+		return nil, ErrFavouriteVersionMismatch
+	}
+
+	return favourite, nil
+}
+
+// Update writes back the version it read, so a concurrent change between the read and the write is not overwritten.
+func (service *favouriteService) Update(userId uuid.UUID, favouriteId uuid.UUID, changes FavouriteChanges, expectedVersion *int) (*Favourite, error) {
+	changes, err := changes.normalized()
+	if err != nil {
+		return nil, err
+	}
+
+	favourite, err := service.ownedFavourite(userId, favouriteId, expectedVersion)
+	if err != nil {
+		return nil, err
 	}
 
+	changes.applyTo(favourite)
+	favourite.UpdatedAt = service.now()
+
 	return service.Dependencies.FavouriteRepository.Update(*favourite)
 }
 
-func (service *favouriteService) Delete(userId uuid.UUID, favouriteId uuid.UUID) error {
-	favourite, err := service.Dependencies.FavouriteRepository.GetById(favouriteId)
+func (service *favouriteService) Delete(userId uuid.UUID, favouriteId uuid.UUID, expectedVersion *int) error {
+	favourite, err := service.ownedFavourite(userId, favouriteId, expectedVersion)
+	if err != nil {
+		return err
+	}
+
+	return service.Dependencies.FavouriteRepository.Delete(favouriteId, favourite.Version, service.now())
+}
+
+func (service *favouriteService) GetTrashForUser(userId uuid.UUID, pageSize int, pageNumber int) ([]TrashedFavourite, *utils.Pagination, error) {
+	retention := service.Dependencies.TrashRetention
+
+	trashed, pagination, err := service.Dependencies.FavouriteRepository.GetTrashedByUserIdPaginated(
+		userId, service.now().Add(-retention), pageSize, pageNumber,
+	)
+	if err != nil {
+		return nil, nil, utils.ErrUnexpected
+	}
+
+	for i := range trashed {
+		trashed[i].ExpiresAt = trashed[i].DeletedAt.Add(retention)
+	}
+
+	return trashed, &pagination, nil
+}
+
+// A favourite that expired but was not purged yet is treated as purged.
+func (service *favouriteService) ownedTrashedFavourite(userId uuid.UUID, favouriteId uuid.UUID) (*TrashedFavourite, error) {
+	trashed, err := service.Dependencies.FavouriteRepository.GetTrashedById(favouriteId)
 	if err != nil {
-		if errors.Is(err, database.IMErrItemNotFound) {
-			return ErrFavouriteNotFound
+		if errors.Is(err, database.ErrItemNotFound) {
+			return nil, ErrFavouriteNotFound
 		}
 
-		return utils.ErrUnexpected
+		return nil, utils.ErrUnexpected
+	}
+
+	if userId != trashed.UserId {
+		return nil, ErrFavouriteNotUnderGivenUser
+	}
+
+	trashed.ExpiresAt = trashed.DeletedAt.Add(service.Dependencies.TrashRetention)
+	if !service.now().Before(trashed.ExpiresAt) {
+		return nil, ErrFavouriteNotFound
+	}
+
+	return trashed, nil
+}
+
+func (service *favouriteService) Restore(userId uuid.UUID, favouriteId uuid.UUID) (*Favourite, error) {
+	trashed, err := service.ownedTrashedFavourite(userId, favouriteId)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := service.assetOf(trashed.Favourite); err != nil {
+		return nil, err
+	}
+
+	return service.Dependencies.FavouriteRepository.Restore(favouriteId, service.now())
+}
+
+func (service *favouriteService) Purge(userId uuid.UUID, favouriteId uuid.UUID) error {
+	if _, err := service.ownedTrashedFavourite(userId, favouriteId); err != nil {
+		return err
+	}
+
+	return service.Dependencies.FavouriteRepository.Purge(favouriteId)
+}
+
+func (service *favouriteService) PurgeExpiredTrash() (int, error) {
+	return service.Dependencies.FavouriteRepository.PurgeDeletedBefore(service.now().Add(-service.Dependencies.TrashRetention))
+}
+
+// The stop function StartTrashPurger returns waits for a purge in progress.
+func (service *favouriteService) StartTrashPurger(interval time.Duration) func() {
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+
+	go func() {
+		defer close(stopped)
+		for {
+			select {
+			case <-ticker.C:
+				if _, err := service.PurgeExpiredTrash(); err != nil {
+					log.Printf("Could not purge the favourites trash: %s", err)
+				}
+			case <-done:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
+// Pinned and unpinned ranks are not compared, so a neighbour on the other side of the pinned ones does not bound the rank.
+func (service *favouriteService) Move(userId uuid.UUID, favouriteId uuid.UUID, targetId uuid.UUID, after bool, expectedVersion *int) (*Favourite, error) {
+	favourite, err := service.ownedFavourite(userId, favouriteId, expectedVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := service.Dependencies.FavouriteRepository.GetById(targetId)
+	if err != nil {
+		if errors.Is(err, database.ErrItemNotFound) {
+			return nil, ErrMoveTargetNotFound
+		}
+
+		return nil, utils.ErrUnexpected
+	}
+
+	if userId != target.UserId {
+		return nil, ErrMoveTargetNotFound
+	}
+
+	if target.Id == favourite.Id {
+		return favourite, nil
+	}
+
+	if target.Rank == "" {
+		ranked, err := service.rankUnranked(userId)
+		if err != nil {
+			return nil, err
+		}
+
+		if rankedTarget, found := ranked[target.Id]; found {
+			target = &rankedTarget
+		}
+		if rankedFavourite, found := ranked[favourite.Id]; found {
+			favourite = &rankedFavourite
+		}
+	}
+
+	// Two favourites are read since one of them can be the moved favourite itself
+	options := FavouriteListOptions{Sort: FavouriteSortRank}
+	neighbours, _, err := service.Dependencies.FavouriteRepository.GetByUserIdKeyset(userId, 2, NewFavouriteCursor(FavouriteSortRank, *target), !after, options)
+	if err != nil {
+		return nil, utils.ErrUnexpected
+	}
+
+	// The favourites before the target are in listing order, so the closest one is the last
+	if !after {
+		slices.Reverse(neighbours)
+	}
+
+	neighbourRank := ""
+	for _, neighbour := range neighbours {
+		if neighbour.Id == favourite.Id {
+			continue
+		}
+
+		if neighbour.Pinned == target.Pinned {
+			neighbourRank = neighbour.Rank
+		}
+		break
+	}
+
+	if after {
+		favourite.Rank = utils.RankBetween(target.Rank, neighbourRank)
+	} else {
+		favourite.Rank = utils.RankBetween(neighbourRank, target.Rank)
+	}
+	favourite.Pinned = target.Pinned
+	favourite.UpdatedAt = service.now()
+
+	return service.Dependencies.FavouriteRepository.Update(*favourite)
+}
+
+// rankUnranked ranks the favourites stored before ranks existed, which all share the empty rank, and returns them by id.
+func (service *favouriteService) rankUnranked(userId uuid.UUID) (map[uuid.UUID]Favourite, error) {
+	options := FavouriteListOptions{Sort: FavouriteSortRank}
+	favourites := []Favourite{}
+	for pageNumber := 0; ; pageNumber++ {
+		page, pagination, err := service.Dependencies.FavouriteRepository.GetByUserIdPaginated(userId, 100, pageNumber, options)
+		if err != nil {
+			return nil, utils.ErrUnexpected
+		}
+
+		favourites = append(favourites, page...)
+		if pagination.Page >= pagination.MaxPage {
+			break
+		}
+	}
+
+	writes := []FavouriteWrite{}
+	for start := 0; start < len(favourites); {
+		// The empty rank sorts first, so the unranked favourites lead the pinned and the unpinned ones
+		end := start
+		for end < len(favourites) && favourites[end].Pinned == favourites[start].Pinned && favourites[end].Rank == "" {
+			end++
+		}
+
+		next := ""
+		if end < len(favourites) && favourites[end].Pinned == favourites[start].Pinned {
+			next = favourites[end].Rank
+		}
+
+		unranked := favourites[start:end]
+		spreadRanks(unranked, "", next)
+		for _, favourite := range unranked {
+			favourite.UpdatedAt = service.now()
+			writes = append(writes, FavouriteWrite{Op: FavouriteWriteUpdate, Favourite: favourite})
+		}
+
+		for end < len(favourites) && favourites[end].Pinned == favourites[start].Pinned {
+			end++
+		}
+		start = end
+	}
+
+	results, err := service.Dependencies.FavouriteRepository.WriteMany(writes, true)
+	if err != nil {
+		return nil, utils.ErrUnexpected
+	}
+
+	ranked := map[uuid.UUID]Favourite{}
+	for _, result := range results {
+		if result.Err != nil {
+			if errors.Is(result.Err, ErrFavouriteVersionMismatch) || errors.Is(result.Err, ErrFavouriteNotFound) || errors.Is(result.Err, ErrFavouriteBatchRolledBack) {
+				return nil, ErrFavouriteVersionMismatch
+			}
+
+			return nil, utils.ErrUnexpected
+		}
+
+		ranked[result.Favourite.Id] = *result.Favourite
+	}
+
+	return ranked, nil
+}
+
+// spreadRanks ranks the middle favourite first, so the ranks grow with the logarithm of the count rather than the count.
+func spreadRanks(favourites []Favourite, before string, after string) {
+	if len(favourites) == 0 {
+		return
+	}
+
+	middle := len(favourites) / 2
+	favourites[middle].Rank = utils.RankBetween(before, after)
+	spreadRanks(favourites[:middle], before, favourites[middle].Rank)
+	spreadRanks(favourites[middle+1:], favourites[middle].Rank, after)
+}
+
+func (service *favouriteService) CreateManyForUser(userId uuid.UUID, items []CreateFavouriteRequestBody, atomic bool) ([]FavouriteWriteResult, error) {
+	assetIds := uuid.UUIDs{}
+	for _, item := range items {
+		assetIds = append(assetIds, item.AssetId)
+	}
+
+	assetTypes, err := service.detectAssetTypes(assetIds)
+	if err != nil {
+		return nil, err
+	}
+
+	rank, err := service.Dependencies.FavouriteRepository.GetLastRank(userId)
+	if err != nil {
+		return nil, ErrCouldNotSaveFavourite
+	}
+
+	now := service.now()
+	results := make([]FavouriteWriteResult, len(items))
+	pending := []int{}
+	writes := []FavouriteWrite{}
+
+	for i, item := range items {
+		tags, err := NormalizeTags(item.Tags)
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+
+		assetType, found := assetTypes[item.AssetId]
+		if !found {
+			results[i].Err = ErrAssetNotFound
+			continue
+		}
+
+		rank = utils.RankBetween(rank, "")
+
+		pending = append(pending, i)
+		writes = append(writes, FavouriteWrite{Op: FavouriteWriteCreate, Favourite: Favourite{
+			Id:          uuid.New(),
+			UserId:      userId,
+			AssetId:     item.AssetId,
+			AssetType:   assetType,
+			Description: item.Description,
+			Version:     1,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+			Rank:        rank,
+			Tags:        tags,
+		}})
+	}
+
+	return service.writeMany(results, pending, writes, atomic)
+}
+
+func (service *favouriteService) UpdateMany(userId uuid.UUID, items []UpdateFavouritesBatchItem, atomic bool) ([]FavouriteWriteResult, error) {
+	ids := uuid.UUIDs{}
+	for _, item := range items {
+		ids = append(ids, item.Id)
+	}
+
+	favourites, err := service.ownedFavourites(userId, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	now := service.now()
+	results := make([]FavouriteWriteResult, len(items))
+	pending := []int{}
+	writes := []FavouriteWrite{}
+
+	for i, item := range items {
+		favourite, err := checkBatchFavourite(favourites, userId, item.Id, item.Version)
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+
+		changes, err := FavouriteChanges{Description: item.Description, Pinned: item.Pinned, Tags: item.Tags}.normalized()
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+
+		changes.applyTo(&favourite)
+		favourite.UpdatedAt = now
+
+		pending = append(pending, i)
+		writes = append(writes, FavouriteWrite{Op: FavouriteWriteUpdate, Favourite: favourite})
+	}
+
+	return service.writeMany(results, pending, writes, atomic)
+}
+
+func (service *favouriteService) DeleteMany(userId uuid.UUID, items []DeleteFavouritesBatchItem, atomic bool) ([]FavouriteWriteResult, error) {
+	ids := uuid.UUIDs{}
+	for _, item := range items {
+		ids = append(ids, item.Id)
+	}
+
+	favourites, err := service.ownedFavourites(userId, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]FavouriteWriteResult, len(items))
+	pending := []int{}
+	writes := []FavouriteWrite{}
+	deletedAt := service.now()
+
+	for i, item := range items {
+		favourite, err := checkBatchFavourite(favourites, userId, item.Id, item.Version)
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+
+		pending = append(pending, i)
+		writes = append(writes, FavouriteWrite{Op: FavouriteWriteDelete, Favourite: favourite, DeletedAt: deletedAt})
+	}
+
+	return service.writeMany(results, pending, writes, atomic)
+}
+
+// GetTagsForUser matches the prefix like a tag, ignoring case and extra spaces.
+func (service *favouriteService) GetTagsForUser(userId uuid.UUID, prefix string, limit int) ([]TagCount, error) {
+	return service.Dependencies.FavouriteRepository.GetTagCounts(userId, normalizeTagText(prefix), limit)
+}
+
+func (service *favouriteService) ownedFavourites(userId uuid.UUID, ids uuid.UUIDs) (map[uuid.UUID]Favourite, error) {
+	favourites, err := service.Dependencies.FavouriteRepository.GetByIds(ids)
+	if err != nil {
+		return nil, utils.ErrUnexpected
+	}
+
+	result := map[uuid.UUID]Favourite{}
+	for _, favourite := range favourites {
+		result[favourite.Id] = favourite
+	}
+
+	return result, nil
+}
+
+func checkBatchFavourite(favourites map[uuid.UUID]Favourite, userId uuid.UUID, id uuid.UUID, expectedVersion *int) (Favourite, error) {
+	favourite, found := favourites[id]
+	if !found {
+		return Favourite{}, ErrFavouriteNotFound
 	}
 
 	if userId != favourite.UserId {
-		return ErrFavouriteNotUnderGivenUser
+		return Favourite{}, ErrFavouriteNotUnderGivenUser
+	}
+
+	if expectedVersion != nil && *expectedVersion != favourite.Version {
+		return Favourite{}, ErrFavouriteVersionMismatch
+	}
+
+	return favourite, nil
+}
+
+// batchItemErrors are the errors an item of a batch fails with, any other is reported as ErrCouldNotSaveFavourite.
+var batchItemErrors = []error{
+	ErrAssetNotFound, ErrFavouriteAlreadyExists, ErrFavouriteNotFound, ErrFavouriteNotUnderGivenUser,
+	ErrFavouriteVersionMismatch, ErrFavouriteBatchRolledBack, ErrInvalidTag, ErrTooManyTags,
+}
+
+// An atomic batch with an item that already failed the checks is not written at all.
+func (service *favouriteService) writeMany(results []FavouriteWriteResult, pending []int, writes []FavouriteWrite, atomic bool) ([]FavouriteWriteResult, error) {
+	if atomic && HasFailedWrite(results) {
+		RollBackFavouriteWrites(results)
+		return results, nil
+	}
+
+	if len(writes) == 0 {
+		return results, nil
+	}
+
+	written, err := service.Dependencies.FavouriteRepository.WriteMany(writes, atomic)
+	if err != nil {
+		return nil, ErrCouldNotSaveFavourite
+	}
+
+	for j, i := range pending {
+		results[i] = written[j]
+		if err := written[j].Err; err != nil && !slices.ContainsFunc(batchItemErrors, func(known error) bool { return errors.Is(err, known) }) {
+			results[i] = FavouriteWriteResult{Err: ErrCouldNotSaveFavourite}
+		}
 	}
 
-	return service.Dependencies.FavouriteRepository.Delete(favouriteId)
+	return results, nil
 }
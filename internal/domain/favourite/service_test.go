@@ -8,22 +8,43 @@ import (
 	"platform-go-challenge/internal/domain/favourite"
 	"platform-go-challenge/internal/domain/insight"
 	"platform-go-challenge/internal/utils"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
+func ptr[T any](v T) *T {
+	return &v
+}
+
 type mockFavouriteRepo struct {
-	getByUserIdPaginatedFn func(userId uuid.UUID, pageSize, pageNumber int) ([]favourite.Favourite, utils.Pagination, error)
+	getByUserIdPaginatedFn func(userId uuid.UUID, pageSize, pageNumber int, options favourite.FavouriteListOptions) ([]favourite.Favourite, utils.Pagination, error)
 	createFn               func(fav favourite.Favourite) (*favourite.Favourite, error)
+	getByUserIdKeysetFn    func(userId uuid.UUID, pageSize int, cursor favourite.FavouriteCursor, before bool, options favourite.FavouriteListOptions) ([]favourite.Favourite, bool, error)
 	getByIdFn              func(id uuid.UUID) (*favourite.Favourite, error)
 	updateFn               func(fav favourite.Favourite) (*favourite.Favourite, error)
-	deleteFn               func(id uuid.UUID) error
+	deleteFn               func(id uuid.UUID, version int, deletedAt time.Time) error
+	getByIdsFn             func(ids uuid.UUIDs) ([]favourite.Favourite, error)
+	getLastRankFn          func(userId uuid.UUID) (string, error)
+	writeManyFn            func(writes []favourite.FavouriteWrite, atomic bool) ([]favourite.FavouriteWriteResult, error)
+	getTagCountsFn         func(userId uuid.UUID, prefix string, limit int) ([]favourite.TagCount, error)
+	getTrashedPaginatedFn  func(userId uuid.UUID, deletedAfter time.Time, pageSize, pageNumber int) ([]favourite.TrashedFavourite, utils.Pagination, error)
+	getTrashedByIdFn       func(id uuid.UUID) (*favourite.TrashedFavourite, error)
+	restoreFn              func(id uuid.UUID, restoredAt time.Time) (*favourite.Favourite, error)
+	purgeFn                func(id uuid.UUID) error
+	purgeDeletedBeforeFn   func(before time.Time) (int, error)
+}
+
+func (m *mockFavouriteRepo) GetByUserIdPaginated(userId uuid.UUID, pageSize, pageNumber int, options favourite.FavouriteListOptions) ([]favourite.Favourite, utils.Pagination, error) {
+	return m.getByUserIdPaginatedFn(userId, pageSize, pageNumber, options)
 }
 
-func (m *mockFavouriteRepo) GetByUserIdPaginated(userId uuid.UUID, pageSize, pageNumber int) ([]favourite.Favourite, utils.Pagination, error) {
-	return m.getByUserIdPaginatedFn(userId, pageSize, pageNumber)
+func (m *mockFavouriteRepo) GetByUserIdKeyset(userId uuid.UUID, pageSize int, cursor favourite.FavouriteCursor, before bool, options favourite.FavouriteListOptions) ([]favourite.Favourite, bool, error) {
+	return m.getByUserIdKeysetFn(userId, pageSize, cursor, before, options)
 }
 
 func (m *mockFavouriteRepo) Create(fav favourite.Favourite) (*favourite.Favourite, error) {
@@ -38,11 +59,49 @@ func (m *mockFavouriteRepo) Update(fav favourite.Favourite) (*favourite.Favourit
 	return m.updateFn(fav)
 }
 
-func (m *mockFavouriteRepo) Delete(id uuid.UUID) error {
-	return m.deleteFn(id)
+func (m *mockFavouriteRepo) Delete(id uuid.UUID, version int, deletedAt time.Time) error {
+	return m.deleteFn(id, version, deletedAt)
+}
+
+func (m *mockFavouriteRepo) GetTrashedByUserIdPaginated(userId uuid.UUID, deletedAfter time.Time, pageSize, pageNumber int) ([]favourite.TrashedFavourite, utils.Pagination, error) {
+	return m.getTrashedPaginatedFn(userId, deletedAfter, pageSize, pageNumber)
+}
+
+func (m *mockFavouriteRepo) GetTrashedById(id uuid.UUID) (*favourite.TrashedFavourite, error) {
+	return m.getTrashedByIdFn(id)
+}
+
+func (m *mockFavouriteRepo) Restore(id uuid.UUID, restoredAt time.Time) (*favourite.Favourite, error) {
+	return m.restoreFn(id, restoredAt)
+}
+
+func (m *mockFavouriteRepo) Purge(id uuid.UUID) error {
+	return m.purgeFn(id)
+}
+
+func (m *mockFavouriteRepo) PurgeDeletedBefore(before time.Time) (int, error) {
+	return m.purgeDeletedBeforeFn(before)
+}
+
+func (m *mockFavouriteRepo) GetByIds(ids uuid.UUIDs) ([]favourite.Favourite, error) {
+	return m.getByIdsFn(ids)
+}
+
+func (m *mockFavouriteRepo) GetTagCounts(userId uuid.UUID, prefix string, limit int) ([]favourite.TagCount, error) {
+	return m.getTagCountsFn(userId, prefix, limit)
+}
+
+func (m *mockFavouriteRepo) GetLastRank(userId uuid.UUID) (string, error) {
+	return m.getLastRankFn(userId)
+}
+
+func (m *mockFavouriteRepo) WriteMany(writes []favourite.FavouriteWrite, atomic bool) ([]favourite.FavouriteWriteResult, error) {
+	return m.writeManyFn(writes, atomic)
 }
 
+// mockChartRepo only reads, the writes of the embedded repository are never used by the favourites
 type mockChartRepo struct {
+	chart.ChartRepository
 	getByIdsFn func(ids uuid.UUIDs) ([]chart.Chart, error)
 	getByIdFn  func(id uuid.UUID) (*chart.Chart, error)
 }
@@ -55,7 +114,9 @@ func (m *mockChartRepo) GetById(id uuid.UUID) (*chart.Chart, error) {
 	return m.getByIdFn(id)
 }
 
+// mockInsightRepo only reads, like mockChartRepo
 type mockInsightRepo struct {
+	insight.InsightRepository
 	getByIdsFn func(ids uuid.UUIDs) ([]insight.Insight, error)
 	getByIdFn  func(id uuid.UUID) (*insight.Insight, error)
 }
@@ -69,6 +130,7 @@ func (m *mockInsightRepo) GetById(id uuid.UUID) (*insight.Insight, error) {
 }
 
 type mockAudienceRepo struct {
+	audience.AudienceRepository
 	getByIdsFn func(ids uuid.UUIDs) ([]audience.Audience, error)
 	getByIdFn  func(id uuid.UUID) (*audience.Audience, error)
 }
@@ -95,10 +157,11 @@ func TestShouldReturnPaginatedFavouritesWhenGetPaginatedForUser(t *testing.T) {
 	pagination := utils.Pagination{Page: pageNumber, PageSize: pageSize, MaxPage: 3}
 
 	mockFavRepo := &mockFavouriteRepo{
-		getByUserIdPaginatedFn: func(uId uuid.UUID, ps, pn int) ([]favourite.Favourite, utils.Pagination, error) {
+		getByUserIdPaginatedFn: func(uId uuid.UUID, ps, pn int, options favourite.FavouriteListOptions) ([]favourite.Favourite, utils.Pagination, error) {
 			assert.Equal(t, userId, uId)
 			assert.Equal(t, pageSize, ps)
 			assert.Equal(t, pageNumber, pn)
+			assert.Equal(t, favourite.FavouriteSortDescription, options.Sort)
 			return favourites, pagination, nil
 		},
 	}
@@ -126,21 +189,180 @@ func TestShouldReturnPaginatedFavouritesWhenGetPaginatedForUser(t *testing.T) {
 
 	service := favourite.NewFavouriteService(favourite.FavouriteServiceDependencies{
 		FavouriteRepository: mockFavRepo,
-		ChartRepository:     mockChartRepo,
-		InsightRepository:   mockInsightRepo,
-		AudienceRepository:  mockAudienceRepo,
+		Assets:              newAssetRegistry(mockChartRepo, mockInsightRepo, mockAudienceRepo),
 	})
 
 	// Act
-	result, pag, err := service.GetPaginatedForUser(userId, pageSize, pageNumber)
+	result, pag, err := service.GetPaginatedForUser(userId, utils.PageQuery{Size: pageSize, Number: pageNumber}, favourite.FavouriteListOptions{Sort: favourite.FavouriteSortDescription})
 
 	// Assert
 	assert.NoError(t, err)
 	assert.NotNil(t, result)
-	assert.Equal(t, &pagination, pag)
-	assert.Len(t, result.Charts, 1)
-	assert.Len(t, result.Insights, 1)
-	assert.Len(t, result.Audiences, 1)
+	assert.Equal(t, pagination.Page, pag.Page)
+	assert.Equal(t, pagination.PageSize, pag.PageSize)
+	assert.Equal(t, pagination.MaxPage, pag.MaxPage)
+	assert.NotEmpty(t, pag.PrevCursor)
+	assert.NotEmpty(t, pag.NextCursor)
+	if assert.Len(t, result, 3) {
+		for i, entry := range result {
+			assert.Equal(t, favourites[i], entry.Favourite)
+			assert.Equal(t, favourites[i].AssetId, entry.Asset.AssetId())
+		}
+	}
+}
+
+func TestShouldOnlyLookUpRequestedAssetTypesWhenGetPaginatedForUser(t *testing.T) {
+	// Arrange
+	userId := uuid.New()
+	options := favourite.FavouriteListOptions{AssetTypes: []favourite.AssetType{favourite.AssetTypeChart}, Query: "sales"}
+	favourites := []favourite.Favourite{
+		{Id: uuid.New(), UserId: userId, AssetId: uuid.New(), AssetType: favourite.AssetTypeChart, Description: "Sales"},
+	}
+
+	mockFavRepo := &mockFavouriteRepo{
+		getByUserIdPaginatedFn: func(uId uuid.UUID, ps, pn int, listOptions favourite.FavouriteListOptions) ([]favourite.Favourite, utils.Pagination, error) {
+			assert.Equal(t, options.AssetTypes, listOptions.AssetTypes)
+			assert.Equal(t, options.Query, listOptions.Query)
+			return favourites, utils.Pagination{PageSize: ps}, nil
+		},
+	}
+
+	mockChartRepo := &mockChartRepo{
+		getByIdsFn: func(ids uuid.UUIDs) ([]chart.Chart, error) {
+			return []chart.Chart{{Id: ids[0]}}, nil
+		},
+	}
+
+	// The insight and audience repositories have no functions, so a lookup would panic
+	service := favourite.NewFavouriteService(favourite.FavouriteServiceDependencies{
+		FavouriteRepository: mockFavRepo,
+		Assets:              newAssetRegistry(mockChartRepo, &mockInsightRepo{}, &mockAudienceRepo{}),
+	})
+
+	// Act
+	result, _, err := service.GetPaginatedForUser(userId, utils.PageQuery{Size: 10}, options)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, result, 1)
+}
+
+func TestGetPaginatedForUserWithCursors(t *testing.T) {
+	userId := uuid.New()
+	cursors := utils.NewCursorCodec("secret")
+	favourites := []favourite.Favourite{
+		{Id: uuid.New(), UserId: userId, AssetId: uuid.New(), AssetType: favourite.AssetTypeChart, CreatedAt: time.Unix(1, 0).UTC(), Rank: "V"},
+		{Id: uuid.New(), UserId: userId, AssetId: uuid.New(), AssetType: favourite.AssetTypeChart, CreatedAt: time.Unix(2, 0).UTC(), Rank: "W"},
+	}
+	encode := func(cursor favourite.FavouriteCursor) string {
+		encoded, err := cursors.Encode(cursor)
+		assert.NoError(t, err)
+		return encoded
+	}
+	decode := func(encoded string) favourite.FavouriteCursor {
+		var cursor favourite.FavouriteCursor
+		assert.NoError(t, cursors.Decode(encoded, &cursor))
+		return cursor
+	}
+	newService := func(mockFavRepo *mockFavouriteRepo) favourite.FavouriteService {
+		service := favourite.NewFavouriteService(favourite.FavouriteServiceDependencies{
+			FavouriteRepository: mockFavRepo,
+			Assets: newAssetRegistry(
+				&mockChartRepo{
+					getByIdsFn: func(ids uuid.UUIDs) ([]chart.Chart, error) {
+						result := []chart.Chart{}
+						for _, id := range ids {
+							result = append(result, chart.Chart{Id: id})
+						}
+						return result, nil
+					},
+				},
+				&mockInsightRepo{
+					getByIdsFn: func(ids uuid.UUIDs) ([]insight.Insight, error) { return []insight.Insight{}, nil },
+				},
+				&mockAudienceRepo{
+					getByIdsFn: func(ids uuid.UUIDs) ([]audience.Audience, error) { return []audience.Audience{}, nil },
+				},
+			),
+			Cursors: cursors,
+		})
+		return &service
+	}
+
+	t.Run("should read the page after the cursor and point to the pages around it", func(t *testing.T) {
+		// Arrange
+		after := favourite.FavouriteCursor{Sort: favourite.FavouriteSortRank, Id: uuid.New()}
+		service := newService(&mockFavouriteRepo{
+			getByUserIdKeysetFn: func(uId uuid.UUID, pageSize int, cursor favourite.FavouriteCursor, before bool, options favourite.FavouriteListOptions) ([]favourite.Favourite, bool, error) {
+				assert.Equal(t, userId, uId)
+				assert.Equal(t, 2, pageSize)
+				assert.Equal(t, after, cursor)
+				assert.False(t, before)
+				return favourites, true, nil
+			},
+		})
+
+		// Act
+		result, pagination, err := service.GetPaginatedForUser(userId, utils.PageQuery{Size: 2, After: encode(after)}, favourite.FavouriteListOptions{})
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Len(t, result, 2)
+		assert.Equal(t, favourite.NewFavouriteCursor(favourite.FavouriteSortRank, favourites[0]), decode(pagination.PrevCursor))
+		assert.Equal(t, favourite.NewFavouriteCursor(favourite.FavouriteSortRank, favourites[1]), decode(pagination.NextCursor))
+	})
+
+	t.Run("should leave out the previous cursor at the start of the listing", func(t *testing.T) {
+		// Arrange
+		before := favourite.FavouriteCursor{Sort: favourite.FavouriteSortRank, Id: uuid.New()}
+		service := newService(&mockFavouriteRepo{
+			getByUserIdKeysetFn: func(_ uuid.UUID, _ int, cursor favourite.FavouriteCursor, isBefore bool, _ favourite.FavouriteListOptions) ([]favourite.Favourite, bool, error) {
+				assert.True(t, isBefore)
+				return favourites, false, nil
+			},
+		})
+
+		// Act
+		_, pagination, err := service.GetPaginatedForUser(userId, utils.PageQuery{Size: 2, Before: encode(before)}, favourite.FavouriteListOptions{})
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Empty(t, pagination.PrevCursor)
+		assert.NotEmpty(t, pagination.NextCursor)
+	})
+
+	t.Run("should point back to the cursor when the page after it is empty", func(t *testing.T) {
+		// Arrange
+		after := favourite.FavouriteCursor{Sort: favourite.FavouriteSortRank, Id: uuid.New()}
+		service := newService(&mockFavouriteRepo{
+			getByUserIdKeysetFn: func(_ uuid.UUID, _ int, _ favourite.FavouriteCursor, _ bool, _ favourite.FavouriteListOptions) ([]favourite.Favourite, bool, error) {
+				return []favourite.Favourite{}, false, nil
+			},
+		})
+
+		// Act
+		_, pagination, err := service.GetPaginatedForUser(userId, utils.PageQuery{Size: 2, After: encode(after)}, favourite.FavouriteListOptions{})
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, after, decode(pagination.PrevCursor))
+		assert.Empty(t, pagination.NextCursor)
+	})
+
+	t.Run("should return invalid cursor when the cursor is forged or made for another sort", func(t *testing.T) {
+		// Arrange
+		service := newService(&mockFavouriteRepo{})
+		otherSort := encode(favourite.FavouriteCursor{Sort: favourite.FavouriteSortDescription, Id: uuid.New()})
+		forged, _ := utils.NewCursorCodec("other secret").Encode(favourite.FavouriteCursor{Sort: favourite.FavouriteSortRank})
+
+		// Act
+		_, _, otherSortErr := service.GetPaginatedForUser(userId, utils.PageQuery{Size: 2, After: otherSort}, favourite.FavouriteListOptions{})
+		_, _, forgedErr := service.GetPaginatedForUser(userId, utils.PageQuery{Size: 2, Before: forged}, favourite.FavouriteListOptions{})
+
+		// Assert
+		assert.ErrorIs(t, otherSortErr, utils.ErrInvalidCursor)
+		assert.ErrorIs(t, forgedErr, utils.ErrInvalidCursor)
+	})
 }
 
 func TestShouldCreateFavouriteWhenAssetExists(t *testing.T) {
@@ -150,20 +372,22 @@ func TestShouldCreateFavouriteWhenAssetExists(t *testing.T) {
 	description := "desc"
 
 	mockChartRepo := &mockChartRepo{
-		getByIdFn: func(id uuid.UUID) (*chart.Chart, error) {
-			if id == assetId {
-				return &chart.Chart{Id: id}, nil
-			}
-			return nil, nil
+		getByIdsFn: func(ids uuid.UUIDs) ([]chart.Chart, error) {
+			assert.Equal(t, uuid.UUIDs{assetId}, ids)
+			return []chart.Chart{{Id: assetId}}, nil
 		},
 	}
 	mockInsightRepo := &mockInsightRepo{
-		getByIdFn: func(id uuid.UUID) (*insight.Insight, error) { return nil, nil },
+		getByIdsFn: func(ids uuid.UUIDs) ([]insight.Insight, error) { return []insight.Insight{}, nil },
 	}
 	mockAudienceRepo := &mockAudienceRepo{
-		getByIdFn: func(id uuid.UUID) (*audience.Audience, error) { return nil, nil },
+		getByIdsFn: func(ids uuid.UUIDs) ([]audience.Audience, error) { return []audience.Audience{}, nil },
 	}
 	mockFavRepo := &mockFavouriteRepo{
+		getLastRankFn: func(uId uuid.UUID) (string, error) {
+			assert.Equal(t, userId, uId)
+			return "V", nil
+		},
 		createFn: func(fav favourite.Favourite) (*favourite.Favourite, error) {
 			assert.Equal(t, userId, fav.UserId)
 			assert.Equal(t, assetId, fav.AssetId)
@@ -175,23 +399,29 @@ func TestShouldCreateFavouriteWhenAssetExists(t *testing.T) {
 		},
 	}
 
+	now := time.Date(2025, time.March, 1, 10, 30, 0, 123456789, time.FixedZone("EET", 2*60*60))
+
 	service := favourite.NewFavouriteService(favourite.FavouriteServiceDependencies{
 		FavouriteRepository: mockFavRepo,
-		ChartRepository:     mockChartRepo,
-		InsightRepository:   mockInsightRepo,
-		AudienceRepository:  mockAudienceRepo,
+		Assets:              newAssetRegistry(mockChartRepo, mockInsightRepo, mockAudienceRepo),
+		Now:                 func() time.Time { return now },
 	})
 
 	// Act
-	created, err := service.CreateForUser(userId, assetId, description)
+	created, err := service.CreateForUser(userId, assetId, description, []string{"Sales", " Q2 ", "q2"})
 
 	// Assert
 	assert.NoError(t, err)
 	assert.NotNil(t, created)
+	assert.Equal(t, []string{"q2", "sales"}, created.Tags)
 	assert.Equal(t, userId, created.UserId)
 	assert.Equal(t, assetId, created.AssetId)
 	assert.Equal(t, description, created.Description)
 	assert.Equal(t, favourite.AssetTypeChart, created.AssetType)
+	assert.Equal(t, time.Date(2025, time.March, 1, 8, 30, 0, 123456000, time.UTC), created.CreatedAt)
+	assert.Equal(t, created.CreatedAt, created.UpdatedAt)
+	assert.Greater(t, created.Rank, "V")
+	assert.False(t, created.Pinned)
 }
 
 func TestShouldReturnAssetNotFoundWhenCreateForUserAndAssetDoesNotExist(t *testing.T) {
@@ -199,25 +429,23 @@ func TestShouldReturnAssetNotFoundWhenCreateForUserAndAssetDoesNotExist(t *testi
 	userId := uuid.New()
 	assetId := uuid.New()
 	mockChartRepo := &mockChartRepo{
-		getByIdFn: func(id uuid.UUID) (*chart.Chart, error) { return nil, nil },
+		getByIdsFn: func(ids uuid.UUIDs) ([]chart.Chart, error) { return []chart.Chart{}, nil },
 	}
 	mockInsightRepo := &mockInsightRepo{
-		getByIdFn: func(id uuid.UUID) (*insight.Insight, error) { return nil, nil },
+		getByIdsFn: func(ids uuid.UUIDs) ([]insight.Insight, error) { return []insight.Insight{}, nil },
 	}
 	mockAudienceRepo := &mockAudienceRepo{
-		getByIdFn: func(id uuid.UUID) (*audience.Audience, error) { return nil, nil },
+		getByIdsFn: func(ids uuid.UUIDs) ([]audience.Audience, error) { return []audience.Audience{}, nil },
 	}
 	mockFavRepo := &mockFavouriteRepo{}
 
 	service := favourite.NewFavouriteService(favourite.FavouriteServiceDependencies{
 		FavouriteRepository: mockFavRepo,
-		ChartRepository:     mockChartRepo,
-		InsightRepository:   mockInsightRepo,
-		AudienceRepository:  mockAudienceRepo,
+		Assets:              newAssetRegistry(mockChartRepo, mockInsightRepo, mockAudienceRepo),
 	})
 
 	// Act
-	created, err := service.CreateForUser(userId, assetId, "desc")
+	created, err := service.CreateForUser(userId, assetId, "desc", nil)
 
 	// Assert
 	assert.Nil(t, created)
@@ -229,20 +457,19 @@ func TestShouldReturnErrorWhenCreateForUserFailsToSave(t *testing.T) {
 	userId := uuid.New()
 	assetId := uuid.New()
 	mockChartRepo := &mockChartRepo{
-		getByIdFn: func(id uuid.UUID) (*chart.Chart, error) {
-			if id == assetId {
-				return &chart.Chart{Id: id}, nil
-			}
-			return nil, nil
+		getByIdsFn: func(ids uuid.UUIDs) ([]chart.Chart, error) {
+			assert.Equal(t, uuid.UUIDs{assetId}, ids)
+			return []chart.Chart{{Id: assetId}}, nil
 		},
 	}
 	mockInsightRepo := &mockInsightRepo{
-		getByIdFn: func(id uuid.UUID) (*insight.Insight, error) { return nil, nil },
+		getByIdsFn: func(ids uuid.UUIDs) ([]insight.Insight, error) { return []insight.Insight{}, nil },
 	}
 	mockAudienceRepo := &mockAudienceRepo{
-		getByIdFn: func(id uuid.UUID) (*audience.Audience, error) { return nil, nil },
+		getByIdsFn: func(ids uuid.UUIDs) ([]audience.Audience, error) { return []audience.Audience{}, nil },
 	}
 	mockFavRepo := &mockFavouriteRepo{
+		getLastRankFn: func(uId uuid.UUID) (string, error) { return "", nil },
 		createFn: func(fav favourite.Favourite) (*favourite.Favourite, error) {
 			return nil, errors.New("db error")
 		},
@@ -250,19 +477,180 @@ func TestShouldReturnErrorWhenCreateForUserFailsToSave(t *testing.T) {
 
 	service := favourite.NewFavouriteService(favourite.FavouriteServiceDependencies{
 		FavouriteRepository: mockFavRepo,
-		ChartRepository:     mockChartRepo,
-		InsightRepository:   mockInsightRepo,
-		AudienceRepository:  mockAudienceRepo,
+		Assets:              newAssetRegistry(mockChartRepo, mockInsightRepo, mockAudienceRepo),
 	})
 
 	// Act
-	created, err := service.CreateForUser(userId, assetId, "desc")
+	created, err := service.CreateForUser(userId, assetId, "desc", nil)
 
 	// Assert
 	assert.Nil(t, created)
 	assert.ErrorIs(t, err, favourite.ErrCouldNotSaveFavourite)
 }
 
+func TestShouldReturnExistingFavouriteWhenCreateForUserAndAssetIsAlreadyFavourite(t *testing.T) {
+	// Arrange
+	userId := uuid.New()
+	assetId := uuid.New()
+	existing := &favourite.Favourite{Id: uuid.New(), UserId: userId, AssetId: assetId, AssetType: favourite.AssetTypeChart}
+	mockChartRepo := &mockChartRepo{
+		getByIdsFn: func(ids uuid.UUIDs) ([]chart.Chart, error) { return []chart.Chart{{Id: assetId}}, nil },
+	}
+	mockInsightRepo := &mockInsightRepo{
+		getByIdsFn: func(ids uuid.UUIDs) ([]insight.Insight, error) { return []insight.Insight{}, nil },
+	}
+	mockAudienceRepo := &mockAudienceRepo{
+		getByIdsFn: func(ids uuid.UUIDs) ([]audience.Audience, error) { return []audience.Audience{}, nil },
+	}
+	mockFavRepo := &mockFavouriteRepo{
+		getLastRankFn: func(uId uuid.UUID) (string, error) { return "", nil },
+		createFn: func(fav favourite.Favourite) (*favourite.Favourite, error) {
+			return existing, favourite.ErrFavouriteAlreadyExists
+		},
+	}
+
+	service := favourite.NewFavouriteService(favourite.FavouriteServiceDependencies{
+		FavouriteRepository: mockFavRepo,
+		Assets:              newAssetRegistry(mockChartRepo, mockInsightRepo, mockAudienceRepo),
+	})
+
+	// Act
+	result, err := service.CreateForUser(userId, assetId, "desc", nil)
+
+	// Assert
+	assert.ErrorIs(t, err, favourite.ErrFavouriteAlreadyExists)
+	assert.Equal(t, existing, result)
+}
+
+func TestGetForUserService(t *testing.T) {
+	userId := uuid.New()
+	favId := uuid.New()
+	assetId := uuid.New()
+	stored := favourite.Favourite{Id: favId, UserId: userId, AssetId: assetId, AssetType: favourite.AssetTypeInsight, Version: 2}
+	newService := func(fav *favourite.Favourite, insights []insight.Insight) favourite.FavouriteService {
+		service := favourite.NewFavouriteService(favourite.FavouriteServiceDependencies{
+			FavouriteRepository: &mockFavouriteRepo{
+				getByIdFn: func(id uuid.UUID) (*favourite.Favourite, error) {
+					if fav == nil {
+						return nil, database.IMErrItemNotFound
+					}
+					return fav, nil
+				},
+			},
+			Assets: newAssetRegistry(
+				&mockChartRepo{},
+				&mockInsightRepo{
+					getByIdsFn: func(ids uuid.UUIDs) ([]insight.Insight, error) {
+						assert.Equal(t, uuid.UUIDs{assetId}, ids)
+						return insights, nil
+					},
+				},
+				&mockAudienceRepo{},
+			),
+		})
+		return &service
+	}
+
+	t.Run("should return the favourite with its asset", func(t *testing.T) {
+		// Arrange
+		asset := insight.Insight{Id: assetId, Text: "insight"}
+		service := newService(&stored, []insight.Insight{asset})
+
+		// Act
+		result, err := service.GetForUser(userId, favId)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, &favourite.FavouriteDetails{Favourite: stored, Asset: asset}, result)
+	})
+
+	t.Run("should return error when favourite not found", func(t *testing.T) {
+		// Arrange
+		service := newService(nil, nil)
+
+		// Act
+		result, err := service.GetForUser(userId, favId)
+
+		// Assert
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, favourite.ErrFavouriteNotFound)
+	})
+
+	t.Run("should return error when favourite does not belong to user", func(t *testing.T) {
+		// Arrange
+		service := newService(&stored, []insight.Insight{{Id: assetId}})
+
+		// Act
+		result, err := service.GetForUser(uuid.New(), favId)
+
+		// Assert
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, favourite.ErrFavouriteNotUnderGivenUser)
+	})
+
+	t.Run("should return asset not found when the asset of the favourite is gone", func(t *testing.T) {
+		// Arrange
+		service := newService(&stored, []insight.Insight{})
+
+		// Act
+		result, err := service.GetForUser(userId, favId)
+
+		// Assert
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, favourite.ErrAssetNotFound)
+	})
+}
+
+func TestGetManyForUserService(t *testing.T) {
+	t.Run("should return the user's favourites in the requested order with their assets", func(t *testing.T) {
+		// Arrange
+		userId := uuid.New()
+		chartAsset := chart.Chart{Id: uuid.New(), Title: "chart"}
+		insightAsset := insight.Insight{Id: uuid.New(), Text: "insight"}
+		chartFav := favourite.Favourite{Id: uuid.New(), UserId: userId, AssetId: chartAsset.Id, AssetType: favourite.AssetTypeChart}
+		insightFav := favourite.Favourite{Id: uuid.New(), UserId: userId, AssetId: insightAsset.Id, AssetType: favourite.AssetTypeInsight}
+		goneAssetFav := favourite.Favourite{Id: uuid.New(), UserId: userId, AssetId: uuid.New(), AssetType: favourite.AssetTypeInsight}
+		otherUserFav := favourite.Favourite{Id: uuid.New(), UserId: uuid.New(), AssetId: uuid.New(), AssetType: favourite.AssetTypeChart}
+		ids := uuid.UUIDs{insightFav.Id, otherUserFav.Id, goneAssetFav.Id, chartFav.Id}
+		service := favourite.NewFavouriteService(favourite.FavouriteServiceDependencies{
+			FavouriteRepository: &mockFavouriteRepo{
+				getByIdsFn: func(requested uuid.UUIDs) ([]favourite.Favourite, error) {
+					assert.Equal(t, ids, requested)
+					return []favourite.Favourite{insightFav, otherUserFav, goneAssetFav, chartFav}, nil
+				},
+			},
+			Assets: newAssetRegistry(
+				&mockChartRepo{
+					getByIdsFn: func(ids uuid.UUIDs) ([]chart.Chart, error) {
+						assert.Equal(t, uuid.UUIDs{chartAsset.Id}, ids)
+						return []chart.Chart{chartAsset}, nil
+					},
+				},
+				&mockInsightRepo{
+					getByIdsFn: func(ids uuid.UUIDs) ([]insight.Insight, error) {
+						return []insight.Insight{insightAsset}, nil
+					},
+				},
+				&mockAudienceRepo{
+					getByIdsFn: func(ids uuid.UUIDs) ([]audience.Audience, error) {
+						return []audience.Audience{}, nil
+					},
+				},
+			),
+		})
+
+		// Act
+		result, err := service.GetManyForUser(userId, ids)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, []favourite.FavouriteWithAsset{
+			{Favourite: insightFav, Asset: insightAsset},
+			{Favourite: chartFav, Asset: chartAsset},
+		}, result)
+	})
+}
+
 func TestUpdateService(t *testing.T) {
 	userId := uuid.New()
 	otherUserId := uuid.New()
@@ -280,7 +668,7 @@ func TestUpdateService(t *testing.T) {
 		})
 
 		// Act
-		result, err := service.Update(userId, favId, "desc")
+		result, err := service.Update(userId, favId, favourite.FavouriteChanges{Description: ptr("desc")}, nil)
 
 		// Assert
 		assert.Nil(t, result)
@@ -299,7 +687,7 @@ func TestUpdateService(t *testing.T) {
 		})
 
 		// Act
-		result, err := service.Update(userId, favId, "desc")
+		result, err := service.Update(userId, favId, favourite.FavouriteChanges{Description: ptr("desc")}, nil)
 
 		// Assert
 		assert.Nil(t, result)
@@ -308,7 +696,9 @@ func TestUpdateService(t *testing.T) {
 
 	t.Run("should update description when input is valid", func(t *testing.T) {
 		// Arrange
-		existingFav := favourite.Favourite{Id: favId, UserId: userId, Description: "old"}
+		createdAt := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+		now := time.Date(2025, time.February, 1, 0, 0, 0, 0, time.UTC)
+		existingFav := favourite.Favourite{Id: favId, UserId: userId, Description: "old", CreatedAt: createdAt, UpdatedAt: createdAt}
 		mockFavRepo := &mockFavouriteRepo{
 			getByIdFn: func(id uuid.UUID) (*favourite.Favourite, error) {
 				return &existingFav, nil
@@ -320,18 +710,44 @@ func TestUpdateService(t *testing.T) {
 		}
 		service := favourite.NewFavouriteService(favourite.FavouriteServiceDependencies{
 			FavouriteRepository: mockFavRepo,
+			Now:                 func() time.Time { return now },
 		})
 
 		// Act
-		result, err := service.Update(userId, favId, "new")
+		result, err := service.Update(userId, favId, favourite.FavouriteChanges{Description: ptr("new")}, nil)
 
 		// Assert
 		assert.NoError(t, err)
 		assert.NotNil(t, result)
 		assert.Equal(t, "new", result.Description)
+		assert.Equal(t, createdAt, result.CreatedAt)
+		assert.Equal(t, now, result.UpdatedAt)
+	})
+
+	t.Run("should clear description when new description is empty", func(t *testing.T) {
+		// Arrange
+		existingFav := favourite.Favourite{Id: favId, UserId: userId, Description: "old"}
+		mockFavRepo := &mockFavouriteRepo{
+			getByIdFn: func(id uuid.UUID) (*favourite.Favourite, error) {
+				return &existingFav, nil
+			},
+			updateFn: func(fav favourite.Favourite) (*favourite.Favourite, error) {
+				return &fav, nil
+			},
+		}
+		service := favourite.NewFavouriteService(favourite.FavouriteServiceDependencies{
+			FavouriteRepository: mockFavRepo,
+		})
+
+		// Act
+		result, err := service.Update(userId, favId, favourite.FavouriteChanges{Description: ptr("")}, nil)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, "", result.Description)
 	})
 
-	t.Run("should keep description unchanged when new description is empty", func(t *testing.T) {
+	t.Run("should keep description unchanged when no new description is given", func(t *testing.T) {
 		// Arrange
 		existingFav := favourite.Favourite{Id: favId, UserId: userId, Description: "keep"}
 		mockFavRepo := &mockFavouriteRepo{
@@ -348,7 +764,7 @@ func TestUpdateService(t *testing.T) {
 		})
 
 		// Act
-		result, err := service.Update(userId, favId, "")
+		result, err := service.Update(userId, favId, favourite.FavouriteChanges{}, nil)
 
 		// Assert
 		assert.NoError(t, err)
@@ -356,11 +772,16 @@ func TestUpdateService(t *testing.T) {
 		assert.Equal(t, "keep", result.Description)
 	})
 
-	t.Run("should return unexpected error when repository GetById fails unexpectedly", func(t *testing.T) {
+	t.Run("should pin the favourite and keep its rank", func(t *testing.T) {
 		// Arrange
+		pinned := true
+		existingFav := favourite.Favourite{Id: favId, UserId: userId, Description: "keep", Rank: "X"}
 		mockFavRepo := &mockFavouriteRepo{
 			getByIdFn: func(id uuid.UUID) (*favourite.Favourite, error) {
-				return nil, errors.New("db failure")
+				return &existingFav, nil
+			},
+			updateFn: func(fav favourite.Favourite) (*favourite.Favourite, error) {
+				return &fav, nil
 			},
 		}
 		service := favourite.NewFavouriteService(favourite.FavouriteServiceDependencies{
@@ -368,24 +789,25 @@ func TestUpdateService(t *testing.T) {
 		})
 
 		// Act
-		result, err := service.Update(userId, favId, "desc")
+		result, err := service.Update(userId, favId, favourite.FavouriteChanges{Pinned: &pinned}, nil)
 
 		// Assert
-		assert.Nil(t, result)
-		assert.ErrorIs(t, err, utils.ErrUnexpected)
+		assert.NoError(t, err)
+		assert.True(t, result.Pinned)
+		assert.Equal(t, "X", result.Rank)
+		assert.Equal(t, "keep", result.Description)
 	})
-}
-
-func TestDeleteService(t *testing.T) {
-	userId := uuid.New()
-	otherUserId := uuid.New()
-	favId := uuid.New()
 
-	t.Run("should return error when favourite not found", func(t *testing.T) {
+	t.Run("should replace the tags when given and keep them otherwise", func(t *testing.T) {
 		// Arrange
+		existingFav := favourite.Favourite{Id: favId, UserId: userId, Description: "keep", Tags: []string{"q2"}}
 		mockFavRepo := &mockFavouriteRepo{
 			getByIdFn: func(id uuid.UUID) (*favourite.Favourite, error) {
-				return nil, database.IMErrItemNotFound
+				copied := existingFav
+				return &copied, nil
+			},
+			updateFn: func(fav favourite.Favourite) (*favourite.Favourite, error) {
+				return &fav, nil
 			},
 		}
 		service := favourite.NewFavouriteService(favourite.FavouriteServiceDependencies{
@@ -393,17 +815,45 @@ func TestDeleteService(t *testing.T) {
 		})
 
 		// Act
-		err := service.Delete(userId, favId)
+		replaced, replaceErr := service.Update(userId, favId, favourite.FavouriteChanges{Tags: []string{"Churn", "sales"}}, nil)
+		cleared, clearErr := service.Update(userId, favId, favourite.FavouriteChanges{Tags: []string{}}, nil)
+		kept, keepErr := service.Update(userId, favId, favourite.FavouriteChanges{Description: ptr("new")}, nil)
 
 		// Assert
-		assert.ErrorIs(t, err, favourite.ErrFavouriteNotFound)
+		assert.NoError(t, replaceErr)
+		assert.Equal(t, []string{"churn", "sales"}, replaced.Tags)
+		assert.NoError(t, clearErr)
+		assert.Equal(t, []string{}, cleared.Tags)
+		assert.NoError(t, keepErr)
+		assert.Equal(t, []string{"q2"}, kept.Tags)
 	})
 
-	t.Run("should return error when favourite does not belong to user", func(t *testing.T) {
+	t.Run("should return invalid tag without reading the favourite", func(t *testing.T) {
+		// Arrange
+		service := favourite.NewFavouriteService(favourite.FavouriteServiceDependencies{
+			FavouriteRepository: &mockFavouriteRepo{},
+		})
+
+		// Act
+		result, err := service.Update(userId, favId, favourite.FavouriteChanges{Tags: []string{" "}}, nil)
+
+		// Assert
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, favourite.ErrInvalidTag)
+	})
+
+	t.Run("should write back the version it read", func(t *testing.T) {
 		// Arrange
+		existingFav := favourite.Favourite{Id: favId, UserId: userId, Description: "old", Version: 3}
+		expectedVersion := 3
 		mockFavRepo := &mockFavouriteRepo{
 			getByIdFn: func(id uuid.UUID) (*favourite.Favourite, error) {
-				return &favourite.Favourite{Id: favId, UserId: otherUserId}, nil
+				return &existingFav, nil
+			},
+			updateFn: func(fav favourite.Favourite) (*favourite.Favourite, error) {
+				assert.Equal(t, 3, fav.Version)
+				fav.Version++
+				return &fav, nil
 			},
 		}
 		service := favourite.NewFavouriteService(favourite.FavouriteServiceDependencies{
@@ -411,33 +861,32 @@ func TestDeleteService(t *testing.T) {
 		})
 
 		// Act
-		err := service.Delete(userId, favId)
+		result, err := service.Update(userId, favId, favourite.FavouriteChanges{Description: ptr("new")}, &expectedVersion)
 
 		// Assert
-		assert.ErrorIs(t, err, favourite.ErrFavouriteNotUnderGivenUser)
+		assert.NoError(t, err)
+		assert.Equal(t, 4, result.Version)
 	})
 
-	t.Run("should delete when input is valid", func(t *testing.T) {
+	t.Run("should return version mismatch when favourite is not at the expected version", func(t *testing.T) {
 		// Arrange
-		existingFav := favourite.Favourite{Id: favId, UserId: userId, Description: "old"}
+		existingFav := favourite.Favourite{Id: favId, UserId: userId, Description: "old", Version: 3}
+		expectedVersion := 2
 		mockFavRepo := &mockFavouriteRepo{
 			getByIdFn: func(id uuid.UUID) (*favourite.Favourite, error) {
 				return &existingFav, nil
 			},
-			deleteFn: func(id uuid.UUID) error {
-				assert.Equal(t, existingFav.Id, id)
-				return nil
-			},
 		}
 		service := favourite.NewFavouriteService(favourite.FavouriteServiceDependencies{
 			FavouriteRepository: mockFavRepo,
 		})
 
 		// Act
-		err := service.Delete(userId, favId)
+		result, err := service.Update(userId, favId, favourite.FavouriteChanges{Description: ptr("new")}, &expectedVersion)
 
 		// Assert
-		assert.NoError(t, err)
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, favourite.ErrFavouriteVersionMismatch)
 	})
 
 	t.Run("should return unexpected error when repository GetById fails unexpectedly", func(t *testing.T) {
@@ -452,9 +901,875 @@ func TestDeleteService(t *testing.T) {
 		})
 
 		// Act
-		err := service.Delete(userId, favId)
+		result, err := service.Update(userId, favId, favourite.FavouriteChanges{Description: ptr("desc")}, nil)
 
 		// Assert
+		assert.Nil(t, result)
 		assert.ErrorIs(t, err, utils.ErrUnexpected)
 	})
 }
+
+func TestDeleteService(t *testing.T) {
+	userId := uuid.New()
+	otherUserId := uuid.New()
+	favId := uuid.New()
+
+	t.Run("should return error when favourite not found", func(t *testing.T) {
+		// Arrange
+		mockFavRepo := &mockFavouriteRepo{
+			getByIdFn: func(id uuid.UUID) (*favourite.Favourite, error) {
+				return nil, database.IMErrItemNotFound
+			},
+		}
+		service := favourite.NewFavouriteService(favourite.FavouriteServiceDependencies{
+			FavouriteRepository: mockFavRepo,
+		})
+
+		// Act
+		err := service.Delete(userId, favId, nil)
+
+		// Assert
+		assert.ErrorIs(t, err, favourite.ErrFavouriteNotFound)
+	})
+
+	t.Run("should return error when favourite does not belong to user", func(t *testing.T) {
+		// Arrange
+		mockFavRepo := &mockFavouriteRepo{
+			getByIdFn: func(id uuid.UUID) (*favourite.Favourite, error) {
+				return &favourite.Favourite{Id: favId, UserId: otherUserId}, nil
+			},
+		}
+		service := favourite.NewFavouriteService(favourite.FavouriteServiceDependencies{
+			FavouriteRepository: mockFavRepo,
+		})
+
+		// Act
+		err := service.Delete(userId, favId, nil)
+
+		// Assert
+		assert.ErrorIs(t, err, favourite.ErrFavouriteNotUnderGivenUser)
+	})
+
+	t.Run("should move the favourite to the trash when input is valid", func(t *testing.T) {
+		// Arrange
+		now := time.Date(2025, time.February, 1, 0, 0, 0, 0, time.UTC)
+		existingFav := favourite.Favourite{Id: favId, UserId: userId, Description: "old"}
+		mockFavRepo := &mockFavouriteRepo{
+			getByIdFn: func(id uuid.UUID) (*favourite.Favourite, error) {
+				return &existingFav, nil
+			},
+			deleteFn: func(id uuid.UUID, version int, deletedAt time.Time) error {
+				assert.Equal(t, existingFav.Id, id)
+				assert.Equal(t, now, deletedAt)
+				return nil
+			},
+		}
+		service := favourite.NewFavouriteService(favourite.FavouriteServiceDependencies{
+			FavouriteRepository: mockFavRepo,
+			Now:                 func() time.Time { return now },
+		})
+
+		// Act
+		err := service.Delete(userId, favId, nil)
+
+		// Assert
+		assert.NoError(t, err)
+	})
+
+	t.Run("should return version mismatch when favourite is not at the expected version", func(t *testing.T) {
+		// Arrange
+		existingFav := favourite.Favourite{Id: favId, UserId: userId, Description: "old", Version: 3}
+		expectedVersion := 2
+		mockFavRepo := &mockFavouriteRepo{
+			getByIdFn: func(id uuid.UUID) (*favourite.Favourite, error) {
+				return &existingFav, nil
+			},
+		}
+		service := favourite.NewFavouriteService(favourite.FavouriteServiceDependencies{
+			FavouriteRepository: mockFavRepo,
+		})
+
+		// Act
+		err := service.Delete(userId, favId, &expectedVersion)
+
+		// Assert
+		assert.ErrorIs(t, err, favourite.ErrFavouriteVersionMismatch)
+	})
+
+	t.Run("should return unexpected error when repository GetById fails unexpectedly", func(t *testing.T) {
+		// Arrange
+		mockFavRepo := &mockFavouriteRepo{
+			getByIdFn: func(id uuid.UUID) (*favourite.Favourite, error) {
+				return nil, errors.New("db failure")
+			},
+		}
+		service := favourite.NewFavouriteService(favourite.FavouriteServiceDependencies{
+			FavouriteRepository: mockFavRepo,
+		})
+
+		// Act
+		err := service.Delete(userId, favId, nil)
+
+		// Assert
+		assert.ErrorIs(t, err, utils.ErrUnexpected)
+	})
+}
+
+func TestMoveService(t *testing.T) {
+	userId := uuid.New()
+	now := time.Date(2025, time.February, 1, 0, 0, 0, 0, time.UTC)
+	moved := favourite.Favourite{Id: uuid.New(), UserId: userId, Rank: "Y", Version: 2}
+	target := favourite.Favourite{Id: uuid.New(), UserId: userId, Rank: "X"}
+	newService := func(mockFavRepo *mockFavouriteRepo) favourite.FavouriteService {
+		if mockFavRepo.getByIdFn == nil {
+			mockFavRepo.getByIdFn = func(id uuid.UUID) (*favourite.Favourite, error) {
+				for _, fav := range []favourite.Favourite{moved, target} {
+					if fav.Id == id {
+						return &fav, nil
+					}
+				}
+				return nil, database.IMErrItemNotFound
+			}
+		}
+		if mockFavRepo.updateFn == nil {
+			mockFavRepo.updateFn = func(fav favourite.Favourite) (*favourite.Favourite, error) {
+				fav.Version++
+				return &fav, nil
+			}
+		}
+		service := favourite.NewFavouriteService(favourite.FavouriteServiceDependencies{
+			FavouriteRepository: mockFavRepo,
+			Now:                 func() time.Time { return now },
+		})
+		return &service
+	}
+
+	t.Run("should rank the favourite between the target and the favourite before it", func(t *testing.T) {
+		// Arrange
+		service := newService(&mockFavouriteRepo{
+			getByUserIdKeysetFn: func(uId uuid.UUID, pageSize int, cursor favourite.FavouriteCursor, before bool, options favourite.FavouriteListOptions) ([]favourite.Favourite, bool, error) {
+				assert.Equal(t, userId, uId)
+				assert.Equal(t, favourite.NewFavouriteCursor(favourite.FavouriteSortRank, target), cursor)
+				assert.True(t, before)
+				assert.Equal(t, favourite.FavouriteSortRank, options.Sort)
+				return []favourite.Favourite{{Id: uuid.New(), Rank: "U"}, {Id: uuid.New(), Rank: "V"}}, true, nil
+			},
+		})
+
+		// Act
+		result, err := service.Move(userId, moved.Id, target.Id, false, nil)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Greater(t, result.Rank, "V")
+		assert.Less(t, result.Rank, "X")
+		assert.Equal(t, 3, result.Version)
+		assert.Equal(t, now, result.UpdatedAt)
+	})
+
+	t.Run("should skip the moved favourite when it is next to the target", func(t *testing.T) {
+		// Arrange
+		service := newService(&mockFavouriteRepo{
+			getByUserIdKeysetFn: func(_ uuid.UUID, _ int, _ favourite.FavouriteCursor, before bool, _ favourite.FavouriteListOptions) ([]favourite.Favourite, bool, error) {
+				assert.False(t, before)
+				return []favourite.Favourite{moved, {Id: uuid.New(), Rank: "Z"}}, false, nil
+			},
+		})
+
+		// Act
+		result, err := service.Move(userId, moved.Id, target.Id, true, nil)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Greater(t, result.Rank, "X")
+		assert.Less(t, result.Rank, "Z")
+	})
+
+	t.Run("should pin the favourite like the target and ignore an unpinned neighbour", func(t *testing.T) {
+		// Arrange
+		pinnedTarget := favourite.Favourite{Id: uuid.New(), UserId: userId, Rank: "x", Pinned: true}
+		service := newService(&mockFavouriteRepo{
+			getByIdFn: func(id uuid.UUID) (*favourite.Favourite, error) {
+				if id == pinnedTarget.Id {
+					return &pinnedTarget, nil
+				}
+				return &moved, nil
+			},
+			getByUserIdKeysetFn: func(_ uuid.UUID, _ int, _ favourite.FavouriteCursor, _ bool, _ favourite.FavouriteListOptions) ([]favourite.Favourite, bool, error) {
+				return []favourite.Favourite{{Id: uuid.New(), Rank: "A"}}, false, nil
+			},
+		})
+
+		// Act
+		result, err := service.Move(userId, moved.Id, pinnedTarget.Id, true, nil)
+
+		// Assert
+		require.NoError(t, err)
+		assert.True(t, result.Pinned)
+		assert.Greater(t, result.Rank, "x")
+	})
+
+	t.Run("should rank the favourites without a rank ahead of the ranked ones before moving next to one of them", func(t *testing.T) {
+		// Arrange
+		first := favourite.Favourite{Id: uuid.New(), UserId: userId, Version: 1}
+		second := favourite.Favourite{Id: uuid.New(), UserId: userId, Version: 1}
+		third := favourite.Favourite{Id: uuid.New(), UserId: userId, Version: 1}
+		ranked := favourite.Favourite{Id: uuid.New(), UserId: userId, Rank: "X", Version: 1}
+		stored := map[uuid.UUID]favourite.Favourite{first.Id: first, second.Id: second, third.Id: third, ranked.Id: ranked}
+		service := newService(&mockFavouriteRepo{
+			getByIdFn: func(id uuid.UUID) (*favourite.Favourite, error) {
+				fav := stored[id]
+				return &fav, nil
+			},
+			getByUserIdPaginatedFn: func(_ uuid.UUID, pageSize, pageNumber int, options favourite.FavouriteListOptions) ([]favourite.Favourite, utils.Pagination, error) {
+				assert.Equal(t, favourite.FavouriteSortRank, options.Sort)
+				return []favourite.Favourite{first, second, third, ranked}, utils.Pagination{Page: pageNumber, PageSize: pageSize}, nil
+			},
+			writeManyFn: func(writes []favourite.FavouriteWrite, atomic bool) ([]favourite.FavouriteWriteResult, error) {
+				assert.True(t, atomic)
+				results := []favourite.FavouriteWriteResult{}
+				for _, write := range writes {
+					fav := write.Favourite
+					fav.Version++
+					stored[fav.Id] = fav
+					results = append(results, favourite.FavouriteWriteResult{Favourite: &fav})
+				}
+				return results, nil
+			},
+			getByUserIdKeysetFn: func(_ uuid.UUID, _ int, cursor favourite.FavouriteCursor, before bool, _ favourite.FavouriteListOptions) ([]favourite.Favourite, bool, error) {
+				assert.Equal(t, second.Id, cursor.Id)
+				assert.NotEmpty(t, cursor.Rank)
+				return []favourite.Favourite{stored[first.Id]}, false, nil
+			},
+		})
+
+		// Act
+		result, err := service.Move(userId, third.Id, second.Id, false, nil)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Less(t, stored[first.Id].Rank, stored[second.Id].Rank)
+		assert.Less(t, stored[second.Id].Rank, stored[third.Id].Rank)
+		assert.Less(t, stored[third.Id].Rank, ranked.Rank)
+		assert.Equal(t, ranked, stored[ranked.Id])
+		assert.Greater(t, result.Rank, stored[first.Id].Rank)
+		assert.Less(t, result.Rank, stored[second.Id].Rank)
+		assert.Equal(t, 3, result.Version)
+	})
+
+	t.Run("should return the favourite unchanged when it is moved next to itself", func(t *testing.T) {
+		// Arrange
+		service := newService(&mockFavouriteRepo{
+			updateFn: func(fav favourite.Favourite) (*favourite.Favourite, error) {
+				t.Fatal("should not update")
+				return nil, nil
+			},
+		})
+
+		// Act
+		result, err := service.Move(userId, moved.Id, moved.Id, false, nil)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, moved, *result)
+	})
+
+	t.Run("should return target not found when the target is missing or of another user", func(t *testing.T) {
+		// Arrange
+		otherUsers := favourite.Favourite{Id: uuid.New(), UserId: uuid.New(), Rank: "X"}
+		service := newService(&mockFavouriteRepo{
+			getByIdFn: func(id uuid.UUID) (*favourite.Favourite, error) {
+				switch id {
+				case moved.Id:
+					return &moved, nil
+				case otherUsers.Id:
+					return &otherUsers, nil
+				}
+				return nil, database.IMErrItemNotFound
+			},
+		})
+
+		// Act
+		_, missingErr := service.Move(userId, moved.Id, uuid.New(), false, nil)
+		_, otherUsersErr := service.Move(userId, moved.Id, otherUsers.Id, false, nil)
+
+		// Assert
+		assert.ErrorIs(t, missingErr, favourite.ErrMoveTargetNotFound)
+		assert.ErrorIs(t, otherUsersErr, favourite.ErrMoveTargetNotFound)
+	})
+
+	t.Run("should return version mismatch when favourite is not at the expected version", func(t *testing.T) {
+		// Arrange
+		expectedVersion := 1
+		service := newService(&mockFavouriteRepo{})
+
+		// Act
+		result, err := service.Move(userId, moved.Id, target.Id, false, &expectedVersion)
+
+		// Assert
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, favourite.ErrFavouriteVersionMismatch)
+	})
+}
+
+func TestCreateManyForUserService(t *testing.T) {
+	userId := uuid.New()
+	chartId := uuid.New()
+	audienceId := uuid.New()
+	missingId := uuid.New()
+	items := []favourite.CreateFavouriteRequestBody{
+		{AssetId: chartId, Description: "chart"},
+		{AssetId: missingId, Description: "missing"},
+		{AssetId: audienceId, Description: "audience"},
+	}
+
+	// Every asset repository is read once for all the items
+	newAssetRepos := func(t *testing.T) (*mockChartRepo, *mockInsightRepo, *mockAudienceRepo) {
+		all := uuid.UUIDs{chartId, missingId, audienceId}
+		return &mockChartRepo{
+			getByIdsFn: func(ids uuid.UUIDs) ([]chart.Chart, error) {
+				assert.Equal(t, all, ids)
+				return []chart.Chart{{Id: chartId}}, nil
+			},
+		}, &mockInsightRepo{
+			getByIdsFn: func(ids uuid.UUIDs) ([]insight.Insight, error) {
+				assert.Equal(t, all, ids)
+				return []insight.Insight{}, nil
+			},
+		}, &mockAudienceRepo{
+			getByIdsFn: func(ids uuid.UUIDs) ([]audience.Audience, error) {
+				assert.Equal(t, all, ids)
+				return []audience.Audience{{Id: audienceId}}, nil
+			},
+		}
+	}
+
+	t.Run("should write the items whose asset exists and report the others", func(t *testing.T) {
+		// Arrange
+		chartRepo, insightRepo, audienceRepo := newAssetRepos(t)
+		mockFavRepo := &mockFavouriteRepo{
+			getLastRankFn: func(uId uuid.UUID) (string, error) { return "V", nil },
+			writeManyFn: func(writes []favourite.FavouriteWrite, atomic bool) ([]favourite.FavouriteWriteResult, error) {
+				assert.False(t, atomic)
+				results := []favourite.FavouriteWriteResult{}
+				if assert.Len(t, writes, 2) {
+					assert.Greater(t, writes[0].Favourite.Rank, "V")
+					assert.Greater(t, writes[1].Favourite.Rank, writes[0].Favourite.Rank)
+					assert.Equal(t, favourite.FavouriteWriteCreate, writes[0].Op)
+					assert.Equal(t, favourite.AssetTypeChart, writes[0].Favourite.AssetType)
+					assert.Equal(t, userId, writes[0].Favourite.UserId)
+					assert.Equal(t, 1, writes[0].Favourite.Version)
+					assert.Equal(t, favourite.AssetTypeAudience, writes[1].Favourite.AssetType)
+					assert.Equal(t, "audience", writes[1].Favourite.Description)
+					results = append(results, favourite.FavouriteWriteResult{Favourite: &writes[0].Favourite})
+					results = append(results, favourite.FavouriteWriteResult{Err: errors.New("db error")})
+				}
+				return results, nil
+			},
+		}
+		service := favourite.NewFavouriteService(favourite.FavouriteServiceDependencies{
+			FavouriteRepository: mockFavRepo,
+			Assets:              newAssetRegistry(chartRepo, insightRepo, audienceRepo),
+		})
+
+		// Act
+		results, err := service.CreateManyForUser(userId, items, false)
+
+		// Assert
+		assert.NoError(t, err)
+		if assert.Len(t, results, 3) {
+			assert.NoError(t, results[0].Err)
+			assert.Equal(t, chartId, results[0].Favourite.AssetId)
+			assert.ErrorIs(t, results[1].Err, favourite.ErrAssetNotFound)
+			assert.ErrorIs(t, results[2].Err, favourite.ErrCouldNotSaveFavourite)
+		}
+	})
+
+	t.Run("should not write an atomic batch with a missing asset", func(t *testing.T) {
+		// Arrange
+		chartRepo, insightRepo, audienceRepo := newAssetRepos(t)
+		service := favourite.NewFavouriteService(favourite.FavouriteServiceDependencies{
+			FavouriteRepository: &mockFavouriteRepo{
+				getLastRankFn: func(uId uuid.UUID) (string, error) { return "", nil },
+			},
+			Assets: newAssetRegistry(chartRepo, insightRepo, audienceRepo),
+		})
+
+		// Act
+		results, err := service.CreateManyForUser(userId, items, true)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, []favourite.FavouriteWriteResult{
+			{Err: favourite.ErrFavouriteBatchRolledBack},
+			{Err: favourite.ErrAssetNotFound},
+			{Err: favourite.ErrFavouriteBatchRolledBack},
+		}, results)
+	})
+
+	t.Run("should return error when an asset repository fails", func(t *testing.T) {
+		// Arrange
+		chartRepo, insightRepo, audienceRepo := newAssetRepos(t)
+		insightRepo.getByIdsFn = func(ids uuid.UUIDs) ([]insight.Insight, error) {
+			return nil, errors.New("db error")
+		}
+		service := favourite.NewFavouriteService(favourite.FavouriteServiceDependencies{
+			FavouriteRepository: &mockFavouriteRepo{},
+			Assets:              newAssetRegistry(chartRepo, insightRepo, audienceRepo),
+		})
+
+		// Act
+		results, err := service.CreateManyForUser(userId, items, false)
+
+		// Assert
+		assert.Error(t, err)
+		assert.Nil(t, results)
+	})
+}
+
+func TestUpdateManyService(t *testing.T) {
+	userId := uuid.New()
+	now := time.Date(2025, time.February, 1, 0, 0, 0, 0, time.UTC)
+	owned := favourite.Favourite{Id: uuid.New(), UserId: userId, Description: "old", Version: 2}
+	kept := favourite.Favourite{Id: uuid.New(), UserId: userId, Description: "kept", Version: 1}
+	other := favourite.Favourite{Id: uuid.New(), UserId: uuid.New(), Version: 1}
+	missingId := uuid.New()
+	staleVersion := 1
+
+	items := []favourite.UpdateFavouritesBatchItem{
+		{Id: owned.Id, Description: ptr("new")},
+		{Id: missingId, Description: ptr("missing")},
+		{Id: other.Id, Description: ptr("other")},
+		{Id: owned.Id, Description: ptr("stale"), Version: &staleVersion},
+		{Id: kept.Id},
+	}
+	getByIds := func(ids uuid.UUIDs) ([]favourite.Favourite, error) {
+		assert.Equal(t, uuid.UUIDs{owned.Id, missingId, other.Id, owned.Id, kept.Id}, ids)
+		return []favourite.Favourite{owned, other, kept}, nil
+	}
+
+	t.Run("should write the items that pass the checks with the version it read", func(t *testing.T) {
+		// Arrange
+		mockFavRepo := &mockFavouriteRepo{
+			getByIdsFn: getByIds,
+			writeManyFn: func(writes []favourite.FavouriteWrite, atomic bool) ([]favourite.FavouriteWriteResult, error) {
+				results := []favourite.FavouriteWriteResult{}
+				if assert.Len(t, writes, 2) {
+					assert.Equal(t, favourite.FavouriteWriteUpdate, writes[0].Op)
+					assert.Equal(t, "new", writes[0].Favourite.Description)
+					assert.Equal(t, 2, writes[0].Favourite.Version)
+					assert.Equal(t, now, writes[0].Favourite.UpdatedAt)
+					assert.Equal(t, "kept", writes[1].Favourite.Description)
+					for _, write := range writes {
+						results = append(results, favourite.FavouriteWriteResult{Favourite: &write.Favourite})
+					}
+				}
+				return results, nil
+			},
+		}
+		service := favourite.NewFavouriteService(favourite.FavouriteServiceDependencies{
+			FavouriteRepository: mockFavRepo,
+			Now:                 func() time.Time { return now },
+		})
+
+		// Act
+		results, err := service.UpdateMany(userId, items, false)
+
+		// Assert
+		assert.NoError(t, err)
+		if assert.Len(t, results, 5) {
+			assert.NoError(t, results[0].Err)
+			assert.ErrorIs(t, results[1].Err, favourite.ErrFavouriteNotFound)
+			assert.ErrorIs(t, results[2].Err, favourite.ErrFavouriteNotUnderGivenUser)
+			assert.ErrorIs(t, results[3].Err, favourite.ErrFavouriteVersionMismatch)
+			assert.NoError(t, results[4].Err)
+		}
+	})
+
+	t.Run("should report the items with invalid tags and write the others", func(t *testing.T) {
+		// Arrange
+		tagItems := []favourite.UpdateFavouritesBatchItem{
+			{Id: owned.Id, Tags: []string{"a,b"}},
+			{Id: kept.Id, Tags: []string{"Q2"}},
+		}
+		mockFavRepo := &mockFavouriteRepo{
+			getByIdsFn: func(ids uuid.UUIDs) ([]favourite.Favourite, error) {
+				return []favourite.Favourite{owned, kept}, nil
+			},
+			writeManyFn: func(writes []favourite.FavouriteWrite, atomic bool) ([]favourite.FavouriteWriteResult, error) {
+				results := []favourite.FavouriteWriteResult{}
+				if assert.Len(t, writes, 1) {
+					assert.Equal(t, kept.Id, writes[0].Favourite.Id)
+					assert.Equal(t, []string{"q2"}, writes[0].Favourite.Tags)
+					results = append(results, favourite.FavouriteWriteResult{Favourite: &writes[0].Favourite})
+				}
+				return results, nil
+			},
+		}
+		service := favourite.NewFavouriteService(favourite.FavouriteServiceDependencies{
+			FavouriteRepository: mockFavRepo,
+		})
+
+		// Act
+		results, err := service.UpdateMany(userId, tagItems, false)
+
+		// Assert
+		assert.NoError(t, err)
+		if assert.Len(t, results, 2) {
+			assert.ErrorIs(t, results[0].Err, favourite.ErrInvalidTag)
+			assert.NoError(t, results[1].Err)
+		}
+	})
+
+	t.Run("should keep the results of the repository for an atomic batch that passes the checks", func(t *testing.T) {
+		// Arrange
+		mockFavRepo := &mockFavouriteRepo{
+			getByIdsFn: func(ids uuid.UUIDs) ([]favourite.Favourite, error) {
+				return []favourite.Favourite{owned}, nil
+			},
+			writeManyFn: func(writes []favourite.FavouriteWrite, atomic bool) ([]favourite.FavouriteWriteResult, error) {
+				assert.True(t, atomic)
+				return []favourite.FavouriteWriteResult{{Err: favourite.ErrFavouriteVersionMismatch}}, nil
+			},
+		}
+		service := favourite.NewFavouriteService(favourite.FavouriteServiceDependencies{
+			FavouriteRepository: mockFavRepo,
+		})
+
+		// Act
+		results, err := service.UpdateMany(userId, []favourite.UpdateFavouritesBatchItem{{Id: owned.Id}}, true)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, []favourite.FavouriteWriteResult{{Err: favourite.ErrFavouriteVersionMismatch}}, results)
+	})
+
+	t.Run("should report the storage failures of items and of the whole batch as could not save", func(t *testing.T) {
+		// Arrange
+		writeErr := errors.New("disk full")
+		mockFavRepo := &mockFavouriteRepo{
+			getByIdsFn: func(ids uuid.UUIDs) ([]favourite.Favourite, error) {
+				return []favourite.Favourite{owned, kept}, nil
+			},
+			writeManyFn: func(writes []favourite.FavouriteWrite, atomic bool) ([]favourite.FavouriteWriteResult, error) {
+				if atomic {
+					return nil, writeErr
+				}
+				return []favourite.FavouriteWriteResult{{Err: writeErr}, {Err: favourite.ErrFavouriteVersionMismatch}}, nil
+			},
+		}
+		service := favourite.NewFavouriteService(favourite.FavouriteServiceDependencies{
+			FavouriteRepository: mockFavRepo,
+		})
+		batch := []favourite.UpdateFavouritesBatchItem{{Id: owned.Id}, {Id: kept.Id}}
+
+		// Act
+		results, err := service.UpdateMany(userId, batch, false)
+		atomicResults, atomicErr := service.UpdateMany(userId, batch, true)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, []favourite.FavouriteWriteResult{
+			{Err: favourite.ErrCouldNotSaveFavourite},
+			{Err: favourite.ErrFavouriteVersionMismatch},
+		}, results)
+		assert.Nil(t, atomicResults)
+		assert.ErrorIs(t, atomicErr, favourite.ErrCouldNotSaveFavourite)
+	})
+
+	t.Run("should return unexpected error when repository GetByIds fails", func(t *testing.T) {
+		// Arrange
+		mockFavRepo := &mockFavouriteRepo{
+			getByIdsFn: func(ids uuid.UUIDs) ([]favourite.Favourite, error) {
+				return nil, errors.New("db failure")
+			},
+		}
+		service := favourite.NewFavouriteService(favourite.FavouriteServiceDependencies{
+			FavouriteRepository: mockFavRepo,
+		})
+
+		// Act
+		results, err := service.UpdateMany(userId, items, false)
+
+		// Assert
+		assert.Nil(t, results)
+		assert.ErrorIs(t, err, utils.ErrUnexpected)
+	})
+}
+
+func TestDeleteManyService(t *testing.T) {
+	t.Run("should delete the owned favourites at the version it read and roll back the rest when atomic", func(t *testing.T) {
+		// Arrange
+		userId := uuid.New()
+		now := time.Date(2025, time.February, 1, 0, 0, 0, 0, time.UTC)
+		owned := favourite.Favourite{Id: uuid.New(), UserId: userId, Version: 4}
+		other := favourite.Favourite{Id: uuid.New(), UserId: uuid.New(), Version: 1}
+		mockFavRepo := &mockFavouriteRepo{
+			getByIdsFn: func(ids uuid.UUIDs) ([]favourite.Favourite, error) {
+				return []favourite.Favourite{owned, other}, nil
+			},
+			writeManyFn: func(writes []favourite.FavouriteWrite, atomic bool) ([]favourite.FavouriteWriteResult, error) {
+				assert.Equal(t, []favourite.FavouriteWrite{{Op: favourite.FavouriteWriteDelete, Favourite: owned, DeletedAt: now}}, writes)
+				return []favourite.FavouriteWriteResult{{}}, nil
+			},
+		}
+		service := favourite.NewFavouriteService(favourite.FavouriteServiceDependencies{
+			FavouriteRepository: mockFavRepo,
+			Now:                 func() time.Time { return now },
+		})
+		items := []favourite.DeleteFavouritesBatchItem{{Id: owned.Id}, {Id: other.Id}}
+
+		// Act
+		results, err := service.DeleteMany(userId, items, false)
+		atomicResults, atomicErr := service.DeleteMany(userId, items, true)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, []favourite.FavouriteWriteResult{{}, {Err: favourite.ErrFavouriteNotUnderGivenUser}}, results)
+		assert.NoError(t, atomicErr)
+		assert.Equal(t, []favourite.FavouriteWriteResult{
+			{Err: favourite.ErrFavouriteBatchRolledBack},
+			{Err: favourite.ErrFavouriteNotUnderGivenUser},
+		}, atomicResults)
+	})
+
+	t.Run("should report the storage failure of an item as could not save", func(t *testing.T) {
+		// Arrange
+		userId := uuid.New()
+		owned := favourite.Favourite{Id: uuid.New(), UserId: userId, Version: 1}
+		mockFavRepo := &mockFavouriteRepo{
+			getByIdsFn: func(ids uuid.UUIDs) ([]favourite.Favourite, error) {
+				return []favourite.Favourite{owned}, nil
+			},
+			writeManyFn: func(writes []favourite.FavouriteWrite, atomic bool) ([]favourite.FavouriteWriteResult, error) {
+				return []favourite.FavouriteWriteResult{{Err: errors.New("disk full")}}, nil
+			},
+		}
+		service := favourite.NewFavouriteService(favourite.FavouriteServiceDependencies{
+			FavouriteRepository: mockFavRepo,
+		})
+
+		// Act
+		results, err := service.DeleteMany(userId, []favourite.DeleteFavouritesBatchItem{{Id: owned.Id}}, false)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, []favourite.FavouriteWriteResult{{Err: favourite.ErrCouldNotSaveFavourite}}, results)
+	})
+}
+
+func TestGetTagsForUserService(t *testing.T) {
+	t.Run("should read the tag counts of the user with a normalised prefix", func(t *testing.T) {
+		// Arrange
+		userId := uuid.New()
+		counts := []favourite.TagCount{{Tag: "q2 review", Count: 3}}
+		mockFavRepo := &mockFavouriteRepo{
+			getTagCountsFn: func(uId uuid.UUID, prefix string, limit int) ([]favourite.TagCount, error) {
+				assert.Equal(t, userId, uId)
+				assert.Equal(t, "q2 rev", prefix)
+				assert.Equal(t, 5, limit)
+				return counts, nil
+			},
+		}
+		service := favourite.NewFavouriteService(favourite.FavouriteServiceDependencies{
+			FavouriteRepository: mockFavRepo,
+		})
+
+		// Act
+		result, err := service.GetTagsForUser(userId, " Q2  Rev", 5)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, counts, result)
+	})
+}
+
+func TestTrashService(t *testing.T) {
+	userId := uuid.New()
+	now := time.Date(2025, time.March, 31, 0, 0, 0, 0, time.UTC)
+	retention := 24 * time.Hour
+	trashed := favourite.TrashedFavourite{
+		Favourite: favourite.Favourite{Id: uuid.New(), UserId: userId, AssetId: uuid.New(), AssetType: favourite.AssetTypeChart, Version: 2},
+		DeletedAt: now.Add(-time.Hour),
+	}
+	newServiceWithCharts := func(mockFavRepo *mockFavouriteRepo, charts []chart.Chart) favourite.FavouriteService {
+		if mockFavRepo.getTrashedByIdFn == nil {
+			mockFavRepo.getTrashedByIdFn = func(id uuid.UUID) (*favourite.TrashedFavourite, error) {
+				copied := trashed
+				return &copied, nil
+			}
+		}
+		chartRepo := &mockChartRepo{getByIdsFn: func(ids uuid.UUIDs) ([]chart.Chart, error) { return charts, nil }}
+		service := favourite.NewFavouriteService(favourite.FavouriteServiceDependencies{
+			FavouriteRepository: mockFavRepo,
+			Assets:              newAssetRegistry(chartRepo, &mockInsightRepo{}, &mockAudienceRepo{}),
+			Now:                 func() time.Time { return now },
+			TrashRetention:      retention,
+		})
+		return &service
+	}
+	newService := func(mockFavRepo *mockFavouriteRepo) favourite.FavouriteService {
+		return newServiceWithCharts(mockFavRepo, []chart.Chart{{Id: trashed.AssetId}})
+	}
+
+	t.Run("should list the trash that has not expired with when every favourite expires", func(t *testing.T) {
+		// Arrange
+		service := newService(&mockFavouriteRepo{
+			getTrashedPaginatedFn: func(uId uuid.UUID, deletedAfter time.Time, pageSize, pageNumber int) ([]favourite.TrashedFavourite, utils.Pagination, error) {
+				assert.Equal(t, userId, uId)
+				assert.Equal(t, now.Add(-retention), deletedAfter)
+				return []favourite.TrashedFavourite{trashed}, utils.Pagination{Page: pageNumber, PageSize: pageSize}, nil
+			},
+		})
+
+		// Act
+		result, pagination, err := service.GetTrashForUser(userId, 10, 0)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, &utils.Pagination{PageSize: 10}, pagination)
+		if assert.Len(t, result, 1) {
+			assert.Equal(t, trashed.DeletedAt.Add(retention), result[0].ExpiresAt)
+		}
+	})
+
+	t.Run("should restore a favourite of the user at the current time", func(t *testing.T) {
+		// Arrange
+		restored := trashed.Favourite
+		service := newService(&mockFavouriteRepo{
+			restoreFn: func(id uuid.UUID, restoredAt time.Time) (*favourite.Favourite, error) {
+				assert.Equal(t, trashed.Id, id)
+				assert.Equal(t, now, restoredAt)
+				return &restored, nil
+			},
+		})
+
+		// Act
+		result, err := service.Restore(userId, trashed.Id)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, &restored, result)
+	})
+
+	t.Run("should not restore a favourite whose asset was deleted", func(t *testing.T) {
+		// Arrange
+		service := newServiceWithCharts(&mockFavouriteRepo{
+			restoreFn: func(id uuid.UUID, restoredAt time.Time) (*favourite.Favourite, error) {
+				t.Fatal("favourite restored without its asset")
+				return nil, nil
+			},
+		}, []chart.Chart{})
+
+		// Act
+		_, err := service.Restore(userId, trashed.Id)
+
+		// Assert
+		assert.ErrorIs(t, err, favourite.ErrAssetNotFound)
+	})
+
+	t.Run("should not restore or purge a favourite that is not in the trash or belongs to another user", func(t *testing.T) {
+		// Arrange
+		missing := newService(&mockFavouriteRepo{
+			getTrashedByIdFn: func(id uuid.UUID) (*favourite.TrashedFavourite, error) {
+				return nil, database.ErrItemNotFound
+			},
+		})
+		owned := newService(&mockFavouriteRepo{})
+
+		// Act
+		_, missingErr := missing.Restore(userId, trashed.Id)
+		purgeMissingErr := missing.Purge(userId, trashed.Id)
+		_, otherErr := owned.Restore(uuid.New(), trashed.Id)
+		purgeOtherErr := owned.Purge(uuid.New(), trashed.Id)
+
+		// Assert
+		assert.ErrorIs(t, missingErr, favourite.ErrFavouriteNotFound)
+		assert.ErrorIs(t, purgeMissingErr, favourite.ErrFavouriteNotFound)
+		assert.ErrorIs(t, otherErr, favourite.ErrFavouriteNotUnderGivenUser)
+		assert.ErrorIs(t, purgeOtherErr, favourite.ErrFavouriteNotUnderGivenUser)
+	})
+
+	t.Run("should treat a favourite that expired before it was purged as purged", func(t *testing.T) {
+		// Arrange
+		service := newService(&mockFavouriteRepo{
+			getTrashedByIdFn: func(id uuid.UUID) (*favourite.TrashedFavourite, error) {
+				expired := trashed
+				expired.DeletedAt = now.Add(-retention)
+				return &expired, nil
+			},
+		})
+
+		// Act
+		_, err := service.Restore(userId, trashed.Id)
+
+		// Assert
+		assert.ErrorIs(t, err, favourite.ErrFavouriteNotFound)
+	})
+
+	t.Run("should purge a favourite of the user", func(t *testing.T) {
+		// Arrange
+		purged := uuid.UUIDs{}
+		service := newService(&mockFavouriteRepo{
+			purgeFn: func(id uuid.UUID) error {
+				purged = append(purged, id)
+				return nil
+			},
+		})
+
+		// Act
+		err := service.Purge(userId, trashed.Id)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, uuid.UUIDs{trashed.Id}, purged)
+	})
+
+	t.Run("should purge the favourites deleted longer than the retention ago", func(t *testing.T) {
+		// Arrange
+		mockFavRepo := &mockFavouriteRepo{
+			purgeDeletedBeforeFn: func(before time.Time) (int, error) {
+				assert.Equal(t, now.Add(-retention), before)
+				return 3, nil
+			},
+		}
+		service := favourite.NewFavouriteService(favourite.FavouriteServiceDependencies{
+			FavouriteRepository: mockFavRepo,
+			Now:                 func() time.Time { return now },
+			TrashRetention:      retention,
+		})
+
+		// Act
+		purged, err := service.PurgeExpiredTrash()
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, 3, purged)
+	})
+
+	t.Run("should purge no more once the purger is stopped", func(t *testing.T) {
+		// Arrange
+		var purges atomic.Int32
+		service := favourite.NewFavouriteService(favourite.FavouriteServiceDependencies{
+			FavouriteRepository: &mockFavouriteRepo{
+				purgeDeletedBeforeFn: func(time.Time) (int, error) {
+					purges.Add(1)
+					return 0, nil
+				},
+			},
+			Now:            func() time.Time { return now },
+			TrashRetention: retention,
+		})
+		stop := service.StartTrashPurger(time.Millisecond)
+		time.Sleep(10 * time.Millisecond)
+
+		// Act
+		stop()
+		stopped := purges.Load()
+		time.Sleep(10 * time.Millisecond)
+
+		// Assert
+		assert.Positive(t, stopped)
+		assert.Equal(t, stopped, purges.Load())
+	})
+}
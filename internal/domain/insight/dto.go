@@ -1,8 +1,79 @@
 package insight
 
-import "github.com/google/uuid"
+import (
+	"platform-go-challenge/internal/utils"
+
+	"github.com/google/uuid"
+)
 
 type Insight struct {
-	Id   uuid.UUID
-	Text string
+	Id   uuid.UUID `json:"id"`
+	Text string    `json:"text"`
+}
+
+// AssetId identifies the insight as the asset of a favourite.
+func (insight Insight) AssetId() uuid.UUID {
+	return insight.Id
+}
+
+// LegacyInsight is an insight with the Id and Text keys insights were serialised with before they had JSON tags
+// next to the snake_case ones, for the clients that still read them.
+type LegacyInsight struct {
+	Insight
+	LegacyId   uuid.UUID `json:"Id"`
+	LegacyText string    `json:"Text"`
+}
+
+func NewLegacyInsight(insight Insight) LegacyInsight {
+	return LegacyInsight{
+		Insight:    insight,
+		LegacyId:   insight.Id,
+		LegacyText: insight.Text,
+	}
+}
+
+// LegacyGetByIds reads the insights with getByIds as LegacyInsight, so the favourites list them with the legacy keys.
+func LegacyGetByIds(getByIds func(ids uuid.UUIDs) ([]Insight, error)) func(ids uuid.UUIDs) ([]LegacyInsight, error) {
+	return func(ids uuid.UUIDs) ([]LegacyInsight, error) {
+		insights, err := getByIds(ids)
+		if err != nil {
+			return nil, err
+		}
+
+		result := make([]LegacyInsight, 0, len(insights))
+		for _, insight := range insights {
+			result = append(result, NewLegacyInsight(insight))
+		}
+
+		return result, nil
+	}
+}
+
+// InsightChanges are the fields an update sets, nil fields are left as they are.
+type InsightChanges struct {
+	Text *string
+}
+
+type CreateInsightRequestBody struct {
+	Text string `json:"text" validate:"required,max=500"`
+}
+
+// UpdateInsightRequestBody is a merge patch, it leaves out the fields that are not changed, see UpdateInsightRequestBody.Changes.
+type UpdateInsightRequestBody struct {
+	utils.MergePatch
+	Text *string `json:"text" validate:"omitnil,max=500"`
+}
+
+// Changes returns the changes of the patch. The text cannot be cleared, so a text set to null is blank and rejected.
+func (body UpdateInsightRequestBody) Changes() InsightChanges {
+	changes := InsightChanges{}
+
+	if body.Has("text") {
+		changes.Text = body.Text
+		if changes.Text == nil {
+			changes.Text = new(string)
+		}
+	}
+
+	return changes
 }
@@ -0,0 +1,65 @@
+package insight_test
+
+import (
+	"encoding/json"
+	"platform-go-challenge/internal/domain/insight"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInsightJSON(t *testing.T) {
+	id := uuid.MustParse("22222222-2222-2222-2222-222222222222")
+
+	t.Run("should serialise with snake_case keys", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		value := insight.Insight{Id: id, Text: "Most users log in on mobile"}
+
+		// Act
+		result, err := json.Marshal(value)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"id":"22222222-2222-2222-2222-222222222222","text":"Most users log in on mobile"}`, string(result))
+	})
+
+	t.Run("should also serialise with the legacy keys as a legacy insight", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		value := insight.NewLegacyInsight(insight.Insight{Id: id, Text: "Most users log in on mobile"})
+
+		// Act
+		result, err := json.Marshal(value)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{
+			"id":"22222222-2222-2222-2222-222222222222",
+			"text":"Most users log in on mobile",
+			"Id":"22222222-2222-2222-2222-222222222222",
+			"Text":"Most users log in on mobile"
+		}`, string(result))
+	})
+}
+
+func TestLegacyGetByIds(t *testing.T) {
+	t.Run("should read the insights as legacy insights", func(t *testing.T) {
+		// Arrange
+		stored := insight.Insight{Id: uuid.New(), Text: "Most users log in on mobile"}
+		getByIds := insight.LegacyGetByIds(func(ids uuid.UUIDs) ([]insight.Insight, error) {
+			assert.Equal(t, uuid.UUIDs{stored.Id}, ids)
+			return []insight.Insight{stored}, nil
+		})
+
+		// Act
+		result, err := getByIds(uuid.UUIDs{stored.Id})
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, []insight.LegacyInsight{insight.NewLegacyInsight(stored)}, result)
+	})
+}
@@ -0,0 +1,11 @@
+package insight
+
+import "errors"
+
+var (
+	ErrInsightNotFound     = errors.New("Insight not found")
+	ErrInvalidInsightText  = errors.New("Insight text must not be blank")
+	ErrInsightTextTaken    = errors.New("An insight with this text already exists")
+	ErrCouldNotSaveInsight = errors.New("Could not save insight")
+	ErrInsightFavourited   = errors.New("Insight is a favourite of some users")
+)
@@ -0,0 +1,188 @@
+package insight
+
+import (
+	"errors"
+	"net/http"
+	"platform-go-challenge/internal/utils"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// respondWithInsightError answers the errors every write of an insight can fail with.
+func respondWithInsightError(w http.ResponseWriter, err error) {
+	if errors.Is(err, ErrInsightNotFound) {
+		utils.RespondWithError(w, http.StatusNotFound, "Could not find Insight with this Id")
+		return
+	}
+	if errors.Is(err, ErrInsightFavourited) {
+		utils.RespondWithError(w, http.StatusConflict, "Insight is a favourite of some users, it cannot be deleted")
+		return
+	}
+	if errors.Is(err, ErrInvalidInsightText) {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if errors.Is(err, ErrInsightTextTaken) {
+		utils.RespondWithError(w, http.StatusConflict, err.Error())
+		return
+	}
+
+	utils.RespondWithError(w, http.StatusInternalServerError, "Internal Server Error")
+}
+
+// insightResponse is the insight as it is sent, with the legacy keys when legacyJSON is set.
+func insightResponse(insight *Insight, legacyJSON bool) any {
+	if insight == nil || !legacyJSON {
+		return insight
+	}
+
+	return NewLegacyInsight(*insight)
+}
+
+type GetInsightsHandlerDependencies struct {
+	InsightService InsightService
+	// LegacyJSON sends the insights with the legacy keys too, see LegacyInsight
+	LegacyJSON bool
+}
+
+func GetInsightsHandler(dependencies GetInsightsHandlerDependencies) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		pageSize, pageNumber, err := utils.GetPaginationQuery(r, 10, 0)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		insights, pagination, err := dependencies.InsightService.GetPaginated(pageSize, pageNumber)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusInternalServerError, "Internal Server Error")
+			return
+		}
+
+		if !dependencies.LegacyJSON {
+			utils.RespondWithPaginatedData(w, http.StatusOK, insights, *pagination)
+			return
+		}
+
+		legacyInsights := make([]LegacyInsight, 0, len(insights))
+		for _, insight := range insights {
+			legacyInsights = append(legacyInsights, NewLegacyInsight(insight))
+		}
+
+		utils.RespondWithPaginatedData(w, http.StatusOK, legacyInsights, *pagination)
+	}
+}
+
+type GetInsightHandlerDependencies struct {
+	InsightService InsightService
+	// LegacyJSON sends the insights with the legacy keys too, see LegacyInsight
+	LegacyJSON bool
+}
+
+func GetInsightHandler(dependencies GetInsightHandlerDependencies) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		insightId, err := uuid.Parse(chi.URLParam(r, "id"))
+		if err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "Insight Id param is not a UUID")
+			return
+		}
+
+		insight, err := dependencies.InsightService.Get(insightId)
+		if err != nil {
+			respondWithInsightError(w, err)
+			return
+		}
+
+		utils.RespondWithData(w, http.StatusOK, insightResponse(insight, dependencies.LegacyJSON))
+	}
+}
+
+type CreateInsightHandlerDependencies struct {
+	InsightService InsightService
+	// LegacyJSON sends the insights with the legacy keys too, see LegacyInsight
+	LegacyJSON bool
+}
+
+func CreateInsightHandler(dependencies CreateInsightHandlerDependencies) http.HandlerFunc {
+	validation := utils.BodyValidator[CreateInsightRequestBody]
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		body, ok := utils.GetParsedBody[CreateInsightRequestBody](r)
+		if !ok {
+			// Should not happen since we validate body before getting in to handler
+			utils.RespondWithError(w, http.StatusInternalServerError, "Internal Server Error")
+			return
+		}
+
+		insight, err := dependencies.InsightService.Create(body.Text)
+		if err != nil {
+			if errors.Is(err, ErrInsightTextTaken) {
+				utils.RespondWithErrorAndData(w, http.StatusConflict, err.Error(), insightResponse(insight, dependencies.LegacyJSON))
+				return
+			}
+
+			respondWithInsightError(w, err)
+			return
+		}
+
+		utils.RespondWithData(w, http.StatusCreated, insightResponse(insight, dependencies.LegacyJSON))
+	}
+
+	return validation(handler)
+}
+
+type UpdateInsightHandlerDependencies struct {
+	InsightService InsightService
+	// LegacyJSON sends the insights with the legacy keys too, see LegacyInsight
+	LegacyJSON bool
+}
+
+func UpdateInsightHandler(dependencies UpdateInsightHandlerDependencies) http.HandlerFunc {
+	validation := utils.BodyValidator[UpdateInsightRequestBody]
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		insightId, err := uuid.Parse(chi.URLParam(r, "id"))
+		if err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "Insight Id param is not a UUID")
+			return
+		}
+
+		body, ok := utils.GetParsedBody[UpdateInsightRequestBody](r)
+		if !ok {
+			// Should not happen since we validate body before getting in to handler
+			utils.RespondWithError(w, http.StatusInternalServerError, "Internal Server Error")
+			return
+		}
+
+		insight, err := dependencies.InsightService.Update(insightId, body.Changes())
+		if err != nil {
+			respondWithInsightError(w, err)
+			return
+		}
+
+		utils.RespondWithData(w, http.StatusOK, insightResponse(insight, dependencies.LegacyJSON))
+	}
+
+	return validation(handler)
+}
+
+type DeleteInsightHandlerDependencies struct {
+	InsightService InsightService
+}
+
+func DeleteInsightHandler(dependencies DeleteInsightHandlerDependencies) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		insightId, err := uuid.Parse(chi.URLParam(r, "id"))
+		if err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "Insight Id param is not a UUID")
+			return
+		}
+
+		err = dependencies.InsightService.Delete(insightId)
+		if err != nil {
+			respondWithInsightError(w, err)
+			return
+		}
+
+		utils.RespondWithMessage(w, http.StatusOK, "Insight deleted")
+	}
+}
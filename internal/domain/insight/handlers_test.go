@@ -0,0 +1,253 @@
+package insight_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"platform-go-challenge/internal/domain/insight"
+	"platform-go-challenge/internal/utils"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+type StubInsightService struct {
+	GetPaginatedFunc func(pageSize, pageNumber int) ([]insight.Insight, *utils.Pagination, error)
+	GetFunc          func(insightId uuid.UUID) (*insight.Insight, error)
+	CreateFunc       func(text string) (*insight.Insight, error)
+	UpdateFunc       func(insightId uuid.UUID, changes insight.InsightChanges) (*insight.Insight, error)
+	DeleteFunc       func(insightId uuid.UUID) error
+}
+
+func (s *StubInsightService) GetPaginated(pageSize, pageNumber int) ([]insight.Insight, *utils.Pagination, error) {
+	if s.GetPaginatedFunc != nil {
+		return s.GetPaginatedFunc(pageSize, pageNumber)
+	}
+	return nil, nil, errors.New("not implemented")
+}
+
+func (s *StubInsightService) Get(insightId uuid.UUID) (*insight.Insight, error) {
+	if s.GetFunc != nil {
+		return s.GetFunc(insightId)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (s *StubInsightService) Create(text string) (*insight.Insight, error) {
+	if s.CreateFunc != nil {
+		return s.CreateFunc(text)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (s *StubInsightService) Update(insightId uuid.UUID, changes insight.InsightChanges) (*insight.Insight, error) {
+	if s.UpdateFunc != nil {
+		return s.UpdateFunc(insightId, changes)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (s *StubInsightService) Delete(insightId uuid.UUID) error {
+	if s.DeleteFunc != nil {
+		return s.DeleteFunc(insightId)
+	}
+	return errors.New("not implemented")
+}
+
+// withInsightId sets the id route param chi would have matched.
+func withInsightId(req *http.Request, insightId string) *http.Request {
+	ctx := chi.NewRouteContext()
+	ctx.URLParams.Add("id", insightId)
+
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, ctx))
+}
+
+func TestGetInsightsHandler(t *testing.T) {
+	t.Run("Should return 200 with the page of insights", func(t *testing.T) {
+		// Arrange
+		insights := []insight.Insight{{Id: uuid.New(), Text: "Most users log in on mobile"}}
+		stubService := &StubInsightService{
+			GetPaginatedFunc: func(pageSize, pageNumber int) ([]insight.Insight, *utils.Pagination, error) {
+				return insights, &utils.Pagination{Page: pageNumber, PageSize: pageSize, MaxPage: 0}, nil
+			},
+		}
+		handler := insight.GetInsightsHandler(insight.GetInsightsHandlerDependencies{InsightService: stubService})
+
+		req := httptest.NewRequest(http.MethodGet, "/admin/insights", nil)
+		w := httptest.NewRecorder()
+
+		// Act
+		handler(w, req)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+		var response utils.PaginatedDataResponse[[]insight.Insight]
+		assert.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+		assert.Equal(t, insights, response.Data)
+		assert.Equal(t, utils.Pagination{Page: 0, PageSize: 10, MaxPage: 0}, response.Pagination)
+	})
+
+	t.Run("Should also send the legacy keys when legacy JSON is enabled", func(t *testing.T) {
+		// Arrange
+		id := uuid.MustParse("22222222-2222-2222-2222-222222222222")
+		stubService := &StubInsightService{
+			GetPaginatedFunc: func(pageSize, pageNumber int) ([]insight.Insight, *utils.Pagination, error) {
+				return []insight.Insight{{Id: id, Text: "Most users log in on mobile"}}, &utils.Pagination{PageSize: pageSize}, nil
+			},
+		}
+		handler := insight.GetInsightsHandler(insight.GetInsightsHandlerDependencies{InsightService: stubService, LegacyJSON: true})
+
+		req := httptest.NewRequest(http.MethodGet, "/admin/insights", nil)
+		w := httptest.NewRecorder()
+
+		// Act
+		handler(w, req)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+		var response utils.PaginatedDataResponse[[]map[string]string]
+		assert.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+		assert.Equal(t, []map[string]string{{
+			"id":   "22222222-2222-2222-2222-222222222222",
+			"text": "Most users log in on mobile",
+			"Id":   "22222222-2222-2222-2222-222222222222",
+			"Text": "Most users log in on mobile",
+		}}, response.Data)
+	})
+}
+
+func TestCreateInsightHandler(t *testing.T) {
+	t.Run("Should return 201 when insight is created successfully", func(t *testing.T) {
+		// Arrange
+		expected := &insight.Insight{Id: uuid.New(), Text: "Most users log in on mobile"}
+		stubService := &StubInsightService{
+			CreateFunc: func(text string) (*insight.Insight, error) {
+				assert.Equal(t, "Most users log in on mobile", text)
+				return expected, nil
+			},
+		}
+		handler := insight.CreateInsightHandler(insight.CreateInsightHandlerDependencies{InsightService: stubService})
+
+		req := httptest.NewRequest(http.MethodPost, "/admin/insights", bytes.NewReader([]byte(`{"text": "Most users log in on mobile"}`)))
+		w := httptest.NewRecorder()
+
+		// Act
+		handler(w, req)
+
+		// Assert
+		assert.Equal(t, http.StatusCreated, w.Result().StatusCode)
+		assert.JSONEq(t, `{"data": {"id": "`+expected.Id.String()+`", "text": "Most users log in on mobile"}}`, w.Body.String())
+	})
+
+	t.Run("Should return 400 when text is too long", func(t *testing.T) {
+		// Arrange
+		handler := insight.CreateInsightHandler(insight.CreateInsightHandlerDependencies{InsightService: &StubInsightService{}})
+
+		bodyBytes, _ := json.Marshal(map[string]any{"text": string(bytes.Repeat([]byte("a"), 501))})
+		req := httptest.NewRequest(http.MethodPost, "/admin/insights", bytes.NewReader(bodyBytes))
+		w := httptest.NewRecorder()
+
+		// Act
+		handler(w, req)
+
+		// Assert
+		assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
+	})
+
+	t.Run("Should return 409 with the existing insight when the text is taken", func(t *testing.T) {
+		// Arrange
+		existing := &insight.Insight{Id: uuid.New(), Text: "Most users log in on mobile"}
+		stubService := &StubInsightService{
+			CreateFunc: func(string) (*insight.Insight, error) { return existing, insight.ErrInsightTextTaken },
+		}
+		handler := insight.CreateInsightHandler(insight.CreateInsightHandlerDependencies{InsightService: stubService})
+
+		req := httptest.NewRequest(http.MethodPost, "/admin/insights", bytes.NewReader([]byte(`{"text": "most users log in on mobile"}`)))
+		w := httptest.NewRecorder()
+
+		// Act
+		handler(w, req)
+
+		// Assert
+		assert.Equal(t, http.StatusConflict, w.Result().StatusCode)
+		assert.Contains(t, w.Body.String(), existing.Id.String())
+	})
+}
+
+func TestUpdateInsightHandler(t *testing.T) {
+	cases := []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{"Should return 200 when update is successful", nil, http.StatusOK},
+		{"Should return 400 when text is blank", insight.ErrInvalidInsightText, http.StatusBadRequest},
+		{"Should return 404 when insight not found", insight.ErrInsightNotFound, http.StatusNotFound},
+		{"Should return 409 when insight is favourited", insight.ErrInsightFavourited, http.StatusConflict},
+		{"Should return 409 when the text is taken", insight.ErrInsightTextTaken, http.StatusConflict},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			insightId := uuid.New()
+			stubService := &StubInsightService{
+				UpdateFunc: func(iId uuid.UUID, changes insight.InsightChanges) (*insight.Insight, error) {
+					assert.Equal(t, insightId, iId)
+					assert.Equal(t, "New", *changes.Text)
+					if tc.err != nil {
+						return nil, tc.err
+					}
+					return &insight.Insight{Id: iId, Text: *changes.Text}, nil
+				},
+			}
+			handler := insight.UpdateInsightHandler(insight.UpdateInsightHandlerDependencies{InsightService: stubService})
+
+			req := httptest.NewRequest(http.MethodPatch, "/admin/insights", bytes.NewReader([]byte(`{"text": "New"}`)))
+			req = withInsightId(req, insightId.String())
+			w := httptest.NewRecorder()
+
+			// Act
+			handler(w, req)
+
+			// Assert
+			assert.Equal(t, tc.status, w.Result().StatusCode)
+		})
+	}
+}
+
+func TestDeleteInsightHandler(t *testing.T) {
+	cases := []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{"Should return 200 when delete is successful", nil, http.StatusOK},
+		{"Should return 404 when insight not found", insight.ErrInsightNotFound, http.StatusNotFound},
+		{"Should return 500 when service fails unexpectedly", errors.New("db down"), http.StatusInternalServerError},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			stubService := &StubInsightService{
+				DeleteFunc: func(uuid.UUID) error { return tc.err },
+			}
+			handler := insight.DeleteInsightHandler(insight.DeleteInsightHandlerDependencies{InsightService: stubService})
+
+			req := withInsightId(httptest.NewRequest(http.MethodDelete, "/admin/insights", nil), uuid.NewString())
+			w := httptest.NewRecorder()
+
+			// Act
+			handler(w, req)
+
+			// Assert
+			assert.Equal(t, tc.status, w.Result().StatusCode)
+		})
+	}
+}
@@ -1,7 +1,9 @@
 package insight
 
 import (
+	"errors"
 	"platform-go-challenge/internal/database"
+	"platform-go-challenge/internal/utils"
 
 	"github.com/google/uuid"
 )
@@ -9,6 +11,15 @@ import (
 type InsightRepository interface {
 	GetByIds(ids uuid.UUIDs) ([]Insight, error)
 	GetById(id uuid.UUID) (*Insight, error)
+	// GetPaginated lists every insight by text
+	GetPaginated(pageSize int, pageNumber int) ([]Insight, utils.Pagination, error)
+	// Create returns the insight that already has the text, ignoring case, together with ErrInsightTextTaken
+	Create(insight Insight) (*Insight, error)
+	// Update fails with ErrInsightNotFound when the insight is gone
+	// and with ErrInsightTextTaken when another insight has the text
+	Update(insight Insight) (*Insight, error)
+	// Delete fails with ErrInsightNotFound when the insight is gone, and with ErrInsightFavourited while a user has a favourite of it
+	Delete(id uuid.UUID) error
 }
 
 type inMemoryDBInsightRepository struct {
@@ -28,15 +39,16 @@ func InMemoryDBInsightModelToDTO(insightModel database.IMInsightModel) Insight {
 	}
 }
 
+func DTOToInMemoryDBInsightModel(dto Insight) database.IMInsightModel {
+	return database.IMInsightModel{
+		Id:   dto.Id,
+		Text: dto.Text,
+	}
+}
+
 func (repo *inMemoryDBInsightRepository) GetByIds(ids uuid.UUIDs) ([]Insight, error) {
 	result := []Insight{}
-	for _, id := range ids {
-		v, found := repo.DB.InsightStorage[id]
-
-		if !found {
-			continue
-		}
-
+	for _, v := range repo.DB.InsightStorage.GetMany(ids) {
 		dto := InMemoryDBInsightModelToDTO(v)
 
 		result = append(result, dto)
@@ -58,3 +70,72 @@ func (repo *inMemoryDBInsightRepository) GetById(id uuid.UUID) (*Insight, error)
 
 	return &dto, nil
 }
+
+func (repo *inMemoryDBInsightRepository) GetPaginated(pageSize int, pageNumber int) ([]Insight, utils.Pagination, error) {
+	models, total, err := repo.DB.InsightStorage.Page(database.IMInsightsByTextIndex, uuid.Nil, pageSize*pageNumber, pageSize)
+	if err != nil {
+		return nil, utils.Pagination{}, err
+	}
+
+	result := []Insight{}
+	for _, model := range models {
+		result = append(result, InMemoryDBInsightModelToDTO(model))
+	}
+
+	maxPage := utils.CalculateMaxPages(total, pageSize)
+
+	return result, utils.Pagination{Page: pageNumber, PageSize: pageSize, MaxPage: maxPage}, nil
+}
+
+func (repo *inMemoryDBInsightRepository) Create(insight Insight) (*Insight, error) {
+	model, err := repo.DB.InsightStorage.Insert(insight.Id, DTOToInMemoryDBInsightModel(insight))
+	if err != nil {
+		if errors.Is(err, database.ErrItemAlreadyExists) {
+			existing := InMemoryDBInsightModelToDTO(model)
+			return &existing, ErrInsightTextTaken
+		}
+
+		return nil, err
+	}
+
+	return &insight, nil
+}
+
+func (repo *inMemoryDBInsightRepository) Update(insight Insight) (*Insight, error) {
+	model, err := repo.DB.InsightStorage.Update(
+		insight.Id,
+		func(current database.IMInsightModel) (database.IMInsightModel, error) {
+			return DTOToInMemoryDBInsightModel(insight), nil
+		},
+	)
+	if err != nil {
+		if errors.Is(err, database.ErrItemNotFound) {
+			return nil, ErrInsightNotFound
+		}
+		if errors.Is(err, database.ErrItemAlreadyExists) {
+			return nil, ErrInsightTextTaken
+		}
+
+		return nil, err
+	}
+
+	updated := InMemoryDBInsightModelToDTO(model)
+
+	return &updated, nil
+}
+
+func (repo *inMemoryDBInsightRepository) Delete(id uuid.UUID) error {
+	deleted, err := database.IMDeleteUnlessFavourited(repo.DB, repo.DB.InsightStorage, id)
+	if errors.Is(err, database.ErrItemFavourited) {
+		return ErrInsightFavourited
+	}
+	if err != nil {
+		return err
+	}
+
+	if !deleted {
+		return ErrInsightNotFound
+	}
+
+	return nil
+}
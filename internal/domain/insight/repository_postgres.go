@@ -0,0 +1,177 @@
+package insight
+
+import (
+	"context"
+	"errors"
+	"platform-go-challenge/internal/database"
+	"platform-go-challenge/internal/utils"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type postgresDBInsightRepository struct {
+	DB *pgxpool.Pool
+}
+
+func NewPostgresDBInsightRepository(db *pgxpool.Pool) *postgresDBInsightRepository {
+	return &postgresDBInsightRepository{
+		DB: db,
+	}
+}
+
+const pgInsightColumns = "id, text"
+
+const pgUniqueViolation = "23505"
+
+func pgScanInsight(row pgx.CollectableRow) (Insight, error) {
+	var insight Insight
+	err := row.Scan(&insight.Id, &insight.Text)
+
+	return insight, err
+}
+
+// GetByIds returns the insights in the order of ids, skipping the missing ones.
+func (repo *postgresDBInsightRepository) GetByIds(ids uuid.UUIDs) ([]Insight, error) {
+	rows, err := repo.DB.Query(
+		context.Background(),
+		"SELECT "+pgInsightColumns+" FROM insights WHERE id = ANY($1::uuid[]) ORDER BY array_position($1::uuid[], id)",
+		ids.Strings(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, pgScanInsight)
+}
+
+func (repo *postgresDBInsightRepository) GetById(id uuid.UUID) (*Insight, error) {
+	rows, err := repo.DB.Query(
+		context.Background(),
+		"SELECT "+pgInsightColumns+" FROM insights WHERE id = $1",
+		id,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	insight, err := pgx.CollectExactlyOneRow(rows, pgScanInsight)
+	if err != nil {
+		return nil, database.PGItemNotFound(err)
+	}
+
+	return &insight, nil
+}
+
+func (repo *postgresDBInsightRepository) GetPaginated(pageSize int, pageNumber int) ([]Insight, utils.Pagination, error) {
+	ctx := context.Background()
+
+	var totalCount int
+	err := repo.DB.QueryRow(ctx, "SELECT count(*) FROM insights").Scan(&totalCount)
+	if err != nil {
+		return nil, utils.Pagination{}, err
+	}
+
+	rows, err := repo.DB.Query(
+		ctx,
+		"SELECT "+pgInsightColumns+` FROM insights ORDER BY text COLLATE "C", id LIMIT $1 OFFSET $2`,
+		pageSize, pageSize*pageNumber,
+	)
+	if err != nil {
+		return nil, utils.Pagination{}, err
+	}
+
+	result, err := pgx.CollectRows(rows, pgScanInsight)
+	if err != nil {
+		return nil, utils.Pagination{}, err
+	}
+
+	if result == nil {
+		result = []Insight{}
+	}
+
+	maxPage := utils.CalculateMaxPages(totalCount, pageSize)
+
+	return result, utils.Pagination{Page: pageNumber, PageSize: pageSize, MaxPage: maxPage}, nil
+}
+
+// pgCreateAttempts bounds the retries when the conflicting insight is deleted between the insert and the lookup.
+const pgCreateAttempts = 3
+
+func (repo *postgresDBInsightRepository) Create(insight Insight) (*Insight, error) {
+	ctx := context.Background()
+
+	for range pgCreateAttempts {
+		tag, err := repo.DB.Exec(
+			ctx,
+			"INSERT INTO insights ("+pgInsightColumns+") VALUES ($1, $2) ON CONFLICT (lower(text)) DO NOTHING",
+			insight.Id, insight.Text,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if tag.RowsAffected() == 1 {
+			return &insight, nil
+		}
+
+		rows, err := repo.DB.Query(
+			ctx,
+			"SELECT "+pgInsightColumns+" FROM insights WHERE lower(text) = lower($1)",
+			insight.Text,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		existing, err := pgx.CollectExactlyOneRow(rows, pgScanInsight)
+		if errors.Is(err, pgx.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		return &existing, ErrInsightTextTaken
+	}
+
+	return nil, ErrCouldNotSaveInsight
+}
+
+func (repo *postgresDBInsightRepository) Update(insight Insight) (*Insight, error) {
+	tag, err := repo.DB.Exec(
+		context.Background(),
+		"UPDATE insights SET text = $2 WHERE id = $1",
+		insight.Id, insight.Text,
+	)
+	if pgErr := (*pgconn.PgError)(nil); errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+		return nil, ErrInsightTextTaken
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return nil, ErrInsightNotFound
+	}
+
+	return &insight, nil
+}
+
+func (repo *postgresDBInsightRepository) Delete(id uuid.UUID) error {
+	deleted, err := database.PGDeleteUnlessFavourited(repo.DB, "insights", id)
+	if errors.Is(err, database.ErrItemFavourited) {
+		return ErrInsightFavourited
+	}
+	if err != nil {
+		return err
+	}
+
+	if !deleted {
+		return ErrInsightNotFound
+	}
+
+	return nil
+}
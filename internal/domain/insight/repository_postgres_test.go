@@ -0,0 +1,48 @@
+package insight_test
+
+import (
+	"context"
+	"platform-go-challenge/internal/domain/favourite"
+	"platform-go-challenge/internal/domain/insight"
+	"platform-go-challenge/test"
+	"platform-go-challenge/test/conformance"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresDBInsightRepositoryConformance(t *testing.T) {
+	conformance.InsightRepository(t, func(t *testing.T, insights []insight.Insight) insight.InsightRepository {
+		pool := test.PostgresPool(t)
+		for _, i := range insights {
+			_, err := pool.Exec(context.Background(), "INSERT INTO insights (id, text) VALUES ($1, $2)", i.Id, i.Text)
+			require.NoError(t, err)
+		}
+
+		return insight.NewPostgresDBInsightRepository(pool)
+	})
+}
+
+func TestPostgresDBInsightRepositoryDelete(t *testing.T) {
+	t.Run("should return error and keep the insight while a user has a favourite of it", func(t *testing.T) {
+		// Arrange
+		pool := test.PostgresPool(t)
+		repo := insight.NewPostgresDBInsightRepository(pool)
+		stored, err := repo.Create(insight.Insight{Id: uuid.New(), Text: "Revenue grew"})
+		require.NoError(t, err)
+		_, err = favourite.NewPostgresDBFavouriteRepository(pool).Create(favourite.Favourite{
+			Id: uuid.New(), UserId: uuid.New(), AssetId: stored.Id, AssetType: favourite.AssetTypeInsight, Rank: "V", Tags: []string{},
+		})
+		require.NoError(t, err)
+
+		// Act
+		err = repo.Delete(stored.Id)
+
+		// Assert
+		assert.ErrorIs(t, err, insight.ErrInsightFavourited)
+		_, err = repo.GetById(stored.Id)
+		assert.NoError(t, err)
+	})
+}
@@ -3,6 +3,7 @@ package insight_test
 import (
 	"platform-go-challenge/internal/database"
 	"platform-go-challenge/internal/domain/insight"
+	"platform-go-challenge/test/conformance"
 	"testing"
 
 	"github.com/google/uuid"
@@ -19,10 +20,10 @@ func TestInMemoryDBInsightRepository_GetByIds(t *testing.T) {
 		mockInsight2 := database.IMInsightModel{Id: id2, Text: "Insight 2"}
 
 		db := &database.IMDatabase{
-			InsightStorage: map[uuid.UUID]database.IMInsightModel{
+			InsightStorage: database.NewIMStorage(map[uuid.UUID]database.IMInsightModel{
 				id1: mockInsight1,
 				id2: mockInsight2,
-			},
+			}),
 		}
 
 		repo := insight.NewInMemoryDBInsightRepository(db)
@@ -44,9 +45,9 @@ func TestInMemoryDBInsightRepository_GetByIds(t *testing.T) {
 		mockInsight := database.IMInsightModel{Id: idExisting, Text: "Only Found Insight"}
 
 		db := &database.IMDatabase{
-			InsightStorage: map[uuid.UUID]database.IMInsightModel{
+			InsightStorage: database.NewIMStorage(map[uuid.UUID]database.IMInsightModel{
 				idExisting: mockInsight,
-			},
+			}),
 		}
 
 		repo := insight.NewInMemoryDBInsightRepository(db)
@@ -62,7 +63,7 @@ func TestInMemoryDBInsightRepository_GetByIds(t *testing.T) {
 	t.Run("should return empty list when no ids match", func(t *testing.T) {
 		// Arrange
 		db := &database.IMDatabase{
-			InsightStorage: map[uuid.UUID]database.IMInsightModel{},
+			InsightStorage: database.NewIMStorage(map[uuid.UUID]database.IMInsightModel{}),
 		}
 		repo := insight.NewInMemoryDBInsightRepository(db)
 		missingID := uuid.New()
@@ -77,7 +78,7 @@ func TestInMemoryDBInsightRepository_GetByIds(t *testing.T) {
 	t.Run("should return empty list when ids slice is empty", func(t *testing.T) {
 		// Arrange
 		db := &database.IMDatabase{
-			InsightStorage: map[uuid.UUID]database.IMInsightModel{},
+			InsightStorage: database.NewIMStorage(map[uuid.UUID]database.IMInsightModel{}),
 		}
 		repo := insight.NewInMemoryDBInsightRepository(db)
 
@@ -96,9 +97,9 @@ func TestInMemoryDBInsightRepository_GetById(t *testing.T) {
 		mockInsight := database.IMInsightModel{Id: id, Text: "Existing Insight"}
 
 		db := &database.IMDatabase{
-			InsightStorage: map[uuid.UUID]database.IMInsightModel{
+			InsightStorage: database.NewIMStorage(map[uuid.UUID]database.IMInsightModel{
 				id: mockInsight,
-			},
+			}),
 		}
 		repo := insight.NewInMemoryDBInsightRepository(db)
 
@@ -115,7 +116,7 @@ func TestInMemoryDBInsightRepository_GetById(t *testing.T) {
 	t.Run("should return error when ID does not exist", func(t *testing.T) {
 		// Arrange
 		db := &database.IMDatabase{
-			InsightStorage: map[uuid.UUID]database.IMInsightModel{},
+			InsightStorage: database.NewIMStorage(map[uuid.UUID]database.IMInsightModel{}),
 		}
 		repo := insight.NewInMemoryDBInsightRepository(db)
 
@@ -129,3 +130,66 @@ func TestInMemoryDBInsightRepository_GetById(t *testing.T) {
 		assert.Nil(t, result)
 	})
 }
+
+func TestInMemoryDBInsightRepository_Delete(t *testing.T) {
+	newDB := func(insightId uuid.UUID, favourites ...database.IMFavouriteModel) *database.IMDatabase {
+		db := database.NewIMDatabase()
+		db.InsightStorage.Set(insightId, database.IMInsightModel{Id: insightId, Text: "Revenue grew"})
+		for _, fav := range favourites {
+			db.FavouriteStorage.Set(fav.Id, fav)
+		}
+		return db
+	}
+
+	t.Run("should delete an insight no user has a favourite of", func(t *testing.T) {
+		// Arrange
+		insightId := uuid.New()
+		db := newDB(insightId, database.IMFavouriteModel{Id: uuid.New(), UserId: uuid.New(), AssetId: uuid.New(), AssetType: "insight"})
+		repo := insight.NewInMemoryDBInsightRepository(db)
+
+		// Act
+		err := repo.Delete(insightId)
+
+		// Assert
+		assert.NoError(t, err)
+		_, found := db.InsightStorage.Get(insightId)
+		assert.False(t, found)
+	})
+
+	t.Run("should return error and keep the insight while a user has a favourite of it", func(t *testing.T) {
+		// Arrange
+		insightId := uuid.New()
+		db := newDB(insightId, database.IMFavouriteModel{Id: uuid.New(), UserId: uuid.New(), AssetId: insightId, AssetType: "insight"})
+		repo := insight.NewInMemoryDBInsightRepository(db)
+
+		// Act
+		err := repo.Delete(insightId)
+
+		// Assert
+		assert.ErrorIs(t, err, insight.ErrInsightFavourited)
+		_, found := db.InsightStorage.Get(insightId)
+		assert.True(t, found)
+	})
+
+	t.Run("should return not found error when the insight does not exist", func(t *testing.T) {
+		// Arrange
+		repo := insight.NewInMemoryDBInsightRepository(newDB(uuid.New()))
+
+		// Act
+		err := repo.Delete(uuid.New())
+
+		// Assert
+		assert.ErrorIs(t, err, insight.ErrInsightNotFound)
+	})
+}
+
+func TestInMemoryDBInsightRepositoryConformance(t *testing.T) {
+	conformance.InsightRepository(t, func(t *testing.T, insights []insight.Insight) insight.InsightRepository {
+		db := database.NewIMDatabase()
+		for _, i := range insights {
+			db.InsightStorage.Set(i.Id, database.IMInsightModel{Id: i.Id, Text: i.Text})
+		}
+
+		return insight.NewInMemoryDBInsightRepository(db)
+	})
+}
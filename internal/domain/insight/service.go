@@ -0,0 +1,105 @@
+package insight
+
+import (
+	"errors"
+	"platform-go-challenge/internal/database"
+	"platform-go-challenge/internal/utils"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// InsightService manages the insights for the admin endpoints, the favourites read them through InsightRepository.
+type InsightService interface {
+	GetPaginated(pageSize int, pageNumber int) ([]Insight, *utils.Pagination, error)
+	Get(insightId uuid.UUID) (*Insight, error)
+	// Create stores the insight under a new id, or returns the insight that already has the text
+	// together with ErrInsightTextTaken
+	Create(text string) (*Insight, error)
+	Update(insightId uuid.UUID, changes InsightChanges) (*Insight, error)
+	// Delete fails with ErrInsightFavourited while a user has a favourite of the insight, the trash left out
+	Delete(insightId uuid.UUID) error
+}
+
+type InsightServiceDependencies struct {
+	InsightRepository InsightRepository
+}
+
+type insightService struct {
+	Dependencies InsightServiceDependencies
+}
+
+func NewInsightService(dependencies InsightServiceDependencies) insightService {
+	return insightService{
+		Dependencies: dependencies,
+	}
+}
+
+func (service *insightService) GetPaginated(pageSize int, pageNumber int) ([]Insight, *utils.Pagination, error) {
+	insights, pagination, err := service.Dependencies.InsightRepository.GetPaginated(pageSize, pageNumber)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return insights, &pagination, nil
+}
+
+func (service *insightService) Get(insightId uuid.UUID) (*Insight, error) {
+	insight, err := service.Dependencies.InsightRepository.GetById(insightId)
+	if err != nil {
+		if errors.Is(err, database.ErrItemNotFound) {
+			return nil, ErrInsightNotFound
+		}
+
+		return nil, utils.ErrUnexpected
+	}
+
+	return insight, nil
+}
+
+func (service *insightService) Create(text string) (*Insight, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, ErrInvalidInsightText
+	}
+
+	created, err := service.Dependencies.InsightRepository.Create(Insight{
+		Id:   uuid.New(),
+		Text: text,
+	})
+	if err != nil {
+		if errors.Is(err, ErrInsightTextTaken) {
+			return created, ErrInsightTextTaken
+		}
+
+		return nil, ErrCouldNotSaveInsight
+	}
+
+	return created, nil
+}
+
+func (service *insightService) Update(insightId uuid.UUID, changes InsightChanges) (*Insight, error) {
+	insight, err := service.Get(insightId)
+	if err != nil {
+		return nil, err
+	}
+
+	if changes.Text != nil {
+		insight.Text = strings.TrimSpace(*changes.Text)
+		if insight.Text == "" {
+			return nil, ErrInvalidInsightText
+		}
+	}
+
+	updated, err := service.Dependencies.InsightRepository.Update(*insight)
+	if errors.Is(err, database.ErrItemNotFound) {
+		// The insight was deleted since it was read
+		return nil, ErrInsightNotFound
+	}
+
+	return updated, err
+}
+
+func (service *insightService) Delete(insightId uuid.UUID) error {
+	return service.Dependencies.InsightRepository.Delete(insightId)
+}
@@ -0,0 +1,180 @@
+package insight_test
+
+import (
+	"errors"
+	"platform-go-challenge/internal/database"
+	"platform-go-challenge/internal/domain/insight"
+	"platform-go-challenge/internal/utils"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockInsightRepo struct {
+	getByIdsFn     func(ids uuid.UUIDs) ([]insight.Insight, error)
+	getByIdFn      func(id uuid.UUID) (*insight.Insight, error)
+	getPaginatedFn func(pageSize, pageNumber int) ([]insight.Insight, utils.Pagination, error)
+	createFn       func(i insight.Insight) (*insight.Insight, error)
+	updateFn       func(i insight.Insight) (*insight.Insight, error)
+	deleteFn       func(id uuid.UUID) error
+}
+
+func (m *mockInsightRepo) GetByIds(ids uuid.UUIDs) ([]insight.Insight, error) {
+	return m.getByIdsFn(ids)
+}
+
+func (m *mockInsightRepo) GetById(id uuid.UUID) (*insight.Insight, error) {
+	return m.getByIdFn(id)
+}
+
+func (m *mockInsightRepo) GetPaginated(pageSize, pageNumber int) ([]insight.Insight, utils.Pagination, error) {
+	return m.getPaginatedFn(pageSize, pageNumber)
+}
+
+func (m *mockInsightRepo) Create(i insight.Insight) (*insight.Insight, error) {
+	return m.createFn(i)
+}
+
+func (m *mockInsightRepo) Update(i insight.Insight) (*insight.Insight, error) {
+	return m.updateFn(i)
+}
+
+func (m *mockInsightRepo) Delete(id uuid.UUID) error {
+	return m.deleteFn(id)
+}
+
+func storedInsightRepo(stored *insight.Insight) *mockInsightRepo {
+	return &mockInsightRepo{
+		getByIdFn: func(id uuid.UUID) (*insight.Insight, error) {
+			if stored == nil || stored.Id != id {
+				return nil, database.ErrItemNotFound
+			}
+			copied := *stored
+			return &copied, nil
+		},
+		updateFn: func(i insight.Insight) (*insight.Insight, error) { return &i, nil },
+	}
+}
+
+func TestCreateInsightService(t *testing.T) {
+	t.Run("should create the insight under a new id with a trimmed text", func(t *testing.T) {
+		// Arrange
+		service := insight.NewInsightService(insight.InsightServiceDependencies{
+			InsightRepository: &mockInsightRepo{
+				createFn: func(i insight.Insight) (*insight.Insight, error) { return &i, nil },
+			},
+		})
+
+		// Act
+		result, err := service.Create("  Most users log in on mobile ")
+
+		// Assert
+		assert.NoError(t, err)
+		assert.NotEqual(t, uuid.Nil, result.Id)
+		assert.Equal(t, "Most users log in on mobile", result.Text)
+	})
+
+	t.Run("should return error when the text is blank", func(t *testing.T) {
+		// Arrange
+		service := insight.NewInsightService(insight.InsightServiceDependencies{})
+
+		// Act
+		result, err := service.Create("   ")
+
+		// Assert
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, insight.ErrInvalidInsightText)
+	})
+
+	t.Run("should return the existing insight when the text is taken", func(t *testing.T) {
+		// Arrange
+		existing := insight.Insight{Id: uuid.New(), Text: "Most users log in on mobile"}
+		service := insight.NewInsightService(insight.InsightServiceDependencies{
+			InsightRepository: &mockInsightRepo{
+				createFn: func(i insight.Insight) (*insight.Insight, error) { return &existing, insight.ErrInsightTextTaken },
+			},
+		})
+
+		// Act
+		result, err := service.Create("most users log in on mobile")
+
+		// Assert
+		assert.ErrorIs(t, err, insight.ErrInsightTextTaken)
+		assert.Equal(t, &existing, result)
+	})
+
+	t.Run("should return error when the repository fails", func(t *testing.T) {
+		// Arrange
+		service := insight.NewInsightService(insight.InsightServiceDependencies{
+			InsightRepository: &mockInsightRepo{
+				createFn: func(i insight.Insight) (*insight.Insight, error) { return nil, errors.New("db down") },
+			},
+		})
+
+		// Act
+		result, err := service.Create("text")
+
+		// Assert
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, insight.ErrCouldNotSaveInsight)
+	})
+}
+
+func TestUpdateInsightService(t *testing.T) {
+	stored := insight.Insight{Id: uuid.New(), Text: "Old"}
+
+	t.Run("should change the text", func(t *testing.T) {
+		// Arrange
+		service := insight.NewInsightService(insight.InsightServiceDependencies{InsightRepository: storedInsightRepo(&stored)})
+		text := " New "
+
+		// Act
+		result, err := service.Update(stored.Id, insight.InsightChanges{Text: &text})
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, &insight.Insight{Id: stored.Id, Text: "New"}, result)
+	})
+
+	t.Run("should return error when the text is cleared", func(t *testing.T) {
+		// Arrange
+		service := insight.NewInsightService(insight.InsightServiceDependencies{InsightRepository: storedInsightRepo(&stored)})
+		empty := ""
+
+		// Act
+		result, err := service.Update(stored.Id, insight.InsightChanges{Text: &empty})
+
+		// Assert
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, insight.ErrInvalidInsightText)
+	})
+
+	t.Run("should return error when insight not found", func(t *testing.T) {
+		// Arrange
+		service := insight.NewInsightService(insight.InsightServiceDependencies{InsightRepository: storedInsightRepo(nil)})
+		text := "New"
+
+		// Act
+		result, err := service.Update(stored.Id, insight.InsightChanges{Text: &text})
+
+		// Assert
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, insight.ErrInsightNotFound)
+	})
+
+	t.Run("should return not found error when the insight is deleted before it is updated", func(t *testing.T) {
+		// Arrange
+		repo := storedInsightRepo(&stored)
+		repo.updateFn = func(i insight.Insight) (*insight.Insight, error) { return nil, database.ErrItemNotFound }
+		service := insight.NewInsightService(insight.InsightServiceDependencies{InsightRepository: repo})
+		text := "New"
+
+		// Act
+		result, err := service.Update(stored.Id, insight.InsightChanges{Text: &text})
+
+		// Assert
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, insight.ErrInsightNotFound)
+	})
+}
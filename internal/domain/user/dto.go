@@ -10,6 +10,8 @@ type User struct {
 	Id       uuid.UUID `json:"id"`
 	Email    string    `json:"email"`
 	Password string    `json:"password"`
+	// IsAdmin lets the user manage the assets through the admin endpoints
+	IsAdmin bool `json:"is_admin"`
 }
 
 type UserLoginRequestBody struct {
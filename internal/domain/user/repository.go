@@ -23,16 +23,19 @@ func InMemoryDBUserModelToDTO(userModel database.IMUserModel) User {
 		Id:       userModel.Id,
 		Email:    userModel.Email,
 		Password: userModel.Password,
+		IsAdmin:  userModel.IsAdmin,
 	}
 }
 
 func (repo *inMemoryDBUserRepository) GetByEmail(email string) (*User, error) {
-	for _, user := range repo.DB.UserStorage {
-		if user.Email == email {
-			userDTO := InMemoryDBUserModelToDTO(user)
-			return &userDTO, nil
-		}
+	user, found := repo.DB.UserStorage.Find(
+		func(user database.IMUserModel) bool { return user.Email == email },
+	)
+	if !found {
+		return nil, ErrUserNotFound
 	}
 
-	return nil, ErrUserNotFound
+	userDTO := InMemoryDBUserModelToDTO(user)
+
+	return &userDTO, nil
 }
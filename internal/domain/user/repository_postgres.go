@@ -0,0 +1,38 @@
+package user
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type postgresDBUserRepository struct {
+	DB *pgxpool.Pool
+}
+
+func NewPostgresDBUserRepository(db *pgxpool.Pool) postgresDBUserRepository {
+	return postgresDBUserRepository{
+		DB: db,
+	}
+}
+
+func (repo *postgresDBUserRepository) GetByEmail(email string) (*User, error) {
+	var user User
+
+	err := repo.DB.QueryRow(
+		context.Background(),
+		"SELECT id, email, password, is_admin FROM users WHERE email = $1",
+		email,
+	).Scan(&user.Id, &user.Email, &user.Password, &user.IsAdmin)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+
+		return nil, err
+	}
+
+	return &user, nil
+}
@@ -0,0 +1,29 @@
+package user_test
+
+import (
+	"context"
+	"platform-go-challenge/internal/domain/user"
+	"platform-go-challenge/test"
+	"platform-go-challenge/test/conformance"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresDBUserRepositoryConformance(t *testing.T) {
+	conformance.UserRepository(t, func(t *testing.T, users []user.User) user.UserRepository {
+		pool := test.PostgresPool(t)
+		for _, u := range users {
+			_, err := pool.Exec(
+				context.Background(),
+				"INSERT INTO users (id, email, password, is_admin) VALUES ($1, $2, $3, $4)",
+				u.Id, u.Email, u.Password, u.IsAdmin,
+			)
+			require.NoError(t, err)
+		}
+
+		repo := user.NewPostgresDBUserRepository(pool)
+
+		return &repo
+	})
+}
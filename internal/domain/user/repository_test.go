@@ -3,6 +3,7 @@ package user_test
 import (
 	"platform-go-challenge/internal/database"
 	"platform-go-challenge/internal/domain/user"
+	"platform-go-challenge/test/conformance"
 	"testing"
 
 	"github.com/google/uuid"
@@ -18,9 +19,9 @@ func TestInMemoryDBUserRepository_GetByEmail(t *testing.T) {
 			Password: "secret",
 		}
 		db := &database.IMDatabase{
-			UserStorage: database.UserStorage{
+			UserStorage: database.NewIMStorage(map[uuid.UUID]database.IMUserModel{
 				expectedUser.Id: expectedUser,
-			},
+			}),
 		}
 		repo := user.NewInMemoryDBUserRepository(db)
 
@@ -38,7 +39,7 @@ func TestInMemoryDBUserRepository_GetByEmail(t *testing.T) {
 	t.Run("should return error when email does not exist in database", func(t *testing.T) {
 		// Arrange
 		db := &database.IMDatabase{
-			UserStorage: database.UserStorage{},
+			UserStorage: database.NewIMStorage(map[uuid.UUID]database.IMUserModel{}),
 		}
 		repo := user.NewInMemoryDBUserRepository(db)
 
@@ -50,3 +51,16 @@ func TestInMemoryDBUserRepository_GetByEmail(t *testing.T) {
 		assert.ErrorIs(t, err, user.ErrUserNotFound)
 	})
 }
+
+func TestInMemoryDBUserRepositoryConformance(t *testing.T) {
+	conformance.UserRepository(t, func(t *testing.T, users []user.User) user.UserRepository {
+		db := database.NewIMDatabase()
+		for _, u := range users {
+			db.UserStorage.Set(u.Id, database.IMUserModel{Id: u.Id, Email: u.Email, Password: u.Password, IsAdmin: u.IsAdmin})
+		}
+
+		repo := user.NewInMemoryDBUserRepository(db)
+
+		return &repo
+	})
+}
@@ -1,6 +1,7 @@
 package user
 
 import (
+	"platform-go-challenge/internal/utils"
 	"time"
 )
 
@@ -36,7 +37,12 @@ func (service *userService) LoginUser(email string, password string) (string, ti
 		return "", time.Time{}, ErrLoginFailed
 	}
 
-	token, expires_at, err := service.Dependencies.GenerateToken(map[string]any{"sub": user.Id.String()})
+	claims := map[string]any{"sub": user.Id.String()}
+	if user.IsAdmin {
+		claims[utils.AdminClaim] = true
+	}
+
+	token, expires_at, err := service.Dependencies.GenerateToken(claims)
 	if err != nil {
 		return "", time.Time{}, ErrTokenGenerationFailed
 	}
@@ -3,6 +3,7 @@ package user_test
 import (
 	"errors"
 	"platform-go-challenge/internal/domain/user"
+	"platform-go-challenge/internal/utils"
 	"testing"
 	"time"
 
@@ -55,6 +56,32 @@ func TestUserService_LoginUser(t *testing.T) {
 		assert.Equal(t, expectedExpiry, expiresAt)
 	})
 
+	t.Run("should add the admin claim to the token of an admin", func(t *testing.T) {
+		// Arrange
+		admin := &user.User{Id: uuid.New(), Email: "admin@example.com", Password: "secret123", IsAdmin: true}
+		mockRepo := &mockUserRepository{
+			getByEmailFn: func(email string) (*user.User, error) {
+				return admin, nil
+			},
+		}
+		var claims map[string]any
+		service := user.NewUserService(user.ServiceDependencies{
+			UserRepository: mockRepo,
+			GenerateToken: func(c map[string]any) (string, time.Time, error) {
+				claims = c
+				return "token", time.Now(), nil
+			},
+			PasswordHasher: func(password string) string { return password },
+		})
+
+		// Act
+		_, _, err := service.LoginUser(admin.Email, "secret123")
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]any{"sub": admin.Id.String(), utils.AdminClaim: true}, claims)
+	})
+
 	t.Run("should return error when user not found", func(t *testing.T) {
 		// Arrange
 		mockRepo := &mockUserRepository{
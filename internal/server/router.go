@@ -13,11 +13,49 @@ import (
 
 type RouterDependencies struct {
 	JWTAuth                *jwtauth.JWTAuth
+	IdempotencyMiddleware  func(next http.Handler) http.Handler
 	UserLoginHandler       http.HandlerFunc
 	GetFavouritesHandler   http.HandlerFunc
+	GetFavouriteHandler    http.HandlerFunc
 	CreateFavouriteHandler http.HandlerFunc
 	UpdateFavouriteHandler http.HandlerFunc
 	DeleteFavouriteHandler http.HandlerFunc
+	MoveFavouriteHandler   http.HandlerFunc
+
+	GetFavouriteTagsHandler http.HandlerFunc
+
+	GetFavouriteTrashHandler http.HandlerFunc
+	RestoreFavouriteHandler  http.HandlerFunc
+	PurgeFavouriteHandler    http.HandlerFunc
+
+	CreateFavouritesBatchHandler http.HandlerFunc
+	UpdateFavouritesBatchHandler http.HandlerFunc
+	DeleteFavouritesBatchHandler http.HandlerFunc
+
+	GetCollectionsHandler            http.HandlerFunc
+	GetCollectionHandler             http.HandlerFunc
+	CreateCollectionHandler          http.HandlerFunc
+	UpdateCollectionHandler          http.HandlerFunc
+	DeleteCollectionHandler          http.HandlerFunc
+	GetCollectionFavouritesHandler   http.HandlerFunc
+	AddCollectionFavouriteHandler    http.HandlerFunc
+	RemoveCollectionFavouriteHandler http.HandlerFunc
+
+	GetChartsHandler      http.HandlerFunc
+	GetChartHandler       http.HandlerFunc
+	CreateChartHandler    http.HandlerFunc
+	UpdateChartHandler    http.HandlerFunc
+	DeleteChartHandler    http.HandlerFunc
+	GetInsightsHandler    http.HandlerFunc
+	GetInsightHandler     http.HandlerFunc
+	CreateInsightHandler  http.HandlerFunc
+	UpdateInsightHandler  http.HandlerFunc
+	DeleteInsightHandler  http.HandlerFunc
+	GetAudiencesHandler   http.HandlerFunc
+	GetAudienceHandler    http.HandlerFunc
+	CreateAudienceHandler http.HandlerFunc
+	UpdateAudienceHandler http.HandlerFunc
+	DeleteAudienceHandler http.HandlerFunc
 }
 
 func SetupRouter(dependencies RouterDependencies) *chi.Mux {
@@ -44,13 +82,55 @@ func SetupRouter(dependencies RouterDependencies) *chi.Mux {
 					r.Use(utils.AuthenticatorMiddleware())
 
 					r.Get("/favourites", dependencies.GetFavouritesHandler)
-					// TODO: Add Idempotency to this endpoint
-					r.Post("/favourites", dependencies.CreateFavouriteHandler)
+					r.With(dependencies.IdempotencyMiddleware).Post("/favourites", dependencies.CreateFavouriteHandler)
+					r.Get("/favourites/tags", dependencies.GetFavouriteTagsHandler)
+					r.Get("/favourites/trash", dependencies.GetFavouriteTrashHandler)
+					r.Post("/favourites/trash/{id}/restore", dependencies.RestoreFavouriteHandler)
+					r.Delete("/favourites/trash/{id}", dependencies.PurgeFavouriteHandler)
+					r.Get("/favourites/{id}", dependencies.GetFavouriteHandler)
 					r.Patch("/favourites/{id}", dependencies.UpdateFavouriteHandler)
 					r.Delete("/favourites/{id}", dependencies.DeleteFavouriteHandler)
+					r.Post("/favourites/{id}/move", dependencies.MoveFavouriteHandler)
+					r.With(dependencies.IdempotencyMiddleware).Post("/favourites/batch", dependencies.CreateFavouritesBatchHandler)
+					r.Patch("/favourites/batch", dependencies.UpdateFavouritesBatchHandler)
+					r.Delete("/favourites/batch", dependencies.DeleteFavouritesBatchHandler)
+
+					r.Get("/collections", dependencies.GetCollectionsHandler)
+					r.Post("/collections", dependencies.CreateCollectionHandler)
+					r.Get("/collections/{id}", dependencies.GetCollectionHandler)
+					r.Patch("/collections/{id}", dependencies.UpdateCollectionHandler)
+					r.Delete("/collections/{id}", dependencies.DeleteCollectionHandler)
+					r.Get("/collections/{id}/favourites", dependencies.GetCollectionFavouritesHandler)
+					r.Post("/collections/{id}/favourites", dependencies.AddCollectionFavouriteHandler)
+					r.Delete("/collections/{id}/favourites/{favouriteId}", dependencies.RemoveCollectionFavouriteHandler)
 				})
 			})
 		})
+
+		// Admin
+		r.Route("/admin", func(r chi.Router) {
+			r.Use(utils.VerifierMiddleware(dependencies.JWTAuth))
+			r.Use(utils.AuthenticatorMiddleware())
+			r.Use(utils.AdminMiddleware())
+
+			r.Get("/charts", dependencies.GetChartsHandler)
+			r.Post("/charts", dependencies.CreateChartHandler)
+			r.Get("/charts/{id}", dependencies.GetChartHandler)
+			r.Patch("/charts/{id}", dependencies.UpdateChartHandler)
+			r.Delete("/charts/{id}", dependencies.DeleteChartHandler)
+
+			r.Get("/insights", dependencies.GetInsightsHandler)
+			r.Post("/insights", dependencies.CreateInsightHandler)
+			r.Get("/insights/{id}", dependencies.GetInsightHandler)
+			r.Patch("/insights/{id}", dependencies.UpdateInsightHandler)
+			r.Delete("/insights/{id}", dependencies.DeleteInsightHandler)
+
+			r.Get("/audiences", dependencies.GetAudiencesHandler)
+			r.Post("/audiences", dependencies.CreateAudienceHandler)
+			r.Get("/audiences/{id}", dependencies.GetAudienceHandler)
+			r.Patch("/audiences/{id}", dependencies.UpdateAudienceHandler)
+			r.Delete("/audiences/{id}", dependencies.DeleteAudienceHandler)
+		})
 	})
 
 	return r
@@ -1,31 +1,185 @@
 package server
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
 	"net/http"
+	"os"
+	"os/signal"
 	"platform-go-challenge/internal/config"
 	"platform-go-challenge/internal/database"
 	"platform-go-challenge/internal/domain/audience"
 	"platform-go-challenge/internal/domain/chart"
+	"platform-go-challenge/internal/domain/collection"
 	"platform-go-challenge/internal/domain/favourite"
 	"platform-go-challenge/internal/domain/insight"
 	"platform-go-challenge/internal/domain/user"
 	"platform-go-challenge/internal/utils"
+	"syscall"
+	"time"
 )
 
-func wireDependencies(cfg config.Config) (*RouterDependencies, error) {
+// shutdownTimeout is how long the requests in flight get to finish once the server is asked to stop.
+const shutdownTimeout = 10 * time.Second
+
+// newIMDatabase returns the database with a close function, which stops the snapshots of a persistent
+// database and takes a last one so the log does not have to be replayed on the next start.
+func newIMDatabase(cfg config.Config) (*database.IMDatabase, func(), error) {
+	if cfg.DataDir == "" {
+		return database.NewIMDatabase(), func() {}, nil
+	}
+
+	db, err := database.NewPersistentIMDatabase(cfg.DataDir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	stopSnapshots := db.StartSnapshots(cfg.SnapshotInterval)
+
+	closeDatabase := func() {
+		stopSnapshots()
+
+		if err := db.Snapshot(); err != nil {
+			log.Printf("Could not snapshot database: %s", err)
+		}
+		if err := db.Close(); err != nil {
+			log.Printf("Could not close database: %s", err)
+		}
+	}
+
+	return db, closeDatabase, nil
+}
+
+type repositories struct {
+	User       user.UserRepository
+	Chart      chart.ChartRepository
+	Insight    insight.InsightRepository
+	Audience   audience.AudienceRepository
+	Favourite  favourite.FavouriteRepository
+	Collection collection.CollectionRepository
+	// Seed loads the fixtures into the storage
+	Seed func(fixtures *database.Fixtures) error
+	// Close releases the storage once the server stopped serving
+	Close func()
+}
+
+func newInMemoryRepositories(cfg config.Config, passwordHasher func(string) string) (*repositories, error) {
+	db, closeDatabase, err := newIMDatabase(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only a database opened empty is seeded, a persistent one keeps what it restored
+	isEmpty := db.IsEmpty()
+	seed := func(fixtures *database.Fixtures) error {
+		if !isEmpty {
+			return nil
+		}
+
+		return database.IMLoadFixtures(db, fixtures, passwordHasher)
+	}
+
+	userRepository := user.NewInMemoryDBUserRepository(db)
+
+	return &repositories{
+		User:       &userRepository,
+		Chart:      chart.NewInMemoryDBChartRepository(db),
+		Insight:    insight.NewInMemoryDBInsightRepository(db),
+		Audience:   audience.NewInMemoryDBAudienceRepository(db),
+		Favourite:  favourite.NewInMemoryDBFavouriteRepository(db),
+		Collection: collection.NewInMemoryDBCollectionRepository(db),
+		Seed:       seed,
+		Close:      closeDatabase,
+	}, nil
+}
+
+func newPostgresRepositories(cfg config.Config, passwordHasher func(string) string) (*repositories, error) {
+	db, err := database.NewPostgresDB(cfg.DatabaseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := database.PGMigrate(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	userRepository := user.NewPostgresDBUserRepository(db)
+
+	return &repositories{
+		User:       &userRepository,
+		Chart:      chart.NewPostgresDBChartRepository(db),
+		Insight:    insight.NewPostgresDBInsightRepository(db),
+		Audience:   audience.NewPostgresDBAudienceRepository(db),
+		Favourite:  favourite.NewPostgresDBFavouriteRepository(db),
+		Collection: collection.NewPostgresDBCollectionRepository(db),
+		Seed: func(fixtures *database.Fixtures) error {
+			return database.PGLoadFixtures(db, fixtures, passwordHasher)
+		},
+		Close: db.Close,
+	}, nil
+}
+
+// loadFixtures returns the dataset to seed the storage with, or nil when nothing should be seeded.
+func loadFixtures(cfg config.Config) (*database.Fixtures, error) {
+	if cfg.SeedFile != "" {
+		return database.LoadFixtures(cfg.SeedFile)
+	}
+
+	if cfg.Environment == "dev" {
+		return database.DefaultFixtures()
+	}
+
+	return nil, nil
+}
+
+// seedRepositories loads the fixtures, if any, once the favourites of the fixtures are checked
+// against the registered asset types.
+func seedRepositories(cfg config.Config, repos *repositories, assets *favourite.AssetRegistry) error {
+	fixtures, err := loadFixtures(cfg)
+	if err != nil || fixtures == nil {
+		return err
+	}
+
+	assetTypes := []string{}
+	for _, assetType := range assets.Types() {
+		assetTypes = append(assetTypes, string(assetType))
+	}
+	if err := fixtures.ValidateAssets(assetTypes); err != nil {
+		return err
+	}
+
+	return repos.Seed(fixtures)
+}
+
+func newRepositories(cfg config.Config, passwordHasher func(string) string) (*repositories, error) {
+	switch cfg.DatabaseDriver {
+	case config.DatabaseDriverInMemory:
+		return newInMemoryRepositories(cfg, passwordHasher)
+	case config.DatabaseDriverPostgres:
+		return newPostgresRepositories(cfg, passwordHasher)
+	default:
+		return nil, fmt.Errorf("unknown database driver %q", cfg.DatabaseDriver)
+	}
+}
+
+// wireDependencies returns the dependencies of the router with a shutdown function,
+// which stops the background work and releases the storage.
+func wireDependencies(cfg config.Config) (*RouterDependencies, func(), error) {
 	jwtAuth := utils.NewJWTAuth(cfg.JWTSecretKey)
 	passwordHasher := utils.NewHasher(cfg.HashingSalt)
-	db := database.NewIMDatabase()
 
-	if cfg.Environment == "dev" {
-		database.IMpopulateStorageForDevEnv(db, passwordHasher)
+	repos, err := newRepositories(cfg, passwordHasher)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	// Users
-	userRepository := user.NewInMemoryDBUserRepository(db)
 	userService := user.NewUserService(user.ServiceDependencies{
 		GenerateToken:  utils.NewJWTokenIssuer(jwtAuth),
-		UserRepository: &userRepository,
+		UserRepository: repos.User,
 		PasswordHasher: passwordHasher,
 	})
 
@@ -36,21 +190,40 @@ func wireDependencies(cfg config.Config) (*RouterDependencies, error) {
 	)
 
 	// Favourites
-	chartRepository := chart.NewInMemoryDBChartRepository(db)
-	insightRepository := insight.NewInMemoryDBInsightRepository(db)
-	audienceRepository := audience.NewInMemoryDBAudienceRepository(db)
-	favouriteRepository := favourite.NewInMemoryDBFavouriteRepository(db)
+	// Every asset type a favourite can point to is registered here, a new one only needs its provider
+	assets := favourite.NewAssetRegistry()
+	assets.Register(favourite.NewAssetProvider(favourite.AssetTypeChart, "charts", repos.Chart.GetByIds))
+	if cfg.LegacyInsightJSON {
+		assets.Register(favourite.NewAssetProvider(favourite.AssetTypeInsight, "insights", insight.LegacyGetByIds(repos.Insight.GetByIds)))
+	} else {
+		assets.Register(favourite.NewAssetProvider(favourite.AssetTypeInsight, "insights", repos.Insight.GetByIds))
+	}
+	assets.Register(favourite.NewAssetProvider(favourite.AssetTypeAudience, "audiences", repos.Audience.GetByIds))
+
+	if err := seedRepositories(cfg, repos, assets); err != nil {
+		repos.Close()
+		return nil, nil, err
+	}
 
 	favouriteService := favourite.NewFavouriteService(favourite.FavouriteServiceDependencies{
-		ChartRepository:     chartRepository,
-		InsightRepository:   insightRepository,
-		AudienceRepository:  audienceRepository,
-		FavouriteRepository: favouriteRepository,
+		Assets:              assets,
+		FavouriteRepository: repos.Favourite,
+		Cursors:             utils.NewCursorCodec(cfg.CursorSecretKey),
+		TrashRetention:      cfg.TrashRetention,
 	})
 
+	stopTrashPurger := favouriteService.StartTrashPurger(cfg.TrashPurgeInterval)
+
 	getFavouritesHandler := favourite.GetFavouritesHandler(
 		favourite.GetFavouritesHandlerDependencies{
 			FavouriteService: &favouriteService,
+			Assets:           assets,
+		},
+	)
+
+	getFavouriteHandler := favourite.GetFavouriteHandler(
+		favourite.GetFavouriteHandlerDependencies{
+			FavouriteService: &favouriteService,
 		},
 	)
 
@@ -72,25 +245,301 @@ func wireDependencies(cfg config.Config) (*RouterDependencies, error) {
 		},
 	)
 
+	moveFavouriteHandler := favourite.MoveFavouriteHandler(
+		favourite.MoveFavouriteHandlerDependencies{
+			FavouriteService: &favouriteService,
+		},
+	)
+
+	getFavouriteTagsHandler := favourite.GetFavouriteTagsHandler(
+		favourite.GetFavouriteTagsHandlerDependencies{
+			FavouriteService: &favouriteService,
+		},
+	)
+
+	getFavouriteTrashHandler := favourite.GetFavouriteTrashHandler(
+		favourite.GetFavouriteTrashHandlerDependencies{
+			FavouriteService: &favouriteService,
+		},
+	)
+
+	restoreFavouriteHandler := favourite.RestoreFavouriteHandler(
+		favourite.RestoreFavouriteHandlerDependencies{
+			FavouriteService: &favouriteService,
+		},
+	)
+
+	purgeFavouriteHandler := favourite.PurgeFavouriteHandler(
+		favourite.PurgeFavouriteHandlerDependencies{
+			FavouriteService: &favouriteService,
+		},
+	)
+
+	createFavouritesBatchHandler := favourite.CreateFavouritesBatchHandler(
+		favourite.CreateFavouritesBatchHandlerDependencies{
+			FavouriteService: &favouriteService,
+		},
+	)
+
+	updateFavouritesBatchHandler := favourite.UpdateFavouritesBatchHandler(
+		favourite.UpdateFavouritesBatchHandlerDependencies{
+			FavouriteService: &favouriteService,
+		},
+	)
+
+	deleteFavouritesBatchHandler := favourite.DeleteFavouritesBatchHandler(
+		favourite.DeleteFavouritesBatchHandlerDependencies{
+			FavouriteService: &favouriteService,
+		},
+	)
+
+	// Collections
+	collectionService := collection.NewCollectionService(collection.CollectionServiceDependencies{
+		CollectionRepository: repos.Collection,
+		FavouriteRepository:  repos.Favourite,
+		FavouriteService:     &favouriteService,
+	})
+
+	getCollectionsHandler := collection.GetCollectionsHandler(
+		collection.GetCollectionsHandlerDependencies{
+			CollectionService: &collectionService,
+		},
+	)
+
+	getCollectionHandler := collection.GetCollectionHandler(
+		collection.GetCollectionHandlerDependencies{
+			CollectionService: &collectionService,
+		},
+	)
+
+	createCollectionHandler := collection.CreateCollectionHandler(
+		collection.CreateCollectionHandlerDependencies{
+			CollectionService: &collectionService,
+		},
+	)
+
+	updateCollectionHandler := collection.UpdateCollectionHandler(
+		collection.UpdateCollectionHandlerDependencies{
+			CollectionService: &collectionService,
+		},
+	)
+
+	deleteCollectionHandler := collection.DeleteCollectionHandler(
+		collection.DeleteCollectionHandlerDependencies{
+			CollectionService: &collectionService,
+		},
+	)
+
+	getCollectionFavouritesHandler := collection.GetCollectionFavouritesHandler(
+		collection.GetCollectionFavouritesHandlerDependencies{
+			CollectionService: &collectionService,
+		},
+	)
+
+	addCollectionFavouriteHandler := collection.AddCollectionFavouriteHandler(
+		collection.AddCollectionFavouriteHandlerDependencies{
+			CollectionService: &collectionService,
+		},
+	)
+
+	removeCollectionFavouriteHandler := collection.RemoveCollectionFavouriteHandler(
+		collection.RemoveCollectionFavouriteHandlerDependencies{
+			CollectionService: &collectionService,
+		},
+	)
+
+	// Charts
+	chartService := chart.NewChartService(chart.ChartServiceDependencies{
+		ChartRepository: repos.Chart,
+	})
+
+	getChartsHandler := chart.GetChartsHandler(
+		chart.GetChartsHandlerDependencies{
+			ChartService: &chartService,
+		},
+	)
+
+	getChartHandler := chart.GetChartHandler(
+		chart.GetChartHandlerDependencies{
+			ChartService: &chartService,
+		},
+	)
+
+	createChartHandler := chart.CreateChartHandler(
+		chart.CreateChartHandlerDependencies{
+			ChartService: &chartService,
+		},
+	)
+
+	updateChartHandler := chart.UpdateChartHandler(
+		chart.UpdateChartHandlerDependencies{
+			ChartService: &chartService,
+		},
+	)
+
+	deleteChartHandler := chart.DeleteChartHandler(
+		chart.DeleteChartHandlerDependencies{
+			ChartService: &chartService,
+		},
+	)
+
+	// Insights
+	insightService := insight.NewInsightService(insight.InsightServiceDependencies{
+		InsightRepository: repos.Insight,
+	})
+
+	getInsightsHandler := insight.GetInsightsHandler(
+		insight.GetInsightsHandlerDependencies{
+			InsightService: &insightService,
+			LegacyJSON:     cfg.LegacyInsightJSON,
+		},
+	)
+
+	getInsightHandler := insight.GetInsightHandler(
+		insight.GetInsightHandlerDependencies{
+			InsightService: &insightService,
+			LegacyJSON:     cfg.LegacyInsightJSON,
+		},
+	)
+
+	createInsightHandler := insight.CreateInsightHandler(
+		insight.CreateInsightHandlerDependencies{
+			InsightService: &insightService,
+			LegacyJSON:     cfg.LegacyInsightJSON,
+		},
+	)
+
+	updateInsightHandler := insight.UpdateInsightHandler(
+		insight.UpdateInsightHandlerDependencies{
+			InsightService: &insightService,
+			LegacyJSON:     cfg.LegacyInsightJSON,
+		},
+	)
+
+	deleteInsightHandler := insight.DeleteInsightHandler(
+		insight.DeleteInsightHandlerDependencies{
+			InsightService: &insightService,
+		},
+	)
+
+	// Audiences
+	audienceService := audience.NewAudienceService(audience.AudienceServiceDependencies{
+		AudienceRepository: repos.Audience,
+	})
+
+	getAudiencesHandler := audience.GetAudiencesHandler(
+		audience.GetAudiencesHandlerDependencies{
+			AudienceService: &audienceService,
+		},
+	)
+
+	getAudienceHandler := audience.GetAudienceHandler(
+		audience.GetAudienceHandlerDependencies{
+			AudienceService: &audienceService,
+		},
+	)
+
+	createAudienceHandler := audience.CreateAudienceHandler(
+		audience.CreateAudienceHandlerDependencies{
+			AudienceService: &audienceService,
+		},
+	)
+
+	updateAudienceHandler := audience.UpdateAudienceHandler(
+		audience.UpdateAudienceHandlerDependencies{
+			AudienceService: &audienceService,
+		},
+	)
+
+	deleteAudienceHandler := audience.DeleteAudienceHandler(
+		audience.DeleteAudienceHandlerDependencies{
+			AudienceService: &audienceService,
+		},
+	)
+
 	// Routing
 	routerDependencies := RouterDependencies{
-		JWTAuth:                jwtAuth,
-		UserLoginHandler:       userLoginHandler,
-		GetFavouritesHandler:   getFavouritesHandler,
-		CreateFavouriteHandler: createFavouriteHandler,
-		UpdateFavouriteHandler: updateFavouriteHandler,
-		DeleteFavouriteHandler: deleteFavouriteHandler,
+		JWTAuth:                 jwtAuth,
+		IdempotencyMiddleware:   utils.IdempotencyMiddleware(utils.NewInMemoryIdempotencyStore(cfg.IdempotencyTTL, nil), cfg.IdempotencyMaxBodyBytes),
+		UserLoginHandler:        userLoginHandler,
+		GetFavouritesHandler:    getFavouritesHandler,
+		GetFavouriteHandler:     getFavouriteHandler,
+		CreateFavouriteHandler:  createFavouriteHandler,
+		UpdateFavouriteHandler:  updateFavouriteHandler,
+		DeleteFavouriteHandler:  deleteFavouriteHandler,
+		MoveFavouriteHandler:    moveFavouriteHandler,
+		GetFavouriteTagsHandler: getFavouriteTagsHandler,
+
+		GetFavouriteTrashHandler: getFavouriteTrashHandler,
+		RestoreFavouriteHandler:  restoreFavouriteHandler,
+		PurgeFavouriteHandler:    purgeFavouriteHandler,
+
+		CreateFavouritesBatchHandler: createFavouritesBatchHandler,
+		UpdateFavouritesBatchHandler: updateFavouritesBatchHandler,
+		DeleteFavouritesBatchHandler: deleteFavouritesBatchHandler,
+
+		GetCollectionsHandler:            getCollectionsHandler,
+		GetCollectionHandler:             getCollectionHandler,
+		CreateCollectionHandler:          createCollectionHandler,
+		UpdateCollectionHandler:          updateCollectionHandler,
+		DeleteCollectionHandler:          deleteCollectionHandler,
+		GetCollectionFavouritesHandler:   getCollectionFavouritesHandler,
+		AddCollectionFavouriteHandler:    addCollectionFavouriteHandler,
+		RemoveCollectionFavouriteHandler: removeCollectionFavouriteHandler,
+
+		GetChartsHandler:      getChartsHandler,
+		GetChartHandler:       getChartHandler,
+		CreateChartHandler:    createChartHandler,
+		UpdateChartHandler:    updateChartHandler,
+		DeleteChartHandler:    deleteChartHandler,
+		GetInsightsHandler:    getInsightsHandler,
+		GetInsightHandler:     getInsightHandler,
+		CreateInsightHandler:  createInsightHandler,
+		UpdateInsightHandler:  updateInsightHandler,
+		DeleteInsightHandler:  deleteInsightHandler,
+		GetAudiencesHandler:   getAudiencesHandler,
+		GetAudienceHandler:    getAudienceHandler,
+		CreateAudienceHandler: createAudienceHandler,
+		UpdateAudienceHandler: updateAudienceHandler,
+		DeleteAudienceHandler: deleteAudienceHandler,
+	}
+
+	shutdown := func() {
+		stopTrashPurger()
+		repos.Close()
 	}
 
-	return &routerDependencies, nil
+	return &routerDependencies, shutdown, nil
 }
 
 func StartServer() {
 	cfg := config.NewConfig()
 
-	dependencies, _ := wireDependencies(*cfg)
+	dependencies, shutdown, err := wireDependencies(*cfg)
+	if err != nil {
+		log.Fatalf("Could not start server: %s", err)
+	}
 
 	router := SetupRouter(*dependencies)
+	httpServer := &http.Server{Addr: ":3008", Handler: router}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("Could not start server: %s", err)
+		}
+	}()
+
+	<-ctx.Done()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Could not shut down server gracefully: %s", err)
+	}
 
-	http.ListenAndServe(":3008", router)
+	shutdown()
 }
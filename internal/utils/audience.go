@@ -0,0 +1,84 @@
+package utils
+
+import (
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// AudienceGenders are the values the gender of an audience can take.
+var AudienceGenders = []string{"Female", "Male", "Non-binary", "Other"}
+
+// The messages a characteristic of an audience is rejected with, by the bodies, the service and the fixtures alike.
+var (
+	AudienceGenderMessage = "must be one of " + strings.Join(AudienceGenders[:len(AudienceGenders)-1], ", ") +
+		" or " + AudienceGenders[len(AudienceGenders)-1]
+	AgeGroupMessage    = "must be an age range like 25-34, or like 65+ for no upper bound"
+	CountryMessage     = "must be an ISO 3166-1 alpha-2 country code like GB"
+	NonNegativeMessage = "must be at least 0"
+)
+
+// ageGroupPattern matches a range of ages like 25-34 or an open one like 65+.
+var ageGroupPattern = regexp.MustCompile(`^(\d{1,3})(?:-(\d{1,3})|\+)$`)
+
+// countryValidate only checks country codes, with the list of ISO 3166 countries of the validator.
+var countryValidate = validator.New()
+
+// AudienceCharacteristics are the characteristics of an audience, by their JSON names.
+type AudienceCharacteristics struct {
+	Gender             string
+	BirthCountry       string
+	AgeGroup           string
+	SocialMediaHours   float64
+	PurchasesLastMonth int
+}
+
+// FieldErrors checks the characteristics named in fields. The ones left out are not checked,
+// so an update does not reject what was stored before the checks existed.
+func (c AudienceCharacteristics) FieldErrors(fields ...string) FieldErrors {
+	errs := FieldErrors{}
+
+	for _, field := range fields {
+		switch {
+		case field == "gender" && !IsAudienceGender(c.Gender):
+			errs[field] = AudienceGenderMessage
+		case field == "age_group" && !IsAgeGroup(c.AgeGroup):
+			errs[field] = AgeGroupMessage
+		case field == "birth_country" && !IsCountry(c.BirthCountry):
+			errs[field] = CountryMessage
+		case field == "social_media_hours" && c.SocialMediaHours < 0:
+			errs[field] = NonNegativeMessage
+		case field == "purchases_last_month" && c.PurchasesLastMonth < 0:
+			errs[field] = NonNegativeMessage
+		}
+	}
+
+	return errs
+}
+
+func IsAudienceGender(value string) bool {
+	return slices.Contains(AudienceGenders, value)
+}
+
+func IsAgeGroup(value string) bool {
+	match := ageGroupPattern.FindStringSubmatch(value)
+	if match == nil {
+		return false
+	}
+	if match[2] == "" {
+		return true
+	}
+
+	lower, _ := strconv.Atoi(match[1])
+	upper, _ := strconv.Atoi(match[2])
+
+	return lower < upper
+}
+
+// IsCountry takes the country code in any case, the audience service stores it in upper case.
+func IsCountry(value string) bool {
+	return countryValidate.Var(strings.ToUpper(value), "iso3166_1_alpha2") == nil
+}
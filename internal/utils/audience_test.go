@@ -0,0 +1,51 @@
+package utils_test
+
+import (
+	"platform-go-challenge/internal/utils"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAudienceCharacteristicsFieldErrors(t *testing.T) {
+	invalid := utils.AudienceCharacteristics{
+		Gender:             "Unknown",
+		BirthCountry:       "Greece",
+		AgeGroup:           "45-30",
+		SocialMediaHours:   -1,
+		PurchasesLastMonth: -2,
+	}
+
+	t.Run("should reject every invalid characteristic it is asked to check", func(t *testing.T) {
+		// Act
+		errs := invalid.FieldErrors("gender", "birth_country", "age_group", "social_media_hours", "purchases_last_month")
+
+		// Assert
+		assert.Equal(t, utils.FieldErrors{
+			"gender":               "must be one of Female, Male, Non-binary or Other",
+			"birth_country":        "must be an ISO 3166-1 alpha-2 country code like GB",
+			"age_group":            "must be an age range like 25-34, or like 65+ for no upper bound",
+			"social_media_hours":   "must be at least 0",
+			"purchases_last_month": "must be at least 0",
+		}, errs)
+	})
+
+	t.Run("should leave out the characteristics it is not asked to check", func(t *testing.T) {
+		// Act
+		errs := invalid.FieldErrors("gender")
+
+		// Assert
+		assert.Equal(t, utils.FieldErrors{"gender": "must be one of Female, Male, Non-binary or Other"}, errs)
+	})
+
+	t.Run("should accept valid characteristics with the country in any case", func(t *testing.T) {
+		// Arrange
+		valid := utils.AudienceCharacteristics{Gender: "Non-binary", BirthCountry: "gr", AgeGroup: "65+"}
+
+		// Act
+		errs := valid.FieldErrors("gender", "birth_country", "age_group", "social_media_hours", "purchases_last_month")
+
+		// Assert
+		assert.Empty(t, errs)
+	})
+}
@@ -38,6 +38,27 @@ func AuthenticatorMiddleware() func(next http.Handler) http.Handler {
 	}
 }
 
+// AdminClaim is set to true in the tokens of the users who can use the admin endpoints.
+const AdminClaim = "admin"
+
+// AdminMiddleware lets through the requests with an admin token, it goes after AuthenticatorMiddleware.
+func AdminMiddleware() func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		handler := func(w http.ResponseWriter, r *http.Request) {
+			_, claims, _ := jwtauth.FromContext(r.Context())
+
+			if admin, _ := claims[AdminClaim].(bool); !admin {
+				RespondWithError(w, http.StatusForbidden, "Admin access required")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		}
+
+		return http.HandlerFunc(handler)
+	}
+}
+
 func VerifierMiddleware(jwtAuth *jwtauth.JWTAuth) func(next http.Handler) http.Handler {
 	return jwtauth.Verifier(jwtAuth)
 }
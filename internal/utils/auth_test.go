@@ -69,6 +69,34 @@ func TestAuthenticatorMiddleware_MissingToken(t *testing.T) {
 	assert.Equal(t, http.StatusUnauthorized, w.Code)
 }
 
+func TestAdminMiddleware(t *testing.T) {
+	// Arrange
+	jwtAuth := jwtauth.New("HS256", []byte("secret"), nil)
+	baseHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	handler := utils.VerifierMiddleware(jwtAuth)(utils.AuthenticatorMiddleware()(utils.AdminMiddleware()(baseHandler)))
+	adminToken, _, _ := utils.NewJWToken(jwtAuth, map[string]any{"sub": "admin", utils.AdminClaim: true})
+	userToken, _, _ := utils.NewJWToken(jwtAuth, map[string]any{"sub": "user"})
+
+	serve := func(token string) int {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+
+		return w.Code
+	}
+
+	// Act
+	adminStatus := serve(adminToken)
+	userStatus := serve(userToken)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, adminStatus)
+	assert.Equal(t, http.StatusForbidden, userStatus)
+}
+
 // Helpers
 func setupHandler(jwtAuth *jwtauth.JWTAuth) http.Handler {
 	if jwtAuth == nil {
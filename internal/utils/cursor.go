@@ -0,0 +1,67 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+var ErrInvalidCursor = errors.New("cursor is invalid")
+
+// CursorCodec turns a position in a listing into an opaque cursor and back.
+// Cursors are signed, so a client can only send back the ones the server gave it.
+type CursorCodec struct {
+	secret []byte
+}
+
+func NewCursorCodec(secret string) *CursorCodec {
+	return &CursorCodec{secret: []byte(secret)}
+}
+
+func (codec *CursorCodec) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, codec.secret)
+	mac.Write(payload)
+
+	return mac.Sum(nil)
+}
+
+// Encode returns the cursor of a position, which can be any value that marshals to JSON.
+func (codec *CursorCodec) Encode(position any) (string, error) {
+	payload, err := json.Marshal(position)
+	if err != nil {
+		return "", err
+	}
+
+	encoding := base64.RawURLEncoding
+
+	return encoding.EncodeToString(payload) + "." + encoding.EncodeToString(codec.sign(payload)), nil
+}
+
+// Decode reads a cursor made by Encode into position, and fails with ErrInvalidCursor when it was tampered with.
+func (codec *CursorCodec) Decode(cursor string, position any) error {
+	encodedPayload, encodedSignature, found := strings.Cut(cursor, ".")
+	if !found {
+		return ErrInvalidCursor
+	}
+
+	encoding := base64.RawURLEncoding
+
+	payload, err := encoding.DecodeString(encodedPayload)
+	if err != nil {
+		return ErrInvalidCursor
+	}
+
+	signature, err := encoding.DecodeString(encodedSignature)
+	if err != nil || !hmac.Equal(signature, codec.sign(payload)) {
+		return ErrInvalidCursor
+	}
+
+	if err := json.Unmarshal(payload, position); err != nil {
+		return ErrInvalidCursor
+	}
+
+	return nil
+}
@@ -0,0 +1,68 @@
+package utils_test
+
+import (
+	"platform-go-challenge/internal/utils"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type position struct {
+	Key string `json:"key"`
+	Id  int    `json:"id"`
+}
+
+func TestCursorCodec(t *testing.T) {
+	codec := utils.NewCursorCodec("secret")
+
+	t.Run("Should decode the position it encoded", func(t *testing.T) {
+		// Arrange
+		expected := position{Key: "chart", Id: 3}
+
+		// Act
+		cursor, encodeErr := codec.Encode(expected)
+		var result position
+		decodeErr := codec.Decode(cursor, &result)
+
+		// Assert
+		assert.NoError(t, encodeErr)
+		assert.NoError(t, decodeErr)
+		assert.Equal(t, expected, result)
+	})
+
+	t.Run("Should return invalid cursor when the payload was changed", func(t *testing.T) {
+		// Arrange
+		cursor, _ := codec.Encode(position{Key: "chart", Id: 3})
+		other, _ := codec.Encode(position{Key: "chart", Id: 4})
+		_, signature, _ := strings.Cut(cursor, ".")
+		otherPayload, _, _ := strings.Cut(other, ".")
+
+		// Act
+		err := codec.Decode(otherPayload+"."+signature, &position{})
+
+		// Assert
+		assert.ErrorIs(t, err, utils.ErrInvalidCursor)
+	})
+
+	t.Run("Should return invalid cursor when it was signed with another secret", func(t *testing.T) {
+		// Arrange
+		cursor, _ := utils.NewCursorCodec("other").Encode(position{Key: "chart", Id: 3})
+
+		// Act
+		err := codec.Decode(cursor, &position{})
+
+		// Assert
+		assert.ErrorIs(t, err, utils.ErrInvalidCursor)
+	})
+
+	t.Run("Should return invalid cursor when it is not a cursor", func(t *testing.T) {
+		for _, cursor := range []string{"", "abc", "a.b", "!!.!!"} {
+			// Act
+			err := codec.Decode(cursor, &position{})
+
+			// Assert
+			assert.ErrorIs(t, err, utils.ErrInvalidCursor, cursor)
+		}
+	})
+}
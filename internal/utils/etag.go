@@ -0,0 +1,45 @@
+package utils
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	ETagHeader    = "ETag"
+	IfMatchHeader = "If-Match"
+)
+
+var ErrInvalidIfMatch = errors.New("If-Match must hold a single strong ETag")
+
+// FormatETag returns the strong entity tag of a resource version.
+func FormatETag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+func SetETag(w http.ResponseWriter, version int) {
+	w.Header().Set(ETagHeader, FormatETag(version))
+}
+
+// GetIfMatchVersion returns the version the client expects the resource to be at,
+// or nil when the request has no If-Match header or it matches any version ("*").
+// Weak tags are rejected, since If-Match always uses the strong comparison.
+func GetIfMatchVersion(r *http.Request) (*int, error) {
+	ifMatch := strings.TrimSpace(r.Header.Get(IfMatchHeader))
+	if ifMatch == "" || ifMatch == "*" {
+		return nil, nil
+	}
+
+	if len(ifMatch) < 2 || !strings.HasPrefix(ifMatch, `"`) || !strings.HasSuffix(ifMatch, `"`) {
+		return nil, ErrInvalidIfMatch
+	}
+
+	version, err := strconv.Atoi(ifMatch[1 : len(ifMatch)-1])
+	if err != nil || version < 0 {
+		return nil, ErrInvalidIfMatch
+	}
+
+	return &version, nil
+}
@@ -0,0 +1,56 @@
+package utils_test
+
+import (
+	"net/http/httptest"
+	"platform-go-challenge/internal/utils"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetIfMatchVersion(t *testing.T) {
+	t.Run("Should return the version of a strong ETag", func(t *testing.T) {
+		// Arrange
+		r := httptest.NewRequest("PATCH", "/favourites/id", nil)
+		r.Header.Set(utils.IfMatchHeader, utils.FormatETag(3))
+
+		// Act
+		version, err := utils.GetIfMatchVersion(r)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, 3, *version)
+	})
+
+	t.Run("Should return nil when the header is missing or a wildcard", func(t *testing.T) {
+		// Arrange
+		missing := httptest.NewRequest("PATCH", "/favourites/id", nil)
+		wildcard := httptest.NewRequest("PATCH", "/favourites/id", nil)
+		wildcard.Header.Set(utils.IfMatchHeader, "*")
+
+		// Act
+		missingVersion, missingErr := utils.GetIfMatchVersion(missing)
+		wildcardVersion, wildcardErr := utils.GetIfMatchVersion(wildcard)
+
+		// Assert
+		assert.NoError(t, missingErr)
+		assert.Nil(t, missingVersion)
+		assert.NoError(t, wildcardErr)
+		assert.Nil(t, wildcardVersion)
+	})
+
+	t.Run("Should return error for weak, unquoted or listed tags", func(t *testing.T) {
+		for _, ifMatch := range []string{`W/"3"`, `3`, `"3", "4"`, `"abc"`, `"`} {
+			// Arrange
+			r := httptest.NewRequest("PATCH", "/favourites/id", nil)
+			r.Header.Set(utils.IfMatchHeader, ifMatch)
+
+			// Act
+			version, err := utils.GetIfMatchVersion(r)
+
+			// Assert
+			assert.ErrorIs(t, err, utils.ErrInvalidIfMatch, ifMatch)
+			assert.Nil(t, version)
+		}
+	})
+}
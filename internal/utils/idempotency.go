@@ -0,0 +1,264 @@
+package utils
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	IdempotencyKeyHeader      = "Idempotency-Key"
+	IdempotentReplayedHeader  = "Idempotent-Replayed"
+	maxIdempotencyKeyLength   = 255
+	idempotencyAnonymousScope = "anonymous"
+)
+
+var (
+	ErrIdempotencyKeyInFlight = errors.New("A request with this Idempotency-Key is still being processed")
+	ErrIdempotencyKeyReused   = errors.New("Idempotency-Key was already used with a different request")
+)
+
+// idempotentHeaders are the response headers that are stored and replayed along with the body.
+var idempotentHeaders = []string{"Content-Type", "ETag", "Location"}
+
+// IdempotentResponse is what gets replayed for a repeated key.
+type IdempotentResponse struct {
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+// IdempotencyStore keeps the responses of completed requests and reserves the keys of the ones in flight.
+type IdempotencyStore interface {
+	// Begin reserves the key, or returns the stored response when the request with the key already completed.
+	// It fails with ErrIdempotencyKeyInFlight while the key is reserved and with ErrIdempotencyKeyReused
+	// when the key was used for a request with another fingerprint.
+	Begin(key string, fingerprint string) (*IdempotentResponse, error)
+	Complete(key string, response IdempotentResponse)
+	// Release drops the reservation, so the request can be retried with the same key.
+	Release(key string)
+}
+
+type idempotencyEntry struct {
+	fingerprint string
+	response    *IdempotentResponse
+	expiresAt   time.Time
+}
+
+type inMemoryIdempotencyStore struct {
+	mu        sync.Mutex
+	entries   map[string]*idempotencyEntry
+	ttl       time.Duration
+	now       func() time.Time
+	nextSweep time.Time
+}
+
+// NewInMemoryIdempotencyStore keeps every completed response for ttl after it was stored.
+func NewInMemoryIdempotencyStore(ttl time.Duration, now func() time.Time) *inMemoryIdempotencyStore {
+	if now == nil {
+		now = time.Now
+	}
+
+	return &inMemoryIdempotencyStore{
+		entries: map[string]*idempotencyEntry{},
+		ttl:     ttl,
+		now:     now,
+	}
+}
+
+func (store *inMemoryIdempotencyStore) Begin(key string, fingerprint string) (*IdempotentResponse, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	now := store.now()
+	store.sweep(now)
+
+	entry, found := store.entries[key]
+	if found && entry.response != nil && !now.Before(entry.expiresAt) {
+		delete(store.entries, key)
+		found = false
+	}
+
+	if !found {
+		store.entries[key] = &idempotencyEntry{fingerprint: fingerprint}
+		return nil, nil
+	}
+
+	if entry.fingerprint != fingerprint {
+		return nil, ErrIdempotencyKeyReused
+	}
+
+	if entry.response == nil {
+		return nil, ErrIdempotencyKeyInFlight
+	}
+
+	return entry.response, nil
+}
+
+func (store *inMemoryIdempotencyStore) Complete(key string, response IdempotentResponse) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	entry, found := store.entries[key]
+	if !found {
+		return
+	}
+
+	entry.response = &response
+	entry.expiresAt = store.now().Add(store.ttl)
+}
+
+func (store *inMemoryIdempotencyStore) Release(key string) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	entry, found := store.entries[key]
+	if found && entry.response == nil {
+		delete(store.entries, key)
+	}
+}
+
+// sweep drops the expired responses at most once per ttl, it must be called with the lock held.
+func (store *inMemoryIdempotencyStore) sweep(now time.Time) {
+	if now.Before(store.nextSweep) {
+		return
+	}
+
+	for key, entry := range store.entries {
+		if entry.response != nil && !now.Before(entry.expiresAt) {
+			delete(store.entries, key)
+		}
+	}
+
+	store.nextSweep = now.Add(store.ttl)
+}
+
+// idempotencyRecorder passes the response through while keeping a copy of it.
+type idempotencyRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (rec *idempotencyRecorder) WriteHeader(status int) {
+	if rec.status == 0 {
+		rec.status = status
+	}
+
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *idempotencyRecorder) Write(data []byte) (int, error) {
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+
+	rec.body.Write(data)
+
+	return rec.ResponseWriter.Write(data)
+}
+
+func requestFingerprint(r *http.Request, body []byte) string {
+	hasher := sha256.New()
+	hasher.Write([]byte(r.Method + " " + r.URL.Path + "\n"))
+	hasher.Write(body)
+
+	return hex.EncodeToString(hasher.Sum(nil))
+}
+
+// IdempotencyMiddleware replays the stored response of a request that is retried with the same Idempotency-Key.
+// Keys are scoped to the user of the auth token, so it has to run after the auth middlewares.
+// Requests without the header are passed through, and 5xx responses are not stored so they can be retried.
+// The body of a keyed request is read to fingerprint it, so one longer than maxBodyBytes is rejected with 413.
+func IdempotencyMiddleware(store IdempotencyStore, maxBodyBytes int64) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		handler := func(w http.ResponseWriter, r *http.Request) {
+			idempotencyKey := r.Header.Get(IdempotencyKeyHeader)
+			if idempotencyKey == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if len(idempotencyKey) > maxIdempotencyKeyLength {
+				RespondWithError(w, http.StatusBadRequest, "Idempotency-Key is too long")
+				return
+			}
+
+			scope, err := GetJWTokenSub(r)
+			if err != nil {
+				scope = idempotencyAnonymousScope
+			}
+			key := scope + ":" + idempotencyKey
+
+			body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+			if err != nil {
+				var maxBytesErr *http.MaxBytesError
+				if errors.As(err, &maxBytesErr) {
+					RespondWithError(w, http.StatusRequestEntityTooLarge, "Body is too large")
+					return
+				}
+
+				RespondWithError(w, http.StatusBadRequest, "Could not read body")
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			stored, err := store.Begin(key, requestFingerprint(r, body))
+			if err != nil {
+				if errors.Is(err, ErrIdempotencyKeyReused) {
+					RespondWithError(w, http.StatusUnprocessableEntity, err.Error())
+					return
+				}
+
+				RespondWithError(w, http.StatusConflict, err.Error())
+				return
+			}
+
+			if stored != nil {
+				for name, values := range stored.Header {
+					w.Header()[name] = values
+				}
+				w.Header().Set(IdempotentReplayedHeader, "true")
+				w.WriteHeader(stored.Status)
+				w.Write(stored.Body)
+				return
+			}
+
+			recorder := &idempotencyRecorder{ResponseWriter: w}
+			completed := false
+			defer func() {
+				if !completed {
+					store.Release(key)
+				}
+			}()
+
+			next.ServeHTTP(recorder, r)
+
+			if recorder.status == 0 || recorder.status >= http.StatusInternalServerError {
+				return
+			}
+
+			header := http.Header{}
+			for _, name := range idempotentHeaders {
+				if values := recorder.Header().Values(name); len(values) > 0 {
+					header[http.CanonicalHeaderKey(name)] = slices.Clone(values)
+				}
+			}
+
+			store.Complete(key, IdempotentResponse{
+				Status: recorder.status,
+				Header: header,
+				Body:   recorder.body.Bytes(),
+			})
+			completed = true
+		}
+
+		return http.HandlerFunc(handler)
+	}
+}
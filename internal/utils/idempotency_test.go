@@ -0,0 +1,295 @@
+package utils_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"platform-go-challenge/internal/utils"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func TestInMemoryIdempotencyStore(t *testing.T) {
+	response := utils.IdempotentResponse{
+		Status: http.StatusCreated,
+		Header: http.Header{"Content-Type": {"application/json"}},
+		Body:   []byte(`{}`),
+	}
+
+	t.Run("should reserve a new key and replay it once completed", func(t *testing.T) {
+		// Arrange
+		store := utils.NewInMemoryIdempotencyStore(time.Hour, nil)
+
+		// Act
+		first, firstErr := store.Begin("key", "fingerprint")
+		_, inFlightErr := store.Begin("key", "fingerprint")
+		store.Complete("key", response)
+		replayed, replayErr := store.Begin("key", "fingerprint")
+
+		// Assert
+		assert.Nil(t, first)
+		assert.NoError(t, firstErr)
+		assert.ErrorIs(t, inFlightErr, utils.ErrIdempotencyKeyInFlight)
+		assert.NoError(t, replayErr)
+		assert.Equal(t, &response, replayed)
+	})
+
+	t.Run("should reject a key reused with another fingerprint", func(t *testing.T) {
+		// Arrange
+		store := utils.NewInMemoryIdempotencyStore(time.Hour, nil)
+		store.Begin("key", "fingerprint")
+		store.Complete("key", response)
+
+		// Act
+		result, err := store.Begin("key", "other fingerprint")
+
+		// Assert
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, utils.ErrIdempotencyKeyReused)
+	})
+
+	t.Run("should forget a response after the ttl", func(t *testing.T) {
+		// Arrange
+		clock := &fakeClock{now: time.Now()}
+		store := utils.NewInMemoryIdempotencyStore(time.Minute, clock.Now)
+		store.Begin("key", "fingerprint")
+		store.Complete("key", response)
+
+		// Act
+		clock.now = clock.now.Add(time.Minute)
+		result, err := store.Begin("key", "other fingerprint")
+
+		// Assert
+		assert.Nil(t, result)
+		assert.NoError(t, err)
+	})
+
+	t.Run("should let a released key be used again", func(t *testing.T) {
+		// Arrange
+		store := utils.NewInMemoryIdempotencyStore(time.Hour, nil)
+		store.Begin("key", "fingerprint")
+
+		// Act
+		store.Release("key")
+		result, err := store.Begin("key", "other fingerprint")
+
+		// Assert
+		assert.Nil(t, result)
+		assert.NoError(t, err)
+	})
+}
+
+func newIdempotentRequest(userId string, key string, body string) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "/favourites", strings.NewReader(body))
+	if key != "" {
+		r.Header.Set(utils.IdempotencyKeyHeader, key)
+	}
+
+	tokenAuth := jwtauth.New("HS256", []byte("secret"), nil)
+	token, _, _ := tokenAuth.Encode(map[string]any{"sub": userId})
+
+	return r.WithContext(jwtauth.NewContext(r.Context(), token, nil))
+}
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	t.Run("should replay the stored response without calling the handler again", func(t *testing.T) {
+		// Arrange
+		var calls atomic.Int32
+		handler := utils.IdempotencyMiddleware(utils.NewInMemoryIdempotencyStore(time.Hour, nil), 1024)(
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls.Add(1)
+				utils.RespondWithData(w, http.StatusCreated, map[string]int32{"call": calls.Load()})
+			}),
+		)
+		first := httptest.NewRecorder()
+		second := httptest.NewRecorder()
+
+		// Act
+		handler.ServeHTTP(first, newIdempotentRequest("user", "key", `{"a":1}`))
+		handler.ServeHTTP(second, newIdempotentRequest("user", "key", `{"a":1}`))
+
+		// Assert
+		assert.Equal(t, int32(1), calls.Load())
+		assert.Equal(t, http.StatusCreated, second.Code)
+		assert.Equal(t, first.Body.String(), second.Body.String())
+		assert.Equal(t, "application/json", second.Header().Get("Content-Type"))
+		assert.Equal(t, "true", second.Header().Get(utils.IdempotentReplayedHeader))
+		assert.Empty(t, first.Header().Get(utils.IdempotentReplayedHeader))
+	})
+
+	t.Run("should replay the ETag and the Location of the stored response", func(t *testing.T) {
+		// Arrange
+		handler := utils.IdempotencyMiddleware(utils.NewInMemoryIdempotencyStore(time.Hour, nil), 1024)(
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("ETag", `"1"`)
+				w.Header().Set("Location", "/favourites/1")
+				w.Header().Set("X-Request-Id", "first")
+				utils.RespondWithMessage(w, http.StatusCreated, "created")
+			}),
+		)
+		handler.ServeHTTP(httptest.NewRecorder(), newIdempotentRequest("user", "key", `{}`))
+		w := httptest.NewRecorder()
+
+		// Act
+		handler.ServeHTTP(w, newIdempotentRequest("user", "key", `{}`))
+
+		// Assert
+		assert.Equal(t, `"1"`, w.Header().Get("ETag"))
+		assert.Equal(t, "/favourites/1", w.Header().Get("Location"))
+		assert.Empty(t, w.Header().Get("X-Request-Id"))
+	})
+
+	t.Run("should return 413 when the body is longer than the limit", func(t *testing.T) {
+		// Arrange
+		var calls atomic.Int32
+		handler := utils.IdempotencyMiddleware(utils.NewInMemoryIdempotencyStore(time.Hour, nil), 8)(
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls.Add(1)
+			}),
+		)
+		w := httptest.NewRecorder()
+
+		// Act
+		handler.ServeHTTP(w, newIdempotentRequest("user", "key", `{"description":"too long"}`))
+
+		// Assert
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+		assert.Equal(t, int32(0), calls.Load())
+	})
+
+	t.Run("should return 422 when the key is reused with another body", func(t *testing.T) {
+		// Arrange
+		handler := utils.IdempotencyMiddleware(utils.NewInMemoryIdempotencyStore(time.Hour, nil), 1024)(
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				utils.RespondWithMessage(w, http.StatusCreated, "created")
+			}),
+		)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(httptest.NewRecorder(), newIdempotentRequest("user", "key", `{"a":1}`))
+
+		// Act
+		handler.ServeHTTP(w, newIdempotentRequest("user", "key", `{"a":2}`))
+
+		// Assert
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	})
+
+	t.Run("should return 409 while a request with the same key is in flight", func(t *testing.T) {
+		// Arrange
+		started := make(chan struct{})
+		finish := make(chan struct{})
+		handler := utils.IdempotencyMiddleware(utils.NewInMemoryIdempotencyStore(time.Hour, nil), 1024)(
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				close(started)
+				<-finish
+				utils.RespondWithMessage(w, http.StatusCreated, "created")
+			}),
+		)
+		first := httptest.NewRecorder()
+		done := make(chan struct{})
+		go func() {
+			handler.ServeHTTP(first, newIdempotentRequest("user", "key", `{}`))
+			close(done)
+		}()
+		<-started
+
+		// Act
+		second := httptest.NewRecorder()
+		handler.ServeHTTP(second, newIdempotentRequest("user", "key", `{}`))
+		close(finish)
+		<-done
+
+		// Assert
+		assert.Equal(t, http.StatusConflict, second.Code)
+		assert.Equal(t, http.StatusCreated, first.Code)
+	})
+
+	t.Run("should not store server errors so the request can be retried", func(t *testing.T) {
+		// Arrange
+		var calls atomic.Int32
+		handler := utils.IdempotencyMiddleware(utils.NewInMemoryIdempotencyStore(time.Hour, nil), 1024)(
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if calls.Add(1) == 1 {
+					utils.RespondWithError(w, http.StatusInternalServerError, "Internal Server Error")
+					return
+				}
+				utils.RespondWithMessage(w, http.StatusCreated, "created")
+			}),
+		)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(httptest.NewRecorder(), newIdempotentRequest("user", "key", `{}`))
+
+		// Act
+		handler.ServeHTTP(w, newIdempotentRequest("user", "key", `{}`))
+
+		// Assert
+		assert.Equal(t, int32(2), calls.Load())
+		assert.Equal(t, http.StatusCreated, w.Code)
+	})
+
+	t.Run("should scope keys to the user and pass requests without a key through", func(t *testing.T) {
+		// Arrange
+		var calls atomic.Int32
+		handler := utils.IdempotencyMiddleware(utils.NewInMemoryIdempotencyStore(time.Hour, nil), 1024)(
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls.Add(1)
+				utils.RespondWithMessage(w, http.StatusCreated, "created")
+			}),
+		)
+
+		// Act
+		handler.ServeHTTP(httptest.NewRecorder(), newIdempotentRequest("user", "key", `{}`))
+		handler.ServeHTTP(httptest.NewRecorder(), newIdempotentRequest("other user", "key", `{}`))
+		handler.ServeHTTP(httptest.NewRecorder(), newIdempotentRequest("user", "", `{}`))
+		handler.ServeHTTP(httptest.NewRecorder(), newIdempotentRequest("user", "", `{}`))
+
+		// Assert
+		assert.Equal(t, int32(4), calls.Load())
+	})
+
+	t.Run("should give the handler the same body it read", func(t *testing.T) {
+		// Arrange
+		var received string
+		handler := utils.IdempotencyMiddleware(utils.NewInMemoryIdempotencyStore(time.Hour, nil), 1024)(
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				received = string(body)
+				utils.RespondWithMessage(w, http.StatusCreated, "created")
+			}),
+		)
+
+		// Act
+		handler.ServeHTTP(httptest.NewRecorder(), newIdempotentRequest("user", "key", `{"a":1}`))
+
+		// Assert
+		assert.Equal(t, `{"a":1}`, received)
+	})
+
+	t.Run("should reject keys that are too long", func(t *testing.T) {
+		// Arrange
+		handler := utils.IdempotencyMiddleware(utils.NewInMemoryIdempotencyStore(time.Hour, nil), 1024)(
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}),
+		)
+		w := httptest.NewRecorder()
+		r := newIdempotentRequest("user", strings.Repeat("k", 256), `{}`)
+
+		// Act
+		handler.ServeHTTP(w, r)
+
+		// Assert
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
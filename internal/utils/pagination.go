@@ -11,6 +11,23 @@ type Pagination struct {
 	Page     int `json:"page"`
 	PageSize int `json:"pageSize"`
 	MaxPage  int `json:"maxPage"`
+	// NextCursor and PrevCursor are sent back as the after and before query params to get the pages around this one,
+	// they are left out at the ends of the listing
+	NextCursor string `json:"nextCursor,omitempty"`
+	PrevCursor string `json:"prevCursor,omitempty"`
+}
+
+// PageQuery is either an offset page (Number) or the page right after or before a cursor.
+type PageQuery struct {
+	Size   int
+	Number int
+	After  string
+	Before string
+}
+
+// IsCursor reports whether the page is requested with a cursor, in which case Number is not used.
+func (query PageQuery) IsCursor() bool {
+	return query.After != "" || query.Before != ""
 }
 
 type PaginatedDataResponse[T any] struct {
@@ -23,6 +40,8 @@ var (
 	ErrCouldNotParsePageNumber = errors.New("could not parse page number")
 	ErrInvalidPageSize         = errors.New("page size out of bounds")
 	ErrInvalidPageNumber       = errors.New("page number out of bounds")
+	ErrConflictingCursors      = errors.New("after and before cannot be used together")
+	ErrCursorWithPageNumber    = errors.New("page number cannot be used together with a cursor")
 )
 
 func GetPaginationQuery(r *http.Request, defaultPageSize int, defaultPageNumber int) (int, int, error) {
@@ -74,6 +93,32 @@ func GetPaginationQuery(r *http.Request, defaultPageSize int, defaultPageNumber
 	return pageSize, pageNumber, nil
 }
 
+// GetPageQuery reads an offset page like GetPaginationQuery, or a cursor page from the after or before query params.
+func GetPageQuery(r *http.Request, defaultPageSize int, defaultPageNumber int) (PageQuery, error) {
+	pageSize, pageNumber, err := GetPaginationQuery(r, defaultPageSize, defaultPageNumber)
+	if err != nil {
+		return PageQuery{}, err
+	}
+
+	query := r.URL.Query()
+	page := PageQuery{
+		Size:   pageSize,
+		Number: pageNumber,
+		After:  query.Get("after"),
+		Before: query.Get("before"),
+	}
+
+	if page.After != "" && page.Before != "" {
+		return PageQuery{}, ErrConflictingCursors
+	}
+
+	if page.IsCursor() && query.Get("pageNumber") != "" {
+		return PageQuery{}, ErrCursorWithPageNumber
+	}
+
+	return page, nil
+}
+
 func RespondWithPaginatedData[T any](w http.ResponseWriter, status int, data T, pagination Pagination) {
 	respondWithJSON(w, status, PaginatedDataResponse[T]{Data: data, Pagination: pagination})
 }
@@ -91,3 +91,53 @@ func TestGetPaginationQuery(t *testing.T) {
 		assert.ErrorIs(t, err, utils.ErrInvalidPageSize)
 	})
 }
+
+func TestGetPageQuery(t *testing.T) {
+	t.Run("Should return an offset page when no cursor is given", func(t *testing.T) {
+		// Arrange
+		r := &http.Request{URL: &url.URL{RawQuery: "pageSize=5&pageNumber=1"}}
+
+		// Act
+		page, err := utils.GetPageQuery(r, 20, 0)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, utils.PageQuery{Size: 5, Number: 1}, page)
+		assert.False(t, page.IsCursor())
+	})
+
+	t.Run("Should return a cursor page when after is given", func(t *testing.T) {
+		// Arrange
+		r := &http.Request{URL: &url.URL{RawQuery: "pageSize=5&after=abc"}}
+
+		// Act
+		page, err := utils.GetPageQuery(r, 20, 0)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, utils.PageQuery{Size: 5, After: "abc"}, page)
+		assert.True(t, page.IsCursor())
+	})
+
+	t.Run("Should return error when after and before are given", func(t *testing.T) {
+		// Arrange
+		r := &http.Request{URL: &url.URL{RawQuery: "after=abc&before=def"}}
+
+		// Act
+		_, err := utils.GetPageQuery(r, 20, 0)
+
+		// Assert
+		assert.ErrorIs(t, err, utils.ErrConflictingCursors)
+	})
+
+	t.Run("Should return error when a cursor is given with pageNumber", func(t *testing.T) {
+		// Arrange
+		r := &http.Request{URL: &url.URL{RawQuery: "before=abc&pageNumber=2"}}
+
+		// Act
+		_, err := utils.GetPageQuery(r, 20, 0)
+
+		// Assert
+		assert.ErrorIs(t, err, utils.ErrCursorWithPageNumber)
+	})
+}
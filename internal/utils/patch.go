@@ -0,0 +1,103 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+// The content types of the PATCH bodies, a body sent as plain JSON is read as a merge patch.
+const (
+	MergePatchContentType = "application/merge-patch+json"
+	JSONPatchContentType  = "application/json-patch+json"
+)
+
+var (
+	ErrInvalidJSONPatch     = errors.New("Invalid JSON Patch body")
+	ErrUnsupportedJSONPatch = errors.New("JSON Patch can only add, replace or remove whole fields")
+)
+
+// MergePatch is embedded in a PATCH body to read it as a JSON Merge Patch (RFC 7396),
+// where a field left out is not changed and a field set to null is removed.
+// BodyValidator fills it with the fields the patch has.
+type MergePatch struct {
+	// fields holds whether each field of the patch was null
+	fields map[string]bool
+}
+
+// Has tells whether the patch changes the field, by the name of the field in the JSON body.
+func (patch MergePatch) Has(field string) bool {
+	_, found := patch.fields[field]
+
+	return found
+}
+
+// IsNull tells whether the patch removes the field.
+func (patch MergePatch) IsNull(field string) bool {
+	return patch.fields[field]
+}
+
+func (patch *MergePatch) setFields(fields map[string]bool) {
+	patch.fields = fields
+}
+
+type mergePatchBody interface {
+	setFields(fields map[string]bool)
+}
+
+// mergePatchFields returns the top level fields of a merge patch and whether each of them is null.
+func mergePatchFields(raw []byte) (map[string]bool, error) {
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &members); err != nil {
+		return nil, err
+	}
+
+	fields := make(map[string]bool, len(members))
+	for name, value := range members {
+		fields[name] = bytes.Equal(bytes.TrimSpace(value), []byte("null"))
+	}
+
+	return fields, nil
+}
+
+type jsonPatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value"`
+}
+
+// jsonPatchToMergePatch turns a JSON Patch (RFC 6902) into the merge patch with the same result.
+// Only the operations on whole top level fields have one, the others return ErrUnsupportedJSONPatch.
+// The errors it returns can be shown to the client as they are.
+func jsonPatchToMergePatch(raw []byte) ([]byte, error) {
+	var operations []jsonPatchOperation
+	if err := json.Unmarshal(raw, &operations); err != nil {
+		return nil, ErrInvalidJSONPatch
+	}
+
+	unescape := strings.NewReplacer("~1", "/", "~0", "~")
+	merge := make(map[string]json.RawMessage, len(operations))
+
+	for _, operation := range operations {
+		field, found := strings.CutPrefix(operation.Path, "/")
+		if !found || field == "" || strings.Contains(field, "/") {
+			return nil, ErrUnsupportedJSONPatch
+		}
+		field = unescape.Replace(field)
+
+		switch operation.Op {
+		case "add", "replace":
+			if operation.Value == nil {
+				return nil, ErrInvalidJSONPatch
+			}
+			merge[field] = operation.Value
+		case "remove":
+			merge[field] = json.RawMessage("null")
+		default:
+			return nil, ErrUnsupportedJSONPatch
+		}
+	}
+
+	return json.Marshal(merge)
+}
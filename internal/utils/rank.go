@@ -0,0 +1,91 @@
+package utils
+
+import (
+	"strings"
+)
+
+// rankDigits are the digits of a rank in ascending byte order, so ranks compare like plain strings.
+const rankDigits = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// RankBetween returns a rank that sorts after before and ahead of after, where an empty before
+// stands for the start of the list and an empty after for its end. Ranks are fractional indexes:
+// a rank always fits between two others by growing a digit longer, so moving an item only changes that item.
+// Ranks never end in the zero digit, which keeps room ahead of every rank.
+// When after is not greater than before, which only happens when concurrent moves gave two items the same rank,
+// the result sorts right after before.
+func RankBetween(before string, after string) string {
+	if after != "" && before >= after {
+		return before + rankMidpoint("", "")
+	}
+
+	if after == "" && before != "" {
+		return rankAfter(before)
+	}
+
+	return rankMidpoint(before, after)
+}
+
+// rankAfter steps the last digit of rank that can grow instead of halving the space left at the end,
+// so appending item after item, the most common move, only adds a digit every len(rankDigits) ranks.
+func rankAfter(rank string) string {
+	for i := len(rank) - 1; i >= 0; i-- {
+		digit := strings.IndexByte(rankDigits, rank[i])
+		if digit < len(rankDigits)-1 {
+			return rank[:i] + string(rankDigits[digit+1])
+		}
+	}
+
+	return rank + string(rankDigits[1])
+}
+
+// rankMidpoint expects before to sort ahead of after, an empty after being the end of the list.
+func rankMidpoint(before string, after string) string {
+	if after != "" {
+		// The shared prefix is kept and the midpoint is taken between what follows it
+		n := 0
+		for n < len(after) && rankDigitAt(before, n) == after[n] {
+			n++
+		}
+
+		if n > 0 {
+			return after[:n] + rankMidpoint(rankTail(before, n), after[n:])
+		}
+	}
+
+	low := 0
+	if before != "" {
+		low = strings.IndexByte(rankDigits, before[0])
+	}
+
+	high := len(rankDigits)
+	if after != "" {
+		high = strings.IndexByte(rankDigits, after[0])
+	}
+
+	if high-low > 1 {
+		return string(rankDigits[(low+high+1)/2])
+	}
+
+	// The first digits are adjacent, so the rank starts with the one of before and continues after the rest of it
+	if len(after) > 1 {
+		return after[:1]
+	}
+
+	return string(rankDigits[low]) + rankMidpoint(rankTail(before, 1), "")
+}
+
+func rankDigitAt(rank string, i int) byte {
+	if i < len(rank) {
+		return rank[i]
+	}
+
+	return rankDigits[0]
+}
+
+func rankTail(rank string, n int) string {
+	if n < len(rank) {
+		return rank[n:]
+	}
+
+	return ""
+}
@@ -0,0 +1,85 @@
+package utils_test
+
+import (
+	"math/rand"
+	"platform-go-challenge/internal/utils"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRankBetween(t *testing.T) {
+	t.Run("Should return a rank between the bounds", func(t *testing.T) {
+		cases := []struct{ before, after string }{
+			{"", ""},
+			{"", "V"},
+			{"V", ""},
+			{"1", "2"},
+			{"0V", "1"},
+			{"", "01"},
+			{"zz", ""},
+			{"a1", "a2"},
+			{"000000000003V", "000000000004V"},
+		}
+
+		for _, c := range cases {
+			// Act
+			result := utils.RankBetween(c.before, c.after)
+
+			// Assert
+			assert.Greater(t, result, c.before)
+			if c.after != "" {
+				assert.Less(t, result, c.after)
+			}
+			assert.False(t, strings.HasSuffix(result, "0"), "%q should not end in zero", result)
+		}
+	})
+
+	t.Run("Should keep every rank in order when inserting at random positions", func(t *testing.T) {
+		// Arrange
+		random := rand.New(rand.NewSource(1))
+		ranks := []string{}
+
+		// Act
+		for range 2000 {
+			i := random.Intn(len(ranks) + 1)
+			before, after := "", ""
+			if i > 0 {
+				before = ranks[i-1]
+			}
+			if i < len(ranks) {
+				after = ranks[i]
+			}
+
+			ranks = slices.Insert(ranks, i, utils.RankBetween(before, after))
+		}
+
+		// Assert
+		assert.True(t, slices.IsSorted(ranks))
+		assert.Len(t, slices.Compact(slices.Clone(ranks)), len(ranks))
+	})
+
+	t.Run("Should stay short when always appending", func(t *testing.T) {
+		// Arrange
+		rank := ""
+
+		// Act
+		for range 1000 {
+			rank = utils.RankBetween(rank, "")
+		}
+
+		// Assert
+		assert.LessOrEqual(t, len(rank), 40)
+	})
+
+	t.Run("Should sort right after before when the bounds are equal", func(t *testing.T) {
+		// Act
+		result := utils.RankBetween("V", "V")
+
+		// Assert
+		assert.Greater(t, result, "V")
+		assert.Less(t, result, "W")
+	})
+}
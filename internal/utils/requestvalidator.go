@@ -1,30 +1,68 @@
 package utils
 
 import (
+	"bytes"
 	"context"
+	"encoding"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"mime"
 	"net/http"
+	"reflect"
+	"strings"
 
 	"github.com/go-playground/validator/v10"
 )
 
 const parsedBodyKey string = "parsedBody"
 
+// BodyValidator parses the body into T and validates it before calling next.
+// When T embeds MergePatch the body is a merge patch, or a JSON Patch sent as JSONPatchContentType,
+// and its unknown fields are rejected since they could not be patched.
+// A body failing validation is answered with the FieldErrors under data.
 func BodyValidator[T any](next http.HandlerFunc) http.HandlerFunc {
-	validate := validator.New()
 	return func(w http.ResponseWriter, r *http.Request) {
 		var parsedBody T
 
-		if err := json.NewDecoder(r.Body).Decode(&parsedBody); err != nil {
+		raw, err := io.ReadAll(r.Body)
+		if err != nil {
 			RespondWithError(w, http.StatusBadRequest, "Invalid JSON body")
 			return
 		}
 
-		if err := validate.Struct(parsedBody); err != nil {
+		patch, isPatch := any(&parsedBody).(mergePatchBody)
+		if isPatch && isContentType(r, JSONPatchContentType) {
+			raw, err = jsonPatchToMergePatch(raw)
+			if err != nil {
+				RespondWithError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+		}
+
+		decoder := json.NewDecoder(bytes.NewReader(raw))
+		if isPatch {
+			decoder.DisallowUnknownFields()
+		}
+		if err := decoder.Decode(&parsedBody); err != nil {
+			RespondWithError(w, http.StatusBadRequest, decodeErrorMessage(err))
+			return
+		}
+
+		if isPatch {
+			fields, err := mergePatchFields(raw)
+			if err != nil {
+				RespondWithError(w, http.StatusBadRequest, "Invalid JSON body")
+				return
+			}
+			patch.setFields(fields)
+		}
+
+		if err := bodyValidate.Struct(parsedBody); err != nil {
 			errs := err.(validator.ValidationErrors)
 			message := fmt.Sprintf("Body Validation Failed, %s", errs)
-			RespondWithError(w, http.StatusBadRequest, message)
+			RespondWithErrorAndData(w, http.StatusBadRequest, message, validationFieldErrors(reflect.TypeFor[T](), errs))
 			return
 		}
 
@@ -43,3 +81,49 @@ func GetParsedBody[T any](r *http.Request) (T, bool) {
 
 	return *body, true
 }
+
+func isContentType(r *http.Request, contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+
+	return err == nil && mediaType == contentType
+}
+
+// decodeErrorMessage names the field a body could not be decoded because of, when there is one.
+func decodeErrorMessage(err error) string {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return fmt.Sprintf("Field %s must be %s", typeErr.Field, jsonTypeName(typeErr.Type))
+	}
+
+	// encoding/json has no error type for unknown fields
+	if field, found := strings.CutPrefix(err.Error(), "json: unknown field "); found {
+		return fmt.Sprintf("Unknown field %s", field)
+	}
+
+	return "Invalid JSON body"
+}
+
+var textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+
+func jsonTypeName(t reflect.Type) string {
+	if reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		return "a string"
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return jsonTypeName(t.Elem())
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	default:
+		return "an object"
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"platform-go-challenge/internal/utils"
 	"testing"
 
+	"github.com/go-playground/validator/v10"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -85,6 +86,191 @@ func TestBodyValidator(t *testing.T) {
 		// Assert
 		assert.Equal(t, http.StatusBadRequest, res.Code)
 	})
+
+	t.Run("should return 400 naming the field for wrong types", func(t *testing.T) {
+		// Arrange
+		handler := utils.BodyValidator[DummyRequest](func(w http.ResponseWriter, r *http.Request) {
+			t.Fatal("should not call next handler on an invalid field")
+		})
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(`{"email":5,"password":"supersecret"}`))
+		res := httptest.NewRecorder()
+
+		// Act
+		handler.ServeHTTP(res, req)
+
+		// Assert
+		assert.Equal(t, http.StatusBadRequest, res.Code)
+		assert.JSONEq(t, `{"error":"Field email must be a string"}`, res.Body.String())
+	})
+
+	t.Run("should ignore unknown fields of a body that is not a patch", func(t *testing.T) {
+		// Arrange
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(`{"email":"valid@example.com","password":"supersecret","name":"x"}`))
+		res := httptest.NewRecorder()
+
+		var capturedParsed DummyRequest
+		handler := utils.BodyValidator[DummyRequest](func(w http.ResponseWriter, r *http.Request) {
+			capturedParsed, _ = utils.GetParsedBody[DummyRequest](r)
+		})
+
+		// Act
+		handler.ServeHTTP(res, req)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.Equal(t, "valid@example.com", capturedParsed.Email)
+	})
+}
+
+type DummyBatch struct {
+	Items []DummyItem `json:"items" validate:"required,max=2,dive"`
+}
+
+type DummyItem struct {
+	Name  string `json:"name" validate:"required,max=5"`
+	Color string `json:"color" validate:"omitempty,dummy_color"`
+}
+
+func init() {
+	utils.RegisterValidation(
+		"dummy_color",
+		func(fl validator.FieldLevel) bool { return fl.Field().String() == "red" },
+		"must be red",
+	)
+}
+
+func TestBodyValidatorFieldErrors(t *testing.T) {
+	send := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(body))
+		res := httptest.NewRecorder()
+
+		handler := utils.BodyValidator[DummyBatch](func(w http.ResponseWriter, r *http.Request) {})
+		handler.ServeHTTP(res, req)
+
+		return res
+	}
+
+	t.Run("should return the rejected fields by their JSON path", func(t *testing.T) {
+		// Act
+		res := send(`{"items":[{"name":"ok"},{"name":"too long","color":"blue"}]}`)
+
+		// Assert
+		assert.Equal(t, http.StatusBadRequest, res.Code)
+		var body utils.ErrorWithDataResponse[utils.FieldErrors]
+		assert.NoError(t, json.NewDecoder(res.Body).Decode(&body))
+		assert.Equal(t, utils.FieldErrors{
+			"items[1].name":  "must be at most 5 characters",
+			"items[1].color": "must be red",
+		}, body.Data)
+		assert.Contains(t, body.Error, "Body Validation Failed")
+	})
+
+	t.Run("should return the message of the failed tag", func(t *testing.T) {
+		// Act
+		missing := send(`{}`)
+		tooMany := send(`{"items":[{"name":"a"},{"name":"b"},{"name":"c"}]}`)
+
+		// Assert
+		assert.Contains(t, missing.Body.String(), `"items":"is required"`)
+		assert.Contains(t, tooMany.Body.String(), `"items":"must be at most 2 items"`)
+	})
+
+	t.Run("should let a body pass the registered tag", func(t *testing.T) {
+		// Act
+		res := send(`{"items":[{"name":"ok","color":"red"}]}`)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, res.Code)
+	})
+}
+
+func TestFieldErrors(t *testing.T) {
+	t.Run("should list every field in the error", func(t *testing.T) {
+		// Arrange
+		errs := utils.FieldErrors{"gender": "is required", "age_group": "must be an age range"}
+
+		// Act
+		message := errs.Error()
+
+		// Assert
+		assert.Equal(t, "Body Validation Failed, age_group must be an age range, gender is required", message)
+	})
+}
+
+type DummyPatch struct {
+	utils.MergePatch
+	Name  *string  `json:"name" validate:"omitnil,max=5"`
+	Tags  []string `json:"tags"`
+	Count *int     `json:"count"`
+}
+
+func TestBodyValidatorPatch(t *testing.T) {
+	parse := func(contentType string, body string) (DummyPatch, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(http.MethodPatch, "/", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", contentType)
+		res := httptest.NewRecorder()
+
+		var parsed DummyPatch
+		handler := utils.BodyValidator[DummyPatch](func(w http.ResponseWriter, r *http.Request) {
+			parsed, _ = utils.GetParsedBody[DummyPatch](r)
+		})
+		handler.ServeHTTP(res, req)
+
+		return parsed, res
+	}
+
+	t.Run("should tell the fields a merge patch left out from the ones it set to null", func(t *testing.T) {
+		// Act
+		parsed, res := parse(utils.MergePatchContentType, `{"name":null,"count":2}`)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.True(t, parsed.Has("name"))
+		assert.True(t, parsed.IsNull("name"))
+		assert.True(t, parsed.Has("count"))
+		assert.False(t, parsed.IsNull("count"))
+		assert.Equal(t, 2, *parsed.Count)
+		assert.False(t, parsed.Has("tags"))
+	})
+
+	t.Run("should read a JSON Patch of whole fields as the same merge patch", func(t *testing.T) {
+		// Act
+		parsed, res := parse(utils.JSONPatchContentType+"; charset=utf-8",
+			`[{"op":"replace","path":"/name","value":"new"},{"op":"remove","path":"/tags"},{"op":"add","path":"/count","value":1}]`)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.Equal(t, "new", *parsed.Name)
+		assert.True(t, parsed.Has("tags"))
+		assert.True(t, parsed.IsNull("tags"))
+		assert.Equal(t, 1, *parsed.Count)
+	})
+
+	t.Run("should return 400 for JSON Patch operations that are not on a whole field", func(t *testing.T) {
+		// Act
+		_, elementRes := parse(utils.JSONPatchContentType, `[{"op":"add","path":"/tags/-","value":"q2"}]`)
+		_, moveRes := parse(utils.JSONPatchContentType, `[{"op":"move","from":"/name","path":"/tags"}]`)
+		_, objectRes := parse(utils.JSONPatchContentType, `{"name":"new"}`)
+
+		// Assert
+		assert.Equal(t, http.StatusBadRequest, elementRes.Code)
+		assert.Contains(t, elementRes.Body.String(), utils.ErrUnsupportedJSONPatch.Error())
+		assert.Equal(t, http.StatusBadRequest, moveRes.Code)
+		assert.Contains(t, moveRes.Body.String(), utils.ErrUnsupportedJSONPatch.Error())
+		assert.Equal(t, http.StatusBadRequest, objectRes.Code)
+		assert.Contains(t, objectRes.Body.String(), utils.ErrInvalidJSONPatch.Error())
+	})
+
+	t.Run("should validate and reject unknown fields of a patch", func(t *testing.T) {
+		// Act
+		_, invalidRes := parse(utils.MergePatchContentType, `{"name":"too long"}`)
+		_, unknownRes := parse(utils.JSONPatchContentType, `[{"op":"replace","path":"/other","value":1}]`)
+
+		// Assert
+		assert.Equal(t, http.StatusBadRequest, invalidRes.Code)
+		assert.Equal(t, http.StatusBadRequest, unknownRes.Code)
+		assert.Contains(t, unknownRes.Body.String(), "Unknown field")
+	})
 }
 
 func TestGetParsedBody(t *testing.T) {
@@ -13,6 +13,11 @@ type DataResponse[T any] struct {
 	Data T `json:"data"`
 }
 
+type ErrorWithDataResponse[T any] struct {
+	Error string `json:"error"`
+	Data  T      `json:"data"`
+}
+
 type MessageResponse struct {
 	Message string `json:"message"`
 }
@@ -34,3 +39,8 @@ func RespondWithMessage(w http.ResponseWriter, status int, message string) {
 func RespondWithData[T any](w http.ResponseWriter, status int, data T) {
 	respondWithJSON(w, status, DataResponse[T]{Data: data})
 }
+
+// RespondWithErrorAndData is for errors that point to an existing resource, e.g. a conflict.
+func RespondWithErrorAndData[T any](w http.ResponseWriter, status int, message string, data T) {
+	respondWithJSON(w, status, ErrorWithDataResponse[T]{Error: message, Data: data})
+}
@@ -64,3 +64,22 @@ func TestRespondWithData(t *testing.T) {
 	assert.True(t, ok)
 	assert.Equal(t, "value", decodedData["key"])
 }
+
+func TestRespondWithErrorAndData(t *testing.T) {
+	// Arrange
+	recorder := httptest.NewRecorder()
+	data := map[string]string{"key": "value"}
+
+	// Act
+	utils.RespondWithErrorAndData(recorder, http.StatusConflict, "Conflict", data)
+
+	// Assert
+	assert.Equal(t, http.StatusConflict, recorder.Code)
+	assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+
+	var body utils.ErrorWithDataResponse[map[string]string]
+	err := json.NewDecoder(recorder.Body).Decode(&body)
+	assert.NoError(t, err)
+	assert.Equal(t, "Conflict", body.Error)
+	assert.Equal(t, data, body.Data)
+}
@@ -0,0 +1,134 @@
+package utils
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// bodyValidate is shared by every BodyValidator, so the tags registered with RegisterValidation work in any body.
+var bodyValidate = validator.New()
+
+// customMessages holds the message of every tag registered with RegisterValidation.
+var customMessages = map[string]string{}
+
+// RegisterValidation adds a validate tag for the request bodies, with the message a field failing it is rejected with.
+// It is meant to be called from the init of the package the tag belongs to, before any body is validated.
+func RegisterValidation(tag string, fn validator.Func, message string) {
+	if err := bodyValidate.RegisterValidation(tag, fn); err != nil {
+		panic(err)
+	}
+
+	customMessages[tag] = message
+}
+
+// FieldErrors maps the JSON path of every rejected field of a body to why it was rejected.
+type FieldErrors map[string]string
+
+func (errs FieldErrors) Error() string {
+	fields := make([]string, 0, len(errs))
+	for field, message := range errs {
+		fields = append(fields, field+" "+message)
+	}
+	sort.Strings(fields)
+
+	return "Body Validation Failed, " + strings.Join(fields, ", ")
+}
+
+// validationFieldErrors turns the errors of validating a body of type t into FieldErrors.
+func validationFieldErrors(t reflect.Type, errs validator.ValidationErrors) FieldErrors {
+	result := FieldErrors{}
+	for _, err := range errs {
+		result[jsonFieldPath(t, err.StructNamespace())] = validationMessage(err)
+	}
+
+	return result
+}
+
+func validationMessage(err validator.FieldError) string {
+	if message, found := customMessages[err.Tag()]; found {
+		return message
+	}
+
+	param := err.Param()
+	unit := ""
+	switch err.Kind() {
+	case reflect.String:
+		unit = " characters"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		unit = " items"
+	}
+
+	switch err.Tag() {
+	case "required":
+		return "is required"
+	case "email":
+		return "must be an email"
+	case "uuid", "uuid4":
+		return "must be a UUID"
+	case "oneof":
+		return "must be one of " + strings.Join(strings.Fields(param), ", ")
+	case "max", "lte":
+		return "must be at most " + param + unit
+	case "min", "gte":
+		return "must be at least " + param + unit
+	case "len":
+		return "must be exactly " + param + unit
+	case "gt":
+		return "must be more than " + param + unit
+	case "lt":
+		return "must be less than " + param + unit
+	default:
+		return fmt.Sprintf("failed the %s check", err.Tag())
+	}
+}
+
+// jsonFieldPath turns the struct namespace of a validation error, like "Body.Items[0].Description",
+// into the path of the field in the JSON body, like "items[0].description".
+func jsonFieldPath(t reflect.Type, namespace string) string {
+	segments := strings.Split(namespace, ".")[1:]
+	path := make([]string, 0, len(segments))
+
+	for _, segment := range segments {
+		name, index, indexed := strings.Cut(segment, "[")
+
+		for t != nil && t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+
+		var field reflect.StructField
+		found := false
+		if t != nil && t.Kind() == reflect.Struct {
+			field, found = t.FieldByName(name)
+		}
+		if !found {
+			// Should not happen since the namespace comes from t, keep the Go name rather than failing
+			path = append(path, segment)
+			t = nil
+			continue
+		}
+
+		jsonName := name
+		if tag, _, _ := strings.Cut(field.Tag.Get("json"), ","); tag != "" && tag != "-" {
+			jsonName = tag
+		}
+		t = field.Type
+
+		if indexed {
+			jsonName += "[" + index
+			for t.Kind() == reflect.Pointer {
+				t = t.Elem()
+			}
+			if t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+				t = t.Elem()
+			}
+		}
+
+		path = append(path, jsonName)
+	}
+
+	return strings.Join(path, ".")
+}
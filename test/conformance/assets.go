@@ -0,0 +1,377 @@
+// Package conformance holds the contract every repository implementation has to honour.
+// Each storage backend runs the same suites from its own tests by passing a constructor.
+package conformance
+
+import (
+	"platform-go-challenge/internal/database"
+	"platform-go-challenge/internal/domain/audience"
+	"platform-go-challenge/internal/domain/chart"
+	"platform-go-challenge/internal/domain/insight"
+	"platform-go-challenge/internal/utils"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	concurrentWorkers    = 8
+	concurrentIterations = 25
+)
+
+type assetRepository[T any] interface {
+	GetByIds(ids uuid.UUIDs) ([]T, error)
+	GetById(id uuid.UUID) (*T, error)
+}
+
+// adminAssetRepository is an asset repository the admin endpoints can also write to.
+type adminAssetRepository[T any] interface {
+	assetRepository[T]
+	GetPaginated(pageSize int, pageNumber int) ([]T, utils.Pagination, error)
+	Create(item T) (*T, error)
+	Update(item T) (*T, error)
+	Delete(id uuid.UUID) error
+}
+
+func ChartRepository(t *testing.T, newRepository func(t *testing.T, charts []chart.Chart) chart.ChartRepository) {
+	newChart := func(id uuid.UUID, title string) chart.Chart {
+		return chart.Chart{
+			Id:            id,
+			SchemaVersion: chart.ChartSchemaVersion,
+			Title:         title,
+			Kind:          chart.ChartKindBar,
+			XAxis:         chart.ChartAxis{Title: "Month", Type: chart.AxisTypeCategory},
+			YAxis:         chart.ChartAxis{Title: "Revenue", Type: chart.AxisTypeNumeric, Unit: "EUR"},
+			Series: []chart.ChartSeries{
+				{Name: "2024", Points: []chart.ChartPoint{{X: chart.TextX("Jan"), Y: 2}, {X: chart.TextX("Feb"), Y: 4.5}}},
+				{Name: "2025", Points: []chart.ChartPoint{}},
+			},
+		}
+	}
+
+	assetRepositorySuite(
+		t,
+		func(t *testing.T, charts []chart.Chart) assetRepository[chart.Chart] { return newRepository(t, charts) },
+		func(id uuid.UUID) chart.Chart { return newChart(id, "chart "+id.String()) },
+	)
+	adminAssetRepositorySuite(
+		t,
+		func(t *testing.T, charts []chart.Chart) adminAssetRepository[chart.Chart] {
+			return newRepository(t, charts)
+		},
+		newChart,
+		func(c chart.Chart) chart.Chart {
+			c.Title = "changed " + c.Title
+			c.Kind = chart.ChartKindScatter
+			c.XAxis = chart.ChartAxis{Title: "Day", Type: chart.AxisTypeNumeric}
+			c.Series = []chart.ChartSeries{{Name: "visits", Points: []chart.ChartPoint{{X: chart.NumberX(3), Y: -1}}}}
+			return c
+		},
+		chart.ErrChartNotFound,
+	)
+}
+
+func InsightRepository(t *testing.T, newRepository func(t *testing.T, insights []insight.Insight) insight.InsightRepository) {
+	assetRepositorySuite(
+		t,
+		func(t *testing.T, insights []insight.Insight) assetRepository[insight.Insight] {
+			return newRepository(t, insights)
+		},
+		func(id uuid.UUID) insight.Insight {
+			return insight.Insight{Id: id, Text: "insight " + id.String()}
+		},
+	)
+	adminAssetRepositorySuite(
+		t,
+		func(t *testing.T, insights []insight.Insight) adminAssetRepository[insight.Insight] {
+			return newRepository(t, insights)
+		},
+		func(id uuid.UUID, text string) insight.Insight {
+			return insight.Insight{Id: id, Text: text}
+		},
+		func(i insight.Insight) insight.Insight {
+			i.Text = "changed " + i.Text
+			return i
+		},
+		insight.ErrInsightNotFound,
+	)
+
+	t.Run("should return the existing insight when the text is taken ignoring case", func(t *testing.T) {
+		// Arrange
+		existing := insight.Insight{Id: uuid.New(), Text: "Most users log in on mobile"}
+		repo := newRepository(t, []insight.Insight{existing})
+
+		// Act
+		result, err := repo.Create(insight.Insight{Id: uuid.New(), Text: "most users log in on MOBILE"})
+
+		// Assert
+		assert.ErrorIs(t, err, insight.ErrInsightTextTaken)
+		assert.Equal(t, &existing, result)
+	})
+
+	t.Run("should return error when updating to the text of another insight", func(t *testing.T) {
+		// Arrange
+		first := insight.Insight{Id: uuid.New(), Text: "first"}
+		second := insight.Insight{Id: uuid.New(), Text: "second"}
+		repo := newRepository(t, []insight.Insight{first, second})
+
+		// Act
+		result, err := repo.Update(insight.Insight{Id: second.Id, Text: "FIRST"})
+		stored, _ := repo.GetById(second.Id)
+
+		// Assert
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, insight.ErrInsightTextTaken)
+		assert.Equal(t, &second, stored)
+	})
+
+	t.Run("should change the case of the text of an insight", func(t *testing.T) {
+		// Arrange
+		stored := insight.Insight{Id: uuid.New(), Text: "mobile first"}
+		repo := newRepository(t, []insight.Insight{stored})
+
+		// Act
+		result, err := repo.Update(insight.Insight{Id: stored.Id, Text: "Mobile First"})
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, "Mobile First", result.Text)
+	})
+}
+
+func AudienceRepository(t *testing.T, newRepository func(t *testing.T, audiences []audience.Audience) audience.AudienceRepository) {
+	newAudience := func(id uuid.UUID, birthCountry string) audience.Audience {
+		return audience.Audience{
+			Id:                 id,
+			Gender:             "Female",
+			BirthCountry:       birthCountry,
+			AgeGroup:           "25-34",
+			SocialMediaHours:   2.5,
+			PurchasesLastMonth: 4,
+		}
+	}
+
+	assetRepositorySuite(
+		t,
+		func(t *testing.T, audiences []audience.Audience) assetRepository[audience.Audience] {
+			return newRepository(t, audiences)
+		},
+		func(id uuid.UUID) audience.Audience { return newAudience(id, "GR") },
+	)
+	adminAssetRepositorySuite(
+		t,
+		func(t *testing.T, audiences []audience.Audience) adminAssetRepository[audience.Audience] {
+			return newRepository(t, audiences)
+		},
+		newAudience,
+		func(a audience.Audience) audience.Audience {
+			a.Gender = "Non-binary"
+			a.AgeGroup = "65+"
+			a.SocialMediaHours = 0
+			a.PurchasesLastMonth = 12
+			return a
+		},
+		audience.ErrAudienceNotFound,
+	)
+}
+
+func assetRepositorySuite[T any](
+	t *testing.T,
+	newRepository func(t *testing.T, items []T) assetRepository[T],
+	newItem func(id uuid.UUID) T,
+) {
+	newItems := func(count int) ([]T, uuid.UUIDs) {
+		items := []T{}
+		ids := uuid.UUIDs{}
+		for range count {
+			id := uuid.New()
+			items = append(items, newItem(id))
+			ids = append(ids, id)
+		}
+
+		return items, ids
+	}
+
+	t.Run("should return item by id", func(t *testing.T) {
+		// Arrange
+		items, ids := newItems(3)
+		repo := newRepository(t, items)
+
+		// Act
+		result, err := repo.GetById(ids[1])
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, &items[1], result)
+	})
+
+	t.Run("should return not found error when item does not exist", func(t *testing.T) {
+		// Arrange
+		items, _ := newItems(1)
+		repo := newRepository(t, items)
+
+		// Act
+		result, err := repo.GetById(uuid.New())
+
+		// Assert
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, database.ErrItemNotFound)
+	})
+
+	t.Run("should return items in the order of the ids skipping missing ones", func(t *testing.T) {
+		// Arrange
+		items, ids := newItems(3)
+		repo := newRepository(t, items)
+
+		// Act
+		result, err := repo.GetByIds(uuid.UUIDs{ids[2], uuid.New(), ids[0]})
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, []T{items[2], items[0]}, result)
+	})
+
+	t.Run("should return empty list when none of the ids exist", func(t *testing.T) {
+		// Arrange
+		items, _ := newItems(2)
+		repo := newRepository(t, items)
+
+		// Act
+		missing, missingErr := repo.GetByIds(uuid.UUIDs{uuid.New()})
+		none, noneErr := repo.GetByIds(uuid.UUIDs{})
+
+		// Assert
+		assert.NoError(t, missingErr)
+		assert.NoError(t, noneErr)
+		assert.Equal(t, []T{}, missing)
+		assert.Equal(t, []T{}, none)
+	})
+
+	t.Run("should serve concurrent reads", func(t *testing.T) {
+		// Arrange
+		items, ids := newItems(concurrentWorkers)
+		repo := newRepository(t, items)
+
+		var wg sync.WaitGroup
+		errs := make(chan error, concurrentWorkers*concurrentIterations*2)
+
+		// Act
+		for w := range concurrentWorkers {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for range concurrentIterations {
+					if _, err := repo.GetById(ids[w]); err != nil {
+						errs <- err
+					}
+					if _, err := repo.GetByIds(ids); err != nil {
+						errs <- err
+					}
+				}
+			}()
+		}
+		wg.Wait()
+		close(errs)
+
+		// Assert
+		for err := range errs {
+			assert.NoError(t, err)
+		}
+	})
+}
+
+// adminAssetRepositorySuite checks the writes of the admin endpoints. newItem builds an item
+// listed by key, change returns the item with its fields changed and notFound is the error
+// of a write to a missing item.
+func adminAssetRepositorySuite[T any](
+	t *testing.T,
+	newRepository func(t *testing.T, items []T) adminAssetRepository[T],
+	newItem func(id uuid.UUID, key string) T,
+	change func(item T) T,
+	notFound error,
+) {
+	t.Run("should list items by key across pages", func(t *testing.T) {
+		// Arrange
+		c, a, b := newItem(uuid.New(), "c"), newItem(uuid.New(), "a"), newItem(uuid.New(), "b")
+		repo := newRepository(t, []T{c, a, b})
+
+		// Act
+		first, firstPagination, firstErr := repo.GetPaginated(2, 0)
+		second, secondPagination, secondErr := repo.GetPaginated(2, 1)
+		past, _, pastErr := repo.GetPaginated(2, 2)
+
+		// Assert
+		assert.NoError(t, firstErr)
+		assert.NoError(t, secondErr)
+		assert.NoError(t, pastErr)
+		assert.Equal(t, []T{a, b}, first)
+		assert.Equal(t, []T{c}, second)
+		assert.Equal(t, []T{}, past)
+		assert.Equal(t, utils.Pagination{Page: 0, PageSize: 2, MaxPage: 1}, firstPagination)
+		assert.Equal(t, utils.Pagination{Page: 1, PageSize: 2, MaxPage: 1}, secondPagination)
+	})
+
+	t.Run("should create item that can be read back", func(t *testing.T) {
+		// Arrange
+		repo := newRepository(t, []T{})
+		id := uuid.New()
+		item := newItem(id, "new")
+
+		// Act
+		created, err := repo.Create(item)
+		stored, getErr := repo.GetById(id)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.NoError(t, getErr)
+		assert.Equal(t, &item, created)
+		assert.Equal(t, &item, stored)
+	})
+
+	t.Run("should update item", func(t *testing.T) {
+		// Arrange
+		id := uuid.New()
+		item := newItem(id, "old")
+		repo := newRepository(t, []T{item})
+		changed := change(item)
+
+		// Act
+		updated, err := repo.Update(changed)
+		stored, getErr := repo.GetById(id)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.NoError(t, getErr)
+		assert.Equal(t, &changed, updated)
+		assert.Equal(t, &changed, stored)
+	})
+
+	t.Run("should return not found error when updating missing item", func(t *testing.T) {
+		// Arrange
+		repo := newRepository(t, []T{})
+
+		// Act
+		result, err := repo.Update(newItem(uuid.New(), "missing"))
+
+		// Assert
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, notFound)
+	})
+
+	t.Run("should delete item once", func(t *testing.T) {
+		// Arrange
+		id := uuid.New()
+		repo := newRepository(t, []T{newItem(id, "deleted")})
+
+		// Act
+		err := repo.Delete(id)
+		_, getErr := repo.GetById(id)
+		againErr := repo.Delete(id)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.ErrorIs(t, getErr, database.ErrItemNotFound)
+		assert.ErrorIs(t, againErr, notFound)
+	})
+}
@@ -0,0 +1,344 @@
+package conformance
+
+import (
+	"platform-go-challenge/internal/database"
+	"platform-go-challenge/internal/domain/collection"
+	"platform-go-challenge/internal/domain/favourite"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newCollection(userId uuid.UUID, name string) collection.Collection {
+	now := time.Now().UTC().Truncate(time.Microsecond)
+
+	return collection.Collection{
+		Id:          uuid.New(),
+		UserId:      userId,
+		Name:        name,
+		Description: "description",
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+}
+
+func createCollection(t *testing.T, repo collection.CollectionRepository, userId uuid.UUID, name string) collection.Collection {
+	t.Helper()
+
+	created, err := repo.Create(newCollection(userId, name))
+	require.NoError(t, err)
+
+	return *created
+}
+
+// addFavourites adds the favourites to the collection a second apart, in the given order.
+func addFavourites(t *testing.T, repo collection.CollectionRepository, collectionId uuid.UUID, favourites []favourite.Favourite) {
+	t.Helper()
+
+	addedAt := time.Now().UTC().Truncate(time.Microsecond)
+	for i, fav := range favourites {
+		require.NoError(t, repo.AddFavourite(collectionId, fav.Id, addedAt.Add(time.Duration(i)*time.Second)))
+	}
+}
+
+func collectionNames(collections []collection.Collection) []string {
+	result := []string{}
+	for _, c := range collections {
+		result = append(result, c.Name)
+	}
+
+	return result
+}
+
+// CollectionRepository runs against a collection repository and a favourite repository of the same database,
+// since favourites are put in collections and deleting one takes it out of them.
+func CollectionRepository(t *testing.T, newRepositories func(t *testing.T) (collection.CollectionRepository, favourite.FavouriteRepository)) {
+	t.Run("should create collection and return it by id", func(t *testing.T) {
+		// Arrange
+		repo, _ := newRepositories(t)
+		c := newCollection(uuid.New(), "Q2 campaign")
+
+		// Act
+		created, createErr := repo.Create(c)
+		result, err := repo.GetById(c.Id)
+
+		// Assert
+		assert.NoError(t, createErr)
+		assert.Equal(t, &c, created)
+		assert.NoError(t, err)
+		assert.Equal(t, &c, result)
+	})
+
+	t.Run("should return not found error when collection does not exist", func(t *testing.T) {
+		// Arrange
+		repo, _ := newRepositories(t)
+
+		// Act
+		result, err := repo.GetById(uuid.New())
+
+		// Assert
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, database.ErrItemNotFound)
+	})
+
+	t.Run("should return the existing collection when the user already has the name in any case", func(t *testing.T) {
+		// Arrange
+		repo, _ := newRepositories(t)
+		userId := uuid.New()
+		existing := createCollection(t, repo, userId, "Gen Z research")
+
+		// Act
+		result, err := repo.Create(newCollection(userId, "GEN Z RESEARCH"))
+		_, otherUserErr := repo.Create(newCollection(uuid.New(), "Gen Z research"))
+
+		// Assert
+		assert.ErrorIs(t, err, collection.ErrCollectionNameTaken)
+		assert.Equal(t, &existing, result)
+		assert.NoError(t, otherUserErr)
+	})
+
+	t.Run("should page the collections of a user by name", func(t *testing.T) {
+		// Arrange
+		repo, _ := newRepositories(t)
+		userId := uuid.New()
+		for _, name := range []string{"c", "a", "d", "b"} {
+			createCollection(t, repo, userId, name)
+		}
+		createCollection(t, repo, uuid.New(), "0")
+
+		// Act
+		firstPage, firstPagination, err := repo.GetByUserIdPaginated(userId, 3, 0)
+		secondPage, _, _ := repo.GetByUserIdPaginated(userId, 3, 1)
+		emptyPage, _, _ := repo.GetByUserIdPaginated(uuid.New(), 3, 0)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"a", "b", "c"}, collectionNames(firstPage))
+		assert.Equal(t, 1, firstPagination.MaxPage)
+		assert.Equal(t, []string{"d"}, collectionNames(secondPage))
+		assert.Empty(t, emptyPage)
+	})
+
+	t.Run("should rename a collection unless another collection of the user has the name", func(t *testing.T) {
+		// Arrange
+		repo, _ := newRepositories(t)
+		userId := uuid.New()
+		createCollection(t, repo, userId, "Taken")
+		c := createCollection(t, repo, userId, "Old name")
+
+		// Act
+		c.Name = "taken"
+		_, takenErr := repo.Update(c)
+		c.Name = "New name"
+		renamed, err := repo.Update(c)
+		stored, _ := repo.GetById(c.Id)
+
+		// Assert
+		assert.ErrorIs(t, takenErr, collection.ErrCollectionNameTaken)
+		assert.NoError(t, err)
+		assert.Equal(t, &c, renamed)
+		assert.Equal(t, &c, stored)
+	})
+
+	t.Run("should return collection not found when updating a missing collection", func(t *testing.T) {
+		// Arrange
+		repo, _ := newRepositories(t)
+
+		// Act
+		result, err := repo.Update(newCollection(uuid.New(), "missing"))
+
+		// Assert
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, collection.ErrCollectionNotFound)
+	})
+
+	t.Run("should page the favourites of a collection in the order they were added", func(t *testing.T) {
+		// Arrange
+		repo, favourites := newRepositories(t)
+		userId := uuid.New()
+		c := createCollection(t, repo, userId, "collection")
+		other := createCollection(t, repo, userId, "other")
+		favs := createFavourites(t, favourites, userId, 3)
+		added := []favourite.Favourite{favs[2], favs[0], favs[1]}
+		addFavourites(t, repo, c.Id, added)
+		addFavourites(t, repo, other.Id, favs[:1])
+
+		// Act
+		firstPage, pagination, err := repo.GetFavouriteIdsPaginated(c.Id, 2, 0)
+		secondPage, _, _ := repo.GetFavouriteIdsPaginated(c.Id, 2, 1)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, favouriteIds(added[:2]), firstPage)
+		assert.Equal(t, favouriteIds(added[2:]), secondPage)
+		assert.Equal(t, 1, pagination.MaxPage)
+	})
+
+	t.Run("should not add a favourite to a collection twice", func(t *testing.T) {
+		// Arrange
+		repo, favourites := newRepositories(t)
+		userId := uuid.New()
+		c := createCollection(t, repo, userId, "collection")
+		fav := createFavourites(t, favourites, userId, 1)[0]
+		addFavourites(t, repo, c.Id, []favourite.Favourite{fav})
+
+		// Act
+		err := repo.AddFavourite(c.Id, fav.Id, time.Now().UTC())
+		ids, _, _ := repo.GetFavouriteIdsPaginated(c.Id, 10, 0)
+
+		// Assert
+		assert.ErrorIs(t, err, collection.ErrFavouriteAlreadyInCollection)
+		assert.Equal(t, uuid.UUIDs{fav.Id}, ids)
+	})
+
+	t.Run("should not add to a missing collection or a missing favourite", func(t *testing.T) {
+		// Arrange
+		repo, favourites := newRepositories(t)
+		userId := uuid.New()
+		c := createCollection(t, repo, userId, "collection")
+		fav := createFavourites(t, favourites, userId, 1)[0]
+
+		// Act
+		missingCollectionErr := repo.AddFavourite(uuid.New(), fav.Id, time.Now().UTC())
+		missingFavouriteErr := repo.AddFavourite(c.Id, uuid.New(), time.Now().UTC())
+
+		// Assert
+		assert.ErrorIs(t, missingCollectionErr, collection.ErrCollectionNotFound)
+		assert.ErrorIs(t, missingFavouriteErr, favourite.ErrFavouriteNotFound)
+	})
+
+	t.Run("should remove a favourite from a collection once", func(t *testing.T) {
+		// Arrange
+		repo, favourites := newRepositories(t)
+		userId := uuid.New()
+		c := createCollection(t, repo, userId, "collection")
+		favs := createFavourites(t, favourites, userId, 2)
+		addFavourites(t, repo, c.Id, favs)
+
+		// Act
+		err := repo.RemoveFavourite(c.Id, favs[0].Id)
+		secondErr := repo.RemoveFavourite(c.Id, favs[0].Id)
+		ids, _, _ := repo.GetFavouriteIdsPaginated(c.Id, 10, 0)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.ErrorIs(t, secondErr, collection.ErrFavouriteNotInCollection)
+		assert.Equal(t, uuid.UUIDs{favs[1].Id}, ids)
+	})
+
+	t.Run("should delete a collection once and keep its favourites", func(t *testing.T) {
+		// Arrange
+		repo, favourites := newRepositories(t)
+		userId := uuid.New()
+		c := createCollection(t, repo, userId, "collection")
+		favs := createFavourites(t, favourites, userId, 2)
+		addFavourites(t, repo, c.Id, favs)
+
+		// Act
+		err := repo.Delete(c.Id)
+		secondErr := repo.Delete(c.Id)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.ErrorIs(t, secondErr, collection.ErrCollectionNotFound)
+		_, getErr := repo.GetById(c.Id)
+		assert.ErrorIs(t, getErr, database.ErrItemNotFound)
+		ids, _, _ := repo.GetFavouriteIdsPaginated(c.Id, 10, 0)
+		assert.Empty(t, ids)
+		stored, _ := favourites.GetByIds(favouriteIds(favs))
+		assert.Len(t, stored, 2)
+	})
+
+	t.Run("should take a deleted favourite out of every collection", func(t *testing.T) {
+		// Arrange
+		repo, favourites := newRepositories(t)
+		userId := uuid.New()
+		first := createCollection(t, repo, userId, "first")
+		second := createCollection(t, repo, userId, "second")
+		favs := createFavourites(t, favourites, userId, 3)
+		addFavourites(t, repo, first.Id, favs)
+		addFavourites(t, repo, second.Id, favs)
+
+		// Act
+		require.NoError(t, favourites.Delete(favs[0].Id, favs[0].Version, time.Now()))
+		_, err := favourites.WriteMany([]favourite.FavouriteWrite{
+			{Op: favourite.FavouriteWriteDelete, Favourite: favs[1]},
+		}, true)
+		require.NoError(t, err)
+
+		// Assert
+		for _, c := range []collection.Collection{first, second} {
+			ids, _, _ := repo.GetFavouriteIdsPaginated(c.Id, 10, 0)
+			assert.Equal(t, uuid.UUIDs{favs[2].Id}, ids)
+		}
+	})
+
+	t.Run("should add a favourite added concurrently only once", func(t *testing.T) {
+		// Arrange
+		repo, favourites := newRepositories(t)
+		userId := uuid.New()
+		c := createCollection(t, repo, userId, "collection")
+		fav := createFavourites(t, favourites, userId, 1)[0]
+		var wg sync.WaitGroup
+		errs := make(chan error, concurrentWorkers)
+
+		// Act
+		for range concurrentWorkers {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				errs <- repo.AddFavourite(c.Id, fav.Id, time.Now().UTC().Truncate(time.Microsecond))
+			}()
+		}
+		wg.Wait()
+		close(errs)
+
+		// Assert
+		succeeded := 0
+		for err := range errs {
+			if err == nil {
+				succeeded++
+				continue
+			}
+			assert.ErrorIs(t, err, collection.ErrFavouriteAlreadyInCollection)
+		}
+		ids, _, _ := repo.GetFavouriteIdsPaginated(c.Id, 10, 0)
+		assert.Equal(t, 1, succeeded)
+		assert.Equal(t, uuid.UUIDs{fav.Id}, ids)
+	})
+	t.Run("should leave no favourite in a collection deleted while favourites are added", func(t *testing.T) {
+		// Arrange
+		repo, favourites := newRepositories(t)
+		userId := uuid.New()
+		c := createCollection(t, repo, userId, "collection")
+		favs := createFavourites(t, favourites, userId, concurrentWorkers)
+		var wg sync.WaitGroup
+		errs := make(chan error, concurrentWorkers)
+
+		// Act
+		for _, fav := range favs {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				errs <- repo.AddFavourite(c.Id, fav.Id, time.Now().UTC().Truncate(time.Microsecond))
+			}()
+		}
+		deleteErr := repo.Delete(c.Id)
+		wg.Wait()
+		close(errs)
+
+		// Assert
+		assert.NoError(t, deleteErr)
+		for err := range errs {
+			if err != nil {
+				assert.ErrorIs(t, err, collection.ErrCollectionNotFound)
+			}
+		}
+		ids, _, _ := repo.GetFavouriteIdsPaginated(c.Id, concurrentWorkers, 0)
+		assert.Empty(t, ids)
+	})
+}
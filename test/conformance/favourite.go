@@ -0,0 +1,978 @@
+package conformance
+
+import (
+	"errors"
+	"platform-go-challenge/internal/database"
+	"platform-go-challenge/internal/domain/favourite"
+	"platform-go-challenge/internal/utils"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newFavourite(userId uuid.UUID) favourite.Favourite {
+	return favourite.Favourite{
+		Id:          uuid.New(),
+		UserId:      userId,
+		AssetId:     uuid.New(),
+		AssetType:   favourite.AssetTypeChart,
+		Description: "description",
+		Version:     1,
+		Tags:        []string{},
+	}
+}
+
+func createFavourites(t *testing.T, repo favourite.FavouriteRepository, userId uuid.UUID, count int) []favourite.Favourite {
+	t.Helper()
+
+	result := []favourite.Favourite{}
+	for range count {
+		created, err := repo.Create(newFavourite(userId))
+		require.NoError(t, err)
+		result = append(result, *created)
+	}
+
+	slices.SortFunc(result, func(a, b favourite.Favourite) int {
+		return slices.Compare(a.Id[:], b.Id[:])
+	})
+
+	return result
+}
+
+func favouriteIds(favourites []favourite.Favourite) uuid.UUIDs {
+	result := uuid.UUIDs{}
+	for _, fav := range favourites {
+		result = append(result, fav.Id)
+	}
+
+	return result
+}
+
+func trashedIds(trashed []favourite.TrashedFavourite) uuid.UUIDs {
+	result := uuid.UUIDs{}
+	for _, fav := range trashed {
+		result = append(result, fav.Id)
+	}
+
+	return result
+}
+
+func FavouriteRepository(t *testing.T, newRepository func(t *testing.T) favourite.FavouriteRepository) {
+	t.Run("should create favourite and return it by id", func(t *testing.T) {
+		// Arrange
+		repo := newRepository(t)
+		fav := newFavourite(uuid.New())
+
+		// Act
+		created, createErr := repo.Create(fav)
+		result, err := repo.GetById(fav.Id)
+
+		// Assert
+		assert.NoError(t, createErr)
+		assert.Equal(t, &fav, created)
+		assert.NoError(t, err)
+		assert.Equal(t, &fav, result)
+	})
+
+	t.Run("should return not found error when favourite does not exist", func(t *testing.T) {
+		// Arrange
+		repo := newRepository(t)
+
+		// Act
+		result, err := repo.GetById(uuid.New())
+
+		// Assert
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, database.ErrItemNotFound)
+	})
+
+	t.Run("should return the existing favourite when the user already has the asset", func(t *testing.T) {
+		// Arrange
+		repo := newRepository(t)
+		existing := createFavourites(t, repo, uuid.New(), 1)[0]
+		duplicate := newFavourite(existing.UserId)
+		duplicate.AssetId = existing.AssetId
+
+		// Act
+		result, err := repo.Create(duplicate)
+		_, getErr := repo.GetById(duplicate.Id)
+
+		// Assert
+		assert.ErrorIs(t, err, favourite.ErrFavouriteAlreadyExists)
+		assert.Equal(t, &existing, result)
+		assert.ErrorIs(t, getErr, database.ErrItemNotFound)
+	})
+
+	t.Run("should let different users favourite the same asset", func(t *testing.T) {
+		// Arrange
+		repo := newRepository(t)
+		existing := createFavourites(t, repo, uuid.New(), 1)[0]
+		other := newFavourite(uuid.New())
+		other.AssetId = existing.AssetId
+
+		// Act
+		result, err := repo.Create(other)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, &other, result)
+	})
+
+	t.Run("should update existing favourite", func(t *testing.T) {
+		// Arrange
+		repo := newRepository(t)
+		fav := createFavourites(t, repo, uuid.New(), 1)[0]
+		fav.Description = "updated"
+
+		// Act
+		updated, err := repo.Update(fav)
+		result, _ := repo.GetById(fav.Id)
+
+		// Assert
+		fav.Version = 2
+		assert.NoError(t, err)
+		assert.Equal(t, &fav, updated)
+		assert.Equal(t, &fav, result)
+	})
+
+	t.Run("should not update favourite that was changed since the given version", func(t *testing.T) {
+		// Arrange
+		repo := newRepository(t)
+		fav := createFavourites(t, repo, uuid.New(), 1)[0]
+		_, err := repo.Update(fav)
+		require.NoError(t, err)
+		stale := fav
+		stale.Description = "stale"
+
+		// Act
+		result, err := repo.Update(stale)
+		stored, _ := repo.GetById(fav.Id)
+
+		// Assert
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, favourite.ErrFavouriteVersionMismatch)
+		assert.Equal(t, "description", stored.Description)
+		assert.Equal(t, 2, stored.Version)
+	})
+
+	t.Run("should let exactly one of concurrent updates of the same version win", func(t *testing.T) {
+		// Arrange
+		repo := newRepository(t)
+		fav := createFavourites(t, repo, uuid.New(), 1)[0]
+
+		var wg sync.WaitGroup
+		errs := make(chan error, concurrentWorkers)
+
+		// Act
+		for range concurrentWorkers {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := repo.Update(fav)
+				errs <- err
+			}()
+		}
+		wg.Wait()
+		close(errs)
+
+		// Assert
+		succeeded := 0
+		for err := range errs {
+			if err == nil {
+				succeeded++
+				continue
+			}
+			assert.ErrorIs(t, err, favourite.ErrFavouriteVersionMismatch)
+		}
+		stored, _ := repo.GetById(fav.Id)
+		assert.Equal(t, 1, succeeded)
+		assert.Equal(t, fav.Version+1, stored.Version)
+	})
+
+	t.Run("should return favourite not found when updating a missing favourite", func(t *testing.T) {
+		// Arrange
+		repo := newRepository(t)
+
+		// Act
+		result, err := repo.Update(newFavourite(uuid.New()))
+
+		// Assert
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, favourite.ErrFavouriteNotFound)
+	})
+
+	t.Run("should delete favourite once", func(t *testing.T) {
+		// Arrange
+		repo := newRepository(t)
+		fav := createFavourites(t, repo, uuid.New(), 1)[0]
+
+		// Act
+		err := repo.Delete(fav.Id, fav.Version, time.Now())
+		againErr := repo.Delete(fav.Id, fav.Version, time.Now())
+		_, getErr := repo.GetById(fav.Id)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.ErrorIs(t, againErr, favourite.ErrFavouriteNotFound)
+		assert.ErrorIs(t, getErr, database.ErrItemNotFound)
+	})
+
+	t.Run("should not delete favourite that was changed since the given version", func(t *testing.T) {
+		// Arrange
+		repo := newRepository(t)
+		fav := createFavourites(t, repo, uuid.New(), 1)[0]
+		_, err := repo.Update(fav)
+		require.NoError(t, err)
+
+		// Act
+		err = repo.Delete(fav.Id, fav.Version, time.Now())
+		_, getErr := repo.GetById(fav.Id)
+
+		// Assert
+		assert.ErrorIs(t, err, favourite.ErrFavouriteVersionMismatch)
+		assert.NoError(t, getErr)
+	})
+
+	t.Run("should move a deleted favourite to the trash and restore it with the next version", func(t *testing.T) {
+		// Arrange
+		repo := newRepository(t)
+		fav := newFavourite(uuid.New())
+		fav.Tags = []string{"q2"}
+		_, err := repo.Create(fav)
+		require.NoError(t, err)
+		deletedAt := time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC)
+		restoredAt := deletedAt.Add(time.Hour)
+
+		// Act
+		deleteErr := repo.Delete(fav.Id, fav.Version, deletedAt)
+		trashed, trashedErr := repo.GetTrashedById(fav.Id)
+		restored, restoreErr := repo.Restore(fav.Id, restoredAt)
+		stored, getErr := repo.GetById(fav.Id)
+		_, trashedAfterErr := repo.GetTrashedById(fav.Id)
+		_, againErr := repo.Restore(fav.Id, restoredAt)
+
+		// Assert
+		assert.NoError(t, deleteErr)
+		assert.NoError(t, trashedErr)
+		assert.Equal(t, &favourite.TrashedFavourite{Favourite: fav, DeletedAt: deletedAt}, trashed)
+		assert.NoError(t, restoreErr)
+		expected := fav
+		expected.Version++
+		expected.UpdatedAt = restoredAt
+		assert.Equal(t, &expected, restored)
+		assert.NoError(t, getErr)
+		assert.Equal(t, &expected, stored)
+		assert.ErrorIs(t, trashedAfterErr, database.ErrItemNotFound)
+		assert.ErrorIs(t, againErr, favourite.ErrFavouriteNotFound)
+	})
+
+	t.Run("should not restore a trashed favourite whose asset is a favourite of the user again", func(t *testing.T) {
+		// Arrange
+		repo := newRepository(t)
+		fav := createFavourites(t, repo, uuid.New(), 1)[0]
+		require.NoError(t, repo.Delete(fav.Id, fav.Version, time.Now()))
+		again := newFavourite(fav.UserId)
+		again.AssetId = fav.AssetId
+		_, err := repo.Create(again)
+		require.NoError(t, err)
+
+		// Act
+		existing, restoreErr := repo.Restore(fav.Id, time.Now())
+		_, trashedErr := repo.GetTrashedById(fav.Id)
+
+		// Assert
+		assert.ErrorIs(t, restoreErr, favourite.ErrFavouriteAlreadyExists)
+		assert.Equal(t, &again, existing)
+		assert.NoError(t, trashedErr)
+	})
+
+	t.Run("should page the trash of a user latest deleted first and skip the favourites deleted before the given time", func(t *testing.T) {
+		// Arrange
+		repo := newRepository(t)
+		userId := uuid.New()
+		favourites := createFavourites(t, repo, userId, 4)
+		other := createFavourites(t, repo, uuid.New(), 1)[0]
+		start := time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC)
+		for i, fav := range append(favourites, other) {
+			require.NoError(t, repo.Delete(fav.Id, fav.Version, start.Add(time.Duration(i)*time.Hour)))
+		}
+
+		// Act
+		firstPage, pagination, err := repo.GetTrashedByUserIdPaginated(userId, start, 2, 0)
+		secondPage, _, secondErr := repo.GetTrashedByUserIdPaginated(userId, start, 2, 1)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.NoError(t, secondErr)
+		assert.Equal(t, utils.Pagination{Page: 0, PageSize: 2, MaxPage: 1}, pagination)
+		assert.Equal(t, uuid.UUIDs{favourites[3].Id, favourites[2].Id}, trashedIds(firstPage))
+		assert.Equal(t, uuid.UUIDs{favourites[1].Id}, trashedIds(secondPage))
+	})
+
+	t.Run("should purge a trashed favourite once", func(t *testing.T) {
+		// Arrange
+		repo := newRepository(t)
+		fav := createFavourites(t, repo, uuid.New(), 1)[0]
+		require.NoError(t, repo.Delete(fav.Id, fav.Version, time.Now()))
+
+		// Act
+		err := repo.Purge(fav.Id)
+		againErr := repo.Purge(fav.Id)
+		_, trashedErr := repo.GetTrashedById(fav.Id)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.ErrorIs(t, againErr, favourite.ErrFavouriteNotFound)
+		assert.ErrorIs(t, trashedErr, database.ErrItemNotFound)
+	})
+
+	t.Run("should purge every favourite deleted before the given time", func(t *testing.T) {
+		// Arrange
+		repo := newRepository(t)
+		favourites := append(createFavourites(t, repo, uuid.New(), 2), createFavourites(t, repo, uuid.New(), 2)...)
+		start := time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC)
+		for i, fav := range favourites {
+			require.NoError(t, repo.Delete(fav.Id, fav.Version, start.Add(time.Duration(i)*time.Hour)))
+		}
+
+		// Act
+		purged, err := repo.PurgeDeletedBefore(start.Add(2 * time.Hour))
+		_, purgedErr := repo.GetTrashedById(favourites[1].Id)
+		_, keptErr := repo.GetTrashedById(favourites[2].Id)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, 2, purged)
+		assert.ErrorIs(t, purgedErr, database.ErrItemNotFound)
+		assert.NoError(t, keptErr)
+	})
+
+	t.Run("should return favourites by ids in the given order skipping missing ones", func(t *testing.T) {
+		// Arrange
+		repo := newRepository(t)
+		favourites := createFavourites(t, repo, uuid.New(), 2)
+
+		// Act
+		result, err := repo.GetByIds(uuid.UUIDs{favourites[1].Id, uuid.New(), favourites[0].Id})
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, favouriteIds([]favourite.Favourite{favourites[1], favourites[0]}), favouriteIds(result))
+	})
+
+	t.Run("should apply every write of a batch that succeeds and report the others", func(t *testing.T) {
+		// Arrange
+		repo := newRepository(t)
+		userId := uuid.New()
+		existing := createFavourites(t, repo, userId, 3)
+		created := newFavourite(userId)
+		duplicate := newFavourite(userId)
+		duplicate.AssetId = existing[0].AssetId
+		updated := existing[1]
+		updated.Description = "updated"
+
+		// Act
+		results, err := repo.WriteMany([]favourite.FavouriteWrite{
+			{Op: favourite.FavouriteWriteCreate, Favourite: created},
+			{Op: favourite.FavouriteWriteCreate, Favourite: duplicate},
+			{Op: favourite.FavouriteWriteUpdate, Favourite: updated},
+			{Op: favourite.FavouriteWriteDelete, Favourite: favourite.Favourite{Id: existing[2].Id, Version: 7}},
+		}, false)
+
+		// Assert
+		require.NoError(t, err)
+		require.Len(t, results, 4)
+		assert.NoError(t, results[0].Err)
+		assert.Equal(t, created.Id, results[0].Favourite.Id)
+		assert.ErrorIs(t, results[1].Err, favourite.ErrFavouriteAlreadyExists)
+		assert.Equal(t, existing[0].Id, results[1].Favourite.Id)
+		assert.NoError(t, results[2].Err)
+		assert.Equal(t, 2, results[2].Favourite.Version)
+		assert.ErrorIs(t, results[3].Err, favourite.ErrFavouriteVersionMismatch)
+
+		stored, err := repo.GetByIds(uuid.UUIDs{created.Id, updated.Id, existing[2].Id})
+		assert.NoError(t, err)
+		assert.Len(t, stored, 3)
+	})
+
+	t.Run("should apply nothing of an atomic batch when a write fails", func(t *testing.T) {
+		// Arrange
+		repo := newRepository(t)
+		userId := uuid.New()
+		existing := createFavourites(t, repo, userId, 2)
+		created := newFavourite(userId)
+		updated := existing[0]
+		updated.Description = "updated"
+
+		// Act
+		results, err := repo.WriteMany([]favourite.FavouriteWrite{
+			{Op: favourite.FavouriteWriteCreate, Favourite: created},
+			{Op: favourite.FavouriteWriteUpdate, Favourite: updated},
+			{Op: favourite.FavouriteWriteDelete, Favourite: existing[1]},
+			{Op: favourite.FavouriteWriteDelete, Favourite: favourite.Favourite{Id: uuid.New(), Version: 1}},
+		}, true)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, []favourite.FavouriteWriteResult{
+			{Err: favourite.ErrFavouriteBatchRolledBack},
+			{Err: favourite.ErrFavouriteBatchRolledBack},
+			{Err: favourite.ErrFavouriteBatchRolledBack},
+			{Err: favourite.ErrFavouriteNotFound},
+		}, results)
+
+		_, getErr := repo.GetById(created.Id)
+		assert.ErrorIs(t, getErr, database.ErrItemNotFound)
+		stored, err := repo.GetByIds(favouriteIds(existing))
+		assert.NoError(t, err)
+		assert.Equal(t, existing, stored)
+	})
+
+	t.Run("should move the favourites deleted by a batch to the trash", func(t *testing.T) {
+		// Arrange
+		repo := newRepository(t)
+		existing := createFavourites(t, repo, uuid.New(), 2)
+		deletedAt := time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+		// Act
+		results, err := repo.WriteMany([]favourite.FavouriteWrite{
+			{Op: favourite.FavouriteWriteDelete, Favourite: existing[0], DeletedAt: deletedAt},
+		}, false)
+		atomicResults, atomicErr := repo.WriteMany([]favourite.FavouriteWrite{
+			{Op: favourite.FavouriteWriteDelete, Favourite: existing[1], DeletedAt: deletedAt},
+		}, true)
+		trashed, _, listErr := repo.GetTrashedByUserIdPaginated(existing[0].UserId, time.Time{}, 10, 0)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.NoError(t, atomicErr)
+		assert.False(t, favourite.HasFailedWrite(results))
+		assert.False(t, favourite.HasFailedWrite(atomicResults))
+		assert.NoError(t, listErr)
+		assert.ElementsMatch(t, []favourite.TrashedFavourite{
+			{Favourite: existing[0], DeletedAt: deletedAt},
+			{Favourite: existing[1], DeletedAt: deletedAt},
+		}, trashed)
+	})
+
+	t.Run("should let the writes of an atomic batch see the writes before them", func(t *testing.T) {
+		// Arrange
+		repo := newRepository(t)
+		userId := uuid.New()
+		old := createFavourites(t, repo, userId, 1)[0]
+		replacement := newFavourite(userId)
+		replacement.AssetId = old.AssetId
+
+		// Act
+		results, err := repo.WriteMany([]favourite.FavouriteWrite{
+			{Op: favourite.FavouriteWriteDelete, Favourite: old},
+			{Op: favourite.FavouriteWriteCreate, Favourite: replacement},
+		}, true)
+
+		// Assert
+		require.NoError(t, err)
+		assert.False(t, favourite.HasFailedWrite(results))
+		page, _, err := repo.GetByUserIdPaginated(userId, 10, 0, favourite.FavouriteListOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, uuid.UUIDs{replacement.Id}, favouriteIds(page))
+	})
+
+	t.Run("should page the favourites of a user created at the same time ordered by id", func(t *testing.T) {
+		// Arrange
+		repo := newRepository(t)
+		userId := uuid.New()
+		favourites := createFavourites(t, repo, userId, 5)
+		createFavourites(t, repo, uuid.New(), 3)
+
+		// Act
+		pages := []favourite.Favourite{}
+		paginations := []utils.Pagination{}
+		for page := range 3 {
+			result, pagination, err := repo.GetByUserIdPaginated(userId, 2, page, favourite.FavouriteListOptions{})
+			require.NoError(t, err)
+			pages = append(pages, result...)
+			paginations = append(paginations, pagination)
+		}
+
+		// Assert
+		assert.Equal(t, favourites, pages)
+		assert.Equal(t, []utils.Pagination{
+			{Page: 0, PageSize: 2, MaxPage: 2},
+			{Page: 1, PageSize: 2, MaxPage: 2},
+			{Page: 2, PageSize: 2, MaxPage: 2},
+		}, paginations)
+	})
+
+	t.Run("should list the favourites of a user in the requested order", func(t *testing.T) {
+		// Arrange
+		repo := newRepository(t)
+		userId := uuid.New()
+		day := func(d int) time.Time { return time.Date(2025, time.January, d, 12, 0, 0, 0, time.UTC) }
+
+		first := newFavourite(userId)
+		first.Description, first.AssetType, first.CreatedAt = "b", favourite.AssetTypeInsight, day(1)
+		second := newFavourite(userId)
+		second.Description, second.AssetType, second.CreatedAt = "c", favourite.AssetTypeAudience, day(2)
+		third := newFavourite(userId)
+		third.Description, third.AssetType, third.CreatedAt = "a", favourite.AssetTypeChart, day(3)
+		first.Rank, second.Rank, third.Rank, third.Pinned = "V", "a", "W", true
+		for _, fav := range []favourite.Favourite{second, third, first} {
+			_, err := repo.Create(fav)
+			require.NoError(t, err)
+		}
+
+		expected := map[favourite.FavouriteSort][]favourite.Favourite{
+			favourite.FavouriteSortRank:          {third, first, second},
+			favourite.FavouriteSortCreatedAt:     {first, second, third},
+			favourite.FavouriteSortCreatedAtDesc: {third, second, first},
+			favourite.FavouriteSortDescription:   {third, first, second},
+			favourite.FavouriteSortAssetType:     {second, third, first},
+		}
+
+		for sort, favourites := range expected {
+			// Act
+			result, _, err := repo.GetByUserIdPaginated(userId, 10, 0, favourite.FavouriteListOptions{Sort: sort})
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, favourites, result, sort)
+		}
+	})
+
+	t.Run("should walk every sort through cursors in both directions", func(t *testing.T) {
+		// Arrange
+		repo := newRepository(t)
+		userId := uuid.New()
+		day := func(d int) time.Time { return time.Date(2025, time.January, d, 12, 0, 0, 0, time.UTC) }
+		for i, description := range []string{"b", "a", "b", "c", "a"} {
+			fav := newFavourite(userId)
+			fav.Description = description
+			fav.AssetType = []favourite.AssetType{favourite.AssetTypeChart, favourite.AssetTypeInsight, favourite.AssetTypeAudience}[i%3]
+			fav.CreatedAt = day(i % 2)
+			fav.Rank = []string{"V", "V", "W", "X", "U"}[i]
+			fav.Pinned = i == 3
+			_, err := repo.Create(fav)
+			require.NoError(t, err)
+		}
+		createFavourites(t, repo, uuid.New(), 2)
+
+		for _, sort := range []favourite.FavouriteSort{
+			favourite.FavouriteSortRank,
+			favourite.FavouriteSortCreatedAt,
+			favourite.FavouriteSortCreatedAtDesc,
+			favourite.FavouriteSortDescription,
+			favourite.FavouriteSortAssetType,
+		} {
+			options := favourite.FavouriteListOptions{Sort: sort}
+			expected, _, err := repo.GetByUserIdPaginated(userId, 10, 0, options)
+			require.NoError(t, err)
+
+			// Act
+			forward := expected[:1]
+			for hasMore := true; hasMore; {
+				var page []favourite.Favourite
+				cursor := favourite.NewFavouriteCursor(sort, forward[len(forward)-1])
+				page, hasMore, err = repo.GetByUserIdKeyset(userId, 2, cursor, false, options)
+				require.NoError(t, err)
+				forward = append(slices.Clone(forward), page...)
+			}
+
+			backward := expected[len(expected)-1:]
+			for hasMore := true; hasMore; {
+				var page []favourite.Favourite
+				cursor := favourite.NewFavouriteCursor(sort, backward[0])
+				page, hasMore, err = repo.GetByUserIdKeyset(userId, 2, cursor, true, options)
+				require.NoError(t, err)
+				backward = append(slices.Clone(page), backward...)
+			}
+
+			// Assert
+			assert.Equal(t, expected, forward, sort)
+			assert.Equal(t, expected, backward, sort)
+		}
+	})
+
+	t.Run("should keep paging from the cursor of a deleted favourite", func(t *testing.T) {
+		// Arrange
+		repo := newRepository(t)
+		userId := uuid.New()
+		favourites := createFavourites(t, repo, userId, 4)
+		cursor := favourite.NewFavouriteCursor(favourite.FavouriteSortCreatedAt, favourites[1])
+		require.NoError(t, repo.Delete(favourites[1].Id, favourites[1].Version, time.Now()))
+
+		// Act
+		after, hasMoreAfter, err := repo.GetByUserIdKeyset(userId, 10, cursor, false, favourite.FavouriteListOptions{Sort: favourite.FavouriteSortCreatedAt})
+		before, hasMoreBefore, beforeErr := repo.GetByUserIdKeyset(userId, 10, cursor, true, favourite.FavouriteListOptions{Sort: favourite.FavouriteSortCreatedAt})
+
+		// Assert
+		assert.NoError(t, err)
+		assert.NoError(t, beforeErr)
+		assert.Equal(t, favourites[2:], after)
+		assert.False(t, hasMoreAfter)
+		assert.Equal(t, favourites[:1], before)
+		assert.False(t, hasMoreBefore)
+	})
+
+	t.Run("should return empty page after the last favourite", func(t *testing.T) {
+		// Arrange
+		repo := newRepository(t)
+		userId := uuid.New()
+		favourites := createFavourites(t, repo, userId, 2)
+		cursor := favourite.NewFavouriteCursor(favourite.FavouriteSortCreatedAt, favourites[1])
+
+		// Act
+		result, hasMore, err := repo.GetByUserIdKeyset(userId, 10, cursor, false, favourite.FavouriteListOptions{Sort: favourite.FavouriteSortCreatedAt})
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, []favourite.Favourite{}, result)
+		assert.False(t, hasMore)
+	})
+
+	t.Run("should page and seek only the favourites that match the filters", func(t *testing.T) {
+		// Arrange
+		repo := newRepository(t)
+		userId := uuid.New()
+		matching := []favourite.Favourite{}
+		for i, description := range []string{"Q2 sales", "q2 churn", "Q3 sales", "50% of q2", "Q2 reach"} {
+			fav := newFavourite(userId)
+			fav.Description = description
+			fav.AssetType = []favourite.AssetType{favourite.AssetTypeChart, favourite.AssetTypeInsight}[i%2]
+			fav.CreatedAt = time.Date(2025, time.January, i+1, 0, 0, 0, 0, time.UTC)
+			_, err := repo.Create(fav)
+			require.NoError(t, err)
+			if fav.AssetType == favourite.AssetTypeChart && description != "Q3 sales" {
+				matching = append(matching, fav)
+			}
+		}
+		createFavourites(t, repo, userId, 2)
+		options := favourite.FavouriteListOptions{
+			Sort:       favourite.FavouriteSortCreatedAt,
+			AssetTypes: []favourite.AssetType{favourite.AssetTypeChart},
+			Query:      "Q2",
+		}
+		wildcards := favourite.FavouriteListOptions{Query: "%"}
+
+		// Act
+		firstPage, pagination, err := repo.GetByUserIdPaginated(userId, 1, 0, options)
+		after, hasMoreAfter, afterErr := repo.GetByUserIdKeyset(userId, 1, favourite.NewFavouriteCursor(favourite.FavouriteSortCreatedAt, matching[0]), false, options)
+		before, hasMoreBefore, beforeErr := repo.GetByUserIdKeyset(userId, 5, favourite.NewFavouriteCursor(favourite.FavouriteSortCreatedAt, matching[1]), true, options)
+		percent, _, percentErr := repo.GetByUserIdPaginated(userId, 10, 0, wildcards)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.NoError(t, afterErr)
+		assert.NoError(t, beforeErr)
+		assert.NoError(t, percentErr)
+		assert.Equal(t, matching[:1], firstPage)
+		assert.Equal(t, utils.Pagination{Page: 0, PageSize: 1, MaxPage: 1}, pagination)
+		assert.Equal(t, matching[1:], after)
+		assert.False(t, hasMoreAfter)
+		assert.Equal(t, matching[:1], before)
+		assert.False(t, hasMoreBefore)
+		require.Len(t, percent, 1)
+		assert.Equal(t, "50% of q2", percent[0].Description)
+	})
+
+	t.Run("should page and seek only the favourites with all or any of the tags", func(t *testing.T) {
+		// Arrange
+		repo := newRepository(t)
+		userId := uuid.New()
+		favourites := []favourite.Favourite{}
+		for i, tags := range [][]string{{"q2", "sales"}, {"q2"}, {"churn", "q2", "sales"}, {"sales"}, {}} {
+			fav := newFavourite(userId)
+			fav.Tags = tags
+			fav.CreatedAt = time.Date(2025, time.January, i+1, 0, 0, 0, 0, time.UTC)
+			_, err := repo.Create(fav)
+			require.NoError(t, err)
+			favourites = append(favourites, fav)
+		}
+		other := newFavourite(uuid.New())
+		other.Tags = []string{"q2", "sales"}
+		_, err := repo.Create(other)
+		require.NoError(t, err)
+		all := favourite.FavouriteListOptions{Sort: favourite.FavouriteSortCreatedAt, Tags: []string{"q2", "sales"}, TagMatch: favourite.TagMatchAll}
+		anyOf := favourite.FavouriteListOptions{Sort: favourite.FavouriteSortCreatedAt, Tags: []string{"churn", "sales"}, TagMatch: favourite.TagMatchAny}
+
+		// Act
+		allPage, pagination, allErr := repo.GetByUserIdPaginated(userId, 10, 0, all)
+		anyPage, _, anyErr := repo.GetByUserIdPaginated(userId, 10, 0, anyOf)
+		after, hasMore, afterErr := repo.GetByUserIdKeyset(userId, 10, favourite.NewFavouriteCursor(favourite.FavouriteSortCreatedAt, favourites[0]), false, anyOf)
+
+		// Assert
+		assert.NoError(t, allErr)
+		assert.NoError(t, anyErr)
+		assert.NoError(t, afterErr)
+		assert.Equal(t, []favourite.Favourite{favourites[0], favourites[2]}, allPage)
+		assert.Equal(t, utils.Pagination{Page: 0, PageSize: 10, MaxPage: 0}, pagination)
+		assert.Equal(t, []favourite.Favourite{favourites[0], favourites[2], favourites[3]}, anyPage)
+		assert.Equal(t, []favourite.Favourite{favourites[2], favourites[3]}, after)
+		assert.False(t, hasMore)
+	})
+
+	t.Run("should count the tags of a user most used first", func(t *testing.T) {
+		// Arrange
+		repo := newRepository(t)
+		userId := uuid.New()
+		created := []*favourite.Favourite{}
+		for _, tags := range [][]string{{"q2", "sales"}, {"q2", "qa"}, {"churn", "q2"}, {"sales"}} {
+			fav := newFavourite(userId)
+			fav.Tags = tags
+			result, err := repo.Create(fav)
+			require.NoError(t, err)
+			created = append(created, result)
+		}
+		other := newFavourite(uuid.New())
+		other.Tags = []string{"qa"}
+		_, err := repo.Create(other)
+		require.NoError(t, err)
+
+		// Act
+		counts, countsErr := repo.GetTagCounts(userId, "", 10)
+		prefixed, prefixedErr := repo.GetTagCounts(userId, "q", 1)
+		changed := *created[1]
+		changed.Tags = []string{"sales"}
+		_, updateErr := repo.Update(changed)
+		deleteErr := repo.Delete(created[2].Id, created[2].Version, time.Now())
+		afterWrites, afterWritesErr := repo.GetTagCounts(userId, "", 10)
+
+		// Assert
+		assert.NoError(t, countsErr)
+		assert.NoError(t, prefixedErr)
+		assert.NoError(t, updateErr)
+		assert.NoError(t, deleteErr)
+		assert.NoError(t, afterWritesErr)
+		assert.Equal(t, []favourite.TagCount{{Tag: "q2", Count: 3}, {Tag: "sales", Count: 2}, {Tag: "churn", Count: 1}, {Tag: "qa", Count: 1}}, counts)
+		assert.Equal(t, []favourite.TagCount{{Tag: "q2", Count: 3}}, prefixed)
+		assert.Equal(t, []favourite.TagCount{{Tag: "sales", Count: 3}, {Tag: "q2", Count: 1}}, afterWrites)
+	})
+
+	t.Run("should keep the order of a sort after an update", func(t *testing.T) {
+		// Arrange
+		repo := newRepository(t)
+		userId := uuid.New()
+		favourites := createFavourites(t, repo, userId, 3)
+		last := favourites[2]
+		last.Description = "a"
+
+		// Act
+		updated, err := repo.Update(last)
+		result, _, listErr := repo.GetByUserIdPaginated(userId, 10, 0, favourite.FavouriteListOptions{Sort: favourite.FavouriteSortDescription})
+
+		// Assert
+		assert.NoError(t, err)
+		assert.NoError(t, listErr)
+		assert.Equal(t, []favourite.Favourite{*updated, favourites[0], favourites[1]}, result)
+	})
+
+	t.Run("should move a favourite in the rank order when its rank or pin changes", func(t *testing.T) {
+		// Arrange
+		repo := newRepository(t)
+		userId := uuid.New()
+		favourites := []favourite.Favourite{}
+		for _, rank := range []string{"V", "W", "X"} {
+			fav := newFavourite(userId)
+			fav.Rank = rank
+			created, err := repo.Create(fav)
+			require.NoError(t, err)
+			favourites = append(favourites, *created)
+		}
+		moved := favourites[2]
+		moved.Rank = "VV"
+		pinned := favourites[1]
+		pinned.Pinned = true
+
+		// Act
+		movedResult, movedErr := repo.Update(moved)
+		pinnedResult, pinnedErr := repo.Update(pinned)
+		result, _, err := repo.GetByUserIdPaginated(userId, 10, 0, favourite.FavouriteListOptions{Sort: favourite.FavouriteSortRank})
+
+		// Assert
+		require.NoError(t, movedErr)
+		require.NoError(t, pinnedErr)
+		assert.NoError(t, err)
+		assert.Equal(t, "VV", movedResult.Rank)
+		assert.True(t, pinnedResult.Pinned)
+		assert.Equal(t, []favourite.Favourite{*pinnedResult, favourites[0], *movedResult}, result)
+	})
+
+	t.Run("should return the rank of the favourite listed last by rank", func(t *testing.T) {
+		// Arrange
+		repo := newRepository(t)
+		userId := uuid.New()
+		for _, fav := range []struct {
+			rank   string
+			pinned bool
+		}{{"z", true}, {"X", false}, {"W", false}} {
+			created := newFavourite(userId)
+			created.Rank, created.Pinned = fav.rank, fav.pinned
+			_, err := repo.Create(created)
+			require.NoError(t, err)
+		}
+		createFavourites(t, repo, uuid.New(), 1)
+
+		// Act
+		rank, err := repo.GetLastRank(userId)
+		noRank, noRankErr := repo.GetLastRank(uuid.New())
+
+		// Assert
+		assert.NoError(t, err)
+		assert.NoError(t, noRankErr)
+		assert.Equal(t, "X", rank)
+		assert.Equal(t, "", noRank)
+	})
+
+	t.Run("should return every favourite in one page when page size is larger than the total", func(t *testing.T) {
+		// Arrange
+		repo := newRepository(t)
+		userId := uuid.New()
+		favourites := createFavourites(t, repo, userId, 3)
+
+		// Act
+		result, pagination, err := repo.GetByUserIdPaginated(userId, 10, 0, favourite.FavouriteListOptions{})
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, favourites, result)
+		assert.Equal(t, utils.Pagination{Page: 0, PageSize: 10, MaxPage: 0}, pagination)
+	})
+
+	t.Run("should return empty page when page is past the end", func(t *testing.T) {
+		// Arrange
+		repo := newRepository(t)
+		userId := uuid.New()
+		createFavourites(t, repo, userId, 4)
+
+		// Act
+		result, pagination, err := repo.GetByUserIdPaginated(userId, 2, 5, favourite.FavouriteListOptions{})
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, []favourite.Favourite{}, result)
+		assert.Equal(t, utils.Pagination{Page: 5, PageSize: 2, MaxPage: 1}, pagination)
+	})
+
+	t.Run("should return empty page when user has no favourites", func(t *testing.T) {
+		// Arrange
+		repo := newRepository(t)
+		createFavourites(t, repo, uuid.New(), 2)
+
+		// Act
+		result, pagination, err := repo.GetByUserIdPaginated(uuid.New(), 10, 0, favourite.FavouriteListOptions{})
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, []favourite.Favourite{}, result)
+		assert.Equal(t, utils.Pagination{Page: 0, PageSize: 10, MaxPage: 0}, pagination)
+	})
+
+	t.Run("should keep every concurrent write", func(t *testing.T) {
+		// Arrange
+		repo := newRepository(t)
+		userId := uuid.New()
+
+		var wg sync.WaitGroup
+		kept := make(chan favourite.Favourite, concurrentWorkers*concurrentIterations)
+		errs := make(chan error, concurrentWorkers*concurrentIterations*3)
+
+		// Act
+		for range concurrentWorkers {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := range concurrentIterations {
+					fav, err := repo.Create(newFavourite(userId))
+					if err != nil {
+						errs <- err
+						continue
+					}
+
+					fav.Description = "updated"
+					fav, err = repo.Update(*fav)
+					if err != nil {
+						errs <- err
+						continue
+					}
+
+					if i%2 == 0 {
+						if err := repo.Delete(fav.Id, fav.Version, time.Now()); err != nil {
+							errs <- err
+						}
+						continue
+					}
+
+					kept <- *fav
+				}
+			}()
+		}
+		wg.Wait()
+		close(kept)
+		close(errs)
+
+		// Assert
+		for err := range errs {
+			assert.NoError(t, err)
+		}
+
+		expected := uuid.UUIDs{}
+		for fav := range kept {
+			expected = append(expected, fav.Id)
+		}
+
+		result, _, err := repo.GetByUserIdPaginated(userId, len(expected)+1, 0, favourite.FavouriteListOptions{})
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, expected, favouriteIds(result))
+		for _, fav := range result {
+			assert.Equal(t, "updated", fav.Description)
+		}
+	})
+
+	t.Run("should keep a single favourite when the same asset is created concurrently", func(t *testing.T) {
+		// Arrange
+		repo := newRepository(t)
+		userId := uuid.New()
+		assetId := uuid.New()
+
+		var wg sync.WaitGroup
+		results := make(chan *favourite.Favourite, concurrentWorkers)
+		errs := make(chan error, concurrentWorkers)
+
+		// Act
+		for range concurrentWorkers {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				fav := newFavourite(userId)
+				fav.AssetId = assetId
+
+				result, err := repo.Create(fav)
+				if err != nil && !errors.Is(err, favourite.ErrFavouriteAlreadyExists) {
+					errs <- err
+					return
+				}
+
+				results <- result
+			}()
+		}
+		wg.Wait()
+		close(results)
+		close(errs)
+
+		// Assert
+		for err := range errs {
+			assert.NoError(t, err)
+		}
+
+		stored, _, err := repo.GetByUserIdPaginated(userId, 10, 0, favourite.FavouriteListOptions{})
+		assert.NoError(t, err)
+		assert.Len(t, stored, 1)
+		for result := range results {
+			assert.Equal(t, stored[0], *result)
+		}
+	})
+}
@@ -0,0 +1,69 @@
+package conformance
+
+import (
+	"platform-go-challenge/internal/domain/user"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func UserRepository(t *testing.T, newRepository func(t *testing.T, users []user.User) user.UserRepository) {
+	users := []user.User{
+		{Id: uuid.New(), Email: "first@test.com", Password: "first-hash"},
+		{Id: uuid.New(), Email: "second@test.com", Password: "second-hash", IsAdmin: true},
+	}
+
+	t.Run("should return user by email", func(t *testing.T) {
+		// Arrange
+		repo := newRepository(t, users)
+
+		// Act
+		result, err := repo.GetByEmail(users[1].Email)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, &users[1], result)
+	})
+
+	t.Run("should return user not found when email does not exist", func(t *testing.T) {
+		// Arrange
+		repo := newRepository(t, users)
+
+		// Act
+		result, err := repo.GetByEmail("missing@test.com")
+
+		// Assert
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, user.ErrUserNotFound)
+	})
+
+	t.Run("should serve concurrent reads", func(t *testing.T) {
+		// Arrange
+		repo := newRepository(t, users)
+
+		var wg sync.WaitGroup
+		errs := make(chan error, concurrentWorkers*concurrentIterations)
+
+		// Act
+		for w := range concurrentWorkers {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for range concurrentIterations {
+					if _, err := repo.GetByEmail(users[w%len(users)].Email); err != nil {
+						errs <- err
+					}
+				}
+			}()
+		}
+		wg.Wait()
+		close(errs)
+
+		// Assert
+		for err := range errs {
+			assert.NoError(t, err)
+		}
+	})
+}
@@ -0,0 +1,261 @@
+package e2e
+
+import (
+	"encoding/json"
+	"net/http"
+	"platform-go-challenge/test"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type adminSender func(token string, method string, path string, body string, result any) int
+
+// startAdminServer starts the API with the dev fixtures and returns a sender of requests
+// together with the token of the admin and the token of the regular user.
+func startAdminServer(t *testing.T) (adminSender, string, string) {
+	server, userToken := test.StartServer()
+	t.Cleanup(server.Close)
+
+	client := server.Client()
+	send := func(token string, method string, path string, body string, result any) int {
+		req, _ := http.NewRequest(method, server.URL+path, strings.NewReader(body))
+		if token != "" {
+			req.Header.Add("Authorization", "bearer "+token)
+		}
+
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		if result != nil {
+			assert.NoError(t, json.NewDecoder(resp.Body).Decode(result))
+		}
+
+		return resp.StatusCode
+	}
+
+	var login struct {
+		Data struct {
+			Token string `json:"token"`
+		} `json:"data"`
+	}
+	require.Equal(t, http.StatusOK, send("", http.MethodPost, "/v1/user/login", `{"email":"admin@test.com","password":"pass"}`, &login))
+
+	return send, login.Data.Token, userToken
+}
+
+func TestAdminCharts(t *testing.T) {
+	// Arrange
+	send, adminToken, userToken := startAdminServer(t)
+
+	type chartResponse struct {
+		Data struct {
+			Id            string `json:"id"`
+			SchemaVersion int    `json:"schema_version"`
+			Title         string `json:"title"`
+			Series        []struct {
+				Name   string `json:"name"`
+				Points []struct {
+					X any     `json:"x"`
+					Y float64 `json:"y"`
+				} `json:"points"`
+			} `json:"series"`
+		} `json:"data"`
+	}
+
+	// Act
+	var created chartResponse
+	createStatus := send(
+		adminToken,
+		http.MethodPost,
+		"/v1/admin/charts",
+		`{"title":" Revenue ","kind":"bar","x_axis":{"title":"Month","type":"time"},"y_axis":{"title":"Revenue","type":"numeric","unit":"EUR"},`+
+			`"series":[{"name":"2024","points":[{"x":"2024-01-01T00:00:00Z","y":1200}]}]}`,
+		&created,
+	)
+	invalidStatus := send(
+		adminToken,
+		http.MethodPost,
+		"/v1/admin/charts",
+		`{"title":"Revenue","kind":"bar","x_axis":{"title":"Month","type":"time"},"y_axis":{"title":"Revenue","type":"numeric"},`+
+			`"series":[{"name":"2024","points":[{"x":1,"y":1200}]}]}`,
+		nil,
+	)
+	chartPath := "/v1/admin/charts/" + created.Data.Id
+
+	var updated chartResponse
+	updateStatus := send(adminToken, http.MethodPatch, chartPath, `{"title":"Monthly revenue","series":null}`, &updated)
+
+	var listed struct {
+		Data []struct {
+			Title string `json:"title"`
+		} `json:"data"`
+	}
+	listStatus := send(adminToken, http.MethodGet, "/v1/admin/charts", "", &listed)
+
+	type favouritesPage struct {
+		Data map[string][]struct {
+			Id string `json:"id"`
+		} `json:"data"`
+	}
+	favouritedDeleteStatus := send(adminToken, http.MethodDelete, "/v1/admin/charts/11111111-1111-1111-1111-111111111111", "", nil)
+	var favouritedPage favouritesPage
+	send(userToken, http.MethodGet, "/v1/user/favourites?pageSize=2", "", &favouritedPage)
+
+	deleteFavouriteStatus := send(userToken, http.MethodDelete, "/v1/user/favourites/44444444-4444-4444-4444-444444444444", "", nil)
+	deleteStatus := send(adminToken, http.MethodDelete, "/v1/admin/charts/11111111-1111-1111-1111-111111111111", "", nil)
+	getDeletedStatus := send(adminToken, http.MethodGet, "/v1/admin/charts/11111111-1111-1111-1111-111111111111", "", nil)
+	var deletedPage favouritesPage
+	send(userToken, http.MethodGet, "/v1/user/favourites?pageSize=2", "", &deletedPage)
+	restoreStatus := send(userToken, http.MethodPost, "/v1/user/favourites/trash/44444444-4444-4444-4444-444444444444/restore", "", nil)
+
+	userStatus := send(userToken, http.MethodGet, "/v1/admin/charts", "", nil)
+	anonymousStatus := send("", http.MethodGet, "/v1/admin/charts", "", nil)
+
+	// Assert
+	assert.Equal(t, http.StatusCreated, createStatus)
+	assert.Equal(t, "Revenue", created.Data.Title)
+	assert.Equal(t, 2, created.Data.SchemaVersion)
+	require.Len(t, created.Data.Series, 1)
+	require.Len(t, created.Data.Series[0].Points, 1)
+	assert.Equal(t, "2024-01-01T00:00:00Z", created.Data.Series[0].Points[0].X)
+	assert.Equal(t, http.StatusBadRequest, invalidStatus)
+	assert.Equal(t, http.StatusOK, updateStatus)
+	assert.Equal(t, "Monthly revenue", updated.Data.Title)
+	assert.NotNil(t, updated.Data.Series)
+	assert.Empty(t, updated.Data.Series)
+	assert.Equal(t, http.StatusOK, listStatus)
+	require.Len(t, listed.Data, 2)
+	assert.Equal(t, "Monthly revenue", listed.Data[0].Title)
+	assert.Equal(t, "test chart", listed.Data[1].Title)
+	assert.Equal(t, http.StatusConflict, favouritedDeleteStatus)
+	assert.Len(t, favouritedPage.Data["charts"], 1)
+	assert.Len(t, favouritedPage.Data["insights"], 1)
+	assert.Equal(t, http.StatusOK, deleteFavouriteStatus)
+	assert.Equal(t, http.StatusOK, deleteStatus)
+	assert.Equal(t, http.StatusNotFound, getDeletedStatus)
+	assert.Empty(t, deletedPage.Data["charts"])
+	assert.Len(t, deletedPage.Data["insights"], 1)
+	assert.Len(t, deletedPage.Data["audiences"], 1)
+	assert.Equal(t, http.StatusNotFound, restoreStatus)
+	assert.Equal(t, http.StatusForbidden, userStatus)
+	assert.Equal(t, http.StatusUnauthorized, anonymousStatus)
+}
+
+func TestAdminInsights(t *testing.T) {
+	// Arrange
+	send, adminToken, userToken := startAdminServer(t)
+
+	type insightResponse struct {
+		Data map[string]any `json:"data"`
+	}
+
+	// Act
+	var created insightResponse
+	createStatus := send(adminToken, http.MethodPost, "/v1/admin/insights", `{"text":" Most users log in on mobile "}`, &created)
+	var duplicate insightResponse
+	duplicateStatus := send(adminToken, http.MethodPost, "/v1/admin/insights", `{"text":"MOST users log in on mobile"}`, &duplicate)
+	insightPath := "/v1/admin/insights/" + created.Data["id"].(string)
+
+	takenStatus := send(
+		adminToken,
+		http.MethodPatch,
+		insightPath,
+		`{"text":"100% of zoomers spend more than 8 hours on watching memes"}`,
+		nil,
+	)
+	var updated insightResponse
+	updateStatus := send(adminToken, http.MethodPatch, insightPath, `{"text":"Most users log in on their phone"}`, &updated)
+
+	favouritedDeleteStatus := send(adminToken, http.MethodDelete, "/v1/admin/insights/22222222-2222-2222-2222-222222222222", "", nil)
+	deleteFavouriteStatus := send(userToken, http.MethodDelete, "/v1/user/favourites/55555555-5555-5555-5555-555555555555", "", nil)
+	deleteStatus := send(adminToken, http.MethodDelete, "/v1/admin/insights/22222222-2222-2222-2222-222222222222", "", nil)
+	userStatus := send(userToken, http.MethodPost, "/v1/admin/insights", `{"text":"Not an admin"}`, nil)
+
+	// Assert
+	assert.Equal(t, http.StatusCreated, createStatus)
+	assert.Equal(t, map[string]any{"id": created.Data["id"], "text": "Most users log in on mobile"}, created.Data)
+	assert.Equal(t, http.StatusConflict, duplicateStatus)
+	assert.Equal(t, created.Data, duplicate.Data)
+	assert.Equal(t, http.StatusConflict, takenStatus)
+	assert.Equal(t, http.StatusOK, updateStatus)
+	assert.Equal(t, "Most users log in on their phone", updated.Data["text"])
+	assert.Equal(t, http.StatusConflict, favouritedDeleteStatus)
+	assert.Equal(t, http.StatusOK, deleteFavouriteStatus)
+	assert.Equal(t, http.StatusOK, deleteStatus)
+	assert.Equal(t, http.StatusForbidden, userStatus)
+}
+
+func TestAdminAudiences(t *testing.T) {
+	// Arrange
+	send, adminToken, userToken := startAdminServer(t)
+
+	type audienceResponse struct {
+		Data map[string]any `json:"data"`
+	}
+
+	// Act
+	var created audienceResponse
+	createStatus := send(
+		adminToken,
+		http.MethodPost,
+		"/v1/admin/audiences",
+		`{"gender":"Non-binary","birth_country":"gr","age_group":"18-24","social_media_hours":0,"purchases_last_month":2}`,
+		&created,
+	)
+	var rejected struct {
+		Data map[string]string `json:"data"`
+	}
+	invalidStatus := send(
+		adminToken,
+		http.MethodPost,
+		"/v1/admin/audiences",
+		`{"gender":"Non-binary","birth_country":"Greece","age_group":"24-18","social_media_hours":0,"purchases_last_month":-2}`,
+		&rejected,
+	)
+
+	var updated audienceResponse
+	updateStatus := send(
+		adminToken,
+		http.MethodPatch,
+		"/v1/admin/audiences/33333333-3333-3333-3333-333333333333",
+		`{"age_group":"35-44"}`,
+		&updated,
+	)
+
+	var listed struct {
+		Data []struct {
+			BirthCountry string `json:"birth_country"`
+		} `json:"data"`
+	}
+	listStatus := send(adminToken, http.MethodGet, "/v1/admin/audiences", "", &listed)
+
+	favouritedDeleteStatus := send(adminToken, http.MethodDelete, "/v1/admin/audiences/33333333-3333-3333-3333-333333333333", "", nil)
+	deleteFavouriteStatus := send(userToken, http.MethodDelete, "/v1/user/favourites/66666666-6666-6666-6666-666666666666", "", nil)
+	deleteStatus := send(adminToken, http.MethodDelete, "/v1/admin/audiences/33333333-3333-3333-3333-333333333333", "", nil)
+	userStatus := send(userToken, http.MethodGet, "/v1/admin/audiences", "", nil)
+
+	// Assert
+	assert.Equal(t, http.StatusCreated, createStatus)
+	assert.Equal(t, "GR", created.Data["birth_country"])
+	assert.Equal(t, http.StatusBadRequest, invalidStatus)
+	assert.Equal(t, map[string]string{
+		"birth_country":        "must be an ISO 3166-1 alpha-2 country code like GB",
+		"age_group":            "must be an age range like 25-34, or like 65+ for no upper bound",
+		"purchases_last_month": "must be at least 0",
+	}, rejected.Data)
+	assert.Equal(t, http.StatusOK, updateStatus)
+	assert.Equal(t, "35-44", updated.Data["age_group"])
+	assert.Equal(t, "GB", updated.Data["birth_country"])
+	assert.Equal(t, http.StatusOK, listStatus)
+	require.Len(t, listed.Data, 2)
+	assert.Equal(t, "GB", listed.Data[0].BirthCountry)
+	assert.Equal(t, "GR", listed.Data[1].BirthCountry)
+	assert.Equal(t, http.StatusConflict, favouritedDeleteStatus)
+	assert.Equal(t, http.StatusOK, deleteFavouriteStatus)
+	assert.Equal(t, http.StatusOK, deleteStatus)
+	assert.Equal(t, http.StatusForbidden, userStatus)
+}
@@ -0,0 +1,88 @@
+package e2e
+
+import (
+	"encoding/json"
+	"net/http"
+	"platform-go-challenge/test"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollections(t *testing.T) {
+	// Arrange
+	server, token := test.StartServer()
+	defer server.Close()
+
+	client := server.Client()
+	send := func(method string, path string, body string, result any) int {
+		req, _ := http.NewRequest(method, server.URL+"/v1/user"+path, strings.NewReader(body))
+		req.Header.Add("Authorization", "bearer "+token)
+
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		if result != nil {
+			assert.NoError(t, json.NewDecoder(resp.Body).Decode(result))
+		}
+
+		return resp.StatusCode
+	}
+	listFavourites := func(collectionId string) []string {
+		var result struct {
+			Data []struct {
+				Id   string `json:"id"`
+				Type string `json:"type"`
+			} `json:"data"`
+		}
+		send(http.MethodGet, "/collections/"+collectionId+"/favourites", "", &result)
+
+		items := []string{}
+		for _, item := range result.Data {
+			items = append(items, item.Type+" "+item.Id)
+		}
+		return items
+	}
+
+	// Act
+	var created struct {
+		Data struct {
+			Id   string `json:"id"`
+			Name string `json:"name"`
+		} `json:"data"`
+	}
+	createStatus := send(http.MethodPost, "/collections", `{"name":"Q2 campaign","description":"Slides"}`, &created)
+	duplicateStatus := send(http.MethodPost, "/collections", `{"name":"q2 CAMPAIGN"}`, nil)
+	collectionPath := "/collections/" + created.Data.Id
+
+	addStatus := send(http.MethodPost, collectionPath+"/favourites", `{"favouriteId":"66666666-6666-6666-6666-666666666666"}`, nil)
+	send(http.MethodPost, collectionPath+"/favourites", `{"favouriteId":"44444444-4444-4444-4444-444444444444"}`, nil)
+	addAgainStatus := send(http.MethodPost, collectionPath+"/favourites", `{"favouriteId":"66666666-6666-6666-6666-666666666666"}`, nil)
+	added := listFavourites(created.Data.Id)
+
+	deleteFavouriteStatus := send(http.MethodDelete, "/favourites/44444444-4444-4444-4444-444444444444", "", nil)
+	afterFavouriteDeleted := listFavourites(created.Data.Id)
+
+	deleteCollectionStatus := send(http.MethodDelete, collectionPath, "", nil)
+	getDeletedStatus := send(http.MethodGet, collectionPath, "", nil)
+	getKeptFavouriteStatus := send(http.MethodGet, "/favourites/66666666-6666-6666-6666-666666666666", "", nil)
+
+	// Assert
+	assert.Equal(t, http.StatusCreated, createStatus)
+	assert.Equal(t, "Q2 campaign", created.Data.Name)
+	assert.Equal(t, http.StatusConflict, duplicateStatus)
+	assert.Equal(t, http.StatusCreated, addStatus)
+	assert.Equal(t, http.StatusConflict, addAgainStatus)
+	assert.Equal(t, []string{
+		"audience 66666666-6666-6666-6666-666666666666",
+		"chart 44444444-4444-4444-4444-444444444444",
+	}, added)
+	assert.Equal(t, http.StatusOK, deleteFavouriteStatus)
+	assert.Equal(t, []string{"audience 66666666-6666-6666-6666-666666666666"}, afterFavouriteDeleted)
+	assert.Equal(t, http.StatusOK, deleteCollectionStatus)
+	assert.Equal(t, http.StatusNotFound, getDeletedStatus)
+	assert.Equal(t, http.StatusOK, getKeptFavouriteStatus)
+}
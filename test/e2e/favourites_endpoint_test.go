@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"platform-go-challenge/test"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -26,15 +27,27 @@ func TestGetFavourites(t *testing.T) {
 				map[string]any{
 					"id":          "44444444-4444-4444-4444-444444444444",
 					"description": "Main performance chart",
+					"created_at":  "2025-01-10T09:00:00Z",
+					"updated_at":  "2025-01-10T09:00:00Z",
+					"rank":        "V",
+					"pinned":      false,
+					"tags":        []any{},
 					"info": map[string]any{
-						"id":           "11111111-1111-1111-1111-111111111111",
-						"title":        "test chart",
-						"x_axis_title": "commit number",
-						"y_axis_title": "lines of code",
-						"data": []any{
-							map[string]any{"x": float64(1), "y": float64(100)},
-							map[string]any{"x": float64(2), "y": float64(300)},
-							map[string]any{"x": float64(3), "y": float64(500)},
+						"id":             "11111111-1111-1111-1111-111111111111",
+						"schema_version": float64(2),
+						"title":          "test chart",
+						"kind":           "line",
+						"x_axis":         map[string]any{"title": "commit number", "type": "numeric", "unit": ""},
+						"y_axis":         map[string]any{"title": "lines of code", "type": "numeric", "unit": ""},
+						"series": []any{
+							map[string]any{
+								"name": "lines of code",
+								"points": []any{
+									map[string]any{"x": float64(1), "y": float64(100)},
+									map[string]any{"x": float64(2), "y": float64(300)},
+									map[string]any{"x": float64(3), "y": float64(500)},
+								},
+							},
 						},
 					},
 				},
@@ -43,9 +56,14 @@ func TestGetFavourites(t *testing.T) {
 				map[string]any{
 					"id":          "55555555-5555-5555-5555-555555555555",
 					"description": "Great for Q2 presentation",
+					"created_at":  "2025-01-11T09:00:00Z",
+					"updated_at":  "2025-01-11T09:00:00Z",
+					"rank":        "W",
+					"pinned":      false,
+					"tags":        []any{},
 					"info": map[string]any{
-						"Id":   "22222222-2222-2222-2222-222222222222",
-						"Text": "40% of millennials spend more than 3 hours on social media daily",
+						"id":   "22222222-2222-2222-2222-222222222222",
+						"text": "40% of millennials spend more than 3 hours on social media daily",
 					},
 				},
 			},
@@ -53,10 +71,15 @@ func TestGetFavourites(t *testing.T) {
 				map[string]any{
 					"id":          "66666666-6666-6666-6666-666666666666",
 					"description": "Target audience for campaign",
+					"created_at":  "2025-01-12T09:00:00Z",
+					"updated_at":  "2025-01-12T09:00:00Z",
+					"rank":        "X",
+					"pinned":      false,
+					"tags":        []any{},
 					"info": map[string]any{
 						"id":                   "33333333-3333-3333-3333-333333333333",
 						"gender":               "Male",
-						"birth_country":        "United Kingdom",
+						"birth_country":        "GB",
 						"age_group":            "25-34",
 						"social_media_hours":   float64(3.5),
 						"purchases_last_month": float64(7),
@@ -124,6 +147,83 @@ func TestCreateFavourite(t *testing.T) {
 	assert.Equal(t, data["description"], expected["description"])
 }
 
+func TestCreateFavouriteConflict(t *testing.T) {
+	// Arrange
+	server, token := test.StartServer()
+	defer server.Close()
+
+	// The dev user already has this insight as favourite 55555555-...
+	requestBody := map[string]interface{}{
+		"assetId":     "22222222-2222-2222-2222-222222222222",
+		"description": "Again",
+	}
+
+	client := server.Client()
+
+	bodyBytes, _ := json.Marshal(requestBody)
+	req, err := http.NewRequest(http.MethodPost, server.URL+"/v1/user/favourites", bytes.NewReader(bodyBytes))
+	req.Header.Add("Authorization", "bearer "+token)
+
+	// Act
+	resp, err := client.Do(req)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	// Assert
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+	var result map[string]any
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	assert.NoError(t, err)
+
+	data := result["data"].(map[string]any)
+	assert.Equal(t, "55555555-5555-5555-5555-555555555555", data["id"])
+	assert.Equal(t, "Great for Q2 presentation", data["description"])
+	assert.NotEmpty(t, result["error"])
+}
+
+func TestCreateFavouriteWithIdempotencyKey(t *testing.T) {
+	// Arrange
+	server, token := test.StartServer()
+	defer server.Close()
+
+	client := server.Client()
+
+	create := func(body map[string]any) (*http.Response, map[string]any) {
+		bodyBytes, _ := json.Marshal(body)
+		req, _ := http.NewRequest(http.MethodPost, server.URL+"/v1/user/favourites", bytes.NewReader(bodyBytes))
+		req.Header.Add("Authorization", "bearer "+token)
+		req.Header.Add("Idempotency-Key", "retry-1")
+
+		resp, err := client.Do(req)
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+
+		var result map[string]any
+		json.NewDecoder(resp.Body).Decode(&result)
+
+		return resp, result
+	}
+
+	requestBody := map[string]any{
+		"assetId":     "22222222-2222-2222-2222-222222222223",
+		"description": "Good to know",
+	}
+
+	// Act
+	firstResp, first := create(requestBody)
+	retryResp, retry := create(requestBody)
+	otherBodyResp, _ := create(map[string]any{"assetId": "11111111-1111-1111-1111-111111111111"})
+
+	// Assert
+	assert.Equal(t, http.StatusCreated, firstResp.StatusCode)
+	assert.Equal(t, http.StatusCreated, retryResp.StatusCode)
+	assert.Equal(t, "true", retryResp.Header.Get("Idempotent-Replayed"))
+	assert.NotEmpty(t, firstResp.Header.Get("ETag"))
+	assert.Equal(t, firstResp.Header.Get("ETag"), retryResp.Header.Get("ETag"))
+	assert.Equal(t, first, retry)
+	assert.Equal(t, http.StatusUnprocessableEntity, otherBodyResp.StatusCode)
+}
+
 func TestUpdateFavourite(t *testing.T) {
 	// Arrange
 	server, token := test.StartServer()
@@ -165,6 +265,45 @@ func TestUpdateFavourite(t *testing.T) {
 	assert.Equal(t, data["description"], expected["description"])
 }
 
+func TestUpdateFavouriteWithPatches(t *testing.T) {
+	// Arrange
+	server, token := test.StartServer()
+	defer server.Close()
+
+	client := server.Client()
+	patch := func(contentType string, body string) (int, map[string]any) {
+		req, _ := http.NewRequest(http.MethodPatch, server.URL+"/v1/user/favourites/55555555-5555-5555-5555-555555555555", strings.NewReader(body))
+		req.Header.Add("Authorization", "bearer "+token)
+		req.Header.Set("Content-Type", contentType)
+
+		resp, err := client.Do(req)
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+
+		var result map[string]any
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+		return resp.StatusCode, result
+	}
+
+	// Act
+	tagStatus, _ := patch("application/merge-patch+json", `{"tags":["q2"]}`)
+	clearStatus, cleared := patch("application/merge-patch+json", `{"description":null}`)
+	jsonPatchStatus, patched := patch("application/json-patch+json", `[{"op":"replace","path":"/description","value":"Back again"},{"op":"remove","path":"/tags"}]`)
+	unknownStatus, _ := patch("application/merge-patch+json", `{"asset_id":"22222222-2222-2222-2222-222222222222"}`)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, tagStatus)
+	assert.Equal(t, http.StatusOK, clearStatus)
+	clearedData := cleared["data"].(map[string]any)
+	assert.Equal(t, "", clearedData["description"])
+	assert.Equal(t, []any{"q2"}, clearedData["tags"])
+	assert.Equal(t, http.StatusOK, jsonPatchStatus)
+	patchedData := patched["data"].(map[string]any)
+	assert.Equal(t, "Back again", patchedData["description"])
+	assert.Equal(t, []any{}, patchedData["tags"])
+	assert.Equal(t, http.StatusBadRequest, unknownStatus)
+}
+
 func TestDeleteFavourite(t *testing.T) {
 	// Arrange
 	server, token := test.StartServer()
@@ -193,3 +332,478 @@ func TestDeleteFavourite(t *testing.T) {
 
 	assert.Equal(t, expected, result)
 }
+
+func TestUpdateFavouriteWithIfMatch(t *testing.T) {
+	// Arrange
+	server, token := test.StartServer()
+	defer server.Close()
+
+	client := server.Client()
+	favouriteURL := server.URL + "/v1/user/favourites/55555555-5555-5555-5555-555555555555"
+
+	send := func(method string, ifMatch string, body string) *http.Response {
+		req, _ := http.NewRequest(method, favouriteURL, bytes.NewReader([]byte(body)))
+		req.Header.Add("Authorization", "bearer "+token)
+		if ifMatch != "" {
+			req.Header.Add("If-Match", ifMatch)
+		}
+
+		resp, err := client.Do(req)
+		assert.NoError(t, err)
+		resp.Body.Close()
+
+		return resp
+	}
+
+	// Act
+	firstResp := send(http.MethodPatch, `"1"`, `{"description":"First writer"}`)
+	staleUpdateResp := send(http.MethodPatch, `"1"`, `{"description":"Second writer"}`)
+	staleDeleteResp := send(http.MethodDelete, `"1"`, "")
+	deleteResp := send(http.MethodDelete, firstResp.Header.Get("ETag"), "")
+
+	// Assert
+	assert.Equal(t, http.StatusOK, firstResp.StatusCode)
+	assert.Equal(t, `"2"`, firstResp.Header.Get("ETag"))
+	assert.Equal(t, http.StatusPreconditionFailed, staleUpdateResp.StatusCode)
+	assert.Equal(t, http.StatusPreconditionFailed, staleDeleteResp.StatusCode)
+	assert.Equal(t, http.StatusOK, deleteResp.StatusCode)
+}
+
+func TestGetFavouritesWithCursors(t *testing.T) {
+	// Arrange
+	server, token := test.StartServer()
+	defer server.Close()
+
+	client := server.Client()
+
+	type page struct {
+		Data       map[string][]struct{ Id string }
+		Pagination struct{ NextCursor, PrevCursor string }
+	}
+	get := func(query string) (int, page) {
+		req, _ := http.NewRequest(http.MethodGet, server.URL+"/v1/user/favourites?pageSize=1&"+query, nil)
+		req.Header.Add("Authorization", "bearer "+token)
+
+		resp, err := client.Do(req)
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+
+		var result page
+		json.NewDecoder(resp.Body).Decode(&result)
+
+		return resp.StatusCode, result
+	}
+	ids := func(p page) []string {
+		result := []string{}
+		for _, favourites := range p.Data {
+			for _, fav := range favourites {
+				result = append(result, fav.Id)
+			}
+		}
+
+		return result
+	}
+
+	// Act
+	_, first := get("")
+	_, second := get("after=" + first.Pagination.NextCursor)
+	_, third := get("after=" + second.Pagination.NextCursor)
+	_, back := get("before=" + third.Pagination.PrevCursor)
+	forgedStatus, _ := get("after=" + first.Pagination.NextCursor + "x")
+
+	// Assert
+	assert.Equal(t, []string{"44444444-4444-4444-4444-444444444444"}, ids(first))
+	assert.Empty(t, first.Pagination.PrevCursor)
+	assert.Equal(t, []string{"55555555-5555-5555-5555-555555555555"}, ids(second))
+	assert.Equal(t, []string{"66666666-6666-6666-6666-666666666666"}, ids(third))
+	assert.Empty(t, third.Pagination.NextCursor)
+	assert.Equal(t, ids(second), ids(back))
+	assert.Equal(t, http.StatusBadRequest, forgedStatus)
+}
+
+func TestGetFavouritesWithFilters(t *testing.T) {
+	// Arrange
+	server, token := test.StartServer()
+	defer server.Close()
+
+	client := server.Client()
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/v1/user/favourites?type=insight,chart&q=q2", nil)
+	req.Header.Add("Authorization", "bearer "+token)
+
+	// Act
+	resp, err := client.Do(req)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	// Assert
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	var result struct {
+		Data map[string][]struct {
+			Id          string `json:"id"`
+			Description string `json:"description"`
+		} `json:"data"`
+		Pagination map[string]any `json:"pagination"`
+	}
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+	assert.Empty(t, result.Data["charts"])
+	assert.Empty(t, result.Data["audiences"])
+	if assert.Len(t, result.Data["insights"], 1) {
+		assert.Equal(t, "Great for Q2 presentation", result.Data["insights"][0].Description)
+	}
+	assert.Equal(t, float64(0), result.Pagination["maxPage"])
+}
+
+func TestGetFavouritesFlatLayout(t *testing.T) {
+	// Arrange
+	server, token := test.StartServer()
+	defer server.Close()
+
+	client := server.Client()
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/v1/user/favourites?layout=flat&sort=-created_at", nil)
+	req.Header.Add("Authorization", "bearer "+token)
+
+	// Act
+	resp, err := client.Do(req)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	// Assert
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	var result struct {
+		Data []struct {
+			Id    string         `json:"id"`
+			Type  string         `json:"type"`
+			Asset map[string]any `json:"asset"`
+		} `json:"data"`
+	}
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+
+	types := []string{}
+	for _, item := range result.Data {
+		types = append(types, item.Type)
+	}
+	assert.Equal(t, []string{"audience", "insight", "chart"}, types)
+	if assert.Len(t, result.Data, 3) {
+		assert.Equal(t, "66666666-6666-6666-6666-666666666666", result.Data[0].Id)
+		assert.Equal(t, "33333333-3333-3333-3333-333333333333", result.Data[0].Asset["id"])
+	}
+}
+
+func TestGetFavourite(t *testing.T) {
+	// Arrange
+	server, token := test.StartServer()
+	defer server.Close()
+
+	client := server.Client()
+	favouriteURL := server.URL + "/v1/user/favourites/55555555-5555-5555-5555-555555555555"
+
+	send := func(method string) *http.Response {
+		req, _ := http.NewRequest(method, favouriteURL, nil)
+		req.Header.Add("Authorization", "bearer "+token)
+
+		resp, err := client.Do(req)
+		assert.NoError(t, err)
+
+		return resp
+	}
+
+	// Act
+	getResp := send(http.MethodGet)
+	defer getResp.Body.Close()
+	send(http.MethodDelete).Body.Close()
+	deletedResp := send(http.MethodGet)
+	deletedResp.Body.Close()
+
+	// Assert
+	assert.Equal(t, http.StatusOK, getResp.StatusCode)
+	assert.Equal(t, `"1"`, getResp.Header.Get("ETag"))
+	var result map[string]map[string]any
+	assert.NoError(t, json.NewDecoder(getResp.Body).Decode(&result))
+	assert.Equal(t, "insight", result["data"]["asset_type"])
+	assert.Equal(t, map[string]any{
+		"id":   "22222222-2222-2222-2222-222222222222",
+		"text": "40% of millennials spend more than 3 hours on social media daily",
+	}, result["data"]["asset"])
+	assert.Equal(t, http.StatusNotFound, deletedResp.StatusCode)
+}
+
+func TestFavouritesBatch(t *testing.T) {
+	send := func(t *testing.T, serverURL string, client *http.Client, token string, method string, body any) (int, map[string]any) {
+		bodyBytes, _ := json.Marshal(body)
+		req, err := http.NewRequest(method, serverURL+"/v1/user/favourites/batch", bytes.NewReader(bodyBytes))
+		assert.NoError(t, err)
+		req.Header.Add("Authorization", "bearer "+token)
+
+		resp, err := client.Do(req)
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+
+		var result map[string]any
+		err = json.NewDecoder(resp.Body).Decode(&result)
+		assert.NoError(t, err)
+
+		return resp.StatusCode, result
+	}
+	statuses := func(result map[string]any) []float64 {
+		statuses := []float64{}
+		for _, item := range result["data"].([]any) {
+			statuses = append(statuses, item.(map[string]any)["status"].(float64))
+		}
+		return statuses
+	}
+
+	t.Run("should create, update and delete several favourites reporting every item", func(t *testing.T) {
+		// Arrange
+		server, token := test.StartServer()
+		defer server.Close()
+		client := server.Client()
+
+		// Act
+		createStatus, created := send(t, server.URL, client, token, http.MethodPost, map[string]any{
+			"items": []map[string]any{
+				{"assetId": "22222222-2222-2222-2222-222222222223", "description": "Good to know"},
+				{"assetId": "11111111-1111-1111-1111-111111111111", "description": "Again"},
+				{"assetId": "99999999-9999-9999-9999-999999999999", "description": "Missing"},
+			},
+		})
+		updateStatus, updated := send(t, server.URL, client, token, http.MethodPatch, map[string]any{
+			"items": []map[string]any{
+				{"id": "44444444-4444-4444-4444-444444444444", "description": "Renamed", "version": 1},
+				{"id": "55555555-5555-5555-5555-555555555555", "description": "Stale", "version": 5},
+			},
+		})
+		deleteStatus, deleted := send(t, server.URL, client, token, http.MethodDelete, map[string]any{
+			"items": []map[string]any{
+				{"id": "66666666-6666-6666-6666-666666666666"},
+				{"id": "66666666-6666-6666-6666-666666666666"},
+			},
+		})
+
+		// Assert
+		assert.Equal(t, http.StatusOK, createStatus)
+		assert.Equal(t, []float64{201, 409, 404}, statuses(created))
+		createdFavourite := created["data"].([]any)[0].(map[string]any)["data"].(map[string]any)
+		assert.Equal(t, "insight", createdFavourite["asset_type"])
+
+		assert.Equal(t, http.StatusOK, updateStatus)
+		assert.Equal(t, []float64{200, 412}, statuses(updated))
+		updatedFavourite := updated["data"].([]any)[0].(map[string]any)["data"].(map[string]any)
+		assert.Equal(t, "Renamed", updatedFavourite["description"])
+		assert.Equal(t, float64(2), updatedFavourite["version"])
+
+		assert.Equal(t, http.StatusOK, deleteStatus)
+		assert.Equal(t, []float64{200, 404}, statuses(deleted))
+	})
+
+	t.Run("should change nothing when an item of an atomic batch fails", func(t *testing.T) {
+		// Arrange
+		server, token := test.StartServer()
+		defer server.Close()
+		client := server.Client()
+
+		// Act
+		status, result := send(t, server.URL, client, token, http.MethodDelete, map[string]any{
+			"atomic": true,
+			"items": []map[string]any{
+				{"id": "44444444-4444-4444-4444-444444444444"},
+				{"id": "55555555-5555-5555-5555-555555555555", "version": 5},
+			},
+		})
+
+		// Assert
+		assert.Equal(t, http.StatusUnprocessableEntity, status)
+		assert.NotEmpty(t, result["error"])
+		assert.Equal(t, []float64{424, 412}, statuses(result))
+
+		req, err := http.NewRequest(http.MethodGet, server.URL+"/v1/user/favourites/44444444-4444-4444-4444-444444444444", nil)
+		assert.NoError(t, err)
+		req.Header.Add("Authorization", "bearer "+token)
+		resp, err := client.Do(req)
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+}
+
+func TestMoveAndPinFavourites(t *testing.T) {
+	// Arrange
+	server, token := test.StartServer()
+	defer server.Close()
+
+	client := server.Client()
+	send := func(method string, path string, body string) int {
+		req, _ := http.NewRequest(method, server.URL+"/v1/user/favourites"+path, strings.NewReader(body))
+		req.Header.Add("Authorization", "bearer "+token)
+
+		resp, err := client.Do(req)
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+
+		return resp.StatusCode
+	}
+	listIds := func() []string {
+		req, _ := http.NewRequest(http.MethodGet, server.URL+"/v1/user/favourites?layout=flat", nil)
+		req.Header.Add("Authorization", "bearer "+token)
+
+		resp, err := client.Do(req)
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+
+		var result struct {
+			Data []struct {
+				Id string `json:"id"`
+			} `json:"data"`
+		}
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+
+		ids := []string{}
+		for _, item := range result.Data {
+			ids = append(ids, item.Id)
+		}
+		return ids
+	}
+
+	// Act
+	moveStatus := send(http.MethodPost, "/66666666-6666-6666-6666-666666666666/move", `{"before":"44444444-4444-4444-4444-444444444444"}`)
+	moved := listIds()
+	pinStatus := send(http.MethodPatch, "/55555555-5555-5555-5555-555555555555", `{"pinned":true}`)
+	pinned := listIds()
+	missingTargetStatus := send(http.MethodPost, "/66666666-6666-6666-6666-666666666666/move", `{"after":"99999999-9999-9999-9999-999999999999"}`)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, moveStatus)
+	assert.Equal(t, []string{
+		"66666666-6666-6666-6666-666666666666",
+		"44444444-4444-4444-4444-444444444444",
+		"55555555-5555-5555-5555-555555555555",
+	}, moved)
+	assert.Equal(t, http.StatusOK, pinStatus)
+	assert.Equal(t, []string{
+		"55555555-5555-5555-5555-555555555555",
+		"66666666-6666-6666-6666-666666666666",
+		"44444444-4444-4444-4444-444444444444",
+	}, pinned)
+	assert.Equal(t, http.StatusNotFound, missingTargetStatus)
+}
+
+func TestFavouriteTags(t *testing.T) {
+	// Arrange
+	server, token := test.StartServer()
+	defer server.Close()
+
+	client := server.Client()
+	send := func(method string, path string, body string) int {
+		req, _ := http.NewRequest(method, server.URL+"/v1/user/favourites"+path, strings.NewReader(body))
+		req.Header.Add("Authorization", "bearer "+token)
+
+		resp, err := client.Do(req)
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+
+		return resp.StatusCode
+	}
+	get := func(path string, result any) int {
+		req, _ := http.NewRequest(http.MethodGet, server.URL+"/v1/user/favourites"+path, nil)
+		req.Header.Add("Authorization", "bearer "+token)
+
+		resp, err := client.Do(req)
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(result))
+		return resp.StatusCode
+	}
+
+	// Act
+	firstStatus := send(http.MethodPatch, "/44444444-4444-4444-4444-444444444444", `{"tags":["Q2"," Sales "]}`)
+	secondStatus := send(http.MethodPatch, "/55555555-5555-5555-5555-555555555555", `{"tags":["q2"]}`)
+	invalidStatus := send(http.MethodPatch, "/66666666-6666-6666-6666-666666666666", `{"tags":["a,b"]}`)
+	var filtered struct {
+		Data []struct {
+			Id   string   `json:"id"`
+			Tags []string `json:"tags"`
+		} `json:"data"`
+	}
+	filteredStatus := get("?layout=flat&tag=q2,sales", &filtered)
+	var tags struct {
+		Data []map[string]any `json:"data"`
+	}
+	tagsStatus := get("/tags?prefix=q", &tags)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, firstStatus)
+	assert.Equal(t, http.StatusOK, secondStatus)
+	assert.Equal(t, http.StatusBadRequest, invalidStatus)
+	assert.Equal(t, http.StatusOK, filteredStatus)
+	if assert.Len(t, filtered.Data, 1) {
+		assert.Equal(t, "44444444-4444-4444-4444-444444444444", filtered.Data[0].Id)
+		assert.Equal(t, []string{"q2", "sales"}, filtered.Data[0].Tags)
+	}
+	assert.Equal(t, http.StatusOK, tagsStatus)
+	assert.Equal(t, []map[string]any{{"tag": "q2", "count": float64(2)}}, tags.Data)
+}
+
+func TestFavouriteTrash(t *testing.T) {
+	// Arrange
+	server, token := test.StartServer()
+	defer server.Close()
+
+	client := server.Client()
+	send := func(method string, path string) int {
+		req, _ := http.NewRequest(method, server.URL+"/v1/user/favourites"+path, nil)
+		req.Header.Add("Authorization", "bearer "+token)
+
+		resp, err := client.Do(req)
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+
+		return resp.StatusCode
+	}
+	var trash struct {
+		Data []struct {
+			Id        string `json:"id"`
+			DeletedAt string `json:"deleted_at"`
+			ExpiresAt string `json:"expires_at"`
+		} `json:"data"`
+	}
+	getTrash := func() int {
+		req, _ := http.NewRequest(http.MethodGet, server.URL+"/v1/user/favourites/trash", nil)
+		req.Header.Add("Authorization", "bearer "+token)
+
+		resp, err := client.Do(req)
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&trash))
+		return resp.StatusCode
+	}
+
+	// Act
+	deleteStatus := send(http.MethodDelete, "/55555555-5555-5555-5555-555555555555")
+	deletedStatus := send(http.MethodGet, "/55555555-5555-5555-5555-555555555555")
+	trashStatus := getTrash()
+	trashed := trash.Data
+	restoreStatus := send(http.MethodPost, "/trash/55555555-5555-5555-5555-555555555555/restore")
+	restoredStatus := send(http.MethodGet, "/55555555-5555-5555-5555-555555555555")
+	send(http.MethodDelete, "/66666666-6666-6666-6666-666666666666")
+	purgeStatus := send(http.MethodDelete, "/trash/66666666-6666-6666-6666-666666666666")
+	restorePurgedStatus := send(http.MethodPost, "/trash/66666666-6666-6666-6666-666666666666/restore")
+	getTrash()
+
+	// Assert
+	assert.Equal(t, http.StatusOK, deleteStatus)
+	assert.Equal(t, http.StatusNotFound, deletedStatus)
+	assert.Equal(t, http.StatusOK, trashStatus)
+	if assert.Len(t, trashed, 1) {
+		assert.Equal(t, "55555555-5555-5555-5555-555555555555", trashed[0].Id)
+		assert.NotEmpty(t, trashed[0].DeletedAt)
+		assert.NotEmpty(t, trashed[0].ExpiresAt)
+	}
+	assert.Equal(t, http.StatusOK, restoreStatus)
+	assert.Equal(t, http.StatusOK, restoredStatus)
+	assert.Equal(t, http.StatusOK, purgeStatus)
+	assert.Equal(t, http.StatusNotFound, restorePurgedStatus)
+	assert.Empty(t, trash.Data)
+}
@@ -4,10 +4,12 @@ import (
 	"bytes"
 	"encoding/json"
 	"net/http"
+	"platform-go-challenge/internal/database"
 	"platform-go-challenge/test"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestUserLogin(t *testing.T) {
@@ -40,3 +42,33 @@ func TestUserLogin(t *testing.T) {
 	assert.NotEmpty(t, data["token"])
 	assert.NotEmpty(t, data["expires_at"])
 }
+
+func TestUserLoginWithFixtures(t *testing.T) {
+	// Arrange
+	fixtures, err := database.LoadFixtures("testdata/qa_user.yaml")
+	require.NoError(t, err)
+
+	server, _ := test.StartServerWithFixtures(fixtures)
+	defer server.Close()
+
+	client := server.Client()
+
+	login := func(email string, password string) int {
+		bodyBytes, _ := json.Marshal(map[string]any{"email": email, "password": password})
+		req, _ := http.NewRequest(http.MethodPost, server.URL+"/v1/user/login", bytes.NewReader(bodyBytes))
+
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		return resp.StatusCode
+	}
+
+	// Act
+	qaStatus := login("qa@test.com", "qa-pass")
+	devStatus := login("test@test.com", "pass")
+
+	// Assert
+	assert.Equal(t, http.StatusOK, qaStatus)
+	assert.NotEqual(t, http.StatusOK, devStatus)
+}
@@ -5,21 +5,33 @@ import (
 	"platform-go-challenge/internal/database"
 	"platform-go-challenge/internal/domain/audience"
 	"platform-go-challenge/internal/domain/chart"
+	"platform-go-challenge/internal/domain/collection"
 	"platform-go-challenge/internal/domain/favourite"
 	"platform-go-challenge/internal/domain/insight"
 	"platform-go-challenge/internal/domain/user"
 	"platform-go-challenge/internal/server"
 	"platform-go-challenge/internal/utils"
+	"time"
 )
 
+// StartServer starts the API with the dev fixtures.
 func StartServer() (*httptest.Server, string) {
+	fixtures, err := database.DefaultFixtures()
+	if err != nil {
+		panic(err)
+	}
+
+	return StartServerWithFixtures(fixtures)
+}
+
+// StartServerWithFixtures starts the API with its own dataset, so a test can seed exactly what it needs.
+// The returned token belongs to the first user of the fixtures.
+func StartServerWithFixtures(fixtures *database.Fixtures) (*httptest.Server, string) {
 	jwtAuth := utils.NewJWTAuth("test-secret")
 	passwordHasher := utils.NewHasher("test-secret")
 	db := database.NewIMDatabase()
 	tokenIssuer := utils.NewJWTokenIssuer(jwtAuth)
 
-	database.IMpopulateStorageForDevEnv(db, passwordHasher)
-
 	// Users
 	userRepository := user.NewInMemoryDBUserRepository(db)
 	userService := user.NewUserService(user.ServiceDependencies{
@@ -40,16 +52,39 @@ func StartServer() (*httptest.Server, string) {
 	audienceRepository := audience.NewInMemoryDBAudienceRepository(db)
 	favouriteRepository := favourite.NewInMemoryDBFavouriteRepository(db)
 
+	// Every asset type a favourite can point to is registered here, a new one only needs its provider
+	assets := favourite.NewAssetRegistry()
+	assets.Register(favourite.NewAssetProvider(favourite.AssetTypeChart, "charts", chartRepository.GetByIds))
+	assets.Register(favourite.NewAssetProvider(favourite.AssetTypeInsight, "insights", insightRepository.GetByIds))
+	assets.Register(favourite.NewAssetProvider(favourite.AssetTypeAudience, "audiences", audienceRepository.GetByIds))
+
+	assetTypes := []string{}
+	for _, assetType := range assets.Types() {
+		assetTypes = append(assetTypes, string(assetType))
+	}
+	if err := fixtures.ValidateAssets(assetTypes); err != nil {
+		panic(err)
+	}
+	if err := database.IMLoadFixtures(db, fixtures, passwordHasher); err != nil {
+		panic(err)
+	}
+
 	favouriteService := favourite.NewFavouriteService(favourite.FavouriteServiceDependencies{
-		ChartRepository:     chartRepository,
-		InsightRepository:   insightRepository,
-		AudienceRepository:  audienceRepository,
+		Assets:              assets,
 		FavouriteRepository: favouriteRepository,
+		Cursors:             utils.NewCursorCodec("test-secret"),
 	})
 
 	getFavouritesHandler := favourite.GetFavouritesHandler(
 		favourite.GetFavouritesHandlerDependencies{
 			FavouriteService: &favouriteService,
+			Assets:           assets,
+		},
+	)
+
+	getFavouriteHandler := favourite.GetFavouriteHandler(
+		favourite.GetFavouriteHandlerDependencies{
+			FavouriteService: &favouriteService,
 		},
 	)
 
@@ -71,24 +106,273 @@ func StartServer() (*httptest.Server, string) {
 		},
 	)
 
+	moveFavouriteHandler := favourite.MoveFavouriteHandler(
+		favourite.MoveFavouriteHandlerDependencies{
+			FavouriteService: &favouriteService,
+		},
+	)
+
+	getFavouriteTagsHandler := favourite.GetFavouriteTagsHandler(
+		favourite.GetFavouriteTagsHandlerDependencies{
+			FavouriteService: &favouriteService,
+		},
+	)
+
+	getFavouriteTrashHandler := favourite.GetFavouriteTrashHandler(
+		favourite.GetFavouriteTrashHandlerDependencies{
+			FavouriteService: &favouriteService,
+		},
+	)
+
+	restoreFavouriteHandler := favourite.RestoreFavouriteHandler(
+		favourite.RestoreFavouriteHandlerDependencies{
+			FavouriteService: &favouriteService,
+		},
+	)
+
+	purgeFavouriteHandler := favourite.PurgeFavouriteHandler(
+		favourite.PurgeFavouriteHandlerDependencies{
+			FavouriteService: &favouriteService,
+		},
+	)
+
+	createFavouritesBatchHandler := favourite.CreateFavouritesBatchHandler(
+		favourite.CreateFavouritesBatchHandlerDependencies{
+			FavouriteService: &favouriteService,
+		},
+	)
+
+	updateFavouritesBatchHandler := favourite.UpdateFavouritesBatchHandler(
+		favourite.UpdateFavouritesBatchHandlerDependencies{
+			FavouriteService: &favouriteService,
+		},
+	)
+
+	deleteFavouritesBatchHandler := favourite.DeleteFavouritesBatchHandler(
+		favourite.DeleteFavouritesBatchHandlerDependencies{
+			FavouriteService: &favouriteService,
+		},
+	)
+
+	// Collections
+	collectionService := collection.NewCollectionService(collection.CollectionServiceDependencies{
+		CollectionRepository: collection.NewInMemoryDBCollectionRepository(db),
+		FavouriteRepository:  favouriteRepository,
+		FavouriteService:     &favouriteService,
+	})
+
+	getCollectionsHandler := collection.GetCollectionsHandler(
+		collection.GetCollectionsHandlerDependencies{
+			CollectionService: &collectionService,
+		},
+	)
+
+	getCollectionHandler := collection.GetCollectionHandler(
+		collection.GetCollectionHandlerDependencies{
+			CollectionService: &collectionService,
+		},
+	)
+
+	createCollectionHandler := collection.CreateCollectionHandler(
+		collection.CreateCollectionHandlerDependencies{
+			CollectionService: &collectionService,
+		},
+	)
+
+	updateCollectionHandler := collection.UpdateCollectionHandler(
+		collection.UpdateCollectionHandlerDependencies{
+			CollectionService: &collectionService,
+		},
+	)
+
+	deleteCollectionHandler := collection.DeleteCollectionHandler(
+		collection.DeleteCollectionHandlerDependencies{
+			CollectionService: &collectionService,
+		},
+	)
+
+	getCollectionFavouritesHandler := collection.GetCollectionFavouritesHandler(
+		collection.GetCollectionFavouritesHandlerDependencies{
+			CollectionService: &collectionService,
+		},
+	)
+
+	addCollectionFavouriteHandler := collection.AddCollectionFavouriteHandler(
+		collection.AddCollectionFavouriteHandlerDependencies{
+			CollectionService: &collectionService,
+		},
+	)
+
+	removeCollectionFavouriteHandler := collection.RemoveCollectionFavouriteHandler(
+		collection.RemoveCollectionFavouriteHandlerDependencies{
+			CollectionService: &collectionService,
+		},
+	)
+
+	// Charts
+	chartService := chart.NewChartService(chart.ChartServiceDependencies{
+		ChartRepository: chartRepository,
+	})
+
+	getChartsHandler := chart.GetChartsHandler(
+		chart.GetChartsHandlerDependencies{
+			ChartService: &chartService,
+		},
+	)
+
+	getChartHandler := chart.GetChartHandler(
+		chart.GetChartHandlerDependencies{
+			ChartService: &chartService,
+		},
+	)
+
+	createChartHandler := chart.CreateChartHandler(
+		chart.CreateChartHandlerDependencies{
+			ChartService: &chartService,
+		},
+	)
+
+	updateChartHandler := chart.UpdateChartHandler(
+		chart.UpdateChartHandlerDependencies{
+			ChartService: &chartService,
+		},
+	)
+
+	deleteChartHandler := chart.DeleteChartHandler(
+		chart.DeleteChartHandlerDependencies{
+			ChartService: &chartService,
+		},
+	)
+
+	// Insights
+	insightService := insight.NewInsightService(insight.InsightServiceDependencies{
+		InsightRepository: insightRepository,
+	})
+
+	getInsightsHandler := insight.GetInsightsHandler(
+		insight.GetInsightsHandlerDependencies{
+			InsightService: &insightService,
+		},
+	)
+
+	getInsightHandler := insight.GetInsightHandler(
+		insight.GetInsightHandlerDependencies{
+			InsightService: &insightService,
+		},
+	)
+
+	createInsightHandler := insight.CreateInsightHandler(
+		insight.CreateInsightHandlerDependencies{
+			InsightService: &insightService,
+		},
+	)
+
+	updateInsightHandler := insight.UpdateInsightHandler(
+		insight.UpdateInsightHandlerDependencies{
+			InsightService: &insightService,
+		},
+	)
+
+	deleteInsightHandler := insight.DeleteInsightHandler(
+		insight.DeleteInsightHandlerDependencies{
+			InsightService: &insightService,
+		},
+	)
+
+	// Audiences
+	audienceService := audience.NewAudienceService(audience.AudienceServiceDependencies{
+		AudienceRepository: audienceRepository,
+	})
+
+	getAudiencesHandler := audience.GetAudiencesHandler(
+		audience.GetAudiencesHandlerDependencies{
+			AudienceService: &audienceService,
+		},
+	)
+
+	getAudienceHandler := audience.GetAudienceHandler(
+		audience.GetAudienceHandlerDependencies{
+			AudienceService: &audienceService,
+		},
+	)
+
+	createAudienceHandler := audience.CreateAudienceHandler(
+		audience.CreateAudienceHandlerDependencies{
+			AudienceService: &audienceService,
+		},
+	)
+
+	updateAudienceHandler := audience.UpdateAudienceHandler(
+		audience.UpdateAudienceHandlerDependencies{
+			AudienceService: &audienceService,
+		},
+	)
+
+	deleteAudienceHandler := audience.DeleteAudienceHandler(
+		audience.DeleteAudienceHandlerDependencies{
+			AudienceService: &audienceService,
+		},
+	)
+
 	// Routing
 	routerDependencies := server.RouterDependencies{
-		JWTAuth:                jwtAuth,
-		UserLoginHandler:       userLoginHandler,
-		GetFavouritesHandler:   getFavouritesHandler,
-		CreateFavouriteHandler: createFavouriteHandler,
-		UpdateFavouriteHandler: updateFavouriteHandler,
-		DeleteFavouriteHandler: deleteFavouriteHandler,
+		JWTAuth:                 jwtAuth,
+		IdempotencyMiddleware:   utils.IdempotencyMiddleware(utils.NewInMemoryIdempotencyStore(time.Hour, nil), 1<<20),
+		UserLoginHandler:        userLoginHandler,
+		GetFavouritesHandler:    getFavouritesHandler,
+		GetFavouriteHandler:     getFavouriteHandler,
+		CreateFavouriteHandler:  createFavouriteHandler,
+		UpdateFavouriteHandler:  updateFavouriteHandler,
+		DeleteFavouriteHandler:  deleteFavouriteHandler,
+		MoveFavouriteHandler:    moveFavouriteHandler,
+		GetFavouriteTagsHandler: getFavouriteTagsHandler,
+
+		GetFavouriteTrashHandler: getFavouriteTrashHandler,
+		RestoreFavouriteHandler:  restoreFavouriteHandler,
+		PurgeFavouriteHandler:    purgeFavouriteHandler,
+
+		CreateFavouritesBatchHandler: createFavouritesBatchHandler,
+		UpdateFavouritesBatchHandler: updateFavouritesBatchHandler,
+		DeleteFavouritesBatchHandler: deleteFavouritesBatchHandler,
+
+		GetCollectionsHandler:            getCollectionsHandler,
+		GetCollectionHandler:             getCollectionHandler,
+		CreateCollectionHandler:          createCollectionHandler,
+		UpdateCollectionHandler:          updateCollectionHandler,
+		DeleteCollectionHandler:          deleteCollectionHandler,
+		GetCollectionFavouritesHandler:   getCollectionFavouritesHandler,
+		AddCollectionFavouriteHandler:    addCollectionFavouriteHandler,
+		RemoveCollectionFavouriteHandler: removeCollectionFavouriteHandler,
+
+		GetChartsHandler:      getChartsHandler,
+		GetChartHandler:       getChartHandler,
+		CreateChartHandler:    createChartHandler,
+		UpdateChartHandler:    updateChartHandler,
+		DeleteChartHandler:    deleteChartHandler,
+		GetInsightsHandler:    getInsightsHandler,
+		GetInsightHandler:     getInsightHandler,
+		CreateInsightHandler:  createInsightHandler,
+		UpdateInsightHandler:  updateInsightHandler,
+		DeleteInsightHandler:  deleteInsightHandler,
+		GetAudiencesHandler:   getAudiencesHandler,
+		GetAudienceHandler:    getAudienceHandler,
+		CreateAudienceHandler: createAudienceHandler,
+		UpdateAudienceHandler: updateAudienceHandler,
+		DeleteAudienceHandler: deleteAudienceHandler,
 	}
 
 	router := server.SetupRouter(routerDependencies)
 
 	server := httptest.NewServer(router)
 
-	token, _, _ := tokenIssuer(
-		map[string]any{
-			"sub": "a3973a1c-a77b-4a04-a296-ddec19034419",
-		},
-	)
+	token := ""
+	if len(fixtures.Users) > 0 {
+		token, _, _ = tokenIssuer(
+			map[string]any{
+				"sub": fixtures.Users[0].Id.String(),
+			},
+		)
+	}
+
 	return server, token
 }
@@ -0,0 +1,62 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"platform-go-challenge/internal/database"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresPool connects to TEST_DATABASE_URL and migrates a fresh schema that is dropped when the test ends,
+// so tests never see each other's rows. The test is skipped when TEST_DATABASE_URL is not set,
+// except in CI where it fails, so the PostgreSQL tests cannot be skipped there without notice.
+func PostgresPool(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		if os.Getenv("CI") != "" {
+			t.Fatal("TEST_DATABASE_URL is not set in CI")
+		}
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	admin, err := database.NewPostgresDB(url)
+	if err != nil {
+		t.Fatalf("could not connect to postgres: %s", err)
+	}
+
+	schema := "test_" + strings.ReplaceAll(uuid.NewString(), "-", "")
+	if _, err := admin.Exec(context.Background(), fmt.Sprintf("CREATE SCHEMA %s", schema)); err != nil {
+		admin.Close()
+		t.Fatalf("could not create schema: %s", err)
+	}
+
+	config, err := pgxpool.ParseConfig(url)
+	if err != nil {
+		t.Fatalf("could not parse TEST_DATABASE_URL: %s", err)
+	}
+	config.ConnConfig.RuntimeParams["search_path"] = schema
+
+	pool, err := database.NewPostgresDBWithConfig(config)
+	if err != nil {
+		t.Fatalf("could not connect to postgres: %s", err)
+	}
+
+	t.Cleanup(func() {
+		pool.Close()
+		admin.Exec(context.Background(), fmt.Sprintf("DROP SCHEMA %s CASCADE", schema))
+		admin.Close()
+	})
+
+	if err := database.PGMigrate(pool); err != nil {
+		t.Fatalf("could not migrate: %s", err)
+	}
+
+	return pool
+}